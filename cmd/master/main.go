@@ -7,13 +7,20 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/ingress"
+	"github.com/danpasecinic/podling/internal/master/admission"
 	"github.com/danpasecinic/podling/internal/master/api"
+	"github.com/danpasecinic/podling/internal/master/dns"
+	"github.com/danpasecinic/podling/internal/master/logstore"
 	"github.com/danpasecinic/podling/internal/master/scheduler"
 	"github.com/danpasecinic/podling/internal/master/services"
 	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -34,6 +41,13 @@ func main() {
 	sched := scheduler.NewRoundRobin()
 
 	endpointController := services.NewEndpointController(store)
+	if value := os.Getenv("SERVICE_NODE_PORT_RANGE"); value != "" {
+		portRange, err := services.ParsePortRange(value)
+		if err != nil {
+			log.Fatalf("invalid SERVICE_NODE_PORT_RANGE: %v", err)
+		}
+		endpointController.SetNodePortRange(portRange)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -43,10 +57,53 @@ func main() {
 		}
 	}()
 
+	if pool := os.Getenv("LOADBALANCER_IP_POOL"); pool != "" {
+		provider, err := services.NewIPPoolProvider(pool)
+		if err != nil {
+			log.Fatalf("invalid LOADBALANCER_IP_POOL: %v", err)
+		}
+		loadBalancerController := services.NewLoadBalancerController(store, provider)
+		go func() {
+			if err := loadBalancerController.Start(ctx); err != nil {
+				log.Printf("load balancer controller error: %v", err)
+			}
+		}()
+	}
+
 	server := api.NewServer(store, sched, endpointController)
+	server.SetPodCIDRAllocator(initPodCIDRs(store))
+
+	admissionChain, err := admission.NewChain(os.Getenv("ADMISSION_PLUGINS"))
+	if err != nil {
+		log.Fatalf("invalid ADMISSION_PLUGINS: %v", err)
+	}
+	if len(admissionChain) > 0 {
+		log.Printf("admission plugins enabled: %s", os.Getenv("ADMISSION_PLUGINS"))
+	}
+	server.SetAdmissionChain(admissionChain)
+
+	logStore := initLogStore()
+	server.SetLogStore(logStore)
+	go logStore.Run(ctx)
 
 	go server.StartNodeExpirationChecker(ctx)
 
+	if dnsServer := initDNS(store); dnsServer != nil {
+		go func() {
+			if err := dnsServer.ListenAndServe(ctx); err != nil {
+				log.Printf("cluster DNS disabled: %v", err)
+			}
+		}()
+	}
+
+	if gateway := initIngress(store); gateway != nil {
+		go func() {
+			if err := gateway.ListenAndServe(ctx); err != nil {
+				log.Printf("ingress gateway disabled: %v", err)
+			}
+		}()
+	}
+
 	e := echo.New()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
@@ -121,6 +178,112 @@ func initStore() (state.StateStore, func() error) {
 	}
 }
 
+// initLogStore creates the store for logs shipped by workers, with retention limits from
+// LOG_RETENTION (a duration) and LOG_MAX_BYTES_PER_POD (a size such as 10Mi)
+func initLogStore() *logstore.Store {
+	config := logstore.DefaultConfig()
+
+	if value := os.Getenv("LOG_RETENTION"); value != "" {
+		retention, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("invalid LOG_RETENTION: %v", err)
+		}
+		config.MaxAge = retention
+	}
+	if value := os.Getenv("LOG_MAX_BYTES_PER_POD"); value != "" {
+		maxBytes, err := types.ParseMemory(value)
+		if err != nil {
+			log.Fatalf("invalid LOG_MAX_BYTES_PER_POD: %v", err)
+		}
+		config.MaxBytesPerPod = maxBytes
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("invalid log retention config: %v", err)
+	}
+
+	log.Printf(
+		"retaining shipped logs for %s, up to %s per pod", config.MaxAge, types.FormatMemory(config.MaxBytesPerPod),
+	)
+	return logstore.NewStore(config)
+}
+
+// initDNS creates the cluster DNS server from DNS_ADDR (default :53, "off" disables it),
+// CLUSTER_DOMAIN (default cluster.local) and DNS_UPSTREAMS (comma-separated host:port resolvers,
+// default the nameservers in /etc/resolv.conf)
+func initDNS(store state.StateStore) *dns.Server {
+	config := dns.DefaultConfig()
+
+	if value := os.Getenv("DNS_ADDR"); value == "off" {
+		log.Println("cluster DNS disabled")
+		return nil
+	} else if value != "" {
+		config.Addr = value
+	}
+	if value := os.Getenv("CLUSTER_DOMAIN"); value != "" {
+		config.Domain = value
+	}
+	if value := os.Getenv("DNS_UPSTREAMS"); value != "" {
+		config.Upstreams = nil
+		for _, upstream := range strings.Split(value, ",") {
+			if upstream = strings.TrimSpace(upstream); upstream != "" {
+				config.Upstreams = append(config.Upstreams, upstream)
+			}
+		}
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("invalid cluster DNS config: %v", err)
+	}
+
+	log.Printf("serving cluster DNS for %s on %s", config.Domain, config.Addr)
+	return dns.NewServer(store, config)
+}
+
+// initPodCIDRs creates the allocator of node pod subnets from POD_CIDR (default 10.244.0.0/16,
+// "off" disables it) and NODE_CIDR_MASK_SIZE (the prefix length of each node's subnet, default 22)
+func initPodCIDRs(store state.StateStore) *services.PodCIDRAllocator {
+	cidr := os.Getenv("POD_CIDR")
+	if cidr == "off" {
+		log.Println("pod CIDR allocation disabled")
+		return nil
+	}
+	if cidr == "" {
+		cidr = services.DefaultPodCIDR
+	}
+	maskSize := services.DefaultNodeCIDRMaskSize
+	if value := os.Getenv("NODE_CIDR_MASK_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("invalid NODE_CIDR_MASK_SIZE: %v", err)
+		}
+		maskSize = size
+	}
+
+	allocator, err := services.NewPodCIDRAllocator(store, cidr, maskSize)
+	if err != nil {
+		log.Fatalf("invalid pod CIDR config: %v", err)
+	}
+
+	log.Printf("assigning /%d node pod subnets from %s", maskSize, allocator.ClusterCIDR())
+	return allocator
+}
+
+// initIngress creates the ingress gateway from INGRESS_ADDR (the plain HTTP listen address) and
+// INGRESS_TLS_ADDR (the HTTPS listen address); the gateway is disabled when both are empty
+func initIngress(store state.StateStore) *ingress.Gateway {
+	config := ingress.DefaultConfig()
+	config.Addr = os.Getenv("INGRESS_ADDR")
+	config.TLSAddr = os.Getenv("INGRESS_TLS_ADDR")
+	if config.Addr == "" && config.TLSAddr == "" {
+		return nil
+	}
+	if err := config.Validate(); err != nil {
+		log.Fatalf("invalid ingress gateway config: %v", err)
+	}
+
+	log.Printf("serving ingresses on %q (HTTP) and %q (HTTPS)", config.Addr, config.TLSAddr)
+	return ingress.NewGateway(ingress.NewStoreSource(store), config)
+}
+
 // maskPassword masks the password in a database URL for logging
 func maskPassword() string {
 	return "***masked***"
@@ -6,44 +6,303 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/ingress"
+	"github.com/danpasecinic/podling/internal/types"
 	"github.com/danpasecinic/podling/internal/worker/agent"
+	"github.com/danpasecinic/podling/internal/worker/cri"
+	"github.com/danpasecinic/podling/internal/worker/docker"
+	"github.com/danpasecinic/podling/internal/worker/eviction"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+	"github.com/danpasecinic/podling/internal/worker/logship"
+	"github.com/danpasecinic/podling/internal/worker/netpol"
+	"github.com/danpasecinic/podling/internal/worker/overlay"
+	"github.com/danpasecinic/podling/internal/worker/proxy"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 func main() {
-	nodeID := flag.String("node-id", "", "Node ID (required)")
+	nodeID := flag.String("node-id", "", "Node ID (defaults to the ID stored in -state-dir)")
+	stateDir := flag.String("state-dir", defaultStateDir(), "Directory where the worker persists its node identity")
 	hostname := flag.String("hostname", "localhost", "Worker hostname")
 	port := flag.Int("port", 8081, "Worker port")
 	masterURL := flag.String("master-url", "http://localhost:8070", "Master API URL")
 	heartbeatInterval := flag.Duration("heartbeat-interval", 30*time.Second, "Heartbeat interval")
 	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "Graceful shutdown timeout")
+	runtimeName := flag.String("runtime", "docker", "Container runtime: docker, cri or fake")
+	criEndpoint := flag.String("cri-endpoint", cri.DefaultEndpoint, "CRI runtime endpoint (with -runtime cri)")
+	crictlPath := flag.String("crictl-path", "crictl", "Path to the crictl binary (with -runtime cri)")
+	statsInterval := flag.Duration("stats-interval", 10*time.Second, "Container stats sampling interval")
 
+	gcDefaults := imagegc.DefaultConfig()
+	imageGCHigh := flag.Int(
+		"image-gc-high-threshold", gcDefaults.HighThresholdPercent,
+		"Disk usage percent at which image garbage collection starts",
+	)
+	imageGCLow := flag.Int(
+		"image-gc-low-threshold", gcDefaults.LowThresholdPercent,
+		"Disk usage percent image garbage collection frees down to",
+	)
+	imageGCInterval := flag.Duration("image-gc-interval", gcDefaults.Interval, "Image garbage collection interval")
+	imageMinAge := flag.Duration(
+		"image-minimum-gc-age", gcDefaults.MinAge, "Minimum age of an unused image before it can be removed",
+	)
+
+	evictionDefaults := eviction.DefaultConfig()
+	evictionMemory := flag.String(
+		"eviction-memory-available", types.FormatMemory(evictionDefaults.MemoryAvailableThreshold),
+		"Available memory below which pods are evicted (e.g., 100Mi)",
+	)
+	evictionDiskPercent := flag.Int(
+		"eviction-disk-available-percent", evictionDefaults.DiskAvailablePercent,
+		"Free disk percent below which pods are evicted",
+	)
+	evictionPIDPercent := flag.Int(
+		"eviction-pid-available-percent", evictionDefaults.PIDAvailablePercent,
+		"Free process ID percent below which the node reports PID pressure",
+	)
+	evictionInterval := flag.Duration("eviction-interval", evictionDefaults.Interval, "Eviction check interval")
+	evictionTransition := flag.Duration(
+		"eviction-pressure-transition-period", evictionDefaults.PressureTransitionPeriod,
+		"How long a pressure condition is kept after the signal clears",
+	)
+
+	shipDefaults := logship.DefaultConfig()
+	logSinkName := flag.String("log-sink", "master", "Where container logs are shipped: master, file, loki or none")
+	logSinkURL := flag.String("log-sink-url", "", "Loki push API base URL (with -log-sink loki)")
+	logDir := flag.String("log-dir", "", "Directory for shipped logs (with -log-sink file, default <state-dir>/logs)")
+	logMaxSize := flag.String(
+		"log-max-size-per-pod", "10Mi", "Size at which a pod's log file is rotated (with -log-sink file)",
+	)
+	logMaxAge := flag.Duration("log-max-age", 24*time.Hour, "How long log files are kept (with -log-sink file)")
+	logFlushInterval := flag.Duration(
+		"log-flush-interval", shipDefaults.FlushInterval, "How often buffered log lines are shipped",
+	)
+	logBufferSize := flag.Int(
+		"log-buffer-size", shipDefaults.BufferSize, "Log lines buffered while the log sink is unavailable",
+	)
+
+	proxyDefaults := proxy.DefaultConfig()
+	proxyMode := flag.String(
+		"proxy-mode", string(proxyDefaults.Mode), "How service ClusterIPs are proxied: userspace, nftables or none",
+	)
+	proxySyncInterval := flag.Duration(
+		"proxy-sync-interval", proxyDefaults.SyncInterval, "How often services and endpoints are fetched from the master",
+	)
+	proxyInterface := flag.String(
+		"proxy-interface", proxyDefaults.Interface, "Dummy interface service IPs are assigned to (userspace mode)",
+	)
+
+	clusterDNS := flag.String(
+		"cluster-dns", "", "Cluster DNS server pods resolve names through (default the master's IP, none disables)",
+	)
+	clusterDomain := flag.String("cluster-domain", "cluster.local", "Cluster DNS domain")
+	ingressAddr := flag.String(
+		"ingress-addr", "", "Address the node serves ingresses on over plain HTTP (empty disables the gateway)",
+	)
+	overlayDefaults := overlay.DefaultConfig()
+	overlayMode := flag.String("overlay", "vxlan", "How pod subnets are connected to other nodes: vxlan or none")
+	nodeIP := flag.String(
+		"node-ip", "", "Address other nodes reach this node's pods through (default the address used to reach the master)",
+	)
+	clusterCIDR := flag.String(
+		"cluster-cidr", overlayDefaults.ClusterCIDR, "Cluster pod CIDR; must match the master's POD_CIDR",
+	)
+	podSubnetPrefix := flag.Int(
+		"pod-subnet-prefix", agent.DefaultPodNetworkConfig().SubnetPrefix,
+		"Prefix length of each pod's subnet within the node's pod CIDR",
+	)
+	networkPolicyMode := flag.String(
+		"network-policy", "nftables", "How network policies are enforced on pod traffic: nftables or none",
+	)
 	flag.Parse()
-	if *nodeID == "" {
-		log.Fatal("node-id is required")
+
+	workerNodeID, err := agent.LoadNodeID(*stateDir, *nodeID)
+	if err != nil {
+		log.Fatalf("failed to load node identity: %v", err)
 	}
 
-	workerAgent, err := agent.NewAgent(*nodeID, *masterURL)
+	containerRuntime, err := newRuntime(*runtimeName, *criEndpoint, *crictlPath)
 	if err != nil {
-		log.Fatalf("failed to create agent: %v", err)
+		log.Fatalf("failed to create container runtime: %v", err)
 	}
+	log.Printf("using %s container runtime", containerRuntime.Name())
+
+	workerAgent := agent.NewAgentWithRuntime(workerNodeID, *masterURL, containerRuntime)
 	defer workerAgent.Stop()
 
+	gcConfig := imagegc.Config{
+		HighThresholdPercent: *imageGCHigh,
+		LowThresholdPercent:  *imageGCLow,
+		Interval:             *imageGCInterval,
+		MinAge:               *imageMinAge,
+	}
+	if err := workerAgent.SetImageGCConfig(gcConfig); err != nil {
+		log.Fatalf("invalid image garbage collection config: %v", err)
+	}
+
+	evictionMemoryBytes, err := types.ParseMemory(*evictionMemory)
+	if err != nil {
+		log.Fatalf("invalid eviction memory threshold: %v", err)
+	}
+	evictionConfig := eviction.Config{
+		MemoryAvailableThreshold: evictionMemoryBytes,
+		DiskAvailablePercent:     *evictionDiskPercent,
+		PIDAvailablePercent:      *evictionPIDPercent,
+		Interval:                 *evictionInterval,
+		PressureTransitionPeriod: *evictionTransition,
+	}
+	if err := workerAgent.SetEvictionConfig(evictionConfig); err != nil {
+		log.Fatalf("invalid eviction config: %v", err)
+	}
+
+	if err := workerAgent.SetStatsInterval(*statsInterval); err != nil {
+		log.Fatalf("invalid stats interval: %v", err)
+	}
+
+	if *logDir == "" {
+		*logDir = filepath.Join(*stateDir, "logs")
+	}
+	logSink, err := newLogSink(*logSinkName, *masterURL, *logSinkURL, *logDir, *logMaxSize, *logMaxAge)
+	if err != nil {
+		log.Fatalf("invalid log sink: %v", err)
+	}
+	if logSink != nil {
+		shipConfig := logship.Config{
+			BatchSize:     min(shipDefaults.BatchSize, *logBufferSize),
+			FlushInterval: *logFlushInterval,
+			BufferSize:    *logBufferSize,
+		}
+		if err := shipConfig.Validate(); err != nil {
+			log.Fatalf("invalid log shipping config: %v", err)
+		}
+		workerAgent.SetLogShipper(logship.NewShipper(logSink, shipConfig))
+		log.Printf("shipping container logs to the %s sink", *logSinkName)
+	}
+
+	if *networkPolicyMode != "nftables" && *networkPolicyMode != "none" {
+		log.Fatalf("unknown -network-policy %q (expected nftables or none)", *networkPolicyMode)
+	}
+	networkPolicyCtx, stopNetworkPolicies := context.WithCancel(context.Background())
+	defer stopNetworkPolicies()
+	// The userspace service proxy relays connections from the node, past the nftables rules,
+	// so it checks them against the policies itself
+	var policyFilter proxy.Filter
+	if *networkPolicyMode == "nftables" {
+		policyConfig := netpol.DefaultConfig()
+		policyConfig.NodeID = workerAgent.NodeID()
+		if err := policyConfig.Validate(); err != nil {
+			log.Fatalf("invalid network policy config: %v", err)
+		}
+		if enforcer, err := netpol.NewNFTables(); err != nil {
+			log.Printf("network policies are not enforced: %v", err)
+		} else {
+			controller := netpol.New(netpol.NewMasterSource(*masterURL), enforcer, policyConfig)
+			policyFilter = controller
+			go controller.Run(networkPolicyCtx)
+			log.Printf("enforcing network policies with nftables")
+		}
+	}
+
+	if *proxyMode != "none" {
+		proxyConfig := proxyDefaults
+		proxyConfig.Mode = proxy.Mode(*proxyMode)
+		proxyConfig.SyncInterval = *proxySyncInterval
+		proxyConfig.Interface = *proxyInterface
+		serviceProxy, err := newServiceProxy(proxyConfig, *masterURL, policyFilter)
+		if err != nil {
+			log.Fatalf("invalid service proxy: %v", err)
+		}
+		workerAgent.SetServiceProxy(serviceProxy)
+		log.Printf("proxying service ClusterIPs in %s mode", proxyConfig.Mode)
+	}
+
+	dnsServer := *clusterDNS
+	if dnsServer == "" {
+		dnsServer = defaultClusterDNS(*masterURL)
+	}
+	if dnsServer != "" && dnsServer != "none" {
+		if net.ParseIP(dnsServer) == nil {
+			log.Fatalf("invalid -cluster-dns %q: must be an IP address", dnsServer)
+		}
+		workerAgent.SetClusterDNS(dnsServer, *clusterDomain)
+		log.Printf("pods resolve %s names through %s", *clusterDomain, dnsServer)
+	}
+
+	ingressCtx, stopIngress := context.WithCancel(context.Background())
+	defer stopIngress()
+	if *ingressAddr != "" {
+		ingressConfig := ingress.DefaultConfig()
+		ingressConfig.Addr = *ingressAddr
+		if err := ingressConfig.Validate(); err != nil {
+			log.Fatalf("invalid ingress gateway config: %v", err)
+		}
+		gateway := ingress.NewGateway(ingress.NewMasterSource(*masterURL), ingressConfig)
+		go func() {
+			if err := gateway.ListenAndServe(ingressCtx); err != nil {
+				log.Printf("ingress gateway disabled: %v", err)
+			}
+		}()
+		log.Printf("serving ingresses on %s", *ingressAddr)
+	}
+
+	if *overlayMode != "vxlan" && *overlayMode != "none" {
+		log.Fatalf("unknown -overlay %q (expected vxlan or none)", *overlayMode)
+	}
+	if *nodeIP == "" {
+		*nodeIP = defaultNodeIP(*masterURL)
+	}
+	podNetworkConfig := agent.DefaultPodNetworkConfig()
+	podNetworkConfig.InternalIP = *nodeIP
+	podNetworkConfig.SubnetPrefix = *podSubnetPrefix
+	if *overlayMode == "vxlan" {
+		podNetworkConfig.MTU = overlayDefaults.MTU
+	}
+	if err := workerAgent.SetPodNetworkConfig(podNetworkConfig); err != nil {
+		log.Fatalf("invalid pod network config: %v", err)
+	}
+
 	log.Printf("registering worker with master at %s", *masterURL)
 	if err := workerAgent.Register(*hostname, *port); err != nil {
 		log.Fatalf("failed to register with master: %v", err)
 	}
 
+	overlayCtx, stopOverlay := context.WithCancel(context.Background())
+	defer stopOverlay()
+	if *overlayMode == "vxlan" && workerAgent.PodCIDR() != "" && *nodeIP == "" {
+		log.Printf("overlay disabled: the master is reached over loopback, set -node-ip to enable it")
+	} else if *overlayMode == "vxlan" && workerAgent.PodCIDR() != "" {
+		overlayConfig := overlayDefaults
+		overlayConfig.ClusterCIDR = *clusterCIDR
+		overlayConfig.NodeID = workerAgent.NodeID()
+		overlayConfig.PodCIDR = workerAgent.PodCIDR()
+		overlayConfig.LocalIP = *nodeIP
+		if err := overlayConfig.Validate(); err != nil {
+			log.Fatalf("invalid overlay config: %v", err)
+		}
+		go overlay.NewVXLAN(overlay.NewMasterSource(*masterURL), overlayConfig).Run(overlayCtx)
+		log.Printf("routing pod subnets of %s to other nodes over VXLAN from %s", *clusterCIDR, *nodeIP)
+	}
+
+	recoverCtx, recoverCancel := context.WithTimeout(context.Background(), time.Minute)
+	if _, err := workerAgent.Recover(recoverCtx); err != nil {
+		log.Printf("warning: failed to recover containers from a previous run: %v", err)
+	}
+	recoverCancel()
+
 	workerAgent.Start(*heartbeatInterval)
 
-	server := agent.NewServer(*nodeID, *hostname, *port, workerAgent)
+	server := agent.NewServer(workerAgent.NodeID(), *hostname, *port, workerAgent)
 
 	e := echo.New()
 	e.Use(middleware.Logger())
@@ -56,7 +315,7 @@ func main() {
 				http.StatusOK, map[string]string{
 					"status":  "ok",
 					"service": "podling-worker",
-					"nodeId":  *nodeID,
+					"nodeId":  workerAgent.NodeID(),
 				},
 			)
 		},
@@ -66,7 +325,7 @@ func main() {
 
 	go func() {
 		addr := fmt.Sprintf(":%d", *port)
-		log.Printf("worker starting on %s (node: %s)", addr, *nodeID)
+		log.Printf("worker starting on %s (node: %s)", addr, workerAgent.NodeID())
 		if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			e.Logger.Fatal("shutting down the server")
 		}
@@ -86,6 +345,10 @@ func main() {
 		log.Printf("warning: agent shutdown error: %v", err)
 	}
 
+	stopIngress()
+	stopOverlay()
+	stopNetworkPolicies()
+
 	log.Println("shutting down HTTP server...")
 	if err := e.Shutdown(ctx); err != nil {
 		log.Printf("error during server shutdown: %v", err)
@@ -93,3 +356,123 @@ func main() {
 
 	log.Println("worker stopped gracefully")
 }
+
+// newRuntime creates the container runtime selected on the command line
+func newRuntime(name, criEndpoint, crictlPath string) (runtime.Runtime, error) {
+	switch name {
+	case "docker":
+		return docker.NewClient()
+	case "cri":
+		return cri.NewRuntime(criEndpoint, crictlPath)
+	case "fake":
+		return runtime.NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown runtime %q (expected docker, cri or fake)", name)
+	}
+}
+
+// newLogSink creates the log sink selected on the command line; "none" disables log shipping
+func newLogSink(name, masterURL, sinkURL, dir, maxSize string, maxAge time.Duration) (logship.Sink, error) {
+	switch name {
+	case "master":
+		return logship.NewMasterSink(masterURL), nil
+	case "loki":
+		if sinkURL == "" {
+			return nil, errors.New("-log-sink-url is required with -log-sink loki")
+		}
+		return logship.NewLokiSink(sinkURL), nil
+	case "file":
+		maxBytes, err := types.ParseMemory(maxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -log-max-size-per-pod: %w", err)
+		}
+		sink, err := logship.NewFileSink(dir, maxBytes, maxAge)
+		if err != nil {
+			return nil, err
+		}
+		return sink, nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink %q (expected master, file, loki or none)", name)
+	}
+}
+
+// newServiceProxy creates the service proxy for the configured mode. In userspace mode, relayed
+// connections must pass filter if it is not nil.
+func newServiceProxy(config proxy.Config, masterURL string, filter proxy.Filter) (*proxy.Proxy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var proxier proxy.Proxier
+	switch config.Mode {
+	case proxy.ModeNFTables:
+		nft, err := proxy.NewNFTables()
+		if err != nil {
+			return nil, err
+		}
+		proxier = nft
+	default:
+		addresses := proxy.NewLinkAddresses(config.Interface)
+		userspace := proxy.NewUserspace(addresses, config.DialTimeout, config.UDPIdleTimeout)
+		if filter != nil {
+			userspace.SetFilter(filter)
+		}
+		proxier = userspace
+	}
+	return proxy.New(proxy.NewMasterSource(masterURL), proxier, config.SyncInterval), nil
+}
+
+// defaultClusterDNS returns the IP of the master, which serves cluster DNS, or "" if it only
+// resolves to loopback addresses that pod containers cannot reach
+func defaultClusterDNS(masterURL string) string {
+	u, err := url.Parse(masterURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil {
+		return ""
+	}
+	for _, ip := range ips {
+		if !ip.IsLoopback() && ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// defaultNodeIP returns the local address the node uses to reach the master, or "" if it cannot
+// be determined
+func defaultNodeIP(masterURL string) string {
+	u, err := url.Parse(masterURL)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+	}
+	// Connecting a UDP socket only selects the route; no packets are sent
+	conn, err := net.Dial("udp4", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = conn.Close() }()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || addr.IP.IsLoopback() {
+		return ""
+	}
+	return addr.IP.String()
+}
+
+// defaultStateDir returns the per-user worker state directory
+func defaultStateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "podling-worker")
+	}
+	return filepath.Join(home, ".podling", "worker")
+}
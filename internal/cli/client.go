@@ -2,14 +2,17 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/stream"
 	"github.com/danpasecinic/podling/internal/types"
 )
 
@@ -209,6 +212,41 @@ func (c *Client) GetNode(nodeID string) (*types.Node, error) {
 	return nil, fmt.Errorf("node %s not found", nodeID)
 }
 
+// CordonNode marks a node unschedulable
+func (c *Client) CordonNode(nodeID string) (*types.Node, error) {
+	return c.setNodeSchedulable(nodeID, "cordon")
+}
+
+// UncordonNode makes a cordoned node schedulable again
+func (c *Client) UncordonNode(nodeID string) (*types.Node, error) {
+	return c.setNodeSchedulable(nodeID, "uncordon")
+}
+
+func (c *Client) setNodeSchedulable(nodeID, action string) (*types.Node, error) {
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/api/v1/nodes/"+nodeID+"/"+action, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var node types.Node
+	if err := json.NewDecoder(resp.Body).Decode(&node); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &node, nil
+}
+
 func (c *Client) GetTaskLogs(task *types.Task, tail int) (string, error) {
 	// Get the node to find the worker URL
 	nodes, err := c.ListNodes()
@@ -254,11 +292,134 @@ func (c *Client) GetTaskLogs(task *types.Task, tail int) (string, error) {
 	return logs, nil
 }
 
+// LogOptions selects the lines of a log stream
+type LogOptions struct {
+	// Container selects a pod container; empty streams all containers
+	Container string
+	Follow    bool
+	// Since is a duration like 10m or an RFC 3339 time
+	Since      string
+	Tail       int
+	Timestamps bool
+}
+
+// StreamTaskLogs opens a task's newline-delimited JSON log stream through the master.
+// The caller must close the returned stream.
+func (c *Client) StreamTaskLogs(taskID string, opts LogOptions) (io.ReadCloser, error) {
+	return c.streamLogs("/api/v1/tasks/"+taskID+"/logs/stream", opts)
+}
+
+// StreamPodLogs opens a pod's newline-delimited JSON log stream through the master.
+// The caller must close the returned stream.
+func (c *Client) StreamPodLogs(podID string, opts LogOptions) (io.ReadCloser, error) {
+	return c.streamLogs("/api/v1/pods/"+podID+"/logs/stream", opts)
+}
+
+func (c *Client) streamLogs(path string, opts LogOptions) (io.ReadCloser, error) {
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.Since != "" {
+		query.Set("since", opts.Since)
+	}
+	if opts.Tail > 0 {
+		query.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+
+	// Followed streams stay open indefinitely, so the client timeout does not apply
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Get(c.baseURL + path + "?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// LogSearchOptions selects the retained log lines returned by SearchLogs
+type LogSearchOptions struct {
+	// Selector is a label selector like app=web,tier=frontend
+	Selector string
+	// Grep is a regular expression lines must match
+	Grep      string
+	PodID     string
+	Container string
+	Namespace string
+	// Since is a duration like 10m or an RFC 3339 time
+	Since string
+	// Limit keeps the most recent lines; zero uses the master's default
+	Limit int
+}
+
+// SearchLogs returns the log lines the master retained across pods, including terminated ones, oldest first
+func (c *Client) SearchLogs(opts LogSearchOptions) ([]types.LogRecord, error) {
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"selector":  opts.Selector,
+		"grep":      opts.Grep,
+		"pod":       opts.PodID,
+		"container": opts.Container,
+		"namespace": opts.Namespace,
+		"since":     opts.Since,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/logs?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var records []types.LogRecord
+	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return records, nil
+}
+
 // CreatePod creates a new pod with the given containers
 func (c *Client) CreatePod(name, namespace string, labels map[string]string, containers []types.Container) (
 	*types.Pod,
 	error,
 ) {
+	return c.CreatePodWithOptions(name, namespace, labels, containers, PodOptions{})
+}
+
+// PodOptions holds optional pod-level settings for CreatePodWithOptions
+type PodOptions struct {
+	ImagePullSecrets []string
+	SecurityContext  *types.SecurityContext
+}
+
+// CreatePodWithOptions creates a new pod with additional pod-level settings
+func (c *Client) CreatePodWithOptions(
+	name, namespace string, labels map[string]string, containers []types.Container, opts PodOptions,
+) (*types.Pod, error) {
 	payload := map[string]interface{}{
 		"name":       name,
 		"containers": containers,
@@ -272,6 +433,14 @@ func (c *Client) CreatePod(name, namespace string, labels map[string]string, con
 		payload["labels"] = labels
 	}
 
+	if len(opts.ImagePullSecrets) > 0 {
+		payload["imagePullSecrets"] = opts.ImagePullSecrets
+	}
+
+	if opts.SecurityContext != nil {
+		payload["securityContext"] = opts.SecurityContext
+	}
+
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -408,7 +577,7 @@ func (c *Client) DeletePod(podID string) error {
 // CreateService creates a new service
 func (c *Client) CreateService(
 	name, namespace string, selector map[string]string, ports []types.ServicePort, labels map[string]string,
-	serviceType, sessionAffinity string,
+	serviceType, clusterIP, externalName, sessionAffinity string, sessionAffinityTimeout int, loadBalancing string,
 ) (*types.Service, error) {
 	payload := map[string]interface{}{
 		"name":     name,
@@ -428,10 +597,26 @@ func (c *Client) CreateService(
 		payload["type"] = serviceType
 	}
 
+	if clusterIP != "" {
+		payload["clusterIp"] = clusterIP
+	}
+
+	if externalName != "" {
+		payload["externalName"] = externalName
+	}
+
 	if sessionAffinity != "" {
 		payload["sessionAffinity"] = sessionAffinity
 	}
 
+	if sessionAffinityTimeout > 0 {
+		payload["sessionAffinityTimeoutSeconds"] = sessionAffinityTimeout
+	}
+
+	if loadBalancing != "" {
+		payload["loadBalancing"] = loadBalancing
+	}
+
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -600,3 +785,458 @@ func (c *Client) PruneAll() (*types.PruneResult, error) {
 
 	return &result, nil
 }
+
+// CreateSecret creates a new secret
+func (c *Client) CreateSecret(
+	name, namespace string, secretType types.SecretType, data map[string]string,
+) (*types.Secret, error) {
+	payload := map[string]interface{}{
+		"name": name,
+		"type": secretType,
+		"data": data,
+	}
+
+	if namespace != "" {
+		payload["namespace"] = namespace
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/api/v1/secrets", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("post request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var secret types.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &secret, nil
+}
+
+// ListSecrets retrieves all secrets, optionally filtered by namespace
+func (c *Client) ListSecrets(namespace string) ([]types.Secret, error) {
+	url := c.baseURL + "/api/v1/secrets"
+	if namespace != "" {
+		url += "?namespace=" + namespace
+	}
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var secrets []types.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&secrets); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// DeleteSecret deletes a secret by ID
+func (c *Client) DeleteSecret(secretID string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/api/v1/secrets/"+secretID, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// CreateNetworkPolicy creates a new network policy
+func (c *Client) CreateNetworkPolicy(policy types.NetworkPolicy) (*types.NetworkPolicy, error) {
+	payload := map[string]interface{}{
+		"name":        policy.Name,
+		"podSelector": policy.PodSelector,
+	}
+
+	if policy.Namespace != "" {
+		payload["namespace"] = policy.Namespace
+	}
+	if len(policy.PolicyTypes) > 0 {
+		payload["policyTypes"] = policy.PolicyTypes
+	}
+	if len(policy.Ingress) > 0 {
+		payload["ingress"] = policy.Ingress
+	}
+	if len(policy.Egress) > 0 {
+		payload["egress"] = policy.Egress
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/api/v1/networkpolicies", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("post request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var created types.NetworkPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &created, nil
+}
+
+// ListNetworkPolicies retrieves all network policies, optionally filtered by namespace
+func (c *Client) ListNetworkPolicies(namespace string) ([]types.NetworkPolicy, error) {
+	url := c.baseURL + "/api/v1/networkpolicies"
+	if namespace != "" {
+		url += "?namespace=" + namespace
+	}
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var policies []types.NetworkPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&policies); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return policies, nil
+}
+
+// GetNetworkPolicy retrieves a network policy by ID
+func (c *Client) GetNetworkPolicy(policyID string) (*types.NetworkPolicy, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/networkpolicies/" + policyID)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var policy types.NetworkPolicy
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// DeleteNetworkPolicy deletes a network policy by ID
+func (c *Client) DeleteNetworkPolicy(policyID string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/api/v1/networkpolicies/"+policyID, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// CreateIngress creates a new ingress
+func (c *Client) CreateIngress(
+	name, namespace string, rules []types.IngressRule, defaultBackend *types.IngressBackend, tls []types.IngressTLS,
+) (*types.Ingress, error) {
+	payload := map[string]interface{}{
+		"name":  name,
+		"rules": rules,
+	}
+
+	if namespace != "" {
+		payload["namespace"] = namespace
+	}
+	if defaultBackend != nil {
+		payload["defaultBackend"] = defaultBackend
+	}
+	if len(tls) > 0 {
+		payload["tls"] = tls
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/api/v1/ingresses", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("post request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var ingress types.Ingress
+	if err := json.NewDecoder(resp.Body).Decode(&ingress); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &ingress, nil
+}
+
+// ListIngresses retrieves all ingresses, optionally filtered by namespace
+func (c *Client) ListIngresses(namespace string) ([]types.Ingress, error) {
+	url := c.baseURL + "/api/v1/ingresses"
+	if namespace != "" {
+		url += "?namespace=" + namespace
+	}
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var ingresses []types.Ingress
+	if err := json.NewDecoder(resp.Body).Decode(&ingresses); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return ingresses, nil
+}
+
+// GetIngress retrieves an ingress by ID
+func (c *Client) GetIngress(ingressID string) (*types.Ingress, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/ingresses/" + ingressID)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var ingress types.Ingress
+	if err := json.NewDecoder(resp.Body).Decode(&ingress); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &ingress, nil
+}
+
+// DeleteIngress deletes an ingress by ID
+func (c *Client) DeleteIngress(ingressID string) error {
+	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/api/v1/ingresses/"+ingressID, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// GetPodStats retrieves the measured resource usage of a pod's containers
+func (c *Client) GetPodStats(podID string) (*types.PodStats, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/pods/" + podID + "/stats")
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var stats types.PodStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ListPodMetrics retrieves the usage of all running pods next to their requests and limits
+func (c *Client) ListPodMetrics() ([]types.PodMetrics, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/metrics/pods")
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var metrics []types.PodMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// ListNodeMetrics retrieves the usage of all nodes next to their requested resources and capacity
+func (c *Client) ListNodeMetrics() ([]types.NodeMetrics, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/v1/metrics/nodes")
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var metrics []types.NodeMetrics
+	if err := json.NewDecoder(resp.Body).Decode(&metrics); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// ExecPod opens an exec stream to a command in a pod container through the master.
+// An empty container name selects the pod's first container.
+func (c *Client) ExecPod(
+	podID, containerName string, command []string, tty, stdin bool,
+) (io.ReadWriteCloser, error) {
+	query := url.Values{"command": command}
+	if containerName != "" {
+		query.Set("container", containerName)
+	}
+	if tty {
+		query.Set("tty", "true")
+	}
+	if stdin {
+		query.Set("stdin", "true")
+	}
+
+	return stream.Dial(
+		context.Background(), http.MethodPost, c.baseURL+"/api/v1/pods/"+podID+"/exec?"+query.Encode(),
+	)
+}
+
+// PortForwardPod opens a stream carrying a TCP connection to a port on the pod's network IP through the master
+func (c *Client) PortForwardPod(podID string, port int) (io.ReadWriteCloser, error) {
+	query := url.Values{"port": {strconv.Itoa(port)}}
+	return stream.Dial(
+		context.Background(), http.MethodPost, c.baseURL+"/api/v1/pods/"+podID+"/portforward?"+query.Encode(),
+	)
+}
+
+// DownloadPodFiles returns a tar archive of a file or directory in a pod container.
+// An empty container selects the pod's first container. The caller must close the archive.
+func (c *Client) DownloadPodFiles(podID, container, srcPath string) (io.ReadCloser, error) {
+	query := url.Values{"path": {srcPath}}
+	if container != "" {
+		query.Set("container", container)
+	}
+
+	// Archives can be large, so the client timeout does not apply
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Get(c.baseURL + "/api/v1/pods/" + podID + "/files?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// UploadPodFiles extracts a tar archive into a directory of a pod container.
+// An empty container selects the pod's first container.
+func (c *Client) UploadPodFiles(podID, container, dstDir string, archive io.Reader) error {
+	query := url.Values{"path": {dstDir}}
+	if container != "" {
+		query.Set("container", container)
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPut, c.baseURL+"/api/v1/pods/"+podID+"/files?"+query.Encode(), archive,
+	)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("put request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -908,7 +908,8 @@ func TestClient_CreateService(t *testing.T) {
 
 				client := NewClient(server.URL)
 				service, err := client.CreateService(
-					tt.serviceName, tt.namespace, tt.selector, tt.ports, tt.labels, tt.serviceType, tt.sessionAff,
+					tt.serviceName, tt.namespace, tt.selector, tt.ports, tt.labels, tt.serviceType, "", "", tt.sessionAff, 0,
+					"",
 				)
 
 				if (err != nil) != tt.wantErr {
@@ -1513,3 +1514,224 @@ func TestClient_DeletePod(t *testing.T) {
 		)
 	}
 }
+
+func TestClient_CordonNode(t *testing.T) {
+	tests := []struct {
+		name       string
+		cordon     bool
+		statusCode int
+		wantPath   string
+		wantErr    bool
+	}{
+		{
+			name:       "cordon",
+			cordon:     true,
+			statusCode: http.StatusOK,
+			wantPath:   "/api/v1/nodes/worker-1/cordon",
+		},
+		{
+			name:       "uncordon",
+			statusCode: http.StatusOK,
+			wantPath:   "/api/v1/nodes/worker-1/uncordon",
+		},
+		{
+			name:       "node not found",
+			cordon:     true,
+			statusCode: http.StatusNotFound,
+			wantPath:   "/api/v1/nodes/worker-1/cordon",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							if r.URL.Path != tt.wantPath {
+								t.Errorf("unexpected path: %s, want %s", r.URL.Path, tt.wantPath)
+							}
+							if r.Method != http.MethodPost {
+								t.Errorf("unexpected method: %s", r.Method)
+							}
+
+							w.WriteHeader(tt.statusCode)
+							_ = json.NewEncoder(w).Encode(types.Node{NodeID: "worker-1", Unschedulable: tt.cordon})
+						},
+					),
+				)
+				defer server.Close()
+
+				client := NewClient(server.URL)
+				var node *types.Node
+				var err error
+				if tt.cordon {
+					node, err = client.CordonNode("worker-1")
+				} else {
+					node, err = client.UncordonNode("worker-1")
+				}
+
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && node.Unschedulable != tt.cordon {
+					t.Errorf("Unschedulable = %v, want %v", node.Unschedulable, tt.cordon)
+				}
+			},
+		)
+	}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "successful request", statusCode: http.StatusOK},
+		{name: "server error", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							w.WriteHeader(tt.statusCode)
+							switch r.URL.Path {
+							case "/api/v1/metrics/pods":
+								_ = json.NewEncoder(w).Encode(
+									[]types.PodMetrics{{PodID: "pod-1", Usage: types.ResourceList{CPU: 100}}},
+								)
+							case "/api/v1/metrics/nodes":
+								_ = json.NewEncoder(w).Encode(
+									[]types.NodeMetrics{{NodeID: "worker-1", Usage: types.ResourceList{CPU: 200}}},
+								)
+							case "/api/v1/pods/pod-1/stats":
+								_ = json.NewEncoder(w).Encode(
+									types.PodStats{PodID: "pod-1", Containers: []types.ContainerStats{{Name: "app"}}},
+								)
+							default:
+								t.Errorf("unexpected path: %s", r.URL.Path)
+							}
+						},
+					),
+				)
+				defer server.Close()
+
+				client := NewClient(server.URL)
+
+				pods, err := client.ListPodMetrics()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ListPodMetrics() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				nodes, err := client.ListNodeMetrics()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ListNodeMetrics() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				stats, err := client.GetPodStats("pod-1")
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("GetPodStats() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+
+				if len(pods) != 1 || pods[0].Usage.CPU != 100 {
+					t.Errorf("ListPodMetrics() = %+v", pods)
+				}
+				if len(nodes) != 1 || nodes[0].Usage.CPU != 200 {
+					t.Errorf("ListNodeMetrics() = %+v", nodes)
+				}
+				if len(stats.Containers) != 1 || stats.Containers[0].Name != "app" {
+					t.Errorf("GetPodStats() = %+v", stats)
+				}
+			},
+		)
+	}
+}
+
+func TestClient_StreamLogs(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("container") == "db" {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":"container db not found in pod pod-1"}`))
+					return
+				}
+				gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+				_, _ = w.Write([]byte(`{"container":"app","stream":"stdout","line":"hello"}` + "\n"))
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	logs, err := client.StreamPodLogs(
+		"pod-1", LogOptions{Container: "app", Follow: true, Since: "5m", Tail: 10, Timestamps: true},
+	)
+	if err != nil {
+		t.Fatalf("StreamPodLogs() error = %v", err)
+	}
+	_ = logs.Close()
+	if gotPath != "/api/v1/pods/pod-1/logs/stream" {
+		t.Errorf("path = %s", gotPath)
+	}
+	if gotQuery != "container=app&follow=true&since=5m&tail=10&timestamps=true" {
+		t.Errorf("query = %s", gotQuery)
+	}
+
+	logs, err = client.StreamTaskLogs("task-1", LogOptions{})
+	if err != nil {
+		t.Fatalf("StreamTaskLogs() error = %v", err)
+	}
+	_ = logs.Close()
+	if gotPath != "/api/v1/tasks/task-1/logs/stream" || gotQuery != "" {
+		t.Errorf("request = %s?%s", gotPath, gotQuery)
+	}
+
+	if _, err := client.StreamPodLogs("pod-1", LogOptions{Container: "db"}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
+
+func TestClient_SearchLogs(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/logs" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				if r.URL.Query().Get("grep") == "(" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":"invalid grep pattern"}`))
+					return
+				}
+				gotQuery = r.URL.RawQuery
+				_, _ = w.Write([]byte(`[{"podId":"pod-1","podName":"web","container":"app","stream":"stdout","line":"hi"}]`))
+			},
+		),
+	)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	records, err := client.SearchLogs(LogSearchOptions{Selector: "app=web", Grep: "error", Since: "1h", Limit: 50})
+	if err != nil {
+		t.Fatalf("SearchLogs() error = %v", err)
+	}
+	if gotQuery != "grep=error&limit=50&selector=app%3Dweb&since=1h" {
+		t.Errorf("query = %s", gotQuery)
+	}
+	if len(records) != 1 || records[0].PodName != "web" || records[0].Line != "hi" {
+		t.Errorf("records = %+v", records)
+	}
+
+	if _, err := client.SearchLogs(LogSearchOptions{Grep: "("}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
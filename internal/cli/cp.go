@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var cpContainer string
+
+// copySpec is one side of a copy: a local path, or a path in a pod when PodID is set
+type copySpec struct {
+	PodID string
+	Path  string
+}
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <src> <dst>",
+	Short: "Copy files and directories to and from pod containers",
+	Long: `Copy files and directories between the local machine and a pod container. A path in a pod is
+written as <pod-id>:<path>. Files are transferred as tar archives through the master and the pod's worker.
+
+When the destination is an existing directory, or ends with a slash, the source is copied into it;
+otherwise the source is copied to the destination path.
+
+Examples:
+  # Pull a core dump from the pod's first container
+  podling cp <pod-id>:/tmp/core.1234 ./core.1234
+
+  # Push a config file into the app container
+  podling cp ./app.yaml <pod-id>:/etc/app/app.yaml -c app
+
+  # Copy a directory into /srv in the pod
+  podling cp ./static <pod-id>:/srv/
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src, dst := parseCopySpec(args[0]), parseCopySpec(args[1])
+		client := NewClient(GetMasterURL())
+
+		switch {
+		case src.PodID != "" && dst.PodID == "":
+			return copyFromPod(client, src, dst.Path)
+		case src.PodID == "" && dst.PodID != "":
+			return copyToPod(client, src.Path, dst)
+		default:
+			return fmt.Errorf("exactly one of the source and destination must be a pod path (<pod-id>:<path>)")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+
+	cpCmd.Flags().StringVarP(&cpContainer, "container", "c", "", "container name (defaults to the first container)")
+}
+
+// parseCopySpec splits <pod-id>:<path>; arguments without a pod ID before the colon are local paths
+func parseCopySpec(arg string) copySpec {
+	podID, podPath, ok := strings.Cut(arg, ":")
+	if !ok || podID == "" || strings.ContainsAny(podID, `/\`) || filepath.VolumeName(arg) != "" {
+		return copySpec{Path: arg}
+	}
+	return copySpec{PodID: podID, Path: podPath}
+}
+
+// copyFromPod downloads a path from a pod container and extracts it locally
+func copyFromPod(client *Client, src copySpec, dst string) error {
+	if src.Path == "" {
+		return fmt.Errorf("a path in the pod is required")
+	}
+
+	archive, err := client.DownloadPodFiles(src.PodID, cpContainer, src.Path)
+	if err != nil {
+		return fmt.Errorf("failed to copy from pod: %w", err)
+	}
+	defer func() { _ = archive.Close() }()
+
+	return extractArchive(archive, path.Base(path.Clean(src.Path)), dst)
+}
+
+// copyToPod archives a local path and uploads it to a pod container
+func copyToPod(client *Client, src string, dst copySpec) error {
+	if _, err := os.Lstat(src); err != nil {
+		return err
+	}
+
+	dstDir, name := path.Dir(dst.Path), path.Base(dst.Path)
+	if dst.Path == "" || strings.HasSuffix(dst.Path, "/") {
+		dstDir, name = dst.Path, filepath.Base(src)
+		if dstDir == "" {
+			dstDir = "."
+		}
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(createArchive(writer, src, name))
+	}()
+	defer func() { _ = reader.Close() }()
+
+	if err := client.UploadPodFiles(dst.PodID, cpContainer, dstDir, reader); err != nil {
+		return fmt.Errorf("failed to copy to pod: %w", err)
+	}
+	return nil
+}
+
+// createArchive writes a tar archive of the file or directory at src with its root entry named name
+func createArchive(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(
+		src, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(src, file)
+			if err != nil {
+				return err
+			}
+
+			var link string
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(file); err != nil {
+					return err
+				}
+			}
+			header, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			header.Name = path.Join(name, filepath.ToSlash(rel))
+			if info.IsDir() {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = f.Close() }()
+			_, err = io.Copy(tw, f)
+			return err
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", src, err)
+	}
+	return tw.Close()
+}
+
+// extractArchive extracts a tar archive whose root entry is named srcBase. The root is placed inside
+// dst when dst is an existing directory or ends with a separator, and renamed to dst otherwise.
+// The archive comes from a container and is not trusted: entries and symbolic link targets must
+// stay inside the destination, and nothing is written through a symbolic link.
+func extractArchive(r io.Reader, srcBase, dst string) error {
+	root, rename := dst, ""
+	info, err := os.Stat(dst)
+	intoDir := (err == nil && info.IsDir()) || strings.HasSuffix(dst, "/") ||
+		strings.HasSuffix(dst, string(filepath.Separator))
+	if !intoDir {
+		root, rename = filepath.Dir(dst), filepath.Base(dst)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := path.Clean(header.Name)
+		if rename != "" {
+			first, rest, _ := strings.Cut(name, "/")
+			if first == srcBase {
+				name = path.Join(rename, rest)
+			}
+		}
+		if !insideDestination(name, rename) {
+			return fmt.Errorf("archive entry %q is outside the destination", header.Name)
+		}
+
+		if header.Typeflag == tar.TypeSymlink {
+			if path.IsAbs(header.Linkname) || filepath.IsAbs(header.Linkname) ||
+				!insideDestination(path.Join(path.Dir(name), header.Linkname), rename) {
+				return fmt.Errorf("archive link %q points outside the destination: %q", header.Name, header.Linkname)
+			}
+			// The link itself is replaced, so only its parents must not be links
+			if err := checkNoSymlinks(root, path.Dir(name)); err != nil {
+				return err
+			}
+		} else if err := checkNoSymlinks(root, name); err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if err := extractEntry(tr, header, target); err != nil {
+			return err
+		}
+	}
+}
+
+// insideDestination reports whether a cleaned slash-separated path relative to the extraction
+// root stays inside the destination: the root itself, or the renamed entry when base is set
+func insideDestination(name, base string) bool {
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return false
+	}
+	return base == "" || name == base || strings.HasPrefix(name, base+"/")
+}
+
+// checkNoSymlinks returns an error if name or one of its parents below root is a symbolic link,
+// such as one extracted from an earlier entry, since writing there would follow the link
+func checkNoSymlinks(root, name string) error {
+	current := root
+	for _, part := range strings.Split(name, "/") {
+		if part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", current, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %q would be written through the symbolic link %s", name, current)
+		}
+	}
+	return nil
+}
+
+// extractEntry creates a directory, regular file or symbolic link from an archive entry
+func extractEntry(tr *tar.Reader, header *tar.Header, target string) error {
+	mode := os.FileMode(header.Mode).Perm()
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(target, mode|0o700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	case tar.TypeSymlink:
+		_ = os.Remove(target)
+		if err := os.Symlink(header.Linkname, target); err != nil {
+			return fmt.Errorf("failed to create link %s: %w", target, err)
+		}
+	}
+	return nil
+}
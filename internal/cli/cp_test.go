@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCopySpec(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want copySpec
+	}{
+		{name: "pod path", arg: "pod-1:/tmp/core", want: copySpec{PodID: "pod-1", Path: "/tmp/core"}},
+		{name: "pod relative path", arg: "pod-1:app.yaml", want: copySpec{PodID: "pod-1", Path: "app.yaml"}},
+		{name: "local path", arg: "./core", want: copySpec{Path: "./core"}},
+		{name: "local path with colon", arg: "./dumps/a:b", want: copySpec{Path: "./dumps/a:b"}},
+		{name: "leading colon", arg: ":/tmp", want: copySpec{Path: ":/tmp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := parseCopySpec(tt.arg); got != tt.want {
+					t.Errorf("parseCopySpec(%q) = %+v, want %+v", tt.arg, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "conf", "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "conf", "nested", "app.yaml"), []byte("debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := createArchive(&archive, filepath.Join(src, "conf"), "conf"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		dst      func(dir string) string
+		wantFile string
+	}{
+		{
+			name:     "into existing directory",
+			dst:      func(dir string) string { return dir },
+			wantFile: filepath.Join("conf", "nested", "app.yaml"),
+		},
+		{
+			name:     "renamed",
+			dst:      func(dir string) string { return filepath.Join(dir, "settings") },
+			wantFile: filepath.Join("settings", "nested", "app.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				if err := extractArchive(bytes.NewReader(archive.Bytes()), "conf", tt.dst(dir)); err != nil {
+					t.Fatal(err)
+				}
+				data, err := os.ReadFile(filepath.Join(dir, tt.wantFile))
+				if err != nil || string(data) != "debug\n" {
+					t.Fatalf("%s = %q, %v", tt.wantFile, data, err)
+				}
+				info, _ := os.Stat(filepath.Join(dir, tt.wantFile))
+				if info.Mode().Perm() != 0o600 {
+					t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+				}
+			},
+		)
+	}
+}
+
+func TestExtractArchiveRejectsEscapingEntries(t *testing.T) {
+	var archive bytes.Buffer
+	src := filepath.Join(t.TempDir(), "evil")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := createArchive(&archive, src, "../evil"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := extractArchive(&archive, "evil", filepath.Join(dir, "out")); err == nil {
+		t.Fatal("expected an error for an entry outside the destination")
+	}
+}
+
+func TestExtractArchiveSymlinks(t *testing.T) {
+	type entry struct {
+		name     string
+		linkname string // set for symbolic links; "OUTSIDE" is replaced by a directory outside dst
+	}
+
+	tests := []struct {
+		name    string
+		entries []entry
+		// outsideLink creates conf/escape in the destination, pointing outside it, before extracting
+		outsideLink bool
+		wantErr     bool
+	}{
+		{
+			name:    "link inside the destination",
+			entries: []entry{{name: "conf/"}, {name: "conf/app.yaml"}, {name: "conf/current", linkname: "app.yaml"}},
+		},
+		{
+			name:    "absolute link then a file under it",
+			entries: []entry{{name: "conf/"}, {name: "conf/home", linkname: "OUTSIDE"}, {name: "conf/home/.bashrc"}},
+			wantErr: true,
+		},
+		{
+			name:    "relative link out of the destination",
+			entries: []entry{{name: "conf/"}, {name: "conf/up", linkname: "../../.."}, {name: "conf/up/.bashrc"}},
+			wantErr: true,
+		},
+		{
+			name: "file under a link from the archive",
+			entries: []entry{
+				{name: "conf/"}, {name: "conf/sub/"}, {name: "conf/link", linkname: "sub"}, {name: "conf/link/x"},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "file under an existing link",
+			entries:     []entry{{name: "conf/"}, {name: "conf/escape/.bashrc"}},
+			outsideLink: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				outside := t.TempDir()
+				var archive bytes.Buffer
+				tw := tar.NewWriter(&archive)
+				for _, e := range tt.entries {
+					header := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: tar.TypeReg}
+					switch {
+					case e.linkname == "OUTSIDE":
+						header.Typeflag, header.Linkname = tar.TypeSymlink, outside
+					case e.linkname != "":
+						header.Typeflag, header.Linkname = tar.TypeSymlink, e.linkname
+					case e.name[len(e.name)-1] == '/':
+						header.Typeflag, header.Mode = tar.TypeDir, 0o755
+					}
+					if err := tw.WriteHeader(header); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := tw.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				dir := t.TempDir()
+				if tt.outsideLink {
+					if err := os.MkdirAll(filepath.Join(dir, "conf"), 0o755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.Symlink(outside, filepath.Join(dir, "conf", "escape")); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				err := extractArchive(&archive, "conf", dir)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("extractArchive() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+					t.Errorf("expected nothing written outside the destination, found %d entries", len(entries))
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	execContainer string
+	execStdin     bool
+	execTTY       bool
+)
+
+// ExitError reports the non-zero exit code of a command run in a container
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command terminated with exit code %d", e.Code)
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec [pod-id] -- [command] [args...]",
+	Short: "Run a command in a pod container",
+	Long: `Run a command in a running pod container. The connection goes through the master,
+so the worker does not need to be reachable from the client.
+
+Examples:
+  # List files in the pod's first container
+  podling exec <pod-id> -- ls -l /
+
+  # Open an interactive shell in a specific container
+  podling exec <pod-id> -c web -it -- sh
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		podID, command := args[0], args[1:]
+
+		stdinFd := int(os.Stdin.Fd())
+		tty := execTTY
+		if tty && !term.IsTerminal(stdinFd) {
+			fmt.Fprintln(os.Stderr, "Unable to use a TTY - input is not a terminal")
+			tty = false
+		}
+
+		client := NewClient(GetMasterURL())
+		conn, err := client.ExecPod(podID, execContainer, command, tty, execStdin)
+		if err != nil {
+			return fmt.Errorf("failed to exec in pod: %w", err)
+		}
+		defer func() { _ = conn.Close() }()
+
+		var resize chan stream.TerminalSize
+		if tty {
+			oldState, err := term.MakeRaw(stdinFd)
+			if err != nil {
+				return fmt.Errorf("failed to put terminal into raw mode: %w", err)
+			}
+			defer func() { _ = term.Restore(stdinFd, oldState) }()
+
+			resize = make(chan stream.TerminalSize, 1)
+			stop := watchTerminalSize(stdinFd, resize)
+			defer stop()
+		}
+
+		var stdin io.Reader
+		if execStdin {
+			stdin = os.Stdin
+		}
+
+		exitCode, err := runExecSession(conn, stdin, os.Stdout, os.Stderr, resize)
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			cmd.SilenceUsage = true
+			return &ExitError{Code: exitCode}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	execCmd.Flags().StringVarP(&execContainer, "container", "c", "", "container name (defaults to the first container)")
+	execCmd.Flags().BoolVarP(&execStdin, "stdin", "i", false, "pass stdin to the command")
+	execCmd.Flags().BoolVarP(&execTTY, "tty", "t", false, "allocate a terminal for the command")
+}
+
+// runExecSession sends stdin and terminal size changes over an exec stream, writes the command's
+// output to stdout and stderr, and returns the command's exit code once it finishes
+func runExecSession(
+	conn io.ReadWriter, stdin io.Reader, stdout, stderr io.Writer, resize <-chan stream.TerminalSize,
+) (int, error) {
+	frames := stream.NewFrameWriter(conn)
+	done := make(chan struct{})
+	defer close(done)
+
+	if stdin != nil {
+		go func() {
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := stdin.Read(buf)
+				if n > 0 {
+					if frames.WriteFrame(stream.ChannelStdin, buf[:n]) != nil {
+						return
+					}
+				}
+				if err != nil {
+					// An empty frame closes the command's stdin
+					_ = frames.WriteFrame(stream.ChannelStdin, nil)
+					return
+				}
+			}
+		}()
+	}
+
+	if resize != nil {
+		go func() {
+			for {
+				select {
+				case size := <-resize:
+					payload, _ := json.Marshal(size)
+					if frames.WriteFrame(stream.ChannelResize, payload) != nil {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		channel, payload, err := stream.ReadFrame(conn)
+		if err != nil {
+			return -1, fmt.Errorf("exec stream closed before the command finished: %w", err)
+		}
+
+		switch channel {
+		case stream.ChannelStdout:
+			_, _ = stdout.Write(payload)
+		case stream.ChannelStderr:
+			_, _ = stderr.Write(payload)
+		case stream.ChannelStatus:
+			var status stream.ExecStatus
+			if err := json.Unmarshal(payload, &status); err != nil {
+				return -1, fmt.Errorf("failed to decode exec status: %w", err)
+			}
+			if status.Error != "" {
+				return status.ExitCode, errors.New(status.Error)
+			}
+			return status.ExitCode, nil
+		}
+	}
+}
+
+// sendTerminalSize reports the current size of the terminal, dropping it if a report is still pending
+func sendTerminalSize(fd int, sizes chan<- stream.TerminalSize) {
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		return
+	}
+
+	select {
+	case sizes <- stream.TerminalSize{Width: uint16(width), Height: uint16(height)}:
+	default:
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/danpasecinic/podling/internal/stream"
+)
+
+// watchTerminalSize reports the terminal size now and whenever the terminal is resized,
+// until the returned function is called
+func watchTerminalSize(fd int, sizes chan<- stream.TerminalSize) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	done := make(chan struct{})
+
+	sendTerminalSize(fd, sizes)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				sendTerminalSize(fd, sizes)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
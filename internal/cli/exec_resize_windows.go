@@ -0,0 +1,11 @@
+//go:build windows
+
+package cli
+
+import "github.com/danpasecinic/podling/internal/stream"
+
+// watchTerminalSize reports the terminal size once; Windows has no resize signal
+func watchTerminalSize(fd int, sizes chan<- stream.TerminalSize) func() {
+	sendTerminalSize(fd, sizes)
+	return func() {}
+}
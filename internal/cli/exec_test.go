@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/stream"
+)
+
+func TestRunExecSession(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = client.Close() }()
+	defer func() { _ = server.Close() }()
+
+	received := make(chan string, 1)
+	stdinClosed := make(chan struct{})
+	gotResize := make(chan stream.TerminalSize, 1)
+	go func() {
+		var stdin strings.Builder
+		for {
+			channel, payload, err := stream.ReadFrame(server)
+			if err != nil {
+				return
+			}
+			switch {
+			case channel == stream.ChannelResize:
+				var size stream.TerminalSize
+				_ = json.Unmarshal(payload, &size)
+				gotResize <- size
+			case channel == stream.ChannelStdin && len(payload) == 0:
+				received <- stdin.String()
+				close(stdinClosed)
+			default:
+				stdin.Write(payload)
+			}
+		}
+	}()
+	go func() {
+		<-stdinClosed
+
+		frames := stream.NewFrameWriter(server)
+		_ = frames.WriteFrame(stream.ChannelStdout, []byte("out"))
+		_ = frames.WriteFrame(stream.ChannelStderr, []byte("err"))
+		status, _ := json.Marshal(stream.ExecStatus{ExitCode: 2})
+		_ = frames.WriteFrame(stream.ChannelStatus, status)
+	}()
+
+	resize := make(chan stream.TerminalSize, 1)
+	resize <- stream.TerminalSize{Width: 80, Height: 24}
+
+	var stdout, stderr strings.Builder
+	code, err := runExecSession(client, strings.NewReader("input"), &stdout, &stderr, resize)
+	if err != nil {
+		t.Fatalf("runExecSession() error = %v", err)
+	}
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if stdout.String() != "out" || stderr.String() != "err" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+	if got := <-received; got != "input" {
+		t.Errorf("stdin = %q, want input", got)
+	}
+	if size := <-gotResize; size.Width != 80 || size.Height != 24 {
+		t.Errorf("terminal size = %+v, want 80x24", size)
+	}
+
+	if msg := (&ExitError{Code: 2}).Error(); msg != "command terminated with exit code 2" {
+		t.Errorf("ExitError = %q", msg)
+	}
+}
+
+func TestRunExecSessionStreamClosed(t *testing.T) {
+	client, server := net.Pipe()
+	_ = server.Close()
+
+	if _, err := runExecSession(client, nil, &strings.Builder{}, &strings.Builder{}, nil); err == nil {
+		t.Error("expected an error when the stream closes before the exec status")
+	}
+}
@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var ingressCmd = &cobra.Command{
+	Use:   "ingress",
+	Short: "Manage ingresses",
+	Long:  `Create, list, inspect, and delete ingresses that route HTTP traffic to services by host and path.`,
+}
+
+// Ingress command flags
+var (
+	ingressNamespace      string
+	ingressRules          []string
+	ingressDefaultBackend string
+)
+
+var ingressCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a new ingress",
+	Long: `Create a new ingress.
+
+Rules have the form host/path=service:port[,tls=secret]. A path ending in * matches
+by prefix, otherwise it must match exactly; without a path every path of the host
+matches. The port is a service port number or name, and the host may be empty or
+start with *. to match subdomains.
+
+Examples:
+  # Route example.com to the web service and example.com/api/* to the api service
+  podling ingress create site \
+    --rule "example.com/*=web:80" \
+    --rule "example.com/api/*=api:http"
+
+  # Serve example.com over HTTPS with a certificate from the site-tls secret
+  podling ingress create site \
+    --rule "example.com/*=web:80,tls=site-tls"
+
+  # Send every request that matches no rule to a fallback service
+  podling ingress create catch-all --default-backend fallback:80
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		rules, tls, err := buildIngressRules(ingressRules)
+		if err != nil {
+			return err
+		}
+
+		var defaultBackend *types.IngressBackend
+		if ingressDefaultBackend != "" {
+			backend, err := parseIngressBackend(ingressDefaultBackend)
+			if err != nil {
+				return err
+			}
+			defaultBackend = &backend
+		}
+
+		client := NewClient(GetMasterURL())
+		ingress, err := client.CreateIngress(name, ingressNamespace, rules, defaultBackend, tls)
+		if err != nil {
+			return fmt.Errorf("failed to create ingress: %w", err)
+		}
+
+		fmt.Println("Ingress created successfully:")
+		fmt.Printf("  ID:        %s\n", ingress.IngressID)
+		fmt.Printf("  Name:      %s\n", ingress.Name)
+		fmt.Printf("  Namespace: %s\n", ingress.Namespace)
+		fmt.Printf("  Hosts:     %s\n", formatIngressHosts(*ingress))
+
+		return nil
+	},
+}
+
+var ingressListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all ingresses",
+	Long:  `List all ingresses, optionally filtered by namespace.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		ingresses, err := client.ListIngresses(ingressNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to list ingresses: %w", err)
+		}
+
+		if len(ingresses) == 0 {
+			fmt.Println("No ingresses found")
+			return nil
+		}
+
+		fmt.Printf("%-25s %-20s %-15s %-40s %s\n", "INGRESS ID", "NAME", "NAMESPACE", "HOSTS", "TLS")
+		fmt.Println(strings.Repeat("-", 110))
+
+		for _, ingress := range ingresses {
+			tls := "No"
+			if len(ingress.TLS) > 0 {
+				tls = "Yes"
+			}
+
+			fmt.Printf(
+				"%-25s %-20s %-15s %-40s %s\n",
+				ingress.IngressID,
+				truncate(ingress.Name, 20),
+				truncate(ingress.Namespace, 15),
+				truncate(formatIngressHosts(ingress), 40),
+				tls,
+			)
+		}
+
+		return nil
+	},
+}
+
+var ingressGetCmd = &cobra.Command{
+	Use:   "get [ingress-id]",
+	Short: "Get ingress details",
+	Long:  `Get detailed information about a specific ingress and its routes.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		ingress, err := client.GetIngress(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get ingress: %w", err)
+		}
+
+		fmt.Printf("Ingress: %s\n", ingress.Name)
+		fmt.Printf("  ID:         %s\n", ingress.IngressID)
+		fmt.Printf("  Namespace:  %s\n", ingress.Namespace)
+		fmt.Printf("  Created:    %s\n", ingress.CreatedAt.Format("2006-01-02 15:04:05"))
+		if ingress.DefaultBackend != nil {
+			fmt.Printf("  Default:    %s\n", ingress.DefaultBackend)
+		}
+
+		if len(ingress.Rules) > 0 {
+			fmt.Println("\nRules:")
+			for _, rule := range ingress.Rules {
+				host := rule.Host
+				if host == "" {
+					host = "*"
+				}
+				fmt.Printf("  %s\n", host)
+				for _, path := range rule.Paths {
+					fmt.Printf("    %-30s %-7s -> %s\n", path.Path, path.PathType, path.Backend)
+				}
+			}
+		}
+
+		if len(ingress.TLS) > 0 {
+			fmt.Println("\nTLS:")
+			for _, tls := range ingress.TLS {
+				fmt.Printf("  %s (secret: %s)\n", strings.Join(tls.Hosts, ","), tls.SecretName)
+			}
+		}
+
+		return nil
+	},
+}
+
+var ingressDeleteCmd = &cobra.Command{
+	Use:   "delete [ingress-id]",
+	Short: "Delete an ingress",
+	Long:  `Delete an ingress by its ID.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ingressID := args[0]
+
+		client := NewClient(GetMasterURL())
+		if err := client.DeleteIngress(ingressID); err != nil {
+			return fmt.Errorf("failed to delete ingress: %w", err)
+		}
+
+		fmt.Printf("Ingress %s deleted successfully\n", ingressID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ingressCmd)
+
+	ingressCmd.AddCommand(ingressCreateCmd)
+	ingressCmd.AddCommand(ingressListCmd)
+	ingressCmd.AddCommand(ingressGetCmd)
+	ingressCmd.AddCommand(ingressDeleteCmd)
+
+	ingressCreateCmd.Flags().StringVar(&ingressNamespace, "namespace", "default", "Namespace for the ingress")
+	ingressCreateCmd.Flags().StringArrayVar(
+		&ingressRules, "rule", []string{}, "Routing rule host/path=service:port[,tls=secret] (can be repeated)",
+	)
+	ingressCreateCmd.Flags().StringVar(
+		&ingressDefaultBackend, "default-backend", "", "Service (service:port) for requests that match no rule",
+	)
+
+	ingressListCmd.Flags().StringVar(&ingressNamespace, "namespace", "", "Filter by namespace (empty for all)")
+}
+
+// buildIngressRules parses --rule flags into ingress rules grouped by host, in flag order, and
+// the TLS entries they request
+func buildIngressRules(specs []string) ([]types.IngressRule, []types.IngressTLS, error) {
+	var rules []types.IngressRule
+	var tls []types.IngressTLS
+	hostIndex := make(map[string]int)
+	secretIndex := make(map[string]int)
+
+	for _, spec := range specs {
+		host, path, secret, err := parseIngressRule(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		i, ok := hostIndex[host]
+		if !ok {
+			i = len(rules)
+			hostIndex[host] = i
+			rules = append(rules, types.IngressRule{Host: host})
+		}
+		rules[i].Paths = append(rules[i].Paths, path)
+
+		if secret == "" {
+			continue
+		}
+		if host == "" {
+			return nil, nil, fmt.Errorf("invalid rule %s: tls requires a host", spec)
+		}
+		j, ok := secretIndex[secret]
+		if !ok {
+			j = len(tls)
+			secretIndex[secret] = j
+			tls = append(tls, types.IngressTLS{SecretName: secret})
+		}
+		if !slices.Contains(tls[j].Hosts, host) {
+			tls[j].Hosts = append(tls[j].Hosts, host)
+		}
+	}
+
+	return rules, tls, nil
+}
+
+// parseIngressRule parses a rule in the format host/path=service:port[,tls=secret]. A path
+// ending in * is a prefix and any other path must match exactly; a rule without a path matches
+// every path of the host.
+func parseIngressRule(spec string) (string, types.IngressPath, string, error) {
+	route, options, _ := strings.Cut(spec, ",")
+	match, backendSpec, ok := strings.Cut(route, "=")
+	if !ok {
+		return "", types.IngressPath{}, "", fmt.Errorf(
+			"invalid rule %s (expected host/path=service:port[,tls=secret])", spec,
+		)
+	}
+
+	host, path := match, "/*"
+	if i := strings.Index(match, "/"); i >= 0 {
+		host, path = match[:i], match[i:]
+	}
+
+	ingressPath := types.IngressPath{Path: path, PathType: types.PathTypeExact}
+	if strings.HasSuffix(path, "*") {
+		ingressPath.Path = strings.TrimSuffix(path, "*")
+		ingressPath.PathType = types.PathTypePrefix
+	}
+
+	backend, err := parseIngressBackend(backendSpec)
+	if err != nil {
+		return "", types.IngressPath{}, "", fmt.Errorf("invalid rule %s: %w", spec, err)
+	}
+	ingressPath.Backend = backend
+
+	var secret string
+	if options != "" {
+		key, value, _ := strings.Cut(options, "=")
+		if key != "tls" || value == "" {
+			return "", types.IngressPath{}, "", fmt.Errorf("invalid rule option %q (expected tls=secret)", options)
+		}
+		secret = value
+	}
+
+	return host, ingressPath, secret, nil
+}
+
+// parseIngressBackend parses a backend in the format service:port, where port is a number or
+// a port name
+func parseIngressBackend(spec string) (types.IngressBackend, error) {
+	service, port, ok := strings.Cut(spec, ":")
+	if !ok || service == "" || port == "" {
+		return types.IngressBackend{}, fmt.Errorf("invalid backend %q (expected service:port)", spec)
+	}
+
+	backend := types.IngressBackend{ServiceName: service}
+	if p, err := strconv.Atoi(port); err == nil {
+		backend.ServicePort = p
+	} else {
+		backend.ServicePortName = port
+	}
+	return backend, nil
+}
+
+// formatIngressHosts formats the hosts an ingress routes for display
+func formatIngressHosts(ingress types.Ingress) string {
+	var hosts []string
+	for _, rule := range ingress.Rules {
+		host := rule.Host
+		if host == "" {
+			host = "*"
+		}
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return "*"
+	}
+	return strings.Join(hosts, ",")
+}
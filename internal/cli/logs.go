@@ -1,21 +1,58 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"github.com/danpasecinic/podling/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logsTail int
+	logsTail       int
+	logsFollow     bool
+	logsSince      string
+	logsTimestamps bool
+	logsSelector   string
+	logsGrep       string
+	logsContainer  string
+	logsNamespace  string
 )
 
 var logsCmd = &cobra.Command{
 	Use:   "logs [task-id]",
-	Short: "Fetch container logs for a task",
-	Long:  `Fetch and display container logs for a specific task.`,
-	Args:  cobra.ExactArgs(1),
+	Short: "Fetch container logs for a task, or search logs across pods",
+	Long: `Fetch and display container logs for a specific task. Logs are streamed through the master;
+stderr lines are written to stderr.
+
+Without a task ID, search the logs workers shipped to the master across pods, including pods
+that have terminated or whose worker is gone.
+
+Examples:
+  # Follow the task's output
+  podling logs <task-id> -f
+
+  # Lines from the last 10 minutes with their timestamps
+  podling logs <task-id> --since 10m --timestamps
+
+  # Errors logged by any pod labeled app=web
+  podling logs --selector app=web --grep 'error|panic'
+`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			if logsSelector == "" && logsGrep == "" && logsNamespace == "" && logsContainer == "" {
+				return fmt.Errorf("a task ID or a search flag (--selector, --grep, --namespace, --container) is required")
+			}
+			return searchLogs()
+		}
+		if logsSelector != "" || logsGrep != "" {
+			return fmt.Errorf("--selector and --grep search across pods and cannot be used with a task ID")
+		}
 		taskID := args[0]
 
 		client := NewClient(GetMasterURL())
@@ -33,13 +70,15 @@ var logsCmd = &cobra.Command{
 			return fmt.Errorf("task is not running yet (status: %s)", task.Status)
 		}
 
-		logs, err := client.GetTaskLogs(task, logsTail)
+		logs, err := client.StreamTaskLogs(
+			taskID, LogOptions{Follow: logsFollow, Since: logsSince, Tail: logsTail, Timestamps: logsTimestamps},
+		)
 		if err != nil {
 			return fmt.Errorf("failed to get logs: %w", err)
 		}
+		defer func() { _ = logs.Close() }()
 
-		fmt.Print(logs)
-		return nil
+		return printLogStream(logs, os.Stdout, os.Stderr, false)
 	},
 }
 
@@ -47,4 +86,87 @@ func init() {
 	rootCmd.AddCommand(logsCmd)
 
 	logsCmd.Flags().IntVar(&logsTail, "tail", 100, "number of lines to show from the end of the logs")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep streaming new log lines")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "only show lines newer than a duration (10m) or RFC 3339 time")
+	logsCmd.Flags().BoolVar(&logsTimestamps, "timestamps", false, "show the timestamp of each line")
+	logsCmd.Flags().StringVarP(&logsSelector, "selector", "l", "", "search pods matching labels (key=value,...)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "search lines matching a regular expression")
+	logsCmd.Flags().StringVarP(&logsContainer, "container", "c", "", "search only this container's lines")
+	logsCmd.Flags().StringVarP(&logsNamespace, "namespace", "n", "", "search only pods in this namespace")
+}
+
+// searchLogs prints the retained log lines matching the search flags
+func searchLogs() error {
+	client := NewClient(GetMasterURL())
+	records, err := client.SearchLogs(
+		LogSearchOptions{
+			Selector:  logsSelector,
+			Grep:      logsGrep,
+			Container: logsContainer,
+			Namespace: logsNamespace,
+			Since:     logsSince,
+			Limit:     logsTail,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to search logs: %w", err)
+	}
+
+	printLogRecords(records, os.Stdout, os.Stderr, logsTimestamps)
+	return nil
+}
+
+// printLogRecords writes searched log lines prefixed with their pod and container,
+// stdout lines to stdout and stderr lines to stderr
+func printLogRecords(records []types.LogRecord, stdout, stderr io.Writer, timestamps bool) {
+	for _, record := range records {
+		w := stdout
+		if record.Stream == types.LogStreamStderr {
+			w = stderr
+		}
+
+		pod := record.PodName
+		if pod == "" {
+			pod = record.PodID
+		}
+		source := pod
+		if record.Container != "" {
+			source += "/" + record.Container
+		}
+
+		line := record.Line
+		if timestamps && record.Timestamp != nil {
+			line = record.Timestamp.Format(time.RFC3339Nano) + " " + line
+		}
+		_, _ = fmt.Fprintf(w, "[%s] %s\n", source, line)
+	}
+}
+
+// printLogStream writes the entries of a log stream as they arrive, stdout lines to stdout and
+// stderr lines to stderr. With prefix set, lines are prefixed with their container name.
+func printLogStream(logs io.Reader, stdout, stderr io.Writer, prefix bool) error {
+	decoder := json.NewDecoder(logs)
+	for {
+		var entry types.LogEntry
+		if err := decoder.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read log stream: %w", err)
+		}
+
+		w := stdout
+		if entry.Stream == types.LogStreamStderr {
+			w = stderr
+		}
+
+		line := entry.Line
+		if entry.Timestamp != nil {
+			line = entry.Timestamp.Format(time.RFC3339Nano) + " " + line
+		}
+		if prefix && entry.Container != "" {
+			line = "[" + entry.Container + "] " + line
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
 }
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestPrintLogStream(t *testing.T) {
+	logs := strings.Join(
+		[]string{
+			`{"container":"web","stream":"stdout","line":"listening"}`,
+			`{"container":"db","stream":"stderr","timestamp":"2025-06-01T12:00:00Z","line":"slow query"}`,
+			`{"container":"web","stream":"stdout","line":"GET /"}`,
+		}, "\n",
+	)
+
+	tests := []struct {
+		name       string
+		prefix     bool
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "single container",
+			wantStdout: "listening\nGET /\n",
+			wantStderr: "2025-06-01T12:00:00Z slow query\n",
+		},
+		{
+			name:       "all containers",
+			prefix:     true,
+			wantStdout: "[web] listening\n[web] GET /\n",
+			wantStderr: "[db] 2025-06-01T12:00:00Z slow query\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var stdout, stderr strings.Builder
+				if err := printLogStream(strings.NewReader(logs), &stdout, &stderr, tt.prefix); err != nil {
+					t.Fatalf("printLogStream() error = %v", err)
+				}
+				if stdout.String() != tt.wantStdout || stderr.String() != tt.wantStderr {
+					t.Errorf(
+						"stdout = %q, stderr = %q, want %q, %q",
+						stdout.String(), stderr.String(), tt.wantStdout, tt.wantStderr,
+					)
+				}
+			},
+		)
+	}
+
+	if err := printLogStream(strings.NewReader("not json"), &strings.Builder{}, &strings.Builder{}, false); err == nil {
+		t.Error("expected an error for a malformed stream")
+	}
+}
+
+func TestPrintLogRecords(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	records := []types.LogRecord{
+		{PodID: "pod-1", PodName: "web", LogEntry: types.LogEntry{Container: "app", Stream: "stdout", Line: "up"}},
+		{PodID: "pod-2", LogEntry: types.LogEntry{Container: "app", Stream: "stderr", Timestamp: &ts, Line: "boom"}},
+	}
+
+	var stdout, stderr strings.Builder
+	printLogRecords(records, &stdout, &stderr, true)
+
+	if stdout.String() != "[web/app] up\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if stderr.String() != "[pod-2/app] 2025-06-01T12:00:00Z boom\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
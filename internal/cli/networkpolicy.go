@@ -0,0 +1,396 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var networkPolicyCmd = &cobra.Command{
+	Use:     "networkpolicy",
+	Aliases: []string{"netpol"},
+	Short:   "Manage network policies",
+	Long:    `Create, list, inspect, and delete network policies that restrict traffic between pods.`,
+}
+
+// Network policy command flags
+var (
+	networkPolicyNamespace   string
+	networkPolicyPodSelector string
+	networkPolicyTypes       []string
+	networkPolicyIngress     []string
+	networkPolicyEgress      []string
+)
+
+var networkPolicyCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a new network policy",
+	Long: `Create a new network policy.
+
+The policy applies to the pods in its namespace that match --pod-selector, or to
+every pod in the namespace without one. Once a pod is selected by a policy for a
+direction, only traffic allowed by some rule of those policies gets through.
+
+Rules have the form field[;field...] with these fields:
+  pods=key=value[,key=value]   pods matching the selector
+  namespace=name               pods in the namespace (* for every namespace);
+                               with pods=, the matching pods in it
+  cidr=cidr[!except...]        addresses in the CIDR, minus the excepted CIDRs
+  ports=port[,port...]         destination ports as [tcp|udp|sctp/]port[-end],
+                               or a protocol alone for all of its ports
+
+A rule without pods, namespace or cidr allows every address, and one without
+ports allows every port.
+
+Examples:
+  # Only let web pods reach the api pods on port 8080
+  podling networkpolicy create api-from-web --pod-selector app=api \
+    --ingress "pods=app=web;ports=8080"
+
+  # Isolate every pod in the namespace in both directions
+  podling networkpolicy create deny-all --policy-type Ingress --policy-type Egress
+
+  # Let db pods resolve names and reach nothing else
+  podling networkpolicy create db-egress --pod-selector app=db \
+    --egress "ports=udp/53,tcp/53"
+
+  # Accept metrics scrapes from the ops namespace and a monitoring subnet
+  podling networkpolicy create metrics --ingress "namespace=ops;ports=9090" \
+    --ingress "cidr=192.168.0.0/16!192.168.1.0/24;ports=9090"
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		podSelector, err := types.ParseSelector(networkPolicyPodSelector)
+		if err != nil {
+			return err
+		}
+
+		policy := types.NetworkPolicy{
+			Name:        name,
+			Namespace:   networkPolicyNamespace,
+			PodSelector: types.LabelSelector{MatchLabels: podSelector},
+		}
+		for _, policyType := range networkPolicyTypes {
+			policy.PolicyTypes = append(policy.PolicyTypes, types.PolicyType(policyType))
+		}
+		for _, spec := range networkPolicyIngress {
+			peers, ports, err := parseNetworkPolicyRule(spec)
+			if err != nil {
+				return err
+			}
+			policy.Ingress = append(policy.Ingress, types.NetworkPolicyIngressRule{From: peers, Ports: ports})
+		}
+		for _, spec := range networkPolicyEgress {
+			peers, ports, err := parseNetworkPolicyRule(spec)
+			if err != nil {
+				return err
+			}
+			policy.Egress = append(policy.Egress, types.NetworkPolicyEgressRule{To: peers, Ports: ports})
+		}
+
+		client := NewClient(GetMasterURL())
+		created, err := client.CreateNetworkPolicy(policy)
+		if err != nil {
+			return fmt.Errorf("failed to create network policy: %w", err)
+		}
+
+		fmt.Println("Network policy created successfully:")
+		fmt.Printf("  ID:           %s\n", created.PolicyID)
+		fmt.Printf("  Name:         %s\n", created.Name)
+		fmt.Printf("  Namespace:    %s\n", created.Namespace)
+		fmt.Printf("  Pod Selector: %s\n", formatPodSelector(created.PodSelector))
+		fmt.Printf("  Policy Types: %s\n", formatPolicyTypes(*created))
+
+		return nil
+	},
+}
+
+var networkPolicyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all network policies",
+	Long:  `List all network policies, optionally filtered by namespace.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		policies, err := client.ListNetworkPolicies(networkPolicyNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to list network policies: %w", err)
+		}
+
+		if len(policies) == 0 {
+			fmt.Println("No network policies found")
+			return nil
+		}
+
+		fmt.Printf("%-25s %-20s %-15s %-30s %s\n", "POLICY ID", "NAME", "NAMESPACE", "POD SELECTOR", "POLICY TYPES")
+		fmt.Println(strings.Repeat("-", 110))
+
+		for _, policy := range policies {
+			fmt.Printf(
+				"%-25s %-20s %-15s %-30s %s\n",
+				policy.PolicyID,
+				truncate(policy.Name, 20),
+				truncate(policy.Namespace, 15),
+				truncate(formatPodSelector(policy.PodSelector), 30),
+				formatPolicyTypes(policy),
+			)
+		}
+
+		return nil
+	},
+}
+
+var networkPolicyGetCmd = &cobra.Command{
+	Use:   "get [policy-id]",
+	Short: "Get network policy details",
+	Long:  `Get detailed information about a specific network policy and its rules.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		policy, err := client.GetNetworkPolicy(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get network policy: %w", err)
+		}
+
+		fmt.Printf("Network Policy: %s\n", policy.Name)
+		fmt.Printf("  ID:           %s\n", policy.PolicyID)
+		fmt.Printf("  Namespace:    %s\n", policy.Namespace)
+		fmt.Printf("  Pod Selector: %s\n", formatPodSelector(policy.PodSelector))
+		fmt.Printf("  Policy Types: %s\n", formatPolicyTypes(*policy))
+		fmt.Printf("  Created:      %s\n", policy.CreatedAt.Format("2006-01-02 15:04:05"))
+
+		if policy.AppliesTo(types.PolicyTypeIngress) {
+			fmt.Println("\nIngress:")
+			if len(policy.Ingress) == 0 {
+				fmt.Println("  (all traffic denied)")
+			}
+			for _, rule := range policy.Ingress {
+				fmt.Printf("  from %s on %s\n", formatPolicyPeers(rule.From), formatPolicyPorts(rule.Ports))
+			}
+		}
+
+		if policy.AppliesTo(types.PolicyTypeEgress) {
+			fmt.Println("\nEgress:")
+			if len(policy.Egress) == 0 {
+				fmt.Println("  (all traffic denied)")
+			}
+			for _, rule := range policy.Egress {
+				fmt.Printf("  to %s on %s\n", formatPolicyPeers(rule.To), formatPolicyPorts(rule.Ports))
+			}
+		}
+
+		return nil
+	},
+}
+
+var networkPolicyDeleteCmd = &cobra.Command{
+	Use:   "delete [policy-id]",
+	Short: "Delete a network policy",
+	Long:  `Delete a network policy by its ID.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policyID := args[0]
+
+		client := NewClient(GetMasterURL())
+		if err := client.DeleteNetworkPolicy(policyID); err != nil {
+			return fmt.Errorf("failed to delete network policy: %w", err)
+		}
+
+		fmt.Printf("Network policy %s deleted successfully\n", policyID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkPolicyCmd)
+
+	networkPolicyCmd.AddCommand(networkPolicyCreateCmd)
+	networkPolicyCmd.AddCommand(networkPolicyListCmd)
+	networkPolicyCmd.AddCommand(networkPolicyGetCmd)
+	networkPolicyCmd.AddCommand(networkPolicyDeleteCmd)
+
+	networkPolicyCreateCmd.Flags().StringVar(
+		&networkPolicyNamespace, "namespace", "default", "Namespace for the network policy",
+	)
+	networkPolicyCreateCmd.Flags().StringVar(
+		&networkPolicyPodSelector, "pod-selector", "", "Pods the policy applies to (key=value,...; empty for all)",
+	)
+	networkPolicyCreateCmd.Flags().StringArrayVar(
+		&networkPolicyTypes, "policy-type", []string{}, "Direction the policy restricts: Ingress or Egress (can be repeated)",
+	)
+	networkPolicyCreateCmd.Flags().StringArrayVar(
+		&networkPolicyIngress, "ingress", []string{}, "Allowed incoming traffic rule (can be repeated)",
+	)
+	networkPolicyCreateCmd.Flags().StringArrayVar(
+		&networkPolicyEgress, "egress", []string{}, "Allowed outgoing traffic rule (can be repeated)",
+	)
+
+	networkPolicyListCmd.Flags().StringVar(
+		&networkPolicyNamespace, "namespace", "", "Filter by namespace (empty for all)",
+	)
+}
+
+// parseNetworkPolicyRule parses a rule in the format field[;field...]. The pods and namespace
+// fields form one selector peer and cidr an IP block peer; a rule without either allows every
+// address.
+func parseNetworkPolicyRule(spec string) ([]types.NetworkPolicyPeer, []types.NetworkPolicyPort, error) {
+	var selectorPeer types.NetworkPolicyPeer
+	var peers []types.NetworkPolicyPeer
+	var ports []types.NetworkPolicyPort
+
+	for _, field := range strings.Split(spec, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return nil, nil, fmt.Errorf("invalid rule field %q (expected pods, namespace, cidr or ports=value)", field)
+		}
+
+		switch key {
+		case "pods":
+			selector, err := types.ParseSelector(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			selectorPeer.PodSelector = &types.LabelSelector{MatchLabels: selector}
+		case "namespace":
+			selectorPeer.NamespaceSelector = &types.LabelSelector{}
+			if value != "*" {
+				selectorPeer.NamespaceSelector.MatchLabels = map[string]string{types.NamespaceNameLabel: value}
+			}
+		case "cidr":
+			cidrs := strings.Split(value, "!")
+			peers = append(peers, types.NetworkPolicyPeer{IPBlock: &types.IPBlock{CIDR: cidrs[0], Except: cidrs[1:]}})
+		case "ports":
+			for _, port := range strings.Split(value, ",") {
+				parsed, err := parseNetworkPolicyPort(strings.TrimSpace(port))
+				if err != nil {
+					return nil, nil, err
+				}
+				ports = append(ports, parsed)
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown rule field %q (expected pods, namespace, cidr or ports)", key)
+		}
+	}
+
+	if selectorPeer.PodSelector != nil || selectorPeer.NamespaceSelector != nil {
+		peers = append(peers, selectorPeer)
+	}
+	return peers, ports, nil
+}
+
+// parseNetworkPolicyPort parses a port in the format [protocol/]port[-end], or a protocol alone
+func parseNetworkPolicyPort(spec string) (types.NetworkPolicyPort, error) {
+	protocol, portRange, ok := strings.Cut(spec, "/")
+	if !ok {
+		if _, err := strconv.Atoi(strings.Split(spec, "-")[0]); err != nil {
+			return types.NetworkPolicyPort{Protocol: strings.ToUpper(spec)}, nil
+		}
+		protocol, portRange = "TCP", spec
+	}
+
+	port := types.NetworkPolicyPort{Protocol: strings.ToUpper(protocol)}
+	first, last, isRange := strings.Cut(portRange, "-")
+	var err error
+	if port.Port, err = strconv.Atoi(first); err != nil {
+		return types.NetworkPolicyPort{}, fmt.Errorf("invalid port %q (expected [protocol/]port[-end])", spec)
+	}
+	if isRange {
+		if port.EndPort, err = strconv.Atoi(last); err != nil {
+			return types.NetworkPolicyPort{}, fmt.Errorf("invalid port range %q (expected port-end)", spec)
+		}
+	}
+	return port, nil
+}
+
+// formatPodSelector formats a pod selector for display
+func formatPodSelector(selector types.LabelSelector) string {
+	if len(selector.MatchLabels) == 0 {
+		return "<all pods>"
+	}
+	return formatSelector(selector.MatchLabels)
+}
+
+// formatPolicyTypes formats the directions a policy restricts for display
+func formatPolicyTypes(policy types.NetworkPolicy) string {
+	var directions []string
+	for _, policyType := range []types.PolicyType{types.PolicyTypeIngress, types.PolicyTypeEgress} {
+		if policy.AppliesTo(policyType) {
+			directions = append(directions, string(policyType))
+		}
+	}
+	return strings.Join(directions, ",")
+}
+
+// formatPolicyPeers formats the peers of a rule for display
+func formatPolicyPeers(peers []types.NetworkPolicyPeer) string {
+	if len(peers) == 0 {
+		return "anywhere"
+	}
+
+	formatted := make([]string, len(peers))
+	for i, peer := range peers {
+		if peer.IPBlock != nil {
+			formatted[i] = peer.IPBlock.CIDR
+			if len(peer.IPBlock.Except) > 0 {
+				formatted[i] += " except " + strings.Join(peer.IPBlock.Except, ",")
+			}
+			continue
+		}
+
+		var parts []string
+		if peer.NamespaceSelector != nil {
+			namespace := "namespaces " + formatSelector(peer.NamespaceSelector.MatchLabels)
+			if name, ok := peer.NamespaceSelector.MatchLabels[types.NamespaceNameLabel]; ok &&
+				len(peer.NamespaceSelector.MatchLabels) == 1 {
+				namespace = "namespace " + name
+			} else if len(peer.NamespaceSelector.MatchLabels) == 0 {
+				namespace = "all namespaces"
+			}
+			parts = append(parts, namespace)
+		}
+		if peer.PodSelector != nil {
+			parts = append(parts, "pods "+formatPodSelector(*peer.PodSelector))
+		}
+		formatted[i] = strings.Join(parts, " / ")
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// formatPolicyPorts formats the ports of a rule for display
+func formatPolicyPorts(ports []types.NetworkPolicyPort) string {
+	if len(ports) == 0 {
+		return "all ports"
+	}
+
+	formatted := make([]string, len(ports))
+	for i, port := range ports {
+		switch {
+		case port.Port == 0:
+			formatted[i] = port.Protocol
+		case port.EndPort != 0:
+			formatted[i] = fmt.Sprintf("%d-%d/%s", port.Port, port.EndPort, port.Protocol)
+		default:
+			formatted[i] = fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatSelector formats labels as sorted key=value pairs
+func formatSelector(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
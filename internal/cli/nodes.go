@@ -28,7 +28,10 @@ var nodesCmd = &cobra.Command{
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		_, _ = fmt.Fprint(w, "ID\tHOSTNAME\tPORT\tSTATUS\tCPU\tMEMORY\tTASKS\tLAST HEARTBEAT\n")
+		_, _ = fmt.Fprint(
+			w,
+			"ID\tHOSTNAME\tPORT\tSTATUS\tPOD CIDR\tCPU\tCPU USAGE\tMEMORY\tMEMORY USAGE\tTASKS\tIMAGES\tLAST HEARTBEAT\n",
+		)
 
 		for _, node := range nodes {
 			lastHeartbeat := time.Since(node.LastHeartbeat)
@@ -41,15 +44,37 @@ var nodesCmd = &cobra.Command{
 				memoryStr = types.FormatMemory(node.Resources.Capacity.Memory)
 			}
 
+			cpuUsageStr := "N/A"
+			memoryUsageStr := "N/A"
+			if node.Usage != nil {
+				cpuUsageStr = types.FormatCPU(node.Usage.CPU)
+				memoryUsageStr = types.FormatMemory(node.Usage.Memory)
+			}
+
+			var imageBytes int64
+			for _, img := range node.Images {
+				imageBytes += img.SizeBytes
+			}
+			imagesStr := fmt.Sprintf("%d (%s)", len(node.Images), types.FormatMemory(imageBytes))
+
+			podCIDR := node.PodCIDR
+			if podCIDR == "" {
+				podCIDR = "<none>"
+			}
+
 			_, _ = fmt.Fprintf(
-				w, "%s\t%s\t%d\t%s\t%s\t%s\t%d\t%s ago\n",
+				w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s ago\n",
 				node.NodeID,
 				node.Hostname,
 				node.Port,
-				node.Status,
+				formatNodeStatus(node),
+				podCIDR,
 				cpuStr,
+				cpuUsageStr,
 				memoryStr,
+				memoryUsageStr,
 				node.RunningTasks,
+				imagesStr,
 				heartbeatStr,
 			)
 		}
@@ -57,6 +82,9 @@ var nodesCmd = &cobra.Command{
 		_ = w.Flush()
 
 		if IsVerbose() {
+			printNodeConditions(nodes)
+			printNodeWorkloads(nodes)
+			printNodeImages(nodes)
 			fmt.Printf("\nTotal nodes: %d\n", len(nodes))
 		}
 
@@ -64,6 +92,130 @@ var nodesCmd = &cobra.Command{
 	},
 }
 
+var nodesCordonCmd = &cobra.Command{
+	Use:   "cordon [node-id]",
+	Short: "Mark a node unschedulable",
+	Long:  `Mark a node unschedulable. Pods already running on the node keep running.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		if _, err := client.CordonNode(args[0]); err != nil {
+			return fmt.Errorf("failed to cordon node: %w", err)
+		}
+
+		fmt.Printf("Node %s cordoned\n", args[0])
+		return nil
+	},
+}
+
+var nodesUncordonCmd = &cobra.Command{
+	Use:   "uncordon [node-id]",
+	Short: "Mark a node schedulable",
+	Long:  `Mark a cordoned node schedulable again.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		if _, err := client.UncordonNode(args[0]); err != nil {
+			return fmt.Errorf("failed to uncordon node: %w", err)
+		}
+
+		fmt.Printf("Node %s uncordoned\n", args[0])
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(nodesCmd)
+
+	nodesCmd.AddCommand(nodesCordonCmd)
+	nodesCmd.AddCommand(nodesUncordonCmd)
+}
+
+// formatNodeStatus returns the node status followed by its readiness, cordon state and any problem conditions
+func formatNodeStatus(node types.Node) string {
+	status := string(node.Status)
+	if !node.IsReady() {
+		status += ",NotReady"
+	}
+	if node.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	for _, condition := range node.Conditions {
+		if condition.Status && condition.Type != types.NodeReady {
+			status += "," + string(condition.Type)
+		}
+	}
+	return status
+}
+
+// printNodeConditions prints the conditions reported by each node
+func printNodeConditions(nodes []types.Node) {
+	for _, node := range nodes {
+		if len(node.Conditions) == 0 {
+			continue
+		}
+
+		fmt.Printf("\nConditions of %s:\n", node.NodeID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprint(w, "  TYPE\tSTATUS\tREASON\tLAST TRANSITION\tMESSAGE\n")
+		for _, condition := range node.Conditions {
+			_, _ = fmt.Fprintf(
+				w, "  %s\t%t\t%s\t%s ago\t%s\n", condition.Type, condition.Status, condition.Reason,
+				formatDuration(time.Since(condition.LastTransitionTime)), condition.Message,
+			)
+		}
+		_ = w.Flush()
+	}
+}
+
+// printNodeWorkloads prints the pods and tasks each node reports as running
+func printNodeWorkloads(nodes []types.Node) {
+	for _, node := range nodes {
+		if len(node.Pods) == 0 && len(node.Tasks) == 0 {
+			continue
+		}
+
+		fmt.Printf("\nRunning on %s:\n", node.NodeID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprint(w, "  KIND\tID\tCONTAINER\tCONTAINER ID\tSTATE\n")
+		for _, pod := range node.Pods {
+			for _, container := range pod.Containers {
+				_, _ = fmt.Fprintf(
+					w, "  pod\t%s\t%s\t%s\t%s\n", pod.PodID, container.Name, truncate(container.ContainerID, 12),
+					container.State,
+				)
+			}
+		}
+		for _, task := range node.Tasks {
+			_, _ = fmt.Fprintf(w, "  task\t%s\t-\t%s\trunning\n", task.TaskID, truncate(task.ContainerID, 12))
+		}
+		_ = w.Flush()
+	}
+}
+
+// printNodeImages prints the image inventory reported by each node
+func printNodeImages(nodes []types.Node) {
+	for _, node := range nodes {
+		if len(node.Images) == 0 {
+			continue
+		}
+
+		fmt.Printf("\nImages on %s:\n", node.NodeID)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprint(w, "  IMAGE\tID\tSIZE\tLAST USED\n")
+		for _, img := range node.Images {
+			name := "<none>"
+			if len(img.Names) > 0 {
+				name = img.Names[0]
+			}
+			lastUsed := "never"
+			if img.LastUsed != nil {
+				lastUsed = formatDuration(time.Since(*img.LastUsed)) + " ago"
+			}
+			_, _ = fmt.Fprintf(
+				w, "  %s\t%s\t%s\t%s\n", name, truncate(img.ID, 19), types.FormatMemory(img.SizeBytes), lastUsed,
+			)
+		}
+		_ = w.Flush()
+	}
 }
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/danpasecinic/podling/internal/types"
@@ -20,6 +21,18 @@ var (
 	podCreateLabels     []string
 	podCreateContainers []string
 	podCreatePorts      []string
+	podCreatePullPolicy string
+	podCreatePullSecret []string
+
+	podCreateRunAsUser      int64
+	podCreateRunAsGroup     int64
+	podCreateReadOnlyRootFS bool
+	podCreatePrivileged     bool
+	podCreateNoNewPrivs     bool
+	podCreateCapAdd         []string
+	podCreateCapDrop        []string
+	podCreateSeccomp        string
+	podCreateAppArmor       string
 )
 
 var podCreateCmd = &cobra.Command{
@@ -41,6 +54,19 @@ Examples:
     --port app:8080:80 \
     --port sidecar:9090:9090
 
+  # Create a pod from a private registry
+  podling pod create my-app \
+    --container app:registry.example.com/team/app:1.0 \
+    --image-pull-secret regcred \
+    --image-pull-policy IfNotPresent
+
+  # Run as a non-root user with a read-only root filesystem and no capabilities
+  podling pod create my-app \
+    --container app:myapp:1.0 \
+    --run-as-user 1000 \
+    --read-only-root-fs \
+    --cap-drop ALL
+
   # Create a pod with labels and namespace
   podling pod create my-app \
     --namespace production \
@@ -82,8 +108,23 @@ Port format: [containerName:]hostPort:containerPort
 			return fmt.Errorf("failed to apply port mappings: %w", err)
 		}
 
+		if podCreatePullPolicy != "" {
+			policy := types.ImagePullPolicy(podCreatePullPolicy)
+			if !policy.IsValid() {
+				return fmt.Errorf("invalid image pull policy: %s (expected Always, IfNotPresent or Never)", policy)
+			}
+			for i := range containers {
+				containers[i].ImagePullPolicy = policy
+			}
+		}
+
+		opts := PodOptions{
+			ImagePullSecrets: podCreatePullSecret,
+			SecurityContext:  buildSecurityContext(cmd),
+		}
+
 		client := NewClient(GetMasterURL())
-		pod, err := client.CreatePod(podName, podCreateNamespace, labels, containers)
+		pod, err := client.CreatePodWithOptions(podName, podCreateNamespace, labels, containers, opts)
 		if err != nil {
 			return fmt.Errorf("failed to create pod: %w", err)
 		}
@@ -182,6 +223,9 @@ var podGetCmd = &cobra.Command{
 		fmt.Printf("Name:          %s\n", pod.Name)
 		fmt.Printf("Namespace:     %s\n", pod.Namespace)
 		fmt.Printf("Status:        %s\n", pod.Status)
+		if pod.QOSClass != "" {
+			fmt.Printf("QoS Class:     %s\n", pod.QOSClass)
+		}
 		if pod.NodeID != "" {
 			fmt.Printf("Node ID:       %s\n", pod.NodeID)
 		}
@@ -213,6 +257,7 @@ var podGetCmd = &cobra.Command{
 		for i, container := range pod.Containers {
 			fmt.Printf("\n  [%d] %s\n", i+1, container.Name)
 			fmt.Printf("      Image:       %s\n", container.Image)
+			fmt.Printf("      Pull Policy: %s\n", container.GetImagePullPolicy())
 			fmt.Printf("      Status:      %s\n", container.Status)
 			if container.ContainerID != "" {
 				fmt.Printf("      Container ID: %s\n", truncate(container.ContainerID, 12))
@@ -234,6 +279,21 @@ var podGetCmd = &cobra.Command{
 			}
 		}
 
+		if len(pod.Events) > 0 {
+			fmt.Println("\nEvents:")
+			fmt.Printf("  %-8s %-20s %-10s %-12s %s\n", "TIME", "REASON", "TYPE", "CONTAINER", "MESSAGE")
+			for _, event := range pod.Events {
+				fmt.Printf(
+					"  %-8s %-20s %-10s %-12s %s\n",
+					event.Timestamp.Format("15:04:05"),
+					event.Reason,
+					event.Type,
+					truncate(event.Container, 12),
+					event.Message,
+				)
+			}
+		}
+
 		return nil
 	},
 }
@@ -274,6 +334,73 @@ func init() {
 	podCreateCmd.Flags().StringArrayVarP(
 		&podCreatePorts, "port", "p", []string{}, "port mapping ([containerName:]hostPort:containerPort)",
 	)
+	podCreateCmd.Flags().StringVar(
+		&podCreatePullPolicy, "image-pull-policy", "", "image pull policy for all containers (Always, IfNotPresent, Never)",
+	)
+	podCreateCmd.Flags().StringArrayVar(
+		&podCreatePullSecret, "image-pull-secret", []string{}, "name of a registry secret used to pull images",
+	)
+	podCreateCmd.Flags().Int64Var(&podCreateRunAsUser, "run-as-user", 0, "UID to run all containers as")
+	podCreateCmd.Flags().Int64Var(&podCreateRunAsGroup, "run-as-group", 0, "GID to run all containers as")
+	podCreateCmd.Flags().BoolVar(&podCreateReadOnlyRootFS, "read-only-root-fs", false, "mount root filesystems read-only")
+	podCreateCmd.Flags().BoolVar(&podCreatePrivileged, "privileged", false, "run containers in privileged mode")
+	podCreateCmd.Flags().BoolVar(
+		&podCreateNoNewPrivs, "no-new-privileges", false, "prevent processes from gaining new privileges",
+	)
+	podCreateCmd.Flags().StringArrayVar(&podCreateCapAdd, "cap-add", []string{}, "Linux capability to add")
+	podCreateCmd.Flags().StringArrayVar(&podCreateCapDrop, "cap-drop", []string{}, "Linux capability to drop")
+	podCreateCmd.Flags().StringVar(
+		&podCreateSeccomp, "seccomp-profile", "", "seccomp profile (runtime/default, unconfined, or worker path)",
+	)
+	podCreateCmd.Flags().StringVar(
+		&podCreateAppArmor, "apparmor-profile", "", "AppArmor profile (runtime/default, unconfined, or profile name)",
+	)
+}
+
+// buildSecurityContext builds a pod security context from the flags that were set.
+// It returns nil when no security flag was given.
+func buildSecurityContext(cmd *cobra.Command) *types.SecurityContext {
+	flags := cmd.Flags()
+	sc := &types.SecurityContext{}
+	set := false
+
+	if flags.Changed("run-as-user") {
+		sc.RunAsUser = &podCreateRunAsUser
+		set = true
+	}
+	if flags.Changed("run-as-group") {
+		sc.RunAsGroup = &podCreateRunAsGroup
+		set = true
+	}
+	if flags.Changed("read-only-root-fs") {
+		sc.ReadOnlyRootFilesystem = &podCreateReadOnlyRootFS
+		set = true
+	}
+	if flags.Changed("privileged") {
+		sc.Privileged = &podCreatePrivileged
+		set = true
+	}
+	if flags.Changed("no-new-privileges") {
+		sc.NoNewPrivileges = &podCreateNoNewPrivs
+		set = true
+	}
+	if len(podCreateCapAdd) > 0 || len(podCreateCapDrop) > 0 {
+		sc.Capabilities = &types.Capabilities{Add: podCreateCapAdd, Drop: podCreateCapDrop}
+		set = true
+	}
+	if podCreateSeccomp != "" {
+		sc.SeccompProfile = podCreateSeccomp
+		set = true
+	}
+	if podCreateAppArmor != "" {
+		sc.AppArmorProfile = podCreateAppArmor
+		set = true
+	}
+
+	if !set {
+		return nil
+	}
+	return sc
 }
 
 // parseContainerSpec parses a container specification string
@@ -331,21 +458,26 @@ func parseContainerSpec(spec string) (types.Container, error) {
 
 // Pod logs command
 var (
-	podLogsContainer string
-	podLogsTail      int
+	podLogsContainer  string
+	podLogsTail       int
+	podLogsFollow     bool
+	podLogsSince      string
+	podLogsTimestamps bool
 )
 
 var podLogsCmd = &cobra.Command{
 	Use:   "logs [pod-id]",
 	Short: "Get logs from a pod's containers",
-	Long: `Get logs from one or all containers in a pod.
+	Long: `Get logs from one or all containers in a pod. Logs are streamed through the master;
+stderr lines are written to stderr. Without --container, the lines of all containers are
+interleaved and prefixed with their container name.
 
 Examples:
   # Get logs from all containers in a pod
   podling pod logs <pod-id>
 
-  # Get logs from a specific container
-  podling pod logs <pod-id> --container web
+  # Follow a specific container
+  podling pod logs <pod-id> --container web -f
 
   # Get last 50 lines
   podling pod logs <pod-id> --tail 50
@@ -355,24 +487,21 @@ Examples:
 		podID := args[0]
 
 		client := NewClient(GetMasterURL())
-		logs, err := client.GetPodLogs(podID, podLogsContainer, podLogsTail)
+		logs, err := client.StreamPodLogs(
+			podID, LogOptions{
+				Container:  podLogsContainer,
+				Follow:     podLogsFollow,
+				Since:      podLogsSince,
+				Tail:       podLogsTail,
+				Timestamps: podLogsTimestamps,
+			},
+		)
 		if err != nil {
 			return fmt.Errorf("failed to get pod logs: %w", err)
 		}
+		defer func() { _ = logs.Close() }()
 
-		if podLogsContainer != "" {
-			if log, ok := logs[podLogsContainer]; ok {
-				fmt.Printf("==> Container: %s <==\n%s\n", podLogsContainer, log)
-			} else {
-				return fmt.Errorf("container %s not found", podLogsContainer)
-			}
-		} else {
-			for containerName, log := range logs {
-				fmt.Printf("==> Container: %s <==\n%s\n\n", containerName, log)
-			}
-		}
-
-		return nil
+		return printLogStream(logs, os.Stdout, os.Stderr, podLogsContainer == "")
 	},
 }
 
@@ -380,6 +509,11 @@ Examples:
 func init() {
 	podLogsCmd.Flags().StringVarP(&podLogsContainer, "container", "c", "", "specific container name")
 	podLogsCmd.Flags().IntVarP(&podLogsTail, "tail", "t", 100, "number of lines to show from the end of logs")
+	podLogsCmd.Flags().BoolVarP(&podLogsFollow, "follow", "f", false, "keep streaming new log lines")
+	podLogsCmd.Flags().StringVar(
+		&podLogsSince, "since", "", "only show lines newer than a duration (10m) or RFC 3339 time",
+	)
+	podLogsCmd.Flags().BoolVar(&podLogsTimestamps, "timestamps", false, "show the timestamp of each line")
 }
 
 // truncate truncates a string to the specified length
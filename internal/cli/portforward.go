@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/spf13/cobra"
+)
+
+var portForwardAddress string
+
+// portMapping forwards a local port to a pod port; a local port of 0 picks a free port
+type portMapping struct {
+	Local  int
+	Remote int
+}
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward [pod-id] [local:]remote...",
+	Short: "Forward local ports to a pod",
+	Long: `Forward one or more local ports to ports on a pod's network IP. Connections are tunneled
+through the master and the pod's worker, so the pod needs no host port mappings.
+
+Examples:
+  # Listen on local port 8080 and forward to port 8080 in the pod
+  podling port-forward <pod-id> 8080
+
+  # Listen on local port 8080 and forward to port 80 in the pod
+  podling port-forward <pod-id> 8080:80
+
+  # Listen on a random local port and forward to port 5432 in the pod
+  podling port-forward <pod-id> :5432
+`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		podID := args[0]
+
+		mappings := make([]portMapping, 0, len(args)-1)
+		for _, spec := range args[1:] {
+			mapping, err := parsePortMapping(spec)
+			if err != nil {
+				return err
+			}
+			mappings = append(mappings, mapping)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		client := NewClient(GetMasterURL())
+		for _, mapping := range mappings {
+			listener, err := net.Listen(
+				"tcp", net.JoinHostPort(portForwardAddress, strconv.Itoa(mapping.Local)),
+			)
+			if err != nil {
+				return fmt.Errorf("failed to listen on port %d: %w", mapping.Local, err)
+			}
+			defer func() { _ = listener.Close() }()
+
+			fmt.Printf("Forwarding from %s -> %d\n", listener.Addr(), mapping.Remote)
+			go forwardConnections(listener, client, podID, mapping.Remote)
+		}
+
+		<-ctx.Done()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(portForwardCmd)
+
+	portForwardCmd.Flags().StringVar(&portForwardAddress, "address", "127.0.0.1", "local address to listen on")
+}
+
+// parsePortMapping parses "port", "local:remote" or ":remote"
+func parsePortMapping(spec string) (portMapping, error) {
+	local, remote, found := strings.Cut(spec, ":")
+	if !found {
+		remote = local
+	}
+
+	var mapping portMapping
+	var err error
+	if mapping.Remote, err = strconv.Atoi(remote); err != nil || mapping.Remote < 1 || mapping.Remote > 65535 {
+		return portMapping{}, fmt.Errorf("invalid remote port in %q", spec)
+	}
+	if local != "" {
+		if mapping.Local, err = strconv.Atoi(local); err != nil || mapping.Local < 1 || mapping.Local > 65535 {
+			return portMapping{}, fmt.Errorf("invalid local port in %q", spec)
+		}
+	}
+	return mapping, nil
+}
+
+// forwardConnections tunnels each connection accepted on the listener to the pod port until the listener closes
+func forwardConnections(listener net.Listener, client *Client, podID string, remotePort int) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			tunnel, err := client.PortForwardPod(podID, remotePort)
+			if err != nil {
+				log.Printf("failed to forward connection to port %d: %v", remotePort, err)
+				_ = conn.Close()
+				return
+			}
+			fmt.Printf("Handling connection for %d\n", remotePort)
+			stream.Join(conn, tunnel)
+		}()
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/stream"
+)
+
+func TestParsePortMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    portMapping
+		wantErr bool
+	}{
+		{name: "same port", spec: "8080", want: portMapping{Local: 8080, Remote: 8080}},
+		{name: "local and remote", spec: "8080:80", want: portMapping{Local: 8080, Remote: 80}},
+		{name: "random local port", spec: ":5432", want: portMapping{Local: 0, Remote: 5432}},
+		{name: "invalid remote", spec: "8080:http", wantErr: true},
+		{name: "invalid local", spec: "x:80", wantErr: true},
+		{name: "out of range", spec: "70000", wantErr: true},
+		{name: "empty", spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parsePortMapping(tt.spec)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parsePortMapping(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("parsePortMapping(%q) = %+v, want %+v", tt.spec, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestForwardConnections(t *testing.T) {
+	var gotPath, gotPort string
+	master := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotPort = r.URL.Path, r.URL.Query().Get("port")
+				conn, err := stream.Upgrade(w, r)
+				if err != nil {
+					t.Errorf("Upgrade() error = %v", err)
+					return
+				}
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			},
+		),
+	)
+	defer master.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	go forwardConnections(listener, NewClient(master.URL), "pod-1", 80)
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Errorf("reply = %q, %v, want ping", reply, err)
+	}
+	if gotPath != "/api/v1/pods/pod-1/portforward" || gotPort != "80" {
+		t.Errorf("request = %s port=%s, want the pod's portforward endpoint with port 80", gotPath, gotPort)
+	}
+}
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets",
+	Long:  `Create, list, and delete secrets such as private registry credentials.`,
+}
+
+// Secret create command flags
+var (
+	secretNamespace      string
+	secretDockerServer   string
+	secretDockerUsername string
+	secretDockerPassword string
+	secretLiterals       []string
+	secretTLSCert        string
+	secretTLSKey         string
+)
+
+var secretCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a new secret",
+	Long: `Create a new secret.
+
+Examples:
+  # Create registry credentials for pulling private images
+  podling secret create regcred \
+    --docker-server registry.example.com \
+    --docker-username ci \
+    --docker-password s3cret
+
+  # Create an opaque secret from literal values
+  podling secret create api-keys --from-literal token=abc123
+
+  # Create a TLS secret for an ingress from PEM files
+  podling secret create site-tls --tls-cert site.crt --tls-key site.key
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		var secretType types.SecretType
+		var data map[string]string
+		var err error
+		if secretTLSCert != "" || secretTLSKey != "" {
+			secretType, data, err = buildTLSSecretData(secretTLSCert, secretTLSKey)
+		} else {
+			secretType, data, err = buildSecretData(
+				secretDockerServer, secretDockerUsername, secretDockerPassword, secretLiterals,
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		client := NewClient(GetMasterURL())
+		secret, err := client.CreateSecret(name, secretNamespace, secretType, data)
+		if err != nil {
+			return fmt.Errorf("failed to create secret: %w", err)
+		}
+
+		fmt.Println("Secret created successfully:")
+		fmt.Printf("  ID:        %s\n", secret.SecretID)
+		fmt.Printf("  Name:      %s\n", secret.Name)
+		fmt.Printf("  Namespace: %s\n", secret.Namespace)
+		fmt.Printf("  Type:      %s\n", secret.Type)
+
+		return nil
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all secrets",
+	Long:  `List all secrets, optionally filtered by namespace. Secret values are never shown.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+		secrets, err := client.ListSecrets(secretNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		if len(secrets) == 0 {
+			fmt.Println("No secrets found")
+			return nil
+		}
+
+		fmt.Printf("%-25s %-20s %-15s %-16s %s\n", "SECRET ID", "NAME", "NAMESPACE", "TYPE", "KEYS")
+		fmt.Println(strings.Repeat("-", 90))
+
+		for _, secret := range secrets {
+			keys := make([]string, 0, len(secret.Data))
+			for k := range secret.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			fmt.Printf(
+				"%-25s %-20s %-15s %-16s %s\n",
+				secret.SecretID,
+				truncate(secret.Name, 20),
+				truncate(secret.Namespace, 15),
+				secret.Type,
+				strings.Join(keys, ","),
+			)
+		}
+
+		return nil
+	},
+}
+
+var secretDeleteCmd = &cobra.Command{
+	Use:   "delete [secret-id]",
+	Short: "Delete a secret",
+	Long:  `Delete a secret by its ID.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		secretID := args[0]
+
+		client := NewClient(GetMasterURL())
+		if err := client.DeleteSecret(secretID); err != nil {
+			return fmt.Errorf("failed to delete secret: %w", err)
+		}
+
+		fmt.Printf("Secret %s deleted successfully\n", secretID)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+
+	secretCmd.AddCommand(secretCreateCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretDeleteCmd)
+
+	secretCreateCmd.Flags().StringVar(&secretNamespace, "namespace", "default", "Namespace for the secret")
+	secretCreateCmd.Flags().StringVar(&secretDockerServer, "docker-server", "", "Registry server address")
+	secretCreateCmd.Flags().StringVar(&secretDockerUsername, "docker-username", "", "Registry username")
+	secretCreateCmd.Flags().StringVar(&secretDockerPassword, "docker-password", "", "Registry password")
+	secretCreateCmd.Flags().StringArrayVar(&secretLiterals, "from-literal", []string{}, "Literal value (key=value)")
+	secretCreateCmd.Flags().StringVar(&secretTLSCert, "tls-cert", "", "PEM certificate chain file for a TLS secret")
+	secretCreateCmd.Flags().StringVar(&secretTLSKey, "tls-key", "", "PEM private key file for a TLS secret")
+	secretCreateCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+	secretCreateCmd.MarkFlagsMutuallyExclusive("tls-cert", "docker-server")
+	secretCreateCmd.MarkFlagsMutuallyExclusive("tls-cert", "from-literal")
+
+	secretListCmd.Flags().StringVar(&secretNamespace, "namespace", "", "Filter by namespace (empty for all)")
+}
+
+// buildSecretData determines the secret type and data from the create flags
+func buildSecretData(server, username, password string, literals []string) (
+	types.SecretType, map[string]string, error,
+) {
+	if server != "" || username != "" || password != "" {
+		if len(literals) > 0 {
+			return "", nil, fmt.Errorf("--from-literal cannot be combined with --docker-* flags")
+		}
+		if server == "" || username == "" || password == "" {
+			return "", nil, fmt.Errorf("--docker-server, --docker-username and --docker-password are all required")
+		}
+		return types.SecretTypeDockerRegistry, map[string]string{
+			types.SecretKeyServer:   server,
+			types.SecretKeyUsername: username,
+			types.SecretKeyPassword: password,
+		}, nil
+	}
+
+	if len(literals) == 0 {
+		return "", nil, fmt.Errorf("secret data is required (use --docker-* or --from-literal flags)")
+	}
+
+	data := make(map[string]string, len(literals))
+	for _, literal := range literals {
+		parts := strings.SplitN(literal, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return "", nil, fmt.Errorf("invalid literal format: %s (expected key=value)", literal)
+		}
+		data[parts[0]] = parts[1]
+	}
+
+	return types.SecretTypeOpaque, data, nil
+}
+
+// buildTLSSecretData reads a certificate chain and private key from PEM files
+func buildTLSSecretData(certFile, keyFile string) (types.SecretType, map[string]string, error) {
+	cert, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	if _, err := tls.X509KeyPair(cert, key); err != nil {
+		return "", nil, fmt.Errorf("invalid certificate and key: %w", err)
+	}
+
+	return types.SecretTypeTLS, map[string]string{
+		types.SecretKeyTLSCert: string(cert),
+		types.SecretKeyTLSKey:  string(key),
+	}, nil
+}
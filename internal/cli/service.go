@@ -22,7 +22,11 @@ var (
 	serviceCreateSelectors []string
 	servicePorts           []string
 	serviceType            string
+	serviceClusterIP       string
+	serviceExternalName    string
 	serviceSessionAffinity string
+	serviceAffinityTimeout int
+	serviceLoadBalancing   string
 )
 
 var serviceCreateCmd = &cobra.Command{
@@ -42,6 +46,30 @@ Examples:
     --port http:8080:80 \
     --port metrics:9090
 
+  # Target the container port named "web" on each pod, whatever its number
+  podling service create web \
+    --selector app=nginx \
+    --port http:80:web
+
+  # Spread connections by pod weight and keep clients on the same pod for 10 minutes
+  podling service create api \
+    --selector app=backend \
+    --port 8080 \
+    --load-balancing Weighted \
+    --session-affinity ClientIP \
+    --session-affinity-timeout 600
+
+  # Create a headless service, resolving to the address of every ready pod
+  podling service create db \
+    --selector app=postgres \
+    --port 5432 \
+    --cluster-ip None
+
+  # Alias an external database; the service name resolves as a CNAME
+  podling service create billing-db \
+    --type ExternalName \
+    --external-name db.billing.example.com
+
   # Create a service with labels and namespace
   podling service create web \
     --namespace production \
@@ -58,11 +86,20 @@ Port format: [name:]port[:targetPort]
 	RunE: func(cmd *cobra.Command, args []string) error {
 		serviceName := args[0]
 
-		if len(serviceCreateSelectors) == 0 {
+		if serviceExternalName != "" && !cmd.Flags().Changed("type") {
+			serviceType = string(types.ServiceTypeExternalName)
+		}
+		externalName := serviceType == string(types.ServiceTypeExternalName)
+
+		if externalName && serviceExternalName == "" {
+			return fmt.Errorf("an external name is required for type ExternalName (use --external-name flag)")
+		}
+
+		if !externalName && len(serviceCreateSelectors) == 0 {
 			return fmt.Errorf("at least one selector is required (use --selector flag)")
 		}
 
-		if len(servicePorts) == 0 {
+		if !externalName && serviceClusterIP != types.ClusterIPNone && len(servicePorts) == 0 {
 			return fmt.Errorf("at least one port is required (use --port flag)")
 		}
 
@@ -94,7 +131,10 @@ Port format: [name:]port[:targetPort]
 		}
 
 		client := NewClient(GetMasterURL())
-		service, err := client.CreateService(serviceName, serviceCreateNamespace, selector, ports, labels, serviceType, serviceSessionAffinity)
+		service, err := client.CreateService(
+			serviceName, serviceCreateNamespace, selector, ports, labels, serviceType, serviceClusterIP,
+			serviceExternalName, serviceSessionAffinity, serviceAffinityTimeout, serviceLoadBalancing,
+		)
 		if err != nil {
 			return fmt.Errorf("failed to create service: %w", err)
 		}
@@ -104,20 +144,16 @@ Port format: [name:]port[:targetPort]
 		fmt.Printf("  Name:       %s\n", service.Name)
 		fmt.Printf("  Namespace:  %s\n", service.Namespace)
 		fmt.Printf("  Type:       %s\n", service.Type)
-		if service.ClusterIP != "" {
-			fmt.Printf("  ClusterIP:  %s\n", service.ClusterIP)
-			fmt.Printf("  DNS:        %s\n", service.GetDNSName())
+		printServiceAddress(*service)
+		if service.Type == types.ServiceTypeLoadBalancer {
+			fmt.Printf("  Ingress:    %s\n", formatServiceIngress(*service))
 		}
 		fmt.Printf("  Ports:      %d\n", len(service.Ports))
 
 		if IsVerbose() {
 			fmt.Println("\nPorts:")
 			for _, p := range service.Ports {
-				if p.Name != "" {
-					fmt.Printf("  - %s: %d -> %d/%s\n", p.Name, p.Port, p.TargetPort, p.Protocol)
-				} else {
-					fmt.Printf("  - %d -> %d/%s\n", p.Port, p.TargetPort, p.Protocol)
-				}
+				fmt.Printf("  - %s\n", formatServicePort(p))
 			}
 
 			if len(selector) > 0 {
@@ -156,8 +192,10 @@ var serviceListCmd = &cobra.Command{
 		}
 
 		// Print header
-		fmt.Printf("%-20s %-15s %-10s %-15s %-40s\n", "NAME", "NAMESPACE", "TYPE", "CLUSTER-IP", "PORTS")
-		fmt.Println(strings.Repeat("-", 100))
+		fmt.Printf(
+			"%-20s %-15s %-12s %-15s %-15s %-40s\n", "NAME", "NAMESPACE", "TYPE", "CLUSTER-IP", "EXTERNAL-IP", "PORTS",
+		)
+		fmt.Println(strings.Repeat("-", 120))
 
 		// Print services
 		for _, svc := range services {
@@ -171,13 +209,22 @@ var serviceListCmd = &cobra.Command{
 				clusterIP = "None"
 			}
 
+			externalIP := "None"
+			switch svc.Type {
+			case types.ServiceTypeLoadBalancer:
+				externalIP = formatServiceIngress(svc)
+			case types.ServiceTypeExternalName:
+				externalIP = svc.ExternalName
+			}
+
 			ports := formatServicePorts(svc.Ports)
 
-			fmt.Printf("%-20s %-15s %-10s %-15s %-40s\n",
+			fmt.Printf("%-20s %-15s %-12s %-15s %-15s %-40s\n",
 				truncate(svc.Name, 20),
 				truncate(namespace, 15),
 				string(svc.Type),
 				clusterIP,
+				truncate(externalIP, 15),
 				truncate(ports, 40),
 			)
 		}
@@ -214,9 +261,15 @@ var serviceGetCmd = &cobra.Command{
 		fmt.Printf("  ID:         %s\n", service.ServiceID)
 		fmt.Printf("  Namespace:  %s\n", service.Namespace)
 		fmt.Printf("  Type:       %s\n", service.Type)
-		if service.ClusterIP != "" {
-			fmt.Printf("  ClusterIP:  %s\n", service.ClusterIP)
-			fmt.Printf("  DNS:        %s\n", service.GetDNSName())
+		printServiceAddress(*service)
+		if service.Type == types.ServiceTypeLoadBalancer {
+			fmt.Printf("  Ingress:    %s\n", formatServiceIngress(*service))
+		}
+		if service.LoadBalancing != "" {
+			fmt.Printf("  Balancing:  %s\n", service.LoadBalancing)
+		}
+		if service.SessionAffinity == types.SessionAffinityClientIP {
+			fmt.Printf("  Affinity:   ClientIP (%s)\n", service.SessionAffinityTimeout())
 		}
 		fmt.Printf("  Created:    %s\n", service.CreatedAt.Format("2006-01-02 15:04:05"))
 
@@ -230,11 +283,7 @@ var serviceGetCmd = &cobra.Command{
 		if len(service.Ports) > 0 {
 			fmt.Println("\nPorts:")
 			for _, p := range service.Ports {
-				if p.Name != "" {
-					fmt.Printf("  - %s: %d -> %d/%s\n", p.Name, p.Port, p.TargetPort, p.Protocol)
-				} else {
-					fmt.Printf("  - %d -> %d/%s\n", p.Port, p.TargetPort, p.Protocol)
-				}
+				fmt.Printf("  - %s\n", formatServicePort(p))
 			}
 		}
 
@@ -249,6 +298,9 @@ var serviceGetCmd = &cobra.Command{
 		if endpoints != nil && endpoints.HasEndpoints() {
 			fmt.Println("\nEndpoints:")
 			for _, subset := range endpoints.Subsets {
+				if len(subset.Ports) > 0 {
+					fmt.Printf("  Ports: %s\n", formatEndpointPorts(subset.Ports))
+				}
 				if len(subset.Addresses) > 0 {
 					fmt.Println("  Ready:")
 					for _, addr := range subset.Addresses {
@@ -303,14 +355,46 @@ func init() {
 	serviceCreateCmd.Flags().StringSliceVar(&serviceCreateLabels, "label", []string{}, "Labels for the service (can be specified multiple times)")
 	serviceCreateCmd.Flags().StringSliceVar(&serviceCreateSelectors, "selector", []string{}, "Pod selector (can be specified multiple times)")
 	serviceCreateCmd.Flags().StringSliceVar(&servicePorts, "port", []string{}, "Service ports (can be specified multiple times)")
-	serviceCreateCmd.Flags().StringVar(&serviceType, "type", "ClusterIP", "Service type (ClusterIP, NodePort, LoadBalancer)")
+	serviceCreateCmd.Flags().StringVar(
+		&serviceType, "type", "ClusterIP", "Service type (ClusterIP, NodePort, LoadBalancer, ExternalName)",
+	)
+	serviceCreateCmd.Flags().StringVar(
+		&serviceClusterIP, "cluster-ip", "", "Set to None for a headless service that resolves to its pods' addresses",
+	)
+	serviceCreateCmd.Flags().StringVar(
+		&serviceExternalName, "external-name", "",
+		"DNS name an ExternalName service is an alias for (implies --type ExternalName)",
+	)
 	serviceCreateCmd.Flags().StringVar(&serviceSessionAffinity, "session-affinity", "", "Session affinity (None or ClientIP)")
+	serviceCreateCmd.Flags().IntVar(
+		&serviceAffinityTimeout, "session-affinity-timeout", 0,
+		"Seconds a client sticks to its pod with ClientIP affinity (default 10800)",
+	)
+	serviceCreateCmd.Flags().StringVar(
+		&serviceLoadBalancing, "load-balancing", "",
+		"Load balancing algorithm (RoundRobin, Random, LeastConnections or Weighted by the podling.io/weight pod annotation)",
+	)
 
 	// List command flags
 	serviceListCmd.Flags().StringVar(&serviceCreateNamespace, "namespace", "", "Filter by namespace (empty for all)")
 }
 
-// parsePortSpec parses a port specification in the format [name:]port[:targetPort]
+// printServiceAddress prints how a service is reached: its ClusterIP, or None when headless, and
+// the name an ExternalName service is an alias for
+func printServiceAddress(service types.Service) {
+	if service.ClusterIP != "" {
+		fmt.Printf("  ClusterIP:  %s\n", service.ClusterIP)
+	}
+	if service.IsExternalName() {
+		fmt.Printf("  Alias for:  %s\n", service.ExternalName)
+	}
+	if service.ClusterIP != "" || service.IsExternalName() {
+		fmt.Printf("  DNS:        %s\n", service.GetDNSName())
+	}
+}
+
+// parsePortSpec parses a port specification in the format [name:]port[:targetPort], where
+// targetPort is a container port number or name
 func parsePortSpec(spec string) (types.ServicePort, error) {
 	parts := strings.Split(spec, ":")
 
@@ -330,11 +414,9 @@ func parsePortSpec(spec string) (types.ServicePort, error) {
 	case 2:
 		if p, err := strconv.Atoi(parts[0]); err == nil {
 			port.Port = p
-			tp, err := strconv.Atoi(parts[1])
-			if err != nil {
-				return port, fmt.Errorf("invalid target port number: %s", parts[1])
+			if err := setTargetPort(&port, parts[1]); err != nil {
+				return port, err
 			}
-			port.TargetPort = tp
 		} else {
 			port.Name = parts[0]
 			p, err := strconv.Atoi(parts[1])
@@ -353,11 +435,9 @@ func parsePortSpec(spec string) (types.ServicePort, error) {
 		}
 		port.Port = p
 
-		tp, err := strconv.Atoi(parts[2])
-		if err != nil {
-			return port, fmt.Errorf("invalid target port number: %s", parts[2])
+		if err := setTargetPort(&port, parts[2]); err != nil {
+			return port, err
 		}
-		port.TargetPort = tp
 
 	default:
 		return port, fmt.Errorf("invalid port format (expected [name:]port[:targetPort])")
@@ -366,6 +446,19 @@ func parsePortSpec(spec string) (types.ServicePort, error) {
 	return port, nil
 }
 
+// setTargetPort sets the target port to a container port number or name
+func setTargetPort(port *types.ServicePort, target string) error {
+	if target == "" {
+		return fmt.Errorf("invalid target port: empty")
+	}
+	if tp, err := strconv.Atoi(target); err == nil {
+		port.TargetPort = tp
+	} else {
+		port.TargetPortName = target
+	}
+	return nil
+}
+
 // formatServicePorts formats service ports for display
 func formatServicePorts(ports []types.ServicePort) string {
 	if len(ports) == 0 {
@@ -374,12 +467,62 @@ func formatServicePorts(ports []types.ServicePort) string {
 
 	parts := make([]string, 0, len(ports))
 	for _, p := range ports {
+		part := fmt.Sprintf("%d", p.Port)
 		if p.Name != "" {
-			parts = append(parts, fmt.Sprintf("%s:%d", p.Name, p.Port))
-		} else {
-			parts = append(parts, fmt.Sprintf("%d", p.Port))
+			part = fmt.Sprintf("%s:%d", p.Name, p.Port)
+		}
+		if p.NodePort != 0 {
+			part = fmt.Sprintf("%s:%d", part, p.NodePort)
 		}
+		parts = append(parts, part)
 	}
 
 	return strings.Join(parts, ",")
 }
+
+// formatServicePort formats a single service port for detailed display,
+// e.g. "http: 80 -> 8080/TCP (node port 30080)"
+func formatServicePort(p types.ServicePort) string {
+	target := strconv.Itoa(p.TargetPort)
+	if p.TargetPortName != "" {
+		target = p.TargetPortName
+	}
+	out := fmt.Sprintf("%d -> %s/%s", p.Port, target, p.Protocol)
+	if p.Name != "" {
+		out = p.Name + ": " + out
+	}
+	if p.NodePort != 0 {
+		out += fmt.Sprintf(" (node port %d)", p.NodePort)
+	}
+	return out
+}
+
+// formatEndpointPorts formats the resolved ports of an endpoint subset for display
+func formatEndpointPorts(ports []types.EndpointPort) string {
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		if p.Name != "" {
+			parts = append(parts, fmt.Sprintf("%s=%d", p.Name, p.Port))
+		} else {
+			parts = append(parts, strconv.Itoa(p.Port))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatServiceIngress formats the load balancer addresses of a service,
+// or <pending> while it has none
+func formatServiceIngress(service types.Service) string {
+	var addrs []string
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addrs = append(addrs, ingress.IP)
+		} else if ingress.Hostname != "" {
+			addrs = append(addrs, ingress.Hostname)
+		}
+	}
+	if len(addrs) == 0 {
+		return "<pending>"
+	}
+	return strings.Join(addrs, ",")
+}
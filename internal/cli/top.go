@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show resource usage of pods and nodes",
+	Long:  `Show the CPU and memory actually used by pods and nodes next to their requests, limits and capacity.`,
+}
+
+var topPodsCmd = &cobra.Command{
+	Use:   "pods [pod-id]",
+	Short: "Show resource usage of pods",
+	Long: `Show the CPU and memory used by running pods next to their requests and limits.
+With a pod ID, show the usage of each of the pod's containers.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+
+		if len(args) == 1 {
+			stats, err := client.GetPodStats(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get pod stats: %w", err)
+			}
+			printContainerStats(stats)
+			return nil
+		}
+
+		metrics, err := client.ListPodMetrics()
+		if err != nil {
+			return fmt.Errorf("failed to get pod metrics: %w", err)
+		}
+
+		if len(metrics) == 0 {
+			fmt.Println("No running pods found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprint(
+			w, "NAME\tNAMESPACE\tNODE\tCPU\tCPU REQUEST\tCPU LIMIT\tMEMORY\tMEMORY REQUEST\tMEMORY LIMIT\n",
+		)
+
+		for _, m := range metrics {
+			cpuStr, memoryStr := "N/A", "N/A"
+			if m.Available {
+				cpuStr = formatUsage(types.FormatCPU(m.Usage.CPU), m.Usage.CPU, m.Limits.CPU)
+				memoryStr = formatUsage(types.FormatMemory(m.Usage.Memory), m.Usage.Memory, m.Limits.Memory)
+			}
+
+			_, _ = fmt.Fprintf(
+				w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				m.Name,
+				m.Namespace,
+				m.NodeID,
+				cpuStr,
+				formatQuantity(types.FormatCPU, m.Requests.CPU),
+				formatQuantity(types.FormatCPU, m.Limits.CPU),
+				memoryStr,
+				formatQuantity(types.FormatMemory, m.Requests.Memory),
+				formatQuantity(types.FormatMemory, m.Limits.Memory),
+			)
+		}
+
+		return w.Flush()
+	},
+}
+
+var topNodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "Show resource usage of nodes",
+	Long:  `Show the CPU and memory used on each node next to the resources requested by its workloads and its capacity.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := NewClient(GetMasterURL())
+
+		metrics, err := client.ListNodeMetrics()
+		if err != nil {
+			return fmt.Errorf("failed to get node metrics: %w", err)
+		}
+
+		if len(metrics) == 0 {
+			fmt.Println("No worker nodes registered.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		_, _ = fmt.Fprint(
+			w, "NODE\tHOSTNAME\tCPU\tCPU REQUESTED\tCPU CAPACITY\tMEMORY\tMEMORY REQUESTED\tMEMORY CAPACITY\n",
+		)
+
+		for _, m := range metrics {
+			cpuStr, memoryStr := "N/A", "N/A"
+			if m.Available {
+				cpuStr = formatUsage(types.FormatCPU(m.Usage.CPU), m.Usage.CPU, m.Capacity.CPU)
+				memoryStr = formatUsage(types.FormatMemory(m.Usage.Memory), m.Usage.Memory, m.Capacity.Memory)
+			}
+
+			_, _ = fmt.Fprintf(
+				w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				m.NodeID,
+				m.Hostname,
+				cpuStr,
+				formatUsage(types.FormatCPU(m.Requested.CPU), m.Requested.CPU, m.Capacity.CPU),
+				formatQuantity(types.FormatCPU, m.Capacity.CPU),
+				memoryStr,
+				formatUsage(types.FormatMemory(m.Requested.Memory), m.Requested.Memory, m.Capacity.Memory),
+				formatQuantity(types.FormatMemory, m.Capacity.Memory),
+			)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+
+	topCmd.AddCommand(topPodsCmd)
+	topCmd.AddCommand(topNodesCmd)
+}
+
+// printContainerStats prints the usage of each container of a pod
+func printContainerStats(stats *types.PodStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprint(w, "CONTAINER\tCPU\tMEMORY\tMEMORY LIMIT\tNET RX\tNET TX\tBLOCK READ\tBLOCK WRITE\tPIDS\n")
+
+	for _, c := range stats.Containers {
+		_, _ = fmt.Fprintf(
+			w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			c.Name,
+			types.FormatCPU(c.CPU),
+			types.FormatMemory(c.Memory),
+			formatQuantity(types.FormatMemory, c.MemoryLimit),
+			types.FormatMemory(int64(c.NetworkRxBytes)),
+			types.FormatMemory(int64(c.NetworkTxBytes)),
+			types.FormatMemory(int64(c.BlockReadBytes)),
+			types.FormatMemory(int64(c.BlockWriteBytes)),
+			c.PIDs,
+		)
+	}
+
+	_ = w.Flush()
+}
+
+// formatQuantity formats a request, limit or capacity, showing "-" when it is not set
+func formatQuantity(format func(int64) string, value int64) string {
+	if value == 0 {
+		return "-"
+	}
+	return format(value)
+}
+
+// formatUsage formats a usage value with its share of the given total, if any
+func formatUsage(formatted string, used, total int64) string {
+	if total <= 0 {
+		return formatted
+	}
+	return fmt.Sprintf("%s (%d%%)", formatted, used*100/total)
+}
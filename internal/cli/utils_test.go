@@ -2,8 +2,11 @@ package cli
 
 import (
 	"os"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
 )
 
 func TestFormatDuration(t *testing.T) {
@@ -349,3 +352,293 @@ func TestIsVerbose_Multiple(t *testing.T) {
 		t.Error("IsVerbose() should be true")
 	}
 }
+
+func TestFormatNodeStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		node types.Node
+		want string
+	}{
+		{
+			name: "online and ready",
+			node: types.Node{
+				Status:     types.NodeOnline,
+				Conditions: []types.NodeCondition{{Type: types.NodeReady, Status: true}},
+			},
+			want: "online",
+		},
+		{
+			name: "runtime unavailable",
+			node: types.Node{
+				Status: types.NodeOnline,
+				Conditions: []types.NodeCondition{
+					{Type: types.NodeReady, Status: false},
+					{Type: types.NodeRuntimeUnavailable, Status: true},
+				},
+			},
+			want: "online,NotReady,RuntimeUnavailable",
+		},
+		{
+			name: "cordoned under pid pressure",
+			node: types.Node{
+				Status:        types.NodeOnline,
+				Unschedulable: true,
+				Conditions:    []types.NodeCondition{{Type: types.NodePIDPressure, Status: true}},
+			},
+			want: "online,SchedulingDisabled,PIDPressure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := formatNodeStatus(tt.node); got != tt.want {
+					t.Errorf("formatNodeStatus() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	tests := []struct {
+		name  string
+		used  int64
+		total int64
+		want  string
+	}{
+		{name: "share of limit", used: 250, total: 1000, want: "250m (25%)"},
+		{name: "over limit", used: 1500, total: 1000, want: "1.5 (150%)"},
+		{name: "no limit", used: 250, total: 0, want: "250m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := formatUsage(types.FormatCPU(tt.used), tt.used, tt.total); got != tt.want {
+					t.Errorf("formatUsage() = %s, want %s", got, tt.want)
+				}
+			},
+		)
+	}
+
+	if got := formatQuantity(types.FormatMemory, 0); got != "-" {
+		t.Errorf("formatQuantity(0) = %s, want -", got)
+	}
+}
+
+func TestFormatServiceIngress(t *testing.T) {
+	tests := []struct {
+		name    string
+		ingress []types.LoadBalancerIngress
+		want    string
+	}{
+		{name: "pending", want: "<pending>"},
+		{name: "ip", ingress: []types.LoadBalancerIngress{{IP: "192.168.1.240"}}, want: "192.168.1.240"},
+		{
+			name:    "ip and hostname",
+			ingress: []types.LoadBalancerIngress{{IP: "192.168.1.240"}, {Hostname: "lb.example.com"}},
+			want:    "192.168.1.240,lb.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				service := types.Service{Type: types.ServiceTypeLoadBalancer}
+				service.Status.LoadBalancer.Ingress = tt.ingress
+				if got := formatServiceIngress(service); got != tt.want {
+					t.Errorf("formatServiceIngress() = %s, want %s", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestBuildIngressRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		wantRules []types.IngressRule
+		wantTLS   []types.IngressTLS
+		wantErr   bool
+	}{
+		{
+			name:  "prefix, exact and named port",
+			specs: []string{"example.com/*=web:80", "example.com/health=health:http", "/static/*=static:8080"},
+			wantRules: []types.IngressRule{
+				{
+					Host: "example.com",
+					Paths: []types.IngressPath{
+						{
+							Path: "/", PathType: types.PathTypePrefix,
+							Backend: types.IngressBackend{ServiceName: "web", ServicePort: 80},
+						},
+						{
+							Path: "/health", PathType: types.PathTypeExact,
+							Backend: types.IngressBackend{ServiceName: "health", ServicePortName: "http"},
+						},
+					},
+				},
+				{
+					Paths: []types.IngressPath{
+						{
+							Path: "/static/", PathType: types.PathTypePrefix,
+							Backend: types.IngressBackend{ServiceName: "static", ServicePort: 8080},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "host without path and tls",
+			specs: []string{"a.example.com=web:80,tls=site-tls", "b.example.com=web:80,tls=site-tls"},
+			wantRules: []types.IngressRule{
+				{
+					Host: "a.example.com",
+					Paths: []types.IngressPath{
+						{
+							Path: "/", PathType: types.PathTypePrefix,
+							Backend: types.IngressBackend{ServiceName: "web", ServicePort: 80},
+						},
+					},
+				},
+				{
+					Host: "b.example.com",
+					Paths: []types.IngressPath{
+						{
+							Path: "/", PathType: types.PathTypePrefix,
+							Backend: types.IngressBackend{ServiceName: "web", ServicePort: 80},
+						},
+					},
+				},
+			},
+			wantTLS: []types.IngressTLS{{Hosts: []string{"a.example.com", "b.example.com"}, SecretName: "site-tls"}},
+		},
+		{name: "missing backend", specs: []string{"example.com/*"}, wantErr: true},
+		{name: "backend without port", specs: []string{"example.com/*=web"}, wantErr: true},
+		{name: "unknown option", specs: []string{"example.com/*=web:80,cert=x"}, wantErr: true},
+		{name: "tls without host", specs: []string{"/*=web:80,tls=site-tls"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rules, tls, err := buildIngressRules(tt.specs)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("buildIngressRules() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+				if !reflect.DeepEqual(rules, tt.wantRules) {
+					t.Errorf("rules = %+v, want %+v", rules, tt.wantRules)
+				}
+				if !reflect.DeepEqual(tls, tt.wantTLS) {
+					t.Errorf("tls = %+v, want %+v", tls, tt.wantTLS)
+				}
+			},
+		)
+	}
+}
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    types.ServicePort
+		wantErr bool
+	}{
+		{spec: "80", want: types.ServicePort{Port: 80, TargetPort: 80, Protocol: "TCP"}},
+		{spec: "80:8080", want: types.ServicePort{Port: 80, TargetPort: 8080, Protocol: "TCP"}},
+		{spec: "80:web", want: types.ServicePort{Port: 80, TargetPortName: "web", Protocol: "TCP"}},
+		{spec: "http:80", want: types.ServicePort{Name: "http", Port: 80, TargetPort: 80, Protocol: "TCP"}},
+		{
+			spec: "http:80:web",
+			want: types.ServicePort{Name: "http", Port: 80, TargetPortName: "web", Protocol: "TCP"},
+		},
+		{spec: "http:web", wantErr: true},
+		{spec: "http:80:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.spec, func(t *testing.T) {
+				got, err := parsePortSpec(tt.spec)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parsePortSpec() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && got != tt.want {
+					t.Errorf("parsePortSpec() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestParseNetworkPolicyRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		wantPeers []types.NetworkPolicyPeer
+		wantPorts []types.NetworkPolicyPort
+		wantErr   bool
+	}{
+		{name: "empty allows everything", spec: ""},
+		{
+			name: "pods on a port",
+			spec: "pods=app=web,tier=frontend;ports=8080",
+			wantPeers: []types.NetworkPolicyPeer{
+				{PodSelector: &types.LabelSelector{MatchLabels: map[string]string{"app": "web", "tier": "frontend"}}},
+			},
+			wantPorts: []types.NetworkPolicyPort{{Protocol: "TCP", Port: 8080}},
+		},
+		{
+			name: "pods in a namespace",
+			spec: "namespace=ops; pods=app=monitor",
+			wantPeers: []types.NetworkPolicyPeer{
+				{
+					PodSelector:       &types.LabelSelector{MatchLabels: map[string]string{"app": "monitor"}},
+					NamespaceSelector: &types.LabelSelector{MatchLabels: map[string]string{types.NamespaceNameLabel: "ops"}},
+				},
+			},
+		},
+		{
+			name:      "every namespace",
+			spec:      "namespace=*",
+			wantPeers: []types.NetworkPolicyPeer{{NamespaceSelector: &types.LabelSelector{}}},
+		},
+		{
+			name: "cidr with exceptions and ports",
+			spec: "cidr=10.0.0.0/8!10.1.0.0/16!10.2.0.0/16;ports=udp/53,tcp/9000-9100,sctp",
+			wantPeers: []types.NetworkPolicyPeer{
+				{IPBlock: &types.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16", "10.2.0.0/16"}}},
+			},
+			wantPorts: []types.NetworkPolicyPort{
+				{Protocol: "UDP", Port: 53},
+				{Protocol: "TCP", Port: 9000, EndPort: 9100},
+				{Protocol: "SCTP"},
+			},
+		},
+		{name: "unknown field", spec: "hosts=example.com", wantErr: true},
+		{name: "field without value", spec: "pods=", wantErr: true},
+		{name: "invalid port", spec: "ports=tcp/http", wantErr: true},
+		{name: "invalid range", spec: "ports=8000-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				peers, ports, err := parseNetworkPolicyRule(tt.spec)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseNetworkPolicyRule(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+				if !reflect.DeepEqual(peers, tt.wantPeers) || !reflect.DeepEqual(ports, tt.wantPorts) {
+					t.Errorf("parseNetworkPolicyRule(%q) = %+v, %+v, want %+v, %+v", tt.spec, peers, ports, tt.wantPeers, tt.wantPorts)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,327 @@
+// Package ingress implements the ingress gateway, an HTTP reverse proxy that routes requests to
+// service endpoints by host and path according to Ingress resources. It runs in the master,
+// reading the state store and terminating TLS with certificates from TLS secrets, or on worker
+// nodes, reading the master API and serving plain HTTP.
+package ingress
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Config controls where the gateway listens and how it proxies
+type Config struct {
+	// Addr is the address plain HTTP is served on; empty disables it
+	Addr string
+
+	// TLSAddr is the address HTTPS is served on; empty disables it
+	TLSAddr string
+
+	// SyncInterval is how often ingresses, services and endpoints are fetched
+	SyncInterval time.Duration
+
+	// Retries is how many other endpoints a failed request is sent to
+	Retries int
+
+	// DialTimeout bounds each connection attempt to an endpoint
+	DialTimeout time.Duration
+
+	// UnhealthyCooldown is how long a failed endpoint is tried only after the others
+	UnhealthyCooldown time.Duration
+
+	// AccessLog logs one line per request
+	AccessLog bool
+}
+
+// DefaultConfig returns the default gateway settings, serving plain HTTP on :80
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":80",
+		SyncInterval:      5 * time.Second,
+		Retries:           2,
+		DialTimeout:       2 * time.Second,
+		UnhealthyCooldown: 10 * time.Second,
+		AccessLog:         true,
+	}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.Addr == "" && c.TLSAddr == "" {
+		return errors.New("an HTTP or HTTPS listen address is required")
+	}
+	if c.SyncInterval <= 0 {
+		return fmt.Errorf("sync interval must be positive, got %v", c.SyncInterval)
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative, got %d", c.Retries)
+	}
+	if c.DialTimeout <= 0 {
+		return fmt.Errorf("dial timeout must be positive, got %v", c.DialTimeout)
+	}
+	if c.UnhealthyCooldown < 0 {
+		return fmt.Errorf("unhealthy cooldown must not be negative, got %v", c.UnhealthyCooldown)
+	}
+	return nil
+}
+
+// Gateway routes HTTP requests to service endpoints according to ingresses
+type Gateway struct {
+	source Source
+	config Config
+	proxy  *httputil.ReverseProxy
+
+	table atomic.Pointer[table]
+}
+
+// NewGateway creates a gateway that routes by the ingresses from source
+func NewGateway(source Source, config Config) *Gateway {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.DialContext = (&net.Dialer{Timeout: config.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+
+	g := &Gateway{source: source, config: config}
+	g.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			// The transport fills in the endpoint; the original Host header is kept
+			pr.Out.URL.Scheme = "http"
+			pr.Out.URL.Host = "endpoint"
+			pr.SetXForwarded()
+		},
+		Transport: &retryTransport{base: base, health: newHealth(config.UnhealthyCooldown), retries: config.Retries},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() == nil {
+				log.Printf("ingress: proxying %s%s failed: %v", r.Host, r.URL.Path, err)
+			}
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		},
+	}
+	g.table.Store(buildTable(nil, nil, nil))
+	return g
+}
+
+// Sync fetches ingresses, services and endpoints and replaces the routing table. TLS secrets
+// that cannot be loaded are logged and skipped. When the source cannot be reached the current
+// table is kept.
+func (g *Gateway) Sync(ctx context.Context) error {
+	ingresses, err := g.source.ListIngresses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	services, err := g.source.ListServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	endpoints, err := g.source.ListEndpoints(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	t := buildTable(ingresses, services, endpoints)
+	if g.config.TLSAddr != "" {
+		g.loadCertificates(ctx, t, ingresses)
+	}
+	g.table.Store(t)
+	return nil
+}
+
+// loadCertificates adds the certificates of the ingresses' TLS secrets to t
+func (g *Gateway) loadCertificates(ctx context.Context, t *table, ingresses []types.Ingress) {
+	for _, ingress := range ingresses {
+		namespace := namespaceOf(ingress.Namespace)
+		for _, entry := range ingress.TLS {
+			cert, err := g.certificate(ctx, namespace, entry.SecretName)
+			if err != nil {
+				log.Printf("ingress: skipping TLS for %s: %v", ingressName(ingress), err)
+				continue
+			}
+			for _, host := range entry.Hosts {
+				if _, ok := t.certificates[hostname(host)]; !ok {
+					t.certificates[hostname(host)] = cert
+				}
+			}
+		}
+	}
+}
+
+// certificate loads the key pair stored in a TLS secret
+func (g *Gateway) certificate(ctx context.Context, namespace, name string) (*tls.Certificate, error) {
+	secret, err := g.source.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	if secret.Type != types.SecretTypeTLS {
+		return nil, fmt.Errorf("secret %s/%s is not of type %s", namespace, name, types.SecretTypeTLS)
+	}
+
+	cert, err := tls.X509KeyPair([]byte(secret.Data[types.SecretKeyTLSCert]), []byte(secret.Data[types.SecretKeyTLSKey]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pair in secret %s/%s: %w", namespace, name, err)
+	}
+	return &cert, nil
+}
+
+// getCertificate picks the certificate for a TLS handshake by server name
+func (g *Gateway) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := g.table.Load().certificate(hello.ServerName); cert != nil {
+		return cert, nil
+	}
+	return nil, fmt.Errorf("no certificate for %q", hello.ServerName)
+}
+
+// ServeHTTP routes a request to its backend and logs it
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &responseRecorder{ResponseWriter: w}
+	state := &requestState{backend: g.table.Load().match(r.Host, r.URL.Path)}
+
+	switch {
+	case state.backend == nil:
+		http.Error(rec, "no ingress rule matches", http.StatusNotFound)
+	case len(state.backend.endpoints) == 0:
+		http.Error(rec, "no ready endpoints for "+state.backend.service, http.StatusServiceUnavailable)
+	default:
+		g.proxy.ServeHTTP(rec, r.WithContext(withRequestState(r.Context(), state)))
+	}
+
+	if g.config.AccessLog {
+		logAccess(r, rec, state, time.Since(start))
+	}
+}
+
+// logAccess writes the access log line of a request
+func logAccess(r *http.Request, rec *responseRecorder, state *requestState, duration time.Duration) {
+	ingress, service, endpoint := "-", "-", "-"
+	if state.backend != nil {
+		ingress, service = state.backend.ingress, state.backend.service
+	}
+	if state.endpoint != "" {
+		endpoint = state.endpoint
+	}
+
+	log.Printf(
+		"ingress: %s %q %d %dB %v host=%s ingress=%s backend=%s endpoint=%s attempts=%d",
+		r.RemoteAddr, r.Method+" "+r.URL.RequestURI()+" "+r.Proto, rec.status(), rec.bytes,
+		duration.Round(time.Microsecond), r.Host, ingress, service, endpoint, state.attempts,
+	)
+}
+
+// Run syncs immediately and then every sync interval until ctx is cancelled
+func (g *Gateway) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		syncCtx, cancel := context.WithTimeout(ctx, g.config.SyncInterval)
+		if err := g.Sync(syncCtx); err != nil && ctx.Err() == nil {
+			log.Printf("ingress sync failed: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListenAndServe listens on the configured addresses, keeps the routing table in sync and
+// serves until ctx is cancelled
+func (g *Gateway) ListenAndServe(ctx context.Context) error {
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, ln := range listeners {
+			_ = ln.Close()
+		}
+	}
+
+	if g.config.Addr != "" {
+		ln, err := net.Listen("tcp", g.config.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", g.config.Addr, err)
+		}
+		listeners = append(listeners, ln)
+	}
+	if g.config.TLSAddr != "" {
+		ln, err := net.Listen("tcp", g.config.TLSAddr)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("failed to listen on %s: %w", g.config.TLSAddr, err)
+		}
+		listeners = append(
+			listeners, tls.NewListener(
+				ln, &tls.Config{GetCertificate: g.getCertificate, MinVersion: tls.VersionTLS12},
+			),
+		)
+	}
+
+	go g.Run(ctx)
+
+	server := &http.Server{Handler: g, ReadHeaderTimeout: 10 * time.Second}
+	var wg sync.WaitGroup
+	for _, ln := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("ingress: serving on %s failed: %v", ln.Addr(), err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = server.Shutdown(shutdownCtx)
+	wg.Wait()
+	return nil
+}
+
+// responseRecorder records the status and size of a response for the access log
+type responseRecorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+// WriteHeader records the status code
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the number of bytes written
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer, so proxied responses can be flushed
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// status returns the recorded status code
+func (r *responseRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
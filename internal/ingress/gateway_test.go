@@ -0,0 +1,322 @@
+package ingress
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// fakeSource serves fixed ingresses, services, endpoints and secrets
+type fakeSource struct {
+	ingresses []types.Ingress
+	services  []types.Service
+	endpoints []types.Endpoints
+	secrets   map[string]types.Secret
+}
+
+func (s *fakeSource) ListIngresses(context.Context) ([]types.Ingress, error) { return s.ingresses, nil }
+func (s *fakeSource) ListServices(context.Context) ([]types.Service, error)  { return s.services, nil }
+func (s *fakeSource) ListEndpoints(context.Context) ([]types.Endpoints, error) {
+	return s.endpoints, nil
+}
+
+func (s *fakeSource) GetSecret(_ context.Context, namespace, name string) (types.Secret, error) {
+	secret, ok := s.secrets[namespace+"/"+name]
+	if !ok {
+		return types.Secret{}, fmt.Errorf("secret %s/%s not found", namespace, name)
+	}
+	return secret, nil
+}
+
+// addService adds a service named name on port 80 whose endpoints are addrs
+func (s *fakeSource) addService(name string, addrs ...string) {
+	id := "svc-" + name
+	s.services = append(
+		s.services, types.Service{
+			ServiceID: id, Name: name, Namespace: "default", Ports: []types.ServicePort{{Port: 80}},
+		},
+	)
+
+	ep := types.Endpoints{ServiceID: id, ServiceName: name, Namespace: "default"}
+	for _, addr := range addrs {
+		host, port, _ := net.SplitHostPort(addr)
+		p, _ := strconv.Atoi(port)
+		ep.Subsets = append(
+			ep.Subsets, types.EndpointSubset{
+				Addresses: []types.EndpointAddress{{IP: host}},
+				Ports:     []types.EndpointPort{{Port: p}},
+			},
+		)
+	}
+	s.endpoints = append(s.endpoints, ep)
+}
+
+// backendServer starts an HTTP server answering with its name, the Host header and the body
+func backendServer(t *testing.T, name string, status int) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				w.WriteHeader(status)
+				_, _ = fmt.Fprintf(w, "%s %s %s %s", name, r.Host, r.Header.Get("X-Forwarded-Host"), body)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// closedAddr returns a local address nothing listens on
+func closedAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func newTestGateway(t *testing.T, source *fakeSource) *Gateway {
+	config := DefaultConfig()
+	config.AccessLog = false
+	g := NewGateway(source, config)
+	if err := g.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	return g
+}
+
+func serve(g *Gateway, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGatewayRouting(t *testing.T) {
+	web, _ := backendServer(t, "web", http.StatusOK)
+	api, _ := backendServer(t, "api", http.StatusOK)
+
+	source := &fakeSource{}
+	source.addService("web", web.Listener.Addr().String())
+	source.addService("api", api.Listener.Addr().String())
+	source.addService("empty")
+	source.ingresses = []types.Ingress{
+		{
+			Name: "site",
+			Rules: []types.IngressRule{
+				{
+					Host: "example.com",
+					Paths: []types.IngressPath{
+						{Path: "/", PathType: types.PathTypePrefix, Backend: backendTo("web", 80)},
+						{Path: "/api", PathType: types.PathTypePrefix, Backend: backendTo("api", 80)},
+						{Path: "/down", PathType: types.PathTypeExact, Backend: backendTo("empty", 80)},
+					},
+				},
+			},
+		},
+	}
+	g := newTestGateway(t, source)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"root", "http://example.com/", http.StatusOK, "web example.com example.com "},
+		{"prefix", "http://example.com/api/users", http.StatusOK, "api example.com example.com "},
+		{"no endpoints", "http://example.com/down", http.StatusServiceUnavailable, "no ready endpoints"},
+		{"unknown host", "http://other.test/", http.StatusNotFound, "no ingress rule matches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := serve(g, http.MethodGet, tt.target, "")
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+				}
+				if !strings.HasPrefix(rec.Body.String(), tt.wantBody) {
+					t.Errorf("expected body to start with %q, got %q", tt.wantBody, rec.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func TestGatewayRetries(t *testing.T) {
+	healthy, healthyHits := backendServer(t, "healthy", http.StatusOK)
+	failing, failingHits := backendServer(t, "failing", http.StatusServiceUnavailable)
+
+	source := &fakeSource{}
+	source.addService("web", closedAddr(t), failing.Listener.Addr().String(), healthy.Listener.Addr().String())
+	source.ingresses = []types.Ingress{
+		{Name: "site", DefaultBackend: &types.IngressBackend{ServiceName: "web", ServicePort: 80}},
+	}
+	g := newTestGateway(t, source)
+
+	for i := 0; i < 3; i++ {
+		rec := serve(g, http.MethodPut, "http://example.com/", "payload")
+		if rec.Code != http.StatusOK || !strings.HasSuffix(rec.Body.String(), "payload") {
+			t.Fatalf("request %d: expected the healthy endpoint to echo the body, got %d %q", i, rec.Code, rec.Body)
+		}
+	}
+	if got := healthyHits.Load(); got != 3 {
+		t.Errorf("expected 3 requests to reach the healthy endpoint, got %d", got)
+	}
+	// After failing once, the failing endpoint is only tried after the healthy one
+	if got := failingHits.Load(); got != 1 {
+		t.Errorf("expected the failing endpoint to be tried once, got %d", got)
+	}
+
+	// POSTs the failing endpoint answered are not sent again; rotation sends one of two there
+	source.services, source.endpoints = nil, nil
+	source.addService("web", failing.Listener.Addr().String(), healthy.Listener.Addr().String())
+	g = newTestGateway(t, source)
+	statuses := map[int]int{}
+	for i := 0; i < 2; i++ {
+		statuses[serve(g, http.MethodPost, "http://example.com/", "order").Code]++
+	}
+	if statuses[http.StatusOK] != 1 || statuses[http.StatusServiceUnavailable] != 1 {
+		t.Errorf("expected one 200 and one 503 for two POSTs, got %v", statuses)
+	}
+	if got := healthyHits.Load(); got != 4 {
+		t.Errorf("expected the healthy endpoint to get 4 requests in total, got %d", got)
+	}
+}
+
+func TestHealthOrder(t *testing.T) {
+	h := newHealth(10 * time.Second)
+	endpoints := []string{"a:80", "b:80", "c:80"}
+
+	if got := h.order(endpoints, 1); !reflect.DeepEqual(got, []string{"b:80", "c:80", "a:80"}) {
+		t.Errorf("order() = %v, want rotation starting at b:80", got)
+	}
+
+	h.failed("b:80")
+	if got := h.order(endpoints, 1); !reflect.DeepEqual(got, []string{"c:80", "a:80", "b:80"}) {
+		t.Errorf("order() = %v, want b:80 last while unhealthy", got)
+	}
+
+	h.succeeded("b:80")
+	if got := h.order(endpoints, 1); got[0] != "b:80" {
+		t.Errorf("order() = %v, want b:80 first once healthy again", got)
+	}
+}
+
+// selfSignedTLSSecret returns a TLS secret with a certificate for hosts
+func selfSignedTLSSecret(t *testing.T, name string, hosts ...string) types.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	return types.Secret{
+		Name: name,
+		Type: types.SecretTypeTLS,
+		Data: map[string]string{
+			types.SecretKeyTLSCert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			types.SecretKeyTLSKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		},
+	}
+}
+
+func TestGatewayCertificates(t *testing.T) {
+	source := &fakeSource{
+		secrets: map[string]types.Secret{
+			"default/site-tls":     selfSignedTLSSecret(t, "site-tls", "example.com"),
+			"default/wildcard-tls": selfSignedTLSSecret(t, "wildcard-tls", "*.example.com"),
+			"default/opaque":       {Name: "opaque", Type: types.SecretTypeOpaque},
+		},
+	}
+	source.ingresses = []types.Ingress{
+		{
+			Name:           "site",
+			DefaultBackend: &types.IngressBackend{ServiceName: "web", ServicePort: 80},
+			TLS: []types.IngressTLS{
+				{Hosts: []string{"example.com"}, SecretName: "site-tls"},
+				{Hosts: []string{"*.example.com"}, SecretName: "wildcard-tls"},
+				{Hosts: []string{"bad.test"}, SecretName: "opaque"},
+			},
+		},
+	}
+
+	config := DefaultConfig()
+	config.TLSAddr = ":443"
+	g := NewGateway(source, config)
+	if err := g.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	tests := []struct {
+		serverName string
+		wantName   string
+	}{
+		{"example.com", "example.com"},
+		{"www.example.com", "*.example.com"},
+		{"bad.test", ""},
+		{"unknown.test", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.serverName, func(t *testing.T) {
+				cert, err := g.getCertificate(&tls.ClientHelloInfo{ServerName: tt.serverName})
+				if tt.wantName == "" {
+					if err == nil {
+						t.Error("expected no certificate")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("getCertificate failed: %v", err)
+				}
+				leaf, err := x509.ParseCertificate(cert.Certificate[0])
+				if err != nil {
+					t.Fatalf("failed to parse certificate: %v", err)
+				}
+				if leaf.DNSNames[0] != tt.wantName {
+					t.Errorf("expected certificate for %s, got %v", tt.wantName, leaf.DNSNames)
+				}
+			},
+		)
+	}
+}
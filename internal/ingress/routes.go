@@ -0,0 +1,226 @@
+package ingress
+
+import (
+	"crypto/tls"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// backend is a resolved ingress backend: a service port and its ready endpoints
+type backend struct {
+	// ingress is the namespaced name of the ingress the backend belongs to, for logging
+	ingress string
+
+	// service is the backend as namespace/service:port, for logging
+	service string
+
+	// endpoints are the host:port addresses of the ready endpoints, sorted
+	endpoints []string
+
+	// next rotates the endpoint requests start with
+	next atomic.Uint64
+}
+
+// route sends requests whose path matches to a backend
+type route struct {
+	path    string
+	exact   bool
+	backend *backend
+}
+
+// matches reports whether the request path matches the route. Prefixes match whole path
+// elements, so "/api" matches "/api" and "/api/users" but not "/apis".
+func (r route) matches(path string) bool {
+	if r.exact {
+		return path == r.path
+	}
+	prefix := strings.TrimSuffix(r.path, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// table is the routing state built from one sync
+type table struct {
+	hosts     map[string][]route // by exact host
+	wildcards map[string][]route // by the domain a "*." host is under
+	anyHost   []route            // rules without a host
+	fallback  *backend           // the first ingress's default backend
+
+	certificates map[string]*tls.Certificate // by host, wildcards included as "*.domain"
+}
+
+// buildTable resolves the ingresses' rules against services and endpoints. Ingresses are
+// applied in namespace/name order, so the older name wins when two define the same route.
+func buildTable(ingresses []types.Ingress, services []types.Service, endpoints []types.Endpoints) *table {
+	t := &table{
+		hosts:        make(map[string][]route),
+		wildcards:    make(map[string][]route),
+		certificates: make(map[string]*tls.Certificate),
+	}
+
+	byName := make(map[string]types.Service, len(services))
+	for _, service := range services {
+		byName[namespaceOf(service.Namespace)+"/"+service.Name] = service
+	}
+	byService := make(map[string]types.Endpoints, len(endpoints))
+	for _, ep := range endpoints {
+		byService[ep.ServiceID] = ep
+	}
+
+	sorted := append([]types.Ingress(nil), ingresses...)
+	sort.SliceStable(
+		sorted, func(i, j int) bool {
+			return ingressName(sorted[i]) < ingressName(sorted[j])
+		},
+	)
+
+	for _, ingress := range sorted {
+		namespace := namespaceOf(ingress.Namespace)
+		resolve := func(b types.IngressBackend) *backend {
+			return resolveBackend(ingressName(ingress), namespace, b, byName, byService)
+		}
+
+		if ingress.DefaultBackend != nil && t.fallback == nil {
+			t.fallback = resolve(*ingress.DefaultBackend)
+		}
+
+		for _, rule := range ingress.Rules {
+			host := strings.ToLower(rule.Host)
+			for _, path := range rule.Paths {
+				r := route{path: path.Path, exact: path.PathType == types.PathTypeExact, backend: resolve(path.Backend)}
+				switch {
+				case host == "":
+					t.anyHost = append(t.anyHost, r)
+				case strings.HasPrefix(host, "*."):
+					t.wildcards[host[2:]] = append(t.wildcards[host[2:]], r)
+				default:
+					t.hosts[host] = append(t.hosts[host], r)
+				}
+			}
+		}
+	}
+
+	sortRoutes(t.anyHost)
+	for _, routes := range t.hosts {
+		sortRoutes(routes)
+	}
+	for _, routes := range t.wildcards {
+		sortRoutes(routes)
+	}
+	return t
+}
+
+// sortRoutes orders routes so the first match is the most specific: exact paths first, then
+// prefixes from longest to shortest
+func sortRoutes(routes []route) {
+	sort.SliceStable(
+		routes, func(i, j int) bool {
+			if routes[i].exact != routes[j].exact {
+				return routes[i].exact
+			}
+			return len(strings.TrimSuffix(routes[i].path, "/")) > len(strings.TrimSuffix(routes[j].path, "/"))
+		},
+	)
+}
+
+// match finds the backend for a request. Rules for the exact host are tried first, then
+// wildcard rules, then rules without a host, then the default backend; it returns nil when
+// nothing matches.
+func (t *table) match(host, path string) *backend {
+	host = hostname(host)
+
+	if b := matchRoutes(t.hosts[host], path); b != nil {
+		return b
+	}
+	if i := strings.IndexByte(host, '.'); i > 0 {
+		if b := matchRoutes(t.wildcards[host[i+1:]], path); b != nil {
+			return b
+		}
+	}
+	if b := matchRoutes(t.anyHost, path); b != nil {
+		return b
+	}
+	return t.fallback
+}
+
+// certificate returns the certificate for a TLS server name, preferring an exact host over a
+// wildcard, or nil if there is none
+func (t *table) certificate(serverName string) *tls.Certificate {
+	host := hostname(serverName)
+	if cert, ok := t.certificates[host]; ok {
+		return cert
+	}
+	if i := strings.IndexByte(host, '.'); i > 0 {
+		return t.certificates["*."+host[i+1:]]
+	}
+	return nil
+}
+
+// matchRoutes returns the backend of the first route matching path
+func matchRoutes(routes []route, path string) *backend {
+	for _, r := range routes {
+		if r.matches(path) {
+			return r.backend
+		}
+	}
+	return nil
+}
+
+// resolveBackend finds the service port a backend names and its ready endpoint addresses. A
+// missing service or port resolves to a backend without endpoints.
+func resolveBackend(
+	ingress, namespace string, b types.IngressBackend,
+	services map[string]types.Service, endpoints map[string]types.Endpoints,
+) *backend {
+	resolved := &backend{ingress: ingress, service: namespace + "/" + b.String()}
+
+	service, ok := services[namespace+"/"+b.ServiceName]
+	if !ok {
+		return resolved
+	}
+
+	for i, port := range service.Ports {
+		if (b.ServicePortName != "" && port.Name != b.ServicePortName) ||
+			(b.ServicePortName == "" && port.Port != b.ServicePort) {
+			continue
+		}
+		for _, subset := range endpoints[service.ServiceID].Subsets {
+			target, ok := subset.TargetPort(i, port)
+			if !ok {
+				continue
+			}
+			for _, addr := range subset.Addresses {
+				resolved.endpoints = append(resolved.endpoints, net.JoinHostPort(addr.IP, strconv.Itoa(target)))
+			}
+		}
+		break
+	}
+
+	sort.Strings(resolved.endpoints)
+	return resolved
+}
+
+// hostname lower-cases a request host and strips its port
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// ingressName returns the namespaced name of an ingress
+func ingressName(ingress types.Ingress) string {
+	return namespaceOf(ingress.Namespace) + "/" + ingress.Name
+}
+
+// namespaceOf returns the namespace, defaulting to "default"
+func namespaceOf(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
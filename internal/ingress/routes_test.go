@@ -0,0 +1,146 @@
+package ingress
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func backendTo(service string, port int) types.IngressBackend {
+	return types.IngressBackend{ServiceName: service, ServicePort: port}
+}
+
+func TestTableMatch(t *testing.T) {
+	ingresses := []types.Ingress{
+		{
+			Name: "web",
+			Rules: []types.IngressRule{
+				{
+					Host: "example.com",
+					Paths: []types.IngressPath{
+						{Path: "/", PathType: types.PathTypePrefix, Backend: backendTo("web", 80)},
+						{Path: "/api", PathType: types.PathTypePrefix, Backend: backendTo("api", 80)},
+						{Path: "/api/v2/", PathType: types.PathTypePrefix, Backend: backendTo("api-v2", 80)},
+						{Path: "/api/health", PathType: types.PathTypeExact, Backend: backendTo("health", 80)},
+					},
+				},
+				{
+					Host:  "*.apps.example.com",
+					Paths: []types.IngressPath{{Path: "/", Backend: backendTo("apps", 80)}},
+				},
+				{
+					Paths: []types.IngressPath{{Path: "/static", Backend: backendTo("static", 80)}},
+				},
+			},
+		},
+		{
+			Name:           "catch-all",
+			Namespace:      "other",
+			DefaultBackend: &types.IngressBackend{ServiceName: "fallback", ServicePortName: "http"},
+		},
+	}
+	table := buildTable(ingresses, nil, nil)
+
+	tests := []struct {
+		host string
+		path string
+		want string
+	}{
+		{"example.com", "/", "default/web:80"},
+		{"example.com", "/about", "default/web:80"},
+		{"Example.COM:8080", "/api", "default/api:80"},
+		{"example.com", "/api/users", "default/api:80"},
+		{"example.com", "/apis", "default/web:80"},
+		{"example.com", "/api/v2", "default/api-v2:80"},
+		{"example.com", "/api/v2/users", "default/api-v2:80"},
+		{"example.com", "/api/health", "default/health:80"},
+		{"example.com", "/api/health/deep", "default/api:80"},
+		{"shop.apps.example.com", "/cart", "default/apps:80"},
+		{"a.shop.apps.example.com", "/cart", "other/fallback:http"},
+		{"apps.example.com", "/", "other/fallback:http"},
+		{"unknown.test", "/static/app.js", "default/static:80"},
+		{"unknown.test", "/", "other/fallback:http"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.host+tt.path, func(t *testing.T) {
+				b := table.match(tt.host, tt.path)
+				if b == nil {
+					t.Fatalf("match(%q, %q) = nil, want %s", tt.host, tt.path, tt.want)
+				}
+				if b.service != tt.want {
+					t.Errorf("match(%q, %q) = %s, want %s", tt.host, tt.path, b.service, tt.want)
+				}
+			},
+		)
+	}
+
+	if b := buildTable(ingresses[:1], nil, nil).match("unknown.test", "/"); b != nil {
+		t.Errorf("expected no match without a default backend, got %s", b.service)
+	}
+}
+
+func TestResolveBackendEndpoints(t *testing.T) {
+	services := []types.Service{
+		{
+			ServiceID: "svc-1",
+			Name:      "web",
+			Namespace: "default",
+			Ports: []types.ServicePort{
+				{Name: "http", Port: 80, TargetPort: 8080},
+				{Name: "admin", Port: 9000, TargetPort: 9000},
+			},
+		},
+	}
+	endpoints := []types.Endpoints{
+		{
+			ServiceID: "svc-1",
+			Subsets: []types.EndpointSubset{
+				{
+					Addresses:         []types.EndpointAddress{{IP: "10.0.0.2"}, {IP: "10.0.0.1"}},
+					NotReadyAddresses: []types.EndpointAddress{{IP: "10.0.0.3"}},
+					Ports:             []types.EndpointPort{{Name: "http", Port: 8081}, {Name: "admin", Port: 9001}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		backend types.IngressBackend
+		want    []string
+	}{
+		{
+			name:    "by port number",
+			backend: backendTo("web", 80),
+			want:    []string{"10.0.0.1:8081", "10.0.0.2:8081"},
+		},
+		{
+			name:    "by port name",
+			backend: types.IngressBackend{ServiceName: "web", ServicePortName: "admin"},
+			want:    []string{"10.0.0.1:9001", "10.0.0.2:9001"},
+		},
+		{
+			name:    "unknown port",
+			backend: backendTo("web", 443),
+		},
+		{
+			name:    "unknown service",
+			backend: backendTo("missing", 80),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				ingress := types.Ingress{Name: "web", DefaultBackend: &tt.backend}
+				b := buildTable([]types.Ingress{ingress}, services, endpoints).fallback
+				if !reflect.DeepEqual(b.endpoints, tt.want) {
+					t.Errorf("endpoints = %v, want %v", b.endpoints, tt.want)
+				}
+			},
+		)
+	}
+}
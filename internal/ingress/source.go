@@ -0,0 +1,137 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// ErrSecretsUnavailable is returned by sources that cannot read secret values
+var ErrSecretsUnavailable = errors.New("secrets are not available from this source")
+
+// Source provides the ingresses, services and endpoints the gateway routes by
+type Source interface {
+	ListIngresses(ctx context.Context) ([]types.Ingress, error)
+	ListServices(ctx context.Context) ([]types.Service, error)
+	ListEndpoints(ctx context.Context) ([]types.Endpoints, error)
+
+	// GetSecret returns a secret with its values, for TLS certificates
+	GetSecret(ctx context.Context, namespace, name string) (types.Secret, error)
+}
+
+// StoreSource reads directly from the master's state store
+type StoreSource struct {
+	store state.StateStore
+}
+
+// NewStoreSource creates a source backed by store
+func NewStoreSource(store state.StateStore) *StoreSource {
+	return &StoreSource{store: store}
+}
+
+// ListIngresses returns the ingresses in all namespaces
+func (s *StoreSource) ListIngresses(_ context.Context) ([]types.Ingress, error) {
+	return s.store.ListIngresses("")
+}
+
+// ListServices returns the services in all namespaces
+func (s *StoreSource) ListServices(_ context.Context) ([]types.Service, error) {
+	return s.store.ListServices("")
+}
+
+// ListEndpoints returns the endpoints of all services
+func (s *StoreSource) ListEndpoints(_ context.Context) ([]types.Endpoints, error) {
+	services, err := s.store.ListServices("")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]types.Endpoints, 0, len(services))
+	for _, service := range services {
+		if ep, err := s.store.GetEndpoints(service.ServiceID); err == nil {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// GetSecret returns a secret by namespace and name
+func (s *StoreSource) GetSecret(_ context.Context, namespace, name string) (types.Secret, error) {
+	return s.store.GetSecretByName(namespace, name)
+}
+
+// MasterSource reads ingresses, services and endpoints from the master API. The API redacts
+// secret values, so gateways using it serve plain HTTP only.
+type MasterSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewMasterSource creates a source backed by the master at masterURL
+func NewMasterSource(masterURL string) *MasterSource {
+	return &MasterSource{
+		url:    strings.TrimSuffix(masterURL, "/") + "/api/v1",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ListIngresses returns the ingresses in all namespaces
+func (s *MasterSource) ListIngresses(ctx context.Context) ([]types.Ingress, error) {
+	var ingresses []types.Ingress
+	if err := s.get(ctx, "/ingresses", &ingresses); err != nil {
+		return nil, err
+	}
+	return ingresses, nil
+}
+
+// ListServices returns the services in all namespaces
+func (s *MasterSource) ListServices(ctx context.Context) ([]types.Service, error) {
+	var services []types.Service
+	if err := s.get(ctx, "/services", &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// ListEndpoints returns the endpoints of all services
+func (s *MasterSource) ListEndpoints(ctx context.Context) ([]types.Endpoints, error) {
+	var endpoints []types.Endpoints
+	if err := s.get(ctx, "/endpoints", &endpoints); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// GetSecret always fails, as the master API never returns secret values
+func (s *MasterSource) GetSecret(_ context.Context, _, _ string) (types.Secret, error) {
+	return types.Secret{}, ErrSecretsUnavailable
+}
+
+// get decodes the JSON response of a master API path into v
+func (s *MasterSource) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach master: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("master returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
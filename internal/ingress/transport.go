@@ -0,0 +1,204 @@
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxReplayBody is the largest request body buffered so it can be sent again on a retry;
+// requests with larger bodies are tried once
+const maxReplayBody = 1 << 20
+
+// health tracks endpoints that recently failed. Failed endpoints are tried after healthy ones
+// until their cooldown passes or a request to them succeeds.
+type health struct {
+	now      func() time.Time
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	unhealthy map[string]time.Time // endpoint -> end of its cooldown
+}
+
+// newHealth creates a health tracker with the given cooldown
+func newHealth(cooldown time.Duration) *health {
+	return &health{
+		now:       time.Now,
+		cooldown:  cooldown,
+		unhealthy: make(map[string]time.Time),
+	}
+}
+
+// failed marks endpoint unhealthy for the cooldown
+func (h *health) failed(endpoint string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	for ep, until := range h.unhealthy {
+		if now.After(until) {
+			delete(h.unhealthy, ep)
+		}
+	}
+	if h.cooldown > 0 {
+		h.unhealthy[endpoint] = now.Add(h.cooldown)
+	}
+}
+
+// succeeded marks endpoint healthy again
+func (h *health) succeeded(endpoint string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.unhealthy, endpoint)
+}
+
+// order returns the endpoints starting at start, with the healthy ones before the unhealthy
+// ones, so a request still goes somewhere when every endpoint failed recently
+func (h *health) order(endpoints []string, start int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	healthy := make([]string, 0, len(endpoints))
+	var unhealthy []string
+	for i := range endpoints {
+		endpoint := endpoints[(start+i)%len(endpoints)]
+		if until, ok := h.unhealthy[endpoint]; ok && now.Before(until) {
+			unhealthy = append(unhealthy, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// requestState carries a request's backend to the transport and the endpoint that served it
+// back to the access log
+type requestState struct {
+	backend  *backend
+	endpoint string
+	attempts int
+}
+
+type requestStateKey struct{}
+
+// withRequestState attaches state to ctx
+func withRequestState(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, requestStateKey{}, state)
+}
+
+// retryTransport sends a proxied request to its backend's endpoints, moving on to the next
+// endpoint when one cannot be reached or answers 502, 503 or 504. Requests that may have been
+// processed are only retried when their method is idempotent.
+type retryTransport struct {
+	base    http.RoundTripper
+	health  *health
+	retries int
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	state, ok := req.Context().Value(requestStateKey{}).(*requestState)
+	if !ok || len(state.backend.endpoints) == 0 {
+		return nil, errors.New("request has no backend endpoints")
+	}
+
+	body, replayable, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b := state.backend
+	candidates := t.health.order(b.endpoints, int(b.next.Add(1)-1)%len(b.endpoints))
+	attempts := min(t.retries+1, len(candidates))
+	if !replayable {
+		attempts = 1
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		endpoint := candidates[i]
+		last := i == attempts-1
+
+		out := req.Clone(req.Context())
+		out.URL.Host = endpoint
+		if body != nil {
+			out.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		state.endpoint, state.attempts = endpoint, i+1
+		resp, err := t.base.RoundTrip(out)
+		if err != nil {
+			t.health.failed(endpoint)
+			lastErr = err
+			if req.Context().Err() != nil || (!isDialError(err) && !idempotent(req.Method)) {
+				break
+			}
+			continue
+		}
+
+		if retryableStatus(resp.StatusCode) {
+			t.health.failed(endpoint)
+			if !last && idempotent(req.Method) {
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+				_ = resp.Body.Close()
+				continue
+			}
+		} else {
+			t.health.succeeded(endpoint)
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// replayableBody buffers the request body if it is small enough to be sent more than once. It
+// returns a nil body for requests without one; a body too large to buffer is left in place,
+// with the part already read put back in front of it.
+func replayableBody(req *http.Request) ([]byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(req.Body, maxReplayBody+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(buf) > maxReplayBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), req.Body), req.Body}
+		return nil, false, nil
+	}
+	_ = req.Body.Close()
+	return buf, true, nil
+}
+
+// isDialError reports whether err means the endpoint could not be connected to, so the
+// request was never sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// idempotent reports whether a request with method can safely be sent twice
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryableStatus reports whether a response status means another endpoint should be tried
+func retryableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,53 @@
+// Package admission validates pods against cluster policy before they are stored.
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Plugin inspects a pod and rejects it by returning an error
+type Plugin interface {
+	// Name returns the plugin name used for configuration
+	Name() string
+
+	// AdmitPod returns an error if the pod must not be created
+	AdmitPod(pod *types.Pod) error
+}
+
+// Chain runs a list of plugins in order, rejecting on the first failure
+type Chain []Plugin
+
+// AdmitPod runs all plugins against the pod
+func (c Chain) AdmitPod(pod *types.Pod) error {
+	for _, plugin := range c {
+		if err := plugin.AdmitPod(pod); err != nil {
+			return fmt.Errorf("admission plugin %s denied the request: %w", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
+// registry maps plugin names to their constructors
+var registry = map[string]func() Plugin{
+	DenyPrivilegedName: func() Plugin { return DenyPrivileged{} },
+}
+
+// NewChain builds a chain from a comma-separated list of plugin names
+func NewChain(names string) (Chain, error) {
+	var chain Chain
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		newPlugin, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown admission plugin: %s", name)
+		}
+		chain = append(chain, newPlugin())
+	}
+	return chain, nil
+}
@@ -0,0 +1,119 @@
+package admission
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestDenyPrivileged(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     types.Pod
+		wantErr bool
+	}{
+		{
+			name: "unprivileged pod",
+			pod: types.Pod{
+				Containers: []types.Container{{Name: "app", Image: "nginx"}},
+			},
+		},
+		{
+			name: "privileged container",
+			pod: types.Pod{
+				Containers: []types.Container{
+					{Name: "app", Image: "nginx"},
+					{
+						Name: "sidecar", Image: "busybox",
+						SecurityContext: &types.SecurityContext{Privileged: boolPtr(true)},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "privileged inherited from pod",
+			pod: types.Pod{
+				SecurityContext: &types.SecurityContext{Privileged: boolPtr(true)},
+				Containers:      []types.Container{{Name: "app", Image: "nginx"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "container overrides privileged pod default",
+			pod: types.Pod{
+				SecurityContext: &types.SecurityContext{Privileged: boolPtr(true)},
+				Containers: []types.Container{
+					{
+						Name: "app", Image: "nginx",
+						SecurityContext: &types.SecurityContext{Privileged: boolPtr(false)},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := DenyPrivileged{}.AdmitPod(&tt.pod)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("AdmitPod() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestNewChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "empty", names: "", wantLen: 0},
+		{name: "deny privileged", names: "DenyPrivileged", wantLen: 1},
+		{name: "whitespace and trailing comma", names: " DenyPrivileged ,", wantLen: 1},
+		{name: "unknown plugin", names: "DenyPrivileged,Bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				chain, err := NewChain(tt.names)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("NewChain() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(chain) != tt.wantLen {
+					t.Errorf("NewChain() len = %d, want %d", len(chain), tt.wantLen)
+				}
+			},
+		)
+	}
+}
+
+func TestChainAdmitPod(t *testing.T) {
+	chain := Chain{DenyPrivileged{}}
+	pod := types.Pod{
+		SecurityContext: &types.SecurityContext{Privileged: boolPtr(true)},
+		Containers:      []types.Container{{Name: "app", Image: "nginx"}},
+	}
+
+	err := chain.AdmitPod(&pod)
+	if err == nil {
+		t.Fatal("expected privileged pod to be denied")
+	}
+	if !strings.Contains(err.Error(), DenyPrivilegedName) {
+		t.Errorf("expected error to name the plugin, got %v", err)
+	}
+
+	if err := (Chain{}).AdmitPod(&pod); err != nil {
+		t.Errorf("empty chain should admit all pods, got %v", err)
+	}
+}
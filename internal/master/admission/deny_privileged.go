@@ -0,0 +1,29 @@
+package admission
+
+import (
+	"fmt"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// DenyPrivilegedName is the configuration name of the DenyPrivileged plugin
+const DenyPrivilegedName = "DenyPrivileged"
+
+// DenyPrivileged rejects pods that run any container in privileged mode
+type DenyPrivileged struct{}
+
+// Name returns the plugin name
+func (DenyPrivileged) Name() string {
+	return DenyPrivilegedName
+}
+
+// AdmitPod rejects the pod if any container is privileged
+func (DenyPrivileged) AdmitPod(pod *types.Pod) error {
+	for i := range pod.Containers {
+		container := &pod.Containers[i]
+		if types.MergeSecurityContext(pod.SecurityContext, container.SecurityContext).IsPrivileged() {
+			return fmt.Errorf("privileged container %q is not allowed", container.Name)
+		}
+	}
+	return nil
+}
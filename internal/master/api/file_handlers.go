@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// DownloadPodFiles handles GET /api/v1/pods/:id/files
+// Proxies a tar archive of a path in one of the pod's containers from its worker
+func (s *Server) DownloadPodFiles(c echo.Context) error {
+	return s.proxyPodFiles(c)
+}
+
+// UploadPodFiles handles PUT /api/v1/pods/:id/files
+// Proxies a tar archive to the pod's worker, which extracts it into a directory of one of the pod's containers
+func (s *Server) UploadPodFiles(c echo.Context) error {
+	return s.proxyPodFiles(c)
+}
+
+// proxyPodFiles forwards a file copy request and its archive body to the pod's worker and copies
+// the worker's response back. Query parameters are passed through.
+func (s *Server) proxyPodFiles(c echo.Context) error {
+	pod, err := s.store.GetPod(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "pod not found"})
+	}
+	if pod.Status != types.PodRunning || pod.NodeID == "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "pod is not running"})
+	}
+
+	node, err := s.store.GetNode(pod.NodeID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
+	}
+
+	url := fmt.Sprintf("http://%s:%d/api/v1/pods/%s/files", node.Hostname, node.Port, pod.PodID)
+	if query := c.QueryString(); query != "" {
+		url += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(c.Request().Context(), c.Request().Method, url, c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	req.Header.Set(echo.HeaderContentType, c.Request().Header.Get(echo.HeaderContentType))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadGateway,
+			map[string]string{"error": fmt.Sprintf("failed to reach node %s: %v", pod.NodeID, err)},
+		)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if contentType := resp.Header.Get(echo.HeaderContentType); contentType != "" {
+		c.Response().Header().Set(echo.HeaderContentType, contentType)
+	}
+	c.Response().WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(c.Response(), resp.Body)
+	return nil
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/master/scheduler"
+	"github.com/danpasecinic/podling/internal/master/services"
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestPodFilesProxy(t *testing.T) {
+	var uploaded, uploadQuery string
+	worker := http.NewServeMux()
+	worker.HandleFunc(
+		"GET /api/v1/pods/{id}/files", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "/tmp/core" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"no such file or directory in container"}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-tar")
+			_, _ = w.Write([]byte("archive"))
+		},
+	)
+	worker.HandleFunc(
+		"PUT /api/v1/pods/{id}/files", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			uploaded, uploadQuery = string(body), r.URL.RawQuery
+			w.WriteHeader(http.StatusNoContent)
+		},
+	)
+
+	store := state.NewInMemoryStore()
+	if err := store.AddNode(newWorkerNode(t, "node-1", worker)); err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range []types.Pod{
+		{PodID: "pod-1", NodeID: "node-1", Status: types.PodRunning},
+		{PodID: "pod-2", Status: types.PodPending},
+	} {
+		if err := store.AddPod(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := echo.New()
+	NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store)).RegisterRoutes(e)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "download", method: http.MethodGet, target: "/api/v1/pods/pod-1/files?path=/tmp/core",
+			wantCode: http.StatusOK, wantBody: "archive",
+		},
+		{
+			name: "download missing path", method: http.MethodGet, target: "/api/v1/pods/pod-1/files?path=/nope",
+			wantCode: http.StatusNotFound, wantBody: "no such file",
+		},
+		{
+			name: "upload", method: http.MethodPut, target: "/api/v1/pods/pod-1/files?path=/etc&container=app",
+			body: "archive", wantCode: http.StatusNoContent,
+		},
+		{
+			name: "pod not running", method: http.MethodGet, target: "/api/v1/pods/pod-2/files?path=/tmp",
+			wantCode: http.StatusConflict,
+		},
+		{
+			name: "unknown pod", method: http.MethodGet, target: "/api/v1/pods/pod-3/files?path=/tmp",
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+				if rec.Code != tt.wantCode {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+				if !strings.Contains(rec.Body.String(), tt.wantBody) {
+					t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+				}
+			},
+		)
+	}
+
+	if uploaded != "archive" || uploadQuery != "path=/etc&container=app" {
+		t.Errorf("worker received %q with query %q", uploaded, uploadQuery)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	cryptoRand "crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"time"
 
@@ -33,12 +35,30 @@ type UpdateTaskStatusRequest struct {
 	HealthStatus types.HealthStatus `json:"healthStatus,omitempty"`
 }
 
-// RegisterNodeRequest represents a request to register a new worker node.
+// RegisterNodeRequest represents a request to register a worker node.
 type RegisterNodeRequest struct {
+	// NodeID is the worker's stable identity; the master assigns one if it is empty
+	NodeID   string `json:"nodeId,omitempty"`
 	Hostname string `json:"hostname" validate:"required"`
 	Port     int    `json:"port" validate:"required"`
 	CPU      string `json:"cpu" validate:"required"`    // e.g., "2", "500m", "2.5"
 	Memory   string `json:"memory" validate:"required"` // e.g., "1Gi", "512Mi", "1073741824"
+
+	EphemeralStorage string `json:"ephemeralStorage,omitempty"` // e.g., "100Gi"
+	PIDs             string `json:"pids,omitempty"`             // e.g., "32768"
+
+	// InternalIP is the address other nodes reach the worker's pods through
+	InternalIP string `json:"internalIp,omitempty"`
+}
+
+// HeartbeatRequest represents the optional status a worker reports with each heartbeat.
+// Omitted fields keep the previously reported values.
+type HeartbeatRequest struct {
+	Images     []types.ContainerImage `json:"images,omitempty"`
+	Conditions []types.NodeCondition  `json:"conditions,omitempty"`
+	Usage      *types.ResourceList    `json:"usage,omitempty"`
+	Pods       []types.RunningPod     `json:"pods,omitempty"`
+	Tasks      []types.RunningTask    `json:"tasks,omitempty"`
 }
 
 // CreateTask handles POST /api/v1/tasks.
@@ -152,7 +172,9 @@ func (s *Server) UpdateTaskStatus(c echo.Context) error {
 }
 
 // RegisterNode handles POST /api/v1/nodes/register.
-// Registers a new worker node with the master.
+// Registers a new worker node with the master. Registering an existing node ID is idempotent:
+// the node record, including its bound pods and cordon state, is kept and brought back online.
+// With a pod CIDR allocator the node is assigned a pod subnet, which it keeps across registrations.
 func (s *Server) RegisterNode(c echo.Context) error {
 	var req RegisterNodeRequest
 	if err := c.Bind(&req); err != nil {
@@ -173,30 +195,91 @@ func (s *Server) RegisterNode(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid memory format: %v", err)})
 	}
 
+	storageBytes, err := types.ParseEphemeralStorage(req.EphemeralStorage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	pids, err := types.ParsePIDs(req.PIDs)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if req.InternalIP != "" && net.ParseIP(req.InternalIP) == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "internalIp must be an IP address"})
+	}
+
+	capacity := types.ResourceList{
+		CPU:              cpuMillicores,
+		Memory:           memoryBytes,
+		EphemeralStorage: storageBytes,
+		PIDs:             pids,
+	}
+
+	nodeID := req.NodeID
+	if nodeID == "" {
+		nodeID = generateID()
+	}
+
+	var podCIDR string
+	if s.podCIDRs != nil {
+		podCIDR, err = s.podCIDRs.Allocate(nodeID)
+		if err != nil {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		}
+	}
+
+	now := time.Now()
+	if req.NodeID != "" {
+		existing, err := s.store.GetNode(req.NodeID)
+		if err != nil && !errors.Is(err, state.ErrNodeNotFound) {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		if err == nil {
+			resources := types.NodeResources{Capacity: capacity, Allocatable: capacity}
+			if existing.Resources != nil {
+				resources.Used = existing.Resources.Used
+			}
+			update := state.NodeUpdate{
+				Status:        ptrTo(types.NodeOnline),
+				LastHeartbeat: &now,
+				Hostname:      &req.Hostname,
+				Port:          &req.Port,
+				Resources:     &resources,
+				InternalIP:    &req.InternalIP,
+			}
+			if s.podCIDRs != nil {
+				update.PodCIDR = &podCIDR
+			}
+			if err := s.store.UpdateNode(req.NodeID, update); err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+
+			node, _ := s.store.GetNode(req.NodeID)
+			return c.JSON(http.StatusOK, node)
+		}
+	}
+
 	node := types.Node{
-		NodeID:        generateID(),
+		NodeID:        nodeID,
 		Hostname:      req.Hostname,
 		Port:          req.Port,
 		Status:        types.NodeOnline,
 		RunningTasks:  0,
-		LastHeartbeat: time.Now(),
+		LastHeartbeat: now,
 		Resources: &types.NodeResources{
-			Capacity: types.ResourceList{
-				CPU:    cpuMillicores,
-				Memory: memoryBytes,
-			},
-			Allocatable: types.ResourceList{
-				CPU:    cpuMillicores,
-				Memory: memoryBytes,
-			},
-			Used: types.ResourceList{
-				CPU:    0,
-				Memory: 0,
-			},
+			Capacity:    capacity,
+			Allocatable: capacity,
+			Used:        types.ResourceList{},
 		},
+		PodCIDR:    podCIDR,
+		InternalIP: req.InternalIP,
 	}
 
 	if err := s.store.AddNode(node); err != nil {
+		if s.podCIDRs != nil {
+			s.podCIDRs.Release(nodeID)
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -204,15 +287,45 @@ func (s *Server) RegisterNode(c echo.Context) error {
 }
 
 // NodeHeartbeat handles POST /api/v1/nodes/:id/heartbeat.
-// Updates the last heartbeat time for a worker node.
+// Updates the last heartbeat time for a worker node and its reported image inventory, conditions,
+// resource usage and running workloads.
 func (s *Server) NodeHeartbeat(c echo.Context) error {
 	nodeID := c.Param("id")
 
+	var req HeartbeatRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
 	now := time.Now()
 	update := state.NodeUpdate{
 		Status:        ptrTo(types.NodeOnline),
 		LastHeartbeat: &now,
 	}
+	if req.Images != nil {
+		update.Images = &req.Images
+	}
+	if req.Conditions != nil {
+		update.Conditions = &req.Conditions
+	}
+	if req.Usage != nil {
+		update.Usage = req.Usage
+	}
+	// Workloads are reported together; an empty list means nothing is running
+	if req.Pods != nil || req.Tasks != nil {
+		pods := req.Pods
+		if pods == nil {
+			pods = []types.RunningPod{}
+		}
+		tasks := req.Tasks
+		if tasks == nil {
+			tasks = []types.RunningTask{}
+		}
+		running := len(pods) + len(tasks)
+		update.Pods = &pods
+		update.Tasks = &tasks
+		update.RunningTasks = &running
+	}
 
 	if err := s.store.UpdateNode(nodeID, update); err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
@@ -238,6 +351,33 @@ func (s *Server) NodeDeregister(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "node deregistered successfully"})
 }
 
+// CordonNode handles POST /api/v1/nodes/:id/cordon.
+// Marks a node unschedulable; pods already running on it are not affected.
+func (s *Server) CordonNode(c echo.Context) error {
+	return s.setNodeUnschedulable(c, true)
+}
+
+// UncordonNode handles POST /api/v1/nodes/:id/uncordon.
+// Makes a cordoned node schedulable again.
+func (s *Server) UncordonNode(c echo.Context) error {
+	return s.setNodeUnschedulable(c, false)
+}
+
+func (s *Server) setNodeUnschedulable(c echo.Context, unschedulable bool) error {
+	nodeID := c.Param("id")
+
+	update := state.NodeUpdate{
+		Unschedulable: &unschedulable,
+	}
+
+	if err := s.store.UpdateNode(nodeID, update); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
+	}
+
+	node, _ := s.store.GetNode(nodeID)
+	return c.JSON(http.StatusOK, node)
+}
+
 // ListNodes handles GET /api/v1/nodes.
 // Returns all registered worker nodes with updated status based on heartbeat.
 func (s *Server) ListNodes(c echo.Context) error {
@@ -305,6 +305,27 @@ func TestRegisterNode(t *testing.T) {
 			reqBody:    `{"hostname":"worker1","port":8081,"cpu":"10","memory":"invalid"}`,
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "with ephemeral storage and pids",
+			reqBody:    `{"hostname":"worker1","port":8081,"cpu":"10","memory":"10Gi","ephemeralStorage":"100Gi","pids":"4096"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "invalid pids format",
+			reqBody:    `{"hostname":"worker1","port":8081,"cpu":"10","memory":"10Gi","pids":"many"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "with internal IP",
+			reqBody:    `{"hostname":"worker1","port":8081,"cpu":"10","memory":"10Gi","internalIp":"192.168.1.10"}`,
+			wantStatus: http.StatusCreated,
+			wantFields: map[string]interface{}{"internalIp": "192.168.1.10"},
+		},
+		{
+			name:       "invalid internal IP",
+			reqBody:    `{"hostname":"worker1","port":8081,"cpu":"10","memory":"10Gi","internalIp":"worker1"}`,
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -343,6 +364,144 @@ func TestRegisterNode(t *testing.T) {
 	}
 }
 
+func TestRegisterNodeIsIdempotent(t *testing.T) {
+	server, e := setupTestServer()
+
+	register := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/nodes/register", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := register(`{"nodeId":"worker-1","hostname":"worker1","port":8081,"cpu":"4","memory":"8Gi"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first registration status = %v, want %v", rec.Code, http.StatusCreated)
+	}
+
+	used := types.ResourceList{CPU: 500}
+	_ = server.store.UpdateNode(
+		"worker-1", state.NodeUpdate{
+			Status:        ptrTo(types.NodeOffline),
+			Unschedulable: ptrTo(true),
+			Resources: &types.NodeResources{
+				Capacity:    types.ResourceList{CPU: 4000},
+				Allocatable: types.ResourceList{CPU: 4000},
+				Used:        used,
+			},
+		},
+	)
+	_ = server.store.AddPod(types.Pod{PodID: "pod-1", NodeID: "worker-1", Status: types.PodRunning})
+
+	rec = register(`{"nodeId":"worker-1","hostname":"worker1.local","port":9091,"cpu":"8","memory":"8Gi"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("re-registration status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	nodes, _ := server.store.ListNodes()
+	if len(nodes) != 1 {
+		t.Fatalf("expected a single node record, got %d", len(nodes))
+	}
+
+	node := nodes[0]
+	if node.NodeID != "worker-1" || node.Status != types.NodeOnline {
+		t.Errorf("node = %s/%s, want worker-1/online", node.NodeID, node.Status)
+	}
+	if node.Hostname != "worker1.local" || node.Port != 9091 {
+		t.Errorf("address = %s:%d, want worker1.local:9091", node.Hostname, node.Port)
+	}
+	if !node.Unschedulable {
+		t.Error("expected cordon state to survive re-registration")
+	}
+	if node.Resources.Capacity.CPU != 8000 || node.Resources.Used != used {
+		t.Errorf("resources = %+v, want new capacity and existing usage", node.Resources)
+	}
+
+	pod, _ := server.store.GetPod("pod-1")
+	if pod.NodeID != "worker-1" {
+		t.Errorf("expected pod to stay bound to worker-1, got %q", pod.NodeID)
+	}
+}
+
+func TestRegisterNodeAssignsPodCIDR(t *testing.T) {
+	server, e := setupTestServer()
+	allocator, err := services.NewPodCIDRAllocator(server.store, "10.244.0.0/16", 24)
+	if err != nil {
+		t.Fatalf("NewPodCIDRAllocator() error = %v", err)
+	}
+	server.SetPodCIDRAllocator(allocator)
+
+	register := func(body string) types.Node {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/nodes/register", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated && rec.Code != http.StatusOK {
+			t.Fatalf("registration status = %v: %s", rec.Code, rec.Body.String())
+		}
+		var node types.Node
+		if err := json.Unmarshal(rec.Body.Bytes(), &node); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return node
+	}
+
+	first := register(`{"nodeId":"worker-1","hostname":"worker1","port":8081,"cpu":"4","memory":"8Gi"}`)
+	second := register(`{"nodeId":"worker-2","hostname":"worker2","port":8081,"cpu":"4","memory":"8Gi"}`)
+	if first.PodCIDR != "10.244.0.0/24" || second.PodCIDR != "10.244.1.0/24" {
+		t.Errorf("pod CIDRs = %q, %q, want 10.244.0.0/24, 10.244.1.0/24", first.PodCIDR, second.PodCIDR)
+	}
+
+	again := register(
+		`{"nodeId":"worker-1","hostname":"worker1","port":8081,"cpu":"4","memory":"8Gi","internalIp":"10.0.0.1"}`,
+	)
+	if again.PodCIDR != first.PodCIDR {
+		t.Errorf("pod CIDR after re-registration = %q, want %q", again.PodCIDR, first.PodCIDR)
+	}
+	if again.InternalIP != "10.0.0.1" {
+		t.Errorf("internal IP after re-registration = %q, want 10.0.0.1", again.InternalIP)
+	}
+}
+
+func TestCordonNode(t *testing.T) {
+	server, e := setupTestServer()
+	_ = server.store.AddNode(types.Node{NodeID: "node123", Hostname: "worker1", Status: types.NodeOnline})
+
+	tests := []struct {
+		name              string
+		path              string
+		wantStatus        int
+		wantUnschedulable bool
+	}{
+		{name: "cordon", path: "/api/v1/nodes/node123/cordon", wantStatus: http.StatusOK, wantUnschedulable: true},
+		{name: "uncordon", path: "/api/v1/nodes/node123/uncordon", wantStatus: http.StatusOK},
+		{name: "non-existent node", path: "/api/v1/nodes/nonexistent/cordon", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+				rec := httptest.NewRecorder()
+
+				e.ServeHTTP(rec, req)
+
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("status = %v, want %v", rec.Code, tt.wantStatus)
+				}
+
+				if tt.wantStatus == http.StatusOK {
+					stored, _ := server.store.GetNode("node123")
+					if stored.Unschedulable != tt.wantUnschedulable {
+						t.Errorf("Unschedulable = %v, want %v", stored.Unschedulable, tt.wantUnschedulable)
+					}
+				}
+			},
+		)
+	}
+}
+
 func TestNodeHeartbeat(t *testing.T) {
 	server, e := setupTestServer()
 
@@ -393,6 +552,121 @@ func TestNodeHeartbeat(t *testing.T) {
 	}
 }
 
+func TestNodeHeartbeatWithImages(t *testing.T) {
+	server, e := setupTestServer()
+
+	node := types.Node{NodeID: "node123", Hostname: "worker1", Status: types.NodeOnline}
+	_ = server.store.AddNode(node)
+
+	body := `{"images":[{"id":"sha256:abc","names":["nginx:latest"],"sizeBytes":1024}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node123/heartbeat", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("NodeHeartbeat() status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	stored, _ := server.store.GetNode("node123")
+	if len(stored.Images) != 1 || stored.Images[0].ID != "sha256:abc" {
+		t.Fatalf("expected image inventory to be stored, got %+v", stored.Images)
+	}
+
+	// A heartbeat without an inventory keeps the previously reported images
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node123/heartbeat", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	stored, _ = server.store.GetNode("node123")
+	if len(stored.Images) != 1 {
+		t.Errorf("expected image inventory to be kept, got %+v", stored.Images)
+	}
+}
+
+func TestNodeHeartbeatWithConditions(t *testing.T) {
+	server, e := setupTestServer()
+
+	node := types.Node{NodeID: "node123", Hostname: "worker1", Status: types.NodeOnline}
+	_ = server.store.AddNode(node)
+
+	body := `{"conditions":[{"type":"MemoryPressure","status":true,"reason":"UnderPressure"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node123/heartbeat", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("NodeHeartbeat() status = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	stored, _ := server.store.GetNode("node123")
+	if !stored.HasPressure() {
+		t.Fatalf("expected memory pressure condition to be stored, got %+v", stored.Conditions)
+	}
+
+	body = `{"conditions":[{"type":"MemoryPressure","status":false,"reason":"NoPressure"}]}`
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node123/heartbeat", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	stored, _ = server.store.GetNode("node123")
+	if stored.HasPressure() {
+		t.Errorf("expected pressure to be cleared, got %+v", stored.Conditions)
+	}
+}
+
+func TestNodeHeartbeatWithUsageAndWorkloads(t *testing.T) {
+	server, e := setupTestServer()
+
+	node := types.Node{NodeID: "node123", Hostname: "worker1", Status: types.NodeOnline}
+	_ = server.store.AddNode(node)
+
+	heartbeat := func(body string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node123/heartbeat", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("NodeHeartbeat() status = %v, want %v", rec.Code, http.StatusOK)
+		}
+	}
+
+	heartbeat(
+		`{"usage":{"cpu":250,"memory":1048576},` +
+			`"pods":[{"podId":"pod-1","containers":[{"name":"app","containerId":"c1","state":"running"}]}],` +
+			`"tasks":[{"taskId":"task-1","containerId":"c2"}]}`,
+	)
+
+	stored, _ := server.store.GetNode("node123")
+	if stored.Usage == nil || stored.Usage.CPU != 250 || stored.Usage.Memory != 1048576 {
+		t.Errorf("expected usage to be stored, got %+v", stored.Usage)
+	}
+	if len(stored.Pods) != 1 || stored.Pods[0].Containers[0].State != "running" {
+		t.Errorf("expected running pods to be stored, got %+v", stored.Pods)
+	}
+	if len(stored.Tasks) != 1 || stored.Tasks[0].TaskID != "task-1" {
+		t.Errorf("expected running tasks to be stored, got %+v", stored.Tasks)
+	}
+	if stored.RunningTasks != 2 {
+		t.Errorf("RunningTasks = %d, want 2", stored.RunningTasks)
+	}
+
+	// An empty workload list clears what was reported before
+	heartbeat(`{"pods":[],"tasks":[]}`)
+
+	stored, _ = server.store.GetNode("node123")
+	if len(stored.Pods) != 0 || len(stored.Tasks) != 0 || stored.RunningTasks != 0 {
+		t.Errorf("expected workloads to be cleared, got pods=%+v tasks=%+v", stored.Pods, stored.Tasks)
+	}
+	if stored.Usage == nil {
+		t.Error("expected usage to be kept when not reported")
+	}
+}
+
 func TestListNodes(t *testing.T) {
 	server, e := setupTestServer()
 
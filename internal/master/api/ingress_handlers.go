@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateIngressRequest represents a request to create a new ingress
+type CreateIngressRequest struct {
+	Name           string                `json:"name" validate:"required"`
+	Namespace      string                `json:"namespace"`
+	Rules          []types.IngressRule   `json:"rules"`
+	DefaultBackend *types.IngressBackend `json:"defaultBackend"`
+	TLS            []types.IngressTLS    `json:"tls"`
+	Labels         map[string]string     `json:"labels"`
+	Annotations    map[string]string     `json:"annotations"`
+}
+
+// UpdateIngressRequest represents a request to replace an ingress's routing
+type UpdateIngressRequest struct {
+	Rules          []types.IngressRule   `json:"rules"`
+	DefaultBackend *types.IngressBackend `json:"defaultBackend"`
+	TLS            []types.IngressTLS    `json:"tls"`
+	Labels         map[string]string     `json:"labels"`
+	Annotations    map[string]string     `json:"annotations"`
+}
+
+// CreateIngress handles POST /api/v1/ingresses
+func (s *Server) CreateIngress(c echo.Context) error {
+	var req CreateIngressRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	ingress := types.Ingress{
+		IngressID:      generateID(),
+		Name:           req.Name,
+		Namespace:      namespace,
+		Rules:          req.Rules,
+		DefaultBackend: req.DefaultBackend,
+		TLS:            req.TLS,
+		Labels:         req.Labels,
+		Annotations:    req.Annotations,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	defaultPathTypes(&ingress)
+
+	if err := ingress.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.store.AddIngress(ingress); err != nil {
+		if errors.Is(err, state.ErrIngressAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "ingress already exists"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, ingress)
+}
+
+// ListIngresses handles GET /api/v1/ingresses
+// Returns all ingresses, optionally filtered by namespace
+func (s *Server) ListIngresses(c echo.Context) error {
+	ingresses, err := s.store.ListIngresses(c.QueryParam("namespace"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ingresses)
+}
+
+// GetIngress handles GET /api/v1/ingresses/:id
+func (s *Server) GetIngress(c echo.Context) error {
+	ingress, err := s.store.GetIngress(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "ingress not found"})
+	}
+
+	return c.JSON(http.StatusOK, ingress)
+}
+
+// UpdateIngress handles PUT /api/v1/ingresses/:id
+// Replaces the ingress's rules, default backend, TLS, labels and annotations
+func (s *Server) UpdateIngress(c echo.Context) error {
+	var req UpdateIngressRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	ingress, err := s.store.GetIngress(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "ingress not found"})
+	}
+
+	ingress.Rules = req.Rules
+	ingress.DefaultBackend = req.DefaultBackend
+	ingress.TLS = req.TLS
+	ingress.Labels = req.Labels
+	ingress.Annotations = req.Annotations
+	defaultPathTypes(&ingress)
+
+	if err := ingress.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.store.UpdateIngress(ingress); err != nil {
+		if errors.Is(err, state.ErrIngressNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "ingress not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	updated, _ := s.store.GetIngress(ingress.IngressID)
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteIngress handles DELETE /api/v1/ingresses/:id
+func (s *Server) DeleteIngress(c echo.Context) error {
+	if err := s.store.DeleteIngress(c.Param("id")); err != nil {
+		if errors.Is(err, state.ErrIngressNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "ingress not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "ingress deleted successfully"})
+}
+
+// defaultPathTypes sets the path type of paths that have none to Prefix
+func defaultPathTypes(ingress *types.Ingress) {
+	for i := range ingress.Rules {
+		for j := range ingress.Rules[i].Paths {
+			if ingress.Rules[i].Paths[j].PathType == "" {
+				ingress.Rules[i].Paths[j].PathType = types.PathTypePrefix
+			}
+		}
+	}
+}
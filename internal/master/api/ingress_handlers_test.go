@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCreateIngress(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name: "host and path rule",
+			payload: `{"name":"web","rules":[{"host":"example.com",` +
+				`"paths":[{"path":"/api","backend":{"serviceName":"api","servicePort":80}}]}]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "default backend only",
+			payload:    `{"name":"catch-all","defaultBackend":{"serviceName":"web","servicePortName":"http"}}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "no rules or default backend",
+			payload:    `{"name":"empty"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "path without leading slash",
+			payload: `{"name":"web","rules":[{"paths":[{"path":"api",` +
+				`"backend":{"serviceName":"api","servicePort":80}}]}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "backend without port",
+			payload: `{"name":"web","rules":[{"paths":[{"path":"/",` +
+				`"backend":{"serviceName":"api"}}]}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			payload:    `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server, e := setupTestServer()
+
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/ingresses", strings.NewReader(tt.payload))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+
+				if err := server.CreateIngress(c); err != nil {
+					t.Fatalf("CreateIngress failed: %v", err)
+				}
+
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func TestIngressLifecycle(t *testing.T) {
+	_, e := setupTestServer()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	payload := `{"name":"web","rules":[{"host":"example.com",` +
+		`"paths":[{"path":"/","backend":{"serviceName":"web","servicePort":80}}]}]}`
+	rec := do(http.MethodPost, "/api/v1/ingresses", payload)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var created types.Ingress
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode ingress: %v", err)
+	}
+	if created.Namespace != "default" {
+		t.Errorf("expected namespace default, got %q", created.Namespace)
+	}
+	if got := created.Rules[0].Paths[0].PathType; got != types.PathTypePrefix {
+		t.Errorf("expected path type to default to Prefix, got %q", got)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/ingresses", payload); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate: expected status 409, got %d", rec.Code)
+	}
+
+	update := `{"rules":[{"host":"example.com","paths":[{"path":"/v2","pathType":"Exact",` +
+		`"backend":{"serviceName":"web-v2","servicePort":8080}}]}]}`
+	rec = do(http.MethodPut, "/api/v1/ingresses/"+created.IngressID, update)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var updated types.Ingress
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("failed to decode ingress: %v", err)
+	}
+	if updated.Name != "web" || updated.Rules[0].Paths[0].Backend.ServiceName != "web-v2" {
+		t.Errorf("unexpected ingress after update: %+v", updated)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/ingresses?namespace=default", "")
+	var list []types.Ingress
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Errorf("list: expected 1 ingress, got %s", rec.Body.String())
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/ingresses/"+created.IngressID, ""); rec.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/ingresses/"+created.IngressID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: expected status 404, got %d", rec.Code)
+	}
+}
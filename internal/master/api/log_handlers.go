@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/logstore"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultLogSearchLimit is the number of most recent lines returned when no limit is given
+const defaultLogSearchLimit = 1000
+
+// StreamPodLogs handles GET /api/v1/pods/:id/logs/stream
+// Proxies the newline-delimited JSON log stream of the pod's worker; query parameters are passed through
+func (s *Server) StreamPodLogs(c echo.Context) error {
+	pod, err := s.store.GetPod(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "pod not found"})
+	}
+	if pod.NodeID == "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "pod is not scheduled to a node"})
+	}
+	return s.proxyLogStream(c, pod.NodeID, "pods/"+pod.PodID)
+}
+
+// StreamTaskLogs handles GET /api/v1/tasks/:id/logs/stream
+// Proxies the task's log stream from its worker like StreamPodLogs
+func (s *Server) StreamTaskLogs(c echo.Context) error {
+	task, err := s.store.GetTask(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "task not found"})
+	}
+	if task.NodeID == "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "task is not scheduled to a node"})
+	}
+	return s.proxyLogStream(c, task.NodeID, "tasks/"+task.TaskID)
+}
+
+// proxyLogStream copies a worker's log stream to the client, flushing as data arrives.
+// The stream has no timeout; it ends when the worker closes it or the client goes away.
+func (s *Server) proxyLogStream(c echo.Context, nodeID, resource string) error {
+	node, err := s.store.GetNode(nodeID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
+	}
+
+	url := fmt.Sprintf("http://%s:%d/api/v1/%s/logs/stream", node.Hostname, node.Port, resource)
+	if query := c.QueryString(); query != "" {
+		url += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return c.JSON(
+			http.StatusBadGateway, map[string]string{"error": fmt.Sprintf("failed to reach node %s: %v", nodeID, err)},
+		)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return c.Blob(resp.StatusCode, resp.Header.Get(echo.HeaderContentType), body)
+	}
+
+	out := c.Response()
+	out.Header().Set(echo.HeaderContentType, resp.Header.Get(echo.HeaderContentType))
+	out.WriteHeader(http.StatusOK)
+	out.Flush()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := out.Write(buf[:n]); writeErr != nil {
+				return nil
+			}
+			out.Flush()
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// ShipLogs handles POST /api/v1/logs
+// Workers ship batches of container log lines here; they are retained per pod for SearchLogs.
+func (s *Server) ShipLogs(c echo.Context) error {
+	if s.logs == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "log store is not enabled"})
+	}
+
+	var batch types.LogBatch
+	if err := c.Bind(&batch); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	s.logs.Append(batch.Records)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SearchLogs handles GET /api/v1/logs
+// Returns retained log lines across pods, including terminated ones, oldest first.
+// Query parameters: selector (key=value,...), grep (regular expression), pod, container, namespace,
+// since (a duration like 10m or an RFC 3339 time) and limit (most recent lines, default 1000, 0 for all).
+func (s *Server) SearchLogs(c echo.Context) error {
+	if s.logs == nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "log store is not enabled"})
+	}
+
+	query := logstore.Query{
+		Namespace: c.QueryParam("namespace"),
+		PodID:     c.QueryParam("pod"),
+		Container: c.QueryParam("container"),
+		Limit:     defaultLogSearchLimit,
+	}
+
+	selector, err := types.ParseSelector(c.QueryParam("selector"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	query.Selector = selector
+
+	if pattern := c.QueryParam("grep"); pattern != "" {
+		grep, err := regexp.Compile(pattern)
+		if err != nil {
+			return c.JSON(
+				http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid grep pattern: %v", err)},
+			)
+		}
+		query.Grep = grep
+	}
+
+	if value := c.QueryParam("since"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			query.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			query.Since = t
+		} else {
+			return c.JSON(
+				http.StatusBadRequest,
+				map[string]string{"error": "invalid since: want a duration like 10m or an RFC 3339 time"},
+			)
+		}
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit parameter"})
+		}
+		query.Limit = limit
+	}
+
+	records := s.logs.Search(query)
+	if records == nil {
+		records = []types.LogRecord{}
+	}
+	return c.JSON(http.StatusOK, records)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/logstore"
+	"github.com/danpasecinic/podling/internal/master/scheduler"
+	"github.com/danpasecinic/podling/internal/master/services"
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestStreamLogsProxy(t *testing.T) {
+	var gotQuery string
+	release := make(chan struct{})
+	worker := http.NewServeMux()
+	worker.HandleFunc(
+		"GET /api/v1/pods/{id}/logs/stream", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("stream") == "all" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid stream \"all\": want stdout or stderr"}`))
+				return
+			}
+			gotQuery = r.URL.RawQuery
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte(`{"container":"app","stream":"stdout","line":"one"}` + "\n"))
+			w.(http.Flusher).Flush()
+			<-release
+			_, _ = w.Write([]byte(`{"container":"app","stream":"stdout","line":"two"}` + "\n"))
+		},
+	)
+	worker.HandleFunc(
+		"GET /api/v1/tasks/{id}/logs/stream", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"stream":"stdout","line":"done"}` + "\n"))
+		},
+	)
+
+	store := state.NewInMemoryStore()
+	if err := store.AddNode(newWorkerNode(t, "node-1", worker)); err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range []types.Pod{
+		{PodID: "pod-1", NodeID: "node-1", Status: types.PodRunning},
+		{PodID: "pod-2", Status: types.PodPending},
+	} {
+		if err := store.AddPod(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AddTask(types.Task{TaskID: "task-1", NodeID: "node-1", Status: types.TaskRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store)).RegisterRoutes(e)
+	master := httptest.NewServer(e)
+	defer master.Close()
+
+	resp, err := http.Get(master.URL + "/api/v1/pods/pod-1/logs/stream?follow=true&container=app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", resp.Header.Get("Content-Type"))
+	}
+
+	// Lines are delivered before the worker finishes the stream
+	lines := bufio.NewScanner(resp.Body)
+	if !lines.Scan() || lines.Text() != `{"container":"app","stream":"stdout","line":"one"}` {
+		t.Fatalf("first line = %q", lines.Text())
+	}
+	close(release)
+	if !lines.Scan() || lines.Text() != `{"container":"app","stream":"stdout","line":"two"}` {
+		t.Fatalf("second line = %q", lines.Text())
+	}
+	if gotQuery != "follow=true&container=app" {
+		t.Errorf("worker query = %q, want the client's query", gotQuery)
+	}
+
+	resp, err = http.Get(master.URL + "/api/v1/tasks/task-1/logs/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != `{"stream":"stdout","line":"done"}`+"\n" {
+		t.Errorf("task logs = %q", body)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "worker error is passed through",
+			path:       "/pods/pod-1/logs/stream?stream=all",
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "pod not scheduled", path: "/pods/pod-2/logs/stream", wantStatus: http.StatusConflict},
+		{name: "unknown pod", path: "/pods/pod-3/logs/stream", wantStatus: http.StatusNotFound},
+		{name: "unknown task", path: "/tasks/task-2/logs/stream", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				resp, err := http.Get(master.URL + "/api/v1" + tt.path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_ = resp.Body.Close()
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestShipAndSearchLogs(t *testing.T) {
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+	e := echo.New()
+	server.RegisterRoutes(e)
+
+	now := time.Now()
+	record := func(podID, app, line string, age time.Duration) types.LogRecord {
+		ts := now.Add(-age)
+		return types.LogRecord{
+			PodID:    podID,
+			Labels:   map[string]string{"app": app},
+			LogEntry: types.LogEntry{Container: "app", Stream: types.LogStreamStdout, Timestamp: &ts, Line: line},
+		}
+	}
+	body, err := json.Marshal(
+		types.LogBatch{
+			Records: []types.LogRecord{
+				record("web-1", "web", "GET / 200", 3*time.Second),
+				record("web-1", "web", "GET /x 500", 2*time.Second),
+				record("api-1", "api", "GET /y 500", time.Second),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ship := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := ship(); code != http.StatusServiceUnavailable {
+		t.Fatalf("ship without a log store = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	server.SetLogStore(logstore.NewStore(logstore.DefaultConfig()))
+	if code := ship(); code != http.StatusNoContent {
+		t.Fatalf("ship = %d, want %d", code, http.StatusNoContent)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantCode  int
+		wantLines []string
+	}{
+		{
+			name: "all", query: "", wantCode: http.StatusOK,
+			wantLines: []string{"GET / 200", "GET /x 500", "GET /y 500"},
+		},
+		{
+			name: "selector and grep", query: "?selector=app%3Dweb&grep=500", wantCode: http.StatusOK,
+			wantLines: []string{"GET /x 500"},
+		},
+		{name: "limit", query: "?limit=1", wantCode: http.StatusOK, wantLines: []string{"GET /y 500"}},
+		{name: "invalid grep", query: "?grep=%28", wantCode: http.StatusBadRequest},
+		{name: "invalid selector", query: "?selector=app", wantCode: http.StatusBadRequest},
+		{name: "invalid since", query: "?since=yesterday", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/logs"+tt.query, nil))
+				if rec.Code != tt.wantCode {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+				}
+				if tt.wantCode != http.StatusOK {
+					return
+				}
+
+				var records []types.LogRecord
+				if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+					t.Fatal(err)
+				}
+				if len(records) != len(tt.wantLines) {
+					t.Fatalf("got %d records, want %v", len(records), tt.wantLines)
+				}
+				for i, want := range tt.wantLines {
+					if records[i].Line != want {
+						t.Errorf("record %d = %q, want %q", i, records[i].Line, want)
+					}
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// workerStatsTimeout bounds each stats request to a worker
+const workerStatsTimeout = 5 * time.Second
+
+// GetPodStats handles GET /api/v1/pods/:id/stats
+// Returns the container stats the pod's worker measured
+func (s *Server) GetPodStats(c echo.Context) error {
+	pod, err := s.store.GetPod(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "pod not found"})
+	}
+	if pod.Status != types.PodRunning || pod.NodeID == "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "pod is not running"})
+	}
+
+	node, err := s.store.GetNode(pod.NodeID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
+	}
+
+	var podStats types.PodStats
+	if err := fetchWorkerStats(node, "/api/v1/pods/"+pod.PodID+"/stats", &podStats); err != nil {
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, podStats)
+}
+
+// ListPodMetrics handles GET /api/v1/metrics/pods
+// Returns the actual usage of every running pod next to its requests and limits
+func (s *Server) ListPodMetrics(c echo.Context) error {
+	pods, err := s.store.ListPods()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	nodes, err := s.store.ListNodes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	stats := collectNodeStats(nodes)
+	podStats := make(map[string]types.PodStats)
+	for _, nodeStats := range stats {
+		for _, p := range nodeStats.Pods {
+			podStats[p.PodID] = p
+		}
+	}
+
+	metrics := make([]types.PodMetrics, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status != types.PodRunning {
+			continue
+		}
+
+		m := types.PodMetrics{
+			PodID:     pod.PodID,
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			NodeID:    pod.NodeID,
+			Requests:  pod.GetTotalResourceRequests().Requests,
+			Limits:    pod.GetTotalResourceLimits(),
+		}
+		if ps, ok := podStats[pod.PodID]; ok {
+			m.Usage = ps.Usage
+			m.Available = true
+			m.Timestamp = ps.Timestamp
+		}
+		metrics = append(metrics, m)
+	}
+
+	sort.Slice(
+		metrics, func(i, j int) bool {
+			return metrics[i].Name < metrics[j].Name
+		},
+	)
+	return c.JSON(http.StatusOK, metrics)
+}
+
+// ListNodeMetrics handles GET /api/v1/metrics/nodes
+// Returns the actual usage of every node next to the requests scheduled on it and its capacity.
+// Nodes that cannot be reached fall back to the usage of their last heartbeat.
+func (s *Server) ListNodeMetrics(c echo.Context) error {
+	nodes, err := s.store.ListNodes()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	stats := collectNodeStats(nodes)
+
+	metrics := make([]types.NodeMetrics, 0, len(nodes))
+	for _, node := range nodes {
+		m := types.NodeMetrics{NodeID: node.NodeID, Hostname: node.Hostname}
+		if node.Resources != nil {
+			m.Requested = node.Resources.Used
+			m.Capacity = node.Resources.Allocatable
+		}
+
+		if nodeStats, ok := stats[node.NodeID]; ok {
+			m.Usage = nodeStats.Usage
+			m.Available = true
+			m.Timestamp = nodeStats.Timestamp
+		} else if node.Usage != nil {
+			m.Usage = *node.Usage
+			m.Available = true
+			m.Timestamp = node.LastHeartbeat
+		}
+		metrics = append(metrics, m)
+	}
+
+	sort.Slice(
+		metrics, func(i, j int) bool {
+			return metrics[i].NodeID < metrics[j].NodeID
+		},
+	)
+	return c.JSON(http.StatusOK, metrics)
+}
+
+// collectNodeStats queries every online node for its stats in parallel.
+// Nodes that fail to answer are left out of the result.
+func collectNodeStats(nodes []types.Node) map[string]types.NodeStats {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = make(map[string]types.NodeStats, len(nodes))
+	)
+
+	for _, node := range nodes {
+		if node.Status != types.NodeOnline {
+			continue
+		}
+		wg.Add(1)
+		go func(node types.Node) {
+			defer wg.Done()
+
+			var nodeStats types.NodeStats
+			if err := fetchWorkerStats(node, "/api/v1/stats", &nodeStats); err != nil {
+				return
+			}
+			mu.Lock()
+			result[node.NodeID] = nodeStats
+			mu.Unlock()
+		}(node)
+	}
+
+	wg.Wait()
+	return result
+}
+
+// fetchWorkerStats decodes the JSON response of a stats request to a worker
+func fetchWorkerStats(node types.Node, path string, out interface{}) error {
+	url := fmt.Sprintf("http://%s:%d%s", node.Hostname, node.Port, path)
+
+	client := &http.Client{Timeout: workerStatsTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stats from node %s: %w", node.NodeID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("node %s returned status %d", node.NodeID, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode stats from node %s: %w", node.NodeID, err)
+	}
+	return nil
+}
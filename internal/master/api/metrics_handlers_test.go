@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/scheduler"
+	"github.com/danpasecinic/podling/internal/master/services"
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// newStatsWorker starts a worker that reports the given node stats and returns a node pointing at it
+func newStatsWorker(t *testing.T, nodeID string, stats types.NodeStats) types.Node {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"GET /api/v1/stats", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(stats)
+		},
+	)
+	mux.HandleFunc(
+		"GET /api/v1/pods/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+			for _, p := range stats.Pods {
+				if p.PodID == r.PathValue("id") {
+					_ = json.NewEncoder(w).Encode(p)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		},
+	)
+	return newWorkerNode(t, nodeID, mux)
+}
+
+// newWorkerNode starts a worker served by handler and returns an online node pointing at it
+func newWorkerNode(t *testing.T, nodeID string, handler http.Handler) types.Node {
+	t.Helper()
+
+	worker := httptest.NewServer(handler)
+	t.Cleanup(worker.Close)
+
+	host, portStr, _ := net.SplitHostPort(worker.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	return types.Node{
+		NodeID:        nodeID,
+		Hostname:      host,
+		Port:          port,
+		Status:        types.NodeOnline,
+		LastHeartbeat: time.Now(),
+		Resources: &types.NodeResources{
+			Capacity:    types.ResourceList{CPU: 4000, Memory: 8 << 30},
+			Allocatable: types.ResourceList{CPU: 4000, Memory: 8 << 30},
+			Used:        types.ResourceList{CPU: 500, Memory: 256 << 20},
+		},
+	}
+}
+
+func newMetricsServer(t *testing.T) (*Server, state.StateStore) {
+	t.Helper()
+
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+
+	pod := types.Pod{
+		PodID:  "pod-1",
+		Name:   "web",
+		NodeID: "node-1",
+		Status: types.PodRunning,
+		Containers: []types.Container{
+			{
+				Name: "app",
+				Resources: types.ResourceRequirements{
+					Requests: types.ResourceList{CPU: 500, Memory: 256 << 20},
+					Limits:   types.ResourceList{CPU: 1000, Memory: 512 << 20},
+				},
+			},
+		},
+	}
+	if err := store.AddPod(pod); err != nil {
+		t.Fatal(err)
+	}
+
+	podStats := types.PodStats{PodID: "pod-1", NodeID: "node-1"}
+	podStats.AddContainer(types.ContainerStats{Name: "app", CPU: 120, Memory: 100 << 20})
+	node := newStatsWorker(
+		t, "node-1", types.NodeStats{
+			NodeID: "node-1",
+			Usage:  types.ResourceList{CPU: 800, Memory: 2 << 30},
+			Pods:   []types.PodStats{podStats},
+		},
+	)
+	if err := store.AddNode(node); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unreachable node falls back to its last reported usage
+	if err := store.AddNode(
+		types.Node{
+			NodeID:   "node-2",
+			Hostname: "127.0.0.1",
+			Port:     1,
+			Status:   types.NodeOffline,
+			Usage:    &types.ResourceList{CPU: 300},
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	return server, store
+}
+
+func TestGetPodStats(t *testing.T) {
+	server, _ := newMetricsServer(t)
+	e := echo.New()
+
+	tests := []struct {
+		name       string
+		podID      string
+		wantStatus int
+	}{
+		{name: "running pod", podID: "pod-1", wantStatus: http.StatusOK},
+		{name: "unknown pod", podID: "missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/pods/"+tt.podID+"/stats", nil)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("id")
+				c.SetParamValues(tt.podID)
+
+				if err := server.GetPodStats(c); err != nil {
+					t.Fatalf("GetPodStats() error = %v", err)
+				}
+				if rec.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var podStats types.PodStats
+				if err := json.Unmarshal(rec.Body.Bytes(), &podStats); err != nil {
+					t.Fatal(err)
+				}
+				if podStats.Usage.CPU != 120 || len(podStats.Containers) != 1 {
+					t.Errorf("pod stats = %+v, want 120m across one container", podStats)
+				}
+			},
+		)
+	}
+}
+
+func TestListPodMetrics(t *testing.T) {
+	server, _ := newMetricsServer(t)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics/pods", nil)
+	rec := httptest.NewRecorder()
+	if err := server.ListPodMetrics(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("ListPodMetrics() error = %v", err)
+	}
+
+	var metrics []types.PodMetrics
+	if err := json.Unmarshal(rec.Body.Bytes(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("metrics = %+v, want one pod", metrics)
+	}
+	m := metrics[0]
+	if !m.Available || m.Usage.CPU != 120 || m.Usage.Memory != 100<<20 {
+		t.Errorf("usage = %+v, want 120m and 100Mi", m.Usage)
+	}
+	if m.Requests.CPU != 500 || m.Limits.CPU != 1000 || m.Limits.Memory != 512<<20 {
+		t.Errorf("requests = %+v, limits = %+v", m.Requests, m.Limits)
+	}
+}
+
+func TestListNodeMetrics(t *testing.T) {
+	server, _ := newMetricsServer(t)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics/nodes", nil)
+	rec := httptest.NewRecorder()
+	if err := server.ListNodeMetrics(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("ListNodeMetrics() error = %v", err)
+	}
+
+	var metrics []types.NodeMetrics
+	if err := json.Unmarshal(rec.Body.Bytes(), &metrics); err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("metrics = %+v, want two nodes", metrics)
+	}
+	if m := metrics[0]; m.Usage.CPU != 800 || m.Requested.CPU != 500 || m.Capacity.CPU != 4000 {
+		t.Errorf("node-1 metrics = %+v", m)
+	}
+	if m := metrics[1]; !m.Available || m.Usage.CPU != 300 {
+		t.Errorf("node-2 metrics = %+v, want heartbeat usage of 300m", m)
+	}
+}
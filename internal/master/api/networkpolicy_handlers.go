@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateNetworkPolicyRequest represents a request to create a new network policy
+type CreateNetworkPolicyRequest struct {
+	Name        string                           `json:"name" validate:"required"`
+	Namespace   string                           `json:"namespace"`
+	PodSelector types.LabelSelector              `json:"podSelector"`
+	PolicyTypes []types.PolicyType               `json:"policyTypes"`
+	Ingress     []types.NetworkPolicyIngressRule `json:"ingress"`
+	Egress      []types.NetworkPolicyEgressRule  `json:"egress"`
+	Labels      map[string]string                `json:"labels"`
+	Annotations map[string]string                `json:"annotations"`
+}
+
+// UpdateNetworkPolicyRequest represents a request to replace a network policy's rules
+type UpdateNetworkPolicyRequest struct {
+	PodSelector types.LabelSelector              `json:"podSelector"`
+	PolicyTypes []types.PolicyType               `json:"policyTypes"`
+	Ingress     []types.NetworkPolicyIngressRule `json:"ingress"`
+	Egress      []types.NetworkPolicyEgressRule  `json:"egress"`
+	Labels      map[string]string                `json:"labels"`
+	Annotations map[string]string                `json:"annotations"`
+}
+
+// CreateNetworkPolicy handles POST /api/v1/networkpolicies
+func (s *Server) CreateNetworkPolicy(c echo.Context) error {
+	var req CreateNetworkPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	policy := types.NetworkPolicy{
+		PolicyID:    generateID(),
+		Name:        req.Name,
+		Namespace:   namespace,
+		PodSelector: req.PodSelector,
+		PolicyTypes: req.PolicyTypes,
+		Ingress:     req.Ingress,
+		Egress:      req.Egress,
+		Labels:      req.Labels,
+		Annotations: req.Annotations,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	defaultPolicyProtocols(&policy)
+
+	if err := policy.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.store.AddNetworkPolicy(policy); err != nil {
+		if errors.Is(err, state.ErrNetworkPolicyAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "network policy already exists"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, policy)
+}
+
+// ListNetworkPolicies handles GET /api/v1/networkpolicies
+// Returns all network policies, optionally filtered by namespace
+func (s *Server) ListNetworkPolicies(c echo.Context) error {
+	policies, err := s.store.ListNetworkPolicies(c.QueryParam("namespace"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policies)
+}
+
+// GetNetworkPolicy handles GET /api/v1/networkpolicies/:id
+func (s *Server) GetNetworkPolicy(c echo.Context) error {
+	policy, err := s.store.GetNetworkPolicy(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "network policy not found"})
+	}
+
+	return c.JSON(http.StatusOK, policy)
+}
+
+// UpdateNetworkPolicy handles PUT /api/v1/networkpolicies/:id
+// Replaces the policy's pod selector, policy types, rules, labels and annotations
+func (s *Server) UpdateNetworkPolicy(c echo.Context) error {
+	var req UpdateNetworkPolicyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	policy, err := s.store.GetNetworkPolicy(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "network policy not found"})
+	}
+
+	policy.PodSelector = req.PodSelector
+	policy.PolicyTypes = req.PolicyTypes
+	policy.Ingress = req.Ingress
+	policy.Egress = req.Egress
+	policy.Labels = req.Labels
+	policy.Annotations = req.Annotations
+	defaultPolicyProtocols(&policy)
+
+	if err := policy.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.store.UpdateNetworkPolicy(policy); err != nil {
+		if errors.Is(err, state.ErrNetworkPolicyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "network policy not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	updated, _ := s.store.GetNetworkPolicy(policy.PolicyID)
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteNetworkPolicy handles DELETE /api/v1/networkpolicies/:id
+func (s *Server) DeleteNetworkPolicy(c echo.Context) error {
+	if err := s.store.DeleteNetworkPolicy(c.Param("id")); err != nil {
+		if errors.Is(err, state.ErrNetworkPolicyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "network policy not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "network policy deleted successfully"})
+}
+
+// defaultPolicyProtocols sets the protocol of ports that have none to TCP and upper-cases the rest
+func defaultPolicyProtocols(policy *types.NetworkPolicy) {
+	normalize := func(ports []types.NetworkPolicyPort) {
+		for i := range ports {
+			ports[i].Protocol = strings.ToUpper(ports[i].Protocol)
+			if ports[i].Protocol == "" {
+				ports[i].Protocol = "TCP"
+			}
+		}
+	}
+	for i := range policy.Ingress {
+		normalize(policy.Ingress[i].Ports)
+	}
+	for i := range policy.Egress {
+		normalize(policy.Egress[i].Ports)
+	}
+}
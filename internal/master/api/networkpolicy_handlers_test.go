@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCreateNetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantStatus int
+	}{
+		{
+			name: "ingress from selected pods",
+			payload: `{"name":"api","podSelector":{"matchLabels":{"app":"api"}},` +
+				`"ingress":[{"from":[{"podSelector":{"matchLabels":{"app":"web"}}}],"ports":[{"port":8080}]}]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "deny all",
+			payload:    `{"name":"deny-all","policyTypes":["Ingress","Egress"]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "unknown policy type",
+			payload:    `{"name":"api","policyTypes":["Inbound"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid ip block",
+			payload:    `{"name":"api","egress":[{"to":[{"ipBlock":{"cidr":"10.0.0.0"}}]}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			payload:    `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server, e := setupTestServer()
+
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/networkpolicies", strings.NewReader(tt.payload))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+
+				if err := server.CreateNetworkPolicy(c); err != nil {
+					t.Fatalf("CreateNetworkPolicy failed: %v", err)
+				}
+
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+				}
+			},
+		)
+	}
+}
+
+func TestNetworkPolicyLifecycle(t *testing.T) {
+	_, e := setupTestServer()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	payload := `{"name":"api","podSelector":{"matchLabels":{"app":"api"}},` +
+		`"ingress":[{"ports":[{"protocol":"udp","port":53}]}]}`
+	rec := do(http.MethodPost, "/api/v1/networkpolicies", payload)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var created types.NetworkPolicy
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("failed to decode network policy: %v", err)
+	}
+	if created.Namespace != "default" {
+		t.Errorf("expected namespace default, got %q", created.Namespace)
+	}
+	if got := created.Ingress[0].Ports[0].Protocol; got != "UDP" {
+		t.Errorf("expected protocol to be upper-cased, got %q", got)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/networkpolicies", payload); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate: expected status 409, got %d", rec.Code)
+	}
+
+	update := `{"podSelector":{"matchLabels":{"app":"api"}},"egress":[{"ports":[{"port":5432}]}]}`
+	rec = do(http.MethodPut, "/api/v1/networkpolicies/"+created.PolicyID, update)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var updated types.NetworkPolicy
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("failed to decode network policy: %v", err)
+	}
+	if updated.Name != "api" || len(updated.Ingress) != 0 || updated.Egress[0].Ports[0].Protocol != "TCP" {
+		t.Errorf("unexpected network policy after update: %+v", updated)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/networkpolicies?namespace=default", "")
+	var list []types.NetworkPolicy
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 {
+		t.Errorf("list: expected 1 network policy, got %s", rec.Body.String())
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/networkpolicies/"+created.PolicyID, ""); rec.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/networkpolicies/"+created.PolicyID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: expected status 404, got %d", rec.Code)
+	}
+}
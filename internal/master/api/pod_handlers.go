@@ -15,12 +15,14 @@ import (
 
 // CreatePodRequest represents a request to create a new pod
 type CreatePodRequest struct {
-	Name          string              `json:"name" validate:"required"`
-	Namespace     string              `json:"namespace,omitempty"`
-	Labels        map[string]string   `json:"labels,omitempty"`
-	Annotations   map[string]string   `json:"annotations,omitempty"`
-	Containers    []types.Container   `json:"containers" validate:"required,min=1"`
-	RestartPolicy types.RestartPolicy `json:"restartPolicy,omitempty"`
+	Name             string                 `json:"name" validate:"required"`
+	Namespace        string                 `json:"namespace,omitempty"`
+	Labels           map[string]string      `json:"labels,omitempty"`
+	Annotations      map[string]string      `json:"annotations,omitempty"`
+	Containers       []types.Container      `json:"containers" validate:"required,min=1"`
+	RestartPolicy    types.RestartPolicy    `json:"restartPolicy,omitempty"`
+	ImagePullSecrets []string               `json:"imagePullSecrets,omitempty"`
+	SecurityContext  *types.SecurityContext `json:"securityContext,omitempty"`
 }
 
 // UpdatePodStatusRequest represents a request to update a pod's status
@@ -32,6 +34,17 @@ type UpdatePodStatusRequest struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// AddPodEventsRequest represents a request to record events on a pod
+type AddPodEventsRequest struct {
+	Events []types.PodEvent `json:"events"`
+}
+
+// ExecutePodRequest is the payload sent to a worker to run a pod
+type ExecutePodRequest struct {
+	Pod                 types.Pod                   `json:"pod"`
+	RegistryCredentials []types.RegistryCredentials `json:"registryCredentials,omitempty"`
+}
+
 // CreatePod handles POST /api/v1/pods
 func (s *Server) CreatePod(c echo.Context) error {
 	var req CreatePodRequest
@@ -60,6 +73,19 @@ func (s *Server) CreatePod(c echo.Context) error {
 				http.StatusBadRequest, map[string]string{"error": "container names must be unique within a pod"},
 			)
 		}
+		if !container.ImagePullPolicy.IsValid() {
+			return c.JSON(
+				http.StatusBadRequest,
+				map[string]string{"error": fmt.Sprintf("invalid imagePullPolicy: %s", container.ImagePullPolicy)},
+			)
+		}
+		// The worker applies the pod's context overridden by the container's, so validate them together
+		if err := types.MergeSecurityContext(req.SecurityContext, container.SecurityContext).Validate(); err != nil {
+			return c.JSON(
+				http.StatusBadRequest,
+				map[string]string{"error": fmt.Sprintf("container %s: invalid securityContext: %v", container.Name, err)},
+			)
+		}
 		containerNames[container.Name] = true
 	}
 
@@ -75,15 +101,22 @@ func (s *Server) CreatePod(c echo.Context) error {
 	}
 
 	pod := types.Pod{
-		PodID:         generateID(),
-		Name:          req.Name,
-		Namespace:     namespace,
-		Labels:        req.Labels,
-		Annotations:   req.Annotations,
-		Containers:    containers,
-		Status:        types.PodPending,
-		RestartPolicy: req.RestartPolicy,
-		CreatedAt:     time.Now(),
+		PodID:            generateID(),
+		Name:             req.Name,
+		Namespace:        namespace,
+		Labels:           req.Labels,
+		Annotations:      req.Annotations,
+		Containers:       containers,
+		Status:           types.PodPending,
+		RestartPolicy:    req.RestartPolicy,
+		ImagePullSecrets: req.ImagePullSecrets,
+		SecurityContext:  req.SecurityContext,
+		CreatedAt:        time.Now(),
+	}
+	pod.QOSClass = pod.GetQOSClass()
+
+	if err := s.admission.AdmitPod(&pod); err != nil {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
 	}
 
 	if err := s.store.AddPod(pod); err != nil {
@@ -177,6 +210,36 @@ func (s *Server) UpdatePodStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, pod)
 }
 
+// AddPodEvents handles POST /api/v1/pods/:id/events
+func (s *Server) AddPodEvents(c echo.Context) error {
+	podID := c.Param("id")
+
+	var req AddPodEventsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	if len(req.Events) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at least one event is required"})
+	}
+
+	now := time.Now()
+	for i := range req.Events {
+		if req.Events[i].Timestamp.IsZero() {
+			req.Events[i].Timestamp = now
+		}
+	}
+
+	if err := s.store.UpdatePod(podID, state.PodUpdate{Events: req.Events}); err != nil {
+		if errors.Is(err, state.ErrPodNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "pod not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // DeletePod handles DELETE /api/v1/pods/:id
 func (s *Server) DeletePod(c echo.Context) error {
 	podID := c.Param("id")
@@ -274,8 +337,9 @@ func (s *Server) triggerPodExecution(podID string, node types.Node) {
 
 	url := fmt.Sprintf("http://%s:%d/api/v1/pods/%s/execute", node.Hostname, node.Port, podID)
 
-	payload := map[string]interface{}{
-		"pod": pod,
+	payload := ExecutePodRequest{
+		Pod:                 pod,
+		RegistryCredentials: s.resolveRegistryCredentials(&pod),
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -296,3 +360,36 @@ func (s *Server) triggerPodExecution(podID string, node types.Node) {
 	}
 	defer func() { _ = resp.Body.Close() }()
 }
+
+// resolveRegistryCredentials looks up the pod's image pull secrets
+// Missing or invalid secrets are recorded as warning events and skipped
+func (s *Server) resolveRegistryCredentials(pod *types.Pod) []types.RegistryCredentials {
+	var creds []types.RegistryCredentials
+	var events []types.PodEvent
+
+	for _, name := range pod.ImagePullSecrets {
+		secret, err := s.store.GetSecretByName(pod.Namespace, name)
+		if err == nil {
+			var cred types.RegistryCredentials
+			cred, err = secret.RegistryCredentials()
+			if err == nil {
+				creds = append(creds, cred)
+				continue
+			}
+		}
+		events = append(
+			events, types.PodEvent{
+				Type:      types.EventTypeWarning,
+				Reason:    "FailedToRetrieveImagePullSecret",
+				Message:   fmt.Sprintf("unable to use image pull secret %q: %v", name, err),
+				Timestamp: time.Now(),
+			},
+		)
+	}
+
+	if len(events) > 0 {
+		_ = s.store.UpdatePod(pod.PodID, state.PodUpdate{Events: events})
+	}
+
+	return creds
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/master/admission"
 	"github.com/danpasecinic/podling/internal/master/scheduler"
 	"github.com/danpasecinic/podling/internal/master/services"
 	"github.com/danpasecinic/podling/internal/master/state"
@@ -473,3 +474,93 @@ func generateTestPodID(n int) string {
 func generateTestPodName(n int) string {
 	return "test-pod-" + string(rune('0'+n))
 }
+
+func TestCreatePodSecurityContext(t *testing.T) {
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+	server.SetAdmissionChain(admission.Chain{admission.DenyPrivileged{}})
+	e := echo.New()
+
+	tests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name: "non-root read-only pod is admitted",
+			payload: map[string]interface{}{
+				"name": "secure",
+				"securityContext": map[string]interface{}{
+					"runAsUser":              1000,
+					"readOnlyRootFilesystem": true,
+				},
+				"containers": []map[string]interface{}{
+					{"name": "app", "image": "nginx:1.25", "securityContext": map[string]interface{}{
+						"capabilities": map[string]interface{}{"drop": []string{"ALL"}},
+					}},
+				},
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "container overrides only the group of the pod's user",
+			payload: map[string]interface{}{
+				"name":            "split",
+				"securityContext": map[string]interface{}{"runAsUser": 1000},
+				"containers": []map[string]interface{}{
+					{"name": "app", "image": "nginx:1.25", "securityContext": map[string]interface{}{"runAsGroup": 3000}},
+				},
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "privileged container is denied",
+			payload: map[string]interface{}{
+				"name": "privileged",
+				"containers": []map[string]interface{}{
+					{"name": "app", "image": "nginx:1.25", "securityContext": map[string]interface{}{"privileged": true}},
+				},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "invalid container security context",
+			payload: map[string]interface{}{
+				"name": "invalid",
+				"containers": []map[string]interface{}{
+					{"name": "app", "image": "nginx:1.25", "securityContext": map[string]interface{}{"runAsGroup": 10}},
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				body, _ := json.Marshal(tt.payload)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/pods", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+
+				if err := server.CreatePod(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("CreatePod failed: %v", err)
+				}
+
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+				}
+			},
+		)
+	}
+
+	pods, _ := store.ListPods()
+	if len(pods) != 2 {
+		t.Fatalf("expected only the admitted pods to be stored, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if pod.SecurityContext == nil || *pod.SecurityContext.RunAsUser != 1000 {
+			t.Errorf("expected pod security context of %s to be stored, got %+v", pod.Name, pod.SecurityContext)
+		}
+	}
+}
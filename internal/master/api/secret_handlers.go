@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// CreateSecretRequest represents a request to create a new secret
+type CreateSecretRequest struct {
+	Name      string            `json:"name" validate:"required"`
+	Namespace string            `json:"namespace"`
+	Type      types.SecretType  `json:"type"`
+	Data      map[string]string `json:"data"`
+}
+
+// CreateSecret handles POST /api/v1/secrets
+// Secret values are never returned by the API
+func (s *Server) CreateSecret(c echo.Context) error {
+	var req CreateSecretRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	secretType := req.Type
+	if secretType == "" {
+		secretType = types.SecretTypeOpaque
+	}
+
+	secret := types.Secret{
+		SecretID:  generateID(),
+		Name:      req.Name,
+		Namespace: namespace,
+		Type:      secretType,
+		Data:      req.Data,
+		CreatedAt: time.Now(),
+	}
+
+	if err := secret.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := s.store.AddSecret(secret); err != nil {
+		if errors.Is(err, state.ErrSecretAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "secret already exists"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, secret.Redacted())
+}
+
+// ListSecrets handles GET /api/v1/secrets
+// Returns all secrets, optionally filtered by namespace
+func (s *Server) ListSecrets(c echo.Context) error {
+	namespace := c.QueryParam("namespace")
+
+	secrets, err := s.store.ListSecrets(namespace)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	for i := range secrets {
+		secrets[i] = secrets[i].Redacted()
+	}
+
+	return c.JSON(http.StatusOK, secrets)
+}
+
+// GetSecret handles GET /api/v1/secrets/:id
+func (s *Server) GetSecret(c echo.Context) error {
+	secretID := c.Param("id")
+
+	secret, err := s.store.GetSecret(secretID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "secret not found"})
+	}
+
+	return c.JSON(http.StatusOK, secret.Redacted())
+}
+
+// DeleteSecret handles DELETE /api/v1/secrets/:id
+func (s *Server) DeleteSecret(c echo.Context) error {
+	secretID := c.Param("id")
+
+	if err := s.store.DeleteSecret(secretID); err != nil {
+		if errors.Is(err, state.ErrSecretNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "secret not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "secret deleted successfully"})
+}
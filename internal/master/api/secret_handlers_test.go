@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestCreateSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name: "registry secret",
+			payload: map[string]interface{}{
+				"name": "regcred",
+				"type": "DockerRegistry",
+				"data": map[string]string{"server": "registry.example.com", "username": "ci", "password": "s3cret"},
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "registry secret missing password",
+			payload: map[string]interface{}{
+				"name": "incomplete",
+				"type": "DockerRegistry",
+				"data": map[string]string{"server": "registry.example.com", "username": "ci"},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing name",
+			payload:    map[string]interface{}{"data": map[string]string{"k": "v"}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				server, e := setupTestServer()
+
+				body, _ := json.Marshal(tt.payload)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/secrets", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+
+				if err := server.CreateSecret(c); err != nil {
+					t.Fatalf("CreateSecret failed: %v", err)
+				}
+
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+				}
+
+				if strings.Contains(rec.Body.String(), "s3cret") {
+					t.Error("response must not contain secret values")
+				}
+			},
+		)
+	}
+}
+
+func TestCreateSecret_Duplicate(t *testing.T) {
+	server, e := setupTestServer()
+
+	payload := `{"name":"keys","data":{"token":"abc"}}`
+	for i, want := range []int{http.StatusCreated, http.StatusConflict} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/secrets", strings.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		if err := server.CreateSecret(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("CreateSecret failed: %v", err)
+		}
+		if rec.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i, want, rec.Code)
+		}
+	}
+}
+
+func TestListSecrets_Redacted(t *testing.T) {
+	server, e := setupTestServer()
+
+	_ = server.store.AddSecret(
+		types.Secret{
+			SecretID:  "secret-1",
+			Name:      "keys",
+			Namespace: "default",
+			Type:      types.SecretTypeOpaque,
+			Data:      map[string]string{"token": "abc123"},
+			CreatedAt: time.Now(),
+		},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/secrets", nil)
+	rec := httptest.NewRecorder()
+
+	if err := server.ListSecrets(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("ListSecrets failed: %v", err)
+	}
+
+	var secrets []types.Secret
+	if err := json.Unmarshal(rec.Body.Bytes(), &secrets); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(secrets) != 1 {
+		t.Fatalf("expected 1 secret, got %d", len(secrets))
+	}
+	if secrets[0].Data["token"] == "abc123" {
+		t.Error("expected secret value to be redacted")
+	}
+}
+
+func TestDeleteSecret(t *testing.T) {
+	server, e := setupTestServer()
+
+	_ = server.store.AddSecret(types.Secret{SecretID: "secret-1", Name: "keys", Type: types.SecretTypeOpaque})
+
+	for _, want := range []int{http.StatusOK, http.StatusNotFound} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/secrets/secret-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("secret-1")
+
+		if err := server.DeleteSecret(c); err != nil {
+			t.Fatalf("DeleteSecret failed: %v", err)
+		}
+		if rec.Code != want {
+			t.Errorf("expected status %d, got %d", want, rec.Code)
+		}
+	}
+}
+
+func TestResolveRegistryCredentials(t *testing.T) {
+	server, _ := setupTestServer()
+
+	_ = server.store.AddSecret(
+		types.Secret{
+			SecretID:  "secret-1",
+			Name:      "regcred",
+			Namespace: "default",
+			Type:      types.SecretTypeDockerRegistry,
+			Data:      map[string]string{"server": "registry.example.com", "username": "ci", "password": "pw"},
+		},
+	)
+	_ = server.store.AddSecret(
+		types.Secret{SecretID: "secret-2", Name: "opaque", Namespace: "default", Type: types.SecretTypeOpaque},
+	)
+
+	pod := types.Pod{
+		PodID:            "pod-1",
+		Name:             "app",
+		Namespace:        "default",
+		Status:           types.PodScheduled,
+		ImagePullSecrets: []string{"regcred", "missing", "opaque"},
+	}
+	_ = server.store.AddPod(pod)
+
+	creds := server.resolveRegistryCredentials(&pod)
+
+	if len(creds) != 1 {
+		t.Fatalf("expected 1 credential, got %d", len(creds))
+	}
+	if creds[0].Server != "registry.example.com" || creds[0].Username != "ci" {
+		t.Errorf("unexpected credentials: %+v", creds[0])
+	}
+
+	updated, _ := server.store.GetPod("pod-1")
+	if len(updated.Events) != 2 {
+		t.Fatalf("expected 2 warning events for unusable secrets, got %d", len(updated.Events))
+	}
+	for _, event := range updated.Events {
+		if event.Type != types.EventTypeWarning {
+			t.Errorf("expected warning event, got %s", event.Type)
+		}
+	}
+}
+
+func TestAddPodEvents(t *testing.T) {
+	server, e := setupTestServer()
+	_ = server.store.AddPod(types.Pod{PodID: "pod-1", Name: "app", Status: types.PodRunning})
+
+	tests := []struct {
+		name       string
+		podID      string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "record events",
+			podID:      "pod-1",
+			body:       `{"events":[{"type":"Warning","reason":"ErrImagePull","message":"denied"}]}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "no events",
+			podID:      "pod-1",
+			body:       `{"events":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown pod",
+			podID:      "missing",
+			body:       `{"events":[{"type":"Normal","reason":"Pulled"}]}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("id")
+				c.SetParamValues(tt.podID)
+
+				if err := server.AddPodEvents(c); err != nil {
+					t.Fatalf("AddPodEvents failed: %v", err)
+				}
+				if rec.Code != tt.wantStatus {
+					t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+				}
+			},
+		)
+	}
+
+	pod, _ := server.store.GetPod("pod-1")
+	if len(pod.Events) != 1 || pod.Events[0].Reason != types.ReasonErrImagePull {
+		t.Errorf("expected ErrImagePull event to be recorded, got %+v", pod.Events)
+	}
+	if pod.Status != types.PodRunning {
+		t.Errorf("expected status to be unchanged, got %s", pod.Status)
+	}
+}
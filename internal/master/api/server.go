@@ -5,6 +5,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/master/admission"
+	"github.com/danpasecinic/podling/internal/master/logstore"
 	"github.com/danpasecinic/podling/internal/master/scheduler"
 	"github.com/danpasecinic/podling/internal/master/services"
 	"github.com/danpasecinic/podling/internal/master/state"
@@ -17,6 +19,9 @@ type Server struct {
 	store              state.StateStore
 	scheduler          scheduler.Scheduler
 	endpointController *services.EndpointController
+	admission          admission.Chain
+	logs               *logstore.Store
+	podCIDRs           *services.PodCIDRAllocator
 }
 
 // NewServer creates a new API server with the given state store and scheduler.
@@ -30,6 +35,23 @@ func NewServer(
 	}
 }
 
+// SetAdmissionChain sets the admission plugins that pods must pass before they are created.
+func (s *Server) SetAdmissionChain(chain admission.Chain) {
+	s.admission = chain
+}
+
+// SetLogStore sets the store that retains the container logs shipped by workers.
+// Without a store the log shipping and search endpoints are unavailable.
+func (s *Server) SetLogStore(store *logstore.Store) {
+	s.logs = store
+}
+
+// SetPodCIDRAllocator sets the allocator that assigns each registering node its pod subnet.
+// Without an allocator nodes get no pod CIDR and their runtimes pick pod addresses themselves.
+func (s *Server) SetPodCIDRAllocator(allocator *services.PodCIDRAllocator) {
+	s.podCIDRs = allocator
+}
+
 // RegisterRoutes registers all API endpoints with the Echo router.
 // Routes are grouped under /api/v1 for versioning.
 func (s *Server) RegisterRoutes(e *echo.Echo) {
@@ -40,18 +62,28 @@ func (s *Server) RegisterRoutes(e *echo.Echo) {
 	v1.GET("/tasks", s.ListTasks)
 	v1.GET("/tasks/:id", s.GetTask)
 	v1.PUT("/tasks/:id/status", s.UpdateTaskStatus)
+	v1.GET("/tasks/:id/logs/stream", s.StreamTaskLogs)
 
 	// Pod routes
 	v1.POST("/pods", s.CreatePod)
 	v1.GET("/pods", s.ListPods)
 	v1.GET("/pods/:id", s.GetPod)
 	v1.PUT("/pods/:id/status", s.UpdatePodStatus)
+	v1.POST("/pods/:id/events", s.AddPodEvents)
+	v1.GET("/pods/:id/stats", s.GetPodStats)
+	v1.GET("/pods/:id/logs/stream", s.StreamPodLogs)
+	v1.POST("/pods/:id/exec", s.ExecPod)
+	v1.POST("/pods/:id/portforward", s.PortForwardPod)
+	v1.GET("/pods/:id/files", s.DownloadPodFiles)
+	v1.PUT("/pods/:id/files", s.UploadPodFiles)
 	v1.DELETE("/pods/:id", s.DeletePod)
 
 	// Node routes
 	v1.POST("/nodes/register", s.RegisterNode)
 	v1.POST("/nodes/:id/heartbeat", s.NodeHeartbeat)
 	v1.POST("/nodes/:id/deregister", s.NodeDeregister)
+	v1.POST("/nodes/:id/cordon", s.CordonNode)
+	v1.POST("/nodes/:id/uncordon", s.UncordonNode)
 	v1.GET("/nodes", s.ListNodes)
 
 	// Service routes
@@ -61,6 +93,35 @@ func (s *Server) RegisterRoutes(e *echo.Echo) {
 	v1.PUT("/services/:id", s.UpdateService)
 	v1.DELETE("/services/:id", s.DeleteService)
 	v1.GET("/services/:id/endpoints", s.GetEndpoints)
+	v1.GET("/endpoints", s.ListEndpoints)
+
+	// Secret routes
+	v1.POST("/secrets", s.CreateSecret)
+	v1.GET("/secrets", s.ListSecrets)
+	v1.GET("/secrets/:id", s.GetSecret)
+	v1.DELETE("/secrets/:id", s.DeleteSecret)
+
+	// Ingress routes
+	v1.POST("/ingresses", s.CreateIngress)
+	v1.GET("/ingresses", s.ListIngresses)
+	v1.GET("/ingresses/:id", s.GetIngress)
+	v1.PUT("/ingresses/:id", s.UpdateIngress)
+	v1.DELETE("/ingresses/:id", s.DeleteIngress)
+
+	// Network policy routes
+	v1.POST("/networkpolicies", s.CreateNetworkPolicy)
+	v1.GET("/networkpolicies", s.ListNetworkPolicies)
+	v1.GET("/networkpolicies/:id", s.GetNetworkPolicy)
+	v1.PUT("/networkpolicies/:id", s.UpdateNetworkPolicy)
+	v1.DELETE("/networkpolicies/:id", s.DeleteNetworkPolicy)
+
+	// Metrics routes
+	v1.GET("/metrics/pods", s.ListPodMetrics)
+	v1.GET("/metrics/nodes", s.ListNodeMetrics)
+
+	// Log routes
+	v1.POST("/logs", s.ShipLogs)
+	v1.GET("/logs", s.SearchLogs)
 
 	// Maintenance routes
 	v1.POST("/prune", s.Prune)
@@ -1,9 +1,15 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/danpasecinic/podling/internal/master/services"
+	"github.com/danpasecinic/podling/internal/master/state"
 	"github.com/danpasecinic/podling/internal/types"
 	"github.com/labstack/echo/v4"
 )
@@ -13,24 +19,34 @@ type CreateServiceRequest struct {
 	Name            string              `json:"name" validate:"required"`
 	Namespace       string              `json:"namespace"`
 	Type            types.ServiceType   `json:"type"`
-	Selector        map[string]string   `json:"selector" validate:"required"`
-	Ports           []types.ServicePort `json:"ports" validate:"required"`
+	ClusterIP       string              `json:"clusterIp"`
+	ExternalName    string              `json:"externalName"`
+	Selector        map[string]string   `json:"selector"`
+	Ports           []types.ServicePort `json:"ports"`
 	Labels          map[string]string   `json:"labels"`
 	Annotations     map[string]string   `json:"annotations"`
 	SessionAffinity string              `json:"sessionAffinity"`
+
+	SessionAffinityTimeoutSeconds int                          `json:"sessionAffinityTimeoutSeconds"`
+	LoadBalancing                 types.LoadBalancingAlgorithm `json:"loadBalancing"`
 }
 
 // UpdateServiceRequest represents a request to update a service
 type UpdateServiceRequest struct {
 	Selector        *map[string]string   `json:"selector"`
+	ExternalName    *string              `json:"externalName"`
 	Ports           *[]types.ServicePort `json:"ports"`
 	Labels          *map[string]string   `json:"labels"`
 	Annotations     *map[string]string   `json:"annotations"`
 	SessionAffinity *string              `json:"sessionAffinity"`
+
+	SessionAffinityTimeoutSeconds *int                          `json:"sessionAffinityTimeoutSeconds"`
+	LoadBalancing                 *types.LoadBalancingAlgorithm `json:"loadBalancing"`
 }
 
 // CreateService handles POST /api/v1/services
-// Creates a new service and allocates a ClusterIP if needed
+// Creates a new service and allocates a ClusterIP unless the service is headless or an ExternalName
+// service, plus node ports for NodePort and LoadBalancer services
 func (s *Server) CreateService(c echo.Context) error {
 	var req CreateServiceRequest
 	if err := c.Bind(&req); err != nil {
@@ -41,14 +57,6 @@ func (s *Server) CreateService(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "name is required"})
 	}
 
-	if len(req.Selector) == 0 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "selector is required"})
-	}
-
-	if len(req.Ports) == 0 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at least one port is required"})
-	}
-
 	namespace := req.Namespace
 	if namespace == "" {
 		namespace = "default"
@@ -59,22 +67,30 @@ func (s *Server) CreateService(c echo.Context) error {
 		serviceType = types.ServiceTypeClusterIP
 	}
 
-	var clusterIP string
-	if serviceType == types.ServiceTypeClusterIP {
-		ip, err := s.endpointController.AllocateClusterIP()
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to allocate cluster IP"})
-		}
-		clusterIP = ip
+	switch serviceType {
+	case types.ServiceTypeClusterIP, types.ServiceTypeNodePort, types.ServiceTypeLoadBalancer,
+		types.ServiceTypeExternalName:
+	default:
+		return c.JSON(
+			http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid service type %q", serviceType)},
+		)
 	}
 
-	for i := range req.Ports {
-		if req.Ports[i].TargetPort == 0 {
-			req.Ports[i].TargetPort = req.Ports[i].Port
-		}
-		if req.Ports[i].Protocol == "" {
-			req.Ports[i].Protocol = "TCP"
-		}
+	if req.ClusterIP != "" && req.ClusterIP != types.ClusterIPNone {
+		return c.JSON(
+			http.StatusBadRequest,
+			map[string]string{"error": fmt.Sprintf("clusterIp must be empty or %q", types.ClusterIPNone)},
+		)
+	}
+
+	if err := validateServiceRouting(
+		req.SessionAffinity, req.SessionAffinityTimeoutSeconds, req.LoadBalancing,
+	); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := validateServicePorts(req.Ports); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
 	service := types.Service{
@@ -82,7 +98,8 @@ func (s *Server) CreateService(c echo.Context) error {
 		Name:            req.Name,
 		Namespace:       namespace,
 		Type:            serviceType,
-		ClusterIP:       clusterIP,
+		ClusterIP:       req.ClusterIP,
+		ExternalName:    normalizeExternalName(req.ExternalName),
 		Selector:        req.Selector,
 		Ports:           req.Ports,
 		Labels:          req.Labels,
@@ -90,12 +107,54 @@ func (s *Server) CreateService(c echo.Context) error {
 		SessionAffinity: req.SessionAffinity,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
+
+		SessionAffinityTimeoutSeconds: req.SessionAffinityTimeoutSeconds,
+		LoadBalancing:                 req.LoadBalancing,
 	}
 
-	if err := s.store.AddService(service); err != nil {
-		if clusterIP != "" {
-			_ = s.endpointController.ReleaseClusterIP(clusterIP)
+	if err := validateServiceAddressing(service); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	for i := range service.Ports {
+		if service.Ports[i].TargetPort == 0 && service.Ports[i].TargetPortName == "" {
+			service.Ports[i].TargetPort = service.Ports[i].Port
+		}
+		if service.Ports[i].Protocol == "" {
+			service.Ports[i].Protocol = "TCP"
+		}
+		if service.Ports[i].NodePort != 0 && !service.UsesNodePorts() {
+			return c.JSON(
+				http.StatusBadRequest, map[string]string{"error": "nodePort requires type NodePort or LoadBalancer"},
+			)
+		}
+	}
+
+	// Headless and ExternalName services have no virtual IP
+	allocated := service.ClusterIP == "" && !service.IsExternalName()
+	if allocated {
+		clusterIP, err := s.endpointController.AllocateClusterIP()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to allocate cluster IP"})
+		}
+		service.ClusterIP = clusterIP
+	}
+	releaseClusterIP := func() {
+		if allocated {
+			_ = s.endpointController.ReleaseClusterIP(service.ClusterIP)
 		}
+	}
+
+	if service.UsesNodePorts() {
+		if status, err := s.allocateNodePorts(service.ServiceID, service.Ports, nil); err != nil {
+			releaseClusterIP()
+			return c.JSON(status, map[string]string{"error": err.Error()})
+		}
+	}
+
+	if err := s.store.AddService(service); err != nil {
+		releaseClusterIP()
+		_ = s.endpointController.ReleaseNodePorts(service.ServiceID)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -138,18 +197,89 @@ func (s *Server) UpdateService(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
+	current, err := s.store.GetService(serviceID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "service not found"})
+	}
+
+	sessionAffinity := current.SessionAffinity
+	if req.SessionAffinity != nil {
+		sessionAffinity = *req.SessionAffinity
+	}
+	affinityTimeout := current.SessionAffinityTimeoutSeconds
+	if req.SessionAffinityTimeoutSeconds != nil {
+		affinityTimeout = *req.SessionAffinityTimeoutSeconds
+	}
+	algorithm := current.LoadBalancing
+	if req.LoadBalancing != nil {
+		algorithm = *req.LoadBalancing
+	}
+	if err := validateServiceRouting(sessionAffinity, affinityTimeout, algorithm); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// The updated service must still be consistent with how it is addressed
+	updated := current
+	updated.SessionAffinity, updated.LoadBalancing = sessionAffinity, algorithm
+	if req.Selector != nil {
+		updated.Selector = *req.Selector
+	}
+	if req.ExternalName != nil {
+		*req.ExternalName = normalizeExternalName(*req.ExternalName)
+		updated.ExternalName = *req.ExternalName
+	}
+	if req.Ports != nil {
+		updated.Ports = *req.Ports
+	}
+	if err := validateServiceAddressing(updated); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if req.Ports != nil {
+		if err := validateServicePorts(*req.Ports); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		for i := range *req.Ports {
+			port := &(*req.Ports)[i]
+			if port.TargetPort == 0 && port.TargetPortName == "" {
+				port.TargetPort = port.Port
+			}
+			if port.Protocol == "" {
+				port.Protocol = "TCP"
+			}
+			if port.NodePort != 0 && !current.UsesNodePorts() {
+				return c.JSON(
+					http.StatusBadRequest, map[string]string{"error": "nodePort requires type NodePort or LoadBalancer"},
+				)
+			}
+		}
+		if current.UsesNodePorts() {
+			if status, err := s.allocateNodePorts(serviceID, *req.Ports, current.Ports); err != nil {
+				return c.JSON(status, map[string]string{"error": err.Error()})
+			}
+		}
+	}
+
 	update := types.ServiceUpdate{
 		Selector:        req.Selector,
+		ExternalName:    req.ExternalName,
 		Ports:           req.Ports,
 		Labels:          req.Labels,
 		Annotations:     req.Annotations,
 		SessionAffinity: req.SessionAffinity,
+
+		SessionAffinityTimeoutSeconds: req.SessionAffinityTimeoutSeconds,
+		LoadBalancing:                 req.LoadBalancing,
 	}
 
 	if err := s.store.UpdateService(serviceID, update); err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
 
+	if req.Ports != nil && current.UsesNodePorts() {
+		s.releaseUnusedNodePorts(current.Ports, *req.Ports)
+	}
+
 	service, _ := s.store.GetService(serviceID)
 	return c.JSON(http.StatusOK, service)
 }
@@ -170,13 +300,207 @@ func (s *Server) DeleteService(c echo.Context) error {
 
 	_ = s.store.DeleteEndpoints(serviceID)
 
-	if service.ClusterIP != "" {
+	if service.ClusterIP != "" && !service.IsHeadless() {
 		_ = s.endpointController.ReleaseClusterIP(service.ClusterIP)
 	}
+	_ = s.endpointController.ReleaseNodePorts(serviceID)
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "service deleted"})
 }
 
+// validateServiceRouting checks a service's session affinity and load balancing settings
+func validateServiceRouting(affinity string, timeoutSeconds int, algorithm types.LoadBalancingAlgorithm) error {
+	switch affinity {
+	case "", types.SessionAffinityNone, types.SessionAffinityClientIP:
+	default:
+		return fmt.Errorf("invalid session affinity %q (expected None or ClientIP)", affinity)
+	}
+	if timeoutSeconds < 0 || timeoutSeconds > types.MaxSessionAffinityTimeout {
+		return fmt.Errorf("sessionAffinityTimeoutSeconds must be between 0 and %d", types.MaxSessionAffinityTimeout)
+	}
+	if !algorithm.IsValid() {
+		return fmt.Errorf(
+			"invalid load balancing algorithm %q (expected RoundRobin, Random, LeastConnections or Weighted)",
+			algorithm,
+		)
+	}
+	return nil
+}
+
+// validateServiceAddressing checks the settings that depend on how a service is reached. Headless
+// services get no virtual IP, so they cannot have node ports, session affinity or a load balancing
+// algorithm; ExternalName services are only a DNS alias and select no pods either.
+func validateServiceAddressing(service types.Service) error {
+	if service.IsExternalName() {
+		if service.ExternalName == "" {
+			return errors.New("externalName is required for type ExternalName")
+		}
+		if err := validateExternalName(service.ExternalName); err != nil {
+			return err
+		}
+		if service.ClusterIP != "" {
+			return errors.New("clusterIp is not allowed for type ExternalName")
+		}
+		if len(service.Selector) > 0 {
+			return errors.New("selector is not allowed for type ExternalName")
+		}
+	} else {
+		if service.ExternalName != "" {
+			return errors.New("externalName requires type ExternalName")
+		}
+		if len(service.Selector) == 0 {
+			return errors.New("selector is required")
+		}
+		// A headless service without ports still resolves to its endpoints
+		if len(service.Ports) == 0 && !service.IsHeadless() {
+			return errors.New("at least one port is required")
+		}
+	}
+
+	if !service.IsHeadless() && !service.IsExternalName() {
+		return nil
+	}
+	if service.IsHeadless() && service.Type != types.ServiceTypeClusterIP {
+		return fmt.Errorf("a headless service must have type ClusterIP, not %s", service.Type)
+	}
+	if service.SessionAffinity == types.SessionAffinityClientIP {
+		return errors.New("session affinity requires a cluster IP")
+	}
+	if service.LoadBalancing != "" {
+		return errors.New("load balancing requires a cluster IP")
+	}
+	return nil
+}
+
+// validateExternalName checks that name is a DNS name of lower-case alphanumeric labels and hyphens,
+// as in RFC 1123; addresses are rejected, since a CNAME cannot point at one
+func validateExternalName(name string) error {
+	if len(name) > 253 {
+		return fmt.Errorf("externalName %q is longer than 253 characters", name)
+	}
+	if net.ParseIP(name) != nil {
+		return fmt.Errorf("externalName %q must be a DNS name, not an IP address", name)
+	}
+	for _, label := range strings.Split(name, ".") {
+		valid := len(label) > 0 && len(label) <= 63 && label[0] != '-' && label[len(label)-1] != '-'
+		for _, r := range label {
+			valid = valid && (r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-')
+		}
+		if !valid {
+			return fmt.Errorf("externalName %q is not a valid DNS name", name)
+		}
+	}
+	return nil
+}
+
+// normalizeExternalName lower-cases a DNS name and drops its trailing dot
+func normalizeExternalName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
+
+// validateServicePorts checks that target ports are either a number or a name, and that every
+// port is named when a service with several ports targets a container port by name, since
+// pods that lack the name serve only some of the ports
+func validateServicePorts(ports []types.ServicePort) error {
+	named := false
+	for _, port := range ports {
+		if port.TargetPort != 0 && port.TargetPortName != "" {
+			return fmt.Errorf("port %d: targetPort and targetPortName are mutually exclusive", port.Port)
+		}
+		if port.TargetPort < 0 || port.TargetPort > 65535 {
+			return fmt.Errorf("port %d: targetPort %d is out of range", port.Port, port.TargetPort)
+		}
+		named = named || port.TargetPortName != ""
+	}
+
+	if named && len(ports) > 1 {
+		for _, port := range ports {
+			if port.Name == "" {
+				return fmt.Errorf("port %d must be named, as the service has several ports and named target ports", port.Port)
+			}
+		}
+	}
+	return nil
+}
+
+// allocateNodePorts assigns a node port to every port, keeping the node port of the matching
+// previous port when none is requested. On failure the ports reserved by this call are released
+// and the HTTP status to report is returned with the error.
+func (s *Server) allocateNodePorts(serviceID string, ports, previous []types.ServicePort) (int, error) {
+	held := make(map[string]int, len(previous))
+	heldPorts := make(map[state.NodePortKey]bool, len(previous))
+	for _, port := range previous {
+		if port.NodePort != 0 {
+			held[servicePortKey(port)] = port.NodePort
+			heldPorts[state.NodePortKey{Port: port.NodePort, Protocol: port.Protocol}] = true
+		}
+	}
+
+	var reserved []state.NodePortKey
+	release := func() {
+		for _, key := range reserved {
+			_ = s.endpointController.ReleaseNodePort(key.Port, key.Protocol)
+		}
+	}
+
+	seen := make(map[state.NodePortKey]bool, len(ports))
+	for i := range ports {
+		if ports[i].NodePort == 0 {
+			ports[i].NodePort = held[servicePortKey(ports[i])]
+		}
+
+		nodePort, err := s.endpointController.AllocateNodePort(serviceID, ports[i].Protocol, ports[i].NodePort)
+		if err != nil {
+			release()
+			switch {
+			case errors.Is(err, state.ErrNodePortAllocated):
+				return http.StatusConflict, fmt.Errorf(
+					"node port %d/%s is already allocated", ports[i].NodePort, ports[i].Protocol,
+				)
+			case errors.Is(err, services.ErrNodePortOutOfRange):
+				return http.StatusBadRequest, err
+			default:
+				return http.StatusInternalServerError, fmt.Errorf("failed to allocate node port: %w", err)
+			}
+		}
+		key := state.NodePortKey{Port: nodePort, Protocol: ports[i].Protocol}
+		if !heldPorts[key] {
+			reserved = append(reserved, key)
+		}
+
+		if seen[key] {
+			release()
+			return http.StatusBadRequest, fmt.Errorf(
+				"node port %d is used by more than one %s port", nodePort, ports[i].Protocol,
+			)
+		}
+		seen[key] = true
+		ports[i].NodePort = nodePort
+	}
+	return http.StatusOK, nil
+}
+
+// releaseUnusedNodePorts frees the node ports of previous that ports no longer use
+func (s *Server) releaseUnusedNodePorts(previous, ports []types.ServicePort) {
+	used := make(map[state.NodePortKey]bool, len(ports))
+	for _, port := range ports {
+		used[state.NodePortKey{Port: port.NodePort, Protocol: port.Protocol}] = true
+	}
+	for _, port := range previous {
+		if port.NodePort != 0 && !used[state.NodePortKey{Port: port.NodePort, Protocol: port.Protocol}] {
+			_ = s.endpointController.ReleaseNodePort(port.NodePort, port.Protocol)
+		}
+	}
+}
+
+// servicePortKey matches a service port across updates: by name when named, otherwise by number
+func servicePortKey(port types.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	return fmt.Sprintf("%s/%d", port.Protocol, port.Port)
+}
+
 // GetEndpoints handles GET /api/v1/services/:id/endpoints
 // Returns the endpoints for a specific service
 func (s *Server) GetEndpoints(c echo.Context) error {
@@ -203,3 +527,21 @@ func (s *Server) GetEndpoints(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, endpoints)
 }
+
+// ListEndpoints handles GET /api/v1/endpoints
+// Returns the endpoints of every service, optionally filtered by namespace
+func (s *Server) ListEndpoints(c echo.Context) error {
+	services, err := s.store.ListServices(c.QueryParam("namespace"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	endpoints := make([]types.Endpoints, 0, len(services))
+	for _, service := range services {
+		if ep, err := s.store.GetEndpoints(service.ServiceID); err == nil {
+			endpoints = append(endpoints, ep)
+		}
+	}
+
+	return c.JSON(http.StatusOK, endpoints)
+}
@@ -598,3 +598,469 @@ func TestGetEndpoints(t *testing.T) {
 		},
 	)
 }
+
+func TestListEndpoints(t *testing.T) {
+	e := echo.New()
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+
+	for _, svc := range []types.Service{
+		{ServiceID: "svc-1", Name: "web", Namespace: "default", Ports: []types.ServicePort{{Port: 80}}},
+		{ServiceID: "svc-2", Name: "db", Namespace: "data", Ports: []types.ServicePort{{Port: 5432}}},
+		{ServiceID: "svc-3", Name: "idle", Namespace: "default", Ports: []types.ServicePort{{Port: 81}}},
+	} {
+		_ = store.AddService(svc)
+	}
+	_ = store.SetEndpoints(types.Endpoints{ServiceID: "svc-1", ServiceName: "web", Namespace: "default"})
+	_ = store.SetEndpoints(types.Endpoints{ServiceID: "svc-2", ServiceName: "db", Namespace: "data"})
+
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{name: "all namespaces", query: "", want: 2},
+		{name: "one namespace", query: "?namespace=data", want: 1},
+		{name: "empty namespace", query: "?namespace=other", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/endpoints"+tt.query, nil)
+				rec := httptest.NewRecorder()
+
+				if err := server.ListEndpoints(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("ListEndpoints failed: %v", err)
+				}
+				if rec.Code != http.StatusOK {
+					t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+				}
+
+				var endpoints []types.Endpoints
+				if err := json.Unmarshal(rec.Body.Bytes(), &endpoints); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if len(endpoints) != tt.want {
+					t.Errorf("expected %d endpoints, got %d", tt.want, len(endpoints))
+				}
+			},
+		)
+	}
+}
+
+func TestNodePortServices(t *testing.T) {
+	e := echo.New()
+	store := state.NewInMemoryStore()
+	endpointController := services.NewEndpointController(store)
+	endpointController.SetNodePortRange(services.PortRange{Min: 30000, Max: 30002})
+	server := NewServer(store, scheduler.NewRoundRobin(), endpointController)
+
+	create := func(payload map[string]interface{}) (*httptest.ResponseRecorder, types.Service) {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/services", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := server.CreateService(e.NewContext(req, rec)); err != nil {
+			t.Fatalf("CreateService failed: %v", err)
+		}
+		var service types.Service
+		_ = json.Unmarshal(rec.Body.Bytes(), &service)
+		return rec, service
+	}
+	nodePortService := func(name string, nodePort int) map[string]interface{} {
+		return map[string]interface{}{
+			"name":     name,
+			"type":     "NodePort",
+			"selector": map[string]string{"app": name},
+			"ports":    []map[string]interface{}{{"name": "http", "port": 80, "nodePort": nodePort}},
+		}
+	}
+
+	rec, web := create(nodePortService("web", 0))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	allocated := web.Ports[0].NodePort
+	if allocated < 30000 || allocated > 30002 || web.ClusterIP == "" {
+		t.Errorf("expected a ClusterIP and a node port in range, got %q and %d", web.ClusterIP, allocated)
+	}
+
+	tests := []struct {
+		name     string
+		payload  map[string]interface{}
+		wantCode int
+	}{
+		{name: "requested port held by another service", payload: nodePortService("api", allocated), wantCode: 409},
+		{name: "requested port out of range", payload: nodePortService("api", 31000), wantCode: 400},
+		{
+			name: "node port on a ClusterIP service",
+			payload: map[string]interface{}{
+				"name":     "api",
+				"selector": map[string]string{"app": "api"},
+				"ports":    []map[string]interface{}{{"port": 80, "nodePort": 30001}},
+			},
+			wantCode: 400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if rec, _ := create(tt.payload); rec.Code != tt.wantCode {
+					t.Errorf("expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+				}
+			},
+		)
+	}
+
+	// Updating ports keeps the node port of a port that is still there
+	body, _ := json.Marshal(
+		map[string]interface{}{
+			"ports": []map[string]interface{}{{"name": "http", "port": 8080}, {"name": "admin", "port": 9090}},
+		},
+	)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/services/"+web.ServiceID, bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(web.ServiceID)
+	if err := server.UpdateService(c); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	var updated types.Service
+	_ = json.Unmarshal(rec.Body.Bytes(), &updated)
+	if rec.Code != http.StatusOK || updated.Ports[0].NodePort != allocated || updated.Ports[1].NodePort == 0 {
+		t.Errorf("expected http to keep node port %d and admin to get one, got %+v", allocated, updated.Ports)
+	}
+
+	// Deleting the service frees its node ports for others
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/services/"+web.ServiceID, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(web.ServiceID)
+	if err := server.DeleteService(c); err != nil {
+		t.Fatalf("DeleteService failed: %v", err)
+	}
+	if ports, _ := store.ListNodePorts(); len(ports) != 0 {
+		t.Errorf("expected node ports to be released, got %v", ports)
+	}
+	if rec, _ := create(nodePortService("api", allocated)); rec.Code != http.StatusCreated {
+		t.Errorf("expected released node port to be reusable, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// TCP and UDP node ports are reserved separately
+	dns := nodePortService("dns", allocated)
+	dns["ports"] = []map[string]interface{}{{"port": 53, "protocol": "UDP", "nodePort": allocated}}
+	if rec, _ := create(dns); rec.Code != http.StatusCreated {
+		t.Errorf("expected a UDP port to share node port %d with TCP, got %d: %s", allocated, rec.Code, rec.Body.String())
+	}
+}
+
+func TestServiceRoutingValidation(t *testing.T) {
+	e := echo.New()
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+
+	service := func(settings map[string]interface{}) map[string]interface{} {
+		payload := map[string]interface{}{
+			"name":     "web",
+			"selector": map[string]string{"app": "web"},
+			"ports":    []map[string]interface{}{{"port": 80}},
+		}
+		for k, v := range settings {
+			payload[k] = v
+		}
+		return payload
+	}
+
+	tests := []struct {
+		name     string
+		payload  map[string]interface{}
+		wantCode int
+	}{
+		{
+			name: "client IP affinity with timeout and weighted balancing",
+			payload: service(
+				map[string]interface{}{
+					"sessionAffinity": "ClientIP", "sessionAffinityTimeoutSeconds": 60, "loadBalancing": "Weighted",
+				},
+			),
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "unknown session affinity",
+			payload:  service(map[string]interface{}{"sessionAffinity": "Cookie"}),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "timeout too long",
+			payload: service(
+				map[string]interface{}{"sessionAffinity": "ClientIP", "sessionAffinityTimeoutSeconds": 86401},
+			),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown algorithm",
+			payload:  service(map[string]interface{}{"loadBalancing": "Fastest"}),
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				body, _ := json.Marshal(tt.payload)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/services", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				if err := server.CreateService(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("CreateService failed: %v", err)
+				}
+				if rec.Code != tt.wantCode {
+					t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+				}
+				if tt.wantCode != http.StatusCreated {
+					return
+				}
+				var created types.Service
+				_ = json.Unmarshal(rec.Body.Bytes(), &created)
+				if created.SessionAffinityTimeoutSeconds != 60 || created.LoadBalancing != types.LoadBalancingWeighted {
+					t.Errorf("expected routing settings to be stored, got %+v", created)
+				}
+			},
+		)
+	}
+}
+
+func TestServicePortValidation(t *testing.T) {
+	e := echo.New()
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+
+	tests := []struct {
+		name     string
+		ports    []map[string]interface{}
+		wantCode int
+	}{
+		{
+			name:     "named target port",
+			ports:    []map[string]interface{}{{"port": 80, "targetPortName": "http"}},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name: "named ports with named target ports",
+			ports: []map[string]interface{}{
+				{"name": "http", "port": 80, "targetPortName": "http"},
+				{"name": "metrics", "port": 9090},
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "target port number and name",
+			ports:    []map[string]interface{}{{"port": 80, "targetPort": 8080, "targetPortName": "http"}},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "unnamed port next to a named target port",
+			ports: []map[string]interface{}{
+				{"name": "http", "port": 80, "targetPortName": "http"},
+				{"port": 9090},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				payload := map[string]interface{}{
+					"name":     "web",
+					"selector": map[string]string{"app": "web"},
+					"ports":    tt.ports,
+				}
+				body, _ := json.Marshal(payload)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/services", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				if err := server.CreateService(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("CreateService failed: %v", err)
+				}
+				if rec.Code != tt.wantCode {
+					t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+				}
+				if tt.wantCode != http.StatusCreated {
+					return
+				}
+
+				var created types.Service
+				_ = json.Unmarshal(rec.Body.Bytes(), &created)
+				if port := created.Ports[0]; port.TargetPortName == "http" && port.TargetPort != 0 {
+					t.Errorf("expected no default target port number with a named target port, got %d", port.TargetPort)
+				}
+			},
+		)
+	}
+}
+
+func TestHeadlessAndExternalNameServices(t *testing.T) {
+	e := echo.New()
+	store := state.NewInMemoryStore()
+	server := NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store))
+
+	selector := map[string]string{"app": "db"}
+	ports := []map[string]interface{}{{"name": "pg", "port": 5432}}
+
+	tests := []struct {
+		name          string
+		payload       map[string]interface{}
+		wantCode      int
+		wantClusterIP string
+	}{
+		{
+			name:          "headless service",
+			payload:       map[string]interface{}{"clusterIp": "None", "selector": selector, "ports": ports},
+			wantCode:      http.StatusCreated,
+			wantClusterIP: types.ClusterIPNone,
+		},
+		{
+			name:          "headless service without ports",
+			payload:       map[string]interface{}{"clusterIp": "None", "selector": selector},
+			wantCode:      http.StatusCreated,
+			wantClusterIP: types.ClusterIPNone,
+		},
+		{
+			name:     "specific cluster IP",
+			payload:  map[string]interface{}{"clusterIp": "10.96.0.50", "selector": selector, "ports": ports},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "headless NodePort service",
+			payload: map[string]interface{}{
+				"type": "NodePort", "clusterIp": "None", "selector": selector, "ports": ports,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "headless service with session affinity",
+			payload: map[string]interface{}{
+				"clusterIp": "None", "selector": selector, "ports": ports, "sessionAffinity": "ClientIP",
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "external name",
+			payload:  map[string]interface{}{"type": "ExternalName", "externalName": "DB.Example.com."},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "external name without a name",
+			payload:  map[string]interface{}{"type": "ExternalName"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "external name that is an IP address",
+			payload:  map[string]interface{}{"type": "ExternalName", "externalName": "192.168.1.10"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "external name with an invalid label",
+			payload:  map[string]interface{}{"type": "ExternalName", "externalName": "db_primary.example.com"},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "external name with a selector",
+			payload: map[string]interface{}{
+				"type": "ExternalName", "externalName": "db.example.com", "selector": selector,
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "external name with a cluster IP",
+			payload: map[string]interface{}{
+				"type": "ExternalName", "externalName": "db.example.com", "clusterIp": "None",
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "external name on a ClusterIP service",
+			payload:  map[string]interface{}{"externalName": "db.example.com", "selector": selector, "ports": ports},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				tt.payload["name"] = "db"
+				body, _ := json.Marshal(tt.payload)
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/services", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				if err := server.CreateService(e.NewContext(req, rec)); err != nil {
+					t.Fatalf("CreateService failed: %v", err)
+				}
+				if rec.Code != tt.wantCode {
+					t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rec.Code, rec.Body.String())
+				}
+				if tt.wantCode != http.StatusCreated {
+					return
+				}
+
+				var created types.Service
+				_ = json.Unmarshal(rec.Body.Bytes(), &created)
+				if created.ClusterIP != tt.wantClusterIP {
+					t.Errorf("expected cluster IP %q, got %q", tt.wantClusterIP, created.ClusterIP)
+				}
+				if created.IsExternalName() && created.ExternalName != "db.example.com" {
+					t.Errorf("expected a normalized external name, got %q", created.ExternalName)
+				}
+			},
+		)
+	}
+
+	t.Run(
+		"update external name", func(t *testing.T) {
+			service := types.Service{
+				ServiceID: "svc-ext", Name: "billing", Namespace: "default",
+				Type: types.ServiceTypeExternalName, ExternalName: "billing.example.com",
+			}
+			if err := store.AddService(service); err != nil {
+				t.Fatalf("failed to add service: %v", err)
+			}
+
+			update := func(payload map[string]interface{}) *httptest.ResponseRecorder {
+				body, _ := json.Marshal(payload)
+				req := httptest.NewRequest(http.MethodPut, "/api/v1/services/svc-ext", bytes.NewReader(body))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := e.NewContext(req, rec)
+				c.SetParamNames("id")
+				c.SetParamValues("svc-ext")
+				if err := server.UpdateService(c); err != nil {
+					t.Fatalf("UpdateService failed: %v", err)
+				}
+				return rec
+			}
+
+			if rec := update(map[string]interface{}{"externalName": "billing.internal.example.com"}); rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got, _ := store.GetService("svc-ext"); got.ExternalName != "billing.internal.example.com" {
+				t.Errorf("expected the external name to be updated, got %q", got.ExternalName)
+			}
+
+			for _, payload := range []map[string]interface{}{
+				{"externalName": ""},
+				{"externalName": "-billing.example.com"},
+				{"selector": map[string]string{"app": "billing"}},
+				{"loadBalancing": "Random"},
+			} {
+				if rec := update(payload); rec.Code != http.StatusBadRequest {
+					t.Errorf("expected status 400 for %v, got %d", payload, rec.Code)
+				}
+			}
+		},
+	)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+// ExecPod handles POST /api/v1/pods/:id/exec
+// Proxies an exec stream to the worker running the pod, so clients do not need direct worker access
+func (s *Server) ExecPod(c echo.Context) error {
+	return s.proxyPodStream(c, "exec")
+}
+
+// PortForwardPod handles POST /api/v1/pods/:id/portforward
+// Proxies a TCP stream to a port on the pod's network IP through the pod's worker
+func (s *Server) PortForwardPod(c echo.Context) error {
+	return s.proxyPodStream(c, "portforward")
+}
+
+// proxyPodStream opens a stream to the pod's worker endpoint and joins it with the client's stream.
+// Errors from the worker are passed through before the client connection is upgraded.
+func (s *Server) proxyPodStream(c echo.Context, endpoint string) error {
+	if !stream.IsUpgrade(c.Request()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": endpoint + " requires a stream upgrade"})
+	}
+
+	pod, err := s.store.GetPod(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "pod not found"})
+	}
+	if pod.Status != types.PodRunning || pod.NodeID == "" {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "pod is not running"})
+	}
+
+	node, err := s.store.GetNode(pod.NodeID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "node not found"})
+	}
+
+	url := fmt.Sprintf("http://%s:%d/api/v1/pods/%s/%s", node.Hostname, node.Port, pod.PodID, endpoint)
+	if query := c.QueryString(); query != "" {
+		url += "?" + query
+	}
+
+	workerConn, err := stream.Dial(c.Request().Context(), http.MethodPost, url)
+	if err != nil {
+		var statusErr *stream.StatusError
+		if errors.As(err, &statusErr) {
+			return c.JSON(statusErr.StatusCode, map[string]string{"error": statusErr.Message})
+		}
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
+	clientConn, err := stream.Upgrade(c.Response(), c.Request())
+	if err != nil {
+		_ = workerConn.Close()
+		log.Printf("%s in pod %s: %v", endpoint, pod.PodID, err)
+		return nil
+	}
+
+	stream.Join(clientConn, workerConn)
+	return nil
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/master/scheduler"
+	"github.com/danpasecinic/podling/internal/master/services"
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/labstack/echo/v4"
+)
+
+func TestExecPodProxy(t *testing.T) {
+	var gotQuery string
+	worker := http.NewServeMux()
+	worker.HandleFunc(
+		"POST /api/v1/pods/{id}/exec", func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("id") != "pod-1" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"pod pod-2 not found or not running"}`))
+				return
+			}
+			gotQuery = r.URL.RawQuery
+
+			conn, err := stream.Upgrade(w, r)
+			if err != nil {
+				t.Errorf("Upgrade() error = %v", err)
+				return
+			}
+			defer func() { _ = conn.Close() }()
+
+			// Echo stdin until it is closed, then report success
+			frames := stream.NewFrameWriter(conn)
+			for {
+				channel, payload, err := stream.ReadFrame(conn)
+				if err != nil || (channel == stream.ChannelStdin && len(payload) == 0) {
+					break
+				}
+				_ = frames.WriteFrame(stream.ChannelStdout, payload)
+			}
+			status, _ := json.Marshal(stream.ExecStatus{ExitCode: 0})
+			_ = frames.WriteFrame(stream.ChannelStatus, status)
+		},
+	)
+
+	store := state.NewInMemoryStore()
+	if err := store.AddNode(newWorkerNode(t, "node-1", worker)); err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range []types.Pod{
+		{PodID: "pod-1", NodeID: "node-1", Status: types.PodRunning},
+		{PodID: "pod-2", NodeID: "node-1", Status: types.PodRunning},
+		{PodID: "pod-3", Status: types.PodPending},
+	} {
+		if err := store.AddPod(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := echo.New()
+	NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store)).RegisterRoutes(e)
+	master := httptest.NewServer(e)
+	defer master.Close()
+
+	ctx := context.Background()
+	conn, err := stream.Dial(ctx, http.MethodPost, master.URL+"/api/v1/pods/pod-1/exec?command=cat&stdin=true")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	frames := stream.NewFrameWriter(conn)
+	_ = frames.WriteFrame(stream.ChannelStdin, []byte("hello"))
+	_ = frames.WriteFrame(stream.ChannelStdin, nil)
+
+	channel, payload, err := stream.ReadFrame(conn)
+	if err != nil || channel != stream.ChannelStdout || string(payload) != "hello" {
+		t.Errorf("first frame = %d %q %v, want stdout hello", channel, payload, err)
+	}
+	if channel, _, err := stream.ReadFrame(conn); err != nil || channel != stream.ChannelStatus {
+		t.Errorf("second frame = %d %v, want the exec status", channel, err)
+	}
+	if gotQuery != "command=cat&stdin=true" {
+		t.Errorf("worker query = %q, want the client's query", gotQuery)
+	}
+
+	tests := []struct {
+		name       string
+		podID      string
+		wantStatus int
+	}{
+		{name: "worker error is passed through", podID: "pod-2", wantStatus: http.StatusNotFound},
+		{name: "pod not running", podID: "pod-3", wantStatus: http.StatusConflict},
+		{name: "unknown pod", podID: "pod-4", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := stream.Dial(ctx, http.MethodPost, master.URL+"/api/v1/pods/"+tt.podID+"/exec?command=sh")
+				var statusErr *stream.StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Errorf("Dial() error = %v, want status %d", err, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestPortForwardPodProxy(t *testing.T) {
+	worker := http.NewServeMux()
+	worker.HandleFunc(
+		"POST /api/v1/pods/{id}/portforward", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("port") != "8080" {
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = w.Write([]byte(`{"error":"failed to connect to pod port"}`))
+				return
+			}
+			conn, err := stream.Upgrade(w, r)
+			if err != nil {
+				t.Errorf("Upgrade() error = %v", err)
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			_, _ = io.Copy(conn, conn)
+		},
+	)
+
+	store := state.NewInMemoryStore()
+	if err := store.AddNode(newWorkerNode(t, "node-1", worker)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddPod(types.Pod{PodID: "pod-1", NodeID: "node-1", Status: types.PodRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := echo.New()
+	NewServer(store, scheduler.NewRoundRobin(), services.NewEndpointController(store)).RegisterRoutes(e)
+	master := httptest.NewServer(e)
+	defer master.Close()
+
+	ctx := context.Background()
+	conn, err := stream.Dial(ctx, http.MethodPost, master.URL+"/api/v1/pods/pod-1/portforward?port=8080")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Errorf("reply = %q, %v, want ping", reply, err)
+	}
+
+	_, err = stream.Dial(ctx, http.MethodPost, master.URL+"/api/v1/pods/pod-1/portforward?port=9090")
+	var statusErr *stream.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Dial() error = %v, want status %d", err, http.StatusBadGateway)
+	}
+}
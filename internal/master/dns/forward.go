@@ -0,0 +1,126 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// SystemUpstreams returns the nameservers listed in a resolv.conf file as host:port addresses
+func SystemUpstreams(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var upstreams []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if ip := net.ParseIP(fields[1]); ip != nil {
+			upstreams = append(upstreams, net.JoinHostPort(ip.String(), "53"))
+		}
+	}
+	return upstreams
+}
+
+// forward sends a query to the upstream resolvers in order and returns the first response
+func (s *Server) forward(ctx context.Context, query []byte, udp bool) ([]byte, error) {
+	if len(s.config.Upstreams) == 0 {
+		return nil, errors.New("no upstream resolvers")
+	}
+
+	var errs []error
+	for _, upstream := range s.config.Upstreams {
+		resp, err := s.exchange(ctx, upstream, query, udp)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", upstream, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// lookupUpstream asks the upstream resolvers for the records of name, returning none when
+// there are no upstreams or they fail
+func (s *Server) lookupUpstream(
+	ctx context.Context, name dnsmessage.Name, qtype dnsmessage.Type, udp bool,
+) []dnsmessage.Resource {
+	if len(s.config.Upstreams) == 0 {
+		return nil
+	}
+
+	id := uint16(rand.Uint32())
+	query, err := (&dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+	}).Pack()
+	if err != nil {
+		return nil
+	}
+
+	answer, err := s.forward(ctx, query, udp)
+	if err != nil {
+		log.Printf("dns: failed to forward %s: %v", name, err)
+		return nil
+	}
+	var resp dnsmessage.Message
+	if err := resp.Unpack(answer); err != nil || resp.Header.ID != id || resp.Header.RCode != dnsmessage.RCodeSuccess {
+		return nil
+	}
+	return resp.Answers
+}
+
+// exchange sends one query to an upstream over the transport the client used
+func (s *Server) exchange(ctx context.Context, upstream string, query []byte, udp bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.ForwardTimeout)
+	defer cancel()
+
+	network := "tcp"
+	if udp {
+		network = "udp"
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, upstream)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	if !udp {
+		if err := writeTCPMessage(conn, query); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxUDPSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Ignore stray datagrams that do not answer this query
+		if n >= 2 && buf[0] == query[0] && buf[1] == query[1] {
+			return append([]byte(nil), buf[:n]...), nil
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package dns
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// podIPAnnotation is where workers publish a running pod's IP
+const podIPAnnotation = "podling.io/pod-ip"
+
+// result is the outcome of resolving a name in the cluster domain
+type result struct {
+	// exists is false for names that do not exist (NXDOMAIN)
+	exists      bool
+	answers     []dnsmessage.Resource
+	additionals []dnsmessage.Resource
+}
+
+// resolve answers a query for a name relative to the cluster domain, such as "web.default.svc."
+func (s *Server) resolve(relative string, qtype dnsmessage.Type) (result, error) {
+	relative = strings.TrimSuffix(relative, ".")
+	if relative == "" {
+		res := result{exists: true}
+		if qtype == dnsmessage.TypeSOA || qtype == dnsmessage.TypeALL {
+			res.answers = []dnsmessage.Resource{s.soa()}
+		}
+		return res, nil
+	}
+
+	labels := strings.Split(relative, ".")
+	zone := labels[len(labels)-1]
+	labels = labels[:len(labels)-1]
+
+	switch {
+	case zone != "svc" && zone != "pod":
+		return result{}, nil
+	case len(labels) <= 1:
+		// The zone itself and its namespaces exist but hold no records
+		return result{exists: true}, nil
+	case zone == "pod" && len(labels) == 2:
+		return s.resolvePod(labels[0], labels[1], qtype)
+	case zone == "svc" && len(labels) == 2:
+		return s.resolveService(labels[0], labels[1], qtype)
+	case zone == "svc" && len(labels) == 3:
+		return s.resolveEndpoint(labels[0], labels[1], labels[2], qtype)
+	case zone == "svc" && len(labels) == 4 && strings.HasPrefix(labels[0], "_") && strings.HasPrefix(labels[1], "_"):
+		return s.resolveSRV(labels[0][1:], labels[1][1:], labels[2], labels[3], qtype)
+	}
+	return result{}, nil
+}
+
+// resolveService answers <service>.<namespace>.svc with the ClusterIP, with the ready endpoint
+// addresses of a headless service, or with a CNAME to the external name of an ExternalName service
+func (s *Server) resolveService(name, namespace string, qtype dnsmessage.Type) (result, error) {
+	service, found, err := s.lookupService(namespace, name)
+	if err != nil || !found {
+		return result{}, err
+	}
+
+	fqdn := serviceName(service.Name, namespace, s.domain)
+	if service.IsExternalName() {
+		// A CNAME answers queries of every type for its name
+		return result{exists: true, answers: s.cnameRecords(fqdn, service.ExternalName)}, nil
+	}
+	if !service.IsHeadless() {
+		return result{exists: true, answers: s.addressRecords(fqdn, []string{service.ClusterIP}, qtype)}, nil
+	}
+
+	endpoints, err := s.lookupEndpoints(namespace, name)
+	if err != nil {
+		return result{}, err
+	}
+	return result{exists: true, answers: s.addressRecords(fqdn, endpoints.GetAllIPs(), qtype)}, nil
+}
+
+// resolveEndpoint answers <dashed-ip>.<service>.<namespace>.svc for a ready endpoint of a headless service
+func (s *Server) resolveEndpoint(host, name, namespace string, qtype dnsmessage.Type) (result, error) {
+	service, found, err := s.lookupService(namespace, name)
+	if err != nil || !found || !service.IsHeadless() {
+		return result{}, err
+	}
+
+	endpoints, err := s.lookupEndpoints(namespace, name)
+	if err != nil {
+		return result{}, err
+	}
+	for _, ip := range endpoints.GetAllIPs() {
+		if dashedIP(ip) == host {
+			fqdn := host + "." + serviceName(service.Name, namespace, s.domain)
+			return result{exists: true, answers: s.addressRecords(fqdn, []string{ip}, qtype)}, nil
+		}
+	}
+	return result{}, nil
+}
+
+// resolveSRV answers _<port>._<protocol>.<service>.<namespace>.svc for a named service port.
+// A service with a ClusterIP has one record targeting the service name; a headless service has
+// one record per ready endpoint, targeting the endpoint's name. ExternalName services have none.
+func (s *Server) resolveSRV(portName, protocol, name, namespace string, qtype dnsmessage.Type) (result, error) {
+	service, found, err := s.lookupService(namespace, name)
+	if err != nil || !found || service.IsExternalName() {
+		return result{}, err
+	}
+
+	var port *types.ServicePort
+	var index int
+	for i := range service.Ports {
+		p := &service.Ports[i]
+		proto := strings.ToLower(p.Protocol)
+		if proto == "" {
+			proto = "tcp"
+		}
+		if p.Name != "" && strings.ToLower(p.Name) == portName && proto == protocol {
+			port, index = p, i
+			break
+		}
+	}
+	if port == nil {
+		return result{}, nil
+	}
+
+	res := result{exists: true}
+	if qtype != dnsmessage.TypeSRV && qtype != dnsmessage.TypeALL {
+		return res, nil
+	}
+
+	srvName := "_" + portName + "._" + protocol + "." + serviceName(service.Name, namespace, s.domain)
+	if !service.IsHeadless() {
+		target := serviceName(service.Name, namespace, s.domain)
+		res.answers = s.srvRecords(srvName, target, port.Port)
+		res.additionals = s.addressRecords(target, []string{service.ClusterIP}, dnsmessage.TypeALL)
+		return res, nil
+	}
+
+	endpoints, err := s.lookupEndpoints(namespace, name)
+	if err != nil {
+		return result{}, err
+	}
+	for _, subset := range endpoints.Subsets {
+		targetPort, ok := subset.TargetPort(index, *port)
+		if !ok {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			target := dashedIP(addr.IP) + "." + serviceName(service.Name, namespace, s.domain)
+			res.answers = append(res.answers, s.srvRecords(srvName, target, targetPort)...)
+			res.additionals = append(
+				res.additionals, s.addressRecords(target, []string{addr.IP}, dnsmessage.TypeALL)...,
+			)
+		}
+	}
+	return res, nil
+}
+
+// resolvePod answers <dashed-ip>.<namespace>.pod for a pod in the namespace with that IP
+func (s *Server) resolvePod(host, namespace string, qtype dnsmessage.Type) (result, error) {
+	pods, err := s.store.ListPodsByLabels(namespace, map[string]string{})
+	if err != nil {
+		return result{}, err
+	}
+	for _, pod := range pods {
+		ip := pod.Annotations[podIPAnnotation]
+		if ip != "" && dashedIP(ip) == host {
+			fqdn := host + "." + namespace + ".pod." + s.domain
+			return result{exists: true, answers: s.addressRecords(fqdn, []string{ip}, qtype)}, nil
+		}
+	}
+	return result{}, nil
+}
+
+// lookupService returns a service by name, reporting whether it exists
+func (s *Server) lookupService(namespace, name string) (types.Service, bool, error) {
+	service, err := s.store.GetServiceByName(namespace, name)
+	if errors.Is(err, state.ErrServiceNotFound) {
+		return types.Service{}, false, nil
+	}
+	if err != nil {
+		return types.Service{}, false, err
+	}
+	return service, true, nil
+}
+
+// lookupEndpoints returns a service's endpoints; a service without endpoints has none ready
+func (s *Server) lookupEndpoints(namespace, name string) (types.Endpoints, error) {
+	endpoints, err := s.store.GetEndpointsByServiceName(namespace, name)
+	if errors.Is(err, state.ErrEndpointsNotFound) {
+		return types.Endpoints{}, nil
+	}
+	return endpoints, err
+}
+
+// addressRecords returns A records for the IPv4 and AAAA records for the IPv6 addresses
+// that match the query type
+func (s *Server) addressRecords(name string, ips []string, qtype dnsmessage.Type) []dnsmessage.Resource {
+	var records []dnsmessage.Resource
+	for _, value := range ips {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			continue
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			if qtype != dnsmessage.TypeA && qtype != dnsmessage.TypeALL {
+				continue
+			}
+			header, ok := s.header(name, dnsmessage.TypeA)
+			if !ok {
+				continue
+			}
+			body := &dnsmessage.AResource{}
+			copy(body.A[:], ip4)
+			records = append(records, dnsmessage.Resource{Header: header, Body: body})
+			continue
+		}
+		if qtype != dnsmessage.TypeAAAA && qtype != dnsmessage.TypeALL {
+			continue
+		}
+		header, ok := s.header(name, dnsmessage.TypeAAAA)
+		if !ok {
+			continue
+		}
+		body := &dnsmessage.AAAAResource{}
+		copy(body.AAAA[:], ip.To16())
+		records = append(records, dnsmessage.Resource{Header: header, Body: body})
+	}
+	return records
+}
+
+// srvRecords returns the SRV record pointing name at target:port
+func (s *Server) srvRecords(name, target string, port int) []dnsmessage.Resource {
+	header, ok := s.header(name, dnsmessage.TypeSRV)
+	targetName, err := dnsmessage.NewName(target)
+	if !ok || err != nil || port <= 0 || port > 65535 {
+		return nil
+	}
+	body := &dnsmessage.SRVResource{Priority: 0, Weight: 100, Port: uint16(port), Target: targetName}
+	return []dnsmessage.Resource{{Header: header, Body: body}}
+}
+
+// cnameRecords returns the CNAME record aliasing name to target
+func (s *Server) cnameRecords(name, target string) []dnsmessage.Resource {
+	header, ok := s.header(name, dnsmessage.TypeCNAME)
+	targetName, err := dnsmessage.NewName(strings.TrimSuffix(target, ".") + ".")
+	if !ok || err != nil {
+		return nil
+	}
+	return []dnsmessage.Resource{{Header: header, Body: &dnsmessage.CNAMEResource{CNAME: targetName}}}
+}
+
+// soa returns the start of authority record of the cluster domain, used for negative caching
+func (s *Server) soa() dnsmessage.Resource {
+	header, _ := s.header(s.domain, dnsmessage.TypeSOA)
+	return dnsmessage.Resource{
+		Header: header,
+		Body: &dnsmessage.SOAResource{
+			NS:      dnsmessage.MustNewName("ns.dns." + s.domain),
+			MBox:    dnsmessage.MustNewName("hostmaster." + s.domain),
+			Serial:  1,
+			Refresh: 7200,
+			Retry:   1800,
+			Expire:  86400,
+			MinTTL:  s.config.TTL,
+		},
+	}
+}
+
+// header returns the header of a cluster record, or false if the name is not a valid DNS name
+func (s *Server) header(name string, rtype dnsmessage.Type) (dnsmessage.ResourceHeader, bool) {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return dnsmessage.ResourceHeader{}, false
+	}
+	return dnsmessage.ResourceHeader{Name: n, Type: rtype, Class: dnsmessage.ClassINET, TTL: s.config.TTL}, true
+}
+
+// serviceName returns the fully qualified name of a service, with a trailing dot
+func serviceName(name, namespace, domain string) string {
+	return strings.ToLower(name) + "." + namespace + ".svc." + domain
+}
+
+// dashedIP writes an IP as a single DNS label, such as 172-18-0-2
+func dashedIP(ip string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(ip)
+}
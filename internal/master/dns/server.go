@@ -0,0 +1,348 @@
+// Package dns serves cluster DNS from the master's state store. Services resolve as
+// <service>.<namespace>.svc.<domain>, headless services to their ready endpoints, ExternalName
+// services as CNAMEs, named service ports as SRV records and pods as
+// <dashed-ip>.<namespace>.pod.<domain>. Other names are forwarded to upstream resolvers, so
+// pods can use the server as their only nameserver.
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// minUDPSize is the largest UDP response a client without EDNS0 accepts
+	minUDPSize = 512
+
+	// maxUDPSize caps the UDP response size advertised and accepted through EDNS0
+	maxUDPSize = 4096
+
+	// tcpIdleTimeout is how long a TCP client connection is kept open between queries
+	tcpIdleTimeout = 10 * time.Second
+
+	// maxCNAMEChain bounds how many aliases are followed for one query
+	maxCNAMEChain = 8
+)
+
+// Config controls where the DNS server listens and what it answers
+type Config struct {
+	// Addr is the UDP and TCP address the server listens on
+	Addr string
+
+	// Domain is the cluster domain, such as cluster.local
+	Domain string
+
+	// TTL is the time to live of cluster records, in seconds
+	TTL uint32
+
+	// Upstreams are the host:port resolvers names outside the cluster domain are forwarded to
+	Upstreams []string
+
+	// ForwardTimeout bounds each query to an upstream resolver
+	ForwardTimeout time.Duration
+}
+
+// DefaultConfig returns the default server settings, forwarding to the node's resolvers
+func DefaultConfig() Config {
+	return Config{
+		Addr:           ":53",
+		Domain:         "cluster.local",
+		TTL:            5,
+		Upstreams:      SystemUpstreams("/etc/resolv.conf"),
+		ForwardTimeout: 2 * time.Second,
+	}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.Addr == "" {
+		return errors.New("listen address is required")
+	}
+	domain := strings.Trim(c.Domain, ".")
+	if domain == "" {
+		return errors.New("cluster domain is required")
+	}
+	if _, err := dnsmessage.NewName(domain + "."); err != nil {
+		return fmt.Errorf("invalid cluster domain %q: %w", c.Domain, err)
+	}
+	for _, upstream := range c.Upstreams {
+		if _, _, err := net.SplitHostPort(upstream); err != nil {
+			return fmt.Errorf("invalid upstream %q: %w", upstream, err)
+		}
+	}
+	if len(c.Upstreams) > 0 && c.ForwardTimeout <= 0 {
+		return fmt.Errorf("forward timeout must be positive, got %v", c.ForwardTimeout)
+	}
+	return nil
+}
+
+// Server answers DNS queries over UDP and TCP
+type Server struct {
+	store  state.StateStore
+	config Config
+	domain string // lower-case, with a trailing dot
+}
+
+// NewServer creates a DNS server backed by store
+func NewServer(store state.StateStore, config Config) *Server {
+	return &Server{
+		store:  store,
+		config: config,
+		domain: strings.ToLower(strings.Trim(config.Domain, ".")) + ".",
+	}
+}
+
+// ListenAndServe listens on the configured address and serves until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on udp %s: %w", s.config.Addr, err)
+	}
+	ln, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to listen on tcp %s: %w", s.config.Addr, err)
+	}
+	s.Serve(ctx, conn, ln)
+	return nil
+}
+
+// Serve answers queries on conn and ln until ctx is cancelled, then closes both
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn, ln net.Listener) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.serveUDP(ctx, conn)
+	}()
+	go func() {
+		defer wg.Done()
+		s.serveTCP(ctx, ln)
+	}()
+
+	<-ctx.Done()
+	_ = conn.Close()
+	_ = ln.Close()
+	wg.Wait()
+}
+
+// serveUDP answers each datagram in its own goroutine
+func (s *Server) serveUDP(ctx context.Context, conn net.PacketConn) {
+	buf := make([]byte, maxUDPSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		query := append([]byte(nil), buf[:n]...)
+		go func() {
+			if resp := s.handle(ctx, query, true); resp != nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}()
+	}
+}
+
+// serveTCP answers length-prefixed queries on each accepted connection
+func (s *Server) serveTCP(ctx context.Context, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Printf("dns: accept failed: %v", err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		go func() {
+			defer func() { _ = conn.Close() }()
+			for {
+				_ = conn.SetDeadline(time.Now().Add(tcpIdleTimeout))
+				query, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				resp := s.handle(ctx, query, false)
+				if resp == nil {
+					return
+				}
+				if err := writeTCPMessage(conn, resp); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// readTCPMessage reads one DNS message with its two-byte length prefix
+func readTCPMessage(r io.Reader) ([]byte, error) {
+	var length uint16
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeTCPMessage writes one DNS message with its two-byte length prefix
+func writeTCPMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// handle answers a query. Malformed messages and responses are dropped by returning nil.
+func (s *Server) handle(ctx context.Context, query []byte, udp bool) []byte {
+	var req dnsmessage.Message
+	if err := req.Unpack(query); err != nil || req.Header.Response {
+		return nil
+	}
+
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 req.Header.ID,
+			Response:           true,
+			OpCode:             req.Header.OpCode,
+			RecursionDesired:   req.Header.RecursionDesired,
+			RecursionAvailable: len(s.config.Upstreams) > 0,
+		},
+		Questions: req.Questions,
+	}
+
+	if req.Header.OpCode != 0 || len(req.Questions) != 1 {
+		resp.Header.RCode = dnsmessage.RCodeNotImplemented
+		return s.pack(resp, req, udp)
+	}
+
+	q := req.Questions[0]
+	name := strings.ToLower(q.Name.String())
+	if name != s.domain && !strings.HasSuffix(name, "."+s.domain) {
+		if answer, err := s.forward(ctx, query, udp); err == nil {
+			return answer
+		} else if len(s.config.Upstreams) > 0 {
+			log.Printf("dns: failed to forward %s: %v", name, err)
+			resp.Header.RCode = dnsmessage.RCodeServerFailure
+		} else {
+			resp.Header.RCode = dnsmessage.RCodeRefused
+		}
+		return s.pack(resp, req, udp)
+	}
+
+	result, err := s.resolve(strings.TrimSuffix(name, s.domain), q.Type)
+	if err != nil {
+		log.Printf("dns: failed to resolve %s: %v", name, err)
+		resp.Header.RCode = dnsmessage.RCodeServerFailure
+		return s.pack(resp, req, udp)
+	}
+
+	resp.Header.Authoritative = true
+	resp.Answers = s.followCNAME(ctx, result.answers, q.Type, udp)
+	resp.Additionals = result.additionals
+	if !result.exists {
+		resp.Header.RCode = dnsmessage.RCodeNameError
+	}
+	if len(resp.Answers) == 0 {
+		resp.Authorities = []dnsmessage.Resource{s.soa()}
+	}
+	return s.pack(resp, req, udp)
+}
+
+// followCNAME appends the records of the target when the answers end with a CNAME, resolving
+// targets in the cluster domain locally and others through the upstreams, so that clients get
+// the addresses of an ExternalName service in one response. Targets that do not resolve leave
+// the CNAME as the only answer.
+func (s *Server) followCNAME(
+	ctx context.Context, answers []dnsmessage.Resource, qtype dnsmessage.Type, udp bool,
+) []dnsmessage.Resource {
+	if qtype == dnsmessage.TypeCNAME {
+		return answers
+	}
+	for range maxCNAMEChain {
+		if len(answers) == 0 {
+			return answers
+		}
+		cname, ok := answers[len(answers)-1].Body.(*dnsmessage.CNAMEResource)
+		if !ok {
+			return answers
+		}
+
+		target := strings.ToLower(cname.CNAME.String())
+		var next []dnsmessage.Resource
+		if target == s.domain || strings.HasSuffix(target, "."+s.domain) {
+			result, err := s.resolve(strings.TrimSuffix(target, s.domain), qtype)
+			if err != nil {
+				log.Printf("dns: failed to resolve %s: %v", target, err)
+			}
+			next = result.answers
+		} else {
+			next = s.lookupUpstream(ctx, cname.CNAME, qtype, udp)
+		}
+		if len(next) == 0 {
+			return answers
+		}
+		answers = append(answers, next...)
+	}
+	return answers
+}
+
+// pack encodes a response, echoing EDNS0 when the query used it and truncating UDP responses
+// that exceed the client's buffer
+func (s *Server) pack(resp, req dnsmessage.Message, udp bool) []byte {
+	limit := minUDPSize
+	for _, extra := range req.Additionals {
+		if extra.Header.Type != dnsmessage.TypeOPT {
+			continue
+		}
+		limit = min(max(int(extra.Header.Class), minUDPSize), maxUDPSize)
+
+		var opt dnsmessage.ResourceHeader
+		_ = opt.SetEDNS0(maxUDPSize, dnsmessage.RCodeSuccess, false)
+		resp.Additionals = append(resp.Additionals, dnsmessage.Resource{Header: opt, Body: &dnsmessage.OPTResource{}})
+		break
+	}
+
+	out, err := resp.Pack()
+	if err != nil {
+		log.Printf("dns: failed to pack response: %v", err)
+		return nil
+	}
+	if !udp || len(out) <= limit {
+		return out
+	}
+
+	resp.Header.Truncated = true
+	resp.Answers, resp.Authorities = nil, nil
+	var extras []dnsmessage.Resource
+	for _, extra := range resp.Additionals {
+		if extra.Header.Type == dnsmessage.TypeOPT {
+			extras = append(extras, extra)
+		}
+	}
+	resp.Additionals = extras
+	if out, err = resp.Pack(); err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,439 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	store := state.NewInMemoryStore()
+
+	services := []types.Service{
+		{
+			ServiceID: "svc-web", Name: "web", Namespace: "default", ClusterIP: "10.96.0.10",
+			Ports: []types.ServicePort{{Name: "http", Protocol: "TCP", Port: 80, TargetPort: 8080}},
+		},
+		{
+			ServiceID: "svc-db", Name: "db", Namespace: "prod", ClusterIP: types.ClusterIPNone,
+			Ports: []types.ServicePort{{Name: "pg", Protocol: "TCP", Port: 5432, TargetPort: 5432}},
+		},
+		{
+			ServiceID: "svc-postgres", Name: "postgres", Namespace: "default",
+			Type: types.ServiceTypeExternalName, ExternalName: "db.prod.svc.cluster.local",
+			Ports: []types.ServicePort{{Name: "pg", Protocol: "TCP", Port: 5432}},
+		},
+		{
+			ServiceID: "svc-billing", Name: "billing", Namespace: "default",
+			Type: types.ServiceTypeExternalName, ExternalName: "billing.example.com",
+		},
+	}
+	for _, service := range services {
+		if err := store.AddService(service); err != nil {
+			t.Fatalf("AddService() error = %v", err)
+		}
+	}
+	err := store.SetEndpoints(
+		types.Endpoints{
+			ServiceID: "svc-db", ServiceName: "db", Namespace: "prod",
+			Subsets: []types.EndpointSubset{
+				{
+					Addresses: []types.EndpointAddress{{IP: "172.18.0.2"}, {IP: "172.18.0.3"}},
+					Ports:     []types.EndpointPort{{Name: "pg", Port: 5433}},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("SetEndpoints() error = %v", err)
+	}
+	pod := types.Pod{
+		PodID: "pod-1", Name: "api", Namespace: "default",
+		Annotations: map[string]string{podIPAnnotation: "172.18.0.9"},
+	}
+	if err := store.AddPod(pod); err != nil {
+		t.Fatalf("AddPod() error = %v", err)
+	}
+
+	config := DefaultConfig()
+	config.Upstreams = nil
+	return NewServer(store, config)
+}
+
+func buildQuery(t *testing.T, name string, qtype dnsmessage.Type, edns bool) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+	if edns {
+		var opt dnsmessage.ResourceHeader
+		_ = opt.SetEDNS0(4096, dnsmessage.RCodeSuccess, false)
+		msg.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+	return query
+}
+
+func unpack(t *testing.T, resp []byte) dnsmessage.Message {
+	t.Helper()
+	var msg dnsmessage.Message
+	if err := msg.Unpack(resp); err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	return msg
+}
+
+// answerStrings renders the answers as "A 10.96.0.10" or "SRV web.default.svc.cluster.local. 80"
+func answerStrings(resources []dnsmessage.Resource) []string {
+	var out []string
+	for _, r := range resources {
+		switch body := r.Body.(type) {
+		case *dnsmessage.AResource:
+			out = append(out, "A "+net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			out = append(out, "AAAA "+net.IP(body.AAAA[:]).String())
+		case *dnsmessage.CNAMEResource:
+			out = append(out, "CNAME "+body.CNAME.String())
+		case *dnsmessage.SRVResource:
+			out = append(out, "SRV "+body.Target.String()+" "+strconv.Itoa(int(body.Port)))
+		case *dnsmessage.SOAResource:
+			out = append(out, "SOA "+body.NS.String())
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestHandle(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		query       string
+		qtype       dnsmessage.Type
+		rcode       dnsmessage.RCode
+		answers     []string
+		additionals []string
+	}{
+		{
+			name:    "service ClusterIP",
+			query:   "web.default.svc.cluster.local.",
+			qtype:   dnsmessage.TypeA,
+			answers: []string{"A 10.96.0.10"},
+		},
+		{
+			name:  "service name is case insensitive",
+			query: "WEB.Default.SVC.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"A 10.96.0.10"},
+		},
+		{
+			name:  "IPv4 service has no AAAA",
+			query: "web.default.svc.cluster.local.", qtype: dnsmessage.TypeAAAA,
+		},
+		{
+			name:  "headless service resolves to endpoints",
+			query: "db.prod.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"A 172.18.0.2", "A 172.18.0.3"},
+		},
+		{
+			name:  "headless endpoint",
+			query: "172-18-0-3.db.prod.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"A 172.18.0.3"},
+		},
+		{
+			name:  "service SRV",
+			query: "_http._tcp.web.default.svc.cluster.local.", qtype: dnsmessage.TypeSRV,
+			answers:     []string{"SRV web.default.svc.cluster.local. 80"},
+			additionals: []string{"A 10.96.0.10"},
+		},
+		{
+			name:  "headless SRV targets endpoints",
+			query: "_pg._tcp.db.prod.svc.cluster.local.", qtype: dnsmessage.TypeSRV,
+			answers: []string{
+				"SRV 172-18-0-2.db.prod.svc.cluster.local. 5433",
+				"SRV 172-18-0-3.db.prod.svc.cluster.local. 5433",
+			},
+			additionals: []string{"A 172.18.0.2", "A 172.18.0.3"},
+		},
+		{
+			name:  "external name in the cluster is followed",
+			query: "postgres.default.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"A 172.18.0.2", "A 172.18.0.3", "CNAME db.prod.svc.cluster.local."},
+		},
+		{
+			name:  "external name without upstreams",
+			query: "billing.default.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"CNAME billing.example.com."},
+		},
+		{
+			name:  "external name CNAME query",
+			query: "postgres.default.svc.cluster.local.", qtype: dnsmessage.TypeCNAME,
+			answers: []string{"CNAME db.prod.svc.cluster.local."},
+		},
+		{
+			name:  "external name has no SRV",
+			query: "_pg._tcp.postgres.default.svc.cluster.local.", qtype: dnsmessage.TypeSRV,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "pod",
+			query: "172-18-0-9.default.pod.cluster.local.", qtype: dnsmessage.TypeA,
+			answers: []string{"A 172.18.0.9"},
+		},
+		{
+			name:  "namespace exists without records",
+			query: "default.svc.cluster.local.", qtype: dnsmessage.TypeA,
+		},
+		{
+			name:  "unknown service",
+			query: "missing.default.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "service in another namespace",
+			query: "web.prod.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "endpoint name of a ClusterIP service",
+			query: "172-18-0-2.web.default.svc.cluster.local.", qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeNameError,
+		},
+		{
+			name:  "outside the cluster without upstreams",
+			query: "example.com.", qtype: dnsmessage.TypeA,
+			rcode: dnsmessage.RCodeRefused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				resp := unpack(t, s.handle(context.Background(), buildQuery(t, tt.query, tt.qtype, false), true))
+				if resp.Header.ID != 42 || !resp.Header.Response {
+					t.Errorf("header = %+v, want a response to query 42", resp.Header)
+				}
+				if resp.Header.RCode != tt.rcode {
+					t.Errorf("rcode = %v, want %v", resp.Header.RCode, tt.rcode)
+				}
+				if got := answerStrings(resp.Answers); !reflect.DeepEqual(got, tt.answers) {
+					t.Errorf("answers = %q, want %q", got, tt.answers)
+				}
+				if got := answerStrings(resp.Additionals); !reflect.DeepEqual(got, tt.additionals) {
+					t.Errorf("additionals = %q, want %q", got, tt.additionals)
+				}
+				if len(tt.answers) == 0 && tt.rcode != dnsmessage.RCodeRefused && len(resp.Authorities) != 1 {
+					t.Errorf("authorities = %v, want the SOA for negative caching", resp.Authorities)
+				}
+			},
+		)
+	}
+}
+
+func TestHandleTruncatesLargeUDPResponses(t *testing.T) {
+	s := newTestServer(t)
+	addresses := make([]types.EndpointAddress, 0, 60)
+	for i := 0; i < 60; i++ {
+		addresses = append(addresses, types.EndpointAddress{IP: net.IPv4(172, 18, 1, byte(i)).String()})
+	}
+	err := s.store.SetEndpoints(
+		types.Endpoints{
+			ServiceID: "svc-db", ServiceName: "db", Namespace: "prod",
+			Subsets: []types.EndpointSubset{{Addresses: addresses}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("SetEndpoints() error = %v", err)
+	}
+
+	query := buildQuery(t, "db.prod.svc.cluster.local.", dnsmessage.TypeA, false)
+	resp := unpack(t, s.handle(context.Background(), query, true))
+	if !resp.Header.Truncated || len(resp.Answers) != 0 {
+		t.Errorf(
+			"truncated = %v with %d answers, want a truncated empty response",
+			resp.Header.Truncated, len(resp.Answers),
+		)
+	}
+
+	resp = unpack(t, s.handle(context.Background(), query, false))
+	if resp.Header.Truncated || len(resp.Answers) != 60 {
+		t.Errorf("tcp: truncated = %v with %d answers, want 60", resp.Header.Truncated, len(resp.Answers))
+	}
+
+	query = buildQuery(t, "db.prod.svc.cluster.local.", dnsmessage.TypeA, true)
+	resp = unpack(t, s.handle(context.Background(), query, true))
+	if resp.Header.Truncated || len(resp.Answers) != 60 {
+		t.Errorf("edns: truncated = %v with %d answers, want 60", resp.Header.Truncated, len(resp.Answers))
+	}
+}
+
+func TestServe(t *testing.T) {
+	// The upstream answers every query with a fixed address
+	upstream, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer func() { _ = upstream.Close() }()
+	go func() {
+		buf := make([]byte, maxUDPSize)
+		for {
+			n, addr, err := upstream.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if msg.Unpack(buf[:n]) != nil {
+				continue
+			}
+			msg.Header.Response = true
+			msg.Answers = []dnsmessage.Resource{
+				{
+					Header: dnsmessage.ResourceHeader{
+						Name: msg.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET,
+					},
+					Body: &dnsmessage.AResource{A: [4]byte{93, 184, 216, 34}},
+				},
+			}
+			out, _ := msg.Pack()
+			_, _ = upstream.WriteTo(out, addr)
+		}
+	}()
+
+	s := newTestServer(t)
+	s.config.Upstreams = []string{upstream.LocalAddr().String()}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Serve(ctx, conn, ln)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	udp, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = udp.Close() }()
+	_ = udp.SetDeadline(time.Now().Add(5 * time.Second))
+
+	exchangeUDP := func(name string) dnsmessage.Message {
+		if _, err := udp.Write(buildQuery(t, name, dnsmessage.TypeA, false)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		buf := make([]byte, maxUDPSize)
+		n, err := udp.Read(buf)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		return unpack(t, buf[:n])
+	}
+
+	if got := answerStrings(exchangeUDP("web.default.svc.cluster.local.").Answers); !reflect.DeepEqual(
+		got, []string{"A 10.96.0.10"},
+	) {
+		t.Errorf("udp cluster answers = %q", got)
+	}
+	if got := answerStrings(exchangeUDP("example.com.").Answers); !reflect.DeepEqual(got, []string{"A 93.184.216.34"}) {
+		t.Errorf("udp forwarded answers = %q", got)
+	}
+	if got := answerStrings(exchangeUDP("billing.default.svc.cluster.local.").Answers); !reflect.DeepEqual(
+		got, []string{"A 93.184.216.34", "CNAME billing.example.com."},
+	) {
+		t.Errorf("udp external name answers = %q", got)
+	}
+
+	tcp, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = tcp.Close() }()
+	_ = tcp.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := writeTCPMessage(tcp, buildQuery(t, "db.prod.svc.cluster.local.", dnsmessage.TypeA, false)); err != nil {
+		t.Fatalf("writeTCPMessage() error = %v", err)
+	}
+	resp, err := readTCPMessage(tcp)
+	if err != nil {
+		t.Fatalf("readTCPMessage() error = %v", err)
+	}
+	if got := answerStrings(unpack(t, resp).Answers); !reflect.DeepEqual(got, []string{"A 172.18.0.2", "A 172.18.0.3"}) {
+		t.Errorf("tcp answers = %q", got)
+	}
+}
+
+func TestSystemUpstreams(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolv.conf")
+	content := "# generated\nsearch example.com\nnameserver 10.0.0.2\nnameserver fd00::1\nnameserver bogus\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	want := []string{"10.0.0.2:53", "[fd00::1]:53"}
+	if got := SystemUpstreams(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("SystemUpstreams() = %q, want %q", got, want)
+	}
+	if got := SystemUpstreams(filepath.Join(t.TempDir(), "missing")); got != nil {
+		t.Errorf("SystemUpstreams() of a missing file = %q, want nil", got)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*Config)
+		wantErr bool
+	}{
+		{name: "default", mutate: func(*Config) {}},
+		{name: "missing address", mutate: func(c *Config) { c.Addr = "" }, wantErr: true},
+		{name: "missing domain", mutate: func(c *Config) { c.Domain = "." }, wantErr: true},
+		{name: "upstream without port", mutate: func(c *Config) { c.Upstreams = []string{"10.0.0.2"} }, wantErr: true},
+		{
+			name: "upstream without timeout",
+			mutate: func(c *Config) {
+				c.Upstreams = []string{"10.0.0.2:53"}
+				c.ForwardTimeout = 0
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := DefaultConfig()
+				tt.mutate(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,259 @@
+// Package logstore retains the container logs shipped by workers so that they can be searched
+// across pods, including pods that have terminated or whose worker is gone.
+package logstore
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Config limits how many log lines are retained for each pod
+type Config struct {
+	// MaxBytesPerPod caps the size of the lines kept for a pod; the oldest lines are dropped first
+	MaxBytesPerPod int64
+
+	// MaxAge is how long lines are kept after they were written
+	MaxAge time.Duration
+
+	// PruneInterval is how often expired lines are removed
+	PruneInterval time.Duration
+}
+
+// DefaultConfig returns the default retention limits
+func DefaultConfig() Config {
+	return Config{
+		MaxBytesPerPod: 10 * 1024 * 1024,
+		MaxAge:         24 * time.Hour,
+		PruneInterval:  time.Minute,
+	}
+}
+
+// Validate checks that the limits are usable
+func (c Config) Validate() error {
+	if c.MaxBytesPerPod <= 0 {
+		return fmt.Errorf("max bytes per pod must be positive, got %d", c.MaxBytesPerPod)
+	}
+	if c.MaxAge <= 0 {
+		return fmt.Errorf("max age must be positive, got %v", c.MaxAge)
+	}
+	if c.PruneInterval <= 0 {
+		return fmt.Errorf("prune interval must be positive, got %v", c.PruneInterval)
+	}
+	return nil
+}
+
+// Query selects the log lines returned by Search. Empty fields match everything.
+type Query struct {
+	Namespace string
+	PodID     string
+	Container string
+	// Selector matches pods whose labels contain all of its key=value pairs
+	Selector map[string]string
+	// Grep matches lines containing the pattern
+	Grep *regexp.Regexp
+	// Since skips lines written before this time
+	Since time.Time
+	// Limit keeps only the most recent lines; zero or less returns all matching lines
+	Limit int
+}
+
+// podLog holds the retained lines of one pod, oldest first
+type podLog struct {
+	podID     string
+	name      string
+	namespace string
+	nodeID    string
+	labels    map[string]string
+	entries   []types.LogEntry
+	bytes     int64
+}
+
+// Store keeps the shipped logs of every pod in memory
+type Store struct {
+	config Config
+	mu     sync.RWMutex
+	pods   map[string]*podLog
+	now    func() time.Time
+}
+
+// NewStore creates an empty log store with the given retention limits
+func NewStore(config Config) *Store {
+	return &Store{
+		config: config,
+		pods:   make(map[string]*podLog),
+		now:    time.Now,
+	}
+}
+
+// Config returns the store's retention limits
+func (s *Store) Config() Config {
+	return s.config
+}
+
+// Append stores shipped log records. Records without a timestamp are stamped with the current time.
+func (s *Store) Append(records []types.LogRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, record := range records {
+		if record.PodID == "" {
+			continue
+		}
+
+		pod, ok := s.pods[record.PodID]
+		if !ok {
+			pod = &podLog{podID: record.PodID}
+			s.pods[record.PodID] = pod
+		}
+		if record.PodName != "" {
+			pod.name = record.PodName
+		}
+		if record.Namespace != "" {
+			pod.namespace = record.Namespace
+		}
+		if record.NodeID != "" {
+			pod.nodeID = record.NodeID
+		}
+		if record.Labels != nil {
+			pod.labels = record.Labels
+		}
+
+		entry := record.LogEntry
+		if entry.Timestamp == nil {
+			ts := now
+			entry.Timestamp = &ts
+		}
+		pod.entries = append(pod.entries, entry)
+		pod.bytes += entrySize(entry)
+
+		dropped := 0
+		for pod.bytes > s.config.MaxBytesPerPod && dropped < len(pod.entries) {
+			pod.bytes -= entrySize(pod.entries[dropped])
+			dropped++
+		}
+		if dropped > 0 {
+			pod.entries = pod.entries[dropped:]
+		}
+	}
+}
+
+// Search returns the retained lines matching the query, oldest first
+func (s *Store) Search(q Query) []types.LogRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cutoff := s.now().Add(-s.config.MaxAge)
+	if q.Since.After(cutoff) {
+		cutoff = q.Since
+	}
+
+	var results []types.LogRecord
+	for _, pod := range s.pods {
+		if q.PodID != "" && pod.podID != q.PodID {
+			continue
+		}
+		if q.Namespace != "" && namespaceOf(pod.namespace) != namespaceOf(q.Namespace) {
+			continue
+		}
+		if !types.MatchesSelector(pod.labels, q.Selector) {
+			continue
+		}
+
+		for _, entry := range pod.entries {
+			if entry.Timestamp.Before(cutoff) {
+				continue
+			}
+			if q.Container != "" && entry.Container != q.Container {
+				continue
+			}
+			if q.Grep != nil && !q.Grep.MatchString(entry.Line) {
+				continue
+			}
+			results = append(
+				results, types.LogRecord{
+					PodID:     pod.podID,
+					PodName:   pod.name,
+					Namespace: pod.namespace,
+					NodeID:    pod.nodeID,
+					Labels:    pod.labels,
+					LogEntry:  entry,
+				},
+			)
+		}
+	}
+
+	sort.SliceStable(
+		results, func(i, j int) bool {
+			if !results[i].Timestamp.Equal(*results[j].Timestamp) {
+				return results[i].Timestamp.Before(*results[j].Timestamp)
+			}
+			return results[i].PodID < results[j].PodID
+		},
+	)
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[len(results)-q.Limit:]
+	}
+	return results
+}
+
+// Prune removes lines older than the maximum age and forgets pods without lines.
+// It returns the number of lines removed.
+func (s *Store) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-s.config.MaxAge)
+	removed := 0
+	for podID, pod := range s.pods {
+		kept := pod.entries[:0]
+		for _, entry := range pod.entries {
+			if entry.Timestamp.Before(cutoff) {
+				pod.bytes -= entrySize(entry)
+				removed++
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		pod.entries = kept
+		if len(pod.entries) == 0 {
+			delete(s.pods, podID)
+		}
+	}
+	return removed
+}
+
+// Run prunes expired lines every PruneInterval until ctx is cancelled
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Prune()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// entrySize approximates the memory held by a log line
+func entrySize(entry types.LogEntry) int64 {
+	return int64(len(entry.Line) + len(entry.Container) + len(entry.Stream))
+}
+
+// namespaceOf normalizes an empty namespace to "default"
+func namespaceOf(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
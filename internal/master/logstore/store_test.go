@@ -0,0 +1,127 @@
+package logstore
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func record(podID string, labels map[string]string, line string, ts time.Time) types.LogRecord {
+	return types.LogRecord{
+		PodID:    podID,
+		PodName:  podID,
+		Labels:   labels,
+		LogEntry: types.LogEntry{Container: "app", Stream: types.LogStreamStdout, Timestamp: &ts, Line: line},
+	}
+}
+
+func newTestStore(config Config, now time.Time) *Store {
+	store := NewStore(config)
+	store.now = func() time.Time { return now }
+	return store
+}
+
+func TestStoreSearch(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newTestStore(DefaultConfig(), now)
+
+	web := map[string]string{"app": "web"}
+	store.Append(
+		[]types.LogRecord{
+			record("web-1", web, "GET / 200", now.Add(-3*time.Minute)),
+			record("api-1", map[string]string{"app": "api"}, "GET /users 500", now.Add(-2*time.Minute)),
+			record("web-2", web, "GET /health 500", now.Add(-time.Minute)),
+			record("web-1", web, "GET /about 200", now),
+		},
+	)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{
+			name:  "all pods in time order",
+			query: Query{},
+			want:  []string{"GET / 200", "GET /users 500", "GET /health 500", "GET /about 200"},
+		},
+		{
+			name:  "selector",
+			query: Query{Selector: web},
+			want:  []string{"GET / 200", "GET /health 500", "GET /about 200"},
+		},
+		{
+			name:  "selector and grep",
+			query: Query{Selector: web, Grep: regexp.MustCompile(`\b500\b`)},
+			want:  []string{"GET /health 500"},
+		},
+		{
+			name:  "pod",
+			query: Query{PodID: "web-1"},
+			want:  []string{"GET / 200", "GET /about 200"},
+		},
+		{
+			name:  "since",
+			query: Query{Since: now.Add(-90 * time.Second)},
+			want:  []string{"GET /health 500", "GET /about 200"},
+		},
+		{
+			name:  "limit keeps the most recent lines",
+			query: Query{Limit: 1},
+			want:  []string{"GET /about 200"},
+		},
+		{
+			name:  "other namespace",
+			query: Query{Namespace: "prod"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				results := store.Search(tt.query)
+				var lines []string
+				for _, r := range results {
+					lines = append(lines, r.Line)
+				}
+				if len(lines) != len(tt.want) {
+					t.Fatalf("Search() = %v, want %v", lines, tt.want)
+				}
+				for i := range lines {
+					if lines[i] != tt.want[i] {
+						t.Errorf("Search()[%d] = %q, want %q", i, lines[i], tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestStoreRetention(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	config := Config{MaxBytesPerPod: 30, MaxAge: time.Hour, PruneInterval: time.Minute}
+	store := newTestStore(config, now)
+
+	// Each line is 10 bytes including its container and stream names, so only the last three fit
+	for _, line := range []string{"a", "b", "c", "d"} {
+		store.Append([]types.LogRecord{record("pod-1", nil, line, now)})
+	}
+	results := store.Search(Query{PodID: "pod-1"})
+	if len(results) != 3 || results[0].Line != "b" {
+		t.Fatalf("Search() returned %d lines starting with %q, want b, c, d", len(results), results[0].Line)
+	}
+
+	store.Append([]types.LogRecord{record("pod-2", nil, "old", now.Add(-2*time.Hour))})
+	if results := store.Search(Query{PodID: "pod-2"}); len(results) != 0 {
+		t.Errorf("expected lines older than the max age to be hidden, got %v", results)
+	}
+	if removed := store.Prune(); removed != 1 {
+		t.Errorf("Prune() = %d, want 1", removed)
+	}
+	if _, ok := store.pods["pod-2"]; ok {
+		t.Error("expected a pod without lines to be forgotten")
+	}
+}
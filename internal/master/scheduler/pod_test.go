@@ -230,3 +230,101 @@ func TestRoundRobin_Concurrent(t *testing.T) {
 		}
 	}
 }
+
+func TestRoundRobin_SkipsNodesUnderPressure(t *testing.T) {
+	scheduler := NewRoundRobin()
+
+	pressured := newTestNode("node-1", types.NodeOnline, 0)
+	pressured.Conditions = []types.NodeCondition{{Type: types.NodeMemoryPressure, Status: true}}
+	healthy := newTestNode("node-2", types.NodeOnline, 0)
+
+	pod := types.Pod{PodID: "pod-1", Containers: []types.Container{{Name: "app", Image: "nginx"}}}
+
+	for i := 0; i < 4; i++ {
+		node, err := scheduler.SelectNodeForPod(pod, []types.Node{pressured, healthy})
+		if err != nil {
+			t.Fatalf("SelectNodeForPod failed: %v", err)
+		}
+		if node.NodeID != "node-2" {
+			t.Fatalf("expected node under pressure to be skipped, got %s", node.NodeID)
+		}
+	}
+
+	_, err := scheduler.SelectNodeForPod(pod, []types.Node{pressured})
+	if !errors.Is(err, ErrNoAvailableNodes) {
+		t.Errorf("expected ErrNoAvailableNodes, got %v", err)
+	}
+
+	_, err = scheduler.SelectNode(types.Task{TaskID: "task-1"}, []types.Node{pressured})
+	if !errors.Is(err, ErrNoAvailableNodes) {
+		t.Errorf("expected tasks to skip nodes under pressure, got %v", err)
+	}
+}
+
+func TestRoundRobin_SkipsCordonedNodes(t *testing.T) {
+	scheduler := NewRoundRobin()
+
+	cordoned := newTestNode("node-1", types.NodeOnline, 0)
+	cordoned.Unschedulable = true
+	schedulable := newTestNode("node-2", types.NodeOnline, 0)
+
+	pod := types.Pod{PodID: "pod-1", Containers: []types.Container{{Name: "app", Image: "nginx"}}}
+
+	for i := 0; i < 4; i++ {
+		node, err := scheduler.SelectNodeForPod(pod, []types.Node{cordoned, schedulable})
+		if err != nil {
+			t.Fatalf("SelectNodeForPod failed: %v", err)
+		}
+		if node.NodeID != "node-2" {
+			t.Fatalf("expected cordoned node to be skipped, got %s", node.NodeID)
+		}
+	}
+
+	_, err := scheduler.SelectNode(types.Task{TaskID: "task-1"}, []types.Node{cordoned})
+	if !errors.Is(err, ErrNoAvailableNodes) {
+		t.Errorf("expected tasks to skip cordoned nodes, got %v", err)
+	}
+}
+
+func TestRoundRobin_SkipsNodesNotReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []types.NodeCondition
+	}{
+		{
+			name:       "not ready",
+			conditions: []types.NodeCondition{{Type: types.NodeReady, Status: false}},
+		},
+		{
+			name:       "runtime unavailable",
+			conditions: []types.NodeCondition{{Type: types.NodeRuntimeUnavailable, Status: true}},
+		},
+		{
+			name: "pid pressure",
+			conditions: []types.NodeCondition{
+				{Type: types.NodeReady, Status: true},
+				{Type: types.NodePIDPressure, Status: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				scheduler := NewRoundRobin()
+
+				node := newTestNode("node-1", types.NodeOnline, 0)
+				node.Conditions = tt.conditions
+
+				pod := types.Pod{PodID: "pod-1", Containers: []types.Container{{Name: "app", Image: "nginx"}}}
+				if _, err := scheduler.SelectNodeForPod(pod, []types.Node{node}); !errors.Is(err, ErrNoAvailableNodes) {
+					t.Errorf("expected ErrNoAvailableNodes for pods, got %v", err)
+				}
+				_, err := scheduler.SelectNode(types.Task{TaskID: "task-1"}, []types.Node{node})
+				if !errors.Is(err, ErrNoAvailableNodes) {
+					t.Errorf("expected ErrNoAvailableNodes for tasks, got %v", err)
+				}
+			},
+		)
+	}
+}
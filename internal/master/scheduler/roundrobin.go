@@ -59,12 +59,12 @@ func (rr *RoundRobin) SelectNodeForPod(pod types.Pod, nodes []types.Node) (*type
 	return &availableNodes[rr.lastUsed], nil
 }
 
-// filterAvailableForTask filters nodes to those that are online and have sufficient
+// filterAvailableForTask filters nodes to those that are online, ready, not cordoned and have sufficient
 // resources for the task. Resource checking is always performed.
 func filterAvailableForTask(task types.Task, nodes []types.Node) []types.Node {
 	available := make([]types.Node, 0)
 	for _, node := range nodes {
-		if node.Status != types.NodeOnline {
+		if node.Status != types.NodeOnline || node.Unschedulable {
 			continue
 		}
 
@@ -72,6 +72,12 @@ func filterAvailableForTask(task types.Task, nodes []types.Node) []types.Node {
 			continue
 		}
 
+		// Nodes that cannot reach their runtime or are under memory, disk or PID pressure
+		// cannot take new work
+		if !node.IsReady() || node.HasPressure() {
+			continue
+		}
+
 		maxSlots := node.GetMaxTaskSlots()
 		if node.RunningTasks >= maxSlots {
 			continue
@@ -88,14 +94,14 @@ func filterAvailableForTask(task types.Task, nodes []types.Node) []types.Node {
 	return available
 }
 
-// filterAvailableForPod filters nodes to those that are online and have sufficient
+// filterAvailableForPod filters nodes to those that are online, ready, not cordoned and have sufficient
 // resources for the pod's total resource requirements
 func filterAvailableForPod(pod types.Pod, nodes []types.Node) []types.Node {
 	available := make([]types.Node, 0)
 	totalResources := pod.GetTotalResourceRequests()
 
 	for _, node := range nodes {
-		if node.Status != types.NodeOnline {
+		if node.Status != types.NodeOnline || node.Unschedulable {
 			continue
 		}
 
@@ -103,6 +109,12 @@ func filterAvailableForPod(pod types.Pod, nodes []types.Node) []types.Node {
 			continue
 		}
 
+		// Nodes that cannot reach their runtime or are under memory, disk or PID pressure
+		// cannot take new work
+		if !node.IsReady() || node.HasPressure() {
+			continue
+		}
+
 		maxSlots := node.GetMaxTaskSlots()
 		if node.RunningTasks >= maxSlots {
 			continue
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +22,7 @@ type EndpointController struct {
 	stopChan     chan struct{}
 	syncInterval time.Duration
 	ipAllocator  *ClusterIPAllocator
+	nodePorts    *NodePortAllocator
 }
 
 // NewEndpointController creates a new endpoint controller
@@ -28,6 +32,7 @@ func NewEndpointController(store state.StateStore) *EndpointController {
 		stopChan:     make(chan struct{}),
 		syncInterval: 10 * time.Second,
 		ipAllocator:  NewClusterIPAllocator("10.96.0.0/12"),
+		nodePorts:    NewNodePortAllocator(store, DefaultNodePortRange),
 	}
 }
 
@@ -107,7 +112,10 @@ func (ec *EndpointController) syncServiceEndpoints(service types.Service) error
 	return nil
 }
 
-// buildEndpoints creates an Endpoints object from a service and its matching pods
+// buildEndpoints creates an Endpoints object from a service and its matching pods. Target ports
+// are resolved per pod and pods are grouped into one subset per resolved port set, so pods that
+// expose a named port under different numbers are still reachable. Pods that serve none of the
+// service's ports are left out.
 func (ec *EndpointController) buildEndpoints(service types.Service, pods []types.Pod) types.Endpoints {
 	namespace := service.Namespace
 	if namespace == "" {
@@ -121,9 +129,7 @@ func (ec *EndpointController) buildEndpoints(service types.Service, pods []types
 		Subsets:     []types.EndpointSubset{},
 	}
 
-	var readyAddrs []types.EndpointAddress
-	var notReadyAddrs []types.EndpointAddress
-
+	subsets := make(map[string]*types.EndpointSubset)
 	for _, pod := range pods {
 		if pod.Status != types.PodRunning || pod.NodeID == "" {
 			continue
@@ -134,41 +140,107 @@ func (ec *EndpointController) buildEndpoints(service types.Service, pods []types
 			continue
 		}
 
+		ports := resolvePorts(service, pod)
+		if len(ports) == 0 && len(service.Ports) > 0 {
+			continue
+		}
+
+		key := endpointPortsKey(ports)
+		subset, ok := subsets[key]
+		if !ok {
+			subset = &types.EndpointSubset{Ports: ports}
+			subsets[key] = subset
+		}
+
 		addr := types.EndpointAddress{
 			IP:     podIP,
 			PodID:  pod.PodID,
 			NodeID: pod.NodeID,
+			Weight: ec.getPodWeight(pod),
 		}
 
 		if ec.isPodReady(pod) {
-			readyAddrs = append(readyAddrs, addr)
+			subset.Addresses = append(subset.Addresses, addr)
 		} else {
-			notReadyAddrs = append(notReadyAddrs, addr)
+			subset.NotReadyAddresses = append(subset.NotReadyAddresses, addr)
 		}
 	}
 
-	var endpointPorts []types.EndpointPort
+	keys := make([]string, 0, len(subsets))
+	for key := range subsets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		subset := subsets[key]
+		sortEndpointAddresses(subset.Addresses)
+		sortEndpointAddresses(subset.NotReadyAddresses)
+		endpoints.Subsets = append(endpoints.Subsets, *subset)
+	}
+
+	return endpoints
+}
+
+// resolvePorts returns the endpoint ports a pod serves for the service's ports, in the service's
+// port order. Named target ports are looked up in the pod's container ports by name and protocol;
+// ports the pod does not expose are left out.
+func resolvePorts(service types.Service, pod types.Pod) []types.EndpointPort {
+	var ports []types.EndpointPort
 	for _, svcPort := range service.Ports {
-		endpointPorts = append(
-			endpointPorts, types.EndpointPort{
+		target := svcPort.TargetPort
+		if svcPort.TargetPortName != "" {
+			target = containerPortByName(pod, svcPort.TargetPortName, svcPort.Protocol)
+		} else if target == 0 {
+			target = svcPort.Port
+		}
+		if target == 0 {
+			continue
+		}
+
+		ports = append(
+			ports, types.EndpointPort{
 				Name:     svcPort.Name,
-				Port:     svcPort.TargetPort,
+				Port:     target,
 				Protocol: svcPort.Protocol,
 			},
 		)
 	}
+	return ports
+}
 
-	if len(readyAddrs) > 0 || len(notReadyAddrs) > 0 {
-		endpoints.Subsets = append(
-			endpoints.Subsets, types.EndpointSubset{
-				Addresses:         readyAddrs,
-				NotReadyAddresses: notReadyAddrs,
-				Ports:             endpointPorts,
-			},
-		)
+// containerPortByName finds the number of a pod's container port by name and protocol, or zero
+func containerPortByName(pod types.Pod, name, protocol string) int {
+	for _, container := range pod.Containers {
+		for _, port := range container.Ports {
+			if port.Name == name && strings.EqualFold(protocolOrTCP(port.Protocol), protocolOrTCP(protocol)) {
+				return port.ContainerPort
+			}
+		}
 	}
+	return 0
+}
 
-	return endpoints
+// protocolOrTCP returns the protocol, defaulting to TCP
+func protocolOrTCP(protocol string) string {
+	if protocol == "" {
+		return "TCP"
+	}
+	return protocol
+}
+
+// endpointPortsKey identifies a resolved port set
+func endpointPortsKey(ports []types.EndpointPort) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = fmt.Sprintf("%s/%d/%s", port.Name, port.Port, protocolOrTCP(port.Protocol))
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortEndpointAddresses orders addresses by IP, so endpoints only change when pods do
+func sortEndpointAddresses(addrs []types.EndpointAddress) {
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].IP < addrs[j].IP })
 }
 
 // getPodIP extracts the IP address from a pod's annotations
@@ -183,6 +255,16 @@ func (ec *EndpointController) getPodIP(pod types.Pod) string {
 	return ""
 }
 
+// getPodWeight reads the pod's load balancing weight from its annotations
+// Missing or invalid weights are reported as zero, which means the default weight
+func (ec *EndpointController) getPodWeight(pod types.Pod) int {
+	weight, err := strconv.Atoi(pod.Annotations[types.EndpointWeightAnnotation])
+	if err != nil || weight < 0 {
+		return 0
+	}
+	return weight
+}
+
 // isPodReady checks if all containers in a pod are ready
 func (ec *EndpointController) isPodReady(pod types.Pod) bool {
 	if pod.Status != types.PodRunning {
@@ -219,6 +301,28 @@ func (ec *EndpointController) ReleaseClusterIP(ip string) error {
 	return ec.ipAllocator.Release(ip)
 }
 
+// SetNodePortRange changes the range node ports are allocated from.
+// It must be called before services are created.
+func (ec *EndpointController) SetNodePortRange(portRange PortRange) {
+	ec.nodePorts = NewNodePortAllocator(ec.store, portRange)
+}
+
+// AllocateNodePort reserves a node port of a protocol for a service, picking a free one if
+// requested is zero
+func (ec *EndpointController) AllocateNodePort(serviceID, protocol string, requested int) (int, error) {
+	return ec.nodePorts.Allocate(serviceID, protocol, requested)
+}
+
+// ReleaseNodePort frees a node port of a protocol
+func (ec *EndpointController) ReleaseNodePort(port int, protocol string) error {
+	return ec.nodePorts.Release(port, protocol)
+}
+
+// ReleaseNodePorts frees every node port held by a service
+func (ec *EndpointController) ReleaseNodePorts(serviceID string) error {
+	return ec.nodePorts.ReleaseService(serviceID)
+}
+
 // ClusterIPAllocator manages allocation of cluster IPs for services
 type ClusterIPAllocator struct {
 	mu        sync.RWMutex
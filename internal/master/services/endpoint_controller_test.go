@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -154,6 +155,27 @@ func TestEndpointControllerGetPodIP(t *testing.T) {
 	}
 }
 
+func TestEndpointControllerGetPodWeight(t *testing.T) {
+	ec := NewEndpointController(state.NewInMemoryStore())
+
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 0},
+		{value: "3", want: 3},
+		{value: "-1", want: 0},
+		{value: "heavy", want: 0},
+	}
+
+	for _, tt := range tests {
+		pod := types.Pod{Annotations: map[string]string{types.EndpointWeightAnnotation: tt.value}}
+		if got := ec.getPodWeight(pod); got != tt.want {
+			t.Errorf("getPodWeight(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
 func TestEndpointControllerIsPodReady(t *testing.T) {
 	store := state.NewInMemoryStore()
 	ec := NewEndpointController(store)
@@ -539,3 +561,76 @@ func TestEndpointControllerSyncAllEndpoints(t *testing.T) {
 		t.Error("Expected endpoints for svc-2")
 	}
 }
+
+func TestEndpointControllerBuildEndpointsNamedTargetPorts(t *testing.T) {
+	ec := NewEndpointController(state.NewInMemoryStore())
+
+	service := types.Service{
+		ServiceID: "svc-1",
+		Name:      "web",
+		Namespace: "default",
+		Ports: []types.ServicePort{
+			{Name: "http", Port: 80, TargetPortName: "web", Protocol: "TCP"},
+			{Name: "metrics", Port: 9090, TargetPort: 9100, Protocol: "TCP"},
+		},
+	}
+
+	pod := func(id, ip string, ports ...types.ContainerPort) types.Pod {
+		return types.Pod{
+			PodID:       id,
+			Namespace:   "default",
+			Status:      types.PodRunning,
+			NodeID:      "node-1",
+			Annotations: map[string]string{"podling.io/pod-ip": ip},
+			Containers: []types.Container{
+				{Name: "app", Status: types.ContainerRunning, Ports: ports},
+			},
+		}
+	}
+
+	pods := []types.Pod{
+		pod("pod-1", "172.17.0.3", types.ContainerPort{Name: "web", ContainerPort: 8080}),
+		pod("pod-2", "172.17.0.2", types.ContainerPort{Name: "web", ContainerPort: 8080, Protocol: "TCP"}),
+		pod("pod-3", "172.17.0.4", types.ContainerPort{Name: "web", ContainerPort: 3000}),
+		pod("pod-4", "172.17.0.5", types.ContainerPort{Name: "web", ContainerPort: 5353, Protocol: "UDP"}),
+		pod("pod-5", "172.17.0.6"),
+	}
+
+	endpoints := ec.buildEndpoints(service, pods)
+
+	type subset struct {
+		ips   []string
+		ports []int
+	}
+	var got []subset
+	for _, s := range endpoints.Subsets {
+		var entry subset
+		for _, addr := range s.Addresses {
+			entry.ips = append(entry.ips, addr.IP)
+		}
+		for _, p := range s.Ports {
+			entry.ports = append(entry.ports, p.Port)
+		}
+		got = append(got, entry)
+	}
+
+	want := []subset{
+		{ips: []string{"172.17.0.4"}, ports: []int{3000, 9100}},
+		{ips: []string{"172.17.0.2", "172.17.0.3"}, ports: []int{8080, 9100}},
+		{ips: []string{"172.17.0.5", "172.17.0.6"}, ports: []int{9100}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("subsets = %+v, want %+v", got, want)
+	}
+
+	// Every subset serves metrics; only the subsets with the named port serve http
+	for i, s := range endpoints.Subsets {
+		_, servesHTTP := s.TargetPort(0, service.Ports[0])
+		if port, ok := s.TargetPort(1, service.Ports[1]); !ok || port != 9100 {
+			t.Errorf("subset %d: metrics target port = %d, %v", i, port, ok)
+		}
+		if servesHTTP != (i < 2) {
+			t.Errorf("subset %d: serves http = %v", i, servesHTTP)
+		}
+	}
+}
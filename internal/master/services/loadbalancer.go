@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// ErrLoadBalancerPoolExhausted is returned when a provider has no addresses left to assign
+var ErrLoadBalancerPoolExhausted = errors.New("no load balancer addresses available")
+
+// LoadBalancerProvider assigns external addresses to LoadBalancer services
+type LoadBalancerProvider interface {
+	// Name identifies the provider in logs
+	Name() string
+
+	// EnsureLoadBalancer returns the load balancer status of a service, assigning addresses
+	// when it has none. It is called for every LoadBalancer service on every sync, with the
+	// service's current status, and must be idempotent.
+	EnsureLoadBalancer(ctx context.Context, service types.Service) (types.LoadBalancerStatus, error)
+
+	// ReleaseLoadBalancer frees the addresses assigned to a service
+	ReleaseLoadBalancer(ctx context.Context, serviceID string) error
+}
+
+// LoadBalancerController keeps the status of LoadBalancer services in sync with a provider.
+// It assigns addresses to new LoadBalancer services and releases them when a service is
+// deleted or changes type.
+type LoadBalancerController struct {
+	store        state.StateStore
+	provider     LoadBalancerProvider
+	syncInterval time.Duration
+	stopChan     chan struct{}
+
+	mu          sync.Mutex
+	provisioned map[string]bool
+}
+
+// NewLoadBalancerController creates a controller that provisions services through provider
+func NewLoadBalancerController(store state.StateStore, provider LoadBalancerProvider) *LoadBalancerController {
+	return &LoadBalancerController{
+		store:        store,
+		provider:     provider,
+		syncInterval: 5 * time.Second,
+		stopChan:     make(chan struct{}),
+		provisioned:  make(map[string]bool),
+	}
+}
+
+// Start runs the reconciliation loop until ctx is cancelled or Stop is called
+func (lc *LoadBalancerController) Start(ctx context.Context) error {
+	log.Printf("Starting load balancer controller with provider %s...", lc.provider.Name())
+
+	ticker := time.NewTicker(lc.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := lc.Sync(ctx); err != nil {
+			log.Printf("Load balancer sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Load balancer controller stopping...")
+			return nil
+		case <-lc.stopChan:
+			log.Println("Load balancer controller stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop halts the load balancer controller
+func (lc *LoadBalancerController) Stop() {
+	close(lc.stopChan)
+}
+
+// Sync provisions every LoadBalancer service and releases the addresses of services that
+// are gone or no longer of type LoadBalancer. Services that already have addresses are
+// handled first, so that after a restart they keep them before new services are assigned any.
+func (lc *LoadBalancerController) Sync(ctx context.Context) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	services, err := lc.store.ListServices("")
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var assigned, pending []types.Service
+	current := make(map[string]bool, len(services))
+	for _, service := range services {
+		if service.Type != types.ServiceTypeLoadBalancer {
+			if lc.provisioned[service.ServiceID] || len(service.Status.LoadBalancer.Ingress) > 0 {
+				lc.release(ctx, service.ServiceID)
+				lc.clearStatus(service)
+			}
+			continue
+		}
+
+		current[service.ServiceID] = true
+		if len(service.Status.LoadBalancer.Ingress) > 0 {
+			assigned = append(assigned, service)
+		} else {
+			pending = append(pending, service)
+		}
+	}
+
+	for serviceID := range lc.provisioned {
+		if !current[serviceID] {
+			lc.release(ctx, serviceID)
+		}
+	}
+
+	for _, service := range append(assigned, pending...) {
+		if err := lc.ensure(ctx, service); err != nil {
+			log.Printf("Failed to provision load balancer for service %s: %v", service.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ensure provisions a single service and stores its status if it changed
+func (lc *LoadBalancerController) ensure(ctx context.Context, service types.Service) error {
+	status, err := lc.provider.EnsureLoadBalancer(ctx, service)
+	if err != nil {
+		return err
+	}
+	lc.provisioned[service.ServiceID] = true
+
+	if reflect.DeepEqual(status, service.Status.LoadBalancer) {
+		return nil
+	}
+
+	serviceStatus := service.Status
+	serviceStatus.LoadBalancer = status
+	if err := lc.store.UpdateService(service.ServiceID, types.ServiceUpdate{Status: &serviceStatus}); err != nil {
+		return fmt.Errorf("failed to update service status: %w", err)
+	}
+	return nil
+}
+
+// release frees a service's addresses and forgets it
+func (lc *LoadBalancerController) release(ctx context.Context, serviceID string) {
+	if err := lc.provider.ReleaseLoadBalancer(ctx, serviceID); err != nil {
+		log.Printf("Failed to release load balancer for service %s: %v", serviceID, err)
+		return
+	}
+	delete(lc.provisioned, serviceID)
+}
+
+// clearStatus removes the ingress addresses from a service that is no longer a load balancer
+func (lc *LoadBalancerController) clearStatus(service types.Service) {
+	if len(service.Status.LoadBalancer.Ingress) == 0 {
+		return
+	}
+	status := service.Status
+	status.LoadBalancer = types.LoadBalancerStatus{}
+	if err := lc.store.UpdateService(service.ServiceID, types.ServiceUpdate{Status: &status}); err != nil {
+		log.Printf("Failed to clear load balancer status of service %s: %v", service.Name, err)
+	}
+}
+
+// IPPoolProvider assigns each LoadBalancer service an IPv4 address from a local pool, similar
+// to MetalLB in L2 mode. Assignments are kept in memory and rebuilt from the services' statuses,
+// which the controller passes back on every sync.
+type IPPoolProvider struct {
+	ranges []ipRange
+
+	mu       sync.Mutex
+	assigned map[uint32]string
+	byID     map[string]uint32
+}
+
+var _ LoadBalancerProvider = (*IPPoolProvider)(nil)
+
+// ipRange is an inclusive range of IPv4 addresses
+type ipRange struct {
+	first uint32
+	last  uint32
+}
+
+// NewIPPoolProvider creates a provider for a comma-separated list of CIDRs and address ranges,
+// such as "192.168.1.240/28,192.168.1.100-192.168.1.110". The network and broadcast addresses
+// of CIDRs larger than /31 are not assigned.
+func NewIPPoolProvider(pool string) (*IPPoolProvider, error) {
+	var ranges []ipRange
+	for _, entry := range strings.Split(pool, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		r, err := parseIPRange(entry)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("load balancer IP pool %q is empty", pool)
+	}
+
+	return &IPPoolProvider{
+		ranges:   ranges,
+		assigned: make(map[uint32]string),
+		byID:     make(map[string]uint32),
+	}, nil
+}
+
+// parseIPRange parses a CIDR or a first-last address range
+func parseIPRange(entry string) (ipRange, error) {
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil || ipNet.IP.To4() == nil {
+			return ipRange{}, fmt.Errorf("invalid IPv4 CIDR %q", entry)
+		}
+		ones, bits := ipNet.Mask.Size()
+		first := ipToUint32(ipNet.IP)
+		last := first | (1<<(bits-ones) - 1)
+		if bits-ones > 1 {
+			first++
+			last--
+		}
+		return ipRange{first: first, last: last}, nil
+	}
+
+	low, high, ok := strings.Cut(entry, "-")
+	if !ok {
+		high = low
+	}
+	firstIP := net.ParseIP(strings.TrimSpace(low)).To4()
+	lastIP := net.ParseIP(strings.TrimSpace(high)).To4()
+	if firstIP == nil || lastIP == nil {
+		return ipRange{}, fmt.Errorf("invalid IPv4 range %q", entry)
+	}
+	r := ipRange{first: ipToUint32(firstIP), last: ipToUint32(lastIP)}
+	if r.first > r.last {
+		return ipRange{}, fmt.Errorf("invalid IPv4 range %q: first address is after last", entry)
+	}
+	return r, nil
+}
+
+// Name identifies the provider in logs
+func (p *IPPoolProvider) Name() string {
+	return "ip-pool"
+}
+
+// EnsureLoadBalancer keeps the service's address if it already has one from the pool that no
+// other service holds, and otherwise assigns the lowest free address
+func (p *IPPoolProvider) EnsureLoadBalancer(
+	_ context.Context, service types.Service,
+) (types.LoadBalancerStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if addr, ok := p.byID[service.ServiceID]; ok {
+		return ipStatus(addr), nil
+	}
+
+	for _, ip := range service.IngressIPs() {
+		parsed := net.ParseIP(ip).To4()
+		if parsed == nil {
+			continue
+		}
+		addr := ipToUint32(parsed)
+		if _, taken := p.assigned[addr]; !taken && p.contains(addr) {
+			p.assign(addr, service.ServiceID)
+			return ipStatus(addr), nil
+		}
+	}
+
+	for _, r := range p.ranges {
+		for addr := uint64(r.first); addr <= uint64(r.last); addr++ {
+			if _, taken := p.assigned[uint32(addr)]; !taken {
+				p.assign(uint32(addr), service.ServiceID)
+				return ipStatus(uint32(addr)), nil
+			}
+		}
+	}
+
+	return types.LoadBalancerStatus{}, ErrLoadBalancerPoolExhausted
+}
+
+// ReleaseLoadBalancer returns the service's address to the pool
+func (p *IPPoolProvider) ReleaseLoadBalancer(_ context.Context, serviceID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if addr, ok := p.byID[serviceID]; ok {
+		delete(p.assigned, addr)
+		delete(p.byID, serviceID)
+	}
+	return nil
+}
+
+// assign records that addr belongs to a service
+func (p *IPPoolProvider) assign(addr uint32, serviceID string) {
+	p.assigned[addr] = serviceID
+	p.byID[serviceID] = addr
+}
+
+// contains returns true if addr is in one of the pool's ranges
+func (p *IPPoolProvider) contains(addr uint32) bool {
+	for _, r := range p.ranges {
+		if addr >= r.first && addr <= r.last {
+			return true
+		}
+	}
+	return false
+}
+
+// ipStatus builds the load balancer status for a single address
+func ipStatus(addr uint32) types.LoadBalancerStatus {
+	return types.LoadBalancerStatus{
+		Ingress: []types.LoadBalancerIngress{{IP: uint32ToIP(addr).String()}},
+	}
+}
+
+// ipToUint32 converts an IPv4 address to an integer
+func ipToUint32(ip net.IP) uint32 {
+	return binary.BigEndian.Uint32(ip.To4())
+}
+
+// uint32ToIP converts an integer to an IPv4 address
+func uint32ToIP(addr uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, addr)
+	return ip
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestNewIPPoolProvider(t *testing.T) {
+	tests := []struct {
+		pool    string
+		want    []ipRange
+		wantErr bool
+	}{
+		{pool: "192.168.1.240/30", want: []ipRange{{first: 0xc0a801f1, last: 0xc0a801f2}}},
+		{pool: "192.168.1.240/31", want: []ipRange{{first: 0xc0a801f0, last: 0xc0a801f1}}},
+		{pool: "192.168.1.240/32", want: []ipRange{{first: 0xc0a801f0, last: 0xc0a801f0}}},
+		{
+			pool: "10.0.0.1-10.0.0.3, 10.0.0.9",
+			want: []ipRange{{first: 0x0a000001, last: 0x0a000003}, {first: 0x0a000009, last: 0x0a000009}},
+		},
+		{pool: "", wantErr: true},
+		{pool: "10.0.0.3-10.0.0.1", wantErr: true},
+		{pool: "fd00::/64", wantErr: true},
+		{pool: "not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.pool, func(t *testing.T) {
+				provider, err := NewIPPoolProvider(tt.pool)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("NewIPPoolProvider() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if err != nil {
+					return
+				}
+				if len(provider.ranges) != len(tt.want) {
+					t.Fatalf("ranges = %v, want %v", provider.ranges, tt.want)
+				}
+				for i := range tt.want {
+					if provider.ranges[i] != tt.want[i] {
+						t.Errorf("ranges[%d] = %v, want %v", i, provider.ranges[i], tt.want[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestIPPoolProvider(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewIPPoolProvider("10.0.0.1-10.0.0.2")
+	if err != nil {
+		t.Fatalf("NewIPPoolProvider() error = %v", err)
+	}
+
+	adopted := types.Service{ServiceID: "svc-old"}
+	adopted.Status.LoadBalancer.Ingress = []types.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	status, err := provider.EnsureLoadBalancer(ctx, adopted)
+	if err != nil || status.Ingress[0].IP != "10.0.0.2" {
+		t.Fatalf("EnsureLoadBalancer() of an assigned service = %v, %v, want 10.0.0.2", status, err)
+	}
+
+	status, err = provider.EnsureLoadBalancer(ctx, types.Service{ServiceID: "svc-new"})
+	if err != nil || status.Ingress[0].IP != "10.0.0.1" {
+		t.Fatalf("EnsureLoadBalancer() = %v, %v, want 10.0.0.1", status, err)
+	}
+
+	status, err = provider.EnsureLoadBalancer(ctx, types.Service{ServiceID: "svc-new"})
+	if err != nil || status.Ingress[0].IP != "10.0.0.1" {
+		t.Errorf("EnsureLoadBalancer() again = %v, %v, want the same address", status, err)
+	}
+
+	if _, err := provider.EnsureLoadBalancer(ctx, types.Service{ServiceID: "svc-3"}); !errors.Is(
+		err, ErrLoadBalancerPoolExhausted,
+	) {
+		t.Errorf("EnsureLoadBalancer() of a full pool error = %v, want ErrLoadBalancerPoolExhausted", err)
+	}
+
+	if err := provider.ReleaseLoadBalancer(ctx, "svc-old"); err != nil {
+		t.Fatalf("ReleaseLoadBalancer() error = %v", err)
+	}
+	status, err = provider.EnsureLoadBalancer(ctx, types.Service{ServiceID: "svc-3"})
+	if err != nil || status.Ingress[0].IP != "10.0.0.2" {
+		t.Errorf("EnsureLoadBalancer() after release = %v, %v, want 10.0.0.2", status, err)
+	}
+}
+
+func TestLoadBalancerController(t *testing.T) {
+	ctx := context.Background()
+	store := state.NewInMemoryStore()
+	provider, err := NewIPPoolProvider("10.0.0.1-10.0.0.2")
+	if err != nil {
+		t.Fatalf("NewIPPoolProvider() error = %v", err)
+	}
+	controller := NewLoadBalancerController(store, provider)
+
+	addService := func(id string, serviceType types.ServiceType, ingress ...string) {
+		t.Helper()
+		service := types.Service{ServiceID: id, Name: id, Type: serviceType, CreatedAt: time.Now()}
+		for _, ip := range ingress {
+			service.Status.LoadBalancer.Ingress = append(
+				service.Status.LoadBalancer.Ingress, types.LoadBalancerIngress{IP: ip},
+			)
+		}
+		if err := store.AddService(service); err != nil {
+			t.Fatalf("AddService() error = %v", err)
+		}
+	}
+	ingressOf := func(id string) []string {
+		t.Helper()
+		service, err := store.GetService(id)
+		if err != nil {
+			t.Fatalf("GetService() error = %v", err)
+		}
+		return service.IngressIPs()
+	}
+
+	// A new service must not take the address an existing service already has
+	addService("svc-new", types.ServiceTypeLoadBalancer)
+	addService("svc-old", types.ServiceTypeLoadBalancer, "10.0.0.1")
+	addService("svc-cluster", types.ServiceTypeClusterIP)
+
+	if err := controller.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := ingressOf("svc-old"); len(got) != 1 || got[0] != "10.0.0.1" {
+		t.Errorf("svc-old ingress = %v, want [10.0.0.1]", got)
+	}
+	if got := ingressOf("svc-new"); len(got) != 1 || got[0] != "10.0.0.2" {
+		t.Errorf("svc-new ingress = %v, want [10.0.0.2]", got)
+	}
+	if got := ingressOf("svc-cluster"); len(got) != 0 {
+		t.Errorf("svc-cluster ingress = %v, want none", got)
+	}
+
+	// Deleting a service frees its address for the next one
+	if err := store.DeleteService("svc-old"); err != nil {
+		t.Fatalf("DeleteService() error = %v", err)
+	}
+	addService("svc-next", types.ServiceTypeLoadBalancer)
+	if err := controller.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := ingressOf("svc-next"); len(got) != 1 || got[0] != "10.0.0.1" {
+		t.Errorf("svc-next ingress = %v, want [10.0.0.1]", got)
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+)
+
+var (
+	// ErrNodePortOutOfRange is returned when a requested node port is outside the allocation range
+	ErrNodePortOutOfRange = errors.New("node port out of range")
+	// ErrNodePortsExhausted is returned when every port in the range is allocated
+	ErrNodePortsExhausted = errors.New("no node ports available")
+)
+
+// PortRange is an inclusive range of ports
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// DefaultNodePortRange is the range node ports are allocated from unless configured otherwise
+var DefaultNodePortRange = PortRange{Min: 30000, Max: 32767}
+
+// ParsePortRange parses a range such as "30000-32767"
+func ParsePortRange(value string) (PortRange, error) {
+	low, high, ok := strings.Cut(value, "-")
+	if !ok {
+		return PortRange{}, fmt.Errorf("invalid port range %q: expected <min>-<max>", value)
+	}
+	lowPort, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+	highPort, err := strconv.Atoi(strings.TrimSpace(high))
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+
+	r := PortRange{Min: lowPort, Max: highPort}
+	if r.Min < 1 || r.Max > 65535 || r.Min > r.Max {
+		return PortRange{}, fmt.Errorf("invalid port range %q: must be within 1-65535 with min <= max", value)
+	}
+	return r, nil
+}
+
+// Contains returns true if port is in the range
+func (r PortRange) Contains(port int) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// Size returns the number of ports in the range
+func (r PortRange) Size() int {
+	return r.Max - r.Min + 1
+}
+
+// String formats the range as <min>-<max>
+func (r PortRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// NodePortAllocator hands out node ports from a range. Reservations live in the state store,
+// so they survive master restarts and two services can never hold the same port. TCP and UDP
+// ports are separate, so one service may hold a port number for TCP and another for UDP.
+type NodePortAllocator struct {
+	store     state.StateStore
+	portRange PortRange
+}
+
+// NewNodePortAllocator creates an allocator for ports in portRange
+func NewNodePortAllocator(store state.StateStore, portRange PortRange) *NodePortAllocator {
+	return &NodePortAllocator{store: store, portRange: portRange}
+}
+
+// Allocate reserves a node port of a protocol for a service. A requested port of zero picks a
+// free port at random; otherwise the requested port is reserved, failing with
+// state.ErrNodePortAllocated if another service holds it for the same protocol.
+func (a *NodePortAllocator) Allocate(serviceID, protocol string, requested int) (int, error) {
+	if requested != 0 {
+		if !a.portRange.Contains(requested) {
+			return 0, fmt.Errorf("%w: %d is not in %s", ErrNodePortOutOfRange, requested, a.portRange)
+		}
+		if err := a.store.ReserveNodePort(requested, protocol, serviceID); err != nil {
+			return 0, err
+		}
+		return requested, nil
+	}
+
+	used, err := a.store.ListNodePorts()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list node ports: %w", err)
+	}
+
+	size := a.portRange.Size()
+	offset := rand.IntN(size)
+	for i := 0; i < size; i++ {
+		port := a.portRange.Min + (offset+i)%size
+		if _, taken := used[state.NodePortKey{Port: port, Protocol: protocol}]; taken {
+			continue
+		}
+		err := a.store.ReserveNodePort(port, protocol, serviceID)
+		if errors.Is(err, state.ErrNodePortAllocated) {
+			// Taken by a concurrent allocation since the ports were listed
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return port, nil
+	}
+
+	return 0, fmt.Errorf("%w in %s", ErrNodePortsExhausted, a.portRange)
+}
+
+// Release frees a node port of a protocol
+func (a *NodePortAllocator) Release(port int, protocol string) error {
+	return a.store.ReleaseNodePort(port, protocol)
+}
+
+// ReleaseService frees every node port held by a service
+func (a *NodePortAllocator) ReleaseService(serviceID string) error {
+	ports, err := a.store.ListNodePorts()
+	if err != nil {
+		return fmt.Errorf("failed to list node ports: %w", err)
+	}
+
+	var errs []error
+	for key, owner := range ports {
+		if owner == serviceID {
+			errs = append(errs, a.store.ReleaseNodePort(key.Port, key.Protocol))
+		}
+	}
+	return errors.Join(errs...)
+}
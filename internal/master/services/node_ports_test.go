@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+)
+
+func TestParsePortRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    PortRange
+		wantErr bool
+	}{
+		{value: "30000-32767", want: PortRange{Min: 30000, Max: 32767}},
+		{value: "8000 - 8000", want: PortRange{Min: 8000, Max: 8000}},
+		{value: "30000", wantErr: true},
+		{value: "32767-30000", wantErr: true},
+		{value: "0-100", wantErr: true},
+		{value: "a-b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.value, func(t *testing.T) {
+				got, err := ParsePortRange(tt.value)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ParsePortRange() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("ParsePortRange() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestNodePortAllocator(t *testing.T) {
+	store := state.NewInMemoryStore()
+	allocator := NewNodePortAllocator(store, PortRange{Min: 30000, Max: 30002})
+
+	if _, err := allocator.Allocate("svc-1", "TCP", 30001); err != nil {
+		t.Fatalf("Allocate(30001) error = %v", err)
+	}
+	if _, err := allocator.Allocate("svc-4", "UDP", 30001); err != nil {
+		t.Errorf("Allocate() of a port held for another protocol error = %v", err)
+	}
+	if _, err := allocator.Allocate("svc-2", "TCP", 30001); !errors.Is(err, state.ErrNodePortAllocated) {
+		t.Errorf("Allocate() of a held port error = %v, want ErrNodePortAllocated", err)
+	}
+	if _, err := allocator.Allocate("svc-2", "TCP", 40000); !errors.Is(err, ErrNodePortOutOfRange) {
+		t.Errorf("Allocate() out of range error = %v, want ErrNodePortOutOfRange", err)
+	}
+
+	seen := map[int]bool{30001: true}
+	for i := 0; i < 2; i++ {
+		port, err := allocator.Allocate("svc-2", "TCP", 0)
+		if err != nil {
+			t.Fatalf("Allocate(0) error = %v", err)
+		}
+		if seen[port] {
+			t.Errorf("Allocate(0) returned port %d twice", port)
+		}
+		seen[port] = true
+	}
+	if _, err := allocator.Allocate("svc-3", "TCP", 0); !errors.Is(err, ErrNodePortsExhausted) {
+		t.Errorf("Allocate() from a full range error = %v, want ErrNodePortsExhausted", err)
+	}
+
+	if err := allocator.ReleaseService("svc-2"); err != nil {
+		t.Fatalf("ReleaseService() error = %v", err)
+	}
+	ports, _ := store.ListNodePorts()
+	tcp := state.NodePortKey{Port: 30001, Protocol: "TCP"}
+	udp := state.NodePortKey{Port: 30001, Protocol: "UDP"}
+	if len(ports) != 2 || ports[tcp] != "svc-1" || ports[udp] != "svc-4" {
+		t.Errorf("node ports after release = %v, want only svc-1's and svc-4's", ports)
+	}
+}
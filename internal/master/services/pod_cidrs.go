@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+)
+
+// ErrPodCIDRsExhausted is returned when every node subnet of the cluster pod CIDR is assigned
+var ErrPodCIDRsExhausted = errors.New("no pod CIDRs available")
+
+// DefaultPodCIDR is the cluster pod CIDR node subnets are carved from unless configured otherwise
+const DefaultPodCIDR = "10.244.0.0/16"
+
+// DefaultNodeCIDRMaskSize is the prefix length of the pod subnet assigned to each node
+const DefaultNodeCIDRMaskSize = 22
+
+// PodCIDRAllocator hands out a pod subnet of the cluster pod CIDR to every node. Assignments
+// live on the node records in the state store, so a node keeps its subnet across master and
+// worker restarts, and the subnet is free again once the node is removed.
+type PodCIDRAllocator struct {
+	store    state.StateStore
+	cluster  *net.IPNet
+	maskSize int
+
+	mu sync.Mutex
+	// pending holds subnets handed out to nodes that are not stored with them yet
+	pending map[string]string
+}
+
+// NewPodCIDRAllocator creates an allocator of /maskSize node subnets from the IPv4 clusterCIDR
+func NewPodCIDRAllocator(store state.StateStore, clusterCIDR string, maskSize int) (*PodCIDRAllocator, error) {
+	_, cluster, err := net.ParseCIDR(clusterCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod CIDR %q: %w", clusterCIDR, err)
+	}
+	if cluster.IP.To4() == nil {
+		return nil, fmt.Errorf("invalid pod CIDR %q: only IPv4 is supported", clusterCIDR)
+	}
+	clusterBits, _ := cluster.Mask.Size()
+	if maskSize < clusterBits || maskSize > 30 {
+		return nil, fmt.Errorf("node CIDR mask size %d must be between %d and 30", maskSize, clusterBits)
+	}
+
+	return &PodCIDRAllocator{
+		store:    store,
+		cluster:  cluster,
+		maskSize: maskSize,
+		pending:  make(map[string]string),
+	}, nil
+}
+
+// ClusterCIDR returns the cluster pod CIDR
+func (a *PodCIDRAllocator) ClusterCIDR() string {
+	return a.cluster.String()
+}
+
+// Allocate returns the node's pod subnet, assigning the first free one if the node has none.
+// The caller stores the subnet on the node record.
+func (a *PodCIDRAllocator) Allocate(nodeID string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	nodes, err := a.store.ListNodes()
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, node := range nodes {
+		if node.PodCIDR == "" {
+			continue
+		}
+		if node.NodeID == nodeID && a.owns(node.PodCIDR) {
+			delete(a.pending, nodeID)
+			return node.PodCIDR, nil
+		}
+		used[node.PodCIDR] = true
+		if a.pending[node.NodeID] == node.PodCIDR {
+			delete(a.pending, node.NodeID)
+		}
+	}
+
+	if cidr, ok := a.pending[nodeID]; ok {
+		return cidr, nil
+	}
+	for _, cidr := range a.pending {
+		used[cidr] = true
+	}
+
+	clusterBits, _ := a.cluster.Mask.Size()
+	count := 1 << (a.maskSize - clusterBits)
+	for i := 0; i < count; i++ {
+		cidr := a.subnet(i)
+		if used[cidr] {
+			continue
+		}
+		a.pending[nodeID] = cidr
+		return cidr, nil
+	}
+
+	return "", fmt.Errorf("%w in %s", ErrPodCIDRsExhausted, a.cluster)
+}
+
+// Release forgets a subnet handed out to a node that was never stored
+func (a *PodCIDRAllocator) Release(nodeID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.pending, nodeID)
+}
+
+// owns returns true if cidr is a node subnet of the cluster pod CIDR
+func (a *PodCIDRAllocator) owns(cidr string) bool {
+	ip, subnet, err := net.ParseCIDR(cidr)
+	if err != nil || !ip.Equal(subnet.IP) || !a.cluster.Contains(ip) {
+		return false
+	}
+	bits, _ := subnet.Mask.Size()
+	return bits == a.maskSize
+}
+
+// subnet returns the index-th node subnet of the cluster pod CIDR
+func (a *PodCIDRAllocator) subnet(index int) string {
+	ip := make(net.IP, 4)
+	base := binary.BigEndian.Uint32(a.cluster.IP.To4())
+	binary.BigEndian.PutUint32(ip, base+uint32(index)<<(32-a.maskSize))
+	return fmt.Sprintf("%s/%d", ip, a.maskSize)
+}
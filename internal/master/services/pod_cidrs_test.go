@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/master/state"
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestNewPodCIDRAllocator(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidr     string
+		maskSize int
+		wantErr  bool
+	}{
+		{name: "default", cidr: DefaultPodCIDR, maskSize: DefaultNodeCIDRMaskSize},
+		{name: "single node subnet", cidr: "10.0.0.0/24", maskSize: 24},
+		{name: "invalid CIDR", cidr: "10.0.0.0", maskSize: 24, wantErr: true},
+		{name: "IPv6", cidr: "fd00::/48", maskSize: 64, wantErr: true},
+		{name: "mask shorter than cluster", cidr: "10.0.0.0/16", maskSize: 8, wantErr: true},
+		{name: "mask too long", cidr: "10.0.0.0/16", maskSize: 31, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := NewPodCIDRAllocator(state.NewInMemoryStore(), tt.cidr, tt.maskSize)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("NewPodCIDRAllocator() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestPodCIDRAllocator(t *testing.T) {
+	store := state.NewInMemoryStore()
+	allocator, err := NewPodCIDRAllocator(store, "10.244.0.0/23", 24)
+	if err != nil {
+		t.Fatalf("NewPodCIDRAllocator() error = %v", err)
+	}
+
+	first, err := allocator.Allocate("node-1")
+	if err != nil {
+		t.Fatalf("Allocate(node-1) error = %v", err)
+	}
+	if first != "10.244.0.0/24" {
+		t.Errorf("Allocate(node-1) = %s, want 10.244.0.0/24", first)
+	}
+	if again, _ := allocator.Allocate("node-1"); again != first {
+		t.Errorf("Allocate(node-1) again = %s, want the pending %s", again, first)
+	}
+
+	// A subnet handed out but not yet stored is not given to another node
+	second, err := allocator.Allocate("node-2")
+	if err != nil {
+		t.Fatalf("Allocate(node-2) error = %v", err)
+	}
+	if second != "10.244.1.0/24" {
+		t.Errorf("Allocate(node-2) = %s, want 10.244.1.0/24", second)
+	}
+	if _, err := allocator.Allocate("node-3"); !errors.Is(err, ErrPodCIDRsExhausted) {
+		t.Errorf("Allocate() from a full CIDR error = %v, want ErrPodCIDRsExhausted", err)
+	}
+
+	_ = store.AddNode(types.Node{NodeID: "node-1", PodCIDR: first})
+	allocator.Release("node-2")
+	third, err := allocator.Allocate("node-3")
+	if err != nil {
+		t.Fatalf("Allocate(node-3) error = %v", err)
+	}
+	if third != second {
+		t.Errorf("Allocate(node-3) = %s, want the released %s", third, second)
+	}
+
+	// Removing a node frees its subnet
+	_ = store.DeleteNode("node-1")
+	if got, _ := allocator.Allocate("node-4"); got != first {
+		t.Errorf("Allocate(node-4) = %s, want the freed %s", got, first)
+	}
+}
+
+func TestPodCIDRAllocatorKeepsStoredSubnet(t *testing.T) {
+	store := state.NewInMemoryStore()
+	_ = store.AddNode(types.Node{NodeID: "node-1", PodCIDR: "10.244.12.0/22"})
+	_ = store.AddNode(types.Node{NodeID: "node-2", PodCIDR: "192.168.0.0/24"})
+
+	allocator, err := NewPodCIDRAllocator(store, DefaultPodCIDR, DefaultNodeCIDRMaskSize)
+	if err != nil {
+		t.Fatalf("NewPodCIDRAllocator() error = %v", err)
+	}
+
+	if got, _ := allocator.Allocate("node-1"); got != "10.244.12.0/22" {
+		t.Errorf("Allocate(node-1) = %s, want its stored 10.244.12.0/22", got)
+	}
+	// Subnets outside the cluster pod CIDR, for example after it was changed, are replaced
+	if got, _ := allocator.Allocate("node-2"); got != "10.244.0.0/22" {
+		t.Errorf("Allocate(node-2) = %s, want 10.244.0.0/22", got)
+	}
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestInMemoryStore_Ingresses(t *testing.T) {
+	store := NewInMemoryStore()
+
+	created := time.Now().Add(-time.Hour)
+	ingress := types.Ingress{
+		IngressID: "ing-1",
+		Name:      "web",
+		Namespace: "default",
+		Rules: []types.IngressRule{
+			{
+				Host: "example.com",
+				Paths: []types.IngressPath{
+					{Path: "/", Backend: types.IngressBackend{ServiceName: "web", ServicePort: 80}},
+				},
+			},
+		},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+
+	if err := store.AddIngress(ingress); err != nil {
+		t.Fatalf("AddIngress failed: %v", err)
+	}
+
+	duplicate := ingress
+	duplicate.IngressID = "ing-2"
+	if err := store.AddIngress(duplicate); !errors.Is(err, ErrIngressAlreadyExists) {
+		t.Errorf("expected ErrIngressAlreadyExists for duplicate name, got %v", err)
+	}
+
+	update := ingress
+	update.Name = "renamed"
+	update.DefaultBackend = &types.IngressBackend{ServiceName: "fallback", ServicePort: 8080}
+	if err := store.UpdateIngress(update); err != nil {
+		t.Fatalf("UpdateIngress failed: %v", err)
+	}
+	got, err := store.GetIngress("ing-1")
+	if err != nil {
+		t.Fatalf("GetIngress failed: %v", err)
+	}
+	if got.Name != "web" || got.DefaultBackend == nil || !got.CreatedAt.Equal(created) || !got.UpdatedAt.After(created) {
+		t.Errorf("expected the spec to change and name and creation time to be kept, got %+v", got)
+	}
+
+	if ingresses, _ := store.ListIngresses("other"); len(ingresses) != 0 {
+		t.Errorf("expected no ingresses in other namespace, got %d", len(ingresses))
+	}
+	if ingresses, _ := store.ListIngresses(""); len(ingresses) != 1 {
+		t.Errorf("expected 1 ingress, got %d", len(ingresses))
+	}
+
+	if err := store.DeleteIngress("ing-1"); err != nil {
+		t.Fatalf("DeleteIngress failed: %v", err)
+	}
+	if err := store.UpdateIngress(update); !errors.Is(err, ErrIngressNotFound) {
+		t.Errorf("expected ErrIngressNotFound updating a deleted ingress, got %v", err)
+	}
+	if err := store.DeleteIngress("ing-1"); !errors.Is(err, ErrIngressNotFound) {
+		t.Errorf("expected ErrIngressNotFound on second delete, got %v", err)
+	}
+}
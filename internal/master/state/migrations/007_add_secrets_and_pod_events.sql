@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS secrets (
+    secret_id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    namespace VARCHAR(255) NOT NULL DEFAULT 'default',
+    type VARCHAR(50) NOT NULL,
+    data JSONB,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE(namespace, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_secrets_namespace ON secrets(namespace);
+
+ALTER TABLE pods ADD COLUMN IF NOT EXISTS image_pull_secrets JSONB;
+ALTER TABLE pods ADD COLUMN IF NOT EXISTS events JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pods DROP COLUMN IF EXISTS events;
+ALTER TABLE pods DROP COLUMN IF EXISTS image_pull_secrets;
+DROP INDEX IF EXISTS idx_secrets_namespace;
+DROP TABLE IF EXISTS secrets;
+-- +goose StatementEnd
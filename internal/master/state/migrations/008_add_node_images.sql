@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS images JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE nodes DROP COLUMN IF EXISTS images;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pods ADD COLUMN IF NOT EXISTS security_context JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pods DROP COLUMN IF EXISTS security_context;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pods ADD COLUMN IF NOT EXISTS qos_class VARCHAR(50);
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS conditions JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE nodes DROP COLUMN IF EXISTS conditions;
+ALTER TABLE pods DROP COLUMN IF EXISTS qos_class;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS unschedulable BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE nodes DROP COLUMN IF EXISTS unschedulable;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS usage JSONB;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS reported_pods JSONB;
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS reported_tasks JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE nodes DROP COLUMN IF EXISTS reported_tasks;
+ALTER TABLE nodes DROP COLUMN IF EXISTS reported_pods;
+ALTER TABLE nodes DROP COLUMN IF EXISTS usage;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS node_ports (
+    node_port INTEGER PRIMARY KEY,
+    service_id VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_ports_service_id ON node_ports(service_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_node_ports_service_id;
+DROP TABLE IF EXISTS node_ports;
+-- +goose StatementEnd
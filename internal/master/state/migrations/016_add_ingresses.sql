@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ingresses (
+    ingress_id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    namespace VARCHAR(255) NOT NULL DEFAULT 'default',
+    rules JSONB,
+    default_backend JSONB,
+    tls JSONB,
+    labels JSONB,
+    annotations JSONB,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE(namespace, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingresses_namespace ON ingresses(namespace);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_ingresses_namespace;
+DROP TABLE IF EXISTS ingresses;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS pod_cidr VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS internal_ip VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE nodes DROP COLUMN IF EXISTS internal_ip;
+ALTER TABLE nodes DROP COLUMN IF EXISTS pod_cidr;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS network_policies (
+    policy_id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    namespace VARCHAR(255) NOT NULL DEFAULT 'default',
+    pod_selector JSONB,
+    policy_types JSONB,
+    ingress JSONB,
+    egress JSONB,
+    labels JSONB,
+    annotations JSONB,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE(namespace, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_network_policies_namespace ON network_policies(namespace);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_network_policies_namespace;
+DROP TABLE IF EXISTS network_policies;
+-- +goose StatementEnd
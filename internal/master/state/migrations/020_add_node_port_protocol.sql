@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- TCP and UDP node ports are reserved separately; existing reservations are rebuilt from the
+-- services' ports, so each keeps the protocol it is used with
+ALTER TABLE node_ports ADD COLUMN IF NOT EXISTS protocol VARCHAR(10) NOT NULL DEFAULT 'TCP';
+ALTER TABLE node_ports DROP CONSTRAINT IF EXISTS node_ports_pkey;
+ALTER TABLE node_ports ADD PRIMARY KEY (node_port, protocol);
+
+DELETE FROM node_ports;
+INSERT INTO node_ports (node_port, protocol, service_id)
+SELECT DISTINCT (port->>'nodePort')::INTEGER, COALESCE(NULLIF(port->>'protocol', ''), 'TCP'), services.service_id
+FROM services, jsonb_array_elements(services.ports) AS port
+WHERE COALESCE((port->>'nodePort')::INTEGER, 0) > 0
+ON CONFLICT (node_port, protocol) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM node_ports a USING node_ports b
+WHERE a.node_port = b.node_port AND a.protocol > b.protocol;
+ALTER TABLE node_ports DROP CONSTRAINT IF EXISTS node_ports_pkey;
+ALTER TABLE node_ports DROP COLUMN IF EXISTS protocol;
+ALTER TABLE node_ports ADD PRIMARY KEY (node_port);
+-- +goose StatementEnd
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestInMemoryStore_NetworkPolicies(t *testing.T) {
+	store := NewInMemoryStore()
+
+	created := time.Now().Add(-time.Hour)
+	policy := types.NetworkPolicy{
+		PolicyID:    "np-1",
+		Name:        "api",
+		Namespace:   "default",
+		PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+
+	if err := store.AddNetworkPolicy(policy); err != nil {
+		t.Fatalf("AddNetworkPolicy failed: %v", err)
+	}
+
+	duplicate := policy
+	duplicate.PolicyID = "np-2"
+	if err := store.AddNetworkPolicy(duplicate); !errors.Is(err, ErrNetworkPolicyAlreadyExists) {
+		t.Errorf("expected ErrNetworkPolicyAlreadyExists for duplicate name, got %v", err)
+	}
+
+	update := policy
+	update.Name = "renamed"
+	update.Ingress = []types.NetworkPolicyIngressRule{{Ports: []types.NetworkPolicyPort{{Port: 8080}}}}
+	if err := store.UpdateNetworkPolicy(update); err != nil {
+		t.Fatalf("UpdateNetworkPolicy failed: %v", err)
+	}
+	got, err := store.GetNetworkPolicy("np-1")
+	if err != nil {
+		t.Fatalf("GetNetworkPolicy failed: %v", err)
+	}
+	if got.Name != "api" || len(got.Ingress) != 1 || !got.CreatedAt.Equal(created) || !got.UpdatedAt.After(created) {
+		t.Errorf("expected the spec to change and name and creation time to be kept, got %+v", got)
+	}
+
+	if policies, _ := store.ListNetworkPolicies("other"); len(policies) != 0 {
+		t.Errorf("expected no network policies in other namespace, got %d", len(policies))
+	}
+	if policies, _ := store.ListNetworkPolicies(""); len(policies) != 1 {
+		t.Errorf("expected 1 network policy, got %d", len(policies))
+	}
+
+	if err := store.DeleteNetworkPolicy("np-1"); err != nil {
+		t.Fatalf("DeleteNetworkPolicy failed: %v", err)
+	}
+	if err := store.UpdateNetworkPolicy(update); !errors.Is(err, ErrNetworkPolicyNotFound) {
+		t.Errorf("expected ErrNetworkPolicyNotFound updating a deleted policy, got %v", err)
+	}
+	if err := store.DeleteNetworkPolicy("np-1"); !errors.Is(err, ErrNetworkPolicyNotFound) {
+		t.Errorf("expected ErrNetworkPolicyNotFound on second delete, got %v", err)
+	}
+}
@@ -405,6 +405,124 @@ func (s *PostgresStore) DeleteTask(taskID string) error {
 	return nil
 }
 
+// podColumns is the column list shared by all pod queries
+const podColumns = `pod_id, name, namespace, labels, annotations, containers, status, node_id, restart_policy,
+	created_at, scheduled_at, started_at, finished_at, message, reason, image_pull_secrets, events,
+	security_context, qos_class`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPod reads a single pod row selected with podColumns
+func scanPod(row rowScanner) (types.Pod, error) {
+	var pod types.Pod
+	var labelsJSON, annotationsJSON, containersJSON, pullSecretsJSON, eventsJSON, securityJSON []byte
+	var namespace, nodeID, restartPolicy, message, reason, qosClass sql.NullString
+
+	err := row.Scan(
+		&pod.PodID,
+		&pod.Name,
+		&namespace,
+		&labelsJSON,
+		&annotationsJSON,
+		&containersJSON,
+		&pod.Status,
+		&nodeID,
+		&restartPolicy,
+		&pod.CreatedAt,
+		&pod.ScheduledAt,
+		&pod.StartedAt,
+		&pod.FinishedAt,
+		&message,
+		&reason,
+		&pullSecretsJSON,
+		&eventsJSON,
+		&securityJSON,
+		&qosClass,
+	)
+	if err != nil {
+		return types.Pod{}, err
+	}
+
+	pod.Labels = make(map[string]string)
+	if len(labelsJSON) > 0 && string(labelsJSON) != "null" {
+		if err := json.Unmarshal(labelsJSON, &pod.Labels); err != nil {
+			return types.Pod{}, fmt.Errorf("failed to unmarshal labels: %w", err)
+		}
+	}
+
+	pod.Annotations = make(map[string]string)
+	if len(annotationsJSON) > 0 && string(annotationsJSON) != "null" {
+		var testArray []interface{}
+		if json.Unmarshal(annotationsJSON, &testArray) != nil {
+			if err := json.Unmarshal(annotationsJSON, &pod.Annotations); err != nil {
+				return types.Pod{}, fmt.Errorf("failed to unmarshal annotations: %w", err)
+			}
+		}
+	}
+
+	if err := json.Unmarshal(containersJSON, &pod.Containers); err != nil {
+		return types.Pod{}, fmt.Errorf("failed to unmarshal containers: %w", err)
+	}
+
+	if len(pullSecretsJSON) > 0 {
+		if err := json.Unmarshal(pullSecretsJSON, &pod.ImagePullSecrets); err != nil {
+			return types.Pod{}, fmt.Errorf("failed to unmarshal image pull secrets: %w", err)
+		}
+	}
+
+	if len(eventsJSON) > 0 {
+		if err := json.Unmarshal(eventsJSON, &pod.Events); err != nil {
+			return types.Pod{}, fmt.Errorf("failed to unmarshal events: %w", err)
+		}
+	}
+
+	if len(securityJSON) > 0 && string(securityJSON) != "null" {
+		if err := json.Unmarshal(securityJSON, &pod.SecurityContext); err != nil {
+			return types.Pod{}, fmt.Errorf("failed to unmarshal security context: %w", err)
+		}
+	}
+
+	pod.Namespace = namespace.String
+	pod.NodeID = nodeID.String
+	pod.Message = message.String
+	pod.Reason = reason.String
+	if restartPolicy.Valid {
+		pod.RestartPolicy = types.RestartPolicy(restartPolicy.String)
+	}
+	pod.QOSClass = types.QOSClass(qosClass.String)
+
+	return pod, nil
+}
+
+// queryPods runs a pod query and scans all resulting rows
+func (s *PostgresStore) queryPods(query string, args ...interface{}) ([]types.Pod, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pods: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	var pods []types.Pod
+	for rows.Next() {
+		pod, err := scanPod(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pod: %w", err)
+		}
+		pods = append(pods, pod)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pods: %w", err)
+	}
+
+	return pods, nil
+}
+
 // AddPod adds a new pod to the store
 func (s *PostgresStore) AddPod(pod types.Pod) error {
 	var exists bool
@@ -431,9 +549,24 @@ func (s *PostgresStore) AddPod(pod types.Pod) error {
 		return fmt.Errorf("failed to marshal containers: %w", err)
 	}
 
+	pullSecretsJSON, err := json.Marshal(pod.ImagePullSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal image pull secrets: %w", err)
+	}
+
+	eventsJSON, err := json.Marshal(pod.Events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	securityJSON, err := json.Marshal(pod.SecurityContext)
+	if err != nil {
+		return fmt.Errorf("failed to marshal security context: %w", err)
+	}
+
 	query := `
-		INSERT INTO pods (pod_id, name, namespace, labels, annotations, containers, status, node_id, restart_policy, created_at, scheduled_at, started_at, finished_at, message, reason)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		INSERT INTO pods (` + podColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	_, err = s.db.Exec(
@@ -453,6 +586,10 @@ func (s *PostgresStore) AddPod(pod types.Pod) error {
 		pod.FinishedAt,
 		nullString(pod.Message),
 		nullString(pod.Reason),
+		pullSecretsJSON,
+		eventsJSON,
+		securityJSON,
+		nullString(string(pod.QOSClass)),
 	)
 
 	if err != nil {
@@ -464,34 +601,9 @@ func (s *PostgresStore) AddPod(pod types.Pod) error {
 
 // GetPod retrieves a pod by ID
 func (s *PostgresStore) GetPod(podID string) (types.Pod, error) {
-	query := `
-		SELECT pod_id, name, namespace, labels, annotations, containers, status, node_id, restart_policy, created_at, scheduled_at, started_at, finished_at, message, reason
-		FROM pods
-		WHERE pod_id = $1
-	`
-
-	var pod types.Pod
-	var labelsJSON, annotationsJSON, containersJSON []byte
-	var namespace, nodeID, restartPolicy, message, reason sql.NullString
-
-	err := s.db.QueryRow(query, podID).Scan(
-		&pod.PodID,
-		&pod.Name,
-		&namespace,
-		&labelsJSON,
-		&annotationsJSON,
-		&containersJSON,
-		&pod.Status,
-		&nodeID,
-		&restartPolicy,
-		&pod.CreatedAt,
-		&pod.ScheduledAt,
-		&pod.StartedAt,
-		&pod.FinishedAt,
-		&message,
-		&reason,
-	)
+	query := `SELECT ` + podColumns + ` FROM pods WHERE pod_id = $1`
 
+	pod, err := scanPod(s.db.QueryRow(query, podID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return types.Pod{}, ErrPodNotFound
 	}
@@ -499,36 +611,6 @@ func (s *PostgresStore) GetPod(podID string) (types.Pod, error) {
 		return types.Pod{}, fmt.Errorf("failed to get pod: %w", err)
 	}
 
-	if len(labelsJSON) > 0 {
-		if err := json.Unmarshal(labelsJSON, &pod.Labels); err != nil {
-			return types.Pod{}, fmt.Errorf("failed to unmarshal labels: %w", err)
-		}
-	}
-
-	pod.Annotations = make(map[string]string)
-	if len(annotationsJSON) > 0 {
-		var testArray []interface{}
-		if json.Unmarshal(annotationsJSON, &testArray) == nil {
-			pod.Annotations = make(map[string]string)
-		} else {
-			if err := json.Unmarshal(annotationsJSON, &pod.Annotations); err != nil {
-				return types.Pod{}, fmt.Errorf("failed to unmarshal annotations: %w", err)
-			}
-		}
-	}
-
-	if err := json.Unmarshal(containersJSON, &pod.Containers); err != nil {
-		return types.Pod{}, fmt.Errorf("failed to unmarshal containers: %w", err)
-	}
-
-	pod.Namespace = namespace.String
-	pod.NodeID = nodeID.String
-	pod.Message = message.String
-	pod.Reason = reason.String
-	if restartPolicy.Valid {
-		pod.RestartPolicy = types.RestartPolicy(restartPolicy.String)
-	}
-
 	return pod, nil
 }
 
@@ -604,6 +686,24 @@ func (s *PostgresStore) UpdatePod(podID string, updates PodUpdate) error {
 		args = append(args, annotationsJSON)
 		argPos++
 	}
+	if len(updates.Events) > 0 {
+		// Append new events and keep only the most recent MaxPodEvents entries
+		eventsJSON, err := json.Marshal(updates.Events)
+		if err != nil {
+			return fmt.Errorf("failed to marshal events: %w", err)
+		}
+		query += fmt.Sprintf(
+			`events = (
+				SELECT COALESCE(jsonb_agg(e ORDER BY idx), '[]'::jsonb) FROM (
+					SELECT e, idx FROM jsonb_array_elements(COALESCE(events, '[]'::jsonb) || $%d) WITH ORDINALITY AS t(e, idx)
+					ORDER BY idx DESC LIMIT %d
+				) recent
+			), `,
+			argPos, types.MaxPodEvents,
+		)
+		args = append(args, eventsJSON)
+		argPos++
+	}
 
 	query = query[:len(query)-2]
 	query += fmt.Sprintf(" WHERE pod_id = $%d", argPos)
@@ -619,107 +719,119 @@ func (s *PostgresStore) UpdatePod(podID string, updates PodUpdate) error {
 
 // ListPods returns all pods in the store
 func (s *PostgresStore) ListPods() ([]types.Pod, error) {
-	query := `
-		SELECT pod_id, name, namespace, labels, annotations, containers, status, node_id, restart_policy, created_at, scheduled_at, started_at, finished_at, message, reason
-		FROM pods
-		ORDER BY created_at DESC
-	`
+	return s.queryPods(`SELECT ` + podColumns + ` FROM pods ORDER BY created_at DESC`)
+}
 
-	rows, err := s.db.Query(query)
+// DeletePod removes a pod from the store
+func (s *PostgresStore) DeletePod(podID string) error {
+	result, err := s.db.Exec("DELETE FROM pods WHERE pod_id = $1", podID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pods: %w", err)
+		return fmt.Errorf("failed to delete pod: %w", err)
 	}
-	defer func(rows *sql.Rows) {
-		_ = rows.Close()
-	}(rows)
 
-	var pods []types.Pod
-	for rows.Next() {
-		var pod types.Pod
-		var labelsJSON, annotationsJSON, containersJSON []byte
-		var namespace, nodeID, restartPolicy, message, reason sql.NullString
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
 
-		err := rows.Scan(
-			&pod.PodID,
-			&pod.Name,
-			&namespace,
-			&labelsJSON,
-			&annotationsJSON,
-			&containersJSON,
-			&pod.Status,
-			&nodeID,
-			&restartPolicy,
-			&pod.CreatedAt,
-			&pod.ScheduledAt,
-			&pod.StartedAt,
-			&pod.FinishedAt,
-			&message,
-			&reason,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan pod: %w", err)
-		}
+	if rowsAffected == 0 {
+		return ErrPodNotFound
+	}
 
-		if len(labelsJSON) > 0 && string(labelsJSON) != "null" {
-			pod.Labels = make(map[string]string)
-			if err := json.Unmarshal(labelsJSON, &pod.Labels); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
-			}
-		} else {
-			pod.Labels = make(map[string]string)
-		}
-
-		pod.Annotations = make(map[string]string)
-		if len(annotationsJSON) > 0 && string(annotationsJSON) != "null" {
-			var testArray []interface{}
-			if json.Unmarshal(annotationsJSON, &testArray) == nil {
-				pod.Annotations = make(map[string]string)
-			} else {
-				if err := json.Unmarshal(annotationsJSON, &pod.Annotations); err != nil {
-					return nil, fmt.Errorf("failed to unmarshal annotations: %w", err)
-				}
-			}
+	return nil
+}
+
+// nodeColumns is the column list shared by all node queries
+const nodeColumns = `node_id, hostname, port, status, running_tasks, last_heartbeat, resources, images, conditions,
+	unschedulable, usage, reported_pods, reported_tasks, pod_cidr, internal_ip`
+
+// scanNode reads a single node row selected with nodeColumns
+func scanNode(row rowScanner) (types.Node, error) {
+	var node types.Node
+	var resourcesJSON, imagesJSON, conditionsJSON, usageJSON, podsJSON, tasksJSON []byte
+
+	err := row.Scan(
+		&node.NodeID,
+		&node.Hostname,
+		&node.Port,
+		&node.Status,
+		&node.RunningTasks,
+		&node.LastHeartbeat,
+		&resourcesJSON,
+		&imagesJSON,
+		&conditionsJSON,
+		&node.Unschedulable,
+		&usageJSON,
+		&podsJSON,
+		&tasksJSON,
+		&node.PodCIDR,
+		&node.InternalIP,
+	)
+	if err != nil {
+		return types.Node{}, err
+	}
+
+	if err := json.Unmarshal(resourcesJSON, &node.Resources); err != nil {
+		return types.Node{}, fmt.Errorf("failed to unmarshal resources: %w", err)
+	}
+
+	if len(imagesJSON) > 0 {
+		if err := json.Unmarshal(imagesJSON, &node.Images); err != nil {
+			return types.Node{}, fmt.Errorf("failed to unmarshal images: %w", err)
 		}
+	}
 
-		if err := json.Unmarshal(containersJSON, &pod.Containers); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal containers: %w", err)
+	if len(conditionsJSON) > 0 {
+		if err := json.Unmarshal(conditionsJSON, &node.Conditions); err != nil {
+			return types.Node{}, fmt.Errorf("failed to unmarshal conditions: %w", err)
 		}
+	}
 
-		pod.Namespace = namespace.String
-		pod.NodeID = nodeID.String
-		pod.Message = message.String
-		pod.Reason = reason.String
-		if restartPolicy.Valid {
-			pod.RestartPolicy = types.RestartPolicy(restartPolicy.String)
+	if len(usageJSON) > 0 {
+		if err := json.Unmarshal(usageJSON, &node.Usage); err != nil {
+			return types.Node{}, fmt.Errorf("failed to unmarshal usage: %w", err)
 		}
+	}
 
-		pods = append(pods, pod)
+	if len(podsJSON) > 0 {
+		if err := json.Unmarshal(podsJSON, &node.Pods); err != nil {
+			return types.Node{}, fmt.Errorf("failed to unmarshal reported pods: %w", err)
+		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating pods: %w", err)
+	if len(tasksJSON) > 0 {
+		if err := json.Unmarshal(tasksJSON, &node.Tasks); err != nil {
+			return types.Node{}, fmt.Errorf("failed to unmarshal reported tasks: %w", err)
+		}
 	}
 
-	return pods, nil
+	return node, nil
 }
 
-// DeletePod removes a pod from the store
-func (s *PostgresStore) DeletePod(podID string) error {
-	result, err := s.db.Exec("DELETE FROM pods WHERE pod_id = $1", podID)
+// queryNodes runs a node query and scans all resulting rows
+func (s *PostgresStore) queryNodes(query string, args ...interface{}) ([]types.Node, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete pod: %w", err)
+		return nil, fmt.Errorf("failed to query nodes: %w", err)
 	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	var nodes []types.Node
+	for rows.Next() {
+		node, err := scanNode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan node: %w", err)
+		}
+		nodes = append(nodes, node)
 	}
 
-	if rowsAffected == 0 {
-		return ErrPodNotFound
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating nodes: %w", err)
 	}
 
-	return nil
+	return nodes, nil
 }
 
 // AddNode adds a new node to the store
@@ -734,8 +846,8 @@ func (s *PostgresStore) AddNode(node types.Node) error {
 	}
 
 	query := `
-		INSERT INTO nodes (node_id, hostname, port, status, running_tasks, last_heartbeat, resources)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO nodes (` + nodeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	resourcesJSON, err := json.Marshal(node.Resources)
@@ -743,6 +855,31 @@ func (s *PostgresStore) AddNode(node types.Node) error {
 		return fmt.Errorf("failed to marshal resources: %w", err)
 	}
 
+	imagesJSON, err := json.Marshal(node.Images)
+	if err != nil {
+		return fmt.Errorf("failed to marshal images: %w", err)
+	}
+
+	conditionsJSON, err := json.Marshal(node.Conditions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conditions: %w", err)
+	}
+
+	usageJSON, err := json.Marshal(node.Usage)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	podsJSON, err := json.Marshal(node.Pods)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reported pods: %w", err)
+	}
+
+	tasksJSON, err := json.Marshal(node.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reported tasks: %w", err)
+	}
+
 	_, err = s.db.Exec(
 		query,
 		node.NodeID,
@@ -752,6 +889,14 @@ func (s *PostgresStore) AddNode(node types.Node) error {
 		node.RunningTasks,
 		node.LastHeartbeat,
 		resourcesJSON,
+		imagesJSON,
+		conditionsJSON,
+		node.Unschedulable,
+		usageJSON,
+		podsJSON,
+		tasksJSON,
+		node.PodCIDR,
+		node.InternalIP,
 	)
 
 	if err != nil {
@@ -763,30 +908,9 @@ func (s *PostgresStore) AddNode(node types.Node) error {
 
 // GetNode retrieves a node by ID
 func (s *PostgresStore) GetNode(nodeID string) (types.Node, error) {
-	query := `
-		SELECT node_id, hostname, port, status, running_tasks, last_heartbeat, resources
-		FROM nodes
-		WHERE node_id = $1
-	`
-
-	var node types.Node
-	var resourcesJSON []byte
-	err := s.db.QueryRow(query, nodeID).Scan(
-		&node.NodeID,
-		&node.Hostname,
-		&node.Port,
-		&node.Status,
-		&node.RunningTasks,
-		&node.LastHeartbeat,
-		&resourcesJSON,
-	)
-
-	if err == nil {
-		if err := json.Unmarshal(resourcesJSON, &node.Resources); err != nil {
-			return types.Node{}, fmt.Errorf("failed to unmarshal resources: %w", err)
-		}
-	}
+	query := `SELECT ` + nodeColumns + ` FROM nodes WHERE node_id = $1`
 
+	node, err := scanNode(s.db.QueryRow(query, nodeID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return types.Node{}, ErrNodeNotFound
 	}
@@ -827,6 +951,85 @@ func (s *PostgresStore) UpdateNode(nodeID string, updates NodeUpdate) error {
 		args = append(args, *updates.LastHeartbeat)
 		argPos++
 	}
+	if updates.Images != nil {
+		imagesJSON, err := json.Marshal(*updates.Images)
+		if err != nil {
+			return fmt.Errorf("failed to marshal images: %w", err)
+		}
+		query += fmt.Sprintf("images = $%d, ", argPos)
+		args = append(args, imagesJSON)
+		argPos++
+	}
+	if updates.Conditions != nil {
+		conditionsJSON, err := json.Marshal(*updates.Conditions)
+		if err != nil {
+			return fmt.Errorf("failed to marshal conditions: %w", err)
+		}
+		query += fmt.Sprintf("conditions = $%d, ", argPos)
+		args = append(args, conditionsJSON)
+		argPos++
+	}
+	if updates.Unschedulable != nil {
+		query += fmt.Sprintf("unschedulable = $%d, ", argPos)
+		args = append(args, *updates.Unschedulable)
+		argPos++
+	}
+	if updates.Hostname != nil {
+		query += fmt.Sprintf("hostname = $%d, ", argPos)
+		args = append(args, *updates.Hostname)
+		argPos++
+	}
+	if updates.Port != nil {
+		query += fmt.Sprintf("port = $%d, ", argPos)
+		args = append(args, *updates.Port)
+		argPos++
+	}
+	if updates.PodCIDR != nil {
+		query += fmt.Sprintf("pod_cidr = $%d, ", argPos)
+		args = append(args, *updates.PodCIDR)
+		argPos++
+	}
+	if updates.InternalIP != nil {
+		query += fmt.Sprintf("internal_ip = $%d, ", argPos)
+		args = append(args, *updates.InternalIP)
+		argPos++
+	}
+	if updates.Resources != nil {
+		resourcesJSON, err := json.Marshal(updates.Resources)
+		if err != nil {
+			return fmt.Errorf("failed to marshal resources: %w", err)
+		}
+		query += fmt.Sprintf("resources = $%d, ", argPos)
+		args = append(args, resourcesJSON)
+		argPos++
+	}
+	if updates.Usage != nil {
+		usageJSON, err := json.Marshal(updates.Usage)
+		if err != nil {
+			return fmt.Errorf("failed to marshal usage: %w", err)
+		}
+		query += fmt.Sprintf("usage = $%d, ", argPos)
+		args = append(args, usageJSON)
+		argPos++
+	}
+	if updates.Pods != nil {
+		podsJSON, err := json.Marshal(*updates.Pods)
+		if err != nil {
+			return fmt.Errorf("failed to marshal reported pods: %w", err)
+		}
+		query += fmt.Sprintf("reported_pods = $%d, ", argPos)
+		args = append(args, podsJSON)
+		argPos++
+	}
+	if updates.Tasks != nil {
+		tasksJSON, err := json.Marshal(*updates.Tasks)
+		if err != nil {
+			return fmt.Errorf("failed to marshal reported tasks: %w", err)
+		}
+		query += fmt.Sprintf("reported_tasks = $%d, ", argPos)
+		args = append(args, tasksJSON)
+		argPos++
+	}
 
 	query = query[:len(query)-2]
 	query += fmt.Sprintf(" WHERE node_id = $%d", argPos)
@@ -842,49 +1045,7 @@ func (s *PostgresStore) UpdateNode(nodeID string, updates NodeUpdate) error {
 
 // ListNodes returns all nodes in the store
 func (s *PostgresStore) ListNodes() ([]types.Node, error) {
-	query := `
-		SELECT node_id, hostname, port, status, running_tasks, last_heartbeat, resources
-		FROM nodes
-		ORDER BY last_heartbeat DESC
-	`
-
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query nodes: %w", err)
-	}
-	defer func(rows *sql.Rows) {
-		_ = rows.Close()
-	}(rows)
-
-	var nodes []types.Node
-	for rows.Next() {
-		var node types.Node
-		var resourcesJSON []byte
-		err := rows.Scan(
-			&node.NodeID,
-			&node.Hostname,
-			&node.Port,
-			&node.Status,
-			&node.RunningTasks,
-			&node.LastHeartbeat,
-			&resourcesJSON,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan node: %w", err)
-		}
-
-		if err := json.Unmarshal(resourcesJSON, &node.Resources); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal resources: %w", err)
-		}
-
-		nodes = append(nodes, node)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating nodes: %w", err)
-	}
-
-	return nodes, nil
+	return s.queryNodes(`SELECT ` + nodeColumns + ` FROM nodes ORDER BY last_heartbeat DESC`)
 }
 
 // DeleteNode removes a node from the store
@@ -909,55 +1070,27 @@ func (s *PostgresStore) DeleteNode(nodeID string) error {
 // GetAvailableNodes returns all online nodes with available capacity
 func (s *PostgresStore) GetAvailableNodes() ([]types.Node, error) {
 	query := `
-		SELECT node_id, hostname, port, status, running_tasks, last_heartbeat, resources
+		SELECT ` + nodeColumns + `
 		FROM nodes
 		WHERE status = $1
 		ORDER BY running_tasks ASC
 	`
 
-	rows, err := s.db.Query(query, types.NodeOnline)
+	nodes, err := s.queryNodes(query, types.NodeOnline)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query available nodes: %w", err)
+		return nil, err
 	}
-	defer func(rows *sql.Rows) {
-		_ = rows.Close()
-	}(rows)
 
-	var nodes []types.Node
-	for rows.Next() {
-		var node types.Node
-		var resourcesJSON []byte
-		err := rows.Scan(
-			&node.NodeID,
-			&node.Hostname,
-			&node.Port,
-			&node.Status,
-			&node.RunningTasks,
-			&node.LastHeartbeat,
-			&resourcesJSON,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan node: %w", err)
-		}
-
-		if err := json.Unmarshal(resourcesJSON, &node.Resources); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal resources: %w", err)
-		}
-
-		// Filter nodes that have available capacity
-		maxSlots := node.GetMaxTaskSlots()
-		if node.RunningTasks >= maxSlots {
+	// Filter nodes that have available capacity
+	available := make([]types.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node.RunningTasks >= node.GetMaxTaskSlots() {
 			continue
 		}
-
-		nodes = append(nodes, node)
+		available = append(available, node)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating nodes: %w", err)
-	}
-
-	return nodes, nil
+	return available, nil
 }
 
 // nullString converts an empty string to sql.NullString
@@ -1007,9 +1140,14 @@ func (s *PostgresStore) AddService(service types.Service) error {
 		return fmt.Errorf("failed to marshal annotations: %w", err)
 	}
 
+	statusJSON, err := json.Marshal(service.Status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+
 	query := `
-		INSERT INTO services (service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO services (service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at, status, session_affinity_timeout, load_balancing, external_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
 	_, err = s.db.Exec(
@@ -1026,6 +1164,10 @@ func (s *PostgresStore) AddService(service types.Service) error {
 		nullString(service.SessionAffinity),
 		service.CreatedAt,
 		service.UpdatedAt,
+		statusJSON,
+		service.SessionAffinityTimeoutSeconds,
+		nullString(string(service.LoadBalancing)),
+		nullString(service.ExternalName),
 	)
 
 	if err != nil {
@@ -1038,14 +1180,15 @@ func (s *PostgresStore) AddService(service types.Service) error {
 // GetService retrieves a service by ID
 func (s *PostgresStore) GetService(serviceID string) (types.Service, error) {
 	query := `
-		SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at
+		SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at, status, session_affinity_timeout, load_balancing, external_name
 		FROM services
 		WHERE service_id = $1
 	`
 
 	var service types.Service
-	var namespace, clusterIP, sessionAffinity sql.NullString
-	var selectorJSON, portsJSON, labelsJSON, annotationsJSON []byte
+	var namespace, clusterIP, sessionAffinity, loadBalancing, externalName sql.NullString
+	var affinityTimeout sql.NullInt64
+	var selectorJSON, portsJSON, labelsJSON, annotationsJSON, statusJSON []byte
 
 	err := s.db.QueryRow(query, serviceID).Scan(
 		&service.ServiceID,
@@ -1060,6 +1203,10 @@ func (s *PostgresStore) GetService(serviceID string) (types.Service, error) {
 		&sessionAffinity,
 		&service.CreatedAt,
 		&service.UpdatedAt,
+		&statusJSON,
+		&affinityTimeout,
+		&loadBalancing,
+		&externalName,
 	)
 
 	if err != nil {
@@ -1078,6 +1225,15 @@ func (s *PostgresStore) GetService(serviceID string) (types.Service, error) {
 	if sessionAffinity.Valid {
 		service.SessionAffinity = sessionAffinity.String
 	}
+	if affinityTimeout.Valid {
+		service.SessionAffinityTimeoutSeconds = int(affinityTimeout.Int64)
+	}
+	if loadBalancing.Valid {
+		service.LoadBalancing = types.LoadBalancingAlgorithm(loadBalancing.String)
+	}
+	if externalName.Valid {
+		service.ExternalName = externalName.String
+	}
 
 	if err := json.Unmarshal(selectorJSON, &service.Selector); err != nil {
 		return types.Service{}, fmt.Errorf("failed to unmarshal selector: %w", err)
@@ -1091,6 +1247,9 @@ func (s *PostgresStore) GetService(serviceID string) (types.Service, error) {
 	if err := json.Unmarshal(annotationsJSON, &service.Annotations); err != nil {
 		return types.Service{}, fmt.Errorf("failed to unmarshal annotations: %w", err)
 	}
+	if err := unmarshalServiceStatus(statusJSON, &service.Status); err != nil {
+		return types.Service{}, err
+	}
 
 	return service, nil
 }
@@ -1102,14 +1261,15 @@ func (s *PostgresStore) GetServiceByName(namespace, name string) (types.Service,
 	}
 
 	query := `
-		SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at
+		SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at, status, session_affinity_timeout, load_balancing, external_name
 		FROM services
 		WHERE COALESCE(namespace, 'default') = $1 AND name = $2
 	`
 
 	var service types.Service
-	var ns, clusterIP, sessionAffinity sql.NullString
-	var selectorJSON, portsJSON, labelsJSON, annotationsJSON []byte
+	var ns, clusterIP, sessionAffinity, loadBalancing, externalName sql.NullString
+	var affinityTimeout sql.NullInt64
+	var selectorJSON, portsJSON, labelsJSON, annotationsJSON, statusJSON []byte
 
 	err := s.db.QueryRow(query, namespace, name).Scan(
 		&service.ServiceID,
@@ -1124,6 +1284,10 @@ func (s *PostgresStore) GetServiceByName(namespace, name string) (types.Service,
 		&sessionAffinity,
 		&service.CreatedAt,
 		&service.UpdatedAt,
+		&statusJSON,
+		&affinityTimeout,
+		&loadBalancing,
+		&externalName,
 	)
 
 	if err != nil {
@@ -1142,6 +1306,15 @@ func (s *PostgresStore) GetServiceByName(namespace, name string) (types.Service,
 	if sessionAffinity.Valid {
 		service.SessionAffinity = sessionAffinity.String
 	}
+	if affinityTimeout.Valid {
+		service.SessionAffinityTimeoutSeconds = int(affinityTimeout.Int64)
+	}
+	if loadBalancing.Valid {
+		service.LoadBalancing = types.LoadBalancingAlgorithm(loadBalancing.String)
+	}
+	if externalName.Valid {
+		service.ExternalName = externalName.String
+	}
 
 	if err := json.Unmarshal(selectorJSON, &service.Selector); err != nil {
 		return types.Service{}, fmt.Errorf("failed to unmarshal selector: %w", err)
@@ -1155,6 +1328,9 @@ func (s *PostgresStore) GetServiceByName(namespace, name string) (types.Service,
 	if err := json.Unmarshal(annotationsJSON, &service.Annotations); err != nil {
 		return types.Service{}, fmt.Errorf("failed to unmarshal annotations: %w", err)
 	}
+	if err := unmarshalServiceStatus(statusJSON, &service.Status); err != nil {
+		return types.Service{}, err
+	}
 
 	return service, nil
 }
@@ -1181,6 +1357,12 @@ func (s *PostgresStore) UpdateService(serviceID string, updates types.ServiceUpd
 		argNum++
 	}
 
+	if updates.ExternalName != nil {
+		query += fmt.Sprintf(", external_name = $%d", argNum)
+		args = append(args, nullString(*updates.ExternalName))
+		argNum++
+	}
+
 	if updates.Ports != nil {
 		portsJSON, err := json.Marshal(*updates.Ports)
 		if err != nil {
@@ -1217,6 +1399,28 @@ func (s *PostgresStore) UpdateService(serviceID string, updates types.ServiceUpd
 		argNum++
 	}
 
+	if updates.SessionAffinityTimeoutSeconds != nil {
+		query += fmt.Sprintf(", session_affinity_timeout = $%d", argNum)
+		args = append(args, *updates.SessionAffinityTimeoutSeconds)
+		argNum++
+	}
+
+	if updates.LoadBalancing != nil {
+		query += fmt.Sprintf(", load_balancing = $%d", argNum)
+		args = append(args, nullString(string(*updates.LoadBalancing)))
+		argNum++
+	}
+
+	if updates.Status != nil {
+		statusJSON, err := json.Marshal(*updates.Status)
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		query += fmt.Sprintf(", status = $%d", argNum)
+		args = append(args, statusJSON)
+		argNum++
+	}
+
 	query += fmt.Sprintf(" WHERE service_id = $%d", argNum)
 	args = append(args, serviceID)
 
@@ -1235,13 +1439,13 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 
 	if namespace == "" {
 		query = `
-			SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at
+			SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at, status, session_affinity_timeout, load_balancing, external_name
 			FROM services
 			ORDER BY created_at DESC
 		`
 	} else {
 		query = `
-			SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at
+			SELECT service_id, name, namespace, type, cluster_ip, selector, ports, labels, annotations, session_affinity, created_at, updated_at, status, session_affinity_timeout, load_balancing, external_name
 			FROM services
 			WHERE COALESCE(namespace, 'default') = $1
 			ORDER BY created_at DESC
@@ -1260,8 +1464,9 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 	var services []types.Service
 	for rows.Next() {
 		var service types.Service
-		var ns, clusterIP, sessionAffinity sql.NullString
-		var selectorJSON, portsJSON, labelsJSON, annotationsJSON []byte
+		var ns, clusterIP, sessionAffinity, loadBalancing, externalName sql.NullString
+		var affinityTimeout sql.NullInt64
+		var selectorJSON, portsJSON, labelsJSON, annotationsJSON, statusJSON []byte
 
 		err := rows.Scan(
 			&service.ServiceID,
@@ -1276,6 +1481,10 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 			&sessionAffinity,
 			&service.CreatedAt,
 			&service.UpdatedAt,
+			&statusJSON,
+			&affinityTimeout,
+			&loadBalancing,
+			&externalName,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan service: %w", err)
@@ -1290,6 +1499,15 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 		if sessionAffinity.Valid {
 			service.SessionAffinity = sessionAffinity.String
 		}
+		if affinityTimeout.Valid {
+			service.SessionAffinityTimeoutSeconds = int(affinityTimeout.Int64)
+		}
+		if loadBalancing.Valid {
+			service.LoadBalancing = types.LoadBalancingAlgorithm(loadBalancing.String)
+		}
+		if externalName.Valid {
+			service.ExternalName = externalName.String
+		}
 
 		if err := json.Unmarshal(selectorJSON, &service.Selector); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal selector: %w", err)
@@ -1303,6 +1521,9 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 		if err := json.Unmarshal(annotationsJSON, &service.Annotations); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal annotations: %w", err)
 		}
+		if err := unmarshalServiceStatus(statusJSON, &service.Status); err != nil {
+			return nil, err
+		}
 
 		services = append(services, service)
 	}
@@ -1314,6 +1535,18 @@ func (s *PostgresStore) ListServices(namespace string) ([]types.Service, error)
 	return services, nil
 }
 
+// unmarshalServiceStatus decodes a service's status column, which is NULL for services
+// created before statuses were stored
+func unmarshalServiceStatus(data []byte, status *types.ServiceStatus) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, status); err != nil {
+		return fmt.Errorf("failed to unmarshal status: %w", err)
+	}
+	return nil
+}
+
 // DeleteService removes a service from the store
 func (s *PostgresStore) DeleteService(serviceID string) error {
 	result, err := s.db.Exec("DELETE FROM services WHERE service_id = $1", serviceID)
@@ -1468,7 +1701,7 @@ func (s *PostgresStore) ListPodsByLabels(namespace string, labels map[string]str
 	}
 
 	query := `
-		SELECT pod_id, name, namespace, labels, annotations, containers, status, node_id, restart_policy, created_at, scheduled_at, started_at, finished_at, message, reason
+		SELECT ` + podColumns + `
 		FROM pods
 		WHERE COALESCE(namespace, 'default') = $1 AND labels @> $2
 		ORDER BY created_at DESC
@@ -1479,88 +1712,610 @@ func (s *PostgresStore) ListPodsByLabels(namespace string, labels map[string]str
 		return nil, fmt.Errorf("failed to marshal labels: %w", err)
 	}
 
-	rows, err := s.db.Query(query, namespace, labelsJSON)
+	return s.queryPods(query, namespace, labelsJSON)
+}
+
+// scanSecret reads a single secret row
+func scanSecret(row rowScanner) (types.Secret, error) {
+	var secret types.Secret
+	var dataJSON []byte
+
+	err := row.Scan(
+		&secret.SecretID,
+		&secret.Name,
+		&secret.Namespace,
+		&secret.Type,
+		&dataJSON,
+		&secret.CreatedAt,
+	)
+	if err != nil {
+		return types.Secret{}, err
+	}
+
+	if len(dataJSON) > 0 {
+		if err := json.Unmarshal(dataJSON, &secret.Data); err != nil {
+			return types.Secret{}, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+	}
+
+	return secret, nil
+}
+
+// AddSecret adds a new secret to the store
+func (s *PostgresStore) AddSecret(secret types.Secret) error {
+	var exists bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM secrets WHERE secret_id = $1 OR (namespace = $2 AND name = $3))",
+		secret.SecretID, normalizeNamespace(secret.Namespace), secret.Name,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check secret existence: %w", err)
+	}
+	if exists {
+		return ErrSecretAlreadyExists
+	}
+
+	dataJSON, err := json.Marshal(secret.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	query := `
+		INSERT INTO secrets (secret_id, name, namespace, type, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = s.db.Exec(
+		query,
+		secret.SecretID,
+		secret.Name,
+		normalizeNamespace(secret.Namespace),
+		secret.Type,
+		dataJSON,
+		secret.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert secret: %w", err)
+	}
+
+	return nil
+}
+
+// GetSecret retrieves a secret by ID
+func (s *PostgresStore) GetSecret(secretID string) (types.Secret, error) {
+	query := `SELECT secret_id, name, namespace, type, data, created_at FROM secrets WHERE secret_id = $1`
+
+	secret, err := scanSecret(s.db.QueryRow(query, secretID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Secret{}, ErrSecretNotFound
+	}
+	if err != nil {
+		return types.Secret{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// GetSecretByName retrieves a secret by namespace and name
+func (s *PostgresStore) GetSecretByName(namespace, name string) (types.Secret, error) {
+	query := `SELECT secret_id, name, namespace, type, data, created_at FROM secrets WHERE namespace = $1 AND name = $2`
+
+	secret, err := scanSecret(s.db.QueryRow(query, normalizeNamespace(namespace), name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Secret{}, ErrSecretNotFound
+	}
+	if err != nil {
+		return types.Secret{}, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// ListSecrets returns all secrets in the specified namespace
+// If namespace is empty, returns secrets from all namespaces
+func (s *PostgresStore) ListSecrets(namespace string) ([]types.Secret, error) {
+	query := `
+		SELECT secret_id, name, namespace, type, data, created_at
+		FROM secrets
+		WHERE $1 = '' OR namespace = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(query, namespace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pods by labels: %w", err)
+		return nil, fmt.Errorf("failed to query secrets: %w", err)
 	}
 	defer func(rows *sql.Rows) {
 		_ = rows.Close()
 	}(rows)
 
-	var pods []types.Pod
+	secrets := make([]types.Secret, 0)
 	for rows.Next() {
-		var pod types.Pod
-		var ns, nodeID, message, reason sql.NullString
-		var scheduledAt, startedAt, finishedAt sql.NullTime
-		var labelsJSON, annotationsJSON, containersJSON []byte
-
-		err := rows.Scan(
-			&pod.PodID,
-			&pod.Name,
-			&ns,
-			&labelsJSON,
-			&annotationsJSON,
-			&containersJSON,
-			&pod.Status,
-			&nodeID,
-			&pod.RestartPolicy,
-			&pod.CreatedAt,
-			&scheduledAt,
-			&startedAt,
-			&finishedAt,
-			&message,
-			&reason,
-		)
+		secret, err := scanSecret(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan pod: %w", err)
+			return nil, fmt.Errorf("failed to scan secret: %w", err)
 		}
+		secrets = append(secrets, secret)
+	}
 
-		if ns.Valid {
-			pod.Namespace = ns.String
-		}
-		if nodeID.Valid {
-			pod.NodeID = nodeID.String
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating secrets: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// DeleteSecret removes a secret from the store
+func (s *PostgresStore) DeleteSecret(secretID string) error {
+	result, err := s.db.Exec("DELETE FROM secrets WHERE secret_id = $1", secretID)
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrSecretNotFound
+	}
+
+	return nil
+}
+
+// scanIngress scans an ingress row whose specification columns hold JSON
+func scanIngress(row rowScanner) (types.Ingress, error) {
+	var ingress types.Ingress
+	var rulesJSON, defaultBackendJSON, tlsJSON, labelsJSON, annotationsJSON []byte
+
+	err := row.Scan(
+		&ingress.IngressID,
+		&ingress.Name,
+		&ingress.Namespace,
+		&rulesJSON,
+		&defaultBackendJSON,
+		&tlsJSON,
+		&labelsJSON,
+		&annotationsJSON,
+		&ingress.CreatedAt,
+		&ingress.UpdatedAt,
+	)
+	if err != nil {
+		return types.Ingress{}, err
+	}
+
+	fields := []struct {
+		name string
+		data []byte
+		v    any
+	}{
+		{"rules", rulesJSON, &ingress.Rules},
+		{"default backend", defaultBackendJSON, &ingress.DefaultBackend},
+		{"tls", tlsJSON, &ingress.TLS},
+		{"labels", labelsJSON, &ingress.Labels},
+		{"annotations", annotationsJSON, &ingress.Annotations},
+	}
+	for _, field := range fields {
+		if len(field.data) == 0 {
+			continue
 		}
-		if message.Valid {
-			pod.Message = message.String
+		if err := json.Unmarshal(field.data, field.v); err != nil {
+			return types.Ingress{}, fmt.Errorf("failed to unmarshal %s: %w", field.name, err)
 		}
-		if reason.Valid {
-			pod.Reason = reason.String
+	}
+
+	return ingress, nil
+}
+
+// marshalIngressSpec encodes the JSON columns of an ingress
+func marshalIngressSpec(ingress types.Ingress) ([]interface{}, error) {
+	values := []interface{}{ingress.Rules, ingress.DefaultBackend, ingress.TLS, ingress.Labels, ingress.Annotations}
+	encoded := make([]interface{}, len(values))
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ingress: %w", err)
 		}
-		if scheduledAt.Valid {
-			pod.ScheduledAt = &scheduledAt.Time
+		encoded[i] = data
+	}
+	return encoded, nil
+}
+
+// AddIngress adds a new ingress to the store
+func (s *PostgresStore) AddIngress(ingress types.Ingress) error {
+	var exists bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM ingresses WHERE ingress_id = $1 OR (namespace = $2 AND name = $3))",
+		ingress.IngressID, normalizeNamespace(ingress.Namespace), ingress.Name,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check ingress existence: %w", err)
+	}
+	if exists {
+		return ErrIngressAlreadyExists
+	}
+
+	spec, err := marshalIngressSpec(ingress)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO ingresses (ingress_id, name, namespace, rules, default_backend, tls, labels, annotations,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	args := append([]interface{}{ingress.IngressID, ingress.Name, normalizeNamespace(ingress.Namespace)}, spec...)
+	args = append(args, ingress.CreatedAt, ingress.UpdatedAt)
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to insert ingress: %w", err)
+	}
+
+	return nil
+}
+
+// GetIngress retrieves an ingress by ID
+func (s *PostgresStore) GetIngress(ingressID string) (types.Ingress, error) {
+	query := `
+		SELECT ingress_id, name, namespace, rules, default_backend, tls, labels, annotations, created_at, updated_at
+		FROM ingresses
+		WHERE ingress_id = $1
+	`
+
+	ingress, err := scanIngress(s.db.QueryRow(query, ingressID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Ingress{}, ErrIngressNotFound
+	}
+	if err != nil {
+		return types.Ingress{}, fmt.Errorf("failed to get ingress: %w", err)
+	}
+
+	return ingress, nil
+}
+
+// ListIngresses returns all ingresses in the specified namespace
+// If namespace is empty, returns ingresses from all namespaces
+func (s *PostgresStore) ListIngresses(namespace string) ([]types.Ingress, error) {
+	query := `
+		SELECT ingress_id, name, namespace, rules, default_backend, tls, labels, annotations, created_at, updated_at
+		FROM ingresses
+		WHERE $1 = '' OR namespace = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(query, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ingresses: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	ingresses := make([]types.Ingress, 0)
+	for rows.Next() {
+		ingress, err := scanIngress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ingress: %w", err)
 		}
-		if startedAt.Valid {
-			pod.StartedAt = &startedAt.Time
+		ingresses = append(ingresses, ingress)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ingresses: %w", err)
+	}
+
+	return ingresses, nil
+}
+
+// UpdateIngress replaces an existing ingress; its name, namespace and creation time are kept
+func (s *PostgresStore) UpdateIngress(ingress types.Ingress) error {
+	spec, err := marshalIngressSpec(ingress)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE ingresses
+		SET rules = $1, default_backend = $2, tls = $3, labels = $4, annotations = $5, updated_at = NOW()
+		WHERE ingress_id = $6
+	`
+
+	result, err := s.db.Exec(query, append(spec, ingress.IngressID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update ingress: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrIngressNotFound
+	}
+
+	return nil
+}
+
+// DeleteIngress removes an ingress from the store
+func (s *PostgresStore) DeleteIngress(ingressID string) error {
+	result, err := s.db.Exec("DELETE FROM ingresses WHERE ingress_id = $1", ingressID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ingress: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrIngressNotFound
+	}
+
+	return nil
+}
+
+// scanNetworkPolicy scans a network policy row whose specification columns hold JSON
+func scanNetworkPolicy(row rowScanner) (types.NetworkPolicy, error) {
+	var policy types.NetworkPolicy
+	var podSelectorJSON, policyTypesJSON, ingressJSON, egressJSON, labelsJSON, annotationsJSON []byte
+
+	err := row.Scan(
+		&policy.PolicyID,
+		&policy.Name,
+		&policy.Namespace,
+		&podSelectorJSON,
+		&policyTypesJSON,
+		&ingressJSON,
+		&egressJSON,
+		&labelsJSON,
+		&annotationsJSON,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		return types.NetworkPolicy{}, err
+	}
+
+	fields := []struct {
+		name string
+		data []byte
+		v    any
+	}{
+		{"pod selector", podSelectorJSON, &policy.PodSelector},
+		{"policy types", policyTypesJSON, &policy.PolicyTypes},
+		{"ingress rules", ingressJSON, &policy.Ingress},
+		{"egress rules", egressJSON, &policy.Egress},
+		{"labels", labelsJSON, &policy.Labels},
+		{"annotations", annotationsJSON, &policy.Annotations},
+	}
+	for _, field := range fields {
+		if len(field.data) == 0 {
+			continue
 		}
-		if finishedAt.Valid {
-			pod.FinishedAt = &finishedAt.Time
+		if err := json.Unmarshal(field.data, field.v); err != nil {
+			return types.NetworkPolicy{}, fmt.Errorf("failed to unmarshal %s: %w", field.name, err)
 		}
+	}
 
-		if err := json.Unmarshal(labelsJSON, &pod.Labels); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
-		}
-		pod.Annotations = make(map[string]string)
-		if len(annotationsJSON) > 0 {
-			var testArray []interface{}
-			if json.Unmarshal(annotationsJSON, &testArray) == nil {
-				pod.Annotations = make(map[string]string)
-			} else {
-				if err := json.Unmarshal(annotationsJSON, &pod.Annotations); err != nil {
-					return nil, fmt.Errorf("failed to unmarshal annotations: %w", err)
-				}
-			}
+	return policy, nil
+}
+
+// marshalNetworkPolicySpec encodes the JSON columns of a network policy
+func marshalNetworkPolicySpec(policy types.NetworkPolicy) ([]interface{}, error) {
+	values := []interface{}{
+		policy.PodSelector, policy.PolicyTypes, policy.Ingress, policy.Egress, policy.Labels, policy.Annotations,
+	}
+	encoded := make([]interface{}, len(values))
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal network policy: %w", err)
 		}
-		if err := json.Unmarshal(containersJSON, &pod.Containers); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal containers: %w", err)
+		encoded[i] = data
+	}
+	return encoded, nil
+}
+
+// AddNetworkPolicy adds a new network policy to the store
+func (s *PostgresStore) AddNetworkPolicy(policy types.NetworkPolicy) error {
+	var exists bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM network_policies WHERE policy_id = $1 OR (namespace = $2 AND name = $3))",
+		policy.PolicyID, normalizeNamespace(policy.Namespace), policy.Name,
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check network policy existence: %w", err)
+	}
+	if exists {
+		return ErrNetworkPolicyAlreadyExists
+	}
+
+	spec, err := marshalNetworkPolicySpec(policy)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO network_policies (policy_id, name, namespace, pod_selector, policy_types, ingress, egress,
+			labels, annotations, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	args := append([]interface{}{policy.PolicyID, policy.Name, normalizeNamespace(policy.Namespace)}, spec...)
+	args = append(args, policy.CreatedAt, policy.UpdatedAt)
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to insert network policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetNetworkPolicy retrieves a network policy by ID
+func (s *PostgresStore) GetNetworkPolicy(policyID string) (types.NetworkPolicy, error) {
+	query := `
+		SELECT policy_id, name, namespace, pod_selector, policy_types, ingress, egress, labels, annotations,
+			created_at, updated_at
+		FROM network_policies
+		WHERE policy_id = $1
+	`
+
+	policy, err := scanNetworkPolicy(s.db.QueryRow(query, policyID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.NetworkPolicy{}, ErrNetworkPolicyNotFound
+	}
+	if err != nil {
+		return types.NetworkPolicy{}, fmt.Errorf("failed to get network policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ListNetworkPolicies returns all network policies in the specified namespace
+// If namespace is empty, returns network policies from all namespaces
+func (s *PostgresStore) ListNetworkPolicies(namespace string) ([]types.NetworkPolicy, error) {
+	query := `
+		SELECT policy_id, name, namespace, pod_selector, policy_types, ingress, egress, labels, annotations,
+			created_at, updated_at
+		FROM network_policies
+		WHERE $1 = '' OR namespace = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(query, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query network policies: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	policies := make([]types.NetworkPolicy, 0)
+	for rows.Next() {
+		policy, err := scanNetworkPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan network policy: %w", err)
 		}
+		policies = append(policies, policy)
+	}
 
-		pods = append(pods, pod)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating network policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// UpdateNetworkPolicy replaces an existing network policy; its name, namespace and creation time are kept
+func (s *PostgresStore) UpdateNetworkPolicy(policy types.NetworkPolicy) error {
+	spec, err := marshalNetworkPolicySpec(policy)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE network_policies
+		SET pod_selector = $1, policy_types = $2, ingress = $3, egress = $4, labels = $5, annotations = $6,
+			updated_at = NOW()
+		WHERE policy_id = $7
+	`
+
+	result, err := s.db.Exec(query, append(spec, policy.PolicyID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update network policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNetworkPolicyNotFound
+	}
+
+	return nil
+}
+
+// DeleteNetworkPolicy removes a network policy from the store
+func (s *PostgresStore) DeleteNetworkPolicy(policyID string) error {
+	result, err := s.db.Exec("DELETE FROM network_policies WHERE policy_id = $1", policyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete network policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNetworkPolicyNotFound
+	}
+
+	return nil
+}
+
+// ReserveNodePort records that a service holds a node port of a protocol. Reserving a port the
+// service already holds succeeds; a port held by another service returns ErrNodePortAllocated.
+func (s *PostgresStore) ReserveNodePort(port int, protocol, serviceID string) error {
+	result, err := s.db.Exec(
+		`INSERT INTO node_ports (node_port, protocol, service_id) VALUES ($1, $2, $3)
+		ON CONFLICT (node_port, protocol) DO UPDATE SET service_id = EXCLUDED.service_id
+		WHERE node_ports.service_id = EXCLUDED.service_id`,
+		port, protocol, serviceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reserve node port: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrNodePortAllocated
+	}
+
+	return nil
+}
+
+// ReleaseNodePort frees a node port of a protocol; releasing a free port is a no-op
+func (s *PostgresStore) ReleaseNodePort(port int, protocol string) error {
+	_, err := s.db.Exec("DELETE FROM node_ports WHERE node_port = $1 AND protocol = $2", port, protocol)
+	if err != nil {
+		return fmt.Errorf("failed to release node port: %w", err)
+	}
+	return nil
+}
+
+// ListNodePorts returns the reserved node ports and the services holding them
+func (s *PostgresStore) ListNodePorts() (map[NodePortKey]string, error) {
+	rows, err := s.db.Query("SELECT node_port, protocol, service_id FROM node_ports")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node ports: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	ports := make(map[NodePortKey]string)
+	for rows.Next() {
+		var key NodePortKey
+		var serviceID string
+		if err := rows.Scan(&key.Port, &key.Protocol, &serviceID); err != nil {
+			return nil, fmt.Errorf("failed to scan node port: %w", err)
+		}
+		ports[key] = serviceID
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating pods: %w", err)
+		return nil, fmt.Errorf("error iterating node ports: %w", err)
 	}
 
-	return pods, nil
+	return ports, nil
 }
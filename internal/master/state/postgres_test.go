@@ -554,3 +554,220 @@ func TestPostgresStore_UpdateNode_AllFields(t *testing.T) {
 		t.Error("expected LastHeartbeat to be updated")
 	}
 }
+
+func TestPostgresStore_ReserveNodePort(t *testing.T) {
+	store := getTestPostgresStore(t)
+	_, _ = store.db.Exec("DELETE FROM node_ports")
+	t.Cleanup(func() { _, _ = store.db.Exec("DELETE FROM node_ports") })
+
+	if err := store.ReserveNodePort(30080, "TCP", "svc-1"); err != nil {
+		t.Fatalf("failed to reserve node port: %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-1"); err != nil {
+		t.Errorf("reserving a port the service holds should succeed, got %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-2"); err != ErrNodePortAllocated {
+		t.Errorf("expected ErrNodePortAllocated, got %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "UDP", "svc-2"); err != nil {
+		t.Errorf("expected the UDP port to be reservable alongside TCP, got %v", err)
+	}
+
+	ports, err := store.ListNodePorts()
+	if err != nil {
+		t.Fatalf("failed to list node ports: %v", err)
+	}
+	if ports[NodePortKey{Port: 30080, Protocol: "TCP"}] != "svc-1" {
+		t.Errorf("expected port 30080 held by svc-1, got %v", ports)
+	}
+
+	if err := store.ReleaseNodePort(30080, "TCP"); err != nil {
+		t.Fatalf("failed to release node port: %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-2"); err != nil {
+		t.Errorf("expected released port to be reservable, got %v", err)
+	}
+}
+
+func TestPostgresStore_Services(t *testing.T) {
+	store := getTestPostgresStore(t)
+	_, _ = store.db.Exec("DELETE FROM services")
+	t.Cleanup(func() { _, _ = store.db.Exec("DELETE FROM services") })
+
+	now := time.Now()
+	headless := types.Service{
+		ServiceID: "svc-headless",
+		Name:      "db",
+		Type:      types.ServiceTypeClusterIP,
+		ClusterIP: types.ClusterIPNone,
+		Selector:  map[string]string{"app": "db"},
+		Ports:     []types.ServicePort{{Name: "pg", Port: 5432, TargetPort: 5432, Protocol: "TCP"}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	external := types.Service{
+		ServiceID:    "svc-external",
+		Name:         "billing",
+		Type:         types.ServiceTypeExternalName,
+		ExternalName: "billing.example.com",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	for _, service := range []types.Service{headless, external} {
+		if err := store.AddService(service); err != nil {
+			t.Fatalf("failed to add service %s: %v", service.Name, err)
+		}
+	}
+
+	got, err := store.GetServiceByName("default", "db")
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if !got.IsHeadless() || got.ExternalName != "" {
+		t.Errorf("expected a headless service, got %+v", got)
+	}
+
+	externalName := "billing.internal.example.com"
+	if err := store.UpdateService("svc-external", types.ServiceUpdate{ExternalName: &externalName}); err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+	got, err = store.GetService("svc-external")
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if !got.IsExternalName() || got.ExternalName != externalName || got.ClusterIP != "" {
+		t.Errorf("expected the updated external name, got %+v", got)
+	}
+
+	services, err := store.ListServices("")
+	if err != nil {
+		t.Fatalf("failed to list services: %v", err)
+	}
+	if len(services) != 2 {
+		t.Errorf("expected 2 services, got %d", len(services))
+	}
+}
+
+func TestPostgresStore_Ingresses(t *testing.T) {
+	store := getTestPostgresStore(t)
+	_, _ = store.db.Exec("DELETE FROM ingresses")
+	t.Cleanup(func() { _, _ = store.db.Exec("DELETE FROM ingresses") })
+
+	now := time.Now()
+	ingress := types.Ingress{
+		IngressID: "ing-1",
+		Name:      "web",
+		Rules: []types.IngressRule{
+			{
+				Host: "example.com",
+				Paths: []types.IngressPath{
+					{
+						Path: "/api", PathType: types.PathTypeExact,
+						Backend: types.IngressBackend{ServiceName: "api", ServicePortName: "http"},
+					},
+				},
+			},
+		},
+		TLS:       []types.IngressTLS{{Hosts: []string{"example.com"}, SecretName: "example-cert"}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := store.AddIngress(ingress); err != nil {
+		t.Fatalf("failed to add ingress: %v", err)
+	}
+	if err := store.AddIngress(ingress); !errors.Is(err, ErrIngressAlreadyExists) {
+		t.Errorf("expected ErrIngressAlreadyExists, got %v", err)
+	}
+
+	got, err := store.GetIngress("ing-1")
+	if err != nil {
+		t.Fatalf("failed to get ingress: %v", err)
+	}
+	if got.Namespace != "default" || len(got.Rules) != 1 || got.Rules[0].Paths[0].Backend.ServicePortName != "http" {
+		t.Errorf("unexpected ingress: %+v", got)
+	}
+	if len(got.TLS) != 1 || got.TLS[0].SecretName != "example-cert" {
+		t.Errorf("expected tls to round-trip, got %+v", got.TLS)
+	}
+
+	ingress.DefaultBackend = &types.IngressBackend{ServiceName: "fallback", ServicePort: 80}
+	if err := store.UpdateIngress(ingress); err != nil {
+		t.Fatalf("failed to update ingress: %v", err)
+	}
+	ingresses, err := store.ListIngresses("default")
+	if err != nil {
+		t.Fatalf("failed to list ingresses: %v", err)
+	}
+	if len(ingresses) != 1 || ingresses[0].DefaultBackend == nil {
+		t.Errorf("expected the updated ingress, got %+v", ingresses)
+	}
+
+	if err := store.DeleteIngress("ing-1"); err != nil {
+		t.Fatalf("failed to delete ingress: %v", err)
+	}
+	if _, err := store.GetIngress("ing-1"); !errors.Is(err, ErrIngressNotFound) {
+		t.Errorf("expected ErrIngressNotFound, got %v", err)
+	}
+}
+
+func TestPostgresStore_NetworkPolicies(t *testing.T) {
+	store := getTestPostgresStore(t)
+	_, _ = store.db.Exec("DELETE FROM network_policies")
+	t.Cleanup(func() { _, _ = store.db.Exec("DELETE FROM network_policies") })
+
+	now := time.Now()
+	policy := types.NetworkPolicy{
+		PolicyID:    "np-1",
+		Name:        "api",
+		PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+		Ingress: []types.NetworkPolicyIngressRule{
+			{
+				From: []types.NetworkPolicyPeer{
+					{NamespaceSelector: &types.LabelSelector{}},
+					{IPBlock: &types.IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+				},
+				Ports: []types.NetworkPolicyPort{{Protocol: "TCP", Port: 8080}},
+			},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := store.AddNetworkPolicy(policy); err != nil {
+		t.Fatalf("failed to add network policy: %v", err)
+	}
+	if err := store.AddNetworkPolicy(policy); !errors.Is(err, ErrNetworkPolicyAlreadyExists) {
+		t.Errorf("expected ErrNetworkPolicyAlreadyExists, got %v", err)
+	}
+
+	got, err := store.GetNetworkPolicy("np-1")
+	if err != nil {
+		t.Fatalf("failed to get network policy: %v", err)
+	}
+	if got.Namespace != "default" || got.PodSelector.MatchLabels["app"] != "api" || len(got.Ingress) != 1 {
+		t.Errorf("unexpected network policy: %+v", got)
+	}
+	if from := got.Ingress[0].From; len(from) != 2 || from[0].NamespaceSelector == nil || from[1].IPBlock == nil {
+		t.Errorf("expected the peers to round-trip, got %+v", from)
+	}
+
+	policy.PolicyTypes = []types.PolicyType{types.PolicyTypeIngress, types.PolicyTypeEgress}
+	if err := store.UpdateNetworkPolicy(policy); err != nil {
+		t.Fatalf("failed to update network policy: %v", err)
+	}
+	policies, err := store.ListNetworkPolicies("default")
+	if err != nil {
+		t.Fatalf("failed to list network policies: %v", err)
+	}
+	if len(policies) != 1 || len(policies[0].PolicyTypes) != 2 {
+		t.Errorf("expected the updated network policy, got %+v", policies)
+	}
+
+	if err := store.DeleteNetworkPolicy("np-1"); err != nil {
+		t.Fatalf("failed to delete network policy: %v", err)
+	}
+	if _, err := store.GetNetworkPolicy("np-1"); !errors.Is(err, ErrNetworkPolicyNotFound) {
+		t.Errorf("expected ErrNetworkPolicyNotFound, got %v", err)
+	}
+}
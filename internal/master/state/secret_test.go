@@ -0,0 +1,94 @@
+package state
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestInMemoryStore_Secrets(t *testing.T) {
+	store := NewInMemoryStore()
+
+	secret := types.Secret{
+		SecretID:  "secret-1",
+		Name:      "regcred",
+		Namespace: "default",
+		Type:      types.SecretTypeDockerRegistry,
+		Data:      map[string]string{"server": "registry.example.com", "username": "u", "password": "p"},
+		CreatedAt: time.Now(),
+	}
+
+	if err := store.AddSecret(secret); err != nil {
+		t.Fatalf("AddSecret failed: %v", err)
+	}
+
+	duplicate := secret
+	duplicate.SecretID = "secret-2"
+	if err := store.AddSecret(duplicate); !errors.Is(err, ErrSecretAlreadyExists) {
+		t.Errorf("expected ErrSecretAlreadyExists for duplicate name, got %v", err)
+	}
+
+	got, err := store.GetSecretByName("", "regcred")
+	if err != nil {
+		t.Fatalf("GetSecretByName failed: %v", err)
+	}
+	if got.SecretID != "secret-1" {
+		t.Errorf("expected secret-1, got %s", got.SecretID)
+	}
+
+	if _, err := store.GetSecretByName("other", "regcred"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound in other namespace, got %v", err)
+	}
+
+	secrets, err := store.ListSecrets("default")
+	if err != nil {
+		t.Fatalf("ListSecrets failed: %v", err)
+	}
+	if len(secrets) != 1 {
+		t.Errorf("expected 1 secret, got %d", len(secrets))
+	}
+
+	if err := store.DeleteSecret("secret-1"); err != nil {
+		t.Fatalf("DeleteSecret failed: %v", err)
+	}
+	if _, err := store.GetSecret("secret-1"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound after delete, got %v", err)
+	}
+	if err := store.DeleteSecret("secret-1"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound on second delete, got %v", err)
+	}
+}
+
+func TestInMemoryStore_UpdatePodEvents(t *testing.T) {
+	store := NewInMemoryStore()
+
+	if err := store.AddPod(types.Pod{PodID: "pod-1", Name: "web", Status: types.PodPending}); err != nil {
+		t.Fatalf("AddPod failed: %v", err)
+	}
+
+	update := PodUpdate{
+		Events: []types.PodEvent{
+			{Type: types.EventTypeNormal, Reason: "Pulling"},
+			{Type: types.EventTypeWarning, Reason: types.ReasonErrImagePull},
+		},
+	}
+	if err := store.UpdatePod("pod-1", update); err != nil {
+		t.Fatalf("UpdatePod failed: %v", err)
+	}
+	if err := store.UpdatePod("pod-1", PodUpdate{Events: []types.PodEvent{{Reason: "Pulled"}}}); err != nil {
+		t.Fatalf("UpdatePod failed: %v", err)
+	}
+
+	pod, _ := store.GetPod("pod-1")
+	if len(pod.Events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(pod.Events))
+	}
+	if pod.Events[2].Reason != "Pulled" {
+		t.Errorf("expected last event Pulled, got %s", pod.Events[2].Reason)
+	}
+	if pod.Status != types.PodPending {
+		t.Errorf("expected status to be unchanged, got %s", pod.Status)
+	}
+}
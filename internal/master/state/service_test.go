@@ -199,6 +199,24 @@ func TestUpdateService(t *testing.T) {
 	if len(retrieved.Ports) != 2 {
 		t.Errorf("Expected 2 ports, got %d", len(retrieved.Ports))
 	}
+
+	// Update status
+	status := types.ServiceStatus{
+		LoadBalancer: types.LoadBalancerStatus{
+			Ingress: []types.LoadBalancerIngress{{IP: "192.168.1.240"}},
+		},
+	}
+	if err := store.UpdateService("svc-1", types.ServiceUpdate{Status: &status}); err != nil {
+		t.Fatalf("Failed to update service status: %v", err)
+	}
+
+	retrieved, _ = store.GetService("svc-1")
+	if ips := retrieved.IngressIPs(); len(ips) != 1 || ips[0] != "192.168.1.240" {
+		t.Errorf("Expected ingress IP 192.168.1.240, got %v", ips)
+	}
+	if len(retrieved.Ports) != 2 {
+		t.Errorf("Expected status update to keep 2 ports, got %d", len(retrieved.Ports))
+	}
 }
 
 func TestListServices(t *testing.T) {
@@ -578,3 +596,35 @@ func TestServiceGetDNSName(t *testing.T) {
 		})
 	}
 }
+
+func TestReserveNodePort(t *testing.T) {
+	store := NewInMemoryStore()
+
+	if err := store.ReserveNodePort(30080, "TCP", "svc-1"); err != nil {
+		t.Fatalf("Failed to reserve node port: %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-1"); err != nil {
+		t.Errorf("Reserving a port the service holds should succeed, got %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-2"); err != ErrNodePortAllocated {
+		t.Errorf("Expected ErrNodePortAllocated, got %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "UDP", "svc-2"); err != nil {
+		t.Errorf("Expected the UDP port to be reservable alongside TCP, got %v", err)
+	}
+
+	ports, err := store.ListNodePorts()
+	if err != nil {
+		t.Fatalf("Failed to list node ports: %v", err)
+	}
+	if len(ports) != 2 || ports[NodePortKey{Port: 30080, Protocol: "TCP"}] != "svc-1" {
+		t.Errorf("Expected port 30080 held by svc-1, got %v", ports)
+	}
+
+	if err := store.ReleaseNodePort(30080, "TCP"); err != nil {
+		t.Fatalf("Failed to release node port: %v", err)
+	}
+	if err := store.ReserveNodePort(30080, "TCP", "svc-2"); err != nil {
+		t.Errorf("Expected released port to be reservable, got %v", err)
+	}
+}
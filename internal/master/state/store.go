@@ -27,6 +27,20 @@ var (
 	ErrServiceAlreadyExists = errors.New("service already exists")
 	// ErrEndpointsNotFound is returned when endpoints are not found in the store
 	ErrEndpointsNotFound = errors.New("endpoints not found")
+	// ErrSecretNotFound is returned when a secret is not found in the store
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrSecretAlreadyExists is returned when attempting to add a duplicate secret
+	ErrSecretAlreadyExists = errors.New("secret already exists")
+	// ErrNodePortAllocated is returned when reserving a node port another service holds
+	ErrNodePortAllocated = errors.New("node port already allocated")
+	// ErrIngressNotFound is returned when an ingress is not found in the store
+	ErrIngressNotFound = errors.New("ingress not found")
+	// ErrIngressAlreadyExists is returned when attempting to add a duplicate ingress
+	ErrIngressAlreadyExists = errors.New("ingress already exists")
+	// ErrNetworkPolicyNotFound is returned when a network policy is not found in the store
+	ErrNetworkPolicyNotFound = errors.New("network policy not found")
+	// ErrNetworkPolicyAlreadyExists is returned when attempting to add a duplicate network policy
+	ErrNetworkPolicyAlreadyExists = errors.New("network policy already exists")
 )
 
 // TaskUpdate contains fields that can be updated for a task
@@ -45,6 +59,17 @@ type NodeUpdate struct {
 	Status        *types.NodeStatus
 	RunningTasks  *int
 	LastHeartbeat *time.Time
+	Images        *[]types.ContainerImage
+	Conditions    *[]types.NodeCondition
+	Unschedulable *bool
+	Hostname      *string
+	Port          *int
+	Resources     *types.NodeResources
+	Usage         *types.ResourceList
+	Pods          *[]types.RunningPod
+	Tasks         *[]types.RunningTask
+	PodCIDR       *string
+	InternalIP    *string
 }
 
 // PodUpdate contains fields that can be updated for a pod
@@ -58,6 +83,15 @@ type PodUpdate struct {
 	Message     *string
 	Reason      *string
 	Annotations *map[string]string
+	// Events are appended to the pod's existing events
+	Events []types.PodEvent
+}
+
+// NodePortKey identifies a node port reservation. TCP and UDP have separate port spaces, so
+// services may hold the same port number with different protocols.
+type NodePortKey struct {
+	Port     int
+	Protocol string
 }
 
 // StateStore defines the interface for managing task and node state
@@ -97,6 +131,32 @@ type StateStore interface {
 	GetEndpointsByServiceName(namespace, serviceName string) (types.Endpoints, error)
 	DeleteEndpoints(serviceID string) error
 
+	// Secret operations
+	AddSecret(secret types.Secret) error
+	GetSecret(secretID string) (types.Secret, error)
+	GetSecretByName(namespace, name string) (types.Secret, error)
+	ListSecrets(namespace string) ([]types.Secret, error)
+	DeleteSecret(secretID string) error
+
+	// Node port operations
+	ReserveNodePort(port int, protocol, serviceID string) error
+	ReleaseNodePort(port int, protocol string) error
+	ListNodePorts() (map[NodePortKey]string, error)
+
+	// Ingress operations
+	AddIngress(ingress types.Ingress) error
+	GetIngress(ingressID string) (types.Ingress, error)
+	ListIngresses(namespace string) ([]types.Ingress, error)
+	UpdateIngress(ingress types.Ingress) error
+	DeleteIngress(ingressID string) error
+
+	// Network policy operations
+	AddNetworkPolicy(policy types.NetworkPolicy) error
+	GetNetworkPolicy(policyID string) (types.NetworkPolicy, error)
+	ListNetworkPolicies(namespace string) ([]types.NetworkPolicy, error)
+	UpdateNetworkPolicy(policy types.NetworkPolicy) error
+	DeleteNetworkPolicy(policyID string) error
+
 	// Utility
 	GetAvailableNodes() ([]types.Node, error)
 	ListPodsByLabels(namespace string, labels map[string]string) ([]types.Pod, error)
@@ -110,6 +170,10 @@ type InMemoryStore struct {
 	nodes     map[string]types.Node
 	services  map[string]types.Service
 	endpoints map[string]types.Endpoints // key is serviceID
+	secrets   map[string]types.Secret
+	nodePorts map[NodePortKey]string // node port -> service ID
+	ingresses map[string]types.Ingress
+	policies  map[string]types.NetworkPolicy
 }
 
 // NewInMemoryStore creates a new in-memory state store
@@ -120,6 +184,10 @@ func NewInMemoryStore() *InMemoryStore {
 		nodes:     make(map[string]types.Node),
 		services:  make(map[string]types.Service),
 		endpoints: make(map[string]types.Endpoints),
+		secrets:   make(map[string]types.Secret),
+		nodePorts: make(map[NodePortKey]string),
+		ingresses: make(map[string]types.Ingress),
+		policies:  make(map[string]types.NetworkPolicy),
 	}
 }
 
@@ -282,6 +350,9 @@ func (s *InMemoryStore) UpdatePod(podID string, updates PodUpdate) error {
 			pod.Annotations[k] = v
 		}
 	}
+	for _, event := range updates.Events {
+		pod.AddEvent(event)
+	}
 
 	s.pods[podID] = pod
 	return nil
@@ -359,6 +430,41 @@ func (s *InMemoryStore) UpdateNode(nodeID string, updates NodeUpdate) error {
 	if updates.LastHeartbeat != nil {
 		node.LastHeartbeat = *updates.LastHeartbeat
 	}
+	if updates.Images != nil {
+		node.Images = *updates.Images
+	}
+	if updates.Conditions != nil {
+		node.Conditions = *updates.Conditions
+	}
+	if updates.Unschedulable != nil {
+		node.Unschedulable = *updates.Unschedulable
+	}
+	if updates.Hostname != nil {
+		node.Hostname = *updates.Hostname
+	}
+	if updates.Port != nil {
+		node.Port = *updates.Port
+	}
+	if updates.PodCIDR != nil {
+		node.PodCIDR = *updates.PodCIDR
+	}
+	if updates.InternalIP != nil {
+		node.InternalIP = *updates.InternalIP
+	}
+	if updates.Resources != nil {
+		resources := *updates.Resources
+		node.Resources = &resources
+	}
+	if updates.Usage != nil {
+		usage := *updates.Usage
+		node.Usage = &usage
+	}
+	if updates.Pods != nil {
+		node.Pods = *updates.Pods
+	}
+	if updates.Tasks != nil {
+		node.Tasks = *updates.Tasks
+	}
 
 	s.nodes[nodeID] = node
 	return nil
@@ -466,6 +572,9 @@ func (s *InMemoryStore) UpdateService(serviceID string, updates types.ServiceUpd
 	if updates.Selector != nil {
 		service.Selector = *updates.Selector
 	}
+	if updates.ExternalName != nil {
+		service.ExternalName = *updates.ExternalName
+	}
 	if updates.Ports != nil {
 		service.Ports = *updates.Ports
 	}
@@ -478,6 +587,15 @@ func (s *InMemoryStore) UpdateService(serviceID string, updates types.ServiceUpd
 	if updates.SessionAffinity != nil {
 		service.SessionAffinity = *updates.SessionAffinity
 	}
+	if updates.SessionAffinityTimeoutSeconds != nil {
+		service.SessionAffinityTimeoutSeconds = *updates.SessionAffinityTimeoutSeconds
+	}
+	if updates.LoadBalancing != nil {
+		service.LoadBalancing = *updates.LoadBalancing
+	}
+	if updates.Status != nil {
+		service.Status = *updates.Status
+	}
 
 	service.UpdatedAt = time.Now()
 	s.services[serviceID] = service
@@ -621,3 +739,284 @@ func (s *InMemoryStore) ListPodsByLabels(namespace string, labels map[string]str
 
 	return pods, nil
 }
+
+// AddSecret adds a new secret to the store
+func (s *InMemoryStore) AddSecret(secret types.Secret) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.secrets[secret.SecretID]; exists {
+		return ErrSecretAlreadyExists
+	}
+
+	namespace := normalizeNamespace(secret.Namespace)
+	for _, existing := range s.secrets {
+		if normalizeNamespace(existing.Namespace) == namespace && existing.Name == secret.Name {
+			return ErrSecretAlreadyExists
+		}
+	}
+
+	s.secrets[secret.SecretID] = secret
+	return nil
+}
+
+// GetSecret retrieves a secret by ID
+func (s *InMemoryStore) GetSecret(secretID string) (types.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secret, exists := s.secrets[secretID]
+	if !exists {
+		return types.Secret{}, ErrSecretNotFound
+	}
+
+	return secret, nil
+}
+
+// GetSecretByName retrieves a secret by namespace and name
+func (s *InMemoryStore) GetSecretByName(namespace, name string) (types.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	namespace = normalizeNamespace(namespace)
+	for _, secret := range s.secrets {
+		if normalizeNamespace(secret.Namespace) == namespace && secret.Name == name {
+			return secret, nil
+		}
+	}
+
+	return types.Secret{}, ErrSecretNotFound
+}
+
+// ListSecrets returns all secrets in the specified namespace
+// If namespace is empty, returns secrets from all namespaces
+func (s *InMemoryStore) ListSecrets(namespace string) ([]types.Secret, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secrets := make([]types.Secret, 0)
+	for _, secret := range s.secrets {
+		if namespace == "" || normalizeNamespace(secret.Namespace) == namespace {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets, nil
+}
+
+// DeleteSecret removes a secret from the store
+func (s *InMemoryStore) DeleteSecret(secretID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.secrets[secretID]; !exists {
+		return ErrSecretNotFound
+	}
+
+	delete(s.secrets, secretID)
+	return nil
+}
+
+// AddIngress adds a new ingress to the store
+func (s *InMemoryStore) AddIngress(ingress types.Ingress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.ingresses[ingress.IngressID]; exists {
+		return ErrIngressAlreadyExists
+	}
+
+	namespace := normalizeNamespace(ingress.Namespace)
+	for _, existing := range s.ingresses {
+		if normalizeNamespace(existing.Namespace) == namespace && existing.Name == ingress.Name {
+			return ErrIngressAlreadyExists
+		}
+	}
+
+	s.ingresses[ingress.IngressID] = ingress
+	return nil
+}
+
+// GetIngress retrieves an ingress by ID
+func (s *InMemoryStore) GetIngress(ingressID string) (types.Ingress, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ingress, exists := s.ingresses[ingressID]
+	if !exists {
+		return types.Ingress{}, ErrIngressNotFound
+	}
+
+	return ingress, nil
+}
+
+// ListIngresses returns all ingresses in the specified namespace
+// If namespace is empty, returns ingresses from all namespaces
+func (s *InMemoryStore) ListIngresses(namespace string) ([]types.Ingress, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ingresses := make([]types.Ingress, 0)
+	for _, ingress := range s.ingresses {
+		if namespace == "" || normalizeNamespace(ingress.Namespace) == namespace {
+			ingresses = append(ingresses, ingress)
+		}
+	}
+
+	return ingresses, nil
+}
+
+// UpdateIngress replaces an existing ingress; its name, namespace and creation time are kept
+func (s *InMemoryStore) UpdateIngress(ingress types.Ingress) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.ingresses[ingress.IngressID]
+	if !exists {
+		return ErrIngressNotFound
+	}
+
+	ingress.Name = existing.Name
+	ingress.Namespace = existing.Namespace
+	ingress.CreatedAt = existing.CreatedAt
+	ingress.UpdatedAt = time.Now()
+	s.ingresses[ingress.IngressID] = ingress
+	return nil
+}
+
+// DeleteIngress removes an ingress from the store
+func (s *InMemoryStore) DeleteIngress(ingressID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.ingresses[ingressID]; !exists {
+		return ErrIngressNotFound
+	}
+
+	delete(s.ingresses, ingressID)
+	return nil
+}
+
+// AddNetworkPolicy adds a new network policy to the store
+func (s *InMemoryStore) AddNetworkPolicy(policy types.NetworkPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.policies[policy.PolicyID]; exists {
+		return ErrNetworkPolicyAlreadyExists
+	}
+
+	namespace := normalizeNamespace(policy.Namespace)
+	for _, existing := range s.policies {
+		if normalizeNamespace(existing.Namespace) == namespace && existing.Name == policy.Name {
+			return ErrNetworkPolicyAlreadyExists
+		}
+	}
+
+	s.policies[policy.PolicyID] = policy
+	return nil
+}
+
+// GetNetworkPolicy retrieves a network policy by ID
+func (s *InMemoryStore) GetNetworkPolicy(policyID string) (types.NetworkPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy, exists := s.policies[policyID]
+	if !exists {
+		return types.NetworkPolicy{}, ErrNetworkPolicyNotFound
+	}
+
+	return policy, nil
+}
+
+// ListNetworkPolicies returns all network policies in the specified namespace
+// If namespace is empty, returns network policies from all namespaces
+func (s *InMemoryStore) ListNetworkPolicies(namespace string) ([]types.NetworkPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policies := make([]types.NetworkPolicy, 0)
+	for _, policy := range s.policies {
+		if namespace == "" || normalizeNamespace(policy.Namespace) == namespace {
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, nil
+}
+
+// UpdateNetworkPolicy replaces an existing network policy; its name, namespace and creation time are kept
+func (s *InMemoryStore) UpdateNetworkPolicy(policy types.NetworkPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.policies[policy.PolicyID]
+	if !exists {
+		return ErrNetworkPolicyNotFound
+	}
+
+	policy.Name = existing.Name
+	policy.Namespace = existing.Namespace
+	policy.CreatedAt = existing.CreatedAt
+	policy.UpdatedAt = time.Now()
+	s.policies[policy.PolicyID] = policy
+	return nil
+}
+
+// DeleteNetworkPolicy removes a network policy from the store
+func (s *InMemoryStore) DeleteNetworkPolicy(policyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.policies[policyID]; !exists {
+		return ErrNetworkPolicyNotFound
+	}
+
+	delete(s.policies, policyID)
+	return nil
+}
+
+// ReserveNodePort records that a service holds a node port of a protocol. Reserving a port the
+// service already holds succeeds; a port held by another service returns ErrNodePortAllocated.
+func (s *InMemoryStore) ReserveNodePort(port int, protocol, serviceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := NodePortKey{Port: port, Protocol: protocol}
+	if owner, exists := s.nodePorts[key]; exists && owner != serviceID {
+		return ErrNodePortAllocated
+	}
+
+	s.nodePorts[key] = serviceID
+	return nil
+}
+
+// ReleaseNodePort frees a node port of a protocol; releasing a free port is a no-op
+func (s *InMemoryStore) ReleaseNodePort(port int, protocol string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.nodePorts, NodePortKey{Port: port, Protocol: protocol})
+	return nil
+}
+
+// ListNodePorts returns the reserved node ports and the services holding them
+func (s *InMemoryStore) ListNodePorts() (map[NodePortKey]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ports := make(map[NodePortKey]string, len(s.nodePorts))
+	for port, serviceID := range s.nodePorts {
+		ports[port] = serviceID
+	}
+	return ports, nil
+}
+
+// normalizeNamespace maps an empty namespace to "default"
+func normalizeNamespace(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
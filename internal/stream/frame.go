@@ -0,0 +1,110 @@
+package stream
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Channels multiplexed on an exec stream
+const (
+	// ChannelStdin carries input for the command; an empty frame closes its stdin
+	ChannelStdin byte = iota
+	ChannelStdout
+	ChannelStderr
+	// ChannelStatus carries the ExecStatus sent when the command finished
+	ChannelStatus
+	// ChannelResize carries a TerminalSize whenever the client terminal is resized
+	ChannelResize
+)
+
+// maxFrameSize bounds the payload of a single frame
+const maxFrameSize = 1 << 20
+
+// ExecStatus reports how an exec command finished
+type ExecStatus struct {
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// TerminalSize is the size of the client terminal in characters
+type TerminalSize struct {
+	Width  uint16 `json:"width"`
+	Height uint16 `json:"height"`
+}
+
+// FrameWriter writes frames to a stream; it is safe for concurrent use
+type FrameWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFrameWriter creates a frame writer on w
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// WriteFrame writes one frame; payloads larger than the frame limit are split
+func (f *FrameWriter) WriteFrame(channel byte, payload []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for {
+		chunk := payload
+		if len(chunk) > maxFrameSize {
+			chunk = chunk[:maxFrameSize]
+		}
+
+		header := make([]byte, 5)
+		header[0] = channel
+		binary.BigEndian.PutUint32(header[1:], uint32(len(chunk)))
+		if _, err := f.w.Write(append(header, chunk...)); err != nil {
+			return err
+		}
+
+		payload = payload[len(chunk):]
+		if len(payload) == 0 {
+			return nil
+		}
+	}
+}
+
+// Channel returns a writer that sends everything written to it as frames on one channel
+func (f *FrameWriter) Channel(channel byte) io.Writer {
+	return channelWriter{frames: f, channel: channel}
+}
+
+type channelWriter struct {
+	frames  *FrameWriter
+	channel byte
+}
+
+func (w channelWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := w.frames.WriteFrame(w.channel, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ReadFrame reads the next frame from a stream
+func ReadFrame(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds the %d byte limit", size, maxFrameSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
@@ -0,0 +1,189 @@
+// Package stream carries bidirectional byte streams over upgraded HTTP connections,
+// used by exec and port-forward between the CLI, the master and the workers.
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Protocol is the Upgrade header value that requests a stream
+const Protocol = "podling-stream"
+
+// errNoHalfClose is returned by CloseWrite for connections that cannot be half-closed
+var errNoHalfClose = errors.New("stream cannot be half-closed")
+
+// closeWriter is implemented by connections whose write side can be closed on its own
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// StatusError is returned by Dial when the server refuses the upgrade
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Message)
+}
+
+// Dial sends an upgrade request to rawURL and returns the stream once the server switched protocols.
+// The stream stays open until it is closed or ctx is canceled. Streams over plain HTTP can be
+// half-closed with CloseWrite.
+func Dial(ctx context.Context, method, rawURL string) (io.ReadWriteCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", Protocol)
+
+	// The upgraded body only writes to the connection, so the connection is kept to half-close it.
+	// Under TLS, closing the raw connection's write side would break the session.
+	var mu sync.Mutex
+	var raw net.Conn
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err == nil && req.URL.Scheme == "http" {
+				mu.Lock()
+				raw = conn
+				mu.Unlock()
+			}
+			return conn, err
+		},
+	}
+
+	// No client timeout: it would also bound the lifetime of the stream
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send upgrade request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Message: errorMessage(resp.Body)}
+	}
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("server switched protocols without a writable stream")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return &dialedConn{ReadWriteCloser: conn, raw: raw}, nil
+}
+
+// dialedConn is a stream returned by Dial
+type dialedConn struct {
+	io.ReadWriteCloser
+	// raw is the underlying connection, or nil if it cannot be half-closed
+	raw net.Conn
+}
+
+// CloseWrite closes the write side of the stream, so the server reads EOF
+func (c *dialedConn) CloseWrite() error {
+	if cw, ok := c.raw.(closeWriter); ok {
+		return cw.CloseWrite()
+	}
+	return errNoHalfClose
+}
+
+// errorMessage extracts the message of a JSON error response, falling back to the raw body
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 64*1024))
+
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil && payload.Error != "" {
+		return payload.Error
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// IsUpgrade reports whether the request asks for a stream
+func IsUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), Protocol) &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// Upgrade takes over the connection of a stream request and switches protocols.
+// Nothing must be written to w before or after a successful upgrade.
+func Upgrade(w http.ResponseWriter, r *http.Request) (io.ReadWriteCloser, error) {
+	if !IsUpgrade(r) {
+		return nil, fmt.Errorf("request does not ask for a %s upgrade", Protocol)
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + Protocol + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to switch protocols: %w", err)
+	}
+
+	return &hijackedConn{Conn: conn, reader: rw.Reader}, nil
+}
+
+// hijackedConn reads through the buffer that may already hold bytes sent after the request
+type hijackedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *hijackedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// CloseWrite closes the write side of the connection, so the client reads EOF
+func (c *hijackedConn) CloseWrite() error {
+	if cw, ok := c.Conn.(closeWriter); ok {
+		return cw.CloseWrite()
+	}
+	return errNoHalfClose
+}
+
+// Join copies bytes between two streams in both directions until both are done, then closes
+// both. When one side stops sending, the other side's write half is closed, so a peer that
+// half-closed its connection still receives the reply. Streams that cannot be half-closed, or a
+// failed copy, close both at once.
+func Join(a, b io.ReadWriteCloser) {
+	var once sync.Once
+	closeBoth := func() {
+		_ = a.Close()
+		_ = b.Close()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	forward := func(dst, src io.ReadWriteCloser) {
+		defer wg.Done()
+		if _, err := io.Copy(dst, src); err == nil {
+			if cw, ok := dst.(closeWriter); ok && cw.CloseWrite() == nil {
+				return
+			}
+		}
+		once.Do(closeBoth)
+	}
+	go forward(a, b)
+	go forward(b, a)
+	wg.Wait()
+	once.Do(closeBoth)
+}
@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDialAndUpgrade(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/missing" {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":"pod not found"}`))
+					return
+				}
+
+				conn, err := Upgrade(w, r)
+				if err != nil {
+					t.Errorf("Upgrade() error = %v", err)
+					return
+				}
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			},
+		),
+	)
+	defer server.Close()
+
+	conn, err := Dial(context.Background(), http.MethodPost, server.URL+"/echo")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+		t.Errorf("reply = %q, %v, want ping", reply, err)
+	}
+
+	_, err = Dial(context.Background(), http.MethodPost, server.URL+"/missing")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Dial() error = %v, want a StatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound || statusErr.Message != "pod not found" {
+		t.Errorf("StatusError = %+v", statusErr)
+	}
+}
+
+func TestJoinHalfClose(t *testing.T) {
+	// The backend answers once it has read the whole request, like a request/response protocol
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = backend.Close() }()
+	go func() {
+		conn, err := backend.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		request, _ := io.ReadAll(conn)
+		_, _ = conn.Write(append([]byte("reply to "), request...))
+	}()
+
+	// A server joins upgraded streams with the backend, like a worker forwarding a pod port
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				target, err := net.Dial("tcp", backend.Addr().String())
+				if err != nil {
+					t.Errorf("Dial() error = %v", err)
+					return
+				}
+				conn, err := Upgrade(w, r)
+				if err != nil {
+					_ = target.Close()
+					t.Errorf("Upgrade() error = %v", err)
+					return
+				}
+				Join(conn, target)
+			},
+		),
+	)
+	defer server.Close()
+
+	// A local listener joins accepted connections with a dialed stream, like the CLI
+	local, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = local.Close() }()
+	go func() {
+		conn, err := local.Accept()
+		if err != nil {
+			return
+		}
+		tunnel, err := Dial(context.Background(), http.MethodPost, server.URL)
+		if err != nil {
+			_ = conn.Close()
+			t.Errorf("Dial() error = %v", err)
+			return
+		}
+		Join(conn, tunnel)
+	}()
+
+	client, err := net.Dial("tcp", local.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.(*net.TCPConn).CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := io.ReadAll(client); err != nil || string(reply) != "reply to ping" {
+		t.Errorf("reply = %q, %v, want the reply after half-closing", reply, err)
+	}
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/exec", nil)
+	if _, err := Upgrade(httptest.NewRecorder(), req); err == nil {
+		t.Error("expected an error for a request without an upgrade header")
+	}
+}
+
+func TestFrames(t *testing.T) {
+	var buf bytes.Buffer
+	frames := NewFrameWriter(&buf)
+
+	if _, err := frames.Channel(ChannelStdout).Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := frames.WriteFrame(ChannelStdin, nil); err != nil {
+		t.Fatal(err)
+	}
+	large := bytes.Repeat([]byte("x"), maxFrameSize+10)
+	if err := frames.WriteFrame(ChannelStderr, large); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		channel byte
+		size    int
+	}{
+		{ChannelStdout, 5},
+		{ChannelStdin, 0},
+		{ChannelStderr, maxFrameSize},
+		{ChannelStderr, 10},
+	}
+	for _, w := range want {
+		channel, payload, err := ReadFrame(&buf)
+		if err != nil {
+			t.Fatalf("ReadFrame() error = %v", err)
+		}
+		if channel != w.channel || len(payload) != w.size {
+			t.Errorf(
+				"frame = channel %d with %d bytes, want channel %d with %d bytes",
+				channel, len(payload), w.channel, w.size,
+			)
+		}
+	}
+	if _, _, err := ReadFrame(&buf); !errors.Is(err, io.EOF) {
+		t.Errorf("ReadFrame() at end = %v, want EOF", err)
+	}
+}
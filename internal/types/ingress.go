@@ -0,0 +1,185 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IngressPathType determines how an ingress path is matched against request paths
+type IngressPathType string
+
+const (
+	// PathTypePrefix matches request paths that start with the path, element by element
+	// "/api" matches "/api" and "/api/users" but not "/apis"
+	PathTypePrefix IngressPathType = "Prefix"
+
+	// PathTypeExact matches the request path exactly
+	PathTypeExact IngressPathType = "Exact"
+)
+
+// Ingress routes external HTTP and HTTPS traffic to services by host and path
+// Similar to Kubernetes Ingresses, it is served by the ingress gateway
+type Ingress struct {
+	// IngressID is the unique identifier for the ingress
+	IngressID string `json:"ingressId"`
+
+	// Name is a human-readable name, unique within the namespace
+	Name string `json:"name"`
+
+	// Namespace is the logical grouping for the ingress; backends are services in it
+	Namespace string `json:"namespace,omitempty"`
+
+	// Rules route requests for a host to backends by path
+	Rules []IngressRule `json:"rules,omitempty"`
+
+	// DefaultBackend receives requests that match no rule
+	DefaultBackend *IngressBackend `json:"defaultBackend,omitempty"`
+
+	// TLS terminates HTTPS for hosts with certificates from TLS secrets
+	TLS []IngressTLS `json:"tls,omitempty"`
+
+	// Labels are key-value pairs for organizing and selecting ingresses
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are key-value pairs for storing arbitrary metadata
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// CreatedAt is when the ingress was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt is when the ingress was last modified
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// IngressRule routes the requests for a host
+type IngressRule struct {
+	// Host is the request host to match, without a port
+	// A leading "*." matches exactly one extra label; empty matches every host
+	Host string `json:"host,omitempty"`
+
+	// Paths map request paths to backends
+	Paths []IngressPath `json:"paths"`
+}
+
+// IngressPath routes requests whose path matches to a backend
+type IngressPath struct {
+	// Path is the path to match; it must start with "/"
+	Path string `json:"path"`
+
+	// PathType is Prefix (default) or Exact
+	PathType IngressPathType `json:"pathType,omitempty"`
+
+	// Backend receives the matching requests
+	Backend IngressBackend `json:"backend"`
+}
+
+// IngressBackend is a service port requests are sent to
+type IngressBackend struct {
+	// ServiceName is the name of a service in the ingress's namespace
+	ServiceName string `json:"serviceName"`
+
+	// ServicePort is the service port number; either it or ServicePortName is required
+	ServicePort int `json:"servicePort,omitempty"`
+
+	// ServicePortName is the name of the service port
+	ServicePortName string `json:"servicePortName,omitempty"`
+}
+
+// IngressTLS names the certificate served for a set of hosts
+type IngressTLS struct {
+	// Hosts are the hosts the certificate is served for
+	Hosts []string `json:"hosts"`
+
+	// SecretName is a TLS secret in the ingress's namespace
+	SecretName string `json:"secretName"`
+}
+
+// String formats the backend as service:port
+func (b IngressBackend) String() string {
+	if b.ServicePortName != "" {
+		return b.ServiceName + ":" + b.ServicePortName
+	}
+	return fmt.Sprintf("%s:%d", b.ServiceName, b.ServicePort)
+}
+
+// Validate checks that the ingress is well formed
+func (i *Ingress) Validate() error {
+	if i.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(i.Rules) == 0 && i.DefaultBackend == nil {
+		return fmt.Errorf("at least one rule or a default backend is required")
+	}
+
+	if i.DefaultBackend != nil {
+		if err := i.DefaultBackend.validate(); err != nil {
+			return fmt.Errorf("default backend: %w", err)
+		}
+	}
+
+	for _, rule := range i.Rules {
+		if err := validateIngressHost(rule.Host); err != nil {
+			return err
+		}
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("rule for host %q has no paths", rule.Host)
+		}
+		for _, path := range rule.Paths {
+			if !strings.HasPrefix(path.Path, "/") {
+				return fmt.Errorf("path %q must start with /", path.Path)
+			}
+			switch path.PathType {
+			case "", PathTypePrefix, PathTypeExact:
+			default:
+				return fmt.Errorf("path %q has unknown path type %q (expected Prefix or Exact)", path.Path, path.PathType)
+			}
+			if err := path.Backend.validate(); err != nil {
+				return fmt.Errorf("path %q: %w", path.Path, err)
+			}
+		}
+	}
+
+	for _, tls := range i.TLS {
+		if tls.SecretName == "" {
+			return fmt.Errorf("tls entries require a secretName")
+		}
+		if len(tls.Hosts) == 0 {
+			return fmt.Errorf("tls secret %s has no hosts", tls.SecretName)
+		}
+		for _, host := range tls.Hosts {
+			if host == "" {
+				return fmt.Errorf("tls secret %s has an empty host", tls.SecretName)
+			}
+			if err := validateIngressHost(host); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the backend names a service port
+func (b IngressBackend) validate() error {
+	if b.ServiceName == "" {
+		return fmt.Errorf("serviceName is required")
+	}
+	if (b.ServicePort == 0) == (b.ServicePortName == "") {
+		return fmt.Errorf("exactly one of servicePort and servicePortName is required")
+	}
+	if b.ServicePort < 0 || b.ServicePort > 65535 {
+		return fmt.Errorf("servicePort %d is out of range", b.ServicePort)
+	}
+	return nil
+}
+
+// validateIngressHost checks that a host is a name without a port, optionally with a leading
+// wildcard label
+func validateIngressHost(host string) error {
+	name := strings.TrimPrefix(host, "*.")
+	if strings.ContainsAny(name, ":/* ") {
+		return fmt.Errorf("invalid host %q (expected a DNS name without a port)", host)
+	}
+	return nil
+}
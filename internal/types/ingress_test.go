@@ -0,0 +1,80 @@
+package types
+
+import "testing"
+
+func TestIngress_Validate(t *testing.T) {
+	backend := IngressBackend{ServiceName: "web", ServicePort: 80}
+
+	tests := []struct {
+		name    string
+		ingress Ingress
+		wantErr bool
+	}{
+		{
+			name: "host and path rules with tls",
+			ingress: Ingress{
+				Name: "web",
+				Rules: []IngressRule{
+					{
+						Host: "*.example.com",
+						Paths: []IngressPath{
+							{Path: "/", Backend: backend},
+							{Path: "/api", PathType: PathTypeExact, Backend: IngressBackend{
+								ServiceName: "api", ServicePortName: "http",
+							}},
+						},
+					},
+				},
+				TLS: []IngressTLS{{Hosts: []string{"*.example.com"}, SecretName: "example-cert"}},
+			},
+		},
+		{
+			name:    "default backend only",
+			ingress: Ingress{Name: "web", DefaultBackend: &backend},
+		},
+		{name: "missing name", ingress: Ingress{DefaultBackend: &backend}, wantErr: true},
+		{name: "no rules or default backend", ingress: Ingress{Name: "web"}, wantErr: true},
+		{
+			name: "host with port",
+			ingress: Ingress{Name: "web", Rules: []IngressRule{
+				{Host: "example.com:80", Paths: []IngressPath{{Path: "/", Backend: backend}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			ingress: Ingress{Name: "web", Rules: []IngressRule{{Paths: []IngressPath{{Path: "api", Backend: backend}}}}},
+			wantErr: true,
+		},
+		{
+			name: "unknown path type",
+			ingress: Ingress{Name: "web", Rules: []IngressRule{
+				{Paths: []IngressPath{{Path: "/", PathType: "Regex", Backend: backend}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "backend with both port and port name",
+			ingress: Ingress{
+				Name: "web", DefaultBackend: &IngressBackend{ServiceName: "web", ServicePort: 80, ServicePortName: "http"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "tls without secret",
+			ingress: Ingress{Name: "web", DefaultBackend: &backend, TLS: []IngressTLS{{Hosts: []string{"example.com"}}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := tt.ingress.Validate()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseSelector parses a label selector of comma-separated key=value pairs, such as "app=web,tier=frontend"
+func ParseSelector(selector string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(selector, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid selector %q (expected key=value)", pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// MatchesSelector reports whether labels contain every key=value pair of the selector
+func MatchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
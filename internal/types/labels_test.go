@@ -0,0 +1,56 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{name: "empty", selector: "", want: map[string]string{}},
+		{name: "single", selector: "app=web", want: map[string]string{"app": "web"}},
+		{
+			name: "multiple", selector: "app=web, tier=frontend",
+			want: map[string]string{"app": "web", "tier": "frontend"},
+		},
+		{name: "empty value", selector: "canary=", want: map[string]string{"canary": ""}},
+		{name: "missing value", selector: "app", wantErr: true},
+		{name: "missing key", selector: "=web", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseSelector(tt.selector)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ParseSelector() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ParseSelector() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMatchesSelector(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+
+	if !MatchesSelector(labels, map[string]string{"app": "web"}) {
+		t.Error("expected a subset selector to match")
+	}
+	if !MatchesSelector(labels, nil) {
+		t.Error("expected an empty selector to match")
+	}
+	if MatchesSelector(labels, map[string]string{"app": "api"}) {
+		t.Error("expected a different value not to match")
+	}
+	if MatchesSelector(labels, map[string]string{"env": "prod"}) {
+		t.Error("expected a missing key not to match")
+	}
+}
@@ -0,0 +1,37 @@
+package types
+
+import "time"
+
+// Log streams a container writes to
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// LogEntry is one line of container output in a log stream.
+// Log streams are sent as newline-delimited JSON, one entry per line.
+type LogEntry struct {
+	// Container is the name of the pod container, or empty for tasks
+	Container string `json:"container,omitempty"`
+	// Stream is LogStreamStdout or LogStreamStderr
+	Stream string `json:"stream"`
+	// Timestamp is set when timestamps were requested
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Line      string     `json:"line"`
+}
+
+// LogRecord is a log line shipped from a worker, tagged with the pod that wrote it so that
+// it can be searched after the pod is gone
+type LogRecord struct {
+	PodID     string            `json:"podId"`
+	PodName   string            `json:"podName,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	NodeID    string            `json:"nodeId,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	LogEntry
+}
+
+// LogBatch is the body of a log shipping request to the master
+type LogBatch struct {
+	Records []LogRecord `json:"records"`
+}
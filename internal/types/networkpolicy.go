@@ -0,0 +1,217 @@
+package types
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NamespaceNameLabel is the label every namespace implicitly carries with its name
+// Podling has no namespace objects, so namespace selectors match against this label only
+const NamespaceNameLabel = "podling.io/namespace"
+
+// PolicyType is a direction of traffic a network policy restricts
+type PolicyType string
+
+const (
+	// PolicyTypeIngress restricts traffic to the selected pods
+	PolicyTypeIngress PolicyType = "Ingress"
+
+	// PolicyTypeEgress restricts traffic from the selected pods
+	PolicyTypeEgress PolicyType = "Egress"
+)
+
+// NetworkPolicy restricts the traffic allowed to and from a set of pods
+// Similar to Kubernetes NetworkPolicies, a pod selected by any policy of a direction only
+// accepts the traffic one of those policies allows; policies are additive and never deny
+type NetworkPolicy struct {
+	// PolicyID is the unique identifier for the policy
+	PolicyID string `json:"policyId"`
+
+	// Name is a human-readable name, unique within the namespace
+	Name string `json:"name"`
+
+	// Namespace is the logical grouping for the policy; it selects pods in it
+	Namespace string `json:"namespace,omitempty"`
+
+	// PodSelector selects the pods the policy applies to; an empty selector selects every pod
+	// in the namespace
+	PodSelector LabelSelector `json:"podSelector"`
+
+	// PolicyTypes are the directions the policy restricts
+	// Default: Ingress, plus Egress when the policy has egress rules
+	PolicyTypes []PolicyType `json:"policyTypes,omitempty"`
+
+	// Ingress rules allow traffic to the selected pods; none isolates them completely
+	Ingress []NetworkPolicyIngressRule `json:"ingress,omitempty"`
+
+	// Egress rules allow traffic from the selected pods; none isolates them completely
+	Egress []NetworkPolicyEgressRule `json:"egress,omitempty"`
+
+	// Labels are key-value pairs for organizing and selecting policies
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are key-value pairs for storing arbitrary metadata
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// CreatedAt is when the policy was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt is when the policy was last modified
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// LabelSelector selects objects whose labels contain every key=value pair of MatchLabels
+// An empty selector selects everything
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// Matches reports whether the labels satisfy the selector
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	return MatchesSelector(labels, s.MatchLabels)
+}
+
+// NetworkPolicyIngressRule allows traffic from peers to ports of the selected pods
+type NetworkPolicyIngressRule struct {
+	// From are the allowed sources; empty allows every source
+	From []NetworkPolicyPeer `json:"from,omitempty"`
+
+	// Ports are the allowed destination ports; empty allows every port
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyEgressRule allows traffic from the selected pods to ports of peers
+type NetworkPolicyEgressRule struct {
+	// To are the allowed destinations; empty allows every destination
+	To []NetworkPolicyPeer `json:"to,omitempty"`
+
+	// Ports are the allowed destination ports; empty allows every port
+	Ports []NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyPeer is a set of pods or addresses traffic is allowed from or to
+// Exactly one of IPBlock or the selectors is set. A PodSelector alone selects pods in the
+// policy's namespace, a NamespaceSelector alone selects every pod in the matching namespaces,
+// and both together select the matching pods in the matching namespaces.
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `json:"podSelector,omitempty"`
+	NamespaceSelector *LabelSelector `json:"namespaceSelector,omitempty"`
+	IPBlock           *IPBlock       `json:"ipBlock,omitempty"`
+}
+
+// IPBlock is a CIDR of allowed addresses, minus the Except CIDRs inside it
+type IPBlock struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// NetworkPolicyPort is a port or port range of a protocol
+type NetworkPolicyPort struct {
+	// Protocol is TCP, UDP or SCTP. Default: TCP
+	Protocol string `json:"protocol,omitempty"`
+
+	// Port is the port number; zero allows every port of the protocol
+	Port int `json:"port,omitempty"`
+
+	// EndPort makes the rule allow the range Port-EndPort
+	EndPort int `json:"endPort,omitempty"`
+}
+
+// NetworkPolicyProtocols are the protocols network policy ports accept
+var NetworkPolicyProtocols = []string{"TCP", "UDP", "SCTP"}
+
+// AppliesTo reports whether the policy restricts traffic in the direction
+func (p *NetworkPolicy) AppliesTo(policyType PolicyType) bool {
+	if len(p.PolicyTypes) > 0 {
+		return slices.Contains(p.PolicyTypes, policyType)
+	}
+	return policyType == PolicyTypeIngress || len(p.Egress) > 0
+}
+
+// Validate checks that the policy is well formed
+func (p *NetworkPolicy) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	for _, policyType := range p.PolicyTypes {
+		if policyType != PolicyTypeIngress && policyType != PolicyTypeEgress {
+			return fmt.Errorf("unknown policy type %q (expected Ingress or Egress)", policyType)
+		}
+	}
+
+	for i, rule := range p.Ingress {
+		if err := validateNetworkPolicyRule(rule.From, rule.Ports); err != nil {
+			return fmt.Errorf("ingress rule %d: %w", i+1, err)
+		}
+	}
+	for i, rule := range p.Egress {
+		if err := validateNetworkPolicyRule(rule.To, rule.Ports); err != nil {
+			return fmt.Errorf("egress rule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// validateNetworkPolicyRule checks the peers and ports of a rule
+func validateNetworkPolicyRule(peers []NetworkPolicyPeer, ports []NetworkPolicyPort) error {
+	for _, peer := range peers {
+		if err := peer.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, port := range ports {
+		protocol := strings.ToUpper(port.Protocol)
+		if protocol != "" && !slices.Contains(NetworkPolicyProtocols, protocol) {
+			return fmt.Errorf("unknown protocol %q (expected TCP, UDP or SCTP)", port.Protocol)
+		}
+		if port.Port < 0 || port.Port > 65535 {
+			return fmt.Errorf("port %d is out of range", port.Port)
+		}
+		if port.EndPort != 0 {
+			if port.Port == 0 {
+				return fmt.Errorf("endPort %d requires a port", port.EndPort)
+			}
+			if port.EndPort < port.Port || port.EndPort > 65535 {
+				return fmt.Errorf("endPort %d must be between port %d and 65535", port.EndPort, port.Port)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the peer is either an IP block or selectors
+func (p NetworkPolicyPeer) validate() error {
+	if p.IPBlock == nil {
+		if p.PodSelector == nil && p.NamespaceSelector == nil {
+			return fmt.Errorf("peer requires a podSelector, namespaceSelector or ipBlock")
+		}
+		return nil
+	}
+	if p.PodSelector != nil || p.NamespaceSelector != nil {
+		return fmt.Errorf("ipBlock cannot be combined with selectors")
+	}
+
+	_, block, err := net.ParseCIDR(p.IPBlock.CIDR)
+	if err != nil || block.IP.To4() == nil {
+		return fmt.Errorf("ipBlock cidr %q is not an IPv4 CIDR", p.IPBlock.CIDR)
+	}
+	for _, except := range p.IPBlock.Except {
+		ip, exceptNet, err := net.ParseCIDR(except)
+		if err != nil || ip.To4() == nil {
+			return fmt.Errorf("ipBlock except %q is not an IPv4 CIDR", except)
+		}
+		blockBits, _ := block.Mask.Size()
+		exceptBits, _ := exceptNet.Mask.Size()
+		if !block.Contains(ip) || exceptBits < blockBits {
+			return fmt.Errorf("ipBlock except %s is not inside %s", except, p.IPBlock.CIDR)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package types
+
+import "testing"
+
+func TestNetworkPolicy_Validate(t *testing.T) {
+	frontend := &LabelSelector{MatchLabels: map[string]string{"app": "frontend"}}
+
+	tests := []struct {
+		name    string
+		policy  NetworkPolicy
+		wantErr bool
+	}{
+		{
+			name: "ingress and egress rules",
+			policy: NetworkPolicy{
+				Name:        "api",
+				PodSelector: LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				Ingress: []NetworkPolicyIngressRule{
+					{
+						From:  []NetworkPolicyPeer{{PodSelector: frontend, NamespaceSelector: &LabelSelector{}}},
+						Ports: []NetworkPolicyPort{{Protocol: "tcp", Port: 8000, EndPort: 8080}},
+					},
+				},
+				Egress: []NetworkPolicyEgressRule{
+					{To: []NetworkPolicyPeer{{IPBlock: &IPBlock{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}}}},
+				},
+			},
+		},
+		{name: "deny all ingress", policy: NetworkPolicy{Name: "deny"}},
+		{name: "missing name", policy: NetworkPolicy{}, wantErr: true},
+		{
+			name:    "unknown policy type",
+			policy:  NetworkPolicy{Name: "deny", PolicyTypes: []PolicyType{"Both"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty peer",
+			policy:  NetworkPolicy{Name: "api", Ingress: []NetworkPolicyIngressRule{{From: []NetworkPolicyPeer{{}}}}},
+			wantErr: true,
+		},
+		{
+			name: "ip block with selector",
+			policy: NetworkPolicy{Name: "api", Ingress: []NetworkPolicyIngressRule{
+				{From: []NetworkPolicyPeer{{PodSelector: frontend, IPBlock: &IPBlock{CIDR: "10.0.0.0/8"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "invalid cidr",
+			policy: NetworkPolicy{Name: "api", Egress: []NetworkPolicyEgressRule{
+				{To: []NetworkPolicyPeer{{IPBlock: &IPBlock{CIDR: "10.0.0.1"}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "except outside cidr",
+			policy: NetworkPolicy{Name: "api", Egress: []NetworkPolicyEgressRule{
+				{To: []NetworkPolicyPeer{{IPBlock: &IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.0.0/8"}}}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "unknown protocol",
+			policy: NetworkPolicy{Name: "api", Ingress: []NetworkPolicyIngressRule{
+				{Ports: []NetworkPolicyPort{{Protocol: "ICMP"}}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "end port before port",
+			policy: NetworkPolicy{Name: "api", Ingress: []NetworkPolicyIngressRule{
+				{Ports: []NetworkPolicyPort{{Port: 8080, EndPort: 80}}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := tt.policy.Validate()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestNetworkPolicy_AppliesTo(t *testing.T) {
+	ingressOnly := NetworkPolicy{Name: "api"}
+	if !ingressOnly.AppliesTo(PolicyTypeIngress) || ingressOnly.AppliesTo(PolicyTypeEgress) {
+		t.Error("expected a policy without egress rules to restrict ingress only")
+	}
+
+	withEgress := NetworkPolicy{Name: "api", Egress: []NetworkPolicyEgressRule{{}}}
+	if !withEgress.AppliesTo(PolicyTypeEgress) {
+		t.Error("expected a policy with egress rules to restrict egress")
+	}
+
+	egressOnly := NetworkPolicy{Name: "api", PolicyTypes: []PolicyType{PolicyTypeEgress}}
+	if egressOnly.AppliesTo(PolicyTypeIngress) || !egressOnly.AppliesTo(PolicyTypeEgress) {
+		t.Error("expected explicit policy types to be used")
+	}
+}
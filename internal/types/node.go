@@ -19,6 +19,119 @@ type Node struct {
 	RunningTasks  int            `json:"runningTasks"`
 	LastHeartbeat time.Time      `json:"lastHeartbeat"`
 	Resources     *NodeResources `json:"resources"`
+	// Images is the image inventory last reported by the worker
+	Images []ContainerImage `json:"images,omitempty"`
+	// Conditions describe node health signals such as memory or disk pressure
+	Conditions []NodeCondition `json:"conditions,omitempty"`
+	// Unschedulable is set when the node is cordoned; no new work is placed on it
+	Unschedulable bool `json:"unschedulable,omitempty"`
+	// Usage is the actual resource consumption last reported by the worker,
+	// as opposed to Resources.Used which sums the requests of scheduled work
+	Usage *ResourceList `json:"usage,omitempty"`
+	// Pods are the pods the worker last reported as running
+	Pods []RunningPod `json:"pods,omitempty"`
+	// Tasks are the tasks the worker last reported as running
+	Tasks []RunningTask `json:"tasks,omitempty"`
+	// PodCIDR is the subnet of the cluster pod CIDR the node's pod IPs are assigned from
+	PodCIDR string `json:"podCIDR,omitempty"`
+	// InternalIP is the address other nodes reach this node's pods through
+	InternalIP string `json:"internalIp,omitempty"`
+}
+
+// RunningPod is a pod a worker reports with its containers
+type RunningPod struct {
+	PodID      string             `json:"podId"`
+	Containers []RunningContainer `json:"containers"`
+}
+
+// RunningContainer is a container of a running pod as observed by the worker
+type RunningContainer struct {
+	Name        string `json:"name"`
+	ContainerID string `json:"containerId"`
+	// State is the runtime state of the container (created, running or exited)
+	State string `json:"state"`
+}
+
+// RunningTask is a task a worker reports as running
+type RunningTask struct {
+	TaskID      string `json:"taskId"`
+	ContainerID string `json:"containerId"`
+}
+
+// NodeConditionType is the kind of signal a node condition reports
+type NodeConditionType string
+
+const (
+	// NodeReady is true when the worker can run containers
+	NodeReady NodeConditionType = "Ready"
+
+	// NodeMemoryPressure is true when available memory is below the eviction threshold
+	NodeMemoryPressure NodeConditionType = "MemoryPressure"
+
+	// NodeDiskPressure is true when available disk is below the eviction threshold
+	NodeDiskPressure NodeConditionType = "DiskPressure"
+
+	// NodePIDPressure is true when the number of free process IDs is below the threshold
+	NodePIDPressure NodeConditionType = "PIDPressure"
+
+	// NodeRuntimeUnavailable is true when the worker cannot reach its container runtime
+	NodeRuntimeUnavailable NodeConditionType = "RuntimeUnavailable"
+)
+
+// NodeCondition is a single observed node condition
+type NodeCondition struct {
+	Type               NodeConditionType `json:"type"`
+	Status             bool              `json:"status"`
+	Reason             string            `json:"reason,omitempty"`
+	Message            string            `json:"message,omitempty"`
+	LastTransitionTime time.Time         `json:"lastTransitionTime"`
+}
+
+// GetCondition returns the node condition of the given type, if reported
+func (n *Node) GetCondition(conditionType NodeConditionType) (NodeCondition, bool) {
+	for _, condition := range n.Conditions {
+		if condition.Type == conditionType {
+			return condition, true
+		}
+	}
+	return NodeCondition{}, false
+}
+
+// HasPressure reports whether the node is under memory, disk or PID pressure
+func (n *Node) HasPressure() bool {
+	for _, conditionType := range []NodeConditionType{NodeMemoryPressure, NodeDiskPressure, NodePIDPressure} {
+		if condition, ok := n.GetCondition(conditionType); ok && condition.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReady reports whether the node can run new work.
+// Nodes that do not report a Ready condition are assumed ready.
+func (n *Node) IsReady() bool {
+	if condition, ok := n.GetCondition(NodeRuntimeUnavailable); ok && condition.Status {
+		return false
+	}
+	if condition, ok := n.GetCondition(NodeReady); ok {
+		return condition.Status
+	}
+	return true
+}
+
+// ContainerImage describes an image present on a worker node
+type ContainerImage struct {
+	// ID is the image ID (content digest)
+	ID string `json:"id"`
+
+	// Names are the repository tags referring to the image
+	Names []string `json:"names,omitempty"`
+
+	// SizeBytes is the size of the image on disk
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// LastUsed is when a container was last started from the image on this node
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
 }
 
 // GetMaxTaskSlots returns the maximum number of tasks that can run on the node
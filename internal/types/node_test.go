@@ -94,3 +94,92 @@ func TestNode_GetMaxTaskSlots(t *testing.T) {
 		)
 	}
 }
+
+func TestNode_HasPressure(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []NodeCondition
+		want       bool
+	}{
+		{
+			name: "no conditions",
+			want: false,
+		},
+		{
+			name: "conditions cleared",
+			conditions: []NodeCondition{
+				{Type: NodeMemoryPressure, Status: false},
+				{Type: NodeDiskPressure, Status: false},
+			},
+			want: false,
+		},
+		{
+			name:       "memory pressure",
+			conditions: []NodeCondition{{Type: NodeMemoryPressure, Status: true}},
+			want:       true,
+		},
+		{
+			name:       "disk pressure",
+			conditions: []NodeCondition{{Type: NodeDiskPressure, Status: true}},
+			want:       true,
+		},
+		{
+			name:       "pid pressure",
+			conditions: []NodeCondition{{Type: NodePIDPressure, Status: true}},
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				node := Node{NodeID: "node1", Conditions: tt.conditions}
+				if got := node.HasPressure(); got != tt.want {
+					t.Errorf("HasPressure() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestNode_IsReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []NodeCondition
+		want       bool
+	}{
+		{
+			name: "no conditions reported",
+			want: true,
+		},
+		{
+			name:       "ready",
+			conditions: []NodeCondition{{Type: NodeReady, Status: true}},
+			want:       true,
+		},
+		{
+			name:       "not ready",
+			conditions: []NodeCondition{{Type: NodeReady, Status: false}},
+			want:       false,
+		},
+		{
+			name: "runtime unavailable",
+			conditions: []NodeCondition{
+				{Type: NodeReady, Status: true},
+				{Type: NodeRuntimeUnavailable, Status: true},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				node := &Node{Conditions: tt.conditions}
+				if got := node.IsReady(); got != tt.want {
+					t.Errorf("IsReady() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // PodStatus represents the current state of a pod
 type PodStatus string
@@ -61,8 +64,80 @@ type Pod struct {
 
 	// Reason is a brief CamelCase message indicating why the pod is in its current state
 	Reason string `json:"reason,omitempty"`
+
+	// ImagePullSecrets are names of registry secrets (in the pod's namespace)
+	// used to authenticate image pulls
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// SecurityContext holds defaults applied to every container in the pod
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+
+	// QOSClass is derived from the containers' resource requests and limits when the pod is created
+	QOSClass QOSClass `json:"qosClass,omitempty"`
+
+	// Events is a short history of notable things that happened to the pod
+	Events []PodEvent `json:"events,omitempty"`
+}
+
+// PodEvent records a single notable occurrence in a pod's lifecycle
+type PodEvent struct {
+	// Type is either Normal or Warning
+	Type string `json:"type"`
+
+	// Reason is a brief CamelCase identifier (e.g., "Pulled", "ErrImagePull")
+	Reason string `json:"reason"`
+
+	// Message is a human-readable description of the event
+	Message string `json:"message,omitempty"`
+
+	// Container is the name of the container the event refers to, if any
+	Container string `json:"container,omitempty"`
+
+	// Timestamp is when the event occurred
+	Timestamp time.Time `json:"timestamp"`
 }
 
+const (
+	// EventTypeNormal is used for informational events
+	EventTypeNormal = "Normal"
+	// EventTypeWarning is used for events that indicate a problem
+	EventTypeWarning = "Warning"
+)
+
+// MaxPodEvents is the maximum number of events kept per pod
+const MaxPodEvents = 50
+
+// ImagePullPolicy describes when the worker should pull a container image
+type ImagePullPolicy string
+
+const (
+	// PullAlways always pulls the image before starting the container
+	PullAlways ImagePullPolicy = "Always"
+	// PullIfNotPresent pulls the image only if it is missing on the node
+	PullIfNotPresent ImagePullPolicy = "IfNotPresent"
+	// PullNever never pulls; the image must already exist on the node
+	PullNever ImagePullPolicy = "Never"
+)
+
+// IsValid returns true if the policy is empty or one of the known values
+func (p ImagePullPolicy) IsValid() bool {
+	switch p {
+	case "", PullAlways, PullIfNotPresent, PullNever:
+		return true
+	}
+	return false
+}
+
+// Image pull related reasons reported on pods
+const (
+	ReasonErrImagePull      = "ErrImagePull"
+	ReasonImagePullBackOff  = "ImagePullBackOff"
+	ReasonErrImageNeverPull = "ErrImageNeverPull"
+)
+
+// ReasonEvicted is set on pods that a worker terminated to relieve node resource pressure
+const ReasonEvicted = "Evicted"
+
 // Container represents a single container within a pod
 type Container struct {
 	// Name is a unique name for the container within the pod
@@ -95,6 +170,13 @@ type Container struct {
 	// Resources specifies the compute resources required by this container
 	Resources ResourceRequirements `json:"resources,omitempty"`
 
+	// ImagePullPolicy controls when the image is pulled
+	// Defaults to Always for ":latest" or untagged images, IfNotPresent otherwise
+	ImagePullPolicy ImagePullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// SecurityContext overrides the pod's security settings for this container
+	SecurityContext *SecurityContext `json:"securityContext,omitempty"`
+
 	// ---- Runtime fields (populated by worker) ----
 
 	// ContainerID is the Docker container ID (set by worker)
@@ -122,6 +204,46 @@ type Container struct {
 	RestartCount int `json:"restartCount,omitempty"`
 }
 
+// GetImagePullPolicy returns the effective pull policy for the container
+func (c *Container) GetImagePullPolicy() ImagePullPolicy {
+	if c.ImagePullPolicy != "" {
+		return c.ImagePullPolicy
+	}
+
+	image := c.Image
+	if strings.Contains(image, "@") {
+		return PullIfNotPresent
+	}
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i < 0 || image[i+1:] == "latest" {
+		return PullAlways
+	}
+	return PullIfNotPresent
+}
+
+// IsPrivileged reports whether any container in the pod runs in privileged mode
+func (p *Pod) IsPrivileged() bool {
+	for i := range p.Containers {
+		if MergeSecurityContext(p.SecurityContext, p.Containers[i].SecurityContext).IsPrivileged() {
+			return true
+		}
+	}
+	return false
+}
+
+// AddEvent appends an event to the pod, keeping at most MaxPodEvents entries
+func (p *Pod) AddEvent(event PodEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	p.Events = append(p.Events, event)
+	if len(p.Events) > MaxPodEvents {
+		p.Events = p.Events[len(p.Events)-MaxPodEvents:]
+	}
+}
+
 // ContainerPort represents a network port in a single container
 type ContainerPort struct {
 	// Name is an optional name for the port (e.g., "http", "metrics")
@@ -187,17 +309,31 @@ func (p *Pod) GetContainerByName(name string) *Container {
 // GetTotalResourceRequests returns the sum of all container resource requests
 // This is used for scheduling - the pod needs at least this much resources
 func (p *Pod) GetTotalResourceRequests() ResourceRequirements {
-	var totalCPU, totalMemory int64
+	var total ResourceList
 
 	for _, container := range p.Containers {
-		totalCPU += container.Resources.Requests.CPU
-		totalMemory += container.Resources.Requests.Memory
+		total.CPU += container.Resources.Requests.CPU
+		total.Memory += container.Resources.Requests.Memory
+		total.EphemeralStorage += container.Resources.Requests.EphemeralStorage
+		total.PIDs += container.Resources.Requests.PIDs
 	}
 
 	return ResourceRequirements{
-		Requests: ResourceList{
-			CPU:    totalCPU,
-			Memory: totalMemory,
-		},
+		Requests: total,
 	}
 }
+
+// GetTotalResourceLimits returns the sum of all container resource limits
+// A container without a limit for a resource contributes zero
+func (p *Pod) GetTotalResourceLimits() ResourceList {
+	var total ResourceList
+
+	for _, container := range p.Containers {
+		total.CPU += container.Resources.Limits.CPU
+		total.Memory += container.Resources.Limits.Memory
+		total.EphemeralStorage += container.Resources.Limits.EphemeralStorage
+		total.PIDs += container.Resources.Limits.PIDs
+	}
+
+	return total
+}
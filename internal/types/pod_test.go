@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestContainer_GetImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		container Container
+		want      ImagePullPolicy
+	}{
+		{"explicit policy wins", Container{Image: "nginx:latest", ImagePullPolicy: PullNever}, PullNever},
+		{"untagged image", Container{Image: "nginx"}, PullAlways},
+		{"latest tag", Container{Image: "nginx:latest"}, PullAlways},
+		{"pinned tag", Container{Image: "nginx:1.25"}, PullIfNotPresent},
+		{"registry with port, untagged", Container{Image: "localhost:5000/app"}, PullAlways},
+		{"registry with port, tagged", Container{Image: "localhost:5000/app:v1"}, PullIfNotPresent},
+		{"digest", Container{Image: "nginx@sha256:abcd"}, PullIfNotPresent},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.container.GetImagePullPolicy(); got != tt.want {
+					t.Errorf("GetImagePullPolicy() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestImagePullPolicy_IsValid(t *testing.T) {
+	tests := []struct {
+		policy ImagePullPolicy
+		want   bool
+	}{
+		{"", true},
+		{PullAlways, true},
+		{PullIfNotPresent, true},
+		{PullNever, true},
+		{"Sometimes", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.IsValid(); got != tt.want {
+			t.Errorf("ImagePullPolicy(%q).IsValid() = %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestPod_AddEvent(t *testing.T) {
+	pod := &Pod{}
+
+	for i := 0; i < MaxPodEvents+5; i++ {
+		pod.AddEvent(PodEvent{Type: EventTypeNormal, Reason: fmt.Sprintf("Event%d", i)})
+	}
+
+	if len(pod.Events) != MaxPodEvents {
+		t.Fatalf("expected %d events, got %d", MaxPodEvents, len(pod.Events))
+	}
+	if pod.Events[0].Reason != "Event5" {
+		t.Errorf("expected oldest events to be dropped, first event is %s", pod.Events[0].Reason)
+	}
+	if pod.Events[0].Timestamp.IsZero() {
+		t.Error("expected timestamp to be set")
+	}
+}
@@ -0,0 +1,69 @@
+package types
+
+// QOSClass is the quality of service class of a pod, derived from its resource requests and limits
+type QOSClass string
+
+const (
+	// QOSGuaranteed pods set equal CPU and memory requests and limits for every container
+	QOSGuaranteed QOSClass = "Guaranteed"
+
+	// QOSBurstable pods set some requests or limits but are not Guaranteed
+	QOSBurstable QOSClass = "Burstable"
+
+	// QOSBestEffort pods set no CPU or memory requests or limits
+	QOSBestEffort QOSClass = "BestEffort"
+)
+
+// Rank orders QoS classes by eviction priority; lower ranks are evicted first
+func (q QOSClass) Rank() int {
+	switch q {
+	case QOSBestEffort:
+		return 0
+	case QOSBurstable:
+		return 1
+	case QOSGuaranteed:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// GetQOSClass classifies the pod following Kubernetes rules.
+// A request that is not set defaults to the limit when checking for Guaranteed.
+func (p *Pod) GetQOSClass() QOSClass {
+	if len(p.Containers) == 0 {
+		return QOSBestEffort
+	}
+
+	bestEffort := true
+	guaranteed := true
+
+	for _, container := range p.Containers {
+		requests := container.Resources.Requests
+		limits := container.Resources.Limits
+
+		if requests.CPU != 0 || requests.Memory != 0 || limits.CPU != 0 || limits.Memory != 0 {
+			bestEffort = false
+		}
+
+		if limits.CPU == 0 || limits.Memory == 0 {
+			guaranteed = false
+			continue
+		}
+		if requests.CPU != 0 && requests.CPU != limits.CPU {
+			guaranteed = false
+		}
+		if requests.Memory != 0 && requests.Memory != limits.Memory {
+			guaranteed = false
+		}
+	}
+
+	switch {
+	case bestEffort:
+		return QOSBestEffort
+	case guaranteed:
+		return QOSGuaranteed
+	default:
+		return QOSBurstable
+	}
+}
@@ -0,0 +1,88 @@
+package types
+
+import "testing"
+
+func TestPod_GetQOSClass(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []Container
+		want       QOSClass
+	}{
+		{
+			name:       "no resources",
+			containers: []Container{{Name: "app"}},
+			want:       QOSBestEffort,
+		},
+		{
+			name: "limits equal requests",
+			containers: []Container{
+				{
+					Name: "app",
+					Resources: ResourceRequirements{
+						Requests: ResourceList{CPU: 500, Memory: 256 * 1024 * 1024},
+						Limits:   ResourceList{CPU: 500, Memory: 256 * 1024 * 1024},
+					},
+				},
+			},
+			want: QOSGuaranteed,
+		},
+		{
+			name: "limits only default requests",
+			containers: []Container{
+				{
+					Name:      "app",
+					Resources: ResourceRequirements{Limits: ResourceList{CPU: 500, Memory: 256 * 1024 * 1024}},
+				},
+			},
+			want: QOSGuaranteed,
+		},
+		{
+			name: "requests below limits",
+			containers: []Container{
+				{
+					Name: "app",
+					Resources: ResourceRequirements{
+						Requests: ResourceList{CPU: 250, Memory: 128 * 1024 * 1024},
+						Limits:   ResourceList{CPU: 500, Memory: 256 * 1024 * 1024},
+					},
+				},
+			},
+			want: QOSBurstable,
+		},
+		{
+			name: "one container without resources",
+			containers: []Container{
+				{
+					Name:      "app",
+					Resources: ResourceRequirements{Limits: ResourceList{CPU: 500, Memory: 256 * 1024 * 1024}},
+				},
+				{Name: "sidecar"},
+			},
+			want: QOSBurstable,
+		},
+		{
+			name: "memory request only",
+			containers: []Container{
+				{Name: "app", Resources: ResourceRequirements{Requests: ResourceList{Memory: 64 * 1024 * 1024}}},
+			},
+			want: QOSBurstable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				pod := Pod{Containers: tt.containers}
+				if got := pod.GetQOSClass(); got != tt.want {
+					t.Errorf("GetQOSClass() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestQOSClass_Rank(t *testing.T) {
+	if !(QOSBestEffort.Rank() < QOSBurstable.Rank() && QOSBurstable.Rank() < QOSGuaranteed.Rank()) {
+		t.Errorf("expected BestEffort < Burstable < Guaranteed eviction ranks")
+	}
+}
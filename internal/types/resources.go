@@ -29,6 +29,13 @@ type ResourceList struct {
 	// Memory in bytes
 	// Can be parsed from strings like "256Mi", "1Gi", "512000000" (bytes)
 	Memory int64 `json:"memory,omitempty"`
+
+	// EphemeralStorage is the container's writable layer size in bytes
+	// Parsed with the same units as memory, e.g. "10Gi"
+	EphemeralStorage int64 `json:"ephemeralStorage,omitempty"`
+
+	// PIDs is the maximum number of processes
+	PIDs int64 `json:"pids,omitempty"`
 }
 
 // NodeResources tracks the total capacity and current usage of a node
@@ -49,12 +56,15 @@ type NodeResources struct {
 // Available returns the amount of resources still available for scheduling
 func (nr *NodeResources) Available() ResourceList {
 	return ResourceList{
-		CPU:    nr.Allocatable.CPU - nr.Used.CPU,
-		Memory: nr.Allocatable.Memory - nr.Used.Memory,
+		CPU:              nr.Allocatable.CPU - nr.Used.CPU,
+		Memory:           nr.Allocatable.Memory - nr.Used.Memory,
+		EphemeralStorage: nr.Allocatable.EphemeralStorage - nr.Used.EphemeralStorage,
+		PIDs:             nr.Allocatable.PIDs - nr.Used.PIDs,
 	}
 }
 
 // CanFit checks if the given resource requirements can fit on the node
+// Ephemeral storage and PIDs are only checked when the node reports them
 func (nr *NodeResources) CanFit(req ResourceRequirements) bool {
 	available := nr.Available()
 
@@ -65,6 +75,12 @@ func (nr *NodeResources) CanFit(req ResourceRequirements) bool {
 	if req.Requests.Memory > available.Memory {
 		return false
 	}
+	if nr.Allocatable.EphemeralStorage > 0 && req.Requests.EphemeralStorage > available.EphemeralStorage {
+		return false
+	}
+	if nr.Allocatable.PIDs > 0 && req.Requests.PIDs > available.PIDs {
+		return false
+	}
 
 	return true
 }
@@ -73,12 +89,16 @@ func (nr *NodeResources) CanFit(req ResourceRequirements) bool {
 func (nr *NodeResources) Allocate(req ResourceRequirements) {
 	nr.Used.CPU += req.Requests.CPU
 	nr.Used.Memory += req.Requests.Memory
+	nr.Used.EphemeralStorage += req.Requests.EphemeralStorage
+	nr.Used.PIDs += req.Requests.PIDs
 }
 
 // Release subtracts the given resource requirements from the used resources
 func (nr *NodeResources) Release(req ResourceRequirements) {
 	nr.Used.CPU -= req.Requests.CPU
 	nr.Used.Memory -= req.Requests.Memory
+	nr.Used.EphemeralStorage -= req.Requests.EphemeralStorage
+	nr.Used.PIDs -= req.Requests.PIDs
 
 	if nr.Used.CPU < 0 {
 		nr.Used.CPU = 0
@@ -86,6 +106,12 @@ func (nr *NodeResources) Release(req ResourceRequirements) {
 	if nr.Used.Memory < 0 {
 		nr.Used.Memory = 0
 	}
+	if nr.Used.EphemeralStorage < 0 {
+		nr.Used.EphemeralStorage = 0
+	}
+	if nr.Used.PIDs < 0 {
+		nr.Used.PIDs = 0
+	}
 }
 
 // ParseCPU parses a CPU quantity string into millicores
@@ -151,6 +177,30 @@ func ParseMemory(s string) (int64, error) {
 	return bytes, nil
 }
 
+// ParseEphemeralStorage parses an ephemeral storage quantity string into bytes
+// Accepts the same units as ParseMemory, e.g. "10Gi" or "500M"
+func ParseEphemeralStorage(s string) (int64, error) {
+	bytes, err := ParseMemory(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ephemeral storage format: %s", s)
+	}
+	return bytes, nil
+}
+
+// ParsePIDs parses a process count
+func ParsePIDs(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	pids, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || pids < 0 {
+		return 0, fmt.Errorf("invalid pids format: %s", s)
+	}
+
+	return pids, nil
+}
+
 // FormatCPU formats CPU millicores as a human-readable string
 func FormatCPU(millicores int64) string {
 	if millicores == 0 {
@@ -208,7 +258,45 @@ func (rl *ResourceList) GetMemoryLimitForDocker() int64 {
 	return rl.Memory
 }
 
+// GetPidsLimitForDocker returns the process limit for Docker (0 means no limit)
+func (rl *ResourceList) GetPidsLimitForDocker() int64 {
+	return rl.PIDs
+}
+
+// GetStorageLimitForDocker returns the writable layer size limit in bytes (0 means no limit)
+func (rl *ResourceList) GetStorageLimitForDocker() int64 {
+	return rl.EphemeralStorage
+}
+
 // IsZero returns true if the resource list has no resources specified
 func (rl *ResourceList) IsZero() bool {
-	return rl.CPU == 0 && rl.Memory == 0
+	return rl.CPU == 0 && rl.Memory == 0 && rl.EphemeralStorage == 0 && rl.PIDs == 0
+}
+
+// Docker CPU share bounds, matching the Linux cgroup limits
+const (
+	minCPUShares = 2
+	maxCPUShares = 262144
+)
+
+// GetCPUSharesForDocker converts the CPU request into Docker CPU shares (1 core = 1024 shares)
+// so that requests act as a relative weight when CPU is contended.
+// Falls back to the CPU limit when no request is set; 0 keeps Docker's default weight.
+func (rr *ResourceRequirements) GetCPUSharesForDocker() int64 {
+	milliCPU := rr.Requests.CPU
+	if milliCPU == 0 {
+		milliCPU = rr.Limits.CPU
+	}
+	if milliCPU == 0 {
+		return 0
+	}
+
+	shares := milliCPU * 1024 / 1000
+	if shares < minCPUShares {
+		return minCPUShares
+	}
+	if shares > maxCPUShares {
+		return maxCPUShares
+	}
+	return shares
 }
@@ -316,6 +316,8 @@ func TestResourceList_IsZero(t *testing.T) {
 		{"has CPU", ResourceList{CPU: 100}, false},
 		{"has Memory", ResourceList{Memory: 100}, false},
 		{"has both", ResourceList{CPU: 100, Memory: 100}, false},
+		{"has EphemeralStorage", ResourceList{EphemeralStorage: 100}, false},
+		{"has PIDs", ResourceList{PIDs: 100}, false},
 	}
 
 	for _, tt := range tests {
@@ -360,3 +362,138 @@ func TestPod_GetTotalResourceRequests(t *testing.T) {
 		t.Errorf("Total Memory requests = %v, want %v", total.Requests.Memory, wantMemory)
 	}
 }
+
+func TestParseEphemeralStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"empty string", "", 0, false},
+		{"Gi", "10Gi", 10 * 1024 * 1024 * 1024, false},
+		{"decimal G", "1G", 1000 * 1000 * 1000, false},
+		{"bytes", "4096", 4096, false},
+		{"invalid", "lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParseEphemeralStorage(tt.input)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ParseEphemeralStorage() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if got != tt.want {
+					t.Errorf("ParseEphemeralStorage() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestParsePIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"empty string", "", 0, false},
+		{"count", "256", 256, false},
+		{"negative", "-1", 0, true},
+		{"unit suffix", "1Ki", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := ParsePIDs(tt.input)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("ParsePIDs() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if got != tt.want {
+					t.Errorf("ParsePIDs() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestResourceRequirements_GetCPUSharesForDocker(t *testing.T) {
+	tests := []struct {
+		name string
+		rr   ResourceRequirements
+		want int64
+	}{
+		{"no cpu", ResourceRequirements{}, 0},
+		{"one core request", ResourceRequirements{Requests: ResourceList{CPU: 1000}}, 1024},
+		{"half core request", ResourceRequirements{Requests: ResourceList{CPU: 500}}, 512},
+		{"tiny request uses minimum", ResourceRequirements{Requests: ResourceList{CPU: 1}}, 2},
+		{"falls back to limit", ResourceRequirements{Limits: ResourceList{CPU: 2000}}, 2048},
+		{
+			"request takes precedence over limit",
+			ResourceRequirements{Requests: ResourceList{CPU: 250}, Limits: ResourceList{CPU: 2000}},
+			256,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.rr.GetCPUSharesForDocker(); got != tt.want {
+					t.Errorf("GetCPUSharesForDocker() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestNodeResources_CanFit_StorageAndPIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		nr   NodeResources
+		req  ResourceList
+		want bool
+	}{
+		{
+			name: "storage fits",
+			nr:   NodeResources{Allocatable: ResourceList{CPU: 1000, Memory: 1024, EphemeralStorage: 100}},
+			req:  ResourceList{EphemeralStorage: 100},
+			want: true,
+		},
+		{
+			name: "storage too large",
+			nr: NodeResources{
+				Allocatable: ResourceList{CPU: 1000, Memory: 1024, EphemeralStorage: 100},
+				Used:        ResourceList{EphemeralStorage: 50},
+			},
+			req:  ResourceList{EphemeralStorage: 60},
+			want: false,
+		},
+		{
+			name: "pids too large",
+			nr:   NodeResources{Allocatable: ResourceList{CPU: 1000, Memory: 1024, PIDs: 100}},
+			req:  ResourceList{PIDs: 200},
+			want: false,
+		},
+		{
+			name: "unreported node capacity is not checked",
+			nr:   NodeResources{Allocatable: ResourceList{CPU: 1000, Memory: 1024}},
+			req:  ResourceList{EphemeralStorage: 1 << 40, PIDs: 1 << 20},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.nr.CanFit(ResourceRequirements{Requests: tt.req}); got != tt.want {
+					t.Errorf("CanFit() = %v, want %v", got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// SecretType defines the kind of data stored in a secret
+type SecretType string
+
+const (
+	// SecretTypeOpaque holds arbitrary key-value data
+	SecretTypeOpaque SecretType = "Opaque"
+
+	// SecretTypeDockerRegistry holds credentials for a container registry
+	// Required keys: server, username, password
+	SecretTypeDockerRegistry SecretType = "DockerRegistry"
+
+	// SecretTypeTLS holds a PEM certificate chain and its private key
+	// Required keys: tls.crt, tls.key
+	SecretTypeTLS SecretType = "TLS"
+)
+
+// Keys used by SecretTypeDockerRegistry secrets
+const (
+	SecretKeyServer   = "server"
+	SecretKeyUsername = "username"
+	SecretKeyPassword = "password"
+)
+
+// Keys used by SecretTypeTLS secrets
+const (
+	SecretKeyTLSCert = "tls.crt"
+	SecretKeyTLSKey  = "tls.key"
+)
+
+// Secret holds sensitive data such as registry credentials
+type Secret struct {
+	// SecretID is the unique identifier for the secret
+	SecretID string `json:"secretId"`
+
+	// Name is a human-readable name, unique within the namespace
+	Name string `json:"name"`
+
+	// Namespace is the logical grouping for the secret
+	Namespace string `json:"namespace,omitempty"`
+
+	// Type determines how the data is interpreted
+	Type SecretType `json:"type"`
+
+	// Data holds the secret values
+	Data map[string]string `json:"data,omitempty"`
+
+	// CreatedAt is when the secret was created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate checks that the secret has the keys required by its type
+func (s *Secret) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch s.Type {
+	case SecretTypeOpaque:
+	case SecretTypeDockerRegistry:
+		for _, key := range []string{SecretKeyServer, SecretKeyUsername, SecretKeyPassword} {
+			if s.Data[key] == "" {
+				return fmt.Errorf("%s secret requires key %q", s.Type, key)
+			}
+		}
+	case SecretTypeTLS:
+		for _, key := range []string{SecretKeyTLSCert, SecretKeyTLSKey} {
+			if s.Data[key] == "" {
+				return fmt.Errorf("%s secret requires key %q", s.Type, key)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown secret type: %s", s.Type)
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the secret with all values hidden
+func (s Secret) Redacted() Secret {
+	if s.Data != nil {
+		data := make(map[string]string, len(s.Data))
+		for k := range s.Data {
+			data[k] = "<redacted>"
+		}
+		s.Data = data
+	}
+	return s
+}
+
+// RegistryCredentials are the resolved credentials for a single registry
+type RegistryCredentials struct {
+	Server   string `json:"server"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RegistryCredentials extracts registry credentials from a DockerRegistry secret
+func (s *Secret) RegistryCredentials() (RegistryCredentials, error) {
+	if s.Type != SecretTypeDockerRegistry {
+		return RegistryCredentials{}, fmt.Errorf("secret %s is not of type %s", s.Name, SecretTypeDockerRegistry)
+	}
+	return RegistryCredentials{
+		Server:   s.Data[SecretKeyServer],
+		Username: s.Data[SecretKeyUsername],
+		Password: s.Data[SecretKeyPassword],
+	}, nil
+}
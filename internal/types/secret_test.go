@@ -0,0 +1,68 @@
+package types
+
+import "testing"
+
+func TestSecret_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  Secret
+		wantErr bool
+	}{
+		{
+			name:   "valid opaque",
+			secret: Secret{Name: "keys", Type: SecretTypeOpaque, Data: map[string]string{"token": "abc"}},
+		},
+		{
+			name: "valid registry",
+			secret: Secret{
+				Name: "regcred",
+				Type: SecretTypeDockerRegistry,
+				Data: map[string]string{"server": "registry.example.com", "username": "u", "password": "p"},
+			},
+		},
+		{
+			name:    "registry missing password",
+			secret:  Secret{Name: "regcred", Type: SecretTypeDockerRegistry, Data: map[string]string{"server": "r", "username": "u"}},
+			wantErr: true,
+		},
+		{
+			name:    "tls missing key",
+			secret:  Secret{Name: "cert", Type: SecretTypeTLS, Data: map[string]string{"tls.crt": "pem"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			secret:  Secret{Type: SecretTypeOpaque},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			secret:  Secret{Name: "x", Type: "Bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				err := tt.secret.Validate()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	secret := Secret{Name: "regcred", Data: map[string]string{"password": "hunter2"}}
+
+	redacted := secret.Redacted()
+
+	if redacted.Data["password"] == "hunter2" {
+		t.Error("expected password to be redacted")
+	}
+	if secret.Data["password"] != "hunter2" {
+		t.Error("expected original secret to be unchanged")
+	}
+}
@@ -0,0 +1,145 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Well-known seccomp and AppArmor profile names
+const (
+	// ProfileRuntimeDefault uses the container runtime's default profile
+	ProfileRuntimeDefault = "runtime/default"
+
+	// ProfileUnconfined disables the profile entirely
+	ProfileUnconfined = "unconfined"
+)
+
+// SecurityContext holds privilege and access control settings for a container.
+// When set on a pod it provides defaults for all of the pod's containers.
+type SecurityContext struct {
+	// RunAsUser is the UID to run the container process as
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// RunAsGroup is the GID to run the container process as (requires RunAsUser)
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// ReadOnlyRootFilesystem mounts the container's root filesystem as read-only
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+
+	// Privileged runs the container with all capabilities and host device access
+	Privileged *bool `json:"privileged,omitempty"`
+
+	// NoNewPrivileges prevents processes from gaining more privileges than their parent
+	NoNewPrivileges *bool `json:"noNewPrivileges,omitempty"`
+
+	// Capabilities are the Linux capabilities to add to or drop from the default set
+	Capabilities *Capabilities `json:"capabilities,omitempty"`
+
+	// SeccompProfile is "runtime/default", "unconfined", or the path of a
+	// JSON seccomp profile on the worker
+	SeccompProfile string `json:"seccompProfile,omitempty"`
+
+	// AppArmorProfile is "runtime/default", "unconfined", or the name of a
+	// profile loaded on the worker
+	AppArmorProfile string `json:"appArmorProfile,omitempty"`
+}
+
+// Capabilities lists Linux capabilities to add or drop, e.g. "NET_ADMIN" or "ALL"
+type Capabilities struct {
+	Add  []string `json:"add,omitempty"`
+	Drop []string `json:"drop,omitempty"`
+}
+
+// Validate checks the security context for invalid combinations
+func (sc *SecurityContext) Validate() error {
+	if sc == nil {
+		return nil
+	}
+
+	if sc.RunAsUser != nil && *sc.RunAsUser < 0 {
+		return fmt.Errorf("runAsUser must be non-negative")
+	}
+	if sc.RunAsGroup != nil {
+		if *sc.RunAsGroup < 0 {
+			return fmt.Errorf("runAsGroup must be non-negative")
+		}
+		if sc.RunAsUser == nil {
+			return fmt.Errorf("runAsGroup requires runAsUser")
+		}
+	}
+
+	if sc.Capabilities != nil {
+		for _, capability := range append(sc.Capabilities.Add, sc.Capabilities.Drop...) {
+			if !isValidCapability(capability) {
+				return fmt.Errorf("invalid capability: %q", capability)
+			}
+		}
+	}
+
+	if sc.AppArmorProfile != "" && strings.ContainsAny(sc.AppArmorProfile, " ,=") {
+		return fmt.Errorf("invalid appArmorProfile: %q", sc.AppArmorProfile)
+	}
+
+	return nil
+}
+
+// IsPrivileged reports whether the security context requests privileged mode
+func (sc *SecurityContext) IsPrivileged() bool {
+	return sc != nil && sc.Privileged != nil && *sc.Privileged
+}
+
+// MergeSecurityContext returns the effective security context for a container.
+// Fields set on the container override those inherited from the pod.
+func MergeSecurityContext(pod, container *SecurityContext) *SecurityContext {
+	if pod == nil && container == nil {
+		return nil
+	}
+
+	merged := &SecurityContext{}
+	if pod != nil {
+		*merged = *pod
+	}
+	if container == nil {
+		return merged
+	}
+
+	if container.RunAsUser != nil {
+		merged.RunAsUser = container.RunAsUser
+	}
+	if container.RunAsGroup != nil {
+		merged.RunAsGroup = container.RunAsGroup
+	}
+	if container.ReadOnlyRootFilesystem != nil {
+		merged.ReadOnlyRootFilesystem = container.ReadOnlyRootFilesystem
+	}
+	if container.Privileged != nil {
+		merged.Privileged = container.Privileged
+	}
+	if container.NoNewPrivileges != nil {
+		merged.NoNewPrivileges = container.NoNewPrivileges
+	}
+	if container.Capabilities != nil {
+		merged.Capabilities = container.Capabilities
+	}
+	if container.SeccompProfile != "" {
+		merged.SeccompProfile = container.SeccompProfile
+	}
+	if container.AppArmorProfile != "" {
+		merged.AppArmorProfile = container.AppArmorProfile
+	}
+
+	return merged
+}
+
+// isValidCapability checks that a capability name looks like "NET_ADMIN", "CAP_NET_ADMIN" or "ALL"
+func isValidCapability(capability string) bool {
+	if capability == "" {
+		return false
+	}
+	for _, r := range capability {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
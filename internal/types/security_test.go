@@ -0,0 +1,98 @@
+package types
+
+import "testing"
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestSecurityContextValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sc      *SecurityContext
+		wantErr bool
+	}{
+		{name: "nil", sc: nil},
+		{name: "user and group", sc: &SecurityContext{RunAsUser: int64Ptr(1000), RunAsGroup: int64Ptr(1000)}},
+		{name: "negative user", sc: &SecurityContext{RunAsUser: int64Ptr(-1)}, wantErr: true},
+		{name: "group without user", sc: &SecurityContext{RunAsGroup: int64Ptr(1000)}, wantErr: true},
+		{
+			name: "valid capabilities",
+			sc:   &SecurityContext{Capabilities: &Capabilities{Add: []string{"NET_ADMIN"}, Drop: []string{"ALL"}}},
+		},
+		{
+			name:    "lowercase capability",
+			sc:      &SecurityContext{Capabilities: &Capabilities{Add: []string{"net_admin"}}},
+			wantErr: true,
+		},
+		{name: "apparmor with comma", sc: &SecurityContext{AppArmorProfile: "a,b"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if err := tt.sc.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestMergeSecurityContext(t *testing.T) {
+	pod := &SecurityContext{
+		RunAsUser:      int64Ptr(1000),
+		Privileged:     boolPtr(true),
+		SeccompProfile: ProfileRuntimeDefault,
+		Capabilities:   &Capabilities{Drop: []string{"ALL"}},
+	}
+	container := &SecurityContext{
+		RunAsUser:  int64Ptr(2000),
+		Privileged: boolPtr(false),
+	}
+
+	merged := MergeSecurityContext(pod, container)
+	if *merged.RunAsUser != 2000 {
+		t.Errorf("RunAsUser = %d, want 2000", *merged.RunAsUser)
+	}
+	if merged.IsPrivileged() {
+		t.Error("expected container to override privileged")
+	}
+	if merged.SeccompProfile != ProfileRuntimeDefault {
+		t.Errorf("SeccompProfile = %q, want inherited %q", merged.SeccompProfile, ProfileRuntimeDefault)
+	}
+	if merged.Capabilities == nil || merged.Capabilities.Drop[0] != "ALL" {
+		t.Error("expected capabilities to be inherited from pod")
+	}
+	if *pod.RunAsUser != 1000 {
+		t.Error("merge must not modify the pod security context")
+	}
+
+	if MergeSecurityContext(nil, nil) != nil {
+		t.Error("expected nil when neither context is set")
+	}
+	if got := MergeSecurityContext(nil, container); *got.RunAsUser != 2000 {
+		t.Error("expected container context when pod context is nil")
+	}
+}
+
+func TestPodIsPrivileged(t *testing.T) {
+	pod := Pod{
+		Containers: []Container{
+			{Name: "app"},
+			{Name: "debug", SecurityContext: &SecurityContext{Privileged: boolPtr(true)}},
+		},
+	}
+	if !pod.IsPrivileged() {
+		t.Error("expected pod with privileged container to be privileged")
+	}
+
+	pod.Containers = pod.Containers[:1]
+	if pod.IsPrivileged() {
+		t.Error("expected pod without privileged containers to be unprivileged")
+	}
+}
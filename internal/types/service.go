@@ -17,8 +17,65 @@ const (
 	// ServiceTypeLoadBalancer exposes the service externally using a load balancer
 	// NodePort and ClusterIP services are automatically created
 	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+
+	// ServiceTypeExternalName aliases the service's DNS name to an external name with a CNAME
+	// record; it gets no ClusterIP, node ports or endpoints
+	ServiceTypeExternalName ServiceType = "ExternalName"
+)
+
+// Session affinity modes
+const (
+	// SessionAffinityNone spreads every connection independently (default)
+	SessionAffinityNone = "None"
+
+	// SessionAffinityClientIP sends connections from the same client IP to the same endpoint
+	SessionAffinityClientIP = "ClientIP"
 )
 
+const (
+	// DefaultSessionAffinityTimeout is how long, in seconds, a client sticks to an endpoint
+	// after its last connection when the service does not set a timeout
+	DefaultSessionAffinityTimeout = 10800
+
+	// MaxSessionAffinityTimeout is the longest session affinity timeout, in seconds
+	MaxSessionAffinityTimeout = 86400
+)
+
+// LoadBalancingAlgorithm selects how a service spreads connections over its endpoints
+type LoadBalancingAlgorithm string
+
+const (
+	// LoadBalancingRoundRobin picks endpoints in turn (default)
+	LoadBalancingRoundRobin LoadBalancingAlgorithm = "RoundRobin"
+
+	// LoadBalancingRandom picks an endpoint at random
+	LoadBalancingRandom LoadBalancingAlgorithm = "Random"
+
+	// LoadBalancingLeastConnections picks the endpoint with the fewest open connections
+	LoadBalancingLeastConnections LoadBalancingAlgorithm = "LeastConnections"
+
+	// LoadBalancingWeighted picks endpoints in proportion to the weight in their pod's
+	// EndpointWeightAnnotation
+	LoadBalancingWeighted LoadBalancingAlgorithm = "Weighted"
+)
+
+// EndpointWeightAnnotation is the pod annotation holding the pod's weight for services using
+// LoadBalancingWeighted; pods without it have weight 1
+const EndpointWeightAnnotation = "podling.io/weight"
+
+// IsValid returns true if the algorithm is known; empty means the default
+func (a LoadBalancingAlgorithm) IsValid() bool {
+	switch a {
+	case "", LoadBalancingRoundRobin, LoadBalancingRandom, LoadBalancingLeastConnections, LoadBalancingWeighted:
+		return true
+	}
+	return false
+}
+
+// ClusterIPNone is the ClusterIP of a headless service, which gets no virtual IP;
+// its DNS name resolves to the addresses of its ready endpoints instead
+const ClusterIPNone = "None"
+
 // Service represents a stable endpoint for a set of pods
 // Similar to Kubernetes Services, it provides service discovery and load balancing
 type Service struct {
@@ -34,10 +91,13 @@ type Service struct {
 	// Type determines how the service is exposed
 	Type ServiceType `json:"type"`
 
-	// ClusterIP is the virtual IP allocated to this service
+	// ClusterIP is the virtual IP allocated to this service, or ClusterIPNone for a headless service
 	// Only valid for ClusterIP and derived types
 	ClusterIP string `json:"clusterIp,omitempty"`
 
+	// ExternalName is the DNS name an ExternalName service is an alias for
+	ExternalName string `json:"externalName,omitempty"`
+
 	// Selector is a label query to identify pods that belong to this service
 	// Pods matching all labels in the selector will receive traffic
 	Selector map[string]string `json:"selector,omitempty"`
@@ -55,11 +115,44 @@ type Service struct {
 	// Valid values: "None" (default), "ClientIP"
 	SessionAffinity string `json:"sessionAffinity,omitempty"`
 
+	// SessionAffinityTimeoutSeconds is how long a client sticks to its endpoint after its last
+	// connection with ClientIP affinity; zero means DefaultSessionAffinityTimeout
+	SessionAffinityTimeoutSeconds int `json:"sessionAffinityTimeoutSeconds,omitempty"`
+
+	// LoadBalancing selects how connections are spread over the endpoints
+	// Valid values: "RoundRobin" (default), "Random", "LeastConnections", "Weighted"
+	LoadBalancing LoadBalancingAlgorithm `json:"loadBalancing,omitempty"`
+
 	// CreatedAt is when the service was created
 	CreatedAt time.Time `json:"createdAt"`
 
 	// UpdatedAt is when the service was last modified
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Status is the observed state of the service, maintained by the master's controllers
+	Status ServiceStatus `json:"status"`
+}
+
+// ServiceStatus represents the observed state of a service
+type ServiceStatus struct {
+	// LoadBalancer holds the external addresses of a LoadBalancer service
+	LoadBalancer LoadBalancerStatus `json:"loadBalancer"`
+}
+
+// LoadBalancerStatus represents the external addresses assigned to a service
+type LoadBalancerStatus struct {
+	// Ingress lists the addresses traffic for the service can be sent to
+	// Empty while the service is waiting for an address
+	Ingress []LoadBalancerIngress `json:"ingress,omitempty"`
+}
+
+// LoadBalancerIngress is a single external address of a service
+type LoadBalancerIngress struct {
+	// IP is set for providers that hand out IP addresses
+	IP string `json:"ip,omitempty"`
+
+	// Hostname is set for providers that hand out DNS names
+	Hostname string `json:"hostname,omitempty"`
 }
 
 // ServicePort represents a port exposed by a service
@@ -74,9 +167,13 @@ type ServicePort struct {
 	Port int `json:"port"`
 
 	// TargetPort is the port to access on the pods selected by this service
-	// If not specified, defaults to Port
+	// If neither it nor TargetPortName is specified, defaults to Port
 	TargetPort int `json:"targetPort,omitempty"`
 
+	// TargetPortName is the name of a container port on the selected pods, resolved per pod
+	// Pods without a container port of that name are left out of the endpoints for this port
+	TargetPortName string `json:"targetPortName,omitempty"`
+
 	// NodePort is the port on each node (only for NodePort/LoadBalancer types)
 	NodePort int `json:"nodePort,omitempty"`
 }
@@ -121,6 +218,9 @@ type EndpointAddress struct {
 
 	// NodeID is the reference to the node hosting the pod
 	NodeID string `json:"nodeId,omitempty"`
+
+	// Weight is the pod's EndpointWeightAnnotation; zero means the default weight of 1
+	Weight int `json:"weight,omitempty"`
 }
 
 // EndpointPort represents a port on an endpoint
@@ -137,11 +237,43 @@ type EndpointPort struct {
 
 // ServiceUpdate represents partial updates to a service
 type ServiceUpdate struct {
-	Selector        *map[string]string `json:"selector,omitempty"`
-	Ports           *[]ServicePort     `json:"ports,omitempty"`
-	Labels          *map[string]string `json:"labels,omitempty"`
-	Annotations     *map[string]string `json:"annotations,omitempty"`
-	SessionAffinity *string            `json:"sessionAffinity,omitempty"`
+	Selector                      *map[string]string      `json:"selector,omitempty"`
+	ExternalName                  *string                 `json:"externalName,omitempty"`
+	Ports                         *[]ServicePort          `json:"ports,omitempty"`
+	Labels                        *map[string]string      `json:"labels,omitempty"`
+	Annotations                   *map[string]string      `json:"annotations,omitempty"`
+	SessionAffinity               *string                 `json:"sessionAffinity,omitempty"`
+	SessionAffinityTimeoutSeconds *int                    `json:"sessionAffinityTimeoutSeconds,omitempty"`
+	LoadBalancing                 *LoadBalancingAlgorithm `json:"loadBalancing,omitempty"`
+	Status                        *ServiceStatus          `json:"status,omitempty"`
+}
+
+// UsesNodePorts returns true if the service's ports are exposed on every node
+func (s *Service) UsesNodePorts() bool {
+	return s.Type == ServiceTypeNodePort || s.Type == ServiceTypeLoadBalancer
+}
+
+// IngressIPs returns the load balancer ingress IP addresses of the service
+func (s *Service) IngressIPs() []string {
+	var ips []string
+	for _, ingress := range s.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			ips = append(ips, ingress.IP)
+		}
+	}
+	return ips
+}
+
+// SessionAffinityTimeout returns how long a client sticks to its endpoint, or zero if the
+// service has no ClientIP affinity
+func (s *Service) SessionAffinityTimeout() time.Duration {
+	if s.SessionAffinity != SessionAffinityClientIP {
+		return 0
+	}
+	if s.SessionAffinityTimeoutSeconds > 0 {
+		return time.Duration(s.SessionAffinityTimeoutSeconds) * time.Second
+	}
+	return DefaultSessionAffinityTimeout * time.Second
 }
 
 // GetPortByName returns a service port by name
@@ -164,6 +296,16 @@ func (s *Service) GetDNSName() string {
 	return s.Name + "." + namespace + ".svc.cluster.local"
 }
 
+// IsHeadless returns true if the service has no virtual IP
+func (s *Service) IsHeadless() bool {
+	return s.ClusterIP == ClusterIPNone
+}
+
+// IsExternalName returns true if the service is a DNS alias for an external name
+func (s *Service) IsExternalName() bool {
+	return s.Type == ServiceTypeExternalName
+}
+
 // HasEndpoints returns true if there are any ready endpoints
 func (e *Endpoints) HasEndpoints() bool {
 	for _, subset := range e.Subsets {
@@ -174,6 +316,30 @@ func (e *Endpoints) HasEndpoints() bool {
 	return false
 }
 
+// TargetPort finds the endpoint port serving the index-th service port. Named service ports are
+// looked up by name; unnamed ones by position. It reports false when the subset's pods do not
+// serve the port, such as when they have no container port with the port's TargetPortName.
+func (s EndpointSubset) TargetPort(index int, port ServicePort) (int, bool) {
+	if port.Name != "" {
+		for _, p := range s.Ports {
+			if p.Name == port.Name {
+				return p.Port, true
+			}
+		}
+	} else if index < len(s.Ports) && s.Ports[index].Name == "" && s.Ports[index].Port > 0 {
+		return s.Ports[index].Port, true
+	}
+
+	// Subsets without ports predate per-pod resolution and serve the numeric target port
+	if len(s.Ports) > 0 || port.TargetPortName != "" {
+		return 0, false
+	}
+	if port.TargetPort > 0 {
+		return port.TargetPort, true
+	}
+	return port.Port, true
+}
+
 // GetAllIPs returns all ready IP addresses across all subsets
 func (e *Endpoints) GetAllIPs() []string {
 	var ips []string
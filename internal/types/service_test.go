@@ -238,3 +238,48 @@ func TestEndpoints_GetAllIPs(t *testing.T) {
 		)
 	}
 }
+
+func TestEndpointSubset_TargetPort(t *testing.T) {
+	subset := EndpointSubset{
+		Ports: []EndpointPort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9100}},
+	}
+
+	tests := []struct {
+		name   string
+		subset EndpointSubset
+		index  int
+		port   ServicePort
+		want   int
+		wantOK bool
+	}{
+		{name: "by name", subset: subset, index: 0, port: ServicePort{Name: "metrics", Port: 9090}, want: 9100, wantOK: true},
+		{name: "missing name", subset: subset, port: ServicePort{Name: "admin", Port: 81, TargetPort: 81}},
+		{
+			name:   "unnamed by position",
+			subset: EndpointSubset{Ports: []EndpointPort{{Port: 8080}, {Port: 8443}}},
+			index:  1,
+			port:   ServicePort{Port: 443, TargetPort: 443},
+			want:   8443,
+			wantOK: true,
+		},
+		{
+			name:   "no ports falls back to the target port",
+			index:  0,
+			port:   ServicePort{Port: 80, TargetPort: 8080},
+			want:   8080,
+			wantOK: true,
+		},
+		{name: "no ports with a named target port", port: ServicePort{Port: 80, TargetPortName: "http"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, ok := tt.subset.TargetPort(tt.index, tt.port)
+				if got != tt.want || ok != tt.wantOK {
+					t.Errorf("TargetPort() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,75 @@
+package types
+
+import "time"
+
+// ContainerStats is the measured resource usage of a single container
+type ContainerStats struct {
+	Name        string `json:"name,omitempty"`
+	ContainerID string `json:"containerId"`
+	// CPU is the average usage in millicores since the previous sample
+	CPU int64 `json:"cpu"`
+	// Memory is the working set in bytes
+	Memory      int64 `json:"memory"`
+	MemoryLimit int64 `json:"memoryLimit,omitempty"`
+	// Network and block IO counters are cumulative since the container started
+	NetworkRxBytes  uint64    `json:"networkRxBytes"`
+	NetworkTxBytes  uint64    `json:"networkTxBytes"`
+	BlockReadBytes  uint64    `json:"blockReadBytes"`
+	BlockWriteBytes uint64    `json:"blockWriteBytes"`
+	PIDs            int64     `json:"pids"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// PodStats is the measured resource usage of a pod and its containers
+type PodStats struct {
+	PodID      string           `json:"podId"`
+	NodeID     string           `json:"nodeId,omitempty"`
+	Usage      ResourceList     `json:"usage"`
+	Containers []ContainerStats `json:"containers"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+// NodeStats is the measured resource usage of a node and the pods running on it
+type NodeStats struct {
+	NodeID    string       `json:"nodeId"`
+	Usage     ResourceList `json:"usage"`
+	Pods      []PodStats   `json:"pods"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// AddContainer appends a container's stats and adds them to the pod's usage
+func (s *PodStats) AddContainer(c ContainerStats) {
+	s.Containers = append(s.Containers, c)
+	s.Usage.CPU += c.CPU
+	s.Usage.Memory += c.Memory
+	s.Usage.PIDs += c.PIDs
+	if c.Timestamp.After(s.Timestamp) {
+		s.Timestamp = c.Timestamp
+	}
+}
+
+// PodMetrics puts a pod's actual usage next to its requests and limits
+type PodMetrics struct {
+	PodID     string       `json:"podId"`
+	Name      string       `json:"name"`
+	Namespace string       `json:"namespace,omitempty"`
+	NodeID    string       `json:"nodeId"`
+	Usage     ResourceList `json:"usage"`
+	Requests  ResourceList `json:"requests"`
+	Limits    ResourceList `json:"limits"`
+	// Available is false when the pod's worker could not be reached for stats
+	Available bool      `json:"available"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// NodeMetrics puts a node's actual usage next to the requests scheduled on it and its capacity
+type NodeMetrics struct {
+	NodeID    string       `json:"nodeId"`
+	Hostname  string       `json:"hostname"`
+	Usage     ResourceList `json:"usage"`
+	Requested ResourceList `json:"requested"`
+	Capacity  ResourceList `json:"capacity"`
+	// Available is false when neither the worker nor its last heartbeat reported usage
+	Available bool      `json:"available"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPodStats_AddContainer(t *testing.T) {
+	now := time.Now()
+	var stats PodStats
+
+	stats.AddContainer(ContainerStats{Name: "app", CPU: 100, Memory: 1024, PIDs: 2, Timestamp: now})
+	stats.AddContainer(ContainerStats{Name: "sidecar", CPU: 50, Memory: 512, PIDs: 1, Timestamp: now.Add(-time.Second)})
+
+	want := ResourceList{CPU: 150, Memory: 1536, PIDs: 3}
+	if stats.Usage != want {
+		t.Errorf("Usage = %+v, want %+v", stats.Usage, want)
+	}
+	if len(stats.Containers) != 2 {
+		t.Errorf("Containers = %d, want 2", len(stats.Containers))
+	}
+	if !stats.Timestamp.Equal(now) {
+		t.Errorf("Timestamp = %v, want the latest container sample %v", stats.Timestamp, now)
+	}
+}
+
+func TestPod_GetTotalResourceLimits(t *testing.T) {
+	pod := Pod{
+		Containers: []Container{
+			{Resources: ResourceRequirements{Limits: ResourceList{CPU: 500, Memory: 256}}},
+			{Resources: ResourceRequirements{Limits: ResourceList{CPU: 250}}},
+		},
+	}
+
+	want := ResourceList{CPU: 750, Memory: 256}
+	if got := pod.GetTotalResourceLimits(); got != want {
+		t.Errorf("GetTotalResourceLimits() = %+v, want %+v", got, want)
+	}
+}
@@ -7,19 +7,26 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/danpasecinic/podling/internal/types"
 	"github.com/danpasecinic/podling/internal/worker/docker"
+	"github.com/danpasecinic/podling/internal/worker/eviction"
 	"github.com/danpasecinic/podling/internal/worker/health"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+	"github.com/danpasecinic/podling/internal/worker/logship"
+	"github.com/danpasecinic/podling/internal/worker/proxy"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/danpasecinic/podling/internal/worker/stats"
 )
 
 // Agent manages task and pod execution and communication with the master.
 type Agent struct {
 	nodeID               string
 	masterURL            string
-	dockerClient         *docker.Client
+	runtime              runtime.Runtime
 	runningTasks         map[string]*types.Task
 	runningPods          map[string]*PodExecution
 	healthCheckers       map[string]*health.Checker
@@ -28,32 +35,79 @@ type Agent struct {
 	stopChan             chan struct{}
 	consecutiveFailures  int
 	maxConsecutiveErrors int
+	pullBackoff          pullBackoff
+	imageGC              *imagegc.Manager
+	eviction             *eviction.Manager
+	storageQuotaOnce     sync.Once
+	storageQuota         bool
+	cpuSampler           *stats.CPUSampler
+	statsCollector       *stats.Collector
+	statsInterval        time.Duration
+	conditionsMu         sync.Mutex
+	conditions           map[types.NodeConditionType]types.NodeCondition
+	logShipper           *logship.Shipper
+	serviceProxy         *proxy.Proxy
+	serviceProxyDone     chan struct{}
+	clusterDNS           *clusterDNS
+	podNetwork           PodNetworkConfig
+	podSubnets           *podSubnets
 }
 
-// NewAgent creates a new worker agent.
+// NewAgent creates a new worker agent that runs containers on Docker.
 func NewAgent(nodeID, masterURL string) (*Agent, error) {
 	dockerClient, err := docker.NewClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create docker client: %w", err)
 	}
 
-	return &Agent{
+	return NewAgentWithRuntime(nodeID, masterURL, dockerClient), nil
+}
+
+// NewAgentWithRuntime creates a new worker agent that runs containers on the given runtime.
+func NewAgentWithRuntime(nodeID, masterURL string, containerRuntime runtime.Runtime) *Agent {
+	a := &Agent{
 		nodeID:               nodeID,
 		masterURL:            masterURL,
-		dockerClient:         dockerClient,
+		runtime:              containerRuntime,
 		runningTasks:         make(map[string]*types.Task),
 		runningPods:          make(map[string]*PodExecution),
 		healthCheckers:       make(map[string]*health.Checker),
 		stopChan:             make(chan struct{}),
 		consecutiveFailures:  0,
 		maxConsecutiveErrors: 10,
-	}, nil
+		pullBackoff:          defaultPullBackoff,
+		cpuSampler:           stats.NewCPUSampler(),
+		statsCollector:       stats.NewCollector(containerRuntime),
+		statsInterval:        defaultStatsInterval,
+		podNetwork:           DefaultPodNetworkConfig(),
+	}
+	a.imageGC = imagegc.NewManager(containerRuntime, imagegc.DefaultConfig(), a.imagesInUse)
+	a.eviction = eviction.NewManager(eviction.DefaultConfig(), &evictionProvider{agent: a})
+
+	return a
 }
 
-// Start begins the agent's background operations (heartbeat).
+// Start begins the agent's background operations (heartbeat, image garbage collection, eviction,
+// stats collection and log shipping).
 func (a *Agent) Start(heartbeatInterval time.Duration) {
 	a.heartbeatTicker = time.NewTicker(heartbeatInterval)
 	go a.heartbeatLoop()
+	if a.imageGC != nil {
+		go a.imageGCLoop()
+	}
+	if a.eviction != nil {
+		go a.evictionLoop()
+	}
+	if a.statsCollector != nil {
+		go a.statsLoop()
+	}
+	if a.logShipper != nil {
+		go a.logShipLoop()
+	}
+	if a.serviceProxy != nil {
+		a.serviceProxyDone = make(chan struct{})
+		go a.serviceProxyLoop()
+	}
 }
 
 // Stop gracefully stops the agent.
@@ -62,8 +116,9 @@ func (a *Agent) Stop() {
 		a.heartbeatTicker.Stop()
 	}
 	close(a.stopChan)
-	if a.dockerClient != nil {
-		_ = a.dockerClient.Close()
+	a.waitForServiceProxy()
+	if a.runtime != nil {
+		_ = a.runtime.Close()
 	}
 }
 
@@ -124,8 +179,8 @@ func (a *Agent) Shutdown(ctx context.Context) error {
 	}
 
 	close(a.stopChan)
-	if a.dockerClient != nil {
-		if err := a.dockerClient.Close(); err != nil {
+	if a.runtime != nil {
+		if err := a.runtime.Close(); err != nil {
 			log.Printf("failed to close docker client: %v", err)
 		}
 	}
@@ -145,10 +200,10 @@ func (a *Agent) cleanupRunningTasks(ctx context.Context) {
 	for _, task := range tasks {
 		if task.ContainerID != "" {
 			log.Printf("force stopping container %s for task %s", task.ContainerID, task.TaskID)
-			if err := a.dockerClient.StopContainer(ctx, task.ContainerID); err != nil {
+			if err := a.runtime.StopContainer(ctx, task.ContainerID); err != nil {
 				log.Printf("error stopping container %s: %v", task.ContainerID, err)
 			}
-			if err := a.dockerClient.RemoveContainer(ctx, task.ContainerID); err != nil {
+			if err := a.runtime.RemoveContainer(ctx, task.ContainerID); err != nil {
 				log.Printf("error removing container %s: %v", task.ContainerID, err)
 			}
 		}
@@ -170,10 +225,10 @@ func (a *Agent) cleanupRunningPods(ctx context.Context) {
 		for _, container := range podExec.pod.Containers {
 			if container.ContainerID != "" {
 				log.Printf("force stopping container %s for pod %s", container.ContainerID, podExec.pod.PodID)
-				if err := a.dockerClient.StopContainer(ctx, container.ContainerID); err != nil {
+				if err := a.runtime.StopContainer(ctx, container.ContainerID); err != nil {
 					log.Printf("error stopping container %s: %v", container.ContainerID, err)
 				}
-				if err := a.dockerClient.RemoveContainer(ctx, container.ContainerID); err != nil {
+				if err := a.runtime.RemoveContainer(ctx, container.ContainerID); err != nil {
 					log.Printf("error removing container %s: %v", container.ContainerID, err)
 				}
 			}
@@ -181,9 +236,10 @@ func (a *Agent) cleanupRunningPods(ctx context.Context) {
 
 		if podExec.networkID != "" {
 			log.Printf("removing pod network %s", podExec.networkID)
-			if err := a.dockerClient.RemovePodNetwork(ctx, podExec.networkID); err != nil {
+			if err := a.runtime.RemovePodNetwork(ctx, podExec.networkID); err != nil {
 				log.Printf("error removing pod network %s: %v", podExec.networkID, err)
 			}
+			a.releasePodSubnet(podExec.pod.PodID)
 		}
 	}
 }
@@ -240,16 +296,32 @@ func (a *Agent) sendHeartbeatWithRetry() error {
 	return fmt.Errorf("heartbeat failed after %d retries: %w", maxRetries, lastErr)
 }
 
-// Register registers the worker node with the master.
+// NodeID returns the ID the worker is registered under.
+func (a *Agent) NodeID() string {
+	return a.nodeID
+}
+
+// Register registers the worker node with the master under its node ID.
+// Registering again after a restart restores the existing node record.
 func (a *Agent) Register(hostname string, port int) error {
 	url := fmt.Sprintf("%s/api/v1/nodes/register", a.masterURL)
 
 	payload := map[string]interface{}{
+		"nodeId":   a.nodeID,
 		"hostname": hostname,
 		"port":     port,
 		"cpu":      "2",
 		"memory":   "2Gi",
 	}
+	if storage := a.ephemeralStorageCapacity(); storage > 0 {
+		payload["ephemeralStorage"] = strconv.FormatInt(storage, 10)
+	}
+	if pids := pidCapacity(); pids > 0 {
+		payload["pids"] = strconv.FormatInt(pids, 10)
+	}
+	if a.podNetwork.InternalIP != "" {
+		payload["internalIp"] = a.podNetwork.InternalIP
+	}
 
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -269,7 +341,8 @@ func (a *Agent) Register(hostname string, port int) error {
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusCreated {
+	// The master answers 200 when the node was already registered under the same ID
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("registration returned status %d", resp.StatusCode)
 	}
 
@@ -282,17 +355,33 @@ func (a *Agent) Register(hostname string, port int) error {
 		a.nodeID = nodeID
 		log.Printf("successfully registered with master as node %s", a.nodeID)
 	}
+	if podCIDR, ok := result["podCIDR"].(string); ok && podCIDR != "" {
+		if err := a.setPodCIDR(podCIDR); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// sendHeartbeat sends a heartbeat to the master node.
+// sendHeartbeat sends a heartbeat to the master node, including the node's status.
 func (a *Agent) sendHeartbeat() error {
 	url := fmt.Sprintf("%s/api/v1/nodes/%s/heartbeat", a.masterURL, a.nodeID)
-	req, err := http.NewRequest(http.MethodPost, url, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	payload := a.heartbeatPayload(ctx)
+	cancel()
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(payloadBytes))
 	if err != nil {
 		return fmt.Errorf("failed to create heartbeat request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Do(req)
@@ -332,7 +421,7 @@ func (a *Agent) deregister() error {
 	return nil
 }
 
-// ExecuteTask executes a task by running it in a Docker container.
+// ExecuteTask executes a task by running it in a container.
 func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 	a.mu.Lock()
 	a.runningTasks[task.TaskID] = task
@@ -348,7 +437,7 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 		log.Printf("failed to update task status to running: %v", err)
 	}
 
-	if err := a.dockerClient.PullImage(ctx, task.Image); err != nil {
+	if err := a.runtime.PullImage(ctx, task.Image); err != nil {
 		if updateErr := a.updateTaskStatus(task.TaskID, types.TaskFailed, "", err.Error()); updateErr != nil {
 			log.Printf("failed to update task status: %v", updateErr)
 		}
@@ -360,36 +449,27 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 		env = append(env, fmt.Sprintf("%s=%s", k, v))
 	}
 
-	// Create container with resource limits and ports if specified
-	var containerID string
-	var err error
-
-	if len(task.Ports) > 0 {
-		ports := make([]docker.PortMapping, len(task.Ports))
-		for i, port := range task.Ports {
-			ports[i] = docker.PortMapping{
-				ContainerPort: port.ContainerPort,
-				HostPort:      port.HostPort,
-				Protocol:      port.Protocol,
-			}
-		}
-
-		cpuLimit := float64(0)
-		memoryLimit := int64(0)
-		if !task.Resources.Limits.IsZero() {
-			cpuLimit = task.Resources.Limits.GetCPULimitForDocker()
-			memoryLimit = task.Resources.Limits.GetMemoryLimitForDocker()
+	ports := make([]runtime.PortMapping, len(task.Ports))
+	for i, port := range task.Ports {
+		ports[i] = runtime.PortMapping{
+			ContainerPort: port.ContainerPort,
+			HostPort:      port.HostPort,
+			Protocol:      port.Protocol,
 		}
+	}
 
-		containerID, err = a.dockerClient.CreateContainerWithResourcesAndPorts(ctx, task.Image, env, cpuLimit, memoryLimit, ports)
-	} else if !task.Resources.Limits.IsZero() {
-		cpuLimit := task.Resources.Limits.GetCPULimitForDocker()
-		memoryLimit := task.Resources.Limits.GetMemoryLimitForDocker()
-		containerID, err = a.dockerClient.CreateContainerWithResources(ctx, task.Image, env, cpuLimit, memoryLimit)
-	} else {
-		containerID, err = a.dockerClient.CreateContainer(ctx, task.Image, env)
+	opts := runtime.ContainerOptions{
+		Image: task.Image,
+		Env:   env,
+		Ports: ports,
+		Labels: map[string]string{
+			runtime.LabelNodeID: a.nodeID,
+			runtime.LabelTaskID: task.TaskID,
+		},
 	}
+	a.applyResources(ctx, &opts, task.Resources)
 
+	containerID, err := a.runtime.CreateContainerWithOptions(ctx, opts)
 	if err != nil {
 		if updateErr := a.updateTaskStatus(task.TaskID, types.TaskFailed, "", err.Error()); updateErr != nil {
 			log.Printf("failed to update task status: %v", updateErr)
@@ -397,17 +477,25 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 		return fmt.Errorf("failed to create container: %w", err)
 	}
 
-	if err := a.dockerClient.StartContainer(ctx, containerID); err != nil {
+	if err := a.runtime.StartContainer(ctx, containerID); err != nil {
 		if updateErr := a.updateTaskStatus(task.TaskID, types.TaskFailed, containerID, err.Error()); updateErr != nil {
 			log.Printf("failed to update task status: %v", updateErr)
 		}
 		return fmt.Errorf("failed to start container: %w", err)
 	}
 
+	a.markImageUsed(task.Image)
+
 	if err := a.updateTaskStatus(task.TaskID, types.TaskRunning, containerID, ""); err != nil {
 		log.Printf("failed to update task with container ID: %v", err)
 	}
 
+	return a.superviseTask(ctx, task, containerID)
+}
+
+// superviseTask runs the liveness probe, waits for the task's container to exit,
+// reports the final status and removes the container
+func (a *Agent) superviseTask(ctx context.Context, task *types.Task, containerID string) error {
 	if task.LivenessProbe != nil {
 		restartPolicy := task.RestartPolicy
 		if restartPolicy == "" {
@@ -419,7 +507,7 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 			containerID,
 			task.LivenessProbe,
 			restartPolicy,
-			a.dockerClient,
+			a.runtime,
 			a.handleUnhealthyContainer,
 		)
 
@@ -438,7 +526,7 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 		log.Printf("started liveness probe for task %s", task.TaskID)
 	}
 
-	exitCode, err := a.dockerClient.WaitContainer(ctx, containerID)
+	exitCode, err := a.runtime.WaitContainer(ctx, containerID)
 	if err != nil {
 		if updateErr := a.updateTaskStatus(task.TaskID, types.TaskFailed, containerID, err.Error()); updateErr != nil {
 			log.Printf("failed to update task status: %v", updateErr)
@@ -471,7 +559,7 @@ func (a *Agent) ExecuteTask(ctx context.Context, task *types.Task) error {
 		}
 	}
 
-	if err := a.dockerClient.RemoveContainer(ctx, containerID); err != nil {
+	if err := a.runtime.RemoveContainer(ctx, containerID); err != nil {
 		log.Printf("failed to remove container %s: %v", containerID, err)
 	}
 
@@ -558,6 +646,15 @@ func (a *Agent) GetTask(taskID string) (*types.Task, bool) {
 
 // GetTaskLogs retrieves container logs for a task.
 func (a *Agent) GetTaskLogs(ctx context.Context, taskID string, tail int) (string, error) {
+	containerID, err := a.taskContainerID(taskID)
+	if err != nil {
+		return "", err
+	}
+	return a.runtime.GetContainerLogs(ctx, containerID, tail)
+}
+
+// taskContainerID returns the ID of the container a task runs in
+func (a *Agent) taskContainerID(taskID string) (string, error) {
 	// First check if task is in runningTasks (for tasks currently executing)
 	a.mu.RLock()
 	task, ok := a.runningTasks[taskID]
@@ -575,8 +672,7 @@ func (a *Agent) GetTaskLogs(ctx context.Context, taskID string, tail int) (strin
 	if task.ContainerID == "" {
 		return "", fmt.Errorf("task %s has no associated container", taskID)
 	}
-
-	return a.dockerClient.GetContainerLogs(ctx, task.ContainerID, tail)
+	return task.ContainerID, nil
 }
 
 // getTaskFromMaster fetches task details from the master
@@ -643,14 +739,14 @@ func (a *Agent) GetPodLogs(ctx context.Context, podID string, containerName stri
 		if !ok {
 			return nil, fmt.Errorf("container %s not found in pod %s", containerName, podID)
 		}
-		containerLogs, err := a.dockerClient.GetContainerLogs(ctx, containerID, tail)
+		containerLogs, err := a.runtime.GetContainerLogs(ctx, containerID, tail)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get logs for container %s: %w", containerName, err)
 		}
 		logs[containerName] = containerLogs
 	} else {
 		for name, containerID := range containerIDs {
-			containerLogs, err := a.dockerClient.GetContainerLogs(ctx, containerID, tail)
+			containerLogs, err := a.runtime.GetContainerLogs(ctx, containerID, tail)
 			if err != nil {
 				logs[name] = fmt.Sprintf("Error: %v", err)
 			} else {
@@ -688,21 +784,22 @@ func (a *Agent) CleanupPod(ctx context.Context, podID string) error {
 
 	for name, containerID := range containerIDs {
 		log.Printf("stopping container %s (id: %s)", name, containerID)
-		if err := a.dockerClient.StopContainer(ctx, containerID); err != nil {
+		if err := a.runtime.StopContainer(ctx, containerID); err != nil {
 			log.Printf("error stopping container %s: %v", name, err)
 		}
 
 		log.Printf("removing container %s (id: %s)", name, containerID)
-		if err := a.dockerClient.RemoveContainer(ctx, containerID); err != nil {
+		if err := a.runtime.RemoveContainer(ctx, containerID); err != nil {
 			log.Printf("error removing container %s: %v", name, err)
 		}
 	}
 
 	if networkID != "" {
 		log.Printf("removing pod network: %s", networkID)
-		if err := a.dockerClient.RemovePodNetwork(ctx, networkID); err != nil {
+		if err := a.runtime.RemovePodNetwork(ctx, networkID); err != nil {
 			log.Printf("error removing pod network %s: %v", networkID, err)
 		}
+		a.releasePodSubnet(podID)
 	}
 
 	a.untrackPodExecution(podID)
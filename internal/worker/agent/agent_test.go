@@ -26,8 +26,8 @@ func TestNewAgent(t *testing.T) {
 	if agent.masterURL != "http://localhost:8080" {
 		t.Errorf("expected masterURL http://localhost:8080, got %s", agent.masterURL)
 	}
-	if agent.dockerClient == nil {
-		t.Error("expected docker client to be initialized")
+	if agent.runtime == nil {
+		t.Error("expected container runtime to be initialized")
 	}
 	if agent.runningTasks == nil {
 		t.Error("expected runningTasks map to be initialized")
@@ -531,11 +531,11 @@ func TestCleanupRunningTasks(t *testing.T) {
 	ctx := context.Background()
 
 	// Pull and create a real container
-	if err := agent.dockerClient.PullImage(ctx, "alpine:latest"); err != nil {
+	if err := agent.runtime.PullImage(ctx, "alpine:latest"); err != nil {
 		t.Skipf("Docker not available: %v", err)
 	}
 
-	containerID, err := agent.dockerClient.CreateContainer(ctx, "alpine:latest", []string{})
+	containerID, err := agent.runtime.CreateContainer(ctx, "alpine:latest", []string{})
 	if err != nil {
 		t.Skipf("Cannot create container: %v", err)
 	}
@@ -556,7 +556,7 @@ func TestCleanupRunningTasks(t *testing.T) {
 	agent.cleanupRunningTasks(ctx)
 
 	// Verify container was removed
-	status, err := agent.dockerClient.GetContainerStatus(ctx, containerID)
+	status, err := agent.runtime.GetContainerStatus(ctx, containerID)
 	if err == nil {
 		t.Errorf("container should be removed, but got status: %s", status)
 	}
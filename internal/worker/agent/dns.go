@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// podDNSNdots makes names with fewer dots, such as web or web.prod, try the search domains first
+const podDNSNdots = "ndots:5"
+
+// clusterDNS is the resolver pod containers are pointed at
+type clusterDNS struct {
+	server string
+	domain string
+}
+
+// SetClusterDNS makes pod containers resolve names through the cluster DNS server, searching
+// <namespace>.svc.<domain>, svc.<domain> and <domain>. It must be called before Start.
+func (a *Agent) SetClusterDNS(server, domain string) {
+	a.clusterDNS = &clusterDNS{server: server, domain: strings.Trim(domain, ".")}
+}
+
+// podDNS returns the resolv.conf settings of the pod's containers, or nil for the runtime default
+func (a *Agent) podDNS(pod *types.Pod) *runtime.DNSConfig {
+	if a.clusterDNS == nil || a.clusterDNS.server == "" {
+		return nil
+	}
+
+	namespace := pod.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	domain := a.clusterDNS.domain
+	return &runtime.DNSConfig{
+		Servers:  []string{a.clusterDNS.server},
+		Searches: []string{namespace + ".svc." + domain, "svc." + domain, domain},
+		Options:  []string{podDNSNdots},
+	}
+}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestPodDNS(t *testing.T) {
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	pod := &types.Pod{PodID: "pod-1", Namespace: "prod"}
+	if got := agent.podDNS(pod); got != nil {
+		t.Errorf("podDNS() without cluster DNS = %+v, want nil", got)
+	}
+
+	agent.SetClusterDNS("10.0.0.1", "cluster.local.")
+	if err := fake.PullImage(context.Background(), "app:1.0"); err != nil {
+		t.Fatalf("PullImage() error = %v", err)
+	}
+	container := &types.Container{Name: "app", Image: "app:1.0"}
+	id, err := agent.createContainer(context.Background(), pod, container, nil, "")
+	if err != nil {
+		t.Fatalf("createContainer() error = %v", err)
+	}
+	opts, _ := fake.ContainerOptions(id)
+	if opts.DNS == nil {
+		t.Fatal("container was created without DNS settings")
+	}
+	if !reflect.DeepEqual(opts.DNS.Servers, []string{"10.0.0.1"}) {
+		t.Errorf("servers = %q", opts.DNS.Servers)
+	}
+	wantSearches := []string{"prod.svc.cluster.local", "svc.cluster.local", "cluster.local"}
+	if !reflect.DeepEqual(opts.DNS.Searches, wantSearches) {
+		t.Errorf("searches = %q, want %q", opts.DNS.Searches, wantSearches)
+	}
+	if !reflect.DeepEqual(opts.DNS.Options, []string{"ndots:5"}) {
+		t.Errorf("options = %q", opts.DNS.Options)
+	}
+
+	if got := agent.podDNS(&types.Pod{PodID: "pod-2"}); got.Searches[0] != "default.svc.cluster.local" {
+		t.Errorf("searches without a namespace = %q, want the default namespace first", got.Searches)
+	}
+}
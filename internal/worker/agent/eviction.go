@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/eviction"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+)
+
+// SetEvictionConfig replaces the node-pressure eviction settings.
+// It must be called before Start.
+func (a *Agent) SetEvictionConfig(config eviction.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	a.eviction = eviction.NewManager(config, &evictionProvider{agent: a})
+	return nil
+}
+
+// evictionLoop periodically checks node pressure and evicts pods when needed.
+func (a *Agent) evictionLoop() {
+	ticker := time.NewTicker(a.eviction.Config().Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), a.eviction.Config().Interval)
+			if _, err := a.eviction.Synchronize(ctx); err != nil {
+				log.Printf("eviction check failed: %v", err)
+			}
+			cancel()
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// pressureConditions returns the node's memory, disk and PID pressure conditions.
+func (a *Agent) pressureConditions() []types.NodeCondition {
+	if a.eviction == nil {
+		return nil
+	}
+	return a.eviction.Conditions()
+}
+
+// evictPod marks a running pod as evicted and stops its containers.
+// ExecutePod then reports the pod as failed with the Evicted reason.
+func (a *Agent) evictPod(ctx context.Context, podID, message string) error {
+	a.mu.RLock()
+	podExec, ok := a.runningPods[podID]
+	a.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("pod %s not found or not running on this worker", podID)
+	}
+
+	podExec.mu.Lock()
+	podExec.evictionMessage = message
+	containerIDs := make(map[string]string)
+	for name, id := range podExec.containerIDs {
+		containerIDs[name] = id
+	}
+	podExec.mu.Unlock()
+
+	event := types.PodEvent{
+		Type:    types.EventTypeWarning,
+		Reason:  types.ReasonEvicted,
+		Message: message,
+	}
+	if err := a.recordPodEvents(podID, []types.PodEvent{event}); err != nil {
+		log.Printf("failed to record eviction event for pod %s: %v", podID, err)
+	}
+
+	for name, containerID := range containerIDs {
+		log.Printf("stopping container %s (id: %s) of evicted pod %s", name, containerID, podID)
+		if err := a.runtime.StopContainer(ctx, containerID); err != nil {
+			log.Printf("error stopping container %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// evictionProvider adapts the agent to the eviction manager
+type evictionProvider struct {
+	agent *Agent
+}
+
+// Signals reads available memory and process IDs from the kernel and free disk from the runtime root directory
+func (p *evictionProvider) Signals(ctx context.Context) (eviction.Signals, error) {
+	var signals eviction.Signals
+
+	available, total, err := eviction.ReadMemInfo()
+	if err != nil {
+		return signals, err
+	}
+	signals.MemoryAvailable = available
+	signals.MemoryCapacity = total
+
+	if used, limit, err := eviction.ReadPIDs(); err != nil {
+		log.Printf("failed to read process count: %v", err)
+	} else {
+		signals.PIDsAvailable = limit - used
+		signals.PIDCapacity = limit
+	}
+
+	rootDir, err := p.agent.runtime.RootDir(ctx)
+	if err != nil {
+		log.Printf("failed to resolve docker root directory: %v", err)
+		return signals, nil
+	}
+
+	disk, err := imagegc.DiskUsage(rootDir)
+	if err != nil {
+		log.Printf("failed to get disk usage for %s: %v", rootDir, err)
+		return signals, nil
+	}
+	signals.DiskAvailable = int64(disk.AvailableBytes)
+	signals.DiskCapacity = int64(disk.CapacityBytes)
+
+	return signals, nil
+}
+
+// ActivePods returns the running pods with their current memory and writable layer usage
+func (p *evictionProvider) ActivePods(ctx context.Context) []eviction.PodCandidate {
+	p.agent.mu.RLock()
+	executions := make([]*PodExecution, 0, len(p.agent.runningPods))
+	for _, podExec := range p.agent.runningPods {
+		executions = append(executions, podExec)
+	}
+	p.agent.mu.RUnlock()
+
+	candidates := make([]eviction.PodCandidate, 0, len(executions))
+	for _, podExec := range executions {
+		podExec.mu.RLock()
+		if podExec.evictionMessage != "" {
+			podExec.mu.RUnlock()
+			continue
+		}
+		containerIDs := make([]string, 0, len(podExec.containerIDs))
+		for _, id := range podExec.containerIDs {
+			containerIDs = append(containerIDs, id)
+		}
+		podExec.mu.RUnlock()
+
+		pod := podExec.pod
+		candidate := eviction.PodCandidate{
+			PodID:         pod.PodID,
+			QOSClass:      pod.QOSClass,
+			MemoryRequest: pod.GetTotalResourceRequests().Requests.Memory,
+		}
+		if candidate.QOSClass == "" {
+			candidate.QOSClass = pod.GetQOSClass()
+		}
+		if pod.StartedAt != nil {
+			candidate.StartedAt = *pod.StartedAt
+		}
+
+		for _, containerID := range containerIDs {
+			if usage, err := p.agent.runtime.ContainerMemoryUsage(ctx, containerID); err == nil {
+				candidate.MemoryUsage += usage
+			}
+			if usage, err := p.agent.runtime.ContainerDiskUsage(ctx, containerID); err == nil {
+				candidate.DiskUsage += usage
+			}
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// ReclaimDisk removes unused images to free disk space
+func (p *evictionProvider) ReclaimDisk(ctx context.Context, bytesToFree int64) (int64, error) {
+	if p.agent.imageGC == nil {
+		return 0, nil
+	}
+	return p.agent.imageGC.ReclaimSpace(ctx, bytesToFree)
+}
+
+// EvictPod evicts the pod from the node
+func (p *evictionProvider) EvictPod(ctx context.Context, podID, message string) error {
+	return p.agent.evictPod(ctx, podID, message)
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/labstack/echo/v4"
+)
+
+// ExecPod handles POST /api/v1/pods/:id/exec
+// Upgrades the connection to a stream and runs a command in one of the pod's containers.
+// Query parameters: command (repeated, required), container (defaults to the first container),
+// stdin and tty ("true" to attach stdin or allocate a terminal).
+func (s *Server) ExecPod(c echo.Context) error {
+	query := c.QueryParams()
+	command := query["command"]
+	if len(command) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "command is required"})
+	}
+
+	containerID, err := s.agent.podContainerID(c.Param("id"), query.Get("container"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	if !stream.IsUpgrade(c.Request()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "exec requires a stream upgrade"})
+	}
+	conn, err := stream.Upgrade(c.Response(), c.Request())
+	if err != nil {
+		log.Printf("exec in pod %s: %v", c.Param("id"), err)
+		return nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	s.agent.serveExec(conn, containerID, command, query.Get("tty") == "true", query.Get("stdin") == "true")
+	return nil
+}
+
+// podContainerID returns the ID of a container of a running pod.
+// An empty name selects the pod's first container.
+func (a *Agent) podContainerID(podID, containerName string) (string, error) {
+	a.mu.RLock()
+	podExec, ok := a.runningPods[podID]
+	a.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("pod %s not found or not running", podID)
+	}
+
+	podExec.mu.RLock()
+	defer podExec.mu.RUnlock()
+
+	if containerName == "" && len(podExec.pod.Containers) > 0 {
+		containerName = podExec.pod.Containers[0].Name
+	}
+	containerID, ok := podExec.containerIDs[containerName]
+	if !ok {
+		return "", fmt.Errorf("container %s not found in pod %s", containerName, podID)
+	}
+	return containerID, nil
+}
+
+// serveExec runs a command with its streams multiplexed over conn and reports its exit status.
+// The command is abandoned if the client disconnects.
+func (a *Agent) serveExec(conn io.ReadWriter, containerID string, command []string, tty, attachStdin bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	frames := stream.NewFrameWriter(conn)
+	stdinReader, stdinWriter := io.Pipe()
+	resize := make(chan runtime.TerminalSize, 1)
+
+	go func() {
+		defer cancel()
+		defer func() { _ = stdinWriter.Close() }()
+
+		for {
+			channel, payload, err := stream.ReadFrame(conn)
+			if err != nil {
+				return
+			}
+
+			switch channel {
+			case stream.ChannelStdin:
+				if len(payload) == 0 {
+					_ = stdinWriter.Close()
+					continue
+				}
+				_, _ = stdinWriter.Write(payload)
+			case stream.ChannelResize:
+				var size stream.TerminalSize
+				if err := json.Unmarshal(payload, &size); err != nil {
+					continue
+				}
+				select {
+				case resize <- runtime.TerminalSize{Width: size.Width, Height: size.Height}:
+				default:
+				}
+			}
+		}
+	}()
+
+	opts := runtime.ExecOptions{
+		Cmd:    command,
+		TTY:    tty,
+		Stdout: frames.Channel(stream.ChannelStdout),
+		Stderr: frames.Channel(stream.ChannelStderr),
+	}
+	if attachStdin {
+		opts.Stdin = stdinReader
+	}
+	if tty {
+		opts.Resize = resize
+	}
+
+	exitCode, err := a.runtime.ExecStream(ctx, containerID, opts)
+	_ = stdinReader.Close()
+
+	status := stream.ExecStatus{ExitCode: exitCode}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	payload, _ := json.Marshal(status)
+	_ = frames.WriteFrame(stream.ChannelStatus, payload)
+}
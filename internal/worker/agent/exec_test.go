@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/labstack/echo/v4"
+)
+
+// newExecServer serves the worker API of an agent running pod-1 with a single "app" container
+func newExecServer(t *testing.T) (*httptest.Server, *runtime.Fake) {
+	t.Helper()
+
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	containerID := startLabeledContainer(t, fake, map[string]string{runtime.LabelPodID: "pod-1"})
+	agent.trackPodExecution(
+		"pod-1", &PodExecution{
+			pod:          &types.Pod{PodID: "pod-1", Containers: []types.Container{{Name: "app"}}},
+			containerIDs: map[string]string{"app": containerID},
+		},
+	)
+
+	e := echo.New()
+	NewServer("test-node", "localhost", 0, agent).RegisterRoutes(e)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return server, fake
+}
+
+// readExecOutput collects stdout until the exec status arrives
+func readExecOutput(t *testing.T, conn io.Reader) (string, stream.ExecStatus) {
+	t.Helper()
+
+	var stdout strings.Builder
+	for {
+		channel, payload, err := stream.ReadFrame(conn)
+		if err != nil {
+			t.Fatalf("ReadFrame() error = %v", err)
+		}
+		switch channel {
+		case stream.ChannelStdout:
+			stdout.Write(payload)
+		case stream.ChannelStatus:
+			var status stream.ExecStatus
+			if err := json.Unmarshal(payload, &status); err != nil {
+				t.Fatal(err)
+			}
+			return stdout.String(), status
+		}
+	}
+}
+
+func TestExecPod(t *testing.T) {
+	server, fake := newExecServer(t)
+	ctx := context.Background()
+
+	query := url.Values{"command": {"cat"}, "stdin": {"true"}}
+	conn, err := stream.Dial(ctx, http.MethodPost, server.URL+"/api/v1/pods/pod-1/exec?"+query.Encode())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	frames := stream.NewFrameWriter(conn)
+	if err := frames.WriteFrame(stream.ChannelStdin, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := frames.WriteFrame(stream.ChannelStdin, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, status := readExecOutput(t, conn)
+	if stdout != "hello" || status.ExitCode != 0 || status.Error != "" {
+		t.Errorf("exec = %q, %+v, want the echoed input and exit code 0", stdout, status)
+	}
+
+	// The exit code of the command is reported
+	fake.SetExecStreamFunc(
+		func(_ string, opts runtime.ExecOptions) (int, error) {
+			_, _ = io.WriteString(opts.Stdout, strings.Join(opts.Cmd, " "))
+			return 3, nil
+		},
+	)
+	query = url.Values{"command": {"sh", "-c", "exit 3"}, "container": {"app"}}
+	conn, err = stream.Dial(ctx, http.MethodPost, server.URL+"/api/v1/pods/pod-1/exec?"+query.Encode())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	stdout, status = readExecOutput(t, conn)
+	if stdout != "sh -c exit 3" || status.ExitCode != 3 {
+		t.Errorf("exec = %q, %+v, want exit code 3", stdout, status)
+	}
+}
+
+func TestExecPodErrors(t *testing.T) {
+	server, _ := newExecServer(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "missing command", path: "/api/v1/pods/pod-1/exec", wantStatus: http.StatusBadRequest},
+		{name: "unknown pod", path: "/api/v1/pods/pod-2/exec?command=sh", wantStatus: http.StatusNotFound},
+		{
+			name:       "unknown container",
+			path:       "/api/v1/pods/pod-1/exec?command=sh&container=sidecar",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := stream.Dial(context.Background(), http.MethodPost, server.URL+tt.path)
+				var statusErr *stream.StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Errorf("Dial() error = %v, want status %d", err, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/labstack/echo/v4"
+)
+
+// tarContentType is the media type of the archives served and accepted by the file endpoints
+const tarContentType = "application/x-tar"
+
+// DownloadPodFiles handles GET /api/v1/pods/:id/files
+// Streams a tar archive of a file or directory in one of the pod's containers.
+// Query parameters: path (required) and container (defaults to the first container).
+func (s *Server) DownloadPodFiles(c echo.Context) error {
+	srcPath := c.QueryParam("path")
+	if srcPath == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "path is required"})
+	}
+
+	containerID, err := s.agent.podContainerID(c.Param("id"), c.QueryParam("container"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	// Headers are sent with the first bytes of the archive, so a missing path can still be reported
+	w := &lazyHeaderWriter{response: c.Response()}
+	err = s.agent.runtime.CopyFromContainer(c.Request().Context(), containerID, srcPath, w)
+	switch {
+	case err == nil && !w.started:
+		return c.Blob(http.StatusOK, tarContentType, nil)
+	case err == nil:
+		return nil
+	case w.started:
+		log.Printf("copy of %s from pod %s ended: %v", srcPath, c.Param("id"), err)
+		return nil
+	case errors.Is(err, runtime.ErrPathNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// UploadPodFiles handles PUT /api/v1/pods/:id/files
+// Extracts the tar archive in the request body into a directory of one of the pod's containers.
+// Query parameters: path (the target directory, required) and container (defaults to the first container).
+func (s *Server) UploadPodFiles(c echo.Context) error {
+	dstDir := c.QueryParam("path")
+	if dstDir == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "path is required"})
+	}
+
+	containerID, err := s.agent.podContainerID(c.Param("id"), c.QueryParam("container"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	err = s.agent.runtime.CopyToContainer(c.Request().Context(), containerID, dstDir, c.Request().Body)
+	if errors.Is(err, runtime.ErrPathNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// lazyHeaderWriter writes the archive response headers before the first write
+type lazyHeaderWriter struct {
+	response *echo.Response
+	started  bool
+}
+
+func (w *lazyHeaderWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.response.Header().Set(echo.HeaderContentType, tarContentType)
+		w.response.WriteHeader(http.StatusOK)
+	}
+	return w.response.Write(p)
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestPodFiles(t *testing.T) {
+	server, fake := newExecServer(t)
+	containerID := fake.Containers()[0]
+	if err := fake.WriteFile(containerID, "/tmp/core.1", []byte("dump")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{name: "file", query: "/pods/pod-1/files?path=/tmp/core.1", wantCode: http.StatusOK},
+		{name: "missing path parameter", query: "/pods/pod-1/files", wantCode: http.StatusBadRequest},
+		{name: "missing file", query: "/pods/pod-1/files?path=/nope", wantCode: http.StatusNotFound},
+		{name: "unknown container", query: "/pods/pod-1/files?path=/tmp&container=db", wantCode: http.StatusNotFound},
+		{name: "unknown pod", query: "/pods/pod-2/files?path=/tmp", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				resp, err := http.Get(server.URL + "/api/v1" + tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer func() { _ = resp.Body.Close() }()
+				if resp.StatusCode != tt.wantCode {
+					t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+				}
+				if tt.wantCode != http.StatusOK {
+					return
+				}
+
+				tr := tar.NewReader(resp.Body)
+				header, err := tr.Next()
+				if err != nil || header.Name != "core.1" {
+					t.Fatalf("first entry = %v, %v, want core.1", header, err)
+				}
+				if data, _ := io.ReadAll(tr); string(data) != "dump" {
+					t.Errorf("core.1 = %q, want dump", data)
+				}
+			},
+		)
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	_ = tw.WriteHeader(&tar.Header{Name: "app.yaml", Mode: 0o644, Size: 6, Typeflag: tar.TypeReg})
+	_, _ = tw.Write([]byte("debug\n"))
+	_ = tw.Close()
+
+	upload := func(dir string) int {
+		req, err := http.NewRequest(
+			http.MethodPut, server.URL+"/api/v1/pods/pod-1/files?path="+dir, bytes.NewReader(archive.Bytes()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := upload("/etc/app"); code != http.StatusNotFound {
+		t.Errorf("upload into a missing directory = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := upload("/tmp"); code != http.StatusNoContent {
+		t.Fatalf("upload = %d, want %d", code, http.StatusNoContent)
+	}
+	if data, err := fake.ReadFile(containerID, "/tmp/app.yaml"); err != nil || string(data) != "debug\n" {
+		t.Errorf("uploaded file = %q, %v", data, err)
+	}
+}
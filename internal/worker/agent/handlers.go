@@ -16,7 +16,7 @@ type ExecuteTaskRequest struct {
 }
 
 // ExecuteTask handles POST /api/v1/tasks/:id/execute
-// Executes a task in a Docker container.
+// Executes a task in a container.
 func (s *Server) ExecuteTask(c echo.Context) error {
 	taskID := c.Param("id")
 
@@ -184,7 +184,8 @@ func validateHealthCheck(check *types.HealthCheck) error {
 
 // ExecutePodRequest represents a pod execution request.
 type ExecutePodRequest struct {
-	Pod types.Pod `json:"pod"`
+	Pod                 types.Pod                   `json:"pod"`
+	RegistryCredentials []types.RegistryCredentials `json:"registryCredentials,omitempty"`
 }
 
 // ExecutePod handles POST /api/v1/pods/:id/execute
@@ -205,7 +206,7 @@ func (s *Server) ExecutePod(c echo.Context) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 		defer cancel()
 
-		if err := s.agent.ExecutePod(ctx, &req.Pod); err != nil {
+		if err := s.agent.ExecutePod(ctx, &req.Pod, req.RegistryCredentials); err != nil {
 			c.Logger().Errorf("pod execution failed: %v", err)
 		}
 	}()
@@ -231,6 +232,29 @@ func (s *Server) GetPodStatus(c echo.Context) error {
 	return c.JSON(http.StatusOK, pod)
 }
 
+// GetPodStats handles GET /api/v1/pods/:id/stats
+// Returns the measured resource usage of a pod's containers.
+func (s *Server) GetPodStats(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	podStats, err := s.agent.PodStats(ctx, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, podStats)
+}
+
+// GetNodeStats handles GET /api/v1/stats
+// Returns the node's resource usage and the usage of each running pod.
+func (s *Server) GetNodeStats(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	return c.JSON(http.StatusOK, s.agent.NodeStats(ctx))
+}
+
 // GetPodLogs handles GET /api/v1/pods/:id/logs
 // Returns logs from containers in a pod.
 func (s *Server) GetPodLogs(c echo.Context) error {
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// nodeIDFile is the file in the worker state directory that holds the node identity
+const nodeIDFile = "node-id"
+
+// LoadNodeID returns the node identity persisted in stateDir so the worker registers as the same
+// node across restarts. A requested ID takes precedence and replaces the stored one; without
+// either, a new ID is generated on first start.
+func LoadNodeID(stateDir, requested string) (string, error) {
+	if err := os.MkdirAll(stateDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	path := filepath.Join(stateDir, nodeIDFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read node ID: %w", err)
+	}
+	stored := strings.TrimSpace(string(data))
+
+	nodeID := requested
+	switch {
+	case requested == "" && stored != "":
+		return stored, nil
+	case requested == "":
+		if nodeID, err = generateNodeID(); err != nil {
+			return "", err
+		}
+	case requested == stored:
+		return stored, nil
+	case stored != "":
+		log.Printf("node ID %s overrides the stored node ID %s", requested, stored)
+	}
+
+	if err := os.WriteFile(path, []byte(nodeID+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to persist node ID: %w", err)
+	}
+	return nodeID, nil
+}
+
+// generateNodeID derives a new node ID from the hostname and a random suffix
+func generateNodeID() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate node ID: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "worker"
+	}
+	hostname = strings.ToLower(strings.SplitN(hostname, ".", 2)[0])
+
+	return hostname + "-" + hex.EncodeToString(suffix), nil
+}
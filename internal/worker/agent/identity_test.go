@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+func TestLoadNodeID(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    string
+		requested string
+		want      string
+	}{
+		{name: "requested ID is persisted", requested: "worker-1", want: "worker-1"},
+		{name: "stored ID is reused", stored: "worker-1", want: "worker-1"},
+		{name: "requested ID overrides stored ID", stored: "worker-1", requested: "worker-2", want: "worker-2"},
+		{name: "same ID", stored: "worker-1", requested: "worker-1", want: "worker-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				dir := t.TempDir()
+				if tt.stored != "" {
+					if err := os.WriteFile(filepath.Join(dir, nodeIDFile), []byte(tt.stored+"\n"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				got, err := LoadNodeID(dir, tt.requested)
+				if err != nil {
+					t.Fatalf("LoadNodeID() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("LoadNodeID() = %s, want %s", got, tt.want)
+				}
+
+				again, err := LoadNodeID(dir, "")
+				if err != nil || again != tt.want {
+					t.Errorf("LoadNodeID() after restart = %s, %v, want %s", again, err, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestLoadNodeIDGeneratesStableID(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+
+	first, err := LoadNodeID(dir, "")
+	if err != nil {
+		t.Fatalf("LoadNodeID() error = %v", err)
+	}
+	if first == "" {
+		t.Fatal("expected a generated node ID")
+	}
+
+	second, err := LoadNodeID(dir, "")
+	if err != nil {
+		t.Fatalf("LoadNodeID() error = %v", err)
+	}
+	if second != first {
+		t.Errorf("node ID changed across restarts: %s then %s", first, second)
+	}
+}
+
+func TestRegisterSendsNodeID(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "new node", status: http.StatusCreated},
+		{name: "re-registered node", status: http.StatusOK},
+		{name: "rejected", status: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var gotNodeID string
+				server := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							if !strings.HasSuffix(r.URL.Path, "/nodes/register") {
+								w.WriteHeader(http.StatusNotFound)
+								return
+							}
+							var body map[string]interface{}
+							_ = json.NewDecoder(r.Body).Decode(&body)
+							gotNodeID, _ = body["nodeId"].(string)
+
+							w.WriteHeader(tt.status)
+							_ = json.NewEncoder(w).Encode(map[string]string{"nodeId": gotNodeID})
+						},
+					),
+				)
+				defer server.Close()
+
+				agent := NewAgentWithRuntime("worker-1", server.URL, runtime.NewFake())
+				defer agent.Stop()
+
+				err := agent.Register("localhost", 8081)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Register() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if gotNodeID != "worker-1" {
+					t.Errorf("registered node ID = %q, want worker-1", gotNodeID)
+				}
+				if agent.NodeID() != "worker-1" {
+					t.Errorf("NodeID() = %s, want worker-1", agent.NodeID())
+				}
+			},
+		)
+	}
+}
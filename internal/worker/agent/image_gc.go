@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+)
+
+// SetImageGCConfig replaces the image garbage collection settings.
+// It must be called before Start.
+func (a *Agent) SetImageGCConfig(config imagegc.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	a.imageGC = imagegc.NewManager(a.runtime, config, a.imagesInUse)
+	return nil
+}
+
+// imageGCLoop periodically removes unused images when disk usage is high.
+func (a *Agent) imageGCLoop() {
+	ticker := time.NewTicker(a.imageGC.Config().Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), a.imageGC.Config().Interval)
+			freed, err := a.imageGC.GarbageCollect(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("image garbage collection failed: %v", err)
+			}
+			if freed > 0 {
+				log.Printf("image garbage collection freed %d bytes", freed)
+			}
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// imagesInUse returns the images of all tasks and pods running on this node.
+func (a *Agent) imagesInUse() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	images := make([]string, 0, len(a.runningTasks)+len(a.runningPods))
+	for _, task := range a.runningTasks {
+		images = append(images, task.Image)
+	}
+	for _, podExec := range a.runningPods {
+		for _, container := range podExec.pod.Containers {
+			images = append(images, container.Image)
+		}
+	}
+	return images
+}
+
+// markImageUsed records that a container was started from the image.
+func (a *Agent) markImageUsed(image string) {
+	if a.imageGC != nil {
+		a.imageGC.MarkUsed(image)
+	}
+}
+
+// imageInventory returns the node's images for reporting to the master.
+// A nil slice is returned when the inventory cannot be collected.
+func (a *Agent) imageInventory() []types.ContainerImage {
+	if a.imageGC == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	images, err := a.imageGC.Inventory(ctx)
+	if err != nil {
+		log.Printf("failed to collect image inventory: %v", err)
+		return nil
+	}
+	return images
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// imagePuller is the subset of the container runtime used to fetch images
+type imagePuller interface {
+	ImageExists(ctx context.Context, imageName string) (bool, error)
+	PullImageWithAuth(ctx context.Context, imageName string, auth *runtime.RegistryAuth) error
+}
+
+// pullBackoff controls how failed image pulls are retried
+type pullBackoff struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+}
+
+var defaultPullBackoff = pullBackoff{
+	attempts: 4,
+	initial:  2 * time.Second,
+	max:      30 * time.Second,
+}
+
+// ImagePullError is returned when a container image could not be made available
+type ImagePullError struct {
+	Reason string
+	Image  string
+	Err    error
+}
+
+func (e *ImagePullError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Reason, e.Image, e.Err)
+}
+
+func (e *ImagePullError) Unwrap() error {
+	return e.Err
+}
+
+// ensureImage makes the container image available according to its pull policy.
+// Progress is reported through onEvent; failed pulls are retried with exponential backoff.
+func ensureImage(
+	ctx context.Context,
+	puller imagePuller,
+	container *types.Container,
+	creds []types.RegistryCredentials,
+	backoff pullBackoff,
+	onEvent func(types.PodEvent),
+) error {
+	policy := container.GetImagePullPolicy()
+
+	if policy != types.PullAlways {
+		exists, err := puller.ImageExists(ctx, container.Image)
+		if err != nil {
+			return &ImagePullError{Reason: types.ReasonErrImagePull, Image: container.Image, Err: err}
+		}
+		if exists {
+			onEvent(
+				imageEvent(
+					types.EventTypeNormal, "Pulled", container,
+					fmt.Sprintf("Container image %q already present on machine", container.Image),
+				),
+			)
+			return nil
+		}
+		if policy == types.PullNever {
+			err := fmt.Errorf("image not present with pull policy of Never")
+			onEvent(imageEvent(types.EventTypeWarning, types.ReasonErrImageNeverPull, container, err.Error()))
+			return &ImagePullError{Reason: types.ReasonErrImageNeverPull, Image: container.Image, Err: err}
+		}
+	}
+
+	auth := credentialsForImage(container.Image, creds)
+	delay := backoff.initial
+	attempts := max(backoff.attempts, 1)
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		onEvent(imageEvent(types.EventTypeNormal, "Pulling", container, fmt.Sprintf("Pulling image %q", container.Image)))
+
+		start := time.Now()
+		lastErr = puller.PullImageWithAuth(ctx, container.Image, auth)
+		if lastErr == nil {
+			onEvent(
+				imageEvent(
+					types.EventTypeNormal, "Pulled", container,
+					fmt.Sprintf(
+						"Successfully pulled image %q in %s", container.Image, time.Since(start).Round(time.Millisecond),
+					),
+				),
+			)
+			return nil
+		}
+
+		onEvent(
+			imageEvent(
+				types.EventTypeWarning, types.ReasonErrImagePull, container,
+				fmt.Sprintf("Failed to pull image %q: %v", container.Image, lastErr),
+			),
+		)
+
+		if attempt == attempts {
+			break
+		}
+
+		onEvent(
+			imageEvent(
+				types.EventTypeWarning, types.ReasonImagePullBackOff, container,
+				fmt.Sprintf("Back-off pulling image %q, retrying in %s", container.Image, delay),
+			),
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return &ImagePullError{Reason: types.ReasonErrImagePull, Image: container.Image, Err: ctx.Err()}
+		}
+
+		delay *= 2
+		if delay > backoff.max {
+			delay = backoff.max
+		}
+	}
+
+	reason := types.ReasonErrImagePull
+	if attempts > 1 {
+		reason = types.ReasonImagePullBackOff
+	}
+	return &ImagePullError{Reason: reason, Image: container.Image, Err: lastErr}
+}
+
+// imageEvent builds a pod event for an image operation on a container
+func imageEvent(eventType, reason string, container *types.Container, message string) types.PodEvent {
+	return types.PodEvent{
+		Type:      eventType,
+		Reason:    reason,
+		Message:   message,
+		Container: container.Name,
+		Timestamp: time.Now(),
+	}
+}
+
+// credentialsForImage selects the credentials whose server matches the image registry
+func credentialsForImage(imageName string, creds []types.RegistryCredentials) *runtime.RegistryAuth {
+	registry := imageRegistry(imageName)
+	for _, cred := range creds {
+		if normalizeRegistry(cred.Server) == registry {
+			return &runtime.RegistryAuth{
+				ServerAddress: cred.Server,
+				Username:      cred.Username,
+				Password:      cred.Password,
+			}
+		}
+	}
+	return nil
+}
+
+// imageRegistry returns the registry host of an image reference
+// Images without an explicit registry resolve to Docker Hub
+func imageRegistry(imageName string) string {
+	first, _, found := strings.Cut(imageName, "/")
+	if !found {
+		return "docker.io"
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return normalizeRegistry(first)
+	}
+	return "docker.io"
+}
+
+// normalizeRegistry strips scheme and path from a registry address and
+// maps the various Docker Hub aliases to docker.io
+func normalizeRegistry(server string) string {
+	server = strings.TrimPrefix(server, "https://")
+	server = strings.TrimPrefix(server, "http://")
+	server, _, _ = strings.Cut(server, "/")
+	server = strings.ToLower(server)
+
+	switch server {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+	return server
+}
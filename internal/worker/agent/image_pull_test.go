@@ -0,0 +1,225 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/docker"
+)
+
+type mockImagePuller struct {
+	present   bool
+	pullErrs  []error
+	pulls     int
+	lastAuth  *docker.RegistryAuth
+	existsErr error
+}
+
+func (m *mockImagePuller) ImageExists(_ context.Context, _ string) (bool, error) {
+	return m.present, m.existsErr
+}
+
+func (m *mockImagePuller) PullImageWithAuth(_ context.Context, _ string, auth *docker.RegistryAuth) error {
+	m.lastAuth = auth
+	m.pulls++
+	if len(m.pullErrs) > 0 {
+		err := m.pullErrs[0]
+		m.pullErrs = m.pullErrs[1:]
+		return err
+	}
+	return nil
+}
+
+var testPullBackoff = pullBackoff{attempts: 3, initial: time.Millisecond, max: 2 * time.Millisecond}
+
+func TestEnsureImage(t *testing.T) {
+	pullErr := errors.New("unauthorized")
+
+	tests := []struct {
+		name       string
+		container  types.Container
+		puller     *mockImagePuller
+		wantPulls  int
+		wantReason string
+		wantEvent  string
+	}{
+		{
+			name:      "IfNotPresent skips pull when image exists",
+			container: types.Container{Name: "app", Image: "nginx:1.25"},
+			puller:    &mockImagePuller{present: true},
+			wantPulls: 0,
+			wantEvent: "Pulled",
+		},
+		{
+			name:      "IfNotPresent pulls when image missing",
+			container: types.Container{Name: "app", Image: "nginx:1.25"},
+			puller:    &mockImagePuller{},
+			wantPulls: 1,
+			wantEvent: "Pulled",
+		},
+		{
+			name:      "Always pulls even when image exists",
+			container: types.Container{Name: "app", Image: "nginx:latest"},
+			puller:    &mockImagePuller{present: true},
+			wantPulls: 1,
+			wantEvent: "Pulled",
+		},
+		{
+			name:       "Never fails when image missing",
+			container:  types.Container{Name: "app", Image: "nginx:1.25", ImagePullPolicy: types.PullNever},
+			puller:     &mockImagePuller{},
+			wantPulls:  0,
+			wantReason: types.ReasonErrImageNeverPull,
+			wantEvent:  types.ReasonErrImageNeverPull,
+		},
+		{
+			name:      "retries and succeeds",
+			container: types.Container{Name: "app", Image: "nginx"},
+			puller:    &mockImagePuller{pullErrs: []error{pullErr}},
+			wantPulls: 2,
+			wantEvent: "Pulled",
+		},
+		{
+			name:       "gives up with back-off",
+			container:  types.Container{Name: "app", Image: "nginx"},
+			puller:     &mockImagePuller{pullErrs: []error{pullErr, pullErr, pullErr}},
+			wantPulls:  3,
+			wantReason: types.ReasonImagePullBackOff,
+			wantEvent:  types.ReasonErrImagePull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var events []types.PodEvent
+				onEvent := func(e types.PodEvent) { events = append(events, e) }
+
+				err := ensureImage(context.Background(), tt.puller, &tt.container, nil, testPullBackoff, onEvent)
+
+				if tt.puller.pulls != tt.wantPulls {
+					t.Errorf("expected %d pulls, got %d", tt.wantPulls, tt.puller.pulls)
+				}
+
+				if tt.wantReason == "" {
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				} else {
+					var pullErr *ImagePullError
+					if !errors.As(err, &pullErr) {
+						t.Fatalf("expected ImagePullError, got %v", err)
+					}
+					if pullErr.Reason != tt.wantReason {
+						t.Errorf("expected reason %s, got %s", tt.wantReason, pullErr.Reason)
+					}
+				}
+
+				if len(events) == 0 {
+					t.Fatal("expected events to be reported")
+				}
+				if last := events[len(events)-1]; last.Reason != tt.wantEvent {
+					t.Errorf("expected last event %s, got %s", tt.wantEvent, last.Reason)
+				}
+				for _, e := range events {
+					if e.Container != tt.container.Name {
+						t.Errorf("expected event for container %s, got %s", tt.container.Name, e.Container)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestEnsureImage_ContextCancelled(t *testing.T) {
+	puller := &mockImagePuller{pullErrs: []error{errors.New("timeout")}}
+	container := types.Container{Name: "app", Image: "nginx"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	backoff := pullBackoff{attempts: 3, initial: time.Hour, max: time.Hour}
+	err := ensureImage(ctx, puller, &container, nil, backoff, func(types.PodEvent) {})
+	if err == nil {
+		t.Fatal("expected error when context is cancelled")
+	}
+	if puller.pulls != 1 {
+		t.Errorf("expected a single pull attempt, got %d", puller.pulls)
+	}
+}
+
+func TestCredentialsForImage(t *testing.T) {
+	creds := []types.RegistryCredentials{
+		{Server: "https://index.docker.io/v1/", Username: "hub-user", Password: "p1"},
+		{Server: "registry.example.com", Username: "corp-user", Password: "p2"},
+		{Server: "localhost:5000", Username: "local-user", Password: "p3"},
+	}
+
+	tests := []struct {
+		image    string
+		wantUser string
+	}{
+		{"nginx", "hub-user"},
+		{"library/nginx:1.25", "hub-user"},
+		{"docker.io/team/app", "hub-user"},
+		{"registry.example.com/team/app:1.0", "corp-user"},
+		{"localhost:5000/app", "local-user"},
+		{"ghcr.io/org/app", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.image, func(t *testing.T) {
+				auth := credentialsForImage(tt.image, creds)
+				got := ""
+				if auth != nil {
+					got = auth.Username
+				}
+				if got != tt.wantUser {
+					t.Errorf("credentialsForImage(%q) user = %q, want %q", tt.image, got, tt.wantUser)
+				}
+			},
+		)
+	}
+}
+
+func TestAgent_RecordPodEvents(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("Expected POST method, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/v1/pods/pod-1/events" {
+					t.Errorf("Unexpected path %s", r.URL.Path)
+				}
+
+				var payload struct {
+					Events []types.PodEvent `json:"events"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+				if len(payload.Events) != 1 || payload.Events[0].Reason != types.ReasonErrImagePull {
+					t.Errorf("Unexpected events: %+v", payload.Events)
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+	defer server.Close()
+
+	agent := &Agent{masterURL: server.URL}
+	events := []types.PodEvent{{Type: types.EventTypeWarning, Reason: types.ReasonErrImagePull}}
+
+	if err := agent.recordPodEvents("pod-1", events); err != nil {
+		t.Fatalf("recordPodEvents failed: %v", err)
+	}
+}
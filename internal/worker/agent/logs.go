@@ -0,0 +1,306 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/labstack/echo/v4"
+)
+
+// LogStreamOptions selects the log lines passed on by streamLogs
+type LogStreamOptions struct {
+	// Container selects a pod container by name; empty streams all of the pod's containers
+	Container string
+	Follow    bool
+	// Since skips lines written before this time
+	Since time.Time
+	// Tail limits each container's output to its last lines; zero or less writes all lines
+	Tail       int
+	Timestamps bool
+	// Stream limits the output to types.LogStreamStdout or types.LogStreamStderr; empty writes both
+	Stream string
+}
+
+// podLogContainers returns the IDs, keyed by container name, of the pod containers whose logs
+// the options select
+func (a *Agent) podLogContainers(podID string, opts LogStreamOptions) (map[string]string, error) {
+	a.mu.RLock()
+	podExec, ok := a.runningPods[podID]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("pod %s not found or not running", podID)
+	}
+
+	containerIDs := make(map[string]string)
+	podExec.mu.RLock()
+	for i := range podExec.pod.Containers {
+		name := podExec.pod.Containers[i].Name
+		if opts.Container != "" && name != opts.Container {
+			continue
+		}
+		if id := podExec.containerIDs[name]; id != "" {
+			containerIDs[name] = id
+		}
+	}
+	podExec.mu.RUnlock()
+
+	if len(containerIDs) == 0 {
+		if opts.Container != "" {
+			return nil, fmt.Errorf("container %s not found in pod %s", opts.Container, podID)
+		}
+		return nil, fmt.Errorf("pod %s has no containers", podID)
+	}
+	return containerIDs, nil
+}
+
+// taskLogContainers returns the task's container keyed by an empty name
+func (a *Agent) taskLogContainers(taskID string) (map[string]string, error) {
+	containerID, err := a.taskContainerID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"": containerID}, nil
+}
+
+// streamLogs passes the log lines of containers keyed by name to emit, streaming the containers
+// concurrently. With Follow it returns once every container stopped or ctx is cancelled.
+// Calls to emit are serialized; an error from emit stops the stream and is returned.
+func (a *Agent) streamLogs(
+	ctx context.Context, containerIDs map[string]string, opts LogStreamOptions, emit func(types.LogEntry) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var emitMu sync.Mutex
+	var emitErr error
+	serialized := func(entry types.LogEntry) error {
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		if emitErr != nil {
+			return emitErr
+		}
+		if err := emit(entry); err != nil {
+			emitErr = err
+			cancel()
+			return err
+		}
+		return nil
+	}
+
+	errs := make([]error, 0, len(containerIDs))
+	var errsMu sync.Mutex
+	var wg sync.WaitGroup
+	for name, containerID := range containerIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			stdout := newLogLineWriter(name, types.LogStreamStdout, opts.Timestamps, serialized)
+			stderr := newLogLineWriter(name, types.LogStreamStderr, opts.Timestamps, serialized)
+			logOpts := runtime.LogOptions{
+				Follow:     opts.Follow,
+				Since:      opts.Since,
+				Tail:       opts.Tail,
+				Timestamps: opts.Timestamps,
+				Stdout:     stdout,
+				Stderr:     stderr,
+			}
+			switch opts.Stream {
+			case types.LogStreamStdout:
+				logOpts.Stderr = io.Discard
+			case types.LogStreamStderr:
+				logOpts.Stdout = io.Discard
+			}
+
+			err := a.runtime.StreamContainerLogs(ctx, containerID, logOpts)
+			if err == nil {
+				err = errors.Join(stdout.Flush(), stderr.Flush())
+			}
+			if err != nil && ctx.Err() == nil {
+				errsMu.Lock()
+				errs = append(errs, err)
+				errsMu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return emitErr
+	}
+	return errors.Join(errs...)
+}
+
+// logLineWriter turns a container's output stream into log entries, one per line
+type logLineWriter struct {
+	container  string
+	stream     string
+	timestamps bool
+	emit       func(types.LogEntry) error
+	partial    []byte
+}
+
+func newLogLineWriter(container, stream string, timestamps bool, emit func(types.LogEntry) error) *logLineWriter {
+	return &logLineWriter{container: container, stream: stream, timestamps: timestamps, emit: emit}
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.partial[:i])
+		w.partial = w.partial[i+1:]
+		if err := w.emitLine(line); err != nil {
+			return 0, err
+		}
+	}
+	if len(w.partial) == 0 {
+		w.partial = nil
+	}
+	return len(p), nil
+}
+
+// Flush emits the final line if it did not end with a newline
+func (w *logLineWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := string(w.partial)
+	w.partial = nil
+	return w.emitLine(line)
+}
+
+// emitLine emits a line, moving its timestamp prefix into the entry when timestamps are on
+func (w *logLineWriter) emitLine(line string) error {
+	entry := types.LogEntry{Container: w.container, Stream: w.stream, Line: strings.TrimSuffix(line, "\r")}
+	if w.timestamps {
+		if prefix, rest, ok := strings.Cut(entry.Line, " "); ok {
+			if ts, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+				entry.Timestamp = &ts
+				entry.Line = rest
+			}
+		}
+	}
+	return w.emit(entry)
+}
+
+// parseSince parses a since parameter given as an RFC 3339 time or as a duration before now
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid since %q: duration must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: want a duration like 10m or an RFC 3339 time", value)
+	}
+	return t, nil
+}
+
+// StreamPodLogs handles GET /api/v1/pods/:id/logs/stream
+// Streams the pod's log lines as newline-delimited JSON types.LogEntry values.
+// Query parameters: container, follow, since, tail, timestamps and stream (stdout or stderr).
+func (s *Server) StreamPodLogs(c echo.Context) error {
+	opts, err := parseLogStreamOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	containerIDs, err := s.agent.podLogContainers(c.Param("id"), opts)
+	return s.writeLogStream(c, containerIDs, opts, err)
+}
+
+// StreamTaskLogs handles GET /api/v1/tasks/:id/logs/stream
+// Streams the task's log lines like StreamPodLogs.
+func (s *Server) StreamTaskLogs(c echo.Context) error {
+	opts, err := parseLogStreamOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	containerIDs, err := s.agent.taskLogContainers(c.Param("id"))
+	return s.writeLogStream(c, containerIDs, opts, err)
+}
+
+// writeLogStream reports a lookup error or writes the containers' log entries as they arrive
+func (s *Server) writeLogStream(
+	c echo.Context, containerIDs map[string]string, opts LogStreamOptions, lookupErr error,
+) error {
+	if lookupErr != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": lookupErr.Error()})
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	encoder := json.NewEncoder(resp)
+	err := s.agent.streamLogs(
+		c.Request().Context(), containerIDs, opts, func(entry types.LogEntry) error {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			resp.Flush()
+			return nil
+		},
+	)
+	if err != nil && c.Request().Context().Err() == nil {
+		log.Printf("log stream for %s ended: %v", c.Param("id"), err)
+	}
+	return nil
+}
+
+// parseLogStreamOptions reads the log stream query parameters
+func parseLogStreamOptions(c echo.Context) (LogStreamOptions, error) {
+	opts := LogStreamOptions{Container: c.QueryParam("container"), Stream: c.QueryParam("stream")}
+
+	switch opts.Stream {
+	case "", types.LogStreamStdout, types.LogStreamStderr:
+	default:
+		return opts, fmt.Errorf("invalid stream %q: want stdout or stderr", opts.Stream)
+	}
+
+	for name, target := range map[string]*bool{"follow": &opts.Follow, "timestamps": &opts.Timestamps} {
+		if value := c.QueryParam(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s parameter", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if value := c.QueryParam("tail"); value != "" {
+		tail, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid tail parameter")
+		}
+		opts.Tail = tail
+	}
+
+	since, err := parseSince(c.QueryParam("since"), time.Now())
+	if err != nil {
+		return opts, err
+	}
+	opts.Since = since
+	return opts, nil
+}
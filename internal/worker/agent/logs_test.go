@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// openLogStream requests a log stream and returns a decoder for its entries
+func openLogStream(t *testing.T, url string) (*json.Decoder, *http.Response) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return json.NewDecoder(bufio.NewReader(resp.Body)), resp
+}
+
+func TestStreamPodLogs(t *testing.T) {
+	server, fake := newExecServer(t)
+	containerID := fake.Containers()[0]
+	_ = fake.AppendLog(containerID, "starting", false)
+	_ = fake.AppendLog(containerID, "warning", true)
+
+	decoder, resp := openLogStream(t, server.URL+"/api/v1/pods/pod-1/logs/stream?timestamps=true")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("response = %d %s, want 200 application/x-ndjson", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var entries []types.LogEntry
+	for decoder.More() {
+		var entry types.LogEntry
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", entries)
+	}
+	for _, entry := range entries {
+		if entry.Container != "app" || entry.Timestamp == nil {
+			t.Errorf("entry = %+v, want a timestamped line of app", entry)
+		}
+	}
+
+	// Streams can be selected
+	decoder, _ = openLogStream(t, server.URL+"/api/v1/pods/pod-1/logs/stream?stream=stderr")
+	var entry types.LogEntry
+	if err := decoder.Decode(&entry); err != nil || entry.Line != "warning" || entry.Stream != types.LogStreamStderr {
+		t.Errorf("entry = %+v, %v, want the stderr line", entry, err)
+	}
+	if decoder.More() {
+		t.Error("expected only the stderr line")
+	}
+
+	// Following delivers new lines until the container stops
+	decoder, _ = openLogStream(t, server.URL+"/api/v1/pods/pod-1/logs/stream?follow=true&tail=1")
+	if err := decoder.Decode(&entry); err != nil || entry.Line != "warning" {
+		t.Fatalf("entry = %+v, %v, want the last line", entry, err)
+	}
+	_ = fake.AppendLog(containerID, "ready", false)
+	if err := decoder.Decode(&entry); err != nil || entry.Line != "ready" || entry.Stream != types.LogStreamStdout {
+		t.Fatalf("entry = %+v, %v, want the followed line", entry, err)
+	}
+	_ = fake.StopContainer(t.Context(), containerID)
+	if decoder.More() {
+		t.Error("expected the stream to end when the container stopped")
+	}
+}
+
+func TestStreamPodLogsErrors(t *testing.T) {
+	server, _ := newExecServer(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "unknown pod", query: "pod-2/logs/stream", wantStatus: http.StatusNotFound},
+		{name: "unknown container", query: "pod-1/logs/stream?container=db", wantStatus: http.StatusNotFound},
+		{name: "invalid since", query: "pod-1/logs/stream?since=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "invalid stream", query: "pod-1/logs/stream?stream=all", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, resp := openLogStream(t, server.URL+"/api/v1/pods/"+tt.query)
+				if resp.StatusCode != tt.wantStatus {
+					t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "10m", want: now.Add(-10 * time.Minute)},
+		{value: "2025-06-01T11:00:00Z", want: now.Add(-time.Hour)},
+		{value: "-5m", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.value, func(t *testing.T) {
+				got, err := parseSince(tt.value, now)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+				}
+				if !got.Equal(tt.want) {
+					t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+				}
+			},
+		)
+	}
+}
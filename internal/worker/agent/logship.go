@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/logship"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// logShippingGracePeriod is how long a finished pod waits for its last log lines to be shipped
+// before its containers are removed
+const logShippingGracePeriod = 5 * time.Second
+
+// SetLogShipper forwards the logs of pod containers to the shipper's sink.
+// It must be called before Start.
+func (a *Agent) SetLogShipper(shipper *logship.Shipper) {
+	a.logShipper = shipper
+}
+
+// logShipLoop sends buffered log records to the sink until the agent stops.
+func (a *Agent) logShipLoop() {
+	a.logShipper.Run(a.stopChan)
+}
+
+// shipContainerLogs follows a pod container's output from since and hands every line to the
+// log shipper, tagged with the pod's identity and labels. It returns immediately; the lines
+// are shipped until the container exits.
+func (a *Agent) shipContainerLogs(
+	execution *PodExecution, containerName, containerID string, since time.Time,
+) {
+	if a.logShipper == nil {
+		return
+	}
+
+	pod := execution.pod
+	template := types.LogRecord{
+		PodID:     pod.PodID,
+		PodName:   pod.Name,
+		Namespace: pod.Namespace,
+		NodeID:    a.nodeID,
+		Labels:    maps.Clone(pod.Labels),
+	}
+	emit := func(entry types.LogEntry) error {
+		record := template
+		record.LogEntry = entry
+		a.logShipper.Add(record)
+		return nil
+	}
+
+	execution.mu.Lock()
+	if execution.logShippingCtx == nil {
+		execution.logShippingCtx, execution.logShippingCancel = context.WithCancel(context.Background())
+	}
+	ctx := execution.logShippingCtx
+	execution.mu.Unlock()
+
+	execution.logShipping.Add(1)
+	go func() {
+		defer execution.logShipping.Done()
+
+		stdout := newLogLineWriter(containerName, types.LogStreamStdout, true, emit)
+		stderr := newLogLineWriter(containerName, types.LogStreamStderr, true, emit)
+		err := a.runtime.StreamContainerLogs(
+			ctx, containerID, runtime.LogOptions{
+				Follow:     true,
+				Since:      since,
+				Timestamps: true,
+				Stdout:     stdout,
+				Stderr:     stderr,
+			},
+		)
+		if err == nil {
+			err = errors.Join(stdout.Flush(), stderr.Flush())
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("log shipping for container %s of pod %s ended: %v", containerName, pod.PodID, err)
+		}
+	}()
+}
+
+// waitForLogShipping gives the pod's log followers a grace period to read the last lines of
+// exited containers, then stops them.
+func (a *Agent) waitForLogShipping(execution *PodExecution) {
+	execution.mu.RLock()
+	cancel := execution.logShippingCancel
+	execution.mu.RUnlock()
+	if cancel == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		execution.logShipping.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(logShippingGracePeriod):
+		log.Printf("log shipping for pod %s did not finish within %s", execution.pod.PodID, logShippingGracePeriod)
+	}
+	cancel()
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/logship"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// memorySink keeps shipped log records in memory
+type memorySink struct {
+	mu      sync.Mutex
+	records []types.LogRecord
+}
+
+func (s *memorySink) Write(_ context.Context, records []types.LogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func TestPodLogsAreShippedBeforeCleanup(t *testing.T) {
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	fake.SetBehavior("app:1.0", runtime.FakeBehavior{ExitAfter: 20 * time.Millisecond, Logs: "starting\nready\n"})
+
+	sink := &memorySink{}
+	shipper := logship.NewShipper(sink, logship.DefaultConfig())
+	agent.SetLogShipper(shipper)
+
+	pod := &types.Pod{
+		PodID:      "pod-1",
+		Name:       "web",
+		Namespace:  "prod",
+		Labels:     map[string]string{"app": "web"},
+		Containers: []types.Container{{Name: "app", Image: "app:1.0"}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := agent.ExecutePod(ctx, pod, nil); err != nil {
+		t.Fatalf("ExecutePod() error = %v", err)
+	}
+	if remaining := fake.Containers(); len(remaining) != 0 {
+		t.Fatalf("expected containers to be removed, got %v", remaining)
+	}
+
+	if err := shipper.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(sink.records) != 2 {
+		t.Fatalf("shipped %d records, want 2: %+v", len(sink.records), sink.records)
+	}
+	for i, want := range []string{"starting", "ready"} {
+		record := sink.records[i]
+		if record.Line != want || record.Container != "app" || record.Stream != types.LogStreamStdout {
+			t.Errorf("record %d = %+v, want %q from app stdout", i, record, want)
+		}
+		if record.PodID != "pod-1" || record.Namespace != "prod" || record.Labels["app"] != "web" {
+			t.Errorf("record %d is not tagged with the pod: %+v", i, record)
+		}
+		if record.NodeID != "test-node" || record.Timestamp == nil {
+			t.Errorf("record %d has node %q and timestamp %v", i, record.NodeID, record.Timestamp)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/eviction"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+)
+
+// heartbeatPayload collects the node status reported to the master with each heartbeat:
+// image inventory, conditions, resource usage and the pods and tasks actually running.
+func (a *Agent) heartbeatPayload(ctx context.Context) map[string]interface{} {
+	payload := map[string]interface{}{
+		"conditions": a.nodeConditions(ctx),
+		"pods":       a.runningPodStatuses(ctx),
+		"tasks":      a.runningTaskStatuses(),
+	}
+	if images := a.imageInventory(); images != nil {
+		payload["images"] = images
+	}
+	if usage := a.nodeUsage(ctx); usage != nil {
+		payload["usage"] = usage
+	}
+	return payload
+}
+
+// nodeConditions returns the Ready and RuntimeUnavailable conditions followed by the pressure conditions.
+func (a *Agent) nodeConditions(ctx context.Context) []types.NodeCondition {
+	runtimeErr := a.runtime.Ping(ctx)
+
+	ready := types.NodeCondition{Type: types.NodeReady, Status: true, Reason: "WorkerReady"}
+	unavailable := types.NodeCondition{
+		Type:    types.NodeRuntimeUnavailable,
+		Reason:  "RuntimeAvailable",
+		Message: a.runtime.Name() + " runtime is reachable",
+	}
+	if runtimeErr != nil {
+		ready.Status = false
+		ready.Reason = "RuntimeUnavailable"
+		ready.Message = runtimeErr.Error()
+		unavailable.Status = true
+		unavailable.Reason = "RuntimeUnreachable"
+		unavailable.Message = runtimeErr.Error()
+	}
+
+	conditions := []types.NodeCondition{a.trackCondition(ready), a.trackCondition(unavailable)}
+	return append(conditions, a.pressureConditions()...)
+}
+
+// trackCondition keeps the last transition time of a condition whose status did not change
+func (a *Agent) trackCondition(condition types.NodeCondition) types.NodeCondition {
+	a.conditionsMu.Lock()
+	defer a.conditionsMu.Unlock()
+
+	if a.conditions == nil {
+		a.conditions = make(map[types.NodeConditionType]types.NodeCondition)
+	}
+
+	previous, exists := a.conditions[condition.Type]
+	if exists && previous.Status == condition.Status {
+		condition.LastTransitionTime = previous.LastTransitionTime
+	} else {
+		condition.LastTransitionTime = time.Now()
+		if exists {
+			log.Printf("node condition %s changed to %t: %s", condition.Type, condition.Status, condition.Message)
+		}
+	}
+
+	a.conditions[condition.Type] = condition
+	return condition
+}
+
+// runningPodStatuses returns the pods running on this worker with the runtime state of their containers.
+func (a *Agent) runningPodStatuses(ctx context.Context) []types.RunningPod {
+	a.mu.RLock()
+	executions := make([]*PodExecution, 0, len(a.runningPods))
+	for _, podExec := range a.runningPods {
+		executions = append(executions, podExec)
+	}
+	a.mu.RUnlock()
+
+	pods := make([]types.RunningPod, 0, len(executions))
+	for _, podExec := range executions {
+		podExec.mu.RLock()
+		running := types.RunningPod{
+			PodID:      podExec.pod.PodID,
+			Containers: make([]types.RunningContainer, 0, len(podExec.containerIDs)),
+		}
+		for name, containerID := range podExec.containerIDs {
+			running.Containers = append(
+				running.Containers, types.RunningContainer{Name: name, ContainerID: containerID},
+			)
+		}
+		podExec.mu.RUnlock()
+
+		for i := range running.Containers {
+			state, err := a.runtime.GetContainerStatus(ctx, running.Containers[i].ContainerID)
+			if err != nil {
+				state = "unknown"
+			}
+			running.Containers[i].State = state
+		}
+		pods = append(pods, running)
+	}
+	return pods
+}
+
+// runningTaskStatuses returns the tasks running on this worker.
+func (a *Agent) runningTaskStatuses() []types.RunningTask {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	tasks := make([]types.RunningTask, 0, len(a.runningTasks))
+	for _, task := range a.runningTasks {
+		tasks = append(tasks, types.RunningTask{TaskID: task.TaskID, ContainerID: task.ContainerID})
+	}
+	return tasks
+}
+
+// nodeUsage returns the node's current CPU, memory, process and runtime disk usage.
+// Signals that cannot be read are left at zero.
+func (a *Agent) nodeUsage(ctx context.Context) *types.ResourceList {
+	var usage types.ResourceList
+
+	if a.cpuSampler != nil {
+		if millicores, ok, err := a.cpuSampler.Sample(); err != nil {
+			log.Printf("failed to sample cpu usage: %v", err)
+		} else if ok {
+			usage.CPU = millicores
+		}
+	}
+
+	if available, total, err := eviction.ReadMemInfo(); err == nil {
+		usage.Memory = total - available
+	}
+
+	if used, _, err := eviction.ReadPIDs(); err == nil {
+		usage.PIDs = used
+	}
+
+	if rootDir, err := a.runtime.RootDir(ctx); err == nil {
+		if disk, err := imagegc.DiskUsage(rootDir); err == nil {
+			usage.EphemeralStorage = int64(disk.UsedBytes())
+		}
+	}
+
+	return &usage
+}
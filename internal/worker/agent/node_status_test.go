@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+func TestNodeConditions(t *testing.T) {
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	ctx := context.Background()
+
+	node := types.Node{Conditions: agent.nodeConditions(ctx)}
+	if !node.IsReady() {
+		t.Fatalf("expected node to be ready, got %+v", node.Conditions)
+	}
+	ready, _ := node.GetCondition(types.NodeReady)
+
+	// An unchanged condition keeps its transition time
+	node = types.Node{Conditions: agent.nodeConditions(ctx)}
+	if again, _ := node.GetCondition(types.NodeReady); !again.LastTransitionTime.Equal(ready.LastTransitionTime) {
+		t.Errorf("LastTransitionTime changed from %v to %v", ready.LastTransitionTime, again.LastTransitionTime)
+	}
+
+	fake.SetPingError(errors.New("connection refused"))
+	node = types.Node{Conditions: agent.nodeConditions(ctx)}
+	if node.IsReady() {
+		t.Error("expected node not to be ready when the runtime is unreachable")
+	}
+	if c, ok := node.GetCondition(types.NodeRuntimeUnavailable); !ok || !c.Status {
+		t.Errorf("expected RuntimeUnavailable condition, got %+v", node.Conditions)
+	}
+}
+
+func TestHeartbeatPayloadReportsWorkloads(t *testing.T) {
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	ctx := context.Background()
+
+	containerID := startLabeledContainer(t, fake, map[string]string{runtime.LabelPodID: "pod-1"})
+	agent.trackPodExecution(
+		"pod-1", &PodExecution{
+			pod:          &types.Pod{PodID: "pod-1"},
+			containerIDs: map[string]string{"app": containerID},
+		},
+	)
+	agent.mu.Lock()
+	agent.runningTasks["task-1"] = &types.Task{TaskID: "task-1", ContainerID: "task-container"}
+	agent.mu.Unlock()
+
+	payload := agent.heartbeatPayload(ctx)
+
+	pods, _ := payload["pods"].([]types.RunningPod)
+	if len(pods) != 1 || pods[0].PodID != "pod-1" {
+		t.Fatalf("pods = %+v, want pod-1", pods)
+	}
+	if c := pods[0].Containers; len(c) != 1 || c[0].ContainerID != containerID || c[0].State != runtime.StateRunning {
+		t.Errorf("containers = %+v, want running %s", c, containerID)
+	}
+
+	tasks, _ := payload["tasks"].([]types.RunningTask)
+	if len(tasks) != 1 || tasks[0].TaskID != "task-1" {
+		t.Errorf("tasks = %+v, want task-1", tasks)
+	}
+
+	if _, ok := payload["usage"].(*types.ResourceList); !ok {
+		t.Errorf("expected resource usage in payload, got %T", payload["usage"])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,8 +12,8 @@ import (
 	"time"
 
 	"github.com/danpasecinic/podling/internal/types"
-	"github.com/danpasecinic/podling/internal/worker/docker"
 	"github.com/danpasecinic/podling/internal/worker/health"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
 )
 
 // PodExecution tracks the state of a running pod
@@ -21,12 +22,22 @@ type PodExecution struct {
 	networkID      string
 	containerIDs   map[string]string
 	healthCheckers map[string]*health.Checker
+	credentials    []types.RegistryCredentials
 	mu             sync.RWMutex
 	cancelFunc     context.CancelFunc
+
+	// evictionMessage is set when the pod was evicted to relieve node pressure
+	evictionMessage string
+
+	// logShipping tracks the followers shipping the containers' logs, which logShippingCancel stops
+	logShipping       sync.WaitGroup
+	logShippingCtx    context.Context
+	logShippingCancel context.CancelFunc
 }
 
 // ExecutePod executes a pod by running all its containers with shared networking
-func (a *Agent) ExecutePod(ctx context.Context, pod *types.Pod) error {
+// Registry credentials are used to authenticate image pulls for private registries
+func (a *Agent) ExecutePod(ctx context.Context, pod *types.Pod, credentials []types.RegistryCredentials) error {
 	log.Printf("starting pod execution: %s (id: %s) with %d containers", pod.Name, pod.PodID, len(pod.Containers))
 
 	podCtx, cancel := context.WithCancel(ctx)
@@ -36,6 +47,7 @@ func (a *Agent) ExecutePod(ctx context.Context, pod *types.Pod) error {
 		pod:            pod,
 		containerIDs:   make(map[string]string),
 		healthCheckers: make(map[string]*health.Checker),
+		credentials:    credentials,
 		cancelFunc:     cancel,
 	}
 
@@ -58,17 +70,26 @@ func (a *Agent) ExecutePod(ctx context.Context, pod *types.Pod) error {
 		return err
 	}
 
-	a.startHealthChecks(podCtx, pod, execution)
+	return a.supervisePod(podCtx, execution)
+}
+
+// supervisePod runs health checks, waits for the pod's started containers to exit,
+// cleans up and reports the final status
+func (a *Agent) supervisePod(ctx context.Context, execution *PodExecution) error {
+	pod := execution.pod
+
+	a.startHealthChecks(ctx, pod, execution)
 
-	if err := a.updatePodIP(podCtx, pod, execution); err != nil {
+	if err := a.updatePodIP(ctx, pod, execution); err != nil {
 		log.Printf("failed to update pod IP: %v", err)
 	}
 
-	containerErrors := a.waitForContainers(podCtx, pod, execution)
+	containerErrors := a.waitForContainers(ctx, pod, execution)
 
 	a.stopHealthCheckers(execution)
+	a.waitForLogShipping(execution)
 	a.cleanupPodResources(context.Background(), execution)
-	return a.finalizePodStatus(pod, containerErrors)
+	return a.finalizePodStatus(execution, containerErrors)
 }
 
 // trackPodExecution registers a pod execution for tracking
@@ -89,10 +110,10 @@ func (a *Agent) untrackPodExecution(podID string) {
 	delete(a.runningPods, podID)
 }
 
-// setupPodNetwork creates a dedicated Docker network for the pod
+// setupPodNetwork creates a dedicated network for the pod
 func (a *Agent) setupPodNetwork(ctx context.Context, pod *types.Pod, execution *PodExecution) error {
 	log.Printf("creating pod network for pod %s", pod.PodID)
-	networkID, err := a.dockerClient.CreatePodNetwork(ctx, pod.PodID)
+	networkID, err := a.createPodNetwork(ctx, pod)
 	if err != nil {
 		errMsg := fmt.Sprintf("failed to create pod network: %v", err)
 		if updateErr := a.updatePodStatus(
@@ -111,17 +132,36 @@ func (a *Agent) setupPodNetwork(ctx context.Context, pod *types.Pod, execution *
 	return nil
 }
 
-// pullContainerImages pulls all container images for the pod
+// pullContainerImages makes all container images available according to their pull policies
 func (a *Agent) pullContainerImages(ctx context.Context, pod *types.Pod, execution *PodExecution) error {
+	onEvent := func(event types.PodEvent) {
+		if err := a.recordPodEvents(pod.PodID, []types.PodEvent{event}); err != nil {
+			log.Printf("failed to record pod event: %v", err)
+		}
+	}
+
 	for i := range pod.Containers {
 		container := &pod.Containers[i]
-		log.Printf("pulling image for container %s: %s", container.Name, container.Image)
+		log.Printf(
+			"ensuring image for container %s: %s (policy: %s)",
+			container.Name, container.Image, container.GetImagePullPolicy(),
+		)
 
-		if err := a.dockerClient.PullImage(ctx, container.Image); err != nil {
+		if err := ensureImage(
+			ctx, a.runtime, container, execution.credentials, a.pullBackoff, onEvent,
+		); err != nil {
+			reason := types.ReasonErrImagePull
+			var pullErr *ImagePullError
+			if errors.As(err, &pullErr) {
+				reason = pullErr.Reason
+			}
+
+			container.Status = types.ContainerWaiting
+			container.Error = err.Error()
 			errMsg := fmt.Sprintf("failed to pull image %s: %v", container.Image, err)
 			a.cleanupPodResources(context.Background(), execution)
 			if updateErr := a.updatePodStatus(
-				pod.PodID, types.PodFailed, pod.Containers, errMsg, "ImagePullError",
+				pod.PodID, types.PodFailed, pod.Containers, errMsg, reason,
 			); updateErr != nil {
 				log.Printf("failed to update pod status: %v", updateErr)
 			}
@@ -147,7 +187,7 @@ func (a *Agent) createPodContainers(ctx context.Context, pod *types.Pod, executi
 
 		log.Printf("creating container %s from image %s in pod network", container.Name, container.Image)
 
-		containerID, err := a.createContainer(ctx, container, env, networkID)
+		containerID, err := a.createContainer(ctx, pod, container, env, networkID)
 		if err != nil {
 			errMsg := fmt.Sprintf("failed to create container %s: %v", container.Name, err)
 			a.cleanupPodResources(context.Background(), execution)
@@ -170,6 +210,7 @@ func (a *Agent) createPodContainers(ctx context.Context, pod *types.Pod, executi
 		if err := a.startContainer(ctx, pod, container, execution); err != nil {
 			return err
 		}
+		a.shipContainerLogs(execution, container.Name, containerID, time.Time{})
 
 		now := time.Now()
 		container.StartedAt = &now
@@ -178,38 +219,44 @@ func (a *Agent) createPodContainers(ctx context.Context, pod *types.Pod, executi
 	return nil
 }
 
-// createContainer creates a single container with or without resource limits
+// createContainer creates a single container with its resource limits, ports and security settings
 func (a *Agent) createContainer(
-	ctx context.Context, container *types.Container, env []string, networkID string,
+	ctx context.Context, pod *types.Pod, container *types.Container, env []string, networkID string,
 ) (string, error) {
-	cpuLimit := container.Resources.Limits.GetCPULimitForDocker()
-	memoryLimit := container.Resources.Limits.GetMemoryLimitForDocker()
-
-	if len(container.Ports) > 0 {
-		ports := make([]docker.PortMapping, len(container.Ports))
-		for i, port := range container.Ports {
-			protocol := port.Protocol
-			if protocol == "" {
-				protocol = "tcp"
-			}
-			ports[i] = docker.PortMapping{
-				ContainerPort: port.ContainerPort,
-				HostPort:      port.HostPort,
-				Protocol:      protocol,
-			}
+	security, err := securityOptions(types.MergeSecurityContext(pod.SecurityContext, container.SecurityContext))
+	if err != nil {
+		return "", err
+	}
+
+	ports := make([]runtime.PortMapping, len(container.Ports))
+	for i, port := range container.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		ports[i] = runtime.PortMapping{
+			ContainerPort: port.ContainerPort,
+			HostPort:      port.HostPort,
+			Protocol:      protocol,
 		}
-		return a.dockerClient.CreateContainerInNetworkWithResourcesAndPorts(
-			ctx, container.Image, env, networkID, cpuLimit, memoryLimit, ports,
-		)
 	}
 
-	if !container.Resources.Limits.IsZero() {
-		return a.dockerClient.CreateContainerInNetworkWithResources(
-			ctx, container.Image, env, networkID, cpuLimit, memoryLimit,
-		)
+	opts := runtime.ContainerOptions{
+		Image:     container.Image,
+		Env:       env,
+		NetworkID: networkID,
+		Ports:     ports,
+		Security:  security,
+		DNS:       a.podDNS(pod),
+		Labels: map[string]string{
+			runtime.LabelNodeID:        a.nodeID,
+			runtime.LabelPodID:         pod.PodID,
+			runtime.LabelContainerName: container.Name,
+		},
 	}
+	a.applyResources(ctx, &opts, container.Resources)
 
-	return a.dockerClient.CreateContainerInNetwork(ctx, container.Image, env, networkID)
+	return a.runtime.CreateContainerWithOptions(ctx, opts)
 }
 
 // startContainer starts a single container
@@ -218,7 +265,7 @@ func (a *Agent) startContainer(
 ) error {
 	log.Printf("starting container %s (id: %s)", container.Name, container.ContainerID)
 
-	if err := a.dockerClient.StartContainer(ctx, container.ContainerID); err != nil {
+	if err := a.runtime.StartContainer(ctx, container.ContainerID); err != nil {
 		errMsg := fmt.Sprintf("failed to start container %s: %v", container.Name, err)
 		container.Status = types.ContainerTerminated
 		container.Error = err.Error()
@@ -231,6 +278,8 @@ func (a *Agent) startContainer(
 		return fmt.Errorf("failed to start container %s: %w", container.Name, err)
 	}
 
+	a.markImageUsed(container.Image)
+
 	return nil
 }
 
@@ -269,7 +318,7 @@ func (a *Agent) startHealthChecks(ctx context.Context, pod *types.Pod, execution
 			container.ContainerID,
 			container.LivenessProbe,
 			restartPolicy,
-			a.dockerClient,
+			a.runtime,
 			onUnhealthy,
 		)
 
@@ -291,7 +340,7 @@ func (a *Agent) updatePodIP(ctx context.Context, pod *types.Pod, execution *PodE
 
 	var podIP string
 	if len(pod.Containers) > 0 && pod.Containers[0].ContainerID != "" {
-		ip, err := a.dockerClient.GetNetworkIP(ctx, pod.Containers[0].ContainerID, networkID)
+		ip, err := a.runtime.GetNetworkIP(ctx, pod.Containers[0].ContainerID, networkID)
 		if err != nil {
 			log.Printf("failed to get pod IP from network: %v", err)
 		} else {
@@ -319,7 +368,7 @@ func (a *Agent) waitForContainers(ctx context.Context, pod *types.Pod, execution
 			defer wg.Done()
 
 			containerID := container.ContainerID
-			exitCode64, err := a.dockerClient.WaitContainer(ctx, containerID)
+			exitCode64, err := a.runtime.WaitContainer(ctx, containerID)
 
 			now := time.Now()
 			container.FinishedAt = &now
@@ -369,7 +418,8 @@ func (a *Agent) stopHealthCheckers(execution *PodExecution) {
 }
 
 // finalizePodStatus determines the final pod status and updates the master
-func (a *Agent) finalizePodStatus(pod *types.Pod, containerErrors []error) error {
+func (a *Agent) finalizePodStatus(execution *PodExecution, containerErrors []error) error {
+	pod := execution.pod
 	finalStatus := types.PodSucceeded
 	message := "All containers completed successfully"
 	reason := "Completed"
@@ -380,10 +430,24 @@ func (a *Agent) finalizePodStatus(pod *types.Pod, containerErrors []error) error
 		reason = "ContainerError"
 	}
 
+	execution.mu.RLock()
+	evictionMessage := execution.evictionMessage
+	execution.mu.RUnlock()
+
+	if evictionMessage != "" {
+		finalStatus = types.PodFailed
+		message = evictionMessage
+		reason = types.ReasonEvicted
+	}
+
 	if err := a.updatePodStatus(pod.PodID, finalStatus, pod.Containers, message, reason); err != nil {
 		log.Printf("failed to update final pod status: %v", err)
 	}
 
+	if evictionMessage != "" {
+		return fmt.Errorf("pod evicted: %s", message)
+	}
+
 	if len(containerErrors) > 0 {
 		return fmt.Errorf("pod failed: %s", message)
 	}
@@ -405,20 +469,21 @@ func (a *Agent) cleanupPodResources(ctx context.Context, execution *PodExecution
 	for name, containerID := range containerIDs {
 		log.Printf("cleaning up container %s (id: %s)", name, containerID)
 
-		if err := a.dockerClient.StopContainer(ctx, containerID); err != nil {
+		if err := a.runtime.StopContainer(ctx, containerID); err != nil {
 			log.Printf("error stopping container %s: %v", name, err)
 		}
 
-		if err := a.dockerClient.RemoveContainer(ctx, containerID); err != nil {
+		if err := a.runtime.RemoveContainer(ctx, containerID); err != nil {
 			log.Printf("error removing container %s: %v", name, err)
 		}
 	}
 
 	if networkID != "" {
 		log.Printf("removing pod network: %s", networkID)
-		if err := a.dockerClient.RemovePodNetwork(ctx, networkID); err != nil {
+		if err := a.runtime.RemovePodNetwork(ctx, networkID); err != nil {
 			log.Printf("error removing pod network %s: %v", networkID, err)
 		}
+		a.releasePodSubnet(execution.pod.PodID)
 	}
 }
 
@@ -467,6 +532,35 @@ func (a *Agent) updatePodStatus(
 	return nil
 }
 
+// recordPodEvents sends pod events to the master
+func (a *Agent) recordPodEvents(podID string, events []types.PodEvent) error {
+	url := fmt.Sprintf("%s/api/v1/pods/%s/events", a.masterURL, podID)
+
+	jsonData, err := json.Marshal(map[string]interface{}{"events": events})
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod events: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create pod events request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send pod events: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pod events request returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 // updatePodStatusWithIP sends a pod status update to the master including pod IP
 func (a *Agent) updatePodStatusWithIP(
 	podID string, status types.PodStatus, containers []types.Container, podIP, message, reason string,
@@ -0,0 +1,194 @@
+package agent
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// defaultPodSubnetPrefix gives each pod a /28: the gateway and up to 13 containers
+const defaultPodSubnetPrefix = 28
+
+// PodNetworkConfig controls how the node's pods are addressed
+type PodNetworkConfig struct {
+	// InternalIP is the address other nodes reach this node's pods through; it is reported to
+	// the master at registration
+	InternalIP string
+
+	// SubnetPrefix is the prefix length of each pod's subnet within the node's pod CIDR
+	SubnetPrefix int
+
+	// MTU of pod network interfaces; zero uses the runtime default
+	MTU int
+}
+
+// DefaultPodNetworkConfig returns the default pod network settings
+func DefaultPodNetworkConfig() PodNetworkConfig {
+	return PodNetworkConfig{SubnetPrefix: defaultPodSubnetPrefix}
+}
+
+// Validate checks that the settings are consistent
+func (c PodNetworkConfig) Validate() error {
+	if c.InternalIP != "" && net.ParseIP(c.InternalIP) == nil {
+		return fmt.Errorf("internal IP %q is not an IP address", c.InternalIP)
+	}
+	// A pod subnet holds at least the network, gateway, container and broadcast addresses
+	if c.SubnetPrefix < 1 || c.SubnetPrefix > 30 {
+		return fmt.Errorf("pod subnet prefix must be between 1 and 30, got %d", c.SubnetPrefix)
+	}
+	if c.MTU < 0 {
+		return fmt.Errorf("MTU must not be negative, got %d", c.MTU)
+	}
+	return nil
+}
+
+// SetPodNetworkConfig changes how pods are addressed. It must be called before Register.
+func (a *Agent) SetPodNetworkConfig(config PodNetworkConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	a.podNetwork = config
+	return nil
+}
+
+// PodCIDR returns the pod subnet the master assigned to the node, or "" if it assigned none
+func (a *Agent) PodCIDR() string {
+	if a.podSubnets == nil {
+		return ""
+	}
+	return a.podSubnets.cidr.String()
+}
+
+// setPodCIDR starts handing out pod subnets from the node's pod CIDR
+func (a *Agent) setPodCIDR(cidr string) error {
+	if cidr == a.PodCIDR() {
+		return nil
+	}
+	subnets, err := newPodSubnets(cidr, a.podNetwork.SubnetPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to use pod CIDR %s: %w", cidr, err)
+	}
+	a.podSubnets = subnets
+	log.Printf("pods on this node get addresses from %s", cidr)
+	return nil
+}
+
+// createPodNetwork creates the pod's network, with a subnet of the node's pod CIDR if it has one
+func (a *Agent) createPodNetwork(ctx context.Context, pod *types.Pod) (string, error) {
+	opts := runtime.PodNetworkOptions{NodeID: a.nodeID, DNS: a.podDNS(pod), MTU: a.podNetwork.MTU}
+	if a.podSubnets != nil {
+		subnet, err := a.podSubnets.allocate(pod.PodID)
+		if err != nil {
+			return "", err
+		}
+		opts.Subnet = subnet
+	}
+
+	networkID, err := a.runtime.CreatePodNetwork(ctx, pod.PodID, opts)
+	if err != nil {
+		a.releasePodSubnet(pod.PodID)
+		return "", err
+	}
+	return networkID, nil
+}
+
+// releasePodSubnet frees the subnet of a pod whose network was removed
+func (a *Agent) releasePodSubnet(podID string) {
+	if a.podSubnets != nil {
+		a.podSubnets.release(podID)
+	}
+}
+
+// errPodSubnetsExhausted is returned when every pod subnet of the node's pod CIDR is in use
+var errPodSubnetsExhausted = errors.New("no pod subnets available")
+
+// podSubnets hands out equally sized subnets of the node's pod CIDR, one per pod
+type podSubnets struct {
+	cidr   *net.IPNet
+	prefix int
+
+	mu    sync.Mutex
+	byPod map[string]string
+	used  map[string]bool
+}
+
+// newPodSubnets creates an allocator of /prefix subnets from the IPv4 cidr
+func newPodSubnets(cidr string, prefix int) (*podSubnets, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	if ipNet.IP.To4() == nil {
+		return nil, fmt.Errorf("only IPv4 pod CIDRs are supported")
+	}
+	if bits, _ := ipNet.Mask.Size(); prefix < bits {
+		return nil, fmt.Errorf("pod subnet prefix /%d is larger than the pod CIDR", prefix)
+	}
+	return &podSubnets{
+		cidr:   ipNet,
+		prefix: prefix,
+		byPod:  make(map[string]string),
+		used:   make(map[string]bool),
+	}, nil
+}
+
+// allocate returns the pod's subnet, picking the first free one if it has none
+func (s *podSubnets) allocate(podID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subnet, ok := s.byPod[podID]; ok {
+		return subnet, nil
+	}
+
+	bits, _ := s.cidr.Mask.Size()
+	base := binary.BigEndian.Uint32(s.cidr.IP.To4())
+	for i := 0; i < 1<<(s.prefix-bits); i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, base+uint32(i)<<(32-s.prefix))
+		subnet := fmt.Sprintf("%s/%d", ip, s.prefix)
+		if s.used[subnet] {
+			continue
+		}
+		s.used[subnet] = true
+		s.byPod[podID] = subnet
+		return subnet, nil
+	}
+	return "", fmt.Errorf("%w in %s", errPodSubnetsExhausted, s.cidr)
+}
+
+// reserve marks the subnet of a recovered pod network as in use. Subnets outside the pod CIDR,
+// such as ones picked by the runtime, are ignored.
+func (s *podSubnets) reserve(podID, subnet string) {
+	ip, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil || !s.cidr.Contains(ip) {
+		return
+	}
+	if bits, _ := ipNet.Mask.Size(); bits != s.prefix {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used[ipNet.String()] = true
+	s.byPod[podID] = ipNet.String()
+}
+
+// release frees the pod's subnet
+func (s *podSubnets) release(podID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subnet, ok := s.byPod[podID]; ok {
+		delete(s.used, subnet)
+		delete(s.byPod, podID)
+	}
+}
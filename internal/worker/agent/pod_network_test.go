@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+func TestPodSubnets(t *testing.T) {
+	subnets, err := newPodSubnets("10.244.4.0/26", 28)
+	if err != nil {
+		t.Fatalf("newPodSubnets() error = %v", err)
+	}
+
+	subnets.reserve("pod-recovered", "10.244.4.16/28")
+	subnets.reserve("pod-elsewhere", "172.18.0.0/16")
+
+	want := map[string]string{
+		"pod-1": "10.244.4.0/28",
+		"pod-2": "10.244.4.32/28",
+		"pod-3": "10.244.4.48/28",
+	}
+	for _, podID := range []string{"pod-1", "pod-2", "pod-3"} {
+		got, err := subnets.allocate(podID)
+		if err != nil {
+			t.Fatalf("allocate(%s) error = %v", podID, err)
+		}
+		if got != want[podID] {
+			t.Errorf("allocate(%s) = %s, want %s", podID, got, want[podID])
+		}
+	}
+	if got, _ := subnets.allocate("pod-1"); got != want["pod-1"] {
+		t.Errorf("allocate(pod-1) again = %s, want %s", got, want["pod-1"])
+	}
+	if _, err := subnets.allocate("pod-4"); !errors.Is(err, errPodSubnetsExhausted) {
+		t.Errorf("allocate() from a full CIDR error = %v, want errPodSubnetsExhausted", err)
+	}
+
+	subnets.release("pod-recovered")
+	if got, _ := subnets.allocate("pod-4"); got != "10.244.4.16/28" {
+		t.Errorf("allocate(pod-4) = %s, want the released 10.244.4.16/28", got)
+	}
+
+	if _, err := newPodSubnets("10.244.4.0/26", 24); err == nil {
+		t.Error("newPodSubnets() with a prefix shorter than the CIDR succeeded, want an error")
+	}
+}
+
+func TestPodNetworkUsesNodePodCIDR(t *testing.T) {
+	var registration map[string]interface{}
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&registration)
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(types.Node{NodeID: "worker-1", PodCIDR: "10.244.8.0/22"})
+			},
+		),
+	)
+	defer server.Close()
+
+	fake := runtime.NewFake()
+	agent := NewAgentWithRuntime("worker-1", server.URL, fake)
+	defer agent.Stop()
+
+	config := DefaultPodNetworkConfig()
+	config.InternalIP = "192.168.1.10"
+	config.MTU = 1450
+	if err := agent.SetPodNetworkConfig(config); err != nil {
+		t.Fatalf("SetPodNetworkConfig() error = %v", err)
+	}
+	if err := agent.Register("localhost", 8081); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if registration["internalIp"] != "192.168.1.10" {
+		t.Errorf("registered internalIp = %v, want 192.168.1.10", registration["internalIp"])
+	}
+	if agent.PodCIDR() != "10.244.8.0/22" {
+		t.Errorf("PodCIDR() = %q, want 10.244.8.0/22", agent.PodCIDR())
+	}
+
+	ctx := context.Background()
+	if _, err := agent.createPodNetwork(ctx, &types.Pod{PodID: "pod-1"}); err != nil {
+		t.Fatalf("createPodNetwork() error = %v", err)
+	}
+	networks, _ := fake.ListPodNetworks(ctx)
+	if len(networks) != 1 || networks[0].Subnet != "10.244.8.0/28" {
+		t.Fatalf("pod networks = %+v, want one with subnet 10.244.8.0/28", networks)
+	}
+
+	agent.releasePodSubnet("pod-1")
+	if got, _ := agent.podSubnets.allocate("pod-2"); got != "10.244.8.0/28" {
+		t.Errorf("subnet after release = %s, want 10.244.8.0/28", got)
+	}
+}
+
+func TestPodNetworkConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*PodNetworkConfig)
+		wantErr bool
+	}{
+		{name: "default", modify: func(*PodNetworkConfig) {}},
+		{name: "internal IP", modify: func(c *PodNetworkConfig) { c.InternalIP = "10.0.0.5" }},
+		{name: "invalid internal IP", modify: func(c *PodNetworkConfig) { c.InternalIP = "node-1" }, wantErr: true},
+		{name: "prefix too long", modify: func(c *PodNetworkConfig) { c.SubnetPrefix = 31 }, wantErr: true},
+		{name: "negative MTU", modify: func(c *PodNetworkConfig) { c.MTU = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := DefaultPodNetworkConfig()
+				tt.modify(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
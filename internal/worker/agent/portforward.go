@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/stream"
+	"github.com/labstack/echo/v4"
+)
+
+// portForwardDialTimeout bounds connecting to the pod port
+const portForwardDialTimeout = 5 * time.Second
+
+// PortForwardPod handles POST /api/v1/pods/:id/portforward
+// Connects to a TCP port on the pod's network IP and upgrades the request to a stream carrying
+// the connection's bytes. Query parameter: port (required).
+func (s *Server) PortForwardPod(c echo.Context) error {
+	if !stream.IsUpgrade(c.Request()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "port-forward requires a stream upgrade"})
+	}
+	port, err := strconv.Atoi(c.QueryParam("port"))
+	if err != nil || port < 1 || port > 65535 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "a port between 1 and 65535 is required"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), portForwardDialTimeout)
+	defer cancel()
+
+	podIP, err := s.agent.podIP(ctx, c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	dialer := net.Dialer{}
+	target, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(podIP, strconv.Itoa(port)))
+	if err != nil {
+		return c.JSON(
+			http.StatusBadGateway, map[string]string{"error": fmt.Sprintf("failed to connect to pod port %d: %v", port, err)},
+		)
+	}
+
+	conn, err := stream.Upgrade(c.Response(), c.Request())
+	if err != nil {
+		_ = target.Close()
+		log.Printf("port-forward to pod %s: %v", c.Param("id"), err)
+		return nil
+	}
+
+	stream.Join(conn, target)
+	return nil
+}
+
+// podIP returns the network IP shared by a running pod's containers
+func (a *Agent) podIP(ctx context.Context, podID string) (string, error) {
+	a.mu.RLock()
+	podExec, ok := a.runningPods[podID]
+	a.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("pod %s not found or not running", podID)
+	}
+
+	podExec.mu.RLock()
+	networkID := podExec.networkID
+	var containerID string
+	if len(podExec.pod.Containers) > 0 {
+		containerID = podExec.containerIDs[podExec.pod.Containers[0].Name]
+	}
+	podExec.mu.RUnlock()
+
+	if containerID == "" {
+		return "", fmt.Errorf("pod %s has no running container", podID)
+	}
+
+	ip, err := a.runtime.GetNetworkIP(ctx, containerID, networkID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get IP of pod %s: %w", podID, err)
+	}
+	if ip == "" {
+		return "", fmt.Errorf("pod %s has no IP address", podID)
+	}
+	return ip, nil
+}
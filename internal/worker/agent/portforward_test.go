@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/stream"
+)
+
+func TestPortForwardPod(t *testing.T) {
+	server, fake := newExecServer(t)
+	ctx := context.Background()
+
+	// The pod's network IP points at a local listener that replies once the request is complete
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listener.Close() }()
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go func() {
+				defer func() { _ = conn.Close() }()
+				request, _ := io.ReadAll(conn)
+				_, _ = conn.Write(request)
+			}()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	for _, c := range fake.Containers() {
+		if err := fake.SetContainerIP(c, "127.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn, err := stream.Dial(ctx, http.MethodPost, server.URL+"/api/v1/pods/pod-1/portforward?port="+strconv.Itoa(port))
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	// The client half-closes and still receives the reply
+	if err := conn.(interface{ CloseWrite() error }).CloseWrite(); err != nil {
+		t.Fatalf("CloseWrite() error = %v", err)
+	}
+	if reply, err := io.ReadAll(conn); err != nil || string(reply) != "ping" {
+		t.Errorf("reply = %q, %v, want ping", reply, err)
+	}
+
+	// A request without an upgrade is refused before the pod port is dialed
+	resp, err := http.Post(server.URL+"/api/v1/pods/pod-1/portforward?port="+strconv.Itoa(port), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain request status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("pod port accepted %d connections, want only the forwarded one", n)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "missing port", path: "/api/v1/pods/pod-1/portforward", wantStatus: http.StatusBadRequest},
+		{name: "invalid port", path: "/api/v1/pods/pod-1/portforward?port=70000", wantStatus: http.StatusBadRequest},
+		{name: "unknown pod", path: "/api/v1/pods/pod-2/portforward?port=80", wantStatus: http.StatusNotFound},
+		{name: "closed port", path: "/api/v1/pods/pod-1/portforward?port=1", wantStatus: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := stream.Dial(ctx, http.MethodPost, server.URL+tt.path)
+				var statusErr *stream.StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Errorf("Dial() error = %v, want status %d", err, tt.wantStatus)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,311 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/health"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// ReasonContainersLost is reported for pods whose containers disappeared while the worker was down
+const ReasonContainersLost = "ContainersLost"
+
+// RecoveryResult summarizes what Recover found on the node
+type RecoveryResult struct {
+	AdoptedPods    int
+	AdoptedTasks   int
+	OrphansRemoved int
+	LostPods       int
+	LostTasks      int
+}
+
+// Recover rediscovers the containers and networks left behind by a previous run of the agent.
+// Pods and tasks the master still expects on this node are adopted and supervised again;
+// containers the master no longer knows about are stopped and removed, and pods the master
+// believes are running here but whose containers are gone are reported as failed.
+// It must be called after Register and before the agent accepts new work.
+func (a *Agent) Recover(ctx context.Context) (RecoveryResult, error) {
+	var result RecoveryResult
+
+	// Workers sharing a runtime each recover only what they created
+	containers, err := a.runtime.ListContainers(
+		ctx, map[string]string{runtime.LabelManaged: "true", runtime.LabelNodeID: a.nodeID},
+	)
+	if err != nil {
+		return result, fmt.Errorf("failed to list managed containers: %w", err)
+	}
+
+	networks, err := a.runtime.ListPodNetworks(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to list pod networks: %w", err)
+	}
+
+	// Without the master's view nothing can be safely removed
+	pods, err := a.listPodsFromMaster()
+	if err != nil {
+		return result, err
+	}
+	tasks, err := a.listTasksFromMaster()
+	if err != nil {
+		return result, err
+	}
+
+	podsByID := make(map[string]types.Pod, len(pods))
+	for _, pod := range pods {
+		podsByID[pod.PodID] = pod
+	}
+	tasksByID := make(map[string]types.Task, len(tasks))
+	for _, task := range tasks {
+		tasksByID[task.TaskID] = task
+	}
+
+	podContainers := make(map[string][]runtime.ContainerInfo)
+	taskContainers := make(map[string]runtime.ContainerInfo)
+	for _, c := range containers {
+		switch {
+		case c.Labels[runtime.LabelPodID] != "":
+			podContainers[c.Labels[runtime.LabelPodID]] = append(podContainers[c.Labels[runtime.LabelPodID]], c)
+		case c.Labels[runtime.LabelTaskID] != "":
+			taskContainers[c.Labels[runtime.LabelTaskID]] = c
+		default:
+			log.Printf("recovery: container %s has no pod or task label, leaving it alone", c.ID)
+		}
+	}
+
+	podNetworks := make(map[string][]string)
+	for _, n := range networks {
+		if !a.ownsNetwork(n, podsByID, podContainers) {
+			continue
+		}
+		podNetworks[n.PodID] = append(podNetworks[n.PodID], n.ID)
+	}
+
+	adoptedPods := make(map[string]bool)
+	for podID, found := range podContainers {
+		pod, known := podsByID[podID]
+		if known && a.ownsPod(pod) && !pod.IsPodTerminal() && a.adoptPod(pod, found, podNetworks[podID]) {
+			adoptedPods[podID] = true
+			result.AdoptedPods++
+			continue
+		}
+
+		log.Printf("recovery: removing %d orphaned container(s) of pod %s", len(found), podID)
+		for _, c := range found {
+			a.removeOrphanContainer(ctx, c.ID)
+			result.OrphansRemoved++
+		}
+	}
+
+	for _, n := range networks {
+		if adoptedPods[n.PodID] && n.Subnet != "" && a.podSubnets != nil {
+			a.podSubnets.reserve(n.PodID, n.Subnet)
+		}
+	}
+
+	for podID, networkIDs := range podNetworks {
+		if adoptedPods[podID] {
+			continue
+		}
+		for _, networkID := range networkIDs {
+			log.Printf("recovery: removing orphaned network %s of pod %s", networkID, podID)
+			if err := a.runtime.RemovePodNetwork(ctx, networkID); err != nil {
+				log.Printf("recovery: failed to remove network %s: %v", networkID, err)
+			}
+		}
+	}
+
+	adoptedTasks := make(map[string]bool)
+	for taskID, c := range taskContainers {
+		task, known := tasksByID[taskID]
+		active := task.Status == types.TaskRunning || task.Status == types.TaskScheduled
+		if known && active && task.NodeID == a.nodeID {
+			a.adoptTask(task, c)
+			adoptedTasks[taskID] = true
+			result.AdoptedTasks++
+			continue
+		}
+
+		log.Printf("recovery: removing orphaned container %s of task %s", c.ID, taskID)
+		a.removeOrphanContainer(ctx, c.ID)
+		result.OrphansRemoved++
+	}
+
+	for _, pod := range pods {
+		if pod.NodeID != a.nodeID || pod.Status != types.PodRunning || adoptedPods[pod.PodID] {
+			continue
+		}
+		log.Printf("recovery: containers of pod %s were lost", pod.PodID)
+		if err := a.updatePodStatus(
+			pod.PodID, types.PodFailed, nil, "Pod containers were not found after the worker restarted",
+			ReasonContainersLost,
+		); err != nil {
+			log.Printf("recovery: failed to report lost pod %s: %v", pod.PodID, err)
+		}
+		result.LostPods++
+	}
+
+	for _, task := range tasks {
+		if task.NodeID != a.nodeID || task.Status != types.TaskRunning || adoptedTasks[task.TaskID] {
+			continue
+		}
+		log.Printf("recovery: container of task %s was lost", task.TaskID)
+		if err := a.updateTaskStatus(
+			task.TaskID, types.TaskFailed, task.ContainerID, "container was not found after the worker restarted",
+		); err != nil {
+			log.Printf("recovery: failed to report lost task %s: %v", task.TaskID, err)
+		}
+		result.LostTasks++
+	}
+
+	log.Printf(
+		"recovery: adopted %d pod(s) and %d task(s), removed %d orphaned container(s), %d pod(s) and %d task(s) lost",
+		result.AdoptedPods, result.AdoptedTasks, result.OrphansRemoved, result.LostPods, result.LostTasks,
+	)
+	return result, nil
+}
+
+// ownsPod reports whether the master binds the pod to this node
+func (a *Agent) ownsPod(pod types.Pod) bool {
+	return pod.NodeID == a.nodeID
+}
+
+// ownsNetwork reports whether a pod network was created by this node. Networks created without a
+// node label belong to this node only if its containers or the master's binding point at the pod.
+func (a *Agent) ownsNetwork(
+	n runtime.NetworkInfo, podsByID map[string]types.Pod, podContainers map[string][]runtime.ContainerInfo,
+) bool {
+	if n.NodeID != "" {
+		return n.NodeID == a.nodeID
+	}
+	if len(podContainers[n.PodID]) > 0 {
+		return true
+	}
+	pod, known := podsByID[n.PodID]
+	return known && a.ownsPod(pod)
+}
+
+// adoptPod tracks a pod found on the node and resumes supervising it.
+// It returns false if any of the pod's containers is missing.
+func (a *Agent) adoptPod(pod types.Pod, containers []runtime.ContainerInfo, networkIDs []string) bool {
+	byName := make(map[string]runtime.ContainerInfo, len(containers))
+	for _, c := range containers {
+		byName[c.Labels[runtime.LabelContainerName]] = c
+	}
+
+	containerIDs := make(map[string]string, len(pod.Containers))
+	for i := range pod.Containers {
+		c, ok := byName[pod.Containers[i].Name]
+		if !ok {
+			log.Printf("recovery: container %s of pod %s is missing", pod.Containers[i].Name, pod.PodID)
+			return false
+		}
+		pod.Containers[i].ContainerID = c.ID
+		containerIDs[pod.Containers[i].Name] = c.ID
+		if c.State == runtime.StateRunning {
+			pod.Containers[i].Status = types.ContainerRunning
+		}
+	}
+
+	var networkID string
+	if len(networkIDs) > 0 {
+		networkID = networkIDs[0]
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	execution := &PodExecution{
+		pod:            &pod,
+		networkID:      networkID,
+		containerIDs:   containerIDs,
+		healthCheckers: make(map[string]*health.Checker),
+		cancelFunc:     cancel,
+	}
+	a.trackPodExecution(pod.PodID, execution)
+
+	// Lines written before the restart were shipped by the previous run
+	now := time.Now()
+	for name, containerID := range containerIDs {
+		a.shipContainerLogs(execution, name, containerID, now)
+	}
+
+	log.Printf("recovery: adopted pod %s with %d container(s)", pod.PodID, len(containerIDs))
+
+	go func() {
+		defer cancel()
+		defer a.untrackPodExecution(pod.PodID)
+		if err := a.supervisePod(ctx, execution); err != nil {
+			log.Printf("adopted pod %s finished: %v", pod.PodID, err)
+		}
+	}()
+	return true
+}
+
+// adoptTask tracks a task found on the node and resumes supervising its container
+func (a *Agent) adoptTask(task types.Task, c runtime.ContainerInfo) {
+	task.ContainerID = c.ID
+
+	a.mu.Lock()
+	a.runningTasks[task.TaskID] = &task
+	a.mu.Unlock()
+
+	log.Printf("recovery: adopted task %s (container %s)", task.TaskID, c.ID)
+
+	go func() {
+		defer func() {
+			a.mu.Lock()
+			delete(a.runningTasks, task.TaskID)
+			a.mu.Unlock()
+		}()
+		if err := a.superviseTask(context.Background(), &task, c.ID); err != nil {
+			log.Printf("adopted task %s finished: %v", task.TaskID, err)
+		}
+	}()
+}
+
+// removeOrphanContainer stops and removes a container no workload owns
+func (a *Agent) removeOrphanContainer(ctx context.Context, containerID string) {
+	if err := a.runtime.StopContainer(ctx, containerID); err != nil {
+		log.Printf("recovery: failed to stop container %s: %v", containerID, err)
+	}
+	if err := a.runtime.RemoveContainer(ctx, containerID); err != nil {
+		log.Printf("recovery: failed to remove container %s: %v", containerID, err)
+	}
+}
+
+// listPodsFromMaster fetches all pods from the master
+func (a *Agent) listPodsFromMaster() ([]types.Pod, error) {
+	var pods []types.Pod
+	if err := a.getFromMaster("/api/v1/pods", &pods); err != nil {
+		return nil, fmt.Errorf("failed to fetch pods: %w", err)
+	}
+	return pods, nil
+}
+
+// listTasksFromMaster fetches all tasks from the master
+func (a *Agent) listTasksFromMaster() ([]types.Task, error) {
+	var tasks []types.Task
+	if err := a.getFromMaster("/api/v1/tasks", &tasks); err != nil {
+		return nil, fmt.Errorf("failed to fetch tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// getFromMaster decodes the JSON response of a GET request to the master
+func (a *Agent) getFromMaster(path string, out interface{}) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(a.masterURL + path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("master returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,283 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// newRecoveryAgent returns an agent whose master reports the given pods and tasks
+func newRecoveryAgent(t *testing.T, pods []types.Pod, tasks []types.Task) (
+	*Agent, *runtime.Fake, *statusRecorder,
+) {
+	t.Helper()
+
+	recorder := &statusRecorder{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"GET /api/v1/pods", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(pods)
+		},
+	)
+	mux.HandleFunc(
+		"GET /api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(tasks)
+		},
+	)
+	mux.HandleFunc("/", recorder.handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	fake := runtime.NewFake()
+	agent := NewAgentWithRuntime("test-node", server.URL, fake)
+	t.Cleanup(agent.Stop)
+
+	return agent, fake, recorder
+}
+
+// startLabeledContainer simulates a container left running by a previous agent
+func startLabeledContainer(t *testing.T, fake *runtime.Fake, labels map[string]string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := fake.PullImage(ctx, "app:1.0"); err != nil {
+		t.Fatalf("PullImage() error = %v", err)
+	}
+	id, err := fake.CreateContainerWithOptions(ctx, runtime.ContainerOptions{Image: "app:1.0", Labels: labels})
+	if err != nil {
+		t.Fatalf("CreateContainerWithOptions() error = %v", err)
+	}
+	if err := fake.StartContainer(ctx, id); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	return id
+}
+
+func TestRecover(t *testing.T) {
+	pods := []types.Pod{
+		{
+			PodID:      "pod-adopted",
+			NodeID:     "test-node",
+			Status:     types.PodRunning,
+			Containers: []types.Container{{Name: "app", Image: "app:1.0"}},
+		},
+		{
+			PodID:      "pod-lost",
+			NodeID:     "test-node",
+			Status:     types.PodRunning,
+			Containers: []types.Container{{Name: "app", Image: "app:1.0"}},
+		},
+		{
+			PodID:      "pod-deleted",
+			NodeID:     "test-node",
+			Status:     types.PodSucceeded,
+			Containers: []types.Container{{Name: "app", Image: "app:1.0"}},
+		},
+	}
+	tasks := []types.Task{
+		{TaskID: "task-adopted", NodeID: "test-node", Status: types.TaskRunning, Image: "app:1.0"},
+	}
+
+	agent, fake, recorder := newRecoveryAgent(t, pods, tasks)
+	ctx := context.Background()
+
+	adoptedID := startLabeledContainer(
+		t, fake, map[string]string{
+			runtime.LabelNodeID:        "test-node",
+			runtime.LabelPodID:         "pod-adopted",
+			runtime.LabelContainerName: "app",
+		},
+	)
+	terminalID := startLabeledContainer(
+		t, fake, map[string]string{
+			runtime.LabelNodeID:        "test-node",
+			runtime.LabelPodID:         "pod-deleted",
+			runtime.LabelContainerName: "app",
+		},
+	)
+	unknownID := startLabeledContainer(
+		t, fake, map[string]string{
+			runtime.LabelNodeID:        "test-node",
+			runtime.LabelPodID:         "pod-unknown",
+			runtime.LabelContainerName: "app",
+		},
+	)
+	taskID := startLabeledContainer(
+		t, fake, map[string]string{runtime.LabelNodeID: "test-node", runtime.LabelTaskID: "task-adopted"},
+	)
+	if err := agent.setPodCIDR("10.244.8.0/22"); err != nil {
+		t.Fatalf("setPodCIDR() error = %v", err)
+	}
+	adoptedNetwork := runtime.PodNetworkOptions{NodeID: "test-node", Subnet: "10.244.8.0/28"}
+	if _, err := fake.CreatePodNetwork(ctx, "pod-adopted", adoptedNetwork); err != nil {
+		t.Fatalf("CreatePodNetwork() error = %v", err)
+	}
+	if _, err := fake.CreatePodNetwork(ctx, "pod-unknown", runtime.PodNetworkOptions{}); err != nil {
+		t.Fatalf("CreatePodNetwork() error = %v", err)
+	}
+
+	result, err := agent.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	want := RecoveryResult{AdoptedPods: 1, AdoptedTasks: 1, OrphansRemoved: 2, LostPods: 1}
+	if result != want {
+		t.Errorf("Recover() = %+v, want %+v", result, want)
+	}
+
+	remaining := make(map[string]bool)
+	for _, id := range fake.Containers() {
+		remaining[id] = true
+	}
+	if !remaining[adoptedID] || !remaining[taskID] {
+		t.Errorf("expected adopted containers to keep running, got %v", fake.Containers())
+	}
+	if remaining[terminalID] || remaining[unknownID] {
+		t.Errorf("expected orphaned containers to be removed, got %v", fake.Containers())
+	}
+
+	networks, _ := fake.ListPodNetworks(ctx)
+	if len(networks) != 1 || networks[0].PodID != "pod-adopted" {
+		t.Errorf("expected only the adopted pod network to remain, got %v", networks)
+	}
+	if subnet, _ := agent.podSubnets.allocate("pod-new"); subnet != "10.244.8.16/28" {
+		t.Errorf("subnet of a new pod = %s, want 10.244.8.16/28 next to the adopted pod's", subnet)
+	}
+
+	pod, ok := agent.GetPod("pod-adopted")
+	if !ok {
+		t.Fatal("expected adopted pod to be tracked")
+	}
+	if pod.Containers[0].ContainerID != adoptedID {
+		t.Errorf("ContainerID = %s, want %s", pod.Containers[0].ContainerID, adoptedID)
+	}
+	if _, ok := agent.GetTask("task-adopted"); !ok {
+		t.Error("expected adopted task to be tracked")
+	}
+
+	// The adopted pod is supervised again: once its container exits, the result is reported
+	if err := fake.StopContainer(ctx, adoptedID); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := agent.GetPod("pod-adopted"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("adopted pod was not finalized after its container exited")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if last := recorder.last(); last == nil || last["status"] != string(types.PodFailed) {
+		t.Errorf("final status = %v, want %s", last, types.PodFailed)
+	}
+}
+
+func TestRecoverLeavesOtherNodesAlone(t *testing.T) {
+	// Two workers share one container runtime; this agent must not touch the other node's workloads
+	pods := []types.Pod{
+		{
+			PodID:      "pod-foreign",
+			NodeID:     "other-node",
+			Status:     types.PodRunning,
+			Containers: []types.Container{{Name: "app", Image: "app:1.0"}},
+		},
+		{PodID: "pod-starting", NodeID: "other-node", Status: types.PodScheduled},
+	}
+	tasks := []types.Task{
+		{TaskID: "task-foreign", NodeID: "other-node", Status: types.TaskRunning, Image: "app:1.0"},
+	}
+
+	agent, fake, recorder := newRecoveryAgent(t, pods, tasks)
+	ctx := context.Background()
+
+	foreign := []string{
+		startLabeledContainer(
+			t, fake, map[string]string{
+				runtime.LabelNodeID:        "other-node",
+				runtime.LabelPodID:         "pod-foreign",
+				runtime.LabelContainerName: "app",
+			},
+		),
+		startLabeledContainer(
+			t, fake, map[string]string{runtime.LabelNodeID: "other-node", runtime.LabelTaskID: "task-foreign"},
+		),
+		// Not yet known to the master as running, like a pod the other worker is still creating
+		startLabeledContainer(
+			t, fake, map[string]string{
+				runtime.LabelNodeID:        "other-node",
+				runtime.LabelPodID:         "pod-starting",
+				runtime.LabelContainerName: "app",
+			},
+		),
+	}
+	for _, podID := range []string{"pod-foreign", "pod-starting"} {
+		if _, err := fake.CreatePodNetwork(ctx, podID, runtime.PodNetworkOptions{NodeID: "other-node"}); err != nil {
+			t.Fatalf("CreatePodNetwork() error = %v", err)
+		}
+	}
+
+	result, err := agent.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	if result != (RecoveryResult{}) {
+		t.Errorf("Recover() = %+v, want nothing adopted, removed or lost", result)
+	}
+
+	remaining := make(map[string]bool)
+	for _, id := range fake.Containers() {
+		remaining[id] = true
+	}
+	for _, id := range foreign {
+		if !remaining[id] {
+			t.Errorf("container %s of the other node was removed", id)
+		}
+	}
+	if networks, _ := fake.ListPodNetworks(ctx); len(networks) != 2 {
+		t.Errorf("expected the other node's networks to remain, got %v", networks)
+	}
+	if _, ok := agent.GetPod("pod-foreign"); ok {
+		t.Error("pod of the other node was adopted")
+	}
+	if _, ok := agent.GetTask("task-foreign"); ok {
+		t.Error("task of the other node was adopted")
+	}
+	if last := recorder.last(); last != nil {
+		t.Errorf("unexpected status update %v", last)
+	}
+}
+
+func TestRecoverMasterUnavailable(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		),
+	)
+	defer server.Close()
+
+	fake := runtime.NewFake()
+	agent := NewAgentWithRuntime("test-node", server.URL, fake)
+	defer agent.Stop()
+
+	id := startLabeledContainer(
+		t, fake, map[string]string{runtime.LabelNodeID: "test-node", runtime.LabelPodID: "pod-1"},
+	)
+
+	if _, err := agent.Recover(context.Background()); err == nil {
+		t.Fatal("expected an error when the master is unavailable")
+	}
+	if ids := fake.Containers(); len(ids) != 1 || ids[0] != id {
+		t.Errorf("expected containers to be left alone, got %v", ids)
+	}
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/imagegc"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// applyResources maps resource requirements onto container runtime options.
+// CPU requests become CPU shares and limits become hard caps. Ephemeral storage
+// limits are only applied when the storage driver supports quotas.
+func (a *Agent) applyResources(ctx context.Context, opts *runtime.ContainerOptions, res types.ResourceRequirements) {
+	opts.CPUQuota = res.Limits.GetCPULimitForDocker()
+	opts.CPUShares = res.GetCPUSharesForDocker()
+	opts.MemoryLimit = res.Limits.GetMemoryLimitForDocker()
+	opts.PidsLimit = res.Limits.GetPidsLimitForDocker()
+
+	if storageLimit := res.Limits.GetStorageLimitForDocker(); storageLimit > 0 {
+		if a.supportsStorageQuota(ctx) {
+			opts.StorageLimit = storageLimit
+		} else {
+			log.Printf(
+				"ephemeral storage limit of %s for image %s not enforced: storage driver has no quota support",
+				types.FormatMemory(storageLimit), opts.Image,
+			)
+		}
+	}
+}
+
+// supportsStorageQuota checks once whether the runtime can limit writable layer size.
+func (a *Agent) supportsStorageQuota(ctx context.Context) bool {
+	a.storageQuotaOnce.Do(
+		func() {
+			if a.runtime == nil {
+				return
+			}
+			supported, err := a.runtime.SupportsStorageQuota(ctx)
+			if err != nil {
+				log.Printf("failed to detect storage quota support: %v", err)
+				return
+			}
+			a.storageQuota = supported
+		},
+	)
+	return a.storageQuota
+}
+
+// ephemeralStorageCapacity returns the size of the filesystem holding container layers, or 0 if unknown.
+func (a *Agent) ephemeralStorageCapacity() int64 {
+	if a.runtime == nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rootDir, err := a.runtime.RootDir(ctx)
+	if err != nil {
+		log.Printf("failed to determine ephemeral storage capacity: %v", err)
+		return 0
+	}
+
+	stats, err := imagegc.DiskUsage(rootDir)
+	if err != nil {
+		log.Printf("failed to determine ephemeral storage capacity: %v", err)
+		return 0
+	}
+	return int64(stats.CapacityBytes)
+}
+
+// pidCapacity returns the kernel's maximum PID count, or 0 if unknown.
+func pidCapacity() int64 {
+	data, err := os.ReadFile("/proc/sys/kernel/pid_max")
+	if err != nil {
+		return 0
+	}
+	pids, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pids
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// statusRecorder is a fake master that records pod and task status updates
+type statusRecorder struct {
+	mu      sync.Mutex
+	updates []map[string]interface{}
+}
+
+func (s *statusRecorder) handler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/status") {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.updates = append(s.updates, body)
+		s.mu.Unlock()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *statusRecorder) last() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.updates) == 0 {
+		return nil
+	}
+	return s.updates[len(s.updates)-1]
+}
+
+func newFakeRuntimeAgent(t *testing.T) (*Agent, *runtime.Fake, *statusRecorder) {
+	t.Helper()
+
+	recorder := &statusRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	t.Cleanup(server.Close)
+
+	fake := runtime.NewFake()
+	agent := NewAgentWithRuntime("test-node", server.URL, fake)
+	agent.pullBackoff = pullBackoff{attempts: 1, initial: time.Millisecond, max: time.Millisecond}
+	t.Cleanup(agent.Stop)
+
+	return agent, fake, recorder
+}
+
+func TestExecutePodWithFakeRuntime(t *testing.T) {
+	tests := []struct {
+		name       string
+		exitCode   int64
+		wantStatus types.PodStatus
+		wantErr    bool
+	}{
+		{name: "all containers succeed", exitCode: 0, wantStatus: types.PodSucceeded},
+		{name: "container fails", exitCode: 1, wantStatus: types.PodFailed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				agent, fake, recorder := newFakeRuntimeAgent(t)
+				fake.SetBehavior("app:1.0", runtime.FakeBehavior{ExitAfter: 20 * time.Millisecond, ExitCode: tt.exitCode})
+				fake.SetBehavior("sidecar:1.0", runtime.FakeBehavior{ExitAfter: 10 * time.Millisecond})
+
+				pod := &types.Pod{
+					PodID: "pod-1",
+					Name:  "web",
+					Containers: []types.Container{
+						{Name: "app", Image: "app:1.0"},
+						{Name: "sidecar", Image: "sidecar:1.0"},
+					},
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				err := agent.ExecutePod(ctx, pod, nil)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ExecutePod() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				last := recorder.last()
+				if last == nil || last["status"] != string(tt.wantStatus) {
+					t.Errorf("final status = %v, want %s", last, tt.wantStatus)
+				}
+				if remaining := fake.Containers(); len(remaining) != 0 {
+					t.Errorf("expected containers to be removed, got %v", remaining)
+				}
+				if _, ok := agent.GetPod("pod-1"); ok {
+					t.Error("expected pod to be untracked after completion")
+				}
+			},
+		)
+	}
+}
+
+func TestExecuteTaskWithFakeRuntime(t *testing.T) {
+	agent, fake, recorder := newFakeRuntimeAgent(t)
+	fake.SetBehavior("job:1.0", runtime.FakeBehavior{ExitAfter: 10 * time.Millisecond, ExitCode: 3})
+
+	task := &types.Task{TaskID: "task-1", Name: "job", Image: "job:1.0", Env: map[string]string{"MODE": "test"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := agent.ExecuteTask(ctx, task); err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+
+	last := recorder.last()
+	if last == nil || last["status"] != string(types.TaskFailed) {
+		t.Fatalf("final status = %v, want %s", last, types.TaskFailed)
+	}
+	if last["error"] != "container exited with code 3" {
+		t.Errorf("error = %v, want exit code message", last["error"])
+	}
+}
+
+func TestEvictPodWithFakeRuntime(t *testing.T) {
+	agent, fake, recorder := newFakeRuntimeAgent(t)
+
+	pod := &types.Pod{
+		PodID:      "pod-1",
+		Name:       "web",
+		Containers: []types.Container{{Name: "app", Image: "server:1.0"}},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- agent.ExecutePod(context.Background(), pod, nil)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if ids := fake.Containers(); len(ids) == 1 {
+			if state, _ := fake.GetContainerStatus(context.Background(), ids[0]); state == runtime.StateRunning {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pod did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := agent.evictPod(context.Background(), "pod-1", "The node was low on resource: memory."); err != nil {
+		t.Fatalf("evictPod() error = %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected ExecutePod to report the eviction")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pod was not stopped after eviction")
+	}
+
+	last := recorder.last()
+	if last == nil || last["status"] != string(types.PodFailed) || last["reason"] != types.ReasonEvicted {
+		t.Errorf("final status = %v, want failed/%s", last, types.ReasonEvicted)
+	}
+}
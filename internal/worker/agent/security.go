@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// securityOptions maps a container's effective security context onto container runtime options.
+// Seccomp profiles other than the built-in names are read from the worker's filesystem.
+func securityOptions(sc *types.SecurityContext) (runtime.SecurityOptions, error) {
+	var opts runtime.SecurityOptions
+	if sc == nil {
+		return opts, nil
+	}
+
+	if err := sc.Validate(); err != nil {
+		return opts, fmt.Errorf("invalid security context: %w", err)
+	}
+
+	if sc.RunAsUser != nil {
+		opts.User = strconv.FormatInt(*sc.RunAsUser, 10)
+		if sc.RunAsGroup != nil {
+			opts.User += ":" + strconv.FormatInt(*sc.RunAsGroup, 10)
+		}
+	}
+
+	opts.ReadOnlyRootfs = sc.ReadOnlyRootFilesystem != nil && *sc.ReadOnlyRootFilesystem
+	opts.Privileged = sc.IsPrivileged()
+	opts.NoNewPrivileges = sc.NoNewPrivileges != nil && *sc.NoNewPrivileges
+
+	if sc.Capabilities != nil {
+		opts.CapAdd = sc.Capabilities.Add
+		opts.CapDrop = sc.Capabilities.Drop
+	}
+
+	switch sc.SeccompProfile {
+	case "", types.ProfileRuntimeDefault:
+	case types.ProfileUnconfined:
+		opts.SeccompProfile = types.ProfileUnconfined
+	default:
+		profile, err := os.ReadFile(sc.SeccompProfile)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read seccomp profile: %w", err)
+		}
+		opts.SeccompProfile = string(profile)
+	}
+
+	switch sc.AppArmorProfile {
+	case "", types.ProfileRuntimeDefault:
+	default:
+		opts.AppArmorProfile = sc.AppArmorProfile
+	}
+
+	return opts, nil
+}
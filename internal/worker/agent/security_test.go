@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestSecurityOptions(t *testing.T) {
+	uid, gid := int64(1000), int64(2000)
+	yes := true
+
+	profilePath := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(profilePath, []byte(`{"defaultAction":"SCMP_ACT_ALLOW"}`), 0o600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sc      *types.SecurityContext
+		wantErr bool
+	}{
+		{
+			name: "nil context",
+			sc:   nil,
+		},
+		{
+			name: "user and group",
+			sc:   &types.SecurityContext{RunAsUser: &uid, RunAsGroup: &gid},
+		},
+		{
+			name: "seccomp profile file",
+			sc:   &types.SecurityContext{SeccompProfile: profilePath},
+		},
+		{
+			name:    "missing seccomp profile file",
+			sc:      &types.SecurityContext{SeccompProfile: "/does/not/exist.json"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid context",
+			sc:      &types.SecurityContext{RunAsGroup: &gid},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := securityOptions(tt.sc)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("securityOptions() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+
+	opts, err := securityOptions(
+		&types.SecurityContext{
+			RunAsUser:              &uid,
+			RunAsGroup:             &gid,
+			ReadOnlyRootFilesystem: &yes,
+			Privileged:             &yes,
+			NoNewPrivileges:        &yes,
+			Capabilities:           &types.Capabilities{Add: []string{"NET_ADMIN"}, Drop: []string{"ALL"}},
+			SeccompProfile:         types.ProfileUnconfined,
+			AppArmorProfile:        "podling-default",
+		},
+	)
+	if err != nil {
+		t.Fatalf("securityOptions() error = %v", err)
+	}
+	if opts.User != "1000:2000" {
+		t.Errorf("User = %q, want 1000:2000", opts.User)
+	}
+	if !opts.ReadOnlyRootfs || !opts.Privileged || !opts.NoNewPrivileges {
+		t.Errorf("expected boolean options to be set, got %+v", opts)
+	}
+	if len(opts.CapAdd) != 1 || len(opts.CapDrop) != 1 {
+		t.Errorf("unexpected capabilities: add=%v drop=%v", opts.CapAdd, opts.CapDrop)
+	}
+	if opts.SeccompProfile != types.ProfileUnconfined {
+		t.Errorf("SeccompProfile = %q, want unconfined", opts.SeccompProfile)
+	}
+	if opts.AppArmorProfile != "podling-default" {
+		t.Errorf("AppArmorProfile = %q, want podling-default", opts.AppArmorProfile)
+	}
+
+	opts, _ = securityOptions(&types.SecurityContext{SeccompProfile: profilePath})
+	if opts.SeccompProfile != `{"defaultAction":"SCMP_ACT_ALLOW"}` {
+		t.Errorf("expected seccomp profile content to be loaded, got %q", opts.SeccompProfile)
+	}
+}
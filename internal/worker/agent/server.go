@@ -29,9 +29,18 @@ func (s *Server) RegisterRoutes(e *echo.Echo) {
 	v1.POST("/tasks/:id/execute", s.ExecuteTask)
 	v1.GET("/tasks/:id/status", s.GetTaskStatus)
 	v1.GET("/tasks/:id/logs", s.GetTaskLogs)
+	v1.GET("/tasks/:id/logs/stream", s.StreamTaskLogs)
 
 	v1.POST("/pods/:id/execute", s.ExecutePod)
 	v1.GET("/pods/:id/status", s.GetPodStatus)
 	v1.GET("/pods/:id/logs", s.GetPodLogs)
+	v1.GET("/pods/:id/logs/stream", s.StreamPodLogs)
+	v1.GET("/pods/:id/stats", s.GetPodStats)
+	v1.POST("/pods/:id/exec", s.ExecPod)
+	v1.POST("/pods/:id/portforward", s.PortForwardPod)
+	v1.GET("/pods/:id/files", s.DownloadPodFiles)
+	v1.PUT("/pods/:id/files", s.UploadPodFiles)
 	v1.DELETE("/pods/:id", s.DeletePod)
+
+	v1.GET("/stats", s.GetNodeStats)
 }
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/proxy"
+)
+
+// serviceProxyCleanupTimeout bounds how long Stop waits for the service proxy to remove its rules
+const serviceProxyCleanupTimeout = 10 * time.Second
+
+// SetServiceProxy makes service ClusterIPs routable on this node through the proxy.
+// It must be called before Start.
+func (a *Agent) SetServiceProxy(p *proxy.Proxy) {
+	a.serviceProxy = p
+}
+
+// serviceProxyLoop keeps the proxy in sync with the master until the agent stops.
+func (a *Agent) serviceProxyLoop() {
+	defer close(a.serviceProxyDone)
+	a.serviceProxy.Run(a.stopChan)
+}
+
+// waitForServiceProxy waits for a running service proxy to clean up after the agent stops.
+func (a *Agent) waitForServiceProxy() {
+	if a.serviceProxyDone == nil {
+		return
+	}
+	select {
+	case <-a.serviceProxyDone:
+	case <-time.After(serviceProxyCleanupTimeout):
+		log.Printf("timed out waiting for the service proxy to clean up")
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/proxy"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+type staticServiceSource struct{}
+
+func (staticServiceSource) ListServices(context.Context) ([]types.Service, error) {
+	return []types.Service{
+		{ServiceID: "svc-1", Name: "web", ClusterIP: "10.96.0.10", Ports: []types.ServicePort{{Port: 80}}},
+	}, nil
+}
+
+func (staticServiceSource) ListEndpoints(context.Context) ([]types.Endpoints, error) {
+	return nil, nil
+}
+
+type countingProxier struct {
+	syncs  atomic.Int32
+	closed atomic.Bool
+}
+
+func (p *countingProxier) Sync([]proxy.Rule) error {
+	p.syncs.Add(1)
+	return nil
+}
+
+func (p *countingProxier) Close() error {
+	p.closed.Store(true)
+	return nil
+}
+
+func TestServiceProxyLifecycle(t *testing.T) {
+	agent := NewAgentWithRuntime("test-node", "http://127.0.0.1:0", runtime.NewFake())
+	proxier := &countingProxier{}
+	serviceProxy := proxy.New(staticServiceSource{}, proxier, time.Hour)
+	agent.SetServiceProxy(serviceProxy)
+
+	agent.Start(time.Hour)
+	deadline := time.Now().Add(time.Second)
+	for proxier.syncs.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if rules := serviceProxy.Rules(); len(rules) != 1 {
+		t.Fatalf("proxy rules = %+v, want the rule for 10.96.0.10:80", rules)
+	}
+
+	agent.Stop()
+	if !proxier.closed.Load() {
+		t.Error("Stop returned before the service proxy was closed")
+	}
+}
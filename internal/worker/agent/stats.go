@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// defaultStatsInterval is how often container stats are sampled
+const defaultStatsInterval = 10 * time.Second
+
+// SetStatsInterval changes how often container stats are sampled.
+// It must be called before Start.
+func (a *Agent) SetStatsInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("stats interval must be positive, got %v", interval)
+	}
+	a.statsInterval = interval
+	return nil
+}
+
+// statsLoop periodically samples the stats of all running containers so that CPU usage
+// can be reported as a rate over the last interval.
+func (a *Agent) statsLoop() {
+	interval := a.statsInterval
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := a.statsCollector.Collect(ctx, a.runningContainerIDs()); err != nil {
+				log.Printf("stats collection failed: %v", err)
+			}
+			cancel()
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// runningContainerIDs returns the containers of all tasks and pods running on this node.
+func (a *Agent) runningContainerIDs() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ids := make([]string, 0, len(a.runningTasks)+len(a.runningPods))
+	for _, task := range a.runningTasks {
+		if task.ContainerID != "" {
+			ids = append(ids, task.ContainerID)
+		}
+	}
+	for _, podExec := range a.runningPods {
+		podExec.mu.RLock()
+		for _, containerID := range podExec.containerIDs {
+			ids = append(ids, containerID)
+		}
+		podExec.mu.RUnlock()
+	}
+	return ids
+}
+
+// PodStats returns the resource usage of a pod running on this worker.
+// Containers not sampled yet are sampled on demand and report no CPU usage until the next sample.
+func (a *Agent) PodStats(ctx context.Context, podID string) (*types.PodStats, error) {
+	a.mu.RLock()
+	podExec, ok := a.runningPods[podID]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("pod %s not found or not running on this worker", podID)
+	}
+	if a.statsCollector == nil {
+		return nil, fmt.Errorf("stats collection is not enabled")
+	}
+
+	podExec.mu.RLock()
+	containerIDs := make(map[string]string, len(podExec.containerIDs))
+	for name, containerID := range podExec.containerIDs {
+		containerIDs[name] = containerID
+	}
+	podExec.mu.RUnlock()
+
+	names := make([]string, 0, len(containerIDs))
+	for name := range containerIDs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	podStats := &types.PodStats{PodID: podID, NodeID: a.nodeID, Containers: []types.ContainerStats{}}
+	for _, name := range names {
+		containerStats, ok := a.statsCollector.Stats(containerIDs[name])
+		if !ok {
+			var err error
+			if containerStats, err = a.statsCollector.Sample(ctx, containerIDs[name]); err != nil {
+				log.Printf("failed to sample stats of container %s in pod %s: %v", name, podID, err)
+				continue
+			}
+		}
+		containerStats.Name = name
+		podStats.AddContainer(containerStats)
+	}
+	return podStats, nil
+}
+
+// NodeStats returns the node's resource usage together with the usage of each running pod.
+func (a *Agent) NodeStats(ctx context.Context) *types.NodeStats {
+	nodeStats := &types.NodeStats{NodeID: a.nodeID, Pods: []types.PodStats{}, Timestamp: time.Now()}
+	if usage := a.nodeUsage(ctx); usage != nil {
+		nodeStats.Usage = *usage
+	}
+
+	a.mu.RLock()
+	podIDs := make([]string, 0, len(a.runningPods))
+	for podID := range a.runningPods {
+		podIDs = append(podIDs, podID)
+	}
+	a.mu.RUnlock()
+	sort.Strings(podIDs)
+
+	for _, podID := range podIDs {
+		podStats, err := a.PodStats(ctx, podID)
+		if err != nil {
+			continue
+		}
+		nodeStats.Pods = append(nodeStats.Pods, *podStats)
+	}
+	return nodeStats
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+func TestPodStats(t *testing.T) {
+	agent, fake, _ := newFakeRuntimeAgent(t)
+	ctx := context.Background()
+
+	fake.SetBehavior("app:1.0", runtime.FakeBehavior{CPUMillicores: 250, MemoryUsage: 64 * 1024 * 1024})
+	containerID := startLabeledContainer(t, fake, map[string]string{runtime.LabelPodID: "pod-1"})
+	agent.trackPodExecution(
+		"pod-1", &PodExecution{
+			pod:          &types.Pod{PodID: "pod-1"},
+			containerIDs: map[string]string{"app": containerID},
+		},
+	)
+
+	if _, err := agent.PodStats(ctx, "missing"); err == nil {
+		t.Error("expected an error for a pod not running on the worker")
+	}
+
+	// The first sample only establishes the CPU baseline
+	podStats, err := agent.PodStats(ctx, "pod-1")
+	if err != nil {
+		t.Fatalf("PodStats() error = %v", err)
+	}
+	if len(podStats.Containers) != 1 || podStats.Containers[0].Name != "app" {
+		t.Fatalf("containers = %+v, want app", podStats.Containers)
+	}
+	if podStats.Usage.Memory != 64*1024*1024 || podStats.Usage.CPU != 0 {
+		t.Errorf("usage = %+v, want 64Mi memory and no CPU yet", podStats.Usage)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if err := agent.statsCollector.Collect(ctx, agent.runningContainerIDs()); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	nodeStats := agent.NodeStats(ctx)
+	if len(nodeStats.Pods) != 1 || nodeStats.Pods[0].PodID != "pod-1" {
+		t.Fatalf("node pods = %+v, want pod-1", nodeStats.Pods)
+	}
+	if cpu := nodeStats.Pods[0].Usage.CPU; cpu < 240 || cpu > 260 {
+		t.Errorf("pod CPU = %dm, want about 250m", cpu)
+	}
+}
@@ -0,0 +1,264 @@
+package cri
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// defaultLogRoot is the directory sandboxes write container logs under
+const defaultLogRoot = "/var/log/podling"
+
+// cpuPeriod is the CFS period used to express CPU limits as a quota
+const cpuPeriod = 100000
+
+// The types below mirror the JSON form of the CRI v1 PodSandboxConfig and
+// ContainerConfig messages accepted by "crictl runp" and "crictl create".
+
+type metadata struct {
+	Name      string `json:"name"`
+	UID       string `json:"uid,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Attempt   uint32 `json:"attempt,omitempty"`
+}
+
+type portMapping struct {
+	Protocol      int `json:"protocol,omitempty"`
+	ContainerPort int `json:"container_port"`
+	HostPort      int `json:"host_port,omitempty"`
+}
+
+type sandboxConfig struct {
+	Metadata     metadata          `json:"metadata"`
+	LogDirectory string            `json:"log_directory"`
+	PortMappings []portMapping     `json:"port_mappings,omitempty"`
+	DNSConfig    *dnsConfig        `json:"dns_config,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Linux        struct{}          `json:"linux"`
+}
+
+type dnsConfig struct {
+	Servers  []string `json:"servers,omitempty"`
+	Searches []string `json:"searches,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type int64Value struct {
+	Value int64 `json:"value"`
+}
+
+type capability struct {
+	AddCapabilities  []string `json:"add_capabilities,omitempty"`
+	DropCapabilities []string `json:"drop_capabilities,omitempty"`
+}
+
+type securityProfile struct {
+	ProfileType  int    `json:"profile_type"`
+	LocalhostRef string `json:"localhost_ref,omitempty"`
+}
+
+type linuxSecurityContext struct {
+	Capabilities   *capability      `json:"capabilities,omitempty"`
+	Privileged     bool             `json:"privileged,omitempty"`
+	RunAsUser      *int64Value      `json:"run_as_user,omitempty"`
+	RunAsGroup     *int64Value      `json:"run_as_group,omitempty"`
+	ReadonlyRootfs bool             `json:"readonly_rootfs,omitempty"`
+	NoNewPrivs     bool             `json:"no_new_privs,omitempty"`
+	Seccomp        *securityProfile `json:"seccomp,omitempty"`
+	Apparmor       *securityProfile `json:"apparmor,omitempty"`
+}
+
+type linuxResources struct {
+	CPUPeriod          int64 `json:"cpu_period,omitempty"`
+	CPUQuota           int64 `json:"cpu_quota,omitempty"`
+	CPUShares          int64 `json:"cpu_shares,omitempty"`
+	MemoryLimitInBytes int64 `json:"memory_limit_in_bytes,omitempty"`
+}
+
+type linuxContainerConfig struct {
+	Resources       *linuxResources      `json:"resources,omitempty"`
+	SecurityContext linuxSecurityContext `json:"security_context"`
+}
+
+type imageSpec struct {
+	Image string `json:"image"`
+}
+
+type containerConfig struct {
+	Metadata metadata             `json:"metadata"`
+	Image    imageSpec            `json:"image"`
+	Envs     []keyValue           `json:"envs,omitempty"`
+	Labels   map[string]string    `json:"labels,omitempty"`
+	LogPath  string               `json:"log_path"`
+	Linux    linuxContainerConfig `json:"linux"`
+}
+
+// Security profile types from the CRI SecurityProfile message
+const (
+	profileRuntimeDefault = 0
+	profileUnconfined     = 1
+	profileLocalhost      = 2
+)
+
+// Protocols from the CRI Protocol enum
+const (
+	protocolTCP  = 0
+	protocolUDP  = 1
+	protocolSCTP = 2
+)
+
+// typeTaskSandbox is the LabelType value of sandboxes created for a single task container
+const typeTaskSandbox = "task-sandbox"
+
+// newSandboxConfig builds the sandbox config for a pod or a task's dedicated sandbox
+func newSandboxConfig(
+	logRoot, name string, ports []runtime.PortMapping, labels map[string]string, dns *runtime.DNSConfig,
+) sandboxConfig {
+	config := sandboxConfig{
+		Metadata:     metadata{Name: name, UID: name, Namespace: "podling"},
+		LogDirectory: filepath.Join(logRoot, name),
+		Labels:       map[string]string{runtime.LabelManaged: "true"},
+	}
+	if dns != nil {
+		config.DNSConfig = &dnsConfig{Servers: dns.Servers, Searches: dns.Searches, Options: dns.Options}
+	}
+	for k, v := range labels {
+		config.Labels[k] = v
+	}
+
+	for _, port := range ports {
+		config.PortMappings = append(
+			config.PortMappings, portMapping{
+				Protocol:      protocolNumber(port.Protocol),
+				ContainerPort: port.ContainerPort,
+				HostPort:      port.HostPort,
+			},
+		)
+	}
+	return config
+}
+
+// protocolNumber converts a protocol name to the CRI enum value
+func protocolNumber(protocol string) int {
+	switch strings.ToUpper(protocol) {
+	case "UDP":
+		return protocolUDP
+	case "SCTP":
+		return protocolSCTP
+	default:
+		return protocolTCP
+	}
+}
+
+// newContainerConfig translates container options to a CRI container config.
+// CRI has no per-container PID limit, so opts.PidsLimit is enforced only by the node's runtime defaults.
+func newContainerConfig(name string, opts runtime.ContainerOptions) (containerConfig, error) {
+	config := containerConfig{
+		Metadata: metadata{Name: name},
+		Image:    imageSpec{Image: opts.Image},
+		Labels:   map[string]string{runtime.LabelManaged: "true"},
+		LogPath:  name + ".log",
+	}
+	for k, v := range opts.Labels {
+		config.Labels[k] = v
+	}
+
+	for _, env := range opts.Env {
+		key, value, _ := strings.Cut(env, "=")
+		config.Envs = append(config.Envs, keyValue{Key: key, Value: value})
+	}
+
+	if opts.CPUQuota > 0 || opts.CPUShares > 0 || opts.MemoryLimit > 0 {
+		resources := &linuxResources{
+			CPUShares:          opts.CPUShares,
+			MemoryLimitInBytes: opts.MemoryLimit,
+		}
+		if opts.CPUQuota > 0 {
+			resources.CPUPeriod = cpuPeriod
+			resources.CPUQuota = int64(opts.CPUQuota * cpuPeriod)
+		}
+		config.Linux.Resources = resources
+	}
+
+	sc, err := newSecurityContext(opts.Security)
+	if err != nil {
+		return config, err
+	}
+	config.Linux.SecurityContext = sc
+	return config, nil
+}
+
+// newSecurityContext translates security options to a CRI Linux security context
+func newSecurityContext(security runtime.SecurityOptions) (linuxSecurityContext, error) {
+	seccomp, err := seccompProfile(security.SeccompProfile)
+	if err != nil {
+		return linuxSecurityContext{}, err
+	}
+
+	sc := linuxSecurityContext{
+		Privileged:     security.Privileged,
+		ReadonlyRootfs: security.ReadOnlyRootfs,
+		NoNewPrivs:     security.NoNewPrivileges,
+		Seccomp:        seccomp,
+		Apparmor:       apparmorProfile(security.AppArmorProfile),
+	}
+
+	if len(security.CapAdd) > 0 || len(security.CapDrop) > 0 {
+		sc.Capabilities = &capability{AddCapabilities: security.CapAdd, DropCapabilities: security.CapDrop}
+	}
+
+	if security.User != "" {
+		user, group, hasGroup := strings.Cut(security.User, ":")
+		if uid, err := strconv.ParseInt(user, 10, 64); err == nil {
+			sc.RunAsUser = &int64Value{Value: uid}
+		}
+		if hasGroup {
+			if gid, err := strconv.ParseInt(group, 10, 64); err == nil {
+				sc.RunAsGroup = &int64Value{Value: gid}
+			}
+		}
+	}
+
+	return sc, nil
+}
+
+// seccompProfile maps a seccomp setting to a CRI profile; custom profiles are written to a file
+// because CRI only accepts localhost profiles by path
+func seccompProfile(profile string) (*securityProfile, error) {
+	switch profile {
+	case "":
+		return &securityProfile{ProfileType: profileRuntimeDefault}, nil
+	case "unconfined":
+		return &securityProfile{ProfileType: profileUnconfined}, nil
+	}
+
+	sum := sha256.Sum256([]byte(profile))
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("podling-seccomp-%s.json", hex.EncodeToString(sum[:8])))
+	if err := os.WriteFile(path, []byte(profile), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write seccomp profile: %w", err)
+	}
+	return &securityProfile{ProfileType: profileLocalhost, LocalhostRef: path}, nil
+}
+
+// apparmorProfile maps an AppArmor setting to a CRI profile
+func apparmorProfile(profile string) *securityProfile {
+	switch profile {
+	case "":
+		return &securityProfile{ProfileType: profileRuntimeDefault}
+	case "unconfined":
+		return &securityProfile{ProfileType: profileUnconfined}
+	default:
+		return &securityProfile{ProfileType: profileLocalhost, LocalhostRef: profile}
+	}
+}
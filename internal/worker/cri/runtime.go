@@ -0,0 +1,867 @@
+// Package cri implements the worker container runtime on top of a CRI endpoint
+// such as containerd or CRI-O, driving it through the crictl command line tool.
+package cri
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// DefaultEndpoint is the containerd CRI socket
+const DefaultEndpoint = "unix:///run/containerd/containerd.sock"
+
+// defaultRootDir is used when the runtime does not report its data directory
+const defaultRootDir = "/var/lib/containerd"
+
+// waitPollInterval is how often WaitContainer checks whether a container exited
+const waitPollInterval = time.Second
+
+// Runtime runs containers through a CRI endpoint.
+// Tasks get a dedicated pod sandbox; pod containers share the sandbox created by CreatePodNetwork.
+type Runtime struct {
+	endpoint string
+	crictl   string
+	logRoot  string
+	run      func(ctx context.Context, args ...string) ([]byte, error)
+	runEnv   func(ctx context.Context, env []string, args ...string) ([]byte, error)
+	stream   func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) error
+
+	mu             sync.Mutex
+	sandboxes      map[string]string        // container ID -> dedicated sandbox ID
+	sandboxConfigs map[string]sandboxConfig // sandbox ID -> config it was started with
+}
+
+var _ runtime.Runtime = (*Runtime)(nil)
+
+// NewRuntime creates a CRI runtime for the given endpoint using the crictl binary at crictlPath
+func NewRuntime(endpoint, crictlPath string) (*Runtime, error) {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if crictlPath == "" {
+		crictlPath = "crictl"
+	}
+
+	path, err := exec.LookPath(crictlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find crictl: %w", err)
+	}
+
+	r := &Runtime{
+		endpoint:       endpoint,
+		crictl:         path,
+		logRoot:        defaultLogRoot,
+		sandboxes:      make(map[string]string),
+		sandboxConfigs: make(map[string]sandboxConfig),
+	}
+	r.run = r.runCrictl
+	r.runEnv = r.runCrictlEnv
+	r.stream = r.streamCrictl
+	return r, nil
+}
+
+// runCrictl executes crictl against the configured endpoint and returns its standard output
+func (r *Runtime) runCrictl(ctx context.Context, args ...string) ([]byte, error) {
+	return r.runCrictlEnv(ctx, nil, args...)
+}
+
+// runCrictlEnv executes crictl with env added to the worker's environment. Secrets are passed
+// this way rather than as arguments, which any local user can read from /proc.
+func (r *Runtime) runCrictlEnv(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	fullArgs := append([]string{"--runtime-endpoint", r.endpoint, "--image-endpoint", r.endpoint}, args...)
+	cmd := exec.CommandContext(ctx, r.crictl, fullArgs...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), &CommandError{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return stdout.Bytes(), nil
+}
+
+// streamCrictl executes crictl against the configured endpoint with the given standard streams attached
+func (r *Runtime) streamCrictl(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, args ...string) error {
+	fullArgs := append([]string{"--runtime-endpoint", r.endpoint, "--image-endpoint", r.endpoint}, args...)
+	cmd := exec.CommandContext(ctx, r.crictl, fullArgs...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return &CommandError{Args: args, Err: err}
+	}
+	return nil
+}
+
+// CommandError is returned when a crictl invocation fails
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	args := strings.Join(redactArgs(e.Args), " ")
+	if e.Stderr != "" {
+		return fmt.Sprintf("crictl %s: %s", args, e.Stderr)
+	}
+	return fmt.Sprintf("crictl %s: %v", args, e.Err)
+}
+
+// secretFlags are crictl flags whose values are credentials
+var secretFlags = []string{"--creds", "--auth"}
+
+// redactArgs returns args with the values of secret flags replaced, so that errors, which end up
+// in pod events and messages, never carry credentials
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = arg
+		for _, flag := range secretFlags {
+			if strings.HasPrefix(arg, flag+"=") {
+				redacted[i] = flag + "=REDACTED"
+			} else if i > 0 && args[i-1] == flag {
+				redacted[i] = "REDACTED"
+			}
+		}
+	}
+	return redacted
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode returns the process exit code carried by a command error, or -1
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Name returns the runtime name
+func (r *Runtime) Name() string {
+	return "cri"
+}
+
+// Ping checks that the CRI runtime answers a version request
+func (r *Runtime) Ping(ctx context.Context) error {
+	if _, err := r.run(ctx, "version"); err != nil {
+		return fmt.Errorf("failed to reach CRI runtime: %w", err)
+	}
+	return nil
+}
+
+// Close releases nothing; crictl connects per invocation
+func (r *Runtime) Close() error {
+	return nil
+}
+
+// PullImage pulls an image through the CRI image service
+func (r *Runtime) PullImage(ctx context.Context, imageName string) error {
+	return r.PullImageWithAuth(ctx, imageName, nil)
+}
+
+// PullImageWithAuth pulls an image, authenticating with the given credentials if set. crictl reads
+// the credentials from its CRICTL_CREDS environment variable, keeping them off its command line.
+func (r *Runtime) PullImageWithAuth(ctx context.Context, imageName string, auth *runtime.RegistryAuth) error {
+	var env []string
+	if auth != nil {
+		env = append(env, "CRICTL_CREDS="+auth.Username+":"+auth.Password)
+	}
+
+	if _, err := r.runEnv(ctx, env, "pull", imageName); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+	return nil
+}
+
+// ImageExists reports whether the image is present in the local image store
+func (r *Runtime) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	out, err := r.run(ctx, "images", "-q", imageName)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// criImageList mirrors the output of "crictl images -o json"
+type criImageList struct {
+	Images []struct {
+		ID       string   `json:"id"`
+		RepoTags []string `json:"repoTags"`
+		Size     string   `json:"size"`
+	} `json:"images"`
+}
+
+// ListImages returns all images in the local image store
+func (r *Runtime) ListImages(ctx context.Context) ([]runtime.ImageInfo, error) {
+	out, err := r.run(ctx, "images", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	var list criImageList
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode image list: %w", err)
+	}
+
+	images := make([]runtime.ImageInfo, 0, len(list.Images))
+	for _, img := range list.Images {
+		size, _ := strconv.ParseInt(img.Size, 10, 64)
+		images = append(images, runtime.ImageInfo{ID: img.ID, RepoTags: img.RepoTags, Size: size})
+	}
+
+	containers, err := r.listContainers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range images {
+		for _, c := range containers {
+			if c.ImageRef == images[i].ID {
+				images[i].Containers++
+			}
+		}
+	}
+	return images, nil
+}
+
+// RemoveImage removes an image from the local image store
+func (r *Runtime) RemoveImage(ctx context.Context, imageID string) error {
+	if _, err := r.run(ctx, "rmi", imageID); err != nil {
+		return fmt.Errorf("failed to remove image %s: %w", imageID, err)
+	}
+	return nil
+}
+
+// RootDir returns the runtime's data directory as reported by "crictl info"
+func (r *Runtime) RootDir(ctx context.Context) (string, error) {
+	out, err := r.run(ctx, "info", "-o", "json")
+	if err != nil {
+		return "", fmt.Errorf("failed to get runtime info: %w", err)
+	}
+
+	var info struct {
+		Config struct {
+			ContainerdRootDir string `json:"containerdRootDir"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(out, &info); err == nil && info.Config.ContainerdRootDir != "" {
+		return info.Config.ContainerdRootDir, nil
+	}
+	return defaultRootDir, nil
+}
+
+// SupportsStorageQuota reports false; CRI does not expose writable layer quotas
+func (r *Runtime) SupportsStorageQuota(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// CreateContainer creates a container from an image and environment
+func (r *Runtime) CreateContainer(ctx context.Context, imageName string, env []string) (string, error) {
+	return r.CreateContainerWithOptions(ctx, runtime.ContainerOptions{Image: imageName, Env: env})
+}
+
+// CreateContainerWithOptions creates a container in the sandbox given by opts.NetworkID,
+// or in a new dedicated sandbox when no network is set
+func (r *Runtime) CreateContainerWithOptions(ctx context.Context, opts runtime.ContainerOptions) (string, error) {
+	name := fmt.Sprintf("podling-%d", time.Now().UnixNano())
+
+	config, err := newContainerConfig(name, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+	containerConfig, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode container config: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "podling-cri-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	sandboxID := opts.NetworkID
+	dedicated := false
+	if sandboxID == "" {
+		labels := map[string]string{runtime.LabelType: typeTaskSandbox}
+		id, err := r.runSandbox(ctx, name, opts.Ports, labels, opts.DNS)
+		if err != nil {
+			return "", fmt.Errorf("failed to create container: %w", err)
+		}
+		sandboxID = id
+		dedicated = true
+	}
+
+	r.mu.Lock()
+	sandbox, ok := r.sandboxConfigs[sandboxID]
+	r.mu.Unlock()
+	if !ok {
+		sandbox = newSandboxConfig(r.logRoot, name, nil, nil, nil)
+	}
+	sandboxConfig, err := json.Marshal(sandbox)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sandbox config: %w", err)
+	}
+
+	containerPath := filepath.Join(dir, "container.json")
+	sandboxPath := filepath.Join(dir, "sandbox.json")
+	if err := os.WriteFile(containerPath, containerConfig, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write container config: %w", err)
+	}
+	if err := os.WriteFile(sandboxPath, sandboxConfig, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write sandbox config: %w", err)
+	}
+
+	out, err := r.run(ctx, "create", sandboxID, containerPath, sandboxPath)
+	if err != nil {
+		if dedicated {
+			_ = r.RemovePodNetwork(context.Background(), sandboxID)
+		}
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	containerID := strings.TrimSpace(string(out))
+	if dedicated {
+		r.mu.Lock()
+		r.sandboxes[containerID] = sandboxID
+		r.mu.Unlock()
+	}
+	return containerID, nil
+}
+
+// StartContainer starts a created container
+func (r *Runtime) StartContainer(ctx context.Context, containerID string) error {
+	if _, err := r.run(ctx, "start", containerID); err != nil {
+		return fmt.Errorf("failed to start container %s: %w", containerID, err)
+	}
+	return nil
+}
+
+// StopContainer stops a running container with a 10 second grace period
+func (r *Runtime) StopContainer(ctx context.Context, containerID string) error {
+	if _, err := r.run(ctx, "stop", "--timeout", "10", containerID); err != nil {
+		return fmt.Errorf("failed to stop container %s: %w", containerID, err)
+	}
+	return nil
+}
+
+// RemoveContainer removes a container and its dedicated sandbox, if any
+func (r *Runtime) RemoveContainer(ctx context.Context, containerID string) error {
+	r.mu.Lock()
+	sandboxID, ok := r.sandboxes[containerID]
+	delete(r.sandboxes, containerID)
+	r.mu.Unlock()
+
+	// Containers adopted after a restart are not in the map; find their sandbox by label
+	if !ok {
+		sandboxID, ok = r.dedicatedSandbox(ctx, containerID)
+	}
+
+	if _, err := r.run(ctx, "rm", "--force", containerID); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", containerID, err)
+	}
+
+	if ok {
+		return r.RemovePodNetwork(ctx, sandboxID)
+	}
+	return nil
+}
+
+// dedicatedSandbox returns the sandbox of a task container if it was created just for that container
+func (r *Runtime) dedicatedSandbox(ctx context.Context, containerID string) (string, bool) {
+	status, err := r.inspect(ctx, containerID)
+	if err != nil || status.Info.SandboxID == "" {
+		return "", false
+	}
+
+	sandboxes, err := r.listSandboxes(ctx, map[string]string{runtime.LabelType: typeTaskSandbox})
+	if err != nil {
+		return "", false
+	}
+	for _, sandbox := range sandboxes {
+		if sandbox.ID == status.Info.SandboxID {
+			return sandbox.ID, true
+		}
+	}
+	return "", false
+}
+
+// containerStatus mirrors the parts of "crictl inspect -o json" the runtime uses
+type containerStatus struct {
+	Status struct {
+		ID       string `json:"id"`
+		State    string `json:"state"`
+		ExitCode int64  `json:"exitCode"`
+	} `json:"status"`
+	Info struct {
+		SandboxID string `json:"sandboxID"`
+	} `json:"info"`
+}
+
+// inspect returns a container's CRI status
+func (r *Runtime) inspect(ctx context.Context, containerID string) (*containerStatus, error) {
+	out, err := r.run(ctx, "inspect", "-o", "json", containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	var status containerStatus
+	if err := json.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("failed to decode status of container %s: %w", containerID, err)
+	}
+	return &status, nil
+}
+
+// GetContainerStatus returns the container state as created, running or exited
+func (r *Runtime) GetContainerStatus(ctx context.Context, containerID string) (string, error) {
+	status, err := r.inspect(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	return stateName(status.Status.State), nil
+}
+
+// stateName converts a CRI container state to the runtime's state names
+func stateName(state string) string {
+	switch state {
+	case "CONTAINER_CREATED":
+		return runtime.StateCreated
+	case "CONTAINER_RUNNING":
+		return runtime.StateRunning
+	case "CONTAINER_EXITED":
+		return runtime.StateExited
+	default:
+		return strings.ToLower(strings.TrimPrefix(state, "CONTAINER_"))
+	}
+}
+
+// WaitContainer polls the container until it exits and returns its exit code.
+// CRI has no wait call, so the state is checked every second.
+func (r *Runtime) WaitContainer(ctx context.Context, containerID string) (int64, error) {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := r.inspect(ctx, containerID)
+		if err != nil {
+			return -1, fmt.Errorf("error waiting for container %s: %w", containerID, err)
+		}
+		if stateName(status.Status.State) == runtime.StateExited {
+			return status.Status.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, fmt.Errorf("error waiting for container %s: %w", containerID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetContainerLogs returns the last tail lines of a container's output
+func (r *Runtime) GetContainerLogs(ctx context.Context, containerID string, tail int) (string, error) {
+	out, err := r.run(ctx, "logs", "--tail", strconv.Itoa(tail), containerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs for container %s: %w", containerID, err)
+	}
+	return string(out), nil
+}
+
+// StreamContainerLogs runs crictl logs, which keeps the container's stdout and stderr apart
+func (r *Runtime) StreamContainerLogs(ctx context.Context, containerID string, opts runtime.LogOptions) error {
+	args := []string{"logs"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	if opts.Tail > 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since", opts.Since.Format(time.RFC3339Nano))
+	}
+	args = append(args, containerID)
+
+	if err := r.stream(ctx, nil, opts.Stdout, opts.Stderr, args...); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to get logs for container %s: %w", containerID, err)
+	}
+	return nil
+}
+
+// ExecInContainer runs a command synchronously in a running container
+func (r *Runtime) ExecInContainer(ctx context.Context, containerID string, cmd []string) (int, string, error) {
+	args := append([]string{"exec", containerID}, cmd...)
+	out, err := r.run(ctx, args...)
+	if err != nil {
+		if code := exitCode(err); code >= 0 {
+			return code, string(out), nil
+		}
+		return -1, string(out), fmt.Errorf("failed to exec in container %s: %w", containerID, err)
+	}
+	return 0, string(out), nil
+}
+
+// ExecStream runs a command in a running container with its streams attached.
+// crictl cannot resize the terminal, so size changes are ignored.
+func (r *Runtime) ExecStream(ctx context.Context, containerID string, opts runtime.ExecOptions) (int, error) {
+	args := []string{"exec"}
+	if opts.Stdin != nil {
+		args = append(args, "-i")
+	}
+	if opts.TTY {
+		args = append(args, "-t")
+	}
+	args = append(append(args, containerID), opts.Cmd...)
+
+	if err := r.stream(ctx, opts.Stdin, opts.Stdout, opts.Stderr, args...); err != nil {
+		if code := exitCode(err); code >= 0 {
+			return code, nil
+		}
+		return -1, fmt.Errorf("failed to exec in container %s: %w", containerID, err)
+	}
+	return 0, nil
+}
+
+// CopyFromContainer archives srcPath with tar inside the container, which must provide a tar binary
+func (r *Runtime) CopyFromContainer(ctx context.Context, containerID, srcPath string, w io.Writer) error {
+	dir, base := path.Split(path.Clean("/" + srcPath))
+	if base == "" {
+		base = "."
+	}
+
+	var stderr bytes.Buffer
+	if err := r.stream(ctx, nil, w, &stderr, "exec", containerID, "tar", "cf", "-", "-C", dir, base); err != nil {
+		return copyError(containerID, srcPath, err, stderr.String())
+	}
+	return nil
+}
+
+// CopyToContainer extracts a tar archive into dstDir with tar inside the container
+func (r *Runtime) CopyToContainer(ctx context.Context, containerID, dstDir string, content io.Reader) error {
+	var stderr bytes.Buffer
+	args := []string{"exec", "-i", containerID, "tar", "xf", "-", "-C", dstDir}
+	if err := r.stream(ctx, content, io.Discard, &stderr, args...); err != nil {
+		return copyError(containerID, dstDir, err, stderr.String())
+	}
+	return nil
+}
+
+// copyError reports a failed tar command, recognizing a missing path from tar's error output
+func copyError(containerID, filePath string, err error, stderr string) error {
+	if strings.Contains(stderr, "No such file or directory") || strings.Contains(stderr, "can't change directory") {
+		return fmt.Errorf("%s: %w", filePath, runtime.ErrPathNotFound)
+	}
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		return fmt.Errorf("failed to copy files in container %s: %w: %s", containerID, err, stderr)
+	}
+	return fmt.Errorf("failed to copy files in container %s: %w", containerID, err)
+}
+
+// CreatePodNetwork runs a pod sandbox whose network namespace the pod's containers share.
+// The sandbox holds the pod's resolv.conf, so opts.DNS applies to all of its containers.
+// Sandbox addresses are assigned by the runtime's CNI plugin, so opts.Subnet and opts.MTU are
+// ignored; point the plugin's IPAM range at the node's pod CIDR to use the cluster pod network.
+func (r *Runtime) CreatePodNetwork(ctx context.Context, podID string, opts runtime.PodNetworkOptions) (string, error) {
+	labels := map[string]string{runtime.LabelPodID: podID, runtime.LabelType: runtime.TypePodNetwork}
+	if opts.NodeID != "" {
+		labels[runtime.LabelNodeID] = opts.NodeID
+	}
+	sandboxID, err := r.runSandbox(ctx, "pod-"+podID, nil, labels, opts.DNS)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pod network pod-%s: %w", podID, err)
+	}
+	return sandboxID, nil
+}
+
+// runSandbox starts a pod sandbox and returns its ID
+func (r *Runtime) runSandbox(
+	ctx context.Context, name string, ports []runtime.PortMapping, labels map[string]string, dns *runtime.DNSConfig,
+) (string, error) {
+	sandbox := newSandboxConfig(r.logRoot, name, ports, labels, dns)
+	if err := os.MkdirAll(sandbox.LogDirectory, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	config, err := json.Marshal(sandbox)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sandbox config: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "podling-cri-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "sandbox.json")
+	if err := os.WriteFile(path, config, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write sandbox config: %w", err)
+	}
+
+	out, err := r.run(ctx, "runp", path)
+	if err != nil {
+		return "", err
+	}
+
+	sandboxID := strings.TrimSpace(string(out))
+	r.mu.Lock()
+	r.sandboxConfigs[sandboxID] = sandbox
+	r.mu.Unlock()
+	return sandboxID, nil
+}
+
+// RemovePodNetwork stops and removes a pod sandbox
+func (r *Runtime) RemovePodNetwork(ctx context.Context, networkID string) error {
+	if _, err := r.run(ctx, "stopp", networkID); err != nil {
+		return fmt.Errorf("failed to stop sandbox %s: %w", networkID, err)
+	}
+	if _, err := r.run(ctx, "rmp", networkID); err != nil {
+		return fmt.Errorf("failed to remove sandbox %s: %w", networkID, err)
+	}
+
+	r.mu.Lock()
+	delete(r.sandboxConfigs, networkID)
+	r.mu.Unlock()
+	return nil
+}
+
+// GetContainerIP returns the IP of the sandbox the container runs in
+func (r *Runtime) GetContainerIP(ctx context.Context, containerID string) (string, error) {
+	status, err := r.inspect(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+	return r.sandboxIP(ctx, status.Info.SandboxID)
+}
+
+// GetNetworkIP returns the IP of the pod sandbox; all containers in a sandbox share it
+func (r *Runtime) GetNetworkIP(ctx context.Context, _, networkID string) (string, error) {
+	return r.sandboxIP(ctx, networkID)
+}
+
+// sandboxIP returns a pod sandbox's primary IP address
+func (r *Runtime) sandboxIP(ctx context.Context, sandboxID string) (string, error) {
+	out, err := r.run(ctx, "inspectp", "-o", "json", sandboxID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect sandbox %s: %w", sandboxID, err)
+	}
+
+	var status struct {
+		Status struct {
+			Network struct {
+				IP string `json:"ip"`
+			} `json:"network"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &status); err != nil {
+		return "", fmt.Errorf("failed to decode status of sandbox %s: %w", sandboxID, err)
+	}
+	if status.Status.Network.IP == "" {
+		return "", fmt.Errorf("sandbox %s has no IP address", sandboxID)
+	}
+	return status.Status.Network.IP, nil
+}
+
+// containerStats mirrors the parts of "crictl stats -o json" the runtime uses
+type containerStats struct {
+	Stats []struct {
+		CPU struct {
+			Timestamp            string `json:"timestamp"`
+			UsageCoreNanoSeconds struct {
+				Value string `json:"value"`
+			} `json:"usageCoreNanoSeconds"`
+		} `json:"cpu"`
+		Memory struct {
+			WorkingSetBytes struct {
+				Value string `json:"value"`
+			} `json:"workingSetBytes"`
+		} `json:"memory"`
+		WritableLayer struct {
+			UsedBytes struct {
+				Value string `json:"value"`
+			} `json:"usedBytes"`
+		} `json:"writableLayer"`
+	} `json:"stats"`
+}
+
+// stats returns a container's CRI resource usage
+func (r *Runtime) stats(ctx context.Context, containerID string) (*containerStats, error) {
+	out, err := r.run(ctx, "stats", "-o", "json", "--id", containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats for container %s: %w", containerID, err)
+	}
+
+	var stats containerStats
+	if err := json.Unmarshal(out, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode stats for container %s: %w", containerID, err)
+	}
+	if len(stats.Stats) == 0 {
+		return nil, fmt.Errorf("no stats reported for container %s", containerID)
+	}
+	return &stats, nil
+}
+
+// ContainerMemoryUsage returns a container's memory working set in bytes
+func (r *Runtime) ContainerMemoryUsage(ctx context.Context, containerID string) (int64, error) {
+	stats, err := r.stats(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+	value, _ := strconv.ParseInt(stats.Stats[0].Memory.WorkingSetBytes.Value, 10, 64)
+	return value, nil
+}
+
+// ContainerStats returns a container's CPU and memory usage. CRI does not report per-container
+// network or block IO counters, so those are left at zero.
+func (r *Runtime) ContainerStats(ctx context.Context, containerID string) (runtime.ContainerStats, error) {
+	stats, err := r.stats(ctx, containerID)
+	if err != nil {
+		return runtime.ContainerStats{}, err
+	}
+	s := stats.Stats[0]
+
+	result := runtime.ContainerStats{Timestamp: time.Now()}
+	if ns, err := strconv.ParseInt(s.CPU.Timestamp, 10, 64); err == nil && ns > 0 {
+		result.Timestamp = time.Unix(0, ns)
+	}
+	result.CPUUsageNanos, _ = strconv.ParseUint(s.CPU.UsageCoreNanoSeconds.Value, 10, 64)
+	result.MemoryWorkingSet, _ = strconv.ParseInt(s.Memory.WorkingSetBytes.Value, 10, 64)
+	return result, nil
+}
+
+// ContainerDiskUsage returns the size of a container's writable layer in bytes
+func (r *Runtime) ContainerDiskUsage(ctx context.Context, containerID string) (int64, error) {
+	stats, err := r.stats(ctx, containerID)
+	if err != nil {
+		return 0, err
+	}
+	value, _ := strconv.ParseInt(stats.Stats[0].WritableLayer.UsedBytes.Value, 10, 64)
+	return value, nil
+}
+
+// criContainer mirrors one entry of "crictl ps -a -o json"
+type criContainer struct {
+	ID    string `json:"id"`
+	Image struct {
+		Image string `json:"image"`
+	} `json:"image"`
+	ImageRef  string            `json:"imageRef"`
+	State     string            `json:"state"`
+	CreatedAt string            `json:"createdAt"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// ListContainers returns all containers carrying every given label
+func (r *Runtime) ListContainers(ctx context.Context, labels map[string]string) ([]runtime.ContainerInfo, error) {
+	list, err := r.listContainers(ctx, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]runtime.ContainerInfo, 0, len(list))
+	for _, c := range list {
+		info := runtime.ContainerInfo{
+			ID:     c.ID,
+			Image:  c.Image.Image,
+			State:  stateName(c.State),
+			Labels: c.Labels,
+		}
+		if nanos, err := strconv.ParseInt(c.CreatedAt, 10, 64); err == nil {
+			info.Created = time.Unix(0, nanos)
+		}
+		containers = append(containers, info)
+	}
+	return containers, nil
+}
+
+// listContainers returns the containers known to the runtime that carry every given label
+func (r *Runtime) listContainers(ctx context.Context, labels map[string]string) ([]criContainer, error) {
+	out, err := r.run(ctx, append([]string{"ps", "-a", "-o", "json"}, labelArgs(labels)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var list struct {
+		Containers []criContainer `json:"containers"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode container list: %w", err)
+	}
+	return list.Containers, nil
+}
+
+// criSandbox mirrors one entry of "crictl pods -o json"
+type criSandbox struct {
+	ID     string            `json:"id"`
+	Labels map[string]string `json:"labels"`
+}
+
+// ListPodNetworks returns the pod sandboxes created by CreatePodNetwork
+func (r *Runtime) ListPodNetworks(ctx context.Context) ([]runtime.NetworkInfo, error) {
+	sandboxes, err := r.listSandboxes(ctx, map[string]string{runtime.LabelType: runtime.TypePodNetwork})
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make([]runtime.NetworkInfo, 0, len(sandboxes))
+	for _, sandbox := range sandboxes {
+		networks = append(
+			networks, runtime.NetworkInfo{
+				ID:     sandbox.ID,
+				PodID:  sandbox.Labels[runtime.LabelPodID],
+				NodeID: sandbox.Labels[runtime.LabelNodeID],
+			},
+		)
+	}
+	return networks, nil
+}
+
+// listSandboxes returns the pod sandboxes that carry every given label
+func (r *Runtime) listSandboxes(ctx context.Context, labels map[string]string) ([]criSandbox, error) {
+	out, err := r.run(ctx, append([]string{"pods", "-o", "json"}, labelArgs(labels)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxes: %w", err)
+	}
+
+	var list struct {
+		Items []criSandbox `json:"items"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode sandbox list: %w", err)
+	}
+	return list.Items, nil
+}
+
+// labelArgs converts a label selector to crictl --label flags
+func labelArgs(labels map[string]string) []string {
+	args := make([]string, 0, 2*len(labels))
+	for k, v := range labels {
+		args = append(args, "--label", k+"="+v)
+	}
+	return args
+}
@@ -0,0 +1,359 @@
+package cri
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// newTestRuntime returns a runtime whose crictl invocations are answered by respond
+func newTestRuntime(t *testing.T, respond func(args []string) (string, error)) (*Runtime, *[][]string) {
+	t.Helper()
+
+	var calls [][]string
+	r := &Runtime{
+		endpoint:       DefaultEndpoint,
+		logRoot:        t.TempDir(),
+		sandboxes:      make(map[string]string),
+		sandboxConfigs: make(map[string]sandboxConfig),
+	}
+	r.run = func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		out, err := respond(args)
+		return []byte(out), err
+	}
+	r.runEnv = func(ctx context.Context, _ []string, args ...string) ([]byte, error) {
+		return r.run(ctx, args...)
+	}
+	r.stream = func(_ context.Context, _ io.Reader, stdout, _ io.Writer, args ...string) error {
+		calls = append(calls, args)
+		out, err := respond(args)
+		_, _ = io.WriteString(stdout, out)
+		return err
+	}
+	return r, &calls
+}
+
+func TestCreateContainerWithDedicatedSandbox(t *testing.T) {
+	var containerConfigJSON []byte
+	r, calls := newTestRuntime(
+		t, func(args []string) (string, error) {
+			switch args[0] {
+			case "runp":
+				return "sandbox-1\n", nil
+			case "create":
+				containerConfigJSON, _ = os.ReadFile(args[2])
+				return "container-1\n", nil
+			}
+			return "", nil
+		},
+	)
+
+	opts := runtime.ContainerOptions{
+		Image:       "nginx:1.25",
+		Env:         []string{"MODE=prod", "EMPTY="},
+		CPUQuota:    0.5,
+		CPUShares:   512,
+		MemoryLimit: 128 * 1024 * 1024,
+		Ports:       []runtime.PortMapping{{ContainerPort: 80, HostPort: 8080}},
+		Security:    runtime.SecurityOptions{User: "1000:2000", ReadOnlyRootfs: true, CapDrop: []string{"ALL"}},
+	}
+
+	id, err := r.CreateContainerWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("CreateContainerWithOptions() error = %v", err)
+	}
+	if id != "container-1" {
+		t.Errorf("container ID = %q, want container-1", id)
+	}
+	if (*calls)[1][1] != "sandbox-1" {
+		t.Errorf("expected container to be created in sandbox-1, got %v", (*calls)[1])
+	}
+
+	var config containerConfig
+	if err := json.Unmarshal(containerConfigJSON, &config); err != nil {
+		t.Fatalf("failed to decode container config: %v", err)
+	}
+	if config.Image.Image != "nginx:1.25" || len(config.Envs) != 2 || config.Envs[1].Key != "EMPTY" {
+		t.Errorf("unexpected container config: %+v", config)
+	}
+	if res := config.Linux.Resources; res == nil || res.CPUQuota != 50000 || res.CPUShares != 512 {
+		t.Errorf("unexpected resources: %+v", config.Linux.Resources)
+	}
+	sc := config.Linux.SecurityContext
+	if sc.RunAsUser == nil || sc.RunAsUser.Value != 1000 || sc.RunAsGroup == nil || sc.RunAsGroup.Value != 2000 {
+		t.Errorf("unexpected user mapping: %+v", sc)
+	}
+	if !sc.ReadonlyRootfs || sc.Capabilities == nil || sc.Capabilities.DropCapabilities[0] != "ALL" {
+		t.Errorf("unexpected security context: %+v", sc)
+	}
+
+	// Removing the container also tears down its dedicated sandbox
+	if err := r.RemoveContainer(context.Background(), id); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+	var verbs []string
+	for _, call := range *calls {
+		verbs = append(verbs, call[0])
+	}
+	if got := strings.Join(verbs, ","); got != "runp,create,rm,stopp,rmp" {
+		t.Errorf("crictl calls = %s", got)
+	}
+}
+
+func TestCreatePodNetworkDNS(t *testing.T) {
+	var sandboxJSON []byte
+	r, _ := newTestRuntime(
+		t, func(args []string) (string, error) {
+			if args[0] == "runp" {
+				sandboxJSON, _ = os.ReadFile(args[1])
+				return "sandbox-1\n", nil
+			}
+			return "", nil
+		},
+	)
+
+	dns := &runtime.DNSConfig{
+		Servers:  []string{"10.0.0.1"},
+		Searches: []string{"default.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+		Options:  []string{"ndots:5"},
+	}
+	if _, err := r.CreatePodNetwork(context.Background(), "pod-1", runtime.PodNetworkOptions{DNS: dns}); err != nil {
+		t.Fatalf("CreatePodNetwork() error = %v", err)
+	}
+
+	var config sandboxConfig
+	if err := json.Unmarshal(sandboxJSON, &config); err != nil {
+		t.Fatalf("failed to decode sandbox config: %v", err)
+	}
+	got := config.DNSConfig
+	if got == nil || got.Servers[0] != "10.0.0.1" || len(got.Searches) != 3 || got.Options[0] != "ndots:5" {
+		t.Errorf("dns_config = %+v, want the pod's resolver settings", got)
+	}
+}
+
+func TestWaitContainer(t *testing.T) {
+	inspections := 0
+	r, _ := newTestRuntime(
+		t, func(args []string) (string, error) {
+			if args[0] != "inspect" {
+				return "", nil
+			}
+			inspections++
+			if inspections < 2 {
+				return `{"status":{"state":"CONTAINER_RUNNING"}}`, nil
+			}
+			return `{"status":{"state":"CONTAINER_EXITED","exitCode":7}}`, nil
+		},
+	)
+
+	code, err := r.WaitContainer(context.Background(), "container-1")
+	if err != nil {
+		t.Fatalf("WaitContainer() error = %v", err)
+	}
+	if code != 7 {
+		t.Errorf("exit code = %d, want 7", code)
+	}
+}
+
+func TestStateName(t *testing.T) {
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{"CONTAINER_CREATED", runtime.StateCreated},
+		{"CONTAINER_RUNNING", runtime.StateRunning},
+		{"CONTAINER_EXITED", runtime.StateExited},
+		{"CONTAINER_UNKNOWN", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := stateName(tt.state); got != tt.want {
+			t.Errorf("stateName(%s) = %s, want %s", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestContainerMemoryUsage(t *testing.T) {
+	r, _ := newTestRuntime(
+		t, func(args []string) (string, error) {
+			return `{"stats":[{"memory":{"workingSetBytes":{"value":"1048576"}},` +
+				`"writableLayer":{"usedBytes":{"value":"4096"}}}]}`, nil
+		},
+	)
+
+	memory, err := r.ContainerMemoryUsage(context.Background(), "container-1")
+	if err != nil || memory != 1048576 {
+		t.Errorf("ContainerMemoryUsage() = %d, %v", memory, err)
+	}
+	disk, err := r.ContainerDiskUsage(context.Background(), "container-1")
+	if err != nil || disk != 4096 {
+		t.Errorf("ContainerDiskUsage() = %d, %v", disk, err)
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+	r, _ := newTestRuntime(
+		t, func(args []string) (string, error) {
+			return `{"stats":[{"cpu":{"timestamp":"1700000000000000000",` +
+				`"usageCoreNanoSeconds":{"value":"2500000000"}},` +
+				`"memory":{"workingSetBytes":{"value":"1048576"}}}]}`, nil
+		},
+	)
+
+	stats, err := r.ContainerStats(context.Background(), "container-1")
+	if err != nil {
+		t.Fatalf("ContainerStats() error = %v", err)
+	}
+	if stats.CPUUsageNanos != 2500000000 || stats.MemoryWorkingSet != 1048576 {
+		t.Errorf("ContainerStats() = %+v", stats)
+	}
+	if !stats.Timestamp.Equal(time.Unix(0, 1700000000000000000)) {
+		t.Errorf("Timestamp = %v", stats.Timestamp)
+	}
+}
+
+func TestExecStream(t *testing.T) {
+	r, calls := newTestRuntime(
+		t, func(args []string) (string, error) {
+			return "hello\n", nil
+		},
+	)
+
+	var stdout strings.Builder
+	code, err := r.ExecStream(
+		context.Background(), "container-1", runtime.ExecOptions{
+			Cmd:    []string{"sh", "-c", "echo hello"},
+			TTY:    true,
+			Stdin:  strings.NewReader(""),
+			Stdout: &stdout,
+		},
+	)
+	if err != nil || code != 0 {
+		t.Fatalf("ExecStream() = %d, %v", code, err)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("stdout = %q, want hello", stdout.String())
+	}
+
+	want := "exec -i -t container-1 sh -c echo hello"
+	if got := strings.Join((*calls)[0], " "); got != want {
+		t.Errorf("crictl args = %q, want %q", got, want)
+	}
+}
+
+func TestStreamContainerLogs(t *testing.T) {
+	r, calls := newTestRuntime(
+		t, func(args []string) (string, error) {
+			return "line 1\n", nil
+		},
+	)
+
+	var stdout strings.Builder
+	since := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := r.StreamContainerLogs(
+		context.Background(), "container-1", runtime.LogOptions{
+			Follow:     true,
+			Timestamps: true,
+			Tail:       10,
+			Since:      since,
+			Stdout:     &stdout,
+		},
+	)
+	if err != nil {
+		t.Fatalf("StreamContainerLogs() error = %v", err)
+	}
+	if stdout.String() != "line 1\n" {
+		t.Errorf("stdout = %q, want line 1", stdout.String())
+	}
+
+	want := "logs --follow --timestamps --tail 10 --since 2025-01-02T03:04:05Z container-1"
+	if got := strings.Join((*calls)[0], " "); got != want {
+		t.Errorf("crictl args = %q, want %q", got, want)
+	}
+}
+
+func TestCopyFiles(t *testing.T) {
+	r, calls := newTestRuntime(
+		t, func(args []string) (string, error) {
+			return "archive", nil
+		},
+	)
+
+	var archive strings.Builder
+	if err := r.CopyFromContainer(context.Background(), "container-1", "/var/log/app", &archive); err != nil {
+		t.Fatalf("CopyFromContainer() error = %v", err)
+	}
+	if archive.String() != "archive" {
+		t.Errorf("archive = %q", archive.String())
+	}
+	if err := r.CopyToContainer(context.Background(), "container-1", "/etc/app", strings.NewReader("")); err != nil {
+		t.Fatalf("CopyToContainer() error = %v", err)
+	}
+
+	want := []string{
+		"exec container-1 tar cf - -C /var/log/ app",
+		"exec -i container-1 tar xf - -C /etc/app",
+	}
+	for i, w := range want {
+		if got := strings.Join((*calls)[i], " "); got != w {
+			t.Errorf("crictl args = %q, want %q", got, w)
+		}
+	}
+}
+
+func TestPullImageWithAuthKeepsPasswordSecret(t *testing.T) {
+	// The fake crictl records its arguments and credentials, then fails like a rejected pull
+	dir := t.TempDir()
+	crictl := filepath.Join(dir, "crictl")
+	script := `#!/bin/sh
+echo "$@" > "` + dir + `/args"
+echo "$CRICTL_CREDS" > "` + dir + `/creds"
+echo "unauthorized: authentication required" >&2
+exit 1
+`
+	if err := os.WriteFile(crictl, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Runtime{endpoint: DefaultEndpoint, crictl: crictl}
+	r.run = r.runCrictl
+	r.runEnv = r.runCrictlEnv
+
+	auth := &runtime.RegistryAuth{Username: "deploy", Password: "s3cret-token"}
+	err := r.PullImageWithAuth(context.Background(), "registry.example.com/app:1.0", auth)
+	if err == nil {
+		t.Fatal("expected the pull to fail")
+	}
+	if strings.Contains(err.Error(), "s3cret-token") {
+		t.Errorf("error %q contains the password", err)
+	}
+	if !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("error %q does not carry crictl's message", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if strings.Contains(string(args), "s3cret-token") {
+		t.Errorf("crictl arguments %q contain the password", args)
+	}
+	if creds, _ := os.ReadFile(filepath.Join(dir, "creds")); strings.TrimSpace(string(creds)) != "deploy:s3cret-token" {
+		t.Errorf("CRICTL_CREDS = %q, want the credentials", creds)
+	}
+}
+
+func TestCommandErrorRedactsCredentials(t *testing.T) {
+	err := &CommandError{
+		Args:   []string{"pull", "--creds", "user:pass", "--auth=dXNlcjpwYXNz", "app:1.0"},
+		Stderr: "denied",
+	}
+	if got := err.Error(); strings.Contains(got, "user:pass") || strings.Contains(got, "dXNlcjpwYXNz") {
+		t.Errorf("Error() = %q, want credentials redacted", got)
+	}
+}
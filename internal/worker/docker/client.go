@@ -3,13 +3,22 @@ package docker
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/danpasecinic/podling/internal/worker/runtime"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 )
 
@@ -18,12 +27,16 @@ type Client struct {
 	cli *client.Client
 }
 
-// PortMapping represents a mapping between container and host ports.
-type PortMapping struct {
-	ContainerPort int
-	HostPort      int
-	Protocol      string
-}
+// Type aliases for the runtime-neutral types the Docker client accepts and returns.
+type (
+	PortMapping      = runtime.PortMapping
+	RegistryAuth     = runtime.RegistryAuth
+	ImageInfo        = runtime.ImageInfo
+	SecurityOptions  = runtime.SecurityOptions
+	ContainerOptions = runtime.ContainerOptions
+)
+
+var _ runtime.Runtime = (*Client)(nil)
 
 // NewClient creates a new Docker client.
 func NewClient() (*Client, error) {
@@ -34,6 +47,19 @@ func NewClient() (*Client, error) {
 	return &Client{cli: cli}, nil
 }
 
+// Name returns the runtime name.
+func (c *Client) Name() string {
+	return "docker"
+}
+
+// Ping checks that the Docker daemon is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	if _, err := c.cli.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping docker daemon: %w", err)
+	}
+	return nil
+}
+
 // Close closes the Docker client connection.
 func (c *Client) Close() error {
 	if c.cli != nil {
@@ -44,21 +70,231 @@ func (c *Client) Close() error {
 
 // PullImage pulls a Docker image from a registry.
 func (c *Client) PullImage(ctx context.Context, imageName string) error {
-	reader, err := c.cli.ImagePull(ctx, imageName, image.PullOptions{})
+	return c.PullImageWithAuth(ctx, imageName, nil)
+}
+
+// PullImageWithAuth pulls a Docker image using the given registry credentials.
+// A nil auth pulls anonymously.
+func (c *Client) PullImageWithAuth(ctx context.Context, imageName string, auth *RegistryAuth) error {
+	opts := image.PullOptions{}
+	if auth != nil {
+		encoded, err := registry.EncodeAuthConfig(
+			registry.AuthConfig{
+				Username:      auth.Username,
+				Password:      auth.Password,
+				ServerAddress: auth.ServerAddress,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to encode registry auth: %w", err)
+		}
+		opts.RegistryAuth = encoded
+	}
+
+	reader, err := c.cli.ImagePull(ctx, imageName, opts)
 	if err != nil {
 		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
 	}
 	defer func() { _ = reader.Close() }()
 
-	// Read all output to ensure pull completes
-	_, err = io.Copy(io.Discard, reader)
+	// Errors such as "unauthorized" or "manifest unknown" are reported
+	// inside the progress stream rather than as an API error
+	if err := jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+
+	return nil
+}
+
+// ImageExists reports whether an image is present in the local image store.
+func (c *Client) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	_, err := c.cli.ImageInspect(ctx, imageName)
 	if err != nil {
-		return fmt.Errorf("failed to read image pull output: %w", err)
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return true, nil
+}
+
+// ListImages returns all top-level images in the local image store.
+func (c *Client) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	summaries, err := c.cli.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	images := make([]ImageInfo, 0, len(summaries))
+	for _, summary := range summaries {
+		images = append(
+			images, ImageInfo{
+				ID:         summary.ID,
+				RepoTags:   summary.RepoTags,
+				Size:       summary.Size,
+				Created:    time.Unix(summary.Created, 0),
+				Containers: summary.Containers,
+			},
+		)
 	}
+	return images, nil
+}
 
+// RemoveImage removes an image and its untagged parents.
+// Images still used by a container are not removed.
+func (c *Client) RemoveImage(ctx context.Context, imageID string) error {
+	_, err := c.cli.ImageRemove(ctx, imageID, image.RemoveOptions{PruneChildren: true})
+	if err != nil {
+		return fmt.Errorf("failed to remove image %s: %w", imageID, err)
+	}
 	return nil
 }
 
+// RootDir returns the Docker daemon's data directory where images are stored.
+func (c *Client) RootDir(ctx context.Context) (string, error) {
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get docker info: %w", err)
+	}
+	return info.DockerRootDir, nil
+}
+
+// SupportsStorageQuota reports whether the daemon's storage driver can limit
+// a container's writable layer size (overlay2 on xfs with project quotas, btrfs or zfs).
+func (c *Client) SupportsStorageQuota(ctx context.Context) (bool, error) {
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get docker info: %w", err)
+	}
+
+	switch info.Driver {
+	case "btrfs", "zfs", "devicemapper", "windowsfilter":
+		return true, nil
+	case "overlay2":
+		for _, status := range info.DriverStatus {
+			if status[0] == "Backing Filesystem" && status[1] == "xfs" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// CreateContainerWithOptions creates a container from the given options.
+func (c *Client) CreateContainerWithOptions(ctx context.Context, opts ContainerOptions) (string, error) {
+	exposedPorts, portBindings := buildPortBindings(opts.Ports)
+
+	labels := map[string]string{
+		runtime.LabelManaged: "true",
+	}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+
+	config := &container.Config{
+		Image:        opts.Image,
+		Env:          opts.Env,
+		ExposedPorts: exposedPorts,
+		User:         opts.Security.User,
+		Labels:       labels,
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings:   portBindings,
+		ReadonlyRootfs: opts.Security.ReadOnlyRootfs,
+		Privileged:     opts.Security.Privileged,
+		CapAdd:         opts.Security.CapAdd,
+		CapDrop:        opts.Security.CapDrop,
+		SecurityOpt:    buildSecurityOpts(opts.Security),
+	}
+
+	if opts.CPUQuota > 0 {
+		hostConfig.NanoCPUs = int64(opts.CPUQuota * 1e9)
+	}
+
+	if opts.CPUShares > 0 {
+		hostConfig.CPUShares = opts.CPUShares
+	}
+
+	if opts.MemoryLimit > 0 {
+		hostConfig.Memory = opts.MemoryLimit
+	}
+
+	if opts.PidsLimit > 0 {
+		hostConfig.PidsLimit = &opts.PidsLimit
+	}
+
+	if opts.StorageLimit > 0 {
+		hostConfig.StorageOpt = map[string]string{"size": fmt.Sprintf("%d", opts.StorageLimit)}
+	}
+
+	if opts.DNS != nil {
+		hostConfig.DNS = opts.DNS.Servers
+		hostConfig.DNSSearch = opts.DNS.Searches
+		hostConfig.DNSOptions = opts.DNS.Options
+	}
+
+	var networkingConfig *network.NetworkingConfig
+	if opts.NetworkID != "" {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				opts.NetworkID: {},
+			},
+		}
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	return resp.ID, nil
+}
+
+// buildPortBindings converts port mappings into Docker's exposed ports and host bindings.
+func buildPortBindings(ports []PortMapping) (nat.PortSet, nat.PortMap) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+
+	exposedPorts := nat.PortSet{}
+	portBindings := nat.PortMap{}
+
+	for _, portMapping := range ports {
+		protocol := portMapping.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+
+		containerPort := nat.Port(fmt.Sprintf("%d/%s", portMapping.ContainerPort, protocol))
+		exposedPorts[containerPort] = struct{}{}
+
+		portBindings[containerPort] = []nat.PortBinding{
+			{
+				HostIP:   "0.0.0.0",
+				HostPort: fmt.Sprintf("%d", portMapping.HostPort),
+			},
+		}
+	}
+
+	return exposedPorts, portBindings
+}
+
+// buildSecurityOpts converts security options into Docker security-opt values.
+func buildSecurityOpts(security SecurityOptions) []string {
+	var opts []string
+	if security.NoNewPrivileges {
+		opts = append(opts, "no-new-privileges:true")
+	}
+	if security.SeccompProfile != "" {
+		opts = append(opts, "seccomp="+security.SeccompProfile)
+	}
+	if security.AppArmorProfile != "" {
+		opts = append(opts, "apparmor="+security.AppArmorProfile)
+	}
+	return opts
+}
+
 // CreateContainer creates a new container with the given configuration.
 func (c *Client) CreateContainer(ctx context.Context, imageName string, env []string) (string, error) {
 	config := &container.Config{
@@ -238,6 +474,90 @@ func (c *Client) GetContainerLogs(ctx context.Context, containerID string, tail
 	return buf.String(), nil
 }
 
+// StreamContainerLogs copies a container's demultiplexed output to the option's writers.
+func (c *Client) StreamContainerLogs(ctx context.Context, containerID string, opts runtime.LogOptions) error {
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Timestamps: opts.Timestamps,
+		Tail:       "all",
+	}
+	if opts.Tail > 0 {
+		options.Tail = strconv.Itoa(opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		options.Since = opts.Since.Format(time.RFC3339Nano)
+	}
+
+	reader, err := c.cli.ContainerLogs(ctx, containerID, options)
+	if err != nil {
+		return fmt.Errorf("failed to get logs for container %s: %w", containerID, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := stdcopy.StdCopy(opts.Stdout, opts.Stderr, reader); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	return nil
+}
+
+// CopyFromContainer writes a tar archive of srcPath in the container to w using Docker's archive API.
+func (c *Client) CopyFromContainer(ctx context.Context, containerID, srcPath string, w io.Writer) error {
+	reader, _, err := c.cli.CopyFromContainer(ctx, containerID, srcPath)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return fmt.Errorf("%s: %w", srcPath, runtime.ErrPathNotFound)
+		}
+		return fmt.Errorf("failed to copy from container %s: %w", containerID, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := io.Copy(w, reader); err != nil {
+		return fmt.Errorf("failed to copy archive: %w", err)
+	}
+	return nil
+}
+
+// CopyToContainer extracts a tar archive into dstDir in the container using Docker's archive API.
+func (c *Client) CopyToContainer(ctx context.Context, containerID, dstDir string, content io.Reader) error {
+	err := c.cli.CopyToContainer(ctx, containerID, dstDir, content, container.CopyToContainerOptions{})
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return fmt.Errorf("%s: %w", dstDir, runtime.ErrPathNotFound)
+		}
+		return fmt.Errorf("failed to copy to container %s: %w", containerID, err)
+	}
+	return nil
+}
+
+// ListContainers returns all containers carrying every given label.
+func (c *Client) ListContainers(ctx context.Context, labels map[string]string) ([]runtime.ContainerInfo, error) {
+	args := filters.NewArgs()
+	for k, v := range labels {
+		args.Add("label", k+"="+v)
+	}
+
+	summaries, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	containers := make([]runtime.ContainerInfo, 0, len(summaries))
+	for _, summary := range summaries {
+		containers = append(
+			containers, runtime.ContainerInfo{
+				ID:      summary.ID,
+				Image:   summary.Image,
+				State:   summary.State,
+				Labels:  summary.Labels,
+				Created: time.Unix(summary.Created, 0),
+			},
+		)
+	}
+	return containers, nil
+}
+
 // ExecInContainer executes a command in a running container
 func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []string) (int, string, error) {
 	execConfig := container.ExecOptions{
@@ -271,6 +591,156 @@ func (c *Client) ExecInContainer(ctx context.Context, containerID string, cmd []
 	return inspectResp.ExitCode, buf.String(), nil
 }
 
+// ExecStream runs a command in a running container with its streams attached and returns its exit code.
+func (c *Client) ExecStream(ctx context.Context, containerID string, opts runtime.ExecOptions) (int, error) {
+	execConfig := container.ExecOptions{
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          opts.TTY,
+		Cmd:          opts.Cmd,
+	}
+
+	execID, err := c.cli.ContainerExecCreate(ctx, containerID, execConfig)
+	if err != nil {
+		return -1, fmt.Errorf("failed to create exec: %w", err)
+	}
+
+	resp, err := c.cli.ContainerExecAttach(ctx, execID.ID, container.ExecStartOptions{Tty: opts.TTY})
+	if err != nil {
+		return -1, fmt.Errorf("failed to attach to exec: %w", err)
+	}
+	defer resp.Close()
+
+	if opts.Stdin != nil {
+		go func() {
+			_, _ = io.Copy(resp.Conn, opts.Stdin)
+			_ = resp.CloseWrite()
+		}()
+	}
+
+	if opts.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-opts.Resize:
+					if !ok {
+						return
+					}
+					resize := container.ResizeOptions{Height: uint(size.Height), Width: uint(size.Width)}
+					_ = c.cli.ContainerExecResize(ctx, execID.ID, resize)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	if opts.TTY {
+		_, err = io.Copy(opts.Stdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(opts.Stdout, opts.Stderr, resp.Reader)
+	}
+	if err != nil {
+		return -1, fmt.Errorf("failed to stream exec output: %w", err)
+	}
+
+	inspectResp, err := c.cli.ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to inspect exec: %w", err)
+	}
+
+	return inspectResp.ExitCode, nil
+}
+
+// ContainerMemoryUsage returns a container's memory working set in bytes
+// (total usage minus inactive page cache, which the kernel can reclaim).
+func (c *Client) ContainerMemoryUsage(ctx context.Context, containerID string) (int64, error) {
+	resp, err := c.cli.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stats for container %s: %w", containerID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, fmt.Errorf("failed to decode stats for container %s: %w", containerID, err)
+	}
+
+	return memoryWorkingSet(stats.MemoryStats), nil
+}
+
+// memoryWorkingSet subtracts inactive file cache from memory usage (cgroup v1 and v2 keys)
+func memoryWorkingSet(mem container.MemoryStats) int64 {
+	usage := mem.Usage
+	inactive, ok := mem.Stats["inactive_file"]
+	if !ok {
+		inactive = mem.Stats["total_inactive_file"]
+	}
+	if inactive < usage {
+		usage -= inactive
+	}
+	return int64(usage)
+}
+
+// ContainerStats returns a sample of a container's CPU, memory, network, block IO and process counters.
+func (c *Client) ContainerStats(ctx context.Context, containerID string) (runtime.ContainerStats, error) {
+	resp, err := c.cli.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return runtime.ContainerStats{}, fmt.Errorf("failed to get stats for container %s: %w", containerID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return runtime.ContainerStats{}, fmt.Errorf("failed to decode stats for container %s: %w", containerID, err)
+	}
+
+	return convertStats(stats), nil
+}
+
+// convertStats maps a Docker stats response to runtime container stats
+func convertStats(stats container.StatsResponse) runtime.ContainerStats {
+	result := runtime.ContainerStats{
+		Timestamp:        stats.Read,
+		CPUUsageNanos:    stats.CPUStats.CPUUsage.TotalUsage,
+		MemoryWorkingSet: memoryWorkingSet(stats.MemoryStats),
+		MemoryLimit:      int64(stats.MemoryStats.Limit),
+		PIDs:             int64(stats.PidsStats.Current),
+	}
+	if result.Timestamp.IsZero() {
+		result.Timestamp = time.Now()
+	}
+
+	for _, network := range stats.Networks {
+		result.NetworkRxBytes += network.RxBytes
+		result.NetworkTxBytes += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			result.BlockReadBytes += entry.Value
+		case "write":
+			result.BlockWriteBytes += entry.Value
+		}
+	}
+
+	return result
+}
+
+// ContainerDiskUsage returns the size of a container's writable layer in bytes.
+func (c *Client) ContainerDiskUsage(ctx context.Context, containerID string) (int64, error) {
+	inspect, _, err := c.cli.ContainerInspectWithRaw(ctx, containerID, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+	if inspect.SizeRw == nil {
+		return 0, nil
+	}
+	return *inspect.SizeRw, nil
+}
+
 // GetContainerIP returns the IP address of a container
 func (c *Client) GetContainerIP(ctx context.Context, containerID string) (string, error) {
 	inspect, err := c.cli.ContainerInspect(ctx, containerID)
@@ -295,21 +765,35 @@ func (c *Client) GetContainerIP(ctx context.Context, containerID string) (string
 }
 
 // CreatePodNetwork creates a dedicated Docker bridge network for a pod
-// All containers in the pod will be attached to this network, sharing the same namespace
-func (c *Client) CreatePodNetwork(ctx context.Context, podID string) (string, error) {
+// All containers in the pod will be attached to this network, sharing the same namespace.
+// Docker configures DNS per container, so opts.DNS is applied through ContainerOptions instead.
+// A network with a subnet from the node's pod CIDR accepts connections routed from other nodes,
+// so pods elsewhere in the cluster reach its containers by IP.
+func (c *Client) CreatePodNetwork(ctx context.Context, podID string, opts runtime.PodNetworkOptions) (string, error) {
 	networkName := fmt.Sprintf("pod-%s", podID)
 
-	// Note: We don't set com.docker.network.bridge.name as it has length/character restrictions
-	createResp, err := c.cli.NetworkCreate(
-		ctx, networkName, network.CreateOptions{
-			Driver: "bridge",
-			Labels: map[string]string{
-				"podling.io/pod-id": podID,
-				"podling.io/type":   "pod-network",
-			},
+	createOpts := network.CreateOptions{
+		Driver: "bridge",
+		Labels: map[string]string{
+			runtime.LabelPodID: podID,
+			runtime.LabelType:  runtime.TypePodNetwork,
 		},
-	)
+		Options: map[string]string{},
+	}
+	if opts.NodeID != "" {
+		createOpts.Labels[runtime.LabelNodeID] = opts.NodeID
+	}
+	if opts.Subnet != "" {
+		createOpts.IPAM = &network.IPAM{Config: []network.IPAMConfig{{Subnet: opts.Subnet}}}
+		// The default "nat" mode drops traffic to container IPs that does not come from the host
+		createOpts.Options["com.docker.network.bridge.gateway_mode_ipv4"] = "nat-unprotected"
+	}
+	if opts.MTU > 0 {
+		createOpts.Options["com.docker.network.driver.mtu"] = strconv.Itoa(opts.MTU)
+	}
 
+	// Note: We don't set com.docker.network.bridge.name as it has length/character restrictions
+	createResp, err := c.cli.NetworkCreate(ctx, networkName, createOpts)
 	if err != nil {
 		return "", fmt.Errorf("failed to create pod network %s: %w", networkName, err)
 	}
@@ -317,6 +801,32 @@ func (c *Client) CreatePodNetwork(ctx context.Context, podID string) (string, er
 	return createResp.ID, nil
 }
 
+// ListPodNetworks returns the networks created by CreatePodNetwork
+func (c *Client) ListPodNetworks(ctx context.Context) ([]runtime.NetworkInfo, error) {
+	networks, err := c.cli.NetworkList(
+		ctx, network.ListOptions{
+			Filters: filters.NewArgs(filters.Arg("label", runtime.LabelType+"="+runtime.TypePodNetwork)),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	infos := make([]runtime.NetworkInfo, 0, len(networks))
+	for _, n := range networks {
+		info := runtime.NetworkInfo{
+			ID:     n.ID,
+			PodID:  n.Labels[runtime.LabelPodID],
+			NodeID: n.Labels[runtime.LabelNodeID],
+		}
+		if len(n.IPAM.Config) > 0 {
+			info.Subnet = n.IPAM.Config[0].Subnet
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
 // RemovePodNetwork removes a pod's network
 func (c *Client) RemovePodNetwork(ctx context.Context, networkID string) error {
 	if err := c.cli.NetworkRemove(ctx, networkID); err != nil {
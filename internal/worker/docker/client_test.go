@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+	"github.com/docker/docker/api/types/container"
 )
 
 func TestNewClient(t *testing.T) {
@@ -560,7 +563,7 @@ func TestCreatePodNetwork(t *testing.T) {
 		"create and remove pod network", func(t *testing.T) {
 			podID := "test-pod-123"
 
-			networkID, err := client.CreatePodNetwork(ctx, podID)
+			networkID, err := client.CreatePodNetwork(ctx, podID, runtime.PodNetworkOptions{})
 			if err != nil {
 				t.Fatalf("CreatePodNetwork() error = %v", err)
 			}
@@ -596,7 +599,7 @@ func TestCreateContainerInNetwork(t *testing.T) {
 	ctx := context.Background()
 	podID := "test-pod-network-456"
 
-	networkID, err := client.CreatePodNetwork(ctx, podID)
+	networkID, err := client.CreatePodNetwork(ctx, podID, runtime.PodNetworkOptions{})
 	if err != nil {
 		t.Fatalf("CreatePodNetwork() error = %v", err)
 	}
@@ -651,7 +654,7 @@ func TestGetNetworkIP(t *testing.T) {
 	ctx := context.Background()
 	podID := "test-pod-ip-789"
 
-	networkID, err := client.CreatePodNetwork(ctx, podID)
+	networkID, err := client.CreatePodNetwork(ctx, podID, runtime.PodNetworkOptions{})
 	if err != nil {
 		t.Fatalf("CreatePodNetwork() error = %v", err)
 	}
@@ -705,3 +708,46 @@ func TestGetNetworkIP(t *testing.T) {
 		},
 	)
 }
+
+func TestConvertStats(t *testing.T) {
+	read := time.Now()
+	stats := container.StatsResponse{
+		Read: read,
+		CPUStats: container.CPUStats{
+			CPUUsage: container.CPUUsage{TotalUsage: 3_000_000_000},
+		},
+		MemoryStats: container.MemoryStats{
+			Usage: 100 << 20,
+			Limit: 512 << 20,
+			Stats: map[string]uint64{"inactive_file": 20 << 20},
+		},
+		PidsStats: container.PidsStats{Current: 7},
+		Networks: map[string]container.NetworkStats{
+			"eth0": {RxBytes: 1000, TxBytes: 500},
+			"eth1": {RxBytes: 24, TxBytes: 12},
+		},
+		BlkioStats: container.BlkioStats{
+			IoServiceBytesRecursive: []container.BlkioStatEntry{
+				{Op: "Read", Value: 4096},
+				{Op: "write", Value: 8192},
+				{Op: "Total", Value: 12288},
+			},
+		},
+	}
+
+	got := convertStats(stats)
+	want := runtime.ContainerStats{
+		Timestamp:        read,
+		CPUUsageNanos:    3_000_000_000,
+		MemoryWorkingSet: 80 << 20,
+		MemoryLimit:      512 << 20,
+		NetworkRxBytes:   1024,
+		NetworkTxBytes:   512,
+		BlockReadBytes:   4096,
+		BlockWriteBytes:  8192,
+		PIDs:             7,
+	}
+	if got != want {
+		t.Errorf("convertStats() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,315 @@
+// Package eviction watches node memory and disk availability and evicts pods,
+// lowest quality of service first, when the node runs short.
+package eviction
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Config holds the eviction thresholds
+type Config struct {
+	// MemoryAvailableThreshold triggers memory pressure when available memory drops below it (bytes)
+	MemoryAvailableThreshold int64
+
+	// DiskAvailablePercent triggers disk pressure when free disk drops below this percentage
+	DiskAvailablePercent int
+
+	// PIDAvailablePercent reports PID pressure when free process IDs drop below this percentage
+	PIDAvailablePercent int
+
+	// Interval is how often signals are checked
+	Interval time.Duration
+
+	// PressureTransitionPeriod is how long a pressure condition stays set after the signal clears
+	PressureTransitionPeriod time.Duration
+}
+
+// DefaultConfig returns the default eviction thresholds
+func DefaultConfig() Config {
+	return Config{
+		MemoryAvailableThreshold: 100 * 1024 * 1024,
+		DiskAvailablePercent:     10,
+		PIDAvailablePercent:      5,
+		Interval:                 10 * time.Second,
+		PressureTransitionPeriod: time.Minute,
+	}
+}
+
+// Validate checks that the thresholds are usable
+func (c Config) Validate() error {
+	if c.MemoryAvailableThreshold < 0 {
+		return fmt.Errorf("memory available threshold must not be negative")
+	}
+	if c.DiskAvailablePercent < 0 || c.DiskAvailablePercent > 100 {
+		return fmt.Errorf("disk available percent must be between 0 and 100, got %d", c.DiskAvailablePercent)
+	}
+	if c.PIDAvailablePercent < 0 || c.PIDAvailablePercent > 100 {
+		return fmt.Errorf("pid available percent must be between 0 and 100, got %d", c.PIDAvailablePercent)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	return nil
+}
+
+// Signals is a snapshot of node resource availability
+type Signals struct {
+	MemoryAvailable int64
+	MemoryCapacity  int64
+	DiskAvailable   int64
+	DiskCapacity    int64
+	PIDsAvailable   int64
+	PIDCapacity     int64
+}
+
+// PodCandidate describes a running pod that may be evicted
+type PodCandidate struct {
+	PodID         string
+	QOSClass      types.QOSClass
+	MemoryUsage   int64
+	MemoryRequest int64
+	DiskUsage     int64
+	StartedAt     time.Time
+}
+
+// Provider supplies the node signals, running pods and the actions the manager takes
+type Provider interface {
+	// Signals returns the current node resource availability
+	Signals(ctx context.Context) (Signals, error)
+
+	// ActivePods returns the pods running on the node with their current usage
+	ActivePods(ctx context.Context) []PodCandidate
+
+	// ReclaimDisk frees node-level disk space (e.g. unused images) and returns the bytes freed
+	ReclaimDisk(ctx context.Context, bytesToFree int64) (int64, error)
+
+	// EvictPod terminates a pod with the given message
+	EvictPod(ctx context.Context, podID, message string) error
+}
+
+// Manager evicts pods when the node is under memory or disk pressure
+type Manager struct {
+	config   Config
+	provider Provider
+	now      func() time.Time
+
+	mu           sync.RWMutex
+	conditions   map[types.NodeConditionType]types.NodeCondition
+	lastObserved map[types.NodeConditionType]time.Time
+}
+
+// NewManager creates a new eviction manager
+func NewManager(config Config, provider Provider) *Manager {
+	return &Manager{
+		config:       config,
+		provider:     provider,
+		now:          time.Now,
+		conditions:   make(map[types.NodeConditionType]types.NodeCondition),
+		lastObserved: make(map[types.NodeConditionType]time.Time),
+	}
+}
+
+// Config returns the manager's configuration
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+// Conditions returns the node's current pressure conditions
+func (m *Manager) Conditions() []types.NodeCondition {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	conditionTypes := []types.NodeConditionType{types.NodeMemoryPressure, types.NodeDiskPressure, types.NodePIDPressure}
+	conditions := make([]types.NodeCondition, 0, len(m.conditions))
+	for _, conditionType := range conditionTypes {
+		if condition, ok := m.conditions[conditionType]; ok {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// Synchronize checks node signals, updates pressure conditions and evicts at most one pod.
+// It returns the ID of the evicted pod, or an empty string if none was evicted.
+// PID pressure is reported so no new work is scheduled on the node, but does not evict pods.
+func (m *Manager) Synchronize(ctx context.Context) (string, error) {
+	signals, err := m.provider.Signals(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read node signals: %w", err)
+	}
+
+	memoryPressure := m.memoryPressure(signals)
+	diskPressure := m.diskPressure(signals)
+	pidPressure := m.pidPressure(signals)
+
+	if diskPressure {
+		needed := m.diskBytesNeeded(signals)
+		freed, err := m.provider.ReclaimDisk(ctx, needed)
+		if err != nil {
+			log.Printf("failed to reclaim node disk space: %v", err)
+		}
+		if freed >= needed {
+			log.Printf("reclaimed %d bytes of disk without evicting pods", freed)
+			signals.DiskAvailable += freed
+			diskPressure = m.diskPressure(signals)
+		}
+	}
+
+	m.setCondition(
+		types.NodeMemoryPressure, memoryPressure,
+		fmt.Sprintf("available memory %s (threshold %s)",
+			types.FormatMemory(signals.MemoryAvailable), types.FormatMemory(m.config.MemoryAvailableThreshold)),
+	)
+	m.setCondition(
+		types.NodeDiskPressure, diskPressure,
+		fmt.Sprintf("available disk %s of %s (threshold %d%%)",
+			types.FormatMemory(signals.DiskAvailable), types.FormatMemory(signals.DiskCapacity),
+			m.config.DiskAvailablePercent),
+	)
+	m.setCondition(
+		types.NodePIDPressure, pidPressure,
+		fmt.Sprintf("available process IDs %d of %d (threshold %d%%)",
+			signals.PIDsAvailable, signals.PIDCapacity, m.config.PIDAvailablePercent),
+	)
+
+	if !memoryPressure && !diskPressure {
+		return "", nil
+	}
+
+	candidates := m.provider.ActivePods(ctx)
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	var signal string
+	if memoryPressure {
+		signal = "memory"
+		RankForMemoryPressure(candidates)
+	} else {
+		signal = "ephemeral-storage"
+		RankForDiskPressure(candidates)
+	}
+
+	victim := candidates[0]
+	message := fmt.Sprintf(
+		"The node was low on resource: %s. Pod QoS class %s was evicted first.", signal, victim.QOSClass,
+	)
+	log.Printf("evicting pod %s (%s): %s", victim.PodID, victim.QOSClass, message)
+
+	if err := m.provider.EvictPod(ctx, victim.PodID, message); err != nil {
+		return "", fmt.Errorf("failed to evict pod %s: %w", victim.PodID, err)
+	}
+	return victim.PodID, nil
+}
+
+// memoryPressure reports whether available memory is below the threshold
+func (m *Manager) memoryPressure(signals Signals) bool {
+	return signals.MemoryCapacity > 0 && signals.MemoryAvailable < m.config.MemoryAvailableThreshold
+}
+
+// diskPressure reports whether free disk is below the threshold percentage
+func (m *Manager) diskPressure(signals Signals) bool {
+	if signals.DiskCapacity == 0 {
+		return false
+	}
+	return signals.DiskAvailable*100 < signals.DiskCapacity*int64(m.config.DiskAvailablePercent)
+}
+
+// pidPressure reports whether free process IDs are below the threshold percentage
+func (m *Manager) pidPressure(signals Signals) bool {
+	if signals.PIDCapacity == 0 {
+		return false
+	}
+	return signals.PIDsAvailable*100 < signals.PIDCapacity*int64(m.config.PIDAvailablePercent)
+}
+
+// diskBytesNeeded returns how much disk must be freed to leave the pressure state
+func (m *Manager) diskBytesNeeded(signals Signals) int64 {
+	target := signals.DiskCapacity * int64(m.config.DiskAvailablePercent) / 100
+	if signals.DiskAvailable >= target {
+		return 0
+	}
+	return target - signals.DiskAvailable
+}
+
+// setCondition records an observation and applies the transition period before clearing pressure
+func (m *Manager) setCondition(conditionType types.NodeConditionType, observed bool, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if observed {
+		m.lastObserved[conditionType] = now
+	}
+
+	status := observed
+	if !observed {
+		if last, ok := m.lastObserved[conditionType]; ok && now.Sub(last) < m.config.PressureTransitionPeriod {
+			status = true
+		}
+	}
+
+	reason := "NoPressure"
+	if status {
+		reason = "UnderPressure"
+	}
+
+	previous, exists := m.conditions[conditionType]
+	condition := types.NodeCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: now,
+	}
+	if exists && previous.Status == status {
+		condition.LastTransitionTime = previous.LastTransitionTime
+	}
+	if !exists || previous.Status != status {
+		log.Printf("node condition %s changed to %t: %s", conditionType, status, message)
+	}
+
+	m.conditions[conditionType] = condition
+}
+
+// RankForMemoryPressure orders pods for eviction under memory pressure: lowest QoS class
+// first, then by how far memory usage exceeds the request, then youngest first
+func RankForMemoryPressure(pods []PodCandidate) {
+	sort.SliceStable(
+		pods, func(i, j int) bool {
+			a, b := pods[i], pods[j]
+			if a.QOSClass.Rank() != b.QOSClass.Rank() {
+				return a.QOSClass.Rank() < b.QOSClass.Rank()
+			}
+			aExcess, bExcess := a.MemoryUsage-a.MemoryRequest, b.MemoryUsage-b.MemoryRequest
+			if aExcess != bExcess {
+				return aExcess > bExcess
+			}
+			return a.StartedAt.After(b.StartedAt)
+		},
+	)
+}
+
+// RankForDiskPressure orders pods for eviction under disk pressure: lowest QoS class
+// first, then by writable layer usage, then youngest first
+func RankForDiskPressure(pods []PodCandidate) {
+	sort.SliceStable(
+		pods, func(i, j int) bool {
+			a, b := pods[i], pods[j]
+			if a.QOSClass.Rank() != b.QOSClass.Rank() {
+				return a.QOSClass.Rank() < b.QOSClass.Rank()
+			}
+			if a.DiskUsage != b.DiskUsage {
+				return a.DiskUsage > b.DiskUsage
+			}
+			return a.StartedAt.After(b.StartedAt)
+		},
+	)
+}
@@ -0,0 +1,274 @@
+package eviction
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+const mib = int64(1 << 20)
+
+type mockProvider struct {
+	signals   Signals
+	pods      []PodCandidate
+	reclaim   int64
+	reclaimed int64
+	evicted   []string
+}
+
+func (m *mockProvider) Signals(_ context.Context) (Signals, error) {
+	return m.signals, nil
+}
+
+func (m *mockProvider) ActivePods(_ context.Context) []PodCandidate {
+	return append([]PodCandidate(nil), m.pods...)
+}
+
+func (m *mockProvider) ReclaimDisk(_ context.Context, _ int64) (int64, error) {
+	m.reclaimed += m.reclaim
+	return m.reclaim, nil
+}
+
+func (m *mockProvider) EvictPod(_ context.Context, podID, _ string) error {
+	m.evicted = append(m.evicted, podID)
+	return nil
+}
+
+func healthySignals() Signals {
+	return Signals{
+		MemoryAvailable: 4096 * mib,
+		MemoryCapacity:  8192 * mib,
+		DiskAvailable:   50 * 1024 * mib,
+		DiskCapacity:    100 * 1024 * mib,
+		PIDsAvailable:   30000,
+		PIDCapacity:     32768,
+	}
+}
+
+func TestSynchronize(t *testing.T) {
+	now := time.Now()
+	pods := []PodCandidate{
+		{PodID: "guaranteed", QOSClass: types.QOSGuaranteed, MemoryUsage: 900 * mib, MemoryRequest: 256 * mib},
+		{PodID: "burstable", QOSClass: types.QOSBurstable, MemoryUsage: 200 * mib, MemoryRequest: 128 * mib},
+		{PodID: "besteffort-small", QOSClass: types.QOSBestEffort, MemoryUsage: 10 * mib, StartedAt: now},
+		{PodID: "besteffort-large", QOSClass: types.QOSBestEffort, MemoryUsage: 500 * mib, DiskUsage: 1 * mib},
+	}
+
+	tests := []struct {
+		name          string
+		signals       func(Signals) Signals
+		pods          []PodCandidate
+		reclaim       int64
+		wantEvicted   string
+		wantMemory    bool
+		wantDisk      bool
+		wantPID       bool
+		wantReclaimed bool
+	}{
+		{
+			name:    "no pressure",
+			signals: func(s Signals) Signals { return s },
+			pods:    pods,
+		},
+		{
+			name: "memory pressure evicts largest best effort pod",
+			signals: func(s Signals) Signals {
+				s.MemoryAvailable = 50 * mib
+				return s
+			},
+			pods:        pods,
+			wantEvicted: "besteffort-large",
+			wantMemory:  true,
+		},
+		{
+			name: "memory pressure without best effort pods evicts burstable",
+			signals: func(s Signals) Signals {
+				s.MemoryAvailable = 50 * mib
+				return s
+			},
+			pods:        pods[:2],
+			wantEvicted: "burstable",
+			wantMemory:  true,
+		},
+		{
+			name: "disk pressure relieved by reclaiming images",
+			signals: func(s Signals) Signals {
+				s.DiskAvailable = 5 * 1024 * mib
+				return s
+			},
+			pods:          pods,
+			reclaim:       10 * 1024 * mib,
+			wantReclaimed: true,
+		},
+		{
+			name: "disk pressure evicts when images are not enough",
+			signals: func(s Signals) Signals {
+				s.DiskAvailable = 5 * 1024 * mib
+				return s
+			},
+			pods:          pods,
+			wantEvicted:   "besteffort-large",
+			wantDisk:      true,
+			wantReclaimed: true,
+		},
+		{
+			name: "pid pressure is reported without evicting",
+			signals: func(s Signals) Signals {
+				s.PIDsAvailable = 100
+				return s
+			},
+			pods:    pods,
+			wantPID: true,
+		},
+		{
+			name: "pressure with no pods",
+			signals: func(s Signals) Signals {
+				s.MemoryAvailable = 50 * mib
+				return s
+			},
+			wantMemory: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				provider := &mockProvider{signals: tt.signals(healthySignals()), pods: tt.pods, reclaim: tt.reclaim}
+				m := NewManager(DefaultConfig(), provider)
+
+				evicted, err := m.Synchronize(context.Background())
+				if err != nil {
+					t.Fatalf("Synchronize() error = %v", err)
+				}
+				if evicted != tt.wantEvicted {
+					t.Errorf("Synchronize() evicted %q, want %q", evicted, tt.wantEvicted)
+				}
+				if tt.wantEvicted == "" && len(provider.evicted) != 0 {
+					t.Errorf("expected no evictions, got %v", provider.evicted)
+				}
+				if (provider.reclaimed > 0 || tt.wantReclaimed) != tt.wantReclaimed {
+					t.Errorf("reclaimed = %d, wantReclaimed %v", provider.reclaimed, tt.wantReclaimed)
+				}
+
+				node := types.Node{Conditions: m.Conditions()}
+				if c, _ := node.GetCondition(types.NodeMemoryPressure); c.Status != tt.wantMemory {
+					t.Errorf("MemoryPressure = %v, want %v", c.Status, tt.wantMemory)
+				}
+				if c, _ := node.GetCondition(types.NodeDiskPressure); c.Status != tt.wantDisk {
+					t.Errorf("DiskPressure = %v, want %v", c.Status, tt.wantDisk)
+				}
+				if c, _ := node.GetCondition(types.NodePIDPressure); c.Status != tt.wantPID {
+					t.Errorf("PIDPressure = %v, want %v", c.Status, tt.wantPID)
+				}
+			},
+		)
+	}
+}
+
+func TestPressureTransitionPeriod(t *testing.T) {
+	provider := &mockProvider{signals: healthySignals()}
+	provider.signals.MemoryAvailable = 50 * mib
+
+	m := NewManager(DefaultConfig(), provider)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if _, err := m.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+
+	provider.signals = healthySignals()
+	now = now.Add(m.config.PressureTransitionPeriod / 2)
+	_, _ = m.Synchronize(context.Background())
+
+	node := types.Node{Conditions: m.Conditions()}
+	if !node.HasPressure() {
+		t.Fatalf("expected pressure to be kept within the transition period")
+	}
+
+	now = now.Add(m.config.PressureTransitionPeriod)
+	_, _ = m.Synchronize(context.Background())
+
+	node = types.Node{Conditions: m.Conditions()}
+	if node.HasPressure() {
+		t.Errorf("expected pressure to clear after the transition period")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(*Config) {}},
+		{name: "negative memory", modify: func(c *Config) { c.MemoryAvailableThreshold = -1 }, wantErr: true},
+		{name: "disk percent over 100", modify: func(c *Config) { c.DiskAvailablePercent = 101 }, wantErr: true},
+		{name: "negative pid percent", modify: func(c *Config) { c.PIDAvailablePercent = -1 }, wantErr: true},
+		{name: "zero interval", modify: func(c *Config) { c.Interval = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := DefaultConfig()
+				tt.modify(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestParseMemInfo(t *testing.T) {
+	input := `MemTotal:        8048576 kB
+MemFree:          512000 kB
+MemAvailable:    2048000 kB
+Buffers:          102400 kB
+`
+	available, total, err := parseMemInfo(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseMemInfo() error = %v", err)
+	}
+	if available != 2048000*1024 {
+		t.Errorf("available = %d, want %d", available, 2048000*1024)
+	}
+	if total != 8048576*1024 {
+		t.Errorf("total = %d, want %d", total, 8048576*1024)
+	}
+
+	if _, _, err := parseMemInfo(strings.NewReader("MemFree: 100 kB\n")); err == nil {
+		t.Errorf("expected error when MemAvailable is missing")
+	}
+}
+
+func TestParseLoadAvg(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "valid", input: "0.42 0.35 0.30 2/613 12345\n", want: 613},
+		{name: "missing fields", input: "0.42 0.35", wantErr: true},
+		{name: "malformed count", input: "0.42 0.35 0.30 613 12345", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parseLoadAvg(strings.NewReader(tt.input))
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("parseLoadAvg() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if got != tt.want {
+					t.Errorf("parseLoadAvg() = %d, want %d", got, tt.want)
+				}
+			},
+		)
+	}
+}
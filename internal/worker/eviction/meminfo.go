@@ -0,0 +1,54 @@
+package eviction
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadMemInfo returns available and total memory in bytes from /proc/meminfo
+func ReadMemInfo() (available, total int64, err error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read memory info: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return parseMemInfo(f)
+}
+
+// parseMemInfo extracts MemAvailable and MemTotal from meminfo formatted input
+func parseMemInfo(r io.Reader) (available, total int64, err error) {
+	foundAvailable, foundTotal := false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		kb, parseErr := strconv.ParseInt(fields[1], 10, 64)
+		if parseErr != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "MemAvailable:":
+			available, foundAvailable = kb*1024, true
+		case "MemTotal:":
+			total, foundTotal = kb*1024, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse memory info: %w", err)
+	}
+
+	if !foundAvailable || !foundTotal {
+		return 0, 0, fmt.Errorf("MemAvailable or MemTotal missing from memory info")
+	}
+	return available, total, nil
+}
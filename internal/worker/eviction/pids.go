@@ -0,0 +1,58 @@
+package eviction
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadPIDs returns the number of processes on the node and the kernel's process ID limit
+func ReadPIDs() (used, limit int64, err error) {
+	f, err := os.Open("/proc/loadavg")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read process count: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	used, err = parseLoadAvg(f)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	data, err := os.ReadFile("/proc/sys/kernel/pid_max")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read pid_max: %w", err)
+	}
+	limit, err = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse pid_max: %w", err)
+	}
+
+	return used, limit, nil
+}
+
+// parseLoadAvg extracts the total number of processes from loadavg formatted input,
+// e.g. "0.42 0.35 0.30 2/613 12345"
+func parseLoadAvg(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read loadavg: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected loadavg format: %q", strings.TrimSpace(string(data)))
+	}
+
+	_, total, found := strings.Cut(fields[3], "/")
+	if !found {
+		return 0, fmt.Errorf("unexpected process count format: %q", fields[3])
+	}
+	count, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse process count: %w", err)
+	}
+	return count, nil
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/danpasecinic/podling/internal/types"
-	"github.com/danpasecinic/podling/internal/worker/docker"
 )
 
 // DockerIPClient defines the interface for getting container IPs
@@ -48,7 +47,7 @@ func NewChecker(
 	containerID string,
 	check *types.HealthCheck,
 	restartPolicy types.RestartPolicy,
-	dockerClient *docker.Client,
+	dockerClient DockerHealthClient,
 	onUnhealthy func(string),
 ) *Checker {
 	return newCheckerWithClient(taskID, containerID, check, restartPolicy, dockerClient, onUnhealthy)
@@ -0,0 +1,15 @@
+package imagegc
+
+// DiskStats describes the capacity of the filesystem holding images
+type DiskStats struct {
+	CapacityBytes  uint64
+	AvailableBytes uint64
+}
+
+// UsedBytes returns the number of bytes in use on the filesystem
+func (d DiskStats) UsedBytes() uint64 {
+	if d.AvailableBytes > d.CapacityBytes {
+		return 0
+	}
+	return d.CapacityBytes - d.AvailableBytes
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package imagegc
+
+import "errors"
+
+// DiskUsage is not supported on this platform
+func DiskUsage(path string) (DiskStats, error) {
+	return DiskStats{}, errors.New("disk usage is not supported on this platform")
+}
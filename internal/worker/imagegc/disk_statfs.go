@@ -0,0 +1,19 @@
+//go:build linux || darwin
+
+package imagegc
+
+import "syscall"
+
+// DiskUsage returns capacity statistics for the filesystem containing path
+func DiskUsage(path string) (DiskStats, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return DiskStats{}, err
+	}
+
+	blockSize := uint64(fs.Bsize)
+	return DiskStats{
+		CapacityBytes:  uint64(fs.Blocks) * blockSize,
+		AvailableBytes: uint64(fs.Bavail) * blockSize,
+	}, nil
+}
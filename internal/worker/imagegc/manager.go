@@ -0,0 +1,359 @@
+// Package imagegc removes unused container images from a worker node when
+// the image filesystem runs low on space.
+package imagegc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// ImageClient is the subset of the container runtime used for image garbage collection
+type ImageClient interface {
+	ListImages(ctx context.Context) ([]runtime.ImageInfo, error)
+	RemoveImage(ctx context.Context, imageID string) error
+	RootDir(ctx context.Context) (string, error)
+}
+
+// Config controls when and how aggressively images are collected
+type Config struct {
+	// HighThresholdPercent is the disk usage at which garbage collection starts
+	HighThresholdPercent int
+
+	// LowThresholdPercent is the disk usage garbage collection tries to free down to
+	LowThresholdPercent int
+
+	// Interval is how often disk usage is checked
+	Interval time.Duration
+
+	// MinAge is the minimum time since last use before an image may be removed
+	MinAge time.Duration
+}
+
+// DefaultConfig returns the default image garbage collection settings
+func DefaultConfig() Config {
+	return Config{
+		HighThresholdPercent: 85,
+		LowThresholdPercent:  80,
+		Interval:             5 * time.Minute,
+		MinAge:               2 * time.Minute,
+	}
+}
+
+// Validate checks that the thresholds are consistent
+func (c Config) Validate() error {
+	if c.HighThresholdPercent < 0 || c.HighThresholdPercent > 100 {
+		return fmt.Errorf("high threshold must be between 0 and 100, got %d", c.HighThresholdPercent)
+	}
+	if c.LowThresholdPercent < 0 || c.LowThresholdPercent > 100 {
+		return fmt.Errorf("low threshold must be between 0 and 100, got %d", c.LowThresholdPercent)
+	}
+	if c.LowThresholdPercent > c.HighThresholdPercent {
+		return fmt.Errorf(
+			"low threshold (%d) must not exceed high threshold (%d)", c.LowThresholdPercent, c.HighThresholdPercent,
+		)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	return nil
+}
+
+// Manager tracks image usage and evicts unused images least-recently-used first
+type Manager struct {
+	client    ImageClient
+	config    Config
+	inUse     func() []string
+	diskUsage func(path string) (DiskStats, error)
+	now       func() time.Time
+
+	mu        sync.Mutex
+	rootDir   string
+	lastUsed  map[string]time.Time
+	firstSeen map[string]time.Time
+}
+
+// NewManager creates a new image garbage collector.
+// inUse returns the image references of containers that are currently running on the node.
+func NewManager(client ImageClient, config Config, inUse func() []string) *Manager {
+	return &Manager{
+		client:    client,
+		config:    config,
+		inUse:     inUse,
+		diskUsage: DiskUsage,
+		now:       time.Now,
+		lastUsed:  make(map[string]time.Time),
+		firstSeen: make(map[string]time.Time),
+	}
+}
+
+// Config returns the manager's configuration
+func (m *Manager) Config() Config {
+	return m.config
+}
+
+// MarkUsed records that a container was started from the given image
+func (m *Manager) MarkUsed(imageRef string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastUsed[NormalizeReference(imageRef)] = m.now()
+}
+
+// Inventory returns the images present on the node with their last use time
+func (m *Manager) Inventory(ctx context.Context) ([]types.ContainerImage, error) {
+	images, err := m.client.ListImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inventory := make([]types.ContainerImage, 0, len(images))
+	for _, img := range images {
+		entry := types.ContainerImage{
+			ID:        img.ID,
+			Names:     img.RepoTags,
+			SizeBytes: img.Size,
+		}
+		if used, ok := m.markedUseLocked(img); ok {
+			entry.LastUsed = &used
+		}
+		inventory = append(inventory, entry)
+	}
+
+	sort.Slice(
+		inventory, func(i, j int) bool {
+			return inventory[i].SizeBytes > inventory[j].SizeBytes
+		},
+	)
+
+	return inventory, nil
+}
+
+// GarbageCollect removes unused images when disk usage is above the high threshold,
+// freeing space until usage drops to the low threshold. It returns the number of bytes freed.
+func (m *Manager) GarbageCollect(ctx context.Context) (int64, error) {
+	path, err := m.imageRootDir(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	stats, err := m.diskUsage(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get disk usage for %s: %w", path, err)
+	}
+	if stats.CapacityBytes == 0 {
+		return 0, nil
+	}
+
+	usagePercent := int(stats.UsedBytes() * 100 / stats.CapacityBytes)
+	if usagePercent < m.config.HighThresholdPercent {
+		return 0, nil
+	}
+
+	target := stats.CapacityBytes * uint64(m.config.LowThresholdPercent) / 100
+	toFree := int64(stats.UsedBytes() - target)
+
+	log.Printf(
+		"image disk usage %d%% is above high threshold %d%%, trying to free %d bytes",
+		usagePercent, m.config.HighThresholdPercent, toFree,
+	)
+
+	freed, err := m.freeSpace(ctx, toFree)
+	if err != nil {
+		return freed, err
+	}
+	if freed < toFree {
+		return freed, fmt.Errorf("failed to free enough image space: freed %d of %d bytes", freed, toFree)
+	}
+	return freed, nil
+}
+
+// ReclaimSpace removes unused images regardless of thresholds until bytesToFree bytes
+// have been reclaimed. It is used to relieve disk pressure before evicting pods.
+func (m *Manager) ReclaimSpace(ctx context.Context, bytesToFree int64) (int64, error) {
+	if bytesToFree <= 0 {
+		return 0, nil
+	}
+	return m.freeSpace(ctx, bytesToFree)
+}
+
+// freeSpace removes candidate images in LRU order until bytesToFree bytes have been reclaimed
+func (m *Manager) freeSpace(ctx context.Context, bytesToFree int64) (int64, error) {
+	images, err := m.client.ListImages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	candidates := m.evictionCandidates(images)
+
+	var freed int64
+	for _, img := range candidates {
+		if freed >= bytesToFree {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return freed, err
+		}
+
+		log.Printf("removing unused image %s %v (%d bytes)", img.ID, img.RepoTags, img.Size)
+		if err := m.client.RemoveImage(ctx, img.ID); err != nil {
+			log.Printf("failed to remove image %s: %v", img.ID, err)
+			continue
+		}
+
+		freed += img.Size
+		m.forget(img)
+	}
+
+	return freed, nil
+}
+
+// evictionCandidates returns the images that may be removed, least recently used first
+func (m *Manager) evictionCandidates(images []runtime.ImageInfo) []runtime.ImageInfo {
+	protected := make(map[string]bool)
+	for _, ref := range m.inUse() {
+		protected[NormalizeReference(ref)] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	seen := make(map[string]bool, len(images))
+	lastUsed := make(map[string]time.Time, len(images))
+
+	candidates := make([]runtime.ImageInfo, 0, len(images))
+	for _, img := range images {
+		seen[img.ID] = true
+		if _, ok := m.firstSeen[img.ID]; !ok {
+			m.firstSeen[img.ID] = now
+		}
+
+		if img.Containers > 0 || isProtected(img, protected) {
+			continue
+		}
+
+		// Images never started since the agent came up are ordered by creation time,
+		// but are still kept for MinAge after they first appeared on the node
+		used, ok := m.markedUseLocked(img)
+		recent := m.firstSeen[img.ID]
+		if ok && used.After(recent) {
+			recent = used
+		}
+		if !ok {
+			used = img.Created
+		}
+		if now.Sub(recent) < m.config.MinAge {
+			continue
+		}
+
+		lastUsed[img.ID] = used
+		candidates = append(candidates, img)
+	}
+
+	for id := range m.firstSeen {
+		if !seen[id] {
+			delete(m.firstSeen, id)
+		}
+	}
+
+	sort.SliceStable(
+		candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if !lastUsed[a.ID].Equal(lastUsed[b.ID]) {
+				return lastUsed[a.ID].Before(lastUsed[b.ID])
+			}
+			return a.Created.Before(b.Created)
+		},
+	)
+
+	return candidates
+}
+
+// markedUseLocked returns the most recent recorded use of any of the image's names
+func (m *Manager) markedUseLocked(img runtime.ImageInfo) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for _, ref := range append([]string{img.ID}, img.RepoTags...) {
+		if used, ok := m.lastUsed[NormalizeReference(ref)]; ok {
+			if !found || used.After(latest) {
+				latest = used
+			}
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// forget drops usage records for a removed image
+func (m *Manager) forget(img runtime.ImageInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.firstSeen, img.ID)
+	delete(m.lastUsed, NormalizeReference(img.ID))
+	for _, tag := range img.RepoTags {
+		delete(m.lastUsed, NormalizeReference(tag))
+	}
+}
+
+// imageRootDir resolves and caches the directory holding the image store
+func (m *Manager) imageRootDir(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rootDir != "" {
+		return m.rootDir, nil
+	}
+
+	dir, err := m.client.RootDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	m.rootDir = dir
+	return dir, nil
+}
+
+// isProtected reports whether any of the image's names is referenced by a running container
+func isProtected(img runtime.ImageInfo, protected map[string]bool) bool {
+	if protected[NormalizeReference(img.ID)] {
+		return true
+	}
+	for _, tag := range img.RepoTags {
+		if protected[NormalizeReference(tag)] {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeReference converts an image reference to the short form used by
+// the local image store, e.g. "docker.io/library/nginx" becomes "nginx:latest"
+func NormalizeReference(ref string) string {
+	if strings.HasPrefix(ref, "sha256:") {
+		return ref
+	}
+
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "index.docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+
+	if strings.Contains(ref, "@") {
+		return ref
+	}
+
+	lastSegment := ref[strings.LastIndex(ref, "/")+1:]
+	if !strings.Contains(lastSegment, ":") {
+		ref += ":latest"
+	}
+	return ref
+}
@@ -0,0 +1,264 @@
+package imagegc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/docker"
+)
+
+type mockImageClient struct {
+	images    []docker.ImageInfo
+	removed   []string
+	removeErr map[string]error
+}
+
+func (m *mockImageClient) ListImages(_ context.Context) ([]docker.ImageInfo, error) {
+	return m.images, nil
+}
+
+func (m *mockImageClient) RemoveImage(_ context.Context, imageID string) error {
+	if err := m.removeErr[imageID]; err != nil {
+		return err
+	}
+	m.removed = append(m.removed, imageID)
+	return nil
+}
+
+func (m *mockImageClient) RootDir(_ context.Context) (string, error) {
+	return "/var/lib/docker", nil
+}
+
+const gib = int64(1 << 30)
+
+func newTestManager(client *mockImageClient, usedPercent uint64, inUse ...string) *Manager {
+	config := DefaultConfig()
+	config.MinAge = 0
+
+	m := NewManager(
+		client, config, func() []string {
+			return inUse
+		},
+	)
+	m.diskUsage = func(string) (DiskStats, error) {
+		capacity := uint64(100 * gib)
+		return DiskStats{CapacityBytes: capacity, AvailableBytes: capacity - usedPercent*uint64(gib)}, nil
+	}
+	return m
+}
+
+func TestGarbageCollect(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		usedPercent uint64
+		images      []docker.ImageInfo
+		inUse       []string
+		markUsed    []string
+		wantRemoved []string
+		wantErr     bool
+	}{
+		{
+			name:        "below high threshold does nothing",
+			usedPercent: 70,
+			images: []docker.ImageInfo{
+				{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 10 * gib, Created: base},
+			},
+			wantRemoved: nil,
+		},
+		{
+			name:        "evicts oldest created first when nothing was used",
+			usedPercent: 90,
+			images: []docker.ImageInfo{
+				{ID: "sha256:new", RepoTags: []string{"redis:7"}, Size: 6 * gib, Created: base.Add(time.Minute)},
+				{ID: "sha256:old", RepoTags: []string{"nginx:1.25"}, Size: 6 * gib, Created: base},
+			},
+			wantRemoved: []string{"sha256:old", "sha256:new"},
+		},
+		{
+			name:        "stops once low threshold is reached",
+			usedPercent: 90,
+			images: []docker.ImageInfo{
+				{ID: "sha256:old", RepoTags: []string{"nginx:1.25"}, Size: 12 * gib, Created: base},
+				{ID: "sha256:new", RepoTags: []string{"redis:7"}, Size: 6 * gib, Created: base.Add(time.Minute)},
+			},
+			wantRemoved: []string{"sha256:old"},
+		},
+		{
+			name:        "recently used images are evicted last",
+			usedPercent: 86,
+			images: []docker.ImageInfo{
+				{ID: "sha256:old", RepoTags: []string{"nginx:1.25"}, Size: 6 * gib, Created: base},
+				{ID: "sha256:new", RepoTags: []string{"redis:7"}, Size: 6 * gib, Created: base.Add(time.Minute)},
+			},
+			markUsed:    []string{"nginx:1.25"},
+			wantRemoved: []string{"sha256:new"},
+		},
+		{
+			name:        "images referenced by running pods are protected",
+			usedPercent: 95,
+			images: []docker.ImageInfo{
+				{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 20 * gib, Created: base},
+				{ID: "sha256:b", RepoTags: []string{"redis:7"}, Size: 20 * gib, Created: base},
+			},
+			inUse:       []string{"docker.io/library/nginx"},
+			wantRemoved: []string{"sha256:b"},
+		},
+		{
+			name:        "images with containers are protected",
+			usedPercent: 90,
+			images: []docker.ImageInfo{
+				{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 20 * gib, Created: base, Containers: 1},
+			},
+			wantRemoved: nil,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				client := &mockImageClient{images: tt.images}
+				m := newTestManager(client, tt.usedPercent, tt.inUse...)
+				for _, ref := range tt.markUsed {
+					m.MarkUsed(ref)
+				}
+
+				_, err := m.GarbageCollect(context.Background())
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("GarbageCollect() error = %v, wantErr %v", err, tt.wantErr)
+				}
+
+				if len(client.removed) != len(tt.wantRemoved) {
+					t.Fatalf("removed %v, want %v", client.removed, tt.wantRemoved)
+				}
+				for i := range tt.wantRemoved {
+					if client.removed[i] != tt.wantRemoved[i] {
+						t.Errorf("removed[%d] = %s, want %s", i, client.removed[i], tt.wantRemoved[i])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestGarbageCollectMinAge(t *testing.T) {
+	client := &mockImageClient{
+		images: []docker.ImageInfo{
+			{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 20 * gib, Created: time.Now().Add(-time.Hour)},
+		},
+	}
+	m := newTestManager(client, 90)
+	m.config.MinAge = time.Hour
+	m.MarkUsed("nginx")
+
+	if _, err := m.GarbageCollect(context.Background()); err == nil {
+		t.Error("expected error when no image is old enough to be removed")
+	}
+	if len(client.removed) != 0 {
+		t.Errorf("expected no images removed, got %v", client.removed)
+	}
+}
+
+func TestGarbageCollectRemoveFailure(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	client := &mockImageClient{
+		images: []docker.ImageInfo{
+			{ID: "sha256:a", RepoTags: []string{"nginx:latest"}, Size: 10 * gib, Created: base},
+			{ID: "sha256:b", RepoTags: []string{"redis:7"}, Size: 10 * gib, Created: base.Add(time.Minute)},
+		},
+		removeErr: map[string]error{"sha256:a": errors.New("conflict")},
+	}
+	m := newTestManager(client, 90)
+
+	freed, err := m.GarbageCollect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if freed != 10*gib {
+		t.Errorf("freed = %d, want %d", freed, 10*gib)
+	}
+	if len(client.removed) != 1 || client.removed[0] != "sha256:b" {
+		t.Errorf("removed = %v, want [sha256:b]", client.removed)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	client := &mockImageClient{
+		images: []docker.ImageInfo{
+			{ID: "sha256:small", RepoTags: []string{"busybox:latest"}, Size: 1},
+			{ID: "sha256:big", RepoTags: []string{"nginx:latest"}, Size: 100},
+		},
+	}
+	m := newTestManager(client, 0)
+	m.MarkUsed("nginx")
+
+	inventory, err := m.Inventory(context.Background())
+	if err != nil {
+		t.Fatalf("Inventory() error = %v", err)
+	}
+	if len(inventory) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(inventory))
+	}
+	if inventory[0].ID != "sha256:big" {
+		t.Errorf("expected largest image first, got %s", inventory[0].ID)
+	}
+	if inventory[0].LastUsed == nil {
+		t.Error("expected LastUsed to be set for used image")
+	}
+	if inventory[1].LastUsed != nil {
+		t.Error("expected LastUsed to be nil for unused image")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "default", config: DefaultConfig()},
+		{name: "low above high", config: Config{HighThresholdPercent: 50, LowThresholdPercent: 60, Interval: time.Minute}, wantErr: true},
+		{name: "high out of range", config: Config{HighThresholdPercent: 110, LowThresholdPercent: 60, Interval: time.Minute}, wantErr: true},
+		{name: "zero interval", config: Config{HighThresholdPercent: 85, LowThresholdPercent: 80}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestNormalizeReference(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"nginx", "nginx:latest"},
+		{"nginx:1.25", "nginx:1.25"},
+		{"docker.io/library/nginx:1.25", "nginx:1.25"},
+		{"myorg/app", "myorg/app:latest"},
+		{"localhost:5000/app", "localhost:5000/app:latest"},
+		{"registry.example.com:5000/team/app:v2", "registry.example.com:5000/team/app:v2"},
+		{"nginx@sha256:abc", "nginx@sha256:abc"},
+		{"sha256:abc", "sha256:abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.ref, func(t *testing.T) {
+				if got := NormalizeReference(tt.ref); got != tt.want {
+					t.Errorf("NormalizeReference(%q) = %q, want %q", tt.ref, got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,175 @@
+// Package logship forwards the container logs of a worker node to a log sink, such as the
+// master, local files or a Loki-compatible endpoint, so that they outlive the containers that wrote them.
+package logship
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Sink receives batches of log records
+type Sink interface {
+	Write(ctx context.Context, records []types.LogRecord) error
+}
+
+// Config controls how log records are buffered and batched
+type Config struct {
+	// BatchSize is the maximum number of records sent in one write
+	BatchSize int
+
+	// FlushInterval is how often buffered records are sent
+	FlushInterval time.Duration
+
+	// BufferSize is the maximum number of records held while the sink is unavailable;
+	// the oldest records are dropped when it is full
+	BufferSize int
+}
+
+// DefaultConfig returns the default shipping settings
+func DefaultConfig() Config {
+	return Config{
+		BatchSize:     500,
+		FlushInterval: 2 * time.Second,
+		BufferSize:    10000,
+	}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch size must be positive, got %d", c.BatchSize)
+	}
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("flush interval must be positive, got %v", c.FlushInterval)
+	}
+	if c.BufferSize < c.BatchSize {
+		return fmt.Errorf("buffer size (%d) must be at least the batch size (%d)", c.BufferSize, c.BatchSize)
+	}
+	return nil
+}
+
+// Shipper buffers log records and writes them to a sink in batches
+type Shipper struct {
+	sink    Sink
+	config  Config
+	mu      sync.Mutex
+	buffer  []types.LogRecord
+	dropped int64
+	flushMu sync.Mutex
+	full    chan struct{}
+}
+
+// NewShipper creates a shipper that writes to sink
+func NewShipper(sink Sink, config Config) *Shipper {
+	return &Shipper{
+		sink:   sink,
+		config: config,
+		full:   make(chan struct{}, 1),
+	}
+}
+
+// Config returns the shipper's settings
+func (s *Shipper) Config() Config {
+	return s.config
+}
+
+// Add buffers a record for shipping. Records without a timestamp are stamped with the current time.
+func (s *Shipper) Add(record types.LogRecord) {
+	if record.Timestamp == nil {
+		now := time.Now()
+		record.Timestamp = &now
+	}
+
+	s.mu.Lock()
+	s.buffer = append(s.buffer, record)
+	s.trimLocked()
+	batchReady := len(s.buffer) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if batchReady {
+		select {
+		case s.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of records dropped because the buffer was full
+func (s *Shipper) Dropped() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Flush writes all buffered records to the sink in batches. When a write fails the batch is
+// kept in the buffer for the next flush and the error is returned.
+func (s *Shipper) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	for {
+		s.mu.Lock()
+		n := min(len(s.buffer), s.config.BatchSize)
+		batch := append([]types.LogRecord(nil), s.buffer[:n]...)
+		s.buffer = s.buffer[n:]
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := s.sink.Write(ctx, batch); err != nil {
+			s.mu.Lock()
+			s.buffer = append(batch, s.buffer...)
+			s.trimLocked()
+			s.mu.Unlock()
+			return fmt.Errorf("failed to ship %d log records: %w", len(batch), err)
+		}
+	}
+}
+
+// Run flushes buffered records every FlushInterval, or as soon as a batch is full, until stop
+// is closed. Remaining records get a final flush before it returns.
+func (s *Shipper) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	failing := false
+	flush := func(timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := s.Flush(ctx)
+		switch {
+		case err != nil && !failing:
+			log.Printf("log shipping failed, buffering records: %v", err)
+		case err == nil && failing:
+			log.Printf("log shipping resumed")
+		}
+		failing = err != nil
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			flush(s.config.FlushInterval * 5)
+		case <-s.full:
+			flush(s.config.FlushInterval * 5)
+		case <-stop:
+			flush(5 * time.Second)
+			return
+		}
+	}
+}
+
+// trimLocked drops the oldest records beyond the buffer size
+func (s *Shipper) trimLocked() {
+	if excess := len(s.buffer) - s.config.BufferSize; excess > 0 {
+		s.buffer = s.buffer[excess:]
+		s.dropped += int64(excess)
+	}
+}
@@ -0,0 +1,139 @@
+package logship
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// recordingSink records written batches and fails while err is set
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]types.LogRecord
+	err     error
+}
+
+func (s *recordingSink) Write(_ context.Context, records []types.LogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, records)
+	return nil
+}
+
+func (s *recordingSink) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, batch := range s.batches {
+		for _, r := range batch {
+			lines = append(lines, r.Line)
+		}
+	}
+	return lines
+}
+
+func logRecord(line string) types.LogRecord {
+	return types.LogRecord{PodID: "pod-1", LogEntry: types.LogEntry{Stream: types.LogStreamStdout, Line: line}}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("DefaultConfig().Validate() error = %v", err)
+	}
+
+	invalid := []Config{
+		{BatchSize: 0, FlushInterval: time.Second, BufferSize: 10},
+		{BatchSize: 10, FlushInterval: 0, BufferSize: 10},
+		{BatchSize: 10, FlushInterval: time.Second, BufferSize: 5},
+	}
+	for _, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected an error", config)
+		}
+	}
+}
+
+func TestShipperFlushBatches(t *testing.T) {
+	sink := &recordingSink{}
+	shipper := NewShipper(sink, Config{BatchSize: 2, FlushInterval: time.Hour, BufferSize: 10})
+
+	for i := range 5 {
+		shipper.Add(logRecord(fmt.Sprintf("line %d", i)))
+	}
+	if err := shipper.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(sink.batches) != 3 {
+		t.Errorf("got %d batches, want 3", len(sink.batches))
+	}
+	if lines := sink.lines(); len(lines) != 5 || lines[0] != "line 0" || lines[4] != "line 4" {
+		t.Errorf("shipped lines = %v", lines)
+	}
+	if sink.batches[0][0].Timestamp == nil {
+		t.Error("expected records to be timestamped")
+	}
+}
+
+func TestShipperBuffersWhileSinkFails(t *testing.T) {
+	sink := &recordingSink{err: errors.New("connection refused")}
+	shipper := NewShipper(sink, Config{BatchSize: 2, FlushInterval: time.Hour, BufferSize: 3})
+
+	for i := range 4 {
+		shipper.Add(logRecord(fmt.Sprintf("line %d", i)))
+	}
+	if err := shipper.Flush(context.Background()); err == nil {
+		t.Fatal("expected Flush() to fail while the sink is down")
+	}
+	if dropped := shipper.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, want 1", dropped)
+	}
+
+	sink.mu.Lock()
+	sink.err = nil
+	sink.mu.Unlock()
+	if err := shipper.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	lines := sink.lines()
+	if len(lines) != 3 || lines[0] != "line 1" || lines[2] != "line 3" {
+		t.Errorf("shipped lines = %v, want the three newest in order", lines)
+	}
+}
+
+func TestShipperRunFlushesFullBatchesAndOnStop(t *testing.T) {
+	sink := &recordingSink{}
+	shipper := NewShipper(sink, Config{BatchSize: 2, FlushInterval: time.Hour, BufferSize: 10})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		shipper.Run(stop)
+		close(done)
+	}()
+
+	shipper.Add(logRecord("one"))
+	shipper.Add(logRecord("two"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.lines()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(sink.lines()) != 2 {
+		t.Fatalf("expected a full batch to be shipped without waiting for the interval, got %v", sink.lines())
+	}
+
+	shipper.Add(logRecord("three"))
+	close(stop)
+	<-done
+	if lines := sink.lines(); len(lines) != 3 {
+		t.Errorf("expected the final flush to ship the rest, got %v", lines)
+	}
+}
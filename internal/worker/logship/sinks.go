@@ -0,0 +1,261 @@
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// postJSON sends body as JSON and fails on a non-2xx response
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send log records: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("log sink returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// MasterSink ships log records to the master, which retains them for cluster-wide search
+type MasterSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewMasterSink creates a sink that posts to the master's log endpoint
+func NewMasterSink(masterURL string) *MasterSink {
+	return &MasterSink{
+		url:    strings.TrimSuffix(masterURL, "/") + "/api/v1/logs",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Write posts the records to the master
+func (s *MasterSink) Write(ctx context.Context, records []types.LogRecord) error {
+	return postJSON(ctx, s.client, s.url, types.LogBatch{Records: records})
+}
+
+// LokiSink pushes log records to a Loki-compatible push API
+type LokiSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewLokiSink creates a sink that pushes to the Loki server at baseURL
+func NewLokiSink(baseURL string) *LokiSink {
+	return &LokiSink{
+		url:    strings.TrimSuffix(baseURL, "/") + "/loki/api/v1/push",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// lokiStream is a set of lines sharing the same labels in a Loki push request
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Write groups the records into streams by their labels and pushes them
+func (s *LokiSink) Write(ctx context.Context, records []types.LogRecord) error {
+	streams := make(map[string]*lokiStream)
+	var order []string
+	for _, record := range records {
+		labels := lokiLabels(record)
+		key := labelKey(labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+		ts := strconv.FormatInt(record.Timestamp.UnixNano(), 10)
+		stream.Values = append(stream.Values, [2]string{ts, record.Line})
+	}
+
+	body := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{Streams: make([]*lokiStream, 0, len(order))}
+	for _, key := range order {
+		body.Streams = append(body.Streams, streams[key])
+	}
+	return postJSON(ctx, s.client, s.url, body)
+}
+
+// lokiLabels returns the stream labels of a record: the pod's labels plus its identity
+func lokiLabels(record types.LogRecord) map[string]string {
+	labels := make(map[string]string, len(record.Labels)+6)
+	for key, value := range record.Labels {
+		labels[lokiLabelName(key)] = value
+	}
+	labels["pod_id"] = record.PodID
+	labels["pod"] = record.PodName
+	labels["namespace"] = record.Namespace
+	labels["node"] = record.NodeID
+	labels["container"] = record.Container
+	labels["stream"] = record.Stream
+	for key, value := range labels {
+		if value == "" {
+			delete(labels, key)
+		}
+	}
+	return labels
+}
+
+// lokiLabelName replaces the characters Loki does not allow in label names with underscores
+func lokiLabelName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// labelKey returns a canonical string for a label set
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%q,", key, labels[key])
+	}
+	return b.String()
+}
+
+// FileSink writes log records to one newline-delimited JSON file per pod. A file is rotated
+// once it exceeds the per-pod size limit, and files not written for longer than the maximum
+// age are removed.
+type FileSink struct {
+	dir            string
+	maxBytesPerPod int64
+	maxAge         time.Duration
+	mu             sync.Mutex
+	lastPrune      time.Time
+	now            func() time.Time
+}
+
+// NewFileSink creates a sink that writes to dir, creating it if needed
+func NewFileSink(dir string, maxBytesPerPod int64, maxAge time.Duration) (*FileSink, error) {
+	if maxBytesPerPod <= 0 {
+		return nil, fmt.Errorf("max bytes per pod must be positive, got %d", maxBytesPerPod)
+	}
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("max age must be positive, got %v", maxAge)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	return &FileSink{dir: dir, maxBytesPerPod: maxBytesPerPod, maxAge: maxAge, now: time.Now}, nil
+}
+
+// Write appends the records to their pods' files
+func (s *FileSink) Write(_ context.Context, records []types.LogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byPod := make(map[string][]byte)
+	var order []string
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal log record: %w", err)
+		}
+		if _, ok := byPod[record.PodID]; !ok {
+			order = append(order, record.PodID)
+		}
+		byPod[record.PodID] = append(append(byPod[record.PodID], line...), '\n')
+	}
+
+	for _, podID := range order {
+		if err := s.appendToPod(podID, byPod[podID]); err != nil {
+			return err
+		}
+	}
+
+	if now := s.now(); now.Sub(s.lastPrune) >= time.Minute {
+		s.lastPrune = now
+		s.prune(now)
+	}
+	return nil
+}
+
+// PodLogPath returns the file the logs of a pod are written to
+func (s *FileSink) PodLogPath(podID string) string {
+	return filepath.Join(s.dir, filepath.Base(podID)+".log")
+}
+
+// appendToPod writes data to the pod's file, rotating it first if it is full
+func (s *FileSink) appendToPod(podID string, data []byte) error {
+	path := s.PodLogPath(podID)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(data)) > s.maxBytesPerPod {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate %s: %w", path, err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// prune removes log files that were last written before the maximum age
+func (s *FileSink) prune(now time.Time) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.Contains(entry.Name(), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) <= s.maxAge {
+			continue
+		}
+		_ = os.Remove(filepath.Join(s.dir, entry.Name()))
+	}
+}
@@ -0,0 +1,154 @@
+package logship
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestMasterSink(t *testing.T) {
+	var received types.LogBatch
+	var status atomic.Int32
+	status.Store(http.StatusNoContent)
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/v1/logs" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("decode: %v", err)
+				}
+				w.WriteHeader(int(status.Load()))
+			},
+		),
+	)
+	defer server.Close()
+
+	sink := NewMasterSink(server.URL)
+	if err := sink.Write(context.Background(), []types.LogRecord{logRecord("hello")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(received.Records) != 1 || received.Records[0].Line != "hello" || received.Records[0].PodID != "pod-1" {
+		t.Errorf("master received %+v", received)
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	if err := sink.Write(context.Background(), []types.LogRecord{logRecord("hello")}); err == nil {
+		t.Error("expected an error for a non-2xx response")
+	}
+}
+
+func TestLokiSink(t *testing.T) {
+	var body struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/loki/api/v1/push" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode: %v", err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			},
+		),
+	)
+	defer server.Close()
+
+	ts := time.Unix(1700000000, 5)
+	records := []types.LogRecord{
+		{
+			PodID: "pod-1", PodName: "web", Labels: map[string]string{"app.kubernetes.io/name": "web"},
+			LogEntry: types.LogEntry{Container: "app", Stream: types.LogStreamStdout, Timestamp: &ts, Line: "one"},
+		},
+		{
+			PodID: "pod-1", PodName: "web", Labels: map[string]string{"app.kubernetes.io/name": "web"},
+			LogEntry: types.LogEntry{Container: "app", Stream: types.LogStreamStdout, Timestamp: &ts, Line: "two"},
+		},
+		{
+			PodID: "pod-1", PodName: "web",
+			LogEntry: types.LogEntry{Container: "app", Stream: types.LogStreamStderr, Timestamp: &ts, Line: "oops"},
+		},
+	}
+	if err := NewLokiSink(server.URL).Write(context.Background(), records); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(body.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(body.Streams))
+	}
+	first := body.Streams[0]
+	labels := first.Stream
+	if labels["app_kubernetes_io_name"] != "web" || labels["pod"] != "web" || labels["stream"] != "stdout" {
+		t.Errorf("stream labels = %v", first.Stream)
+	}
+	if len(first.Values) != 2 || first.Values[0] != [2]string{"1700000000000000005", "one"} {
+		t.Errorf("stream values = %v", first.Values)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir, 200, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+
+	now := time.Now()
+	sink.now = func() time.Time { return now }
+
+	if err := sink.Write(context.Background(), []types.LogRecord{logRecord("first")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	f, err := os.Open(sink.PodLogPath("pod-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(f)
+	var record types.LogRecord
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &record) != nil || record.Line != "first" {
+		t.Errorf("file line = %q, want the JSON record", scanner.Text())
+	}
+	_ = f.Close()
+
+	// The file is rotated once the next write would exceed the size limit
+	for range 3 {
+		if err := sink.Write(context.Background(), []types.LogRecord{logRecord("more output")}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if _, err := os.Stat(sink.PodLogPath("pod-1") + ".1"); err != nil {
+		t.Errorf("expected a rotated file: %v", err)
+	}
+	if info, err := os.Stat(sink.PodLogPath("pod-1")); err != nil || info.Size() > 200 {
+		t.Errorf("current file = %v, %v, want at most 200 bytes", info, err)
+	}
+
+	// Files not written within the maximum age are removed on the next prune
+	stale := filepath.Join(dir, "old-pod.log")
+	if err := os.WriteFile(stale, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(stale, now.Add(-2*time.Hour), now.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	sink.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if err := sink.Write(context.Background(), []types.LogRecord{logRecord("later")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("expected the stale file to be removed, stat error = %v", err)
+	}
+}
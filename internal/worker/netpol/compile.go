@@ -0,0 +1,261 @@
+package netpol
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// podIPAnnotation is the annotation the worker records a pod's IP in
+const podIPAnnotation = "podling.io/pod-ip"
+
+// PodRules is the compiled policy of one pod on the node. Directions that no policy restricts
+// allow all traffic; restricted directions allow only traffic matching one of their rules.
+type PodRules struct {
+	// PodID is the pod the rules belong to
+	PodID string
+
+	// Pod is the namespaced pod name, for logging
+	Pod string
+
+	// IP is the pod's address
+	IP string
+
+	// IngressIsolated is set when a policy restricts traffic to the pod
+	IngressIsolated bool
+
+	// Ingress rules allow traffic from their peers to the pod
+	Ingress []Rule
+
+	// EgressIsolated is set when a policy restricts traffic from the pod
+	EgressIsolated bool
+
+	// Egress rules allow traffic from the pod to their peers
+	Egress []Rule
+}
+
+// Rule allows traffic with a set of remote addresses on a set of ports
+type Rule struct {
+	// AllPeers allows every remote address; otherwise only Peers are allowed
+	AllPeers bool
+
+	// Peers are the allowed remote address blocks, pod IPs as /32s
+	Peers []Block
+
+	// Ports are the allowed destination ports; empty allows every port and protocol
+	Ports []Port
+}
+
+// Block is a CIDR of addresses, minus the Except CIDRs inside it
+type Block struct {
+	CIDR   string
+	Except []string
+}
+
+// Port is a destination port or port range of a protocol
+type Port struct {
+	// Protocol is TCP, UDP or SCTP
+	Protocol string
+
+	// Port is the port number; zero matches every port of the protocol
+	Port int
+
+	// EndPort is the last port of a range, or zero
+	EndPort int
+}
+
+// Compile turns network policies into the rules of the running pods on nodeID that a policy
+// selects. Pod peers resolve to the IPs of running pods on any node. Pods without an IP and
+// rules whose peers resolve to nothing are skipped.
+func Compile(policies []types.NetworkPolicy, pods []types.Pod, nodeID string) []PodRules {
+	var running []types.Pod
+	for _, pod := range pods {
+		if pod.Status == types.PodRunning && podIP(pod) != "" {
+			running = append(running, pod)
+		}
+	}
+	slices.SortFunc(running, func(a, b types.Pod) int { return strings.Compare(a.PodID, b.PodID) })
+
+	// Sorting keeps the compiled rules stable however the master orders its lists
+	policies = slices.Clone(policies)
+	slices.SortFunc(
+		policies, func(a, b types.NetworkPolicy) int {
+			return strings.Compare(namespaceOf(a.Namespace)+"/"+a.Name, namespaceOf(b.Namespace)+"/"+b.Name)
+		},
+	)
+
+	var compiled []PodRules
+	for _, pod := range running {
+		if pod.NodeID != nodeID {
+			continue
+		}
+
+		rules := PodRules{
+			PodID: pod.PodID,
+			Pod:   namespaceOf(pod.Namespace) + "/" + pod.Name,
+			IP:    podIP(pod),
+		}
+		for _, policy := range policies {
+			if namespaceOf(policy.Namespace) != namespaceOf(pod.Namespace) || !policy.PodSelector.Matches(pod.Labels) {
+				continue
+			}
+			if policy.AppliesTo(types.PolicyTypeIngress) {
+				rules.IngressIsolated = true
+				for _, rule := range policy.Ingress {
+					rules.Ingress = appendRule(rules.Ingress, compileRule(policy, rule.From, rule.Ports, running))
+				}
+			}
+			if policy.AppliesTo(types.PolicyTypeEgress) {
+				rules.EgressIsolated = true
+				for _, rule := range policy.Egress {
+					rules.Egress = appendRule(rules.Egress, compileRule(policy, rule.To, rule.Ports, running))
+				}
+			}
+		}
+
+		if rules.IngressIsolated || rules.EgressIsolated {
+			compiled = append(compiled, rules)
+		}
+	}
+	return compiled
+}
+
+// AllowsIngress reports whether the rules let a connection from ip reach the pod's port
+func (r PodRules) AllowsIngress(ip, protocol string, port int) bool {
+	return !r.IngressIsolated || allows(r.Ingress, ip, protocol, port)
+}
+
+// AllowsEgress reports whether the rules let the pod connect to port on ip
+func (r PodRules) AllowsEgress(ip, protocol string, port int) bool {
+	return !r.EgressIsolated || allows(r.Egress, ip, protocol, port)
+}
+
+// allows reports whether any rule matches the remote address and destination port
+func allows(rules []Rule, ip, protocol string, port int) bool {
+	addr := net.ParseIP(ip)
+	for _, rule := range rules {
+		if rule.matchesPeer(addr) && rule.matchesPort(protocol, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPeer reports whether the address is one of the rule's peers
+func (r Rule) matchesPeer(addr net.IP) bool {
+	if r.AllPeers {
+		return true
+	}
+	for _, peer := range r.Peers {
+		if cidrContains(peer.CIDR, addr) && !slices.ContainsFunc(
+			peer.Except, func(except string) bool { return cidrContains(except, addr) },
+		) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPort reports whether the destination port is one of the rule's ports
+func (r Rule) matchesPort(protocol string, port int) bool {
+	if len(r.Ports) == 0 {
+		return true
+	}
+	for _, p := range r.Ports {
+		if !strings.EqualFold(p.Protocol, protocol) {
+			continue
+		}
+		end := p.EndPort
+		if end == 0 {
+			end = p.Port
+		}
+		if p.Port == 0 || (port >= p.Port && port <= end) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileRule resolves the peers and ports of one policy rule. A rule with peers that match no
+// address is returned without peers and without AllPeers, so it allows nothing.
+func compileRule(
+	policy types.NetworkPolicy, peers []types.NetworkPolicyPeer, ports []types.NetworkPolicyPort, pods []types.Pod,
+) Rule {
+	rule := Rule{AllPeers: len(peers) == 0}
+	for _, port := range ports {
+		protocol := strings.ToUpper(port.Protocol)
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		rule.Ports = append(rule.Ports, Port{Protocol: protocol, Port: port.Port, EndPort: port.EndPort})
+	}
+
+	seen := make(map[string]bool)
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			rule.Peers = append(rule.Peers, Block{CIDR: peer.IPBlock.CIDR, Except: peer.IPBlock.Except})
+			continue
+		}
+		for _, pod := range pods {
+			if !selectsPod(policy, peer, pod) {
+				continue
+			}
+			cidr := podIP(pod) + "/32"
+			if !seen[cidr] {
+				seen[cidr] = true
+				rule.Peers = append(rule.Peers, Block{CIDR: cidr})
+			}
+		}
+	}
+	return rule
+}
+
+// appendRule adds a rule that can match some traffic
+func appendRule(rules []Rule, rule Rule) []Rule {
+	if !rule.AllPeers && len(rule.Peers) == 0 {
+		return rules
+	}
+	return append(rules, rule)
+}
+
+// selectsPod reports whether a selector peer of the policy matches the pod
+func selectsPod(policy types.NetworkPolicy, peer types.NetworkPolicyPeer, pod types.Pod) bool {
+	if peer.NamespaceSelector == nil {
+		if namespaceOf(pod.Namespace) != namespaceOf(policy.Namespace) {
+			return false
+		}
+	} else if !peer.NamespaceSelector.Matches(namespaceLabels(pod.Namespace)) {
+		return false
+	}
+	return peer.PodSelector == nil || peer.PodSelector.Matches(pod.Labels)
+}
+
+// namespaceLabels returns the labels of a namespace, which only carry its name
+func namespaceLabels(namespace string) map[string]string {
+	return map[string]string{types.NamespaceNameLabel: namespaceOf(namespace)}
+}
+
+// namespaceOf returns the namespace, defaulting empty to "default"
+func namespaceOf(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
+// podIP returns the pod's IPv4 address, or "" if it has none
+func podIP(pod types.Pod) string {
+	ip := net.ParseIP(pod.Annotations[podIPAnnotation])
+	if ip == nil || ip.To4() == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// cidrContains reports whether the CIDR contains the address
+func cidrContains(cidr string, addr net.IP) bool {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	return err == nil && addr != nil && ipNet.Contains(addr)
+}
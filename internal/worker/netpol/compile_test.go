@@ -0,0 +1,150 @@
+package netpol
+
+import (
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func testPod(id, namespace, nodeID, ip string, labels map[string]string) types.Pod {
+	return types.Pod{
+		PodID:       id,
+		Name:        id,
+		Namespace:   namespace,
+		Labels:      labels,
+		Status:      types.PodRunning,
+		NodeID:      nodeID,
+		Annotations: map[string]string{podIPAnnotation: ip},
+	}
+}
+
+func selector(labels map[string]string) *types.LabelSelector {
+	return &types.LabelSelector{MatchLabels: labels}
+}
+
+func TestCompile(t *testing.T) {
+	pods := []types.Pod{
+		testPod("api", "default", "node-1", "10.244.0.2", map[string]string{"app": "api"}),
+		testPod("web", "default", "node-2", "10.244.4.2", map[string]string{"app": "web"}),
+		testPod("db", "default", "node-1", "10.244.0.18", map[string]string{"app": "db"}),
+		testPod("monitor", "ops", "node-2", "10.244.4.18", map[string]string{"app": "monitor"}),
+		testPod("web-ops", "ops", "node-2", "10.244.4.34", map[string]string{"app": "web"}),
+		testPod("cache", "default", "node-1", "10.244.0.34", nil),
+	}
+	pending := testPod("web-pending", "default", "node-2", "10.244.4.50", map[string]string{"app": "web"})
+	pending.Status = types.PodPending
+	pods = append(pods, pending)
+
+	policies := []types.NetworkPolicy{
+		{
+			Name:        "api",
+			Namespace:   "default",
+			PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Ingress: []types.NetworkPolicyIngressRule{
+				{
+					From:  []types.NetworkPolicyPeer{{PodSelector: selector(map[string]string{"app": "web"})}},
+					Ports: []types.NetworkPolicyPort{{Port: 8080}},
+				},
+				{
+					From: []types.NetworkPolicyPeer{
+						{NamespaceSelector: selector(map[string]string{types.NamespaceNameLabel: "ops"})},
+					},
+					Ports: []types.NetworkPolicyPort{{Protocol: "TCP", Port: 9000, EndPort: 9100}},
+				},
+			},
+		},
+		{
+			Name:        "db",
+			Namespace:   "default",
+			PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			PolicyTypes: []types.PolicyType{types.PolicyTypeIngress, types.PolicyTypeEgress},
+			Ingress: []types.NetworkPolicyIngressRule{
+				{From: []types.NetworkPolicyPeer{{PodSelector: selector(map[string]string{"app": "api"})}}},
+			},
+			Egress: []types.NetworkPolicyEgressRule{
+				{
+					To:    []types.NetworkPolicyPeer{{IPBlock: &types.IPBlock{CIDR: "0.0.0.0/0", Except: []string{"10.0.0.0/8"}}}},
+					Ports: []types.NetworkPolicyPort{{Protocol: "UDP", Port: 53}},
+				},
+			},
+		},
+		{
+			Name:        "other-namespace",
+			Namespace:   "ops",
+			PodSelector: types.LabelSelector{},
+		},
+	}
+
+	compiled := Compile(policies, pods, "node-1")
+	if len(compiled) != 2 || compiled[0].PodID != "api" || compiled[1].PodID != "db" {
+		t.Fatalf("Compile() = %+v, want rules for api and db only", compiled)
+	}
+	api, db := compiled[0], compiled[1]
+
+	tests := []struct {
+		name     string
+		allowed  bool
+		evaluate func() bool
+	}{
+		{"web to api port", true, func() bool { return api.AllowsIngress("10.244.4.2", "TCP", 8080) }},
+		{"web to api other port", false, func() bool { return api.AllowsIngress("10.244.4.2", "TCP", 22) }},
+		{"web over UDP", false, func() bool { return api.AllowsIngress("10.244.4.2", "UDP", 8080) }},
+		{"pending web pod", false, func() bool { return api.AllowsIngress("10.244.4.50", "TCP", 8080) }},
+		{"web in another namespace", false, func() bool { return api.AllowsIngress("10.244.4.34", "TCP", 8080) }},
+		{"ops namespace port range", true, func() bool { return api.AllowsIngress("10.244.4.18", "TCP", 9050) }},
+		{"ops namespace outside range", false, func() bool { return api.AllowsIngress("10.244.4.18", "TCP", 9101) }},
+		{"unlabelled pod", false, func() bool { return api.AllowsIngress("10.244.0.34", "TCP", 8080) }},
+		{"api egress not restricted", true, func() bool { return api.AllowsEgress("10.244.0.18", "TCP", 5432) }},
+		{"api to db any port", true, func() bool { return db.AllowsIngress("10.244.0.2", "TCP", 5432) }},
+		{"web to db", false, func() bool { return db.AllowsIngress("10.244.4.2", "TCP", 5432) }},
+		{"db to external DNS", true, func() bool { return db.AllowsEgress("8.8.8.8", "UDP", 53) }},
+		{"db to cluster DNS", false, func() bool { return db.AllowsEgress("10.96.0.10", "UDP", 53) }},
+		{"db to external HTTPS", false, func() bool { return db.AllowsEgress("8.8.8.8", "TCP", 443) }},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := tt.evaluate(); got != tt.allowed {
+					t.Errorf("allowed = %v, want %v", got, tt.allowed)
+				}
+			},
+		)
+	}
+}
+
+func TestCompileDenyAll(t *testing.T) {
+	pods := []types.Pod{
+		testPod("api", "default", "node-1", "10.244.0.2", map[string]string{"app": "api"}),
+		testPod("web", "default", "node-1", "10.244.0.18", map[string]string{"app": "web"}),
+	}
+	policies := []types.NetworkPolicy{
+		{Name: "deny-all", PodSelector: types.LabelSelector{}},
+		{
+			// A peer that selects no pod adds nothing to the allowed traffic
+			Name:        "from-missing",
+			PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Ingress: []types.NetworkPolicyIngressRule{
+				{From: []types.NetworkPolicyPeer{{PodSelector: selector(map[string]string{"app": "missing"})}}},
+			},
+		},
+	}
+
+	compiled := Compile(policies, pods, "node-1")
+	if len(compiled) != 2 {
+		t.Fatalf("Compile() = %+v, want both pods isolated", compiled)
+	}
+	for _, rules := range compiled {
+		if !rules.IngressIsolated || len(rules.Ingress) != 0 || rules.EgressIsolated {
+			t.Errorf("rules = %+v, want ingress isolated without rules", rules)
+		}
+		if rules.AllowsIngress("10.244.0.2", "TCP", 80) {
+			t.Errorf("%s allows ingress, want it denied", rules.Pod)
+		}
+	}
+
+	// Pods without an IP are skipped
+	delete(pods[0].Annotations, podIPAnnotation)
+	if compiled := Compile(policies, pods, "node-1"); len(compiled) != 1 || compiled[0].PodID != "web" {
+		t.Errorf("Compile() = %+v, want only the pod with an IP", compiled)
+	}
+}
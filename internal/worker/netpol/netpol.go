@@ -0,0 +1,141 @@
+// Package netpol enforces network policies on a worker node. It watches policies and pods on
+// the master, compiles them into allow rules for the pods running on the node and installs the
+// rules with nftables. Compilation is pure, so policy evaluation can be tested without root.
+package netpol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Config controls how policies are watched and enforced
+type Config struct {
+	// NodeID is the node whose pods the policies are enforced for
+	NodeID string
+
+	// SyncInterval is how often policies and pods are fetched from the master
+	SyncInterval time.Duration
+}
+
+// DefaultConfig returns the default enforcement settings
+func DefaultConfig() Config {
+	return Config{SyncInterval: 5 * time.Second}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.NodeID == "" {
+		return errors.New("node ID is required")
+	}
+	if c.SyncInterval <= 0 {
+		return fmt.Errorf("sync interval must be positive, got %v", c.SyncInterval)
+	}
+	return nil
+}
+
+// Enforcer filters pod traffic according to compiled rules
+type Enforcer interface {
+	// Sync replaces the enforced rules
+	Sync(rules []PodRules) error
+
+	// Close stops enforcing and removes anything installed on the node
+	Close() error
+}
+
+// Controller keeps an enforcer in sync with the policies and pods on the master
+type Controller struct {
+	source   Source
+	enforcer Enforcer
+	config   Config
+
+	mu    sync.Mutex
+	rules []PodRules
+}
+
+// New creates a controller that fetches from source and applies the rules with enforcer
+func New(source Source, enforcer Enforcer, config Config) *Controller {
+	return &Controller{
+		source:   source,
+		enforcer: enforcer,
+		config:   config,
+	}
+}
+
+// Sync fetches policies and pods and enforces them. When the master cannot be reached the
+// current rules are kept, so pods stay isolated.
+func (c *Controller) Sync(ctx context.Context) error {
+	policies, err := c.source.ListNetworkPolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list network policies: %w", err)
+	}
+	pods, err := c.source.ListPods(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	rules := Compile(policies, pods, c.config.NodeID)
+	err = c.enforcer.Sync(rules)
+
+	c.mu.Lock()
+	c.rules = rules
+	c.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to apply network policy rules: %w", err)
+	}
+	return nil
+}
+
+// Rules returns the rules compiled by the last sync
+func (c *Controller) Rules() []PodRules {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]PodRules(nil), c.rules...)
+}
+
+// Allows reports whether the compiled rules let a connection from clientIP reach port on
+// endpointIP: the client pod's egress rules and the endpoint pod's ingress rules must both allow
+// it. Only pods on the node have rules, so the other side is not checked when it is elsewhere.
+// The service proxy uses it for connections it relays, which the nftables rules never see.
+func (c *Controller) Allows(clientIP, endpointIP, protocol string, port int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rules := range c.rules {
+		if rules.IP == clientIP && !rules.AllowsEgress(endpointIP, protocol, port) {
+			return false
+		}
+		if rules.IP == endpointIP && !rules.AllowsIngress(clientIP, protocol, port) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run syncs immediately and then every sync interval until ctx is done, then closes the enforcer
+func (c *Controller) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		syncCtx, cancel := context.WithTimeout(ctx, c.config.SyncInterval)
+		if err := c.Sync(syncCtx); err != nil {
+			log.Printf("network policy sync failed: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			if err := c.enforcer.Close(); err != nil {
+				log.Printf("failed to clean up network policy rules: %v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
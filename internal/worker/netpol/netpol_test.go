@@ -0,0 +1,168 @@
+package netpol
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+type fakeSource struct {
+	policies []types.NetworkPolicy
+	pods     []types.Pod
+	err      error
+}
+
+func (s *fakeSource) ListNetworkPolicies(context.Context) ([]types.NetworkPolicy, error) {
+	return s.policies, s.err
+}
+
+func (s *fakeSource) ListPods(context.Context) ([]types.Pod, error) {
+	return s.pods, s.err
+}
+
+type fakeEnforcer struct {
+	synced [][]PodRules
+	closed bool
+}
+
+func (e *fakeEnforcer) Sync(rules []PodRules) error {
+	e.synced = append(e.synced, rules)
+	return nil
+}
+
+func (e *fakeEnforcer) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestControllerSync(t *testing.T) {
+	source := &fakeSource{
+		policies: []types.NetworkPolicy{{Name: "deny-all"}},
+		pods: []types.Pod{
+			testPod("api", "default", "node-1", "10.244.0.2", nil),
+			testPod("web", "default", "node-2", "10.244.4.2", nil),
+		},
+	}
+	enforcer := &fakeEnforcer{}
+	config := DefaultConfig()
+	config.NodeID = "node-1"
+	controller := New(source, enforcer, config)
+
+	if err := controller.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if rules := controller.Rules(); len(rules) != 1 || rules[0].PodID != "api" {
+		t.Errorf("Rules() = %+v, want the local pod only", rules)
+	}
+
+	// Rules are kept while the master is unreachable
+	source.err = errors.New("connection refused")
+	if err := controller.Sync(context.Background()); err == nil {
+		t.Error("Sync() without the master succeeded, want an error")
+	}
+	if len(enforcer.synced) != 1 || len(controller.Rules()) != 1 {
+		t.Errorf("synced %d times with rules %+v, want the rules kept", len(enforcer.synced), controller.Rules())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	controller.Run(ctx)
+	if !enforcer.closed {
+		t.Error("Run() returned without closing the enforcer")
+	}
+}
+
+func TestControllerAllows(t *testing.T) {
+	source := &fakeSource{
+		policies: []types.NetworkPolicy{
+			{
+				Name:        "api",
+				PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+				Ingress: []types.NetworkPolicyIngressRule{
+					{
+						From:  []types.NetworkPolicyPeer{{PodSelector: selector(map[string]string{"app": "web"})}},
+						Ports: []types.NetworkPolicyPort{{Port: 8080}},
+					},
+				},
+			},
+			{
+				Name:        "web",
+				PodSelector: types.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				PolicyTypes: []types.PolicyType{types.PolicyTypeEgress},
+				Egress: []types.NetworkPolicyEgressRule{
+					{To: []types.NetworkPolicyPeer{{PodSelector: selector(map[string]string{"app": "api"})}}},
+				},
+			},
+		},
+		pods: []types.Pod{
+			testPod("api", "default", "node-1", "10.244.0.2", map[string]string{"app": "api"}),
+			testPod("web", "default", "node-1", "10.244.0.18", map[string]string{"app": "web"}),
+			testPod("cache", "default", "node-1", "10.244.0.34", nil),
+			testPod("remote", "default", "node-2", "10.244.4.2", nil),
+		},
+	}
+	config := DefaultConfig()
+	config.NodeID = "node-1"
+	controller := New(source, &fakeEnforcer{}, config)
+
+	if !controller.Allows("10.244.0.34", "10.244.0.2", "TCP", 8080) {
+		t.Error("connection allowed before the first sync was denied")
+	}
+	if err := controller.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		client     string
+		endpoint   string
+		port       int
+		wantAllows bool
+	}{
+		{name: "web to api port", client: "10.244.0.18", endpoint: "10.244.0.2", port: 8080, wantAllows: true},
+		{name: "web to api other port", client: "10.244.0.18", endpoint: "10.244.0.2", port: 22},
+		{name: "unselected pod to isolated api", client: "10.244.0.34", endpoint: "10.244.0.2", port: 8080},
+		{name: "node port client to isolated api", client: "192.168.1.10", endpoint: "10.244.0.2", port: 8080},
+		{name: "web to cache past its egress", client: "10.244.0.18", endpoint: "10.244.0.34", port: 80},
+		{name: "cache to remote pod", client: "10.244.0.34", endpoint: "10.244.4.2", port: 80, wantAllows: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := controller.Allows(tt.client, tt.endpoint, "TCP", tt.port); got != tt.wantAllows {
+					t.Errorf("Allows() = %v, want %v", got, tt.wantAllows)
+				}
+			},
+		)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{name: "missing node ID", modify: func(c *Config) { c.NodeID = "" }, wantErr: true},
+		{name: "zero sync interval", modify: func(c *Config) { c.SyncInterval = 0 }, wantErr: true},
+		{name: "negative sync interval", modify: func(c *Config) { c.SyncInterval = -time.Second }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := DefaultConfig()
+				config.NodeID = "node-1"
+				tt.modify(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
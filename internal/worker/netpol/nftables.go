@@ -0,0 +1,181 @@
+package netpol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNFTablesTable is the nftables table the enforcer owns
+const DefaultNFTablesTable = "podling-netpol"
+
+// NFTables enforces compiled policies with nftables rules in the forward hook, which sees all
+// routed traffic between pod networks, local or through the overlay. Each isolated pod gets an
+// ingress and an egress chain that return for allowed traffic and drop the rest; replies of
+// allowed connections are accepted by connection tracking. The whole table is replaced
+// atomically on every change.
+type NFTables struct {
+	table string
+	run   func(ctx context.Context, stdin []byte, args ...string) ([]byte, error)
+
+	mu      sync.Mutex
+	applied string
+}
+
+var _ Enforcer = (*NFTables)(nil)
+
+// NewNFTables creates an nftables enforcer using the nft binary
+func NewNFTables() (*NFTables, error) {
+	if _, err := exec.LookPath("nft"); err != nil {
+		return nil, fmt.Errorf("failed to find nft: %w", err)
+	}
+	return &NFTables{table: DefaultNFTablesTable, run: runNFT}, nil
+}
+
+// runNFT executes nft with stdin attached and returns its standard output
+func runNFT(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "nft", args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("nft %s: %s", strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("nft %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Sync replaces the enforcer's table with one generated from rules
+func (n *NFTables) Sync(rules []PodRules) error {
+	ruleset := renderNFTables(n.table, rules)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ruleset == n.applied {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := n.run(ctx, []byte(ruleset), "-f", "-"); err != nil {
+		return err
+	}
+	n.applied = ruleset
+	return nil
+}
+
+// Close deletes the enforcer's table
+func (n *NFTables) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	n.applied = ""
+	_, err := n.run(ctx, nil, "delete", "table", "ip", n.table)
+	return err
+}
+
+// renderNFTables generates an nft script that atomically replaces table with the rules
+func renderNFTables(table string, rules []PodRules) string {
+	var b strings.Builder
+
+	// Adding the table first makes the delete succeed when it does not exist yet
+	fmt.Fprintf(&b, "add table ip %s\n", table)
+	fmt.Fprintf(&b, "delete table ip %s\n", table)
+	fmt.Fprintf(&b, "table ip %s {\n", table)
+
+	// Egress is checked before ingress, so traffic between two isolated pods on the node must
+	// be allowed by both
+	var jumps []string
+	for i, pod := range rules {
+		if pod.EgressIsolated {
+			chain := fmt.Sprintf("pod-%d-egress", i)
+			writePodChain(&b, chain, pod, "daddr", pod.Egress)
+			jumps = append(jumps, fmt.Sprintf("ip saddr %s jump %s", pod.IP, chain))
+		}
+	}
+	for i, pod := range rules {
+		if pod.IngressIsolated {
+			chain := fmt.Sprintf("pod-%d-ingress", i)
+			writePodChain(&b, chain, pod, "saddr", pod.Ingress)
+			jumps = append(jumps, fmt.Sprintf("ip daddr %s jump %s", pod.IP, chain))
+		}
+	}
+
+	b.WriteString("\tchain forward {\n")
+	b.WriteString("\t\ttype filter hook forward priority filter - 5; policy accept;\n")
+	b.WriteString("\t\tct state established,related accept\n")
+	for _, jump := range jumps {
+		fmt.Fprintf(&b, "\t\t%s\n", jump)
+	}
+	b.WriteString("\t}\n")
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writePodChain writes a chain that returns for traffic one of the rules allows and drops the
+// rest. peer is the address field of the remote side: saddr for ingress, daddr for egress.
+func writePodChain(b *strings.Builder, chain string, pod PodRules, peer string, rules []Rule) {
+	fmt.Fprintf(b, "\tchain %s {\n", chain)
+	fmt.Fprintf(b, "\t\t# %s\n", pod.Pod)
+	for _, rule := range rules {
+		peers := []string{""}
+		if !rule.AllPeers {
+			peers = peers[:0]
+			for _, block := range rule.Peers {
+				peers = append(peers, nftBlock(peer, block))
+			}
+		}
+		ports := []string{""}
+		if len(rule.Ports) > 0 {
+			ports = ports[:0]
+			for _, port := range rule.Ports {
+				ports = append(ports, nftPort(port))
+			}
+		}
+
+		for _, peerMatch := range peers {
+			for _, portMatch := range ports {
+				fmt.Fprintf(b, "\t\t%s\n", strings.Join(strings.Fields(peerMatch+" "+portMatch+" return"), " "))
+			}
+		}
+	}
+	b.WriteString("\t\tdrop\n")
+	b.WriteString("\t}\n")
+}
+
+// nftBlock matches the remote address against a block
+func nftBlock(field string, block Block) string {
+	match := fmt.Sprintf("ip %s %s", field, block.CIDR)
+	if len(block.Except) > 0 {
+		match += fmt.Sprintf(" ip %s != { %s }", field, strings.Join(block.Except, ", "))
+	}
+	return match
+}
+
+// nftPort matches the destination port
+func nftPort(port Port) string {
+	protocol := strings.ToLower(port.Protocol)
+	switch {
+	case port.Port == 0:
+		return "meta l4proto " + protocol
+	case port.EndPort > port.Port:
+		return fmt.Sprintf("%s dport %d-%d", protocol, port.Port, port.EndPort)
+	default:
+		return fmt.Sprintf("%s dport %d", protocol, port.Port)
+	}
+}
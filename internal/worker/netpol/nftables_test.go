@@ -0,0 +1,82 @@
+package netpol
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderNFTables(t *testing.T) {
+	rules := []PodRules{
+		{
+			Pod:             "default/api",
+			IP:              "10.244.0.2",
+			IngressIsolated: true,
+			Ingress: []Rule{
+				{
+					Peers: []Block{{CIDR: "10.244.4.2/32"}, {CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}},
+					Ports: []Port{{Protocol: "TCP", Port: 8080}, {Protocol: "TCP", Port: 9000, EndPort: 9100}},
+				},
+			},
+		},
+		{
+			Pod:             "default/db",
+			IP:              "10.244.0.18",
+			IngressIsolated: true,
+			EgressIsolated:  true,
+			Egress:          []Rule{{AllPeers: true, Ports: []Port{{Protocol: "UDP"}}}},
+		},
+	}
+	ruleset := renderNFTables("podling-netpol", rules)
+
+	want := []string{
+		"add table ip podling-netpol\ndelete table ip podling-netpol\ntable ip podling-netpol {",
+		"chain pod-0-ingress {\n\t\t# default/api\n",
+		"ip saddr 10.244.4.2/32 tcp dport 8080 return\n",
+		"ip saddr 10.244.4.2/32 tcp dport 9000-9100 return\n",
+		"ip saddr 10.0.0.0/8 ip saddr != { 10.1.0.0/16 } tcp dport 8080 return\n",
+		"chain pod-1-egress {\n\t\t# default/db\n\t\tmeta l4proto udp return\n\t\tdrop\n\t}",
+		"chain pod-1-ingress {\n\t\t# default/db\n\t\tdrop\n\t}",
+		"type filter hook forward priority filter - 5; policy accept;\n" +
+			"\t\tct state established,related accept\n" +
+			"\t\tip saddr 10.244.0.18 jump pod-1-egress\n" +
+			"\t\tip daddr 10.244.0.2 jump pod-0-ingress\n" +
+			"\t\tip daddr 10.244.0.18 jump pod-1-ingress\n",
+	}
+	for _, fragment := range want {
+		if !strings.Contains(ruleset, fragment) {
+			t.Errorf("ruleset is missing %q:\n%s", fragment, ruleset)
+		}
+	}
+	if strings.Contains(ruleset, "pod-0-egress") {
+		t.Errorf("ruleset has an egress chain for a pod without egress isolation:\n%s", ruleset)
+	}
+}
+
+func TestNFTablesSync(t *testing.T) {
+	var calls [][]string
+	n := &NFTables{
+		table: DefaultNFTablesTable,
+		run: func(_ context.Context, stdin []byte, args ...string) ([]byte, error) {
+			calls = append(calls, append([]string{string(stdin)}, args...))
+			return nil, nil
+		},
+	}
+
+	rules := []PodRules{{Pod: "default/api", IP: "10.244.0.2", IngressIsolated: true}}
+	for i := 0; i < 2; i++ {
+		if err := n.Sync(rules); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+	if len(calls) != 1 || calls[0][1] != "-f" {
+		t.Fatalf("calls = %v, want one nft -f - for an unchanged ruleset", calls)
+	}
+
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Join(calls[1][1:], " "); got != "delete table ip podling-netpol" {
+		t.Errorf("Close() ran nft %s, want delete table ip podling-netpol", got)
+	}
+}
@@ -0,0 +1,72 @@
+package netpol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Source provides the policies and pods the rules are compiled from
+type Source interface {
+	ListNetworkPolicies(ctx context.Context) ([]types.NetworkPolicy, error)
+	ListPods(ctx context.Context) ([]types.Pod, error)
+}
+
+// MasterSource reads policies and pods from the master API
+type MasterSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewMasterSource creates a source backed by the master at masterURL
+func NewMasterSource(masterURL string) *MasterSource {
+	return &MasterSource{
+		url:    strings.TrimSuffix(masterURL, "/") + "/api/v1",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ListNetworkPolicies returns the network policies in all namespaces
+func (s *MasterSource) ListNetworkPolicies(ctx context.Context) ([]types.NetworkPolicy, error) {
+	var policies []types.NetworkPolicy
+	if err := s.get(ctx, "/networkpolicies", &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// ListPods returns the pods in all namespaces
+func (s *MasterSource) ListPods(ctx context.Context) ([]types.Pod, error) {
+	var pods []types.Pod
+	if err := s.get(ctx, "/pods", &pods); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+// get decodes the JSON response of a master API path into v
+func (s *MasterSource) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach master: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("master returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package overlay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Source provides the nodes whose pod subnets the overlay connects
+type Source interface {
+	ListNodes(ctx context.Context) ([]types.Node, error)
+}
+
+// MasterSource reads nodes from the master API
+type MasterSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewMasterSource creates a source backed by the master at masterURL
+func NewMasterSource(masterURL string) *MasterSource {
+	return &MasterSource{
+		url:    strings.TrimSuffix(masterURL, "/") + "/api/v1",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ListNodes returns all registered nodes
+func (s *MasterSource) ListNodes(ctx context.Context) ([]types.Node, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"/nodes", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach master: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("master returned status %d for /nodes", resp.StatusCode)
+	}
+
+	var nodes []types.Node
+	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
+		return nil, fmt.Errorf("failed to decode /nodes: %w", err)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,372 @@
+// Package overlay makes pod IPs routable between worker nodes. The master assigns every node a
+// subnet of the cluster pod CIDR; the overlay connects the subnets through a VXLAN device and
+// keeps its routes, neighbours and forwarding entries in sync with the nodes on the master.
+package overlay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// ruleComment marks the iptables rules the overlay installs
+const ruleComment = "podling-overlay"
+
+// Config controls the VXLAN device and how often it is synced
+type Config struct {
+	// Interface is the name of the VXLAN device
+	Interface string
+
+	// VNI is the VXLAN network identifier; it must be the same on every node
+	VNI int
+
+	// Port is the UDP port VXLAN packets are exchanged on; it must be the same on every node
+	Port int
+
+	// MTU of the VXLAN device; pod interfaces must not be larger
+	MTU int
+
+	// ClusterCIDR is the cluster pod CIDR; traffic within it is forwarded without masquerading
+	ClusterCIDR string
+
+	// SyncInterval is how often nodes are fetched from the master
+	SyncInterval time.Duration
+
+	// NodeID is the ID this node is registered under
+	NodeID string
+
+	// PodCIDR is the pod subnet the master assigned to this node
+	PodCIDR string
+
+	// LocalIP is the address other nodes send this node's VXLAN packets to
+	LocalIP string
+}
+
+// DefaultConfig returns the default overlay settings; the node's identity is filled in after it
+// has registered
+func DefaultConfig() Config {
+	return Config{
+		Interface:    "podling-vxlan",
+		VNI:          1,
+		Port:         4789,
+		MTU:          1450,
+		ClusterCIDR:  "10.244.0.0/16",
+		SyncInterval: 10 * time.Second,
+	}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.Interface == "" || len(c.Interface) > 15 {
+		return fmt.Errorf("interface name %q must be 1 to 15 characters", c.Interface)
+	}
+	if c.VNI < 1 || c.VNI > 1<<24-1 {
+		return fmt.Errorf("VNI must be between 1 and %d, got %d", 1<<24-1, c.VNI)
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
+	}
+	if c.MTU < 576 {
+		return fmt.Errorf("MTU must be at least 576, got %d", c.MTU)
+	}
+	if c.SyncInterval <= 0 {
+		return fmt.Errorf("sync interval must be positive, got %v", c.SyncInterval)
+	}
+	_, cluster, err := net.ParseCIDR(c.ClusterCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid cluster CIDR: %w", err)
+	}
+	if c.NodeID == "" {
+		return errors.New("node ID is required")
+	}
+	podIP, _, err := net.ParseCIDR(c.PodCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid pod CIDR: %w", err)
+	}
+	if !cluster.Contains(podIP) {
+		return fmt.Errorf("pod CIDR %s is outside the cluster CIDR %s", c.PodCIDR, c.ClusterCIDR)
+	}
+	if ip := net.ParseIP(c.LocalIP); ip == nil || ip.To4() == nil {
+		return fmt.Errorf("local IP %q is not an IPv4 address", c.LocalIP)
+	}
+	return nil
+}
+
+// VXLAN connects the node's pod subnet to the other nodes' through a VXLAN device. Every node's
+// device has the first address of its pod subnet and a MAC derived from it, so a peer's subnet is
+// routed through that address without learning or flooding. The device and its routes are left
+// in place when the worker stops, so pods stay reachable while it restarts.
+type VXLAN struct {
+	config Config
+	source Source
+	run    func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+	mu    sync.Mutex
+	ready bool
+	peers map[string]string // pod CIDR -> node IP of the installed peers
+}
+
+// NewVXLAN creates an overlay that fetches nodes from source
+func NewVXLAN(source Source, config Config) *VXLAN {
+	return &VXLAN{config: config, source: source, run: runCommand}
+}
+
+// runCommand executes name with args and returns its standard output
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Run syncs immediately and then every sync interval until ctx is done
+func (v *VXLAN) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		syncCtx, cancel := context.WithTimeout(ctx, v.config.SyncInterval)
+		if err := v.Sync(syncCtx); err != nil {
+			log.Printf("overlay sync failed: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync sets up the device on first use and routes the pod subnets of the other nodes through it.
+// When the master cannot be reached the installed peers are kept.
+func (v *VXLAN) Sync(ctx context.Context) error {
+	nodes, err := v.source.ListNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	wanted := peers(nodes, v.config.NodeID)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.ready {
+		if err := v.setup(ctx); err != nil {
+			return err
+		}
+		installed, err := v.listPeers(ctx)
+		if err != nil {
+			return err
+		}
+		v.peers = installed
+		v.ready = true
+	}
+
+	var errs []error
+	for _, cidr := range slices.Sorted(maps.Keys(v.peers)) {
+		if _, ok := wanted[cidr]; ok {
+			continue
+		}
+		if err := v.removePeer(ctx, cidr); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(v.peers, cidr)
+	}
+	for _, cidr := range slices.Sorted(maps.Keys(wanted)) {
+		nodeIP := wanted[cidr]
+		if v.peers[cidr] == nodeIP {
+			continue
+		}
+		if err := v.addPeer(ctx, cidr, nodeIP); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		v.peers[cidr] = nodeIP
+	}
+	return errors.Join(errs...)
+}
+
+// Peers returns the installed peers by pod CIDR
+func (v *VXLAN) Peers() map[string]string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	installed := make(map[string]string, len(v.peers))
+	for cidr, nodeIP := range v.peers {
+		installed[cidr] = nodeIP
+	}
+	return installed
+}
+
+// peers maps the pod CIDR of every other node that has one to the node's internal IP
+func peers(nodes []types.Node, self string) map[string]string {
+	wanted := make(map[string]string)
+	for _, node := range nodes {
+		if node.NodeID == self || node.PodCIDR == "" || node.InternalIP == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(node.PodCIDR); err == nil {
+			wanted[ipNet.String()] = node.InternalIP
+		}
+	}
+	return wanted
+}
+
+// setup creates the VXLAN device, gives it the first address of the node's pod CIDR and lets
+// cluster traffic pass between pod networks without being masqueraded
+func (v *VXLAN) setup(ctx context.Context) error {
+	link := v.config.Interface
+	address, err := deviceIP(v.config.PodCIDR)
+	if err != nil {
+		return err
+	}
+
+	if _, err := v.run(ctx, "ip", "link", "show", "dev", link); err != nil {
+		_, err := v.run(
+			ctx, "ip", "link", "add", link, "type", "vxlan", "id", strconv.Itoa(v.config.VNI),
+			"dstport", strconv.Itoa(v.config.Port), "local", v.config.LocalIP, "nolearning",
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create VXLAN device: %w", err)
+		}
+	}
+	_, err = v.run(
+		ctx, "ip", "link", "set", "dev", link, "address", deviceMAC(address), "mtu", strconv.Itoa(v.config.MTU), "up",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to configure VXLAN device: %w", err)
+	}
+	if _, err := v.run(ctx, "ip", "addr", "replace", address.String()+"/32", "dev", link); err != nil {
+		return fmt.Errorf("failed to assign VXLAN device address: %w", err)
+	}
+
+	// Docker isolates bridge networks from each other in FORWARD; DOCKER-USER is evaluated first
+	forward := "FORWARD"
+	if _, err := v.run(ctx, "iptables", "-w", "-n", "-L", "DOCKER-USER"); err == nil {
+		forward = "DOCKER-USER"
+	}
+	cluster := v.config.ClusterCIDR
+	if err := v.ensureRule(ctx, "filter", forward, "-s", cluster, "-d", cluster, "-j", "ACCEPT"); err != nil {
+		return err
+	}
+	return v.ensureRule(ctx, "nat", "POSTROUTING", "-s", cluster, "-d", cluster, "-j", "RETURN")
+}
+
+// ensureRule inserts an iptables rule at the top of chain unless it is already there
+func (v *VXLAN) ensureRule(ctx context.Context, table, chain string, rule ...string) error {
+	rule = append(rule, "-m", "comment", "--comment", ruleComment)
+	check := append([]string{"-w", "-t", table, "-C", chain}, rule...)
+	if _, err := v.run(ctx, "iptables", check...); err == nil {
+		return nil
+	}
+	insert := append([]string{"-w", "-t", table, "-I", chain, "1"}, rule...)
+	if _, err := v.run(ctx, "iptables", insert...); err != nil {
+		return fmt.Errorf("failed to insert %s rule: %w", chain, err)
+	}
+	return nil
+}
+
+// listPeers returns the peers routed through the device by an earlier run. Their node IPs are
+// unknown, so they are reinstalled if they are still wanted.
+func (v *VXLAN) listPeers(ctx context.Context) (map[string]string, error) {
+	out, err := v.run(ctx, "ip", "-o", "route", "show", "dev", v.config.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overlay routes: %w", err)
+	}
+
+	installed := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(fields[0]); err == nil {
+			installed[ipNet.String()] = ""
+		}
+	}
+	return installed, nil
+}
+
+// addPeer routes a node's pod CIDR through the address of the node's device, resolves that
+// address to the device's MAC and sends frames for the MAC to the node
+func (v *VXLAN) addPeer(ctx context.Context, cidr, nodeIP string) error {
+	address, err := deviceIP(cidr)
+	if err != nil {
+		return err
+	}
+	link := v.config.Interface
+	mac := deviceMAC(address)
+
+	commands := [][]string{
+		{"ip", "neigh", "replace", address.String(), "lladdr", mac, "dev", link, "nud", "permanent"},
+		{"bridge", "fdb", "replace", mac, "dev", link, "dst", nodeIP, "self", "permanent"},
+		{"ip", "route", "replace", cidr, "via", address.String(), "dev", link, "onlink"},
+	}
+	for _, command := range commands {
+		if _, err := v.run(ctx, command[0], command[1:]...); err != nil {
+			return fmt.Errorf("failed to add overlay peer %s: %w", cidr, err)
+		}
+	}
+	return nil
+}
+
+// removePeer removes the route, neighbour and forwarding entry of a node's pod CIDR
+func (v *VXLAN) removePeer(ctx context.Context, cidr string) error {
+	address, err := deviceIP(cidr)
+	if err != nil {
+		return err
+	}
+	link := v.config.Interface
+
+	if _, err := v.run(ctx, "ip", "route", "del", cidr, "dev", link); err != nil {
+		return fmt.Errorf("failed to remove overlay peer %s: %w", cidr, err)
+	}
+	// The entries may be gone already, for example after a reboot
+	_, _ = v.run(ctx, "ip", "neigh", "del", address.String(), "dev", link)
+	_, _ = v.run(ctx, "bridge", "fdb", "del", deviceMAC(address), "dev", link, "self")
+	return nil
+}
+
+// deviceIP returns the first address of a pod CIDR, which the node's VXLAN device owns.
+// Pod subnets carved from the CIDR never hand out their network address, so it is free.
+func deviceIP(cidr string) (net.IP, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod CIDR %q: %w", cidr, err)
+	}
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("pod CIDR %s is not IPv4", cidr)
+	}
+	return ip, nil
+}
+
+// deviceMAC derives a locally administered MAC from a device address, so every node knows its
+// peers' MACs without exchanging them
+func deviceMAC(ip net.IP) string {
+	ip4 := ip.To4()
+	return fmt.Sprintf("0a:58:%02x:%02x:%02x:%02x", ip4[0], ip4[1], ip4[2], ip4[3])
+}
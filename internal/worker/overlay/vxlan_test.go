@@ -0,0 +1,165 @@
+package overlay
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+type fakeSource struct {
+	nodes []types.Node
+	err   error
+}
+
+func (s *fakeSource) ListNodes(context.Context) ([]types.Node, error) {
+	return s.nodes, s.err
+}
+
+func testConfig() Config {
+	config := DefaultConfig()
+	config.NodeID = "node-1"
+	config.PodCIDR = "10.244.0.0/22"
+	config.LocalIP = "192.168.1.1"
+	return config
+}
+
+func TestVXLANSync(t *testing.T) {
+	source := &fakeSource{
+		nodes: []types.Node{
+			{NodeID: "node-1", PodCIDR: "10.244.0.0/22", InternalIP: "192.168.1.1"},
+			{NodeID: "node-2", PodCIDR: "10.244.4.0/22", InternalIP: "192.168.1.2"},
+			{NodeID: "node-3", InternalIP: "192.168.1.3"},
+			{NodeID: "node-4", PodCIDR: "10.244.16.0/22"},
+		},
+	}
+
+	var calls []string
+	v := NewVXLAN(source, testConfig())
+	v.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		cmd := name + " " + strings.Join(args, " ")
+		calls = append(calls, cmd)
+		switch {
+		case cmd == "ip link show dev podling-vxlan":
+			return nil, errors.New("Device \"podling-vxlan\" does not exist.")
+		case strings.HasPrefix(cmd, "iptables -w -t nat -C"):
+			return nil, errors.New("Bad rule")
+		case cmd == "ip -o route show dev podling-vxlan":
+			return []byte("10.244.12.0/22 via 10.244.12.0 onlink \n"), nil
+		}
+		return nil, nil
+	}
+
+	if err := v.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want := []string{
+		"ip link show dev podling-vxlan",
+		"ip link add podling-vxlan type vxlan id 1 dstport 4789 local 192.168.1.1 nolearning",
+		"ip link set dev podling-vxlan address 0a:58:0a:f4:00:00 mtu 1450 up",
+		"ip addr replace 10.244.0.0/32 dev podling-vxlan",
+		"iptables -w -n -L DOCKER-USER",
+		"iptables -w -t filter -C DOCKER-USER -s 10.244.0.0/16 -d 10.244.0.0/16 -j ACCEPT " +
+			"-m comment --comment podling-overlay",
+		"iptables -w -t nat -C POSTROUTING -s 10.244.0.0/16 -d 10.244.0.0/16 -j RETURN " +
+			"-m comment --comment podling-overlay",
+		"iptables -w -t nat -I POSTROUTING 1 -s 10.244.0.0/16 -d 10.244.0.0/16 -j RETURN " +
+			"-m comment --comment podling-overlay",
+		"ip -o route show dev podling-vxlan",
+		"ip route del 10.244.12.0/22 dev podling-vxlan",
+		"ip neigh del 10.244.12.0 dev podling-vxlan",
+		"bridge fdb del 0a:58:0a:f4:0c:00 dev podling-vxlan self",
+		"ip neigh replace 10.244.4.0 lladdr 0a:58:0a:f4:04:00 dev podling-vxlan nud permanent",
+		"bridge fdb replace 0a:58:0a:f4:04:00 dev podling-vxlan dst 192.168.1.2 self permanent",
+		"ip route replace 10.244.4.0/22 via 10.244.4.0 dev podling-vxlan onlink",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q\nwant %q", calls, want)
+	}
+
+	// Only changed peers are touched once the device is set up
+	calls = nil
+	source.nodes[1].InternalIP = "192.168.1.20"
+	if err := v.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want = []string{
+		"ip neigh replace 10.244.4.0 lladdr 0a:58:0a:f4:04:00 dev podling-vxlan nud permanent",
+		"bridge fdb replace 0a:58:0a:f4:04:00 dev podling-vxlan dst 192.168.1.20 self permanent",
+		"ip route replace 10.244.4.0/22 via 10.244.4.0 dev podling-vxlan onlink",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q\nwant %q", calls, want)
+	}
+	if got := v.Peers(); !reflect.DeepEqual(got, map[string]string{"10.244.4.0/22": "192.168.1.20"}) {
+		t.Errorf("Peers() = %v, want only node-2's subnet", got)
+	}
+
+	// Peers are kept while the master is unreachable
+	calls = nil
+	source.err = errors.New("connection refused")
+	if err := v.Sync(context.Background()); err == nil {
+		t.Error("Sync() without the master succeeded, want an error")
+	}
+	if len(calls) != 0 || len(v.Peers()) != 1 {
+		t.Errorf("calls = %q, peers = %v, want the installed peer kept", calls, v.Peers())
+	}
+}
+
+func TestVXLANSyncRetriesFailedSetup(t *testing.T) {
+	failing := true
+	v := NewVXLAN(&fakeSource{}, testConfig())
+	v.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		if failing && name == "ip" && args[0] == "addr" {
+			return nil, errors.New("RTNETLINK answers: Operation not permitted")
+		}
+		return nil, nil
+	}
+
+	if err := v.Sync(context.Background()); err == nil {
+		t.Fatal("Sync() with a failing setup succeeded, want an error")
+	}
+	failing = false
+	if err := v.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() after the failure error = %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{name: "long interface name", modify: func(c *Config) { c.Interface = "podling-overlay0" }, wantErr: true},
+		{name: "VNI out of range", modify: func(c *Config) { c.VNI = 1 << 24 }, wantErr: true},
+		{name: "small MTU", modify: func(c *Config) { c.MTU = 500 }, wantErr: true},
+		{name: "missing node ID", modify: func(c *Config) { c.NodeID = "" }, wantErr: true},
+		{name: "pod CIDR outside cluster", modify: func(c *Config) { c.PodCIDR = "10.0.0.0/22" }, wantErr: true},
+		{name: "missing local IP", modify: func(c *Config) { c.LocalIP = "" }, wantErr: true},
+		{name: "IPv6 local IP", modify: func(c *Config) { c.LocalIP = "fd00::1" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := testConfig()
+				tt.modify(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestDeviceMAC(t *testing.T) {
+	if got := deviceMAC(net.ParseIP("10.244.252.0")); got != "0a:58:0a:f4:fc:00" {
+		t.Errorf("deviceMAC() = %s, want 0a:58:0a:f4:fc:00", got)
+	}
+}
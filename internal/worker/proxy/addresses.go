@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Addresses assigns service IPs to the node so the userspace proxy can listen on them
+type Addresses interface {
+	// Sync makes ips the exact set of assigned service IPs
+	Sync(ips []string) error
+}
+
+// LinkAddresses assigns service IPs to a dummy network interface using the ip command.
+// Traffic from pod networks reaches the IPs through the node, which owns them.
+type LinkAddresses struct {
+	link string
+	run  func(ctx context.Context, args ...string) ([]byte, error)
+
+	mu       sync.Mutex
+	assigned map[string]bool
+}
+
+// NewLinkAddresses creates an address manager for the dummy interface named link
+func NewLinkAddresses(link string) *LinkAddresses {
+	return &LinkAddresses{link: link, run: runIP}
+}
+
+// runIP executes the ip command and returns its standard output
+func runIP(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ip", args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ip %s: %s", strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("ip %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Sync creates the interface if needed, adds missing IPs and removes the ones no longer wanted,
+// including IPs left behind by a previous run
+func (l *LinkAddresses) Sync(ips []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.assigned == nil {
+		if err := l.ensureLink(ctx); err != nil {
+			return err
+		}
+		assigned, err := l.listAddresses(ctx)
+		if err != nil {
+			return err
+		}
+		l.assigned = assigned
+	}
+
+	wanted := make(map[string]bool, len(ips))
+	var errs []error
+	for _, ip := range ips {
+		wanted[ip] = true
+		if l.assigned[ip] {
+			continue
+		}
+		if _, err := l.run(ctx, "addr", "add", ip+"/32", "dev", l.link); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		l.assigned[ip] = true
+	}
+	for ip := range l.assigned {
+		if wanted[ip] {
+			continue
+		}
+		if _, err := l.run(ctx, "addr", "del", ip+"/32", "dev", l.link); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(l.assigned, ip)
+	}
+	return errors.Join(errs...)
+}
+
+// ensureLink creates the dummy interface when it does not exist and brings it up
+func (l *LinkAddresses) ensureLink(ctx context.Context) error {
+	if _, err := l.run(ctx, "link", "show", l.link); err != nil {
+		if _, err := l.run(ctx, "link", "add", l.link, "type", "dummy"); err != nil {
+			return fmt.Errorf("failed to create interface %s: %w", l.link, err)
+		}
+	}
+	if _, err := l.run(ctx, "link", "set", l.link, "up"); err != nil {
+		return fmt.Errorf("failed to bring up interface %s: %w", l.link, err)
+	}
+	return nil
+}
+
+// listAddresses returns the IPv4 addresses currently assigned to the interface
+func (l *LinkAddresses) listAddresses(ctx context.Context) (map[string]bool, error) {
+	out, err := l.run(ctx, "-o", "-4", "addr", "show", "dev", l.link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses of %s: %w", l.link, err)
+	}
+
+	assigned := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "inet" {
+				ip, _, _ := strings.Cut(fields[i+1], "/")
+				assigned[ip] = true
+			}
+		}
+	}
+	return assigned, nil
+}
@@ -0,0 +1,48 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinkAddressesSync(t *testing.T) {
+	var calls []string
+	l := NewLinkAddresses("podling-svc")
+	l.run = func(_ context.Context, args ...string) ([]byte, error) {
+		cmd := strings.Join(args, " ")
+		calls = append(calls, cmd)
+		switch cmd {
+		case "link show podling-svc":
+			return nil, errors.New("Device \"podling-svc\" does not exist.")
+		case "-o -4 addr show dev podling-svc":
+			return []byte("7: podling-svc    inet 10.96.0.9/32 scope global podling-svc\n"), nil
+		}
+		return nil, nil
+	}
+
+	if err := l.Sync([]string{"10.96.0.10"}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want := []string{
+		"link show podling-svc",
+		"link add podling-svc type dummy",
+		"link set podling-svc up",
+		"-o -4 addr show dev podling-svc",
+		"addr add 10.96.0.10/32 dev podling-svc",
+		"addr del 10.96.0.9/32 dev podling-svc",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	calls = nil
+	if err := l.Sync([]string{"10.96.0.10", "10.96.0.11"}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"addr add 10.96.0.11/32 dev podling-svc"}) {
+		t.Errorf("calls = %q, want only the new address added", calls)
+	}
+}
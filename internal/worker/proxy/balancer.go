@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// balancer orders a service's endpoints for new connections according to the service's
+// algorithm and session affinity. It tracks open connections for least-connections balancing
+// and the endpoint each client was last sent to for ClientIP affinity.
+type balancer struct {
+	now  func() time.Time
+	rand func(n int) int
+
+	mu        sync.Mutex
+	algorithm types.LoadBalancingAlgorithm
+	affinity  time.Duration
+	endpoints []string
+	weights   []int
+	current   []int
+	next      int
+	active    map[string]int
+	clients   map[string]stickyEndpoint
+}
+
+// stickyEndpoint is the endpoint a client sticks to until expires
+type stickyEndpoint struct {
+	endpoint string
+	expires  time.Time
+}
+
+// newBalancer creates a balancer for a rule
+func newBalancer(rule Rule) *balancer {
+	b := &balancer{
+		now:     time.Now,
+		rand:    rand.IntN,
+		active:  make(map[string]int),
+		clients: make(map[string]stickyEndpoint),
+	}
+	b.update(rule)
+	return b
+}
+
+// update replaces the endpoints and settings. Open connection counts are kept and clients
+// whose endpoint is gone or whose affinity expired are forgotten.
+func (b *balancer) update(rule Rule) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	weights := make([]int, len(rule.Endpoints))
+	for i, endpoint := range rule.Endpoints {
+		weights[i] = 1
+		if w := rule.Weights[endpoint]; w > 0 {
+			weights[i] = w
+		}
+	}
+	if !slices.Equal(b.endpoints, rule.Endpoints) || !slices.Equal(b.weights, weights) {
+		b.current = make([]int, len(rule.Endpoints))
+	}
+
+	b.algorithm = rule.Algorithm
+	b.affinity = rule.Affinity
+	b.endpoints = append([]string(nil), rule.Endpoints...)
+	b.weights = weights
+
+	now := b.now()
+	for client, sticky := range b.clients {
+		if b.affinity == 0 || now.After(sticky.expires) || !slices.Contains(b.endpoints, sticky.endpoint) {
+			delete(b.clients, client)
+		}
+	}
+}
+
+// candidates returns the endpoints in the order they should be tried for a new connection
+// from clientIP: the chosen endpoint first, then the others as fallbacks
+func (b *balancer) candidates(clientIP string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(b.endpoints)
+	if n == 0 {
+		return nil
+	}
+
+	start := -1
+	if sticky, ok := b.clients[clientIP]; ok && b.affinity > 0 && !b.now().After(sticky.expires) {
+		start = slices.Index(b.endpoints, sticky.endpoint)
+	}
+	if start < 0 {
+		start = b.pick()
+	}
+	return append(append([]string(nil), b.endpoints[start:]...), b.endpoints[:start]...)
+}
+
+// pick chooses the index of the endpoint for a connection without affinity
+func (b *balancer) pick() int {
+	n := len(b.endpoints)
+
+	switch b.algorithm {
+	case types.LoadBalancingRandom:
+		return b.rand(n)
+
+	case types.LoadBalancingLeastConnections:
+		// Ties go to the next endpoint in round-robin order
+		best := -1
+		for i := 0; i < n; i++ {
+			index := (b.next + i) % n
+			if best < 0 || b.active[b.endpoints[index]] < b.active[b.endpoints[best]] {
+				best = index
+			}
+		}
+		b.next = best + 1
+		return best
+
+	case types.LoadBalancingWeighted:
+		// Smooth weighted round-robin spreads heavier endpoints' turns instead of bunching them
+		best, total := 0, 0
+		for i, weight := range b.weights {
+			b.current[i] += weight
+			total += weight
+			if b.current[i] > b.current[best] {
+				best = i
+			}
+		}
+		b.current[best] -= total
+		return best
+
+	default:
+		index := b.next % n
+		b.next = index + 1
+		return index
+	}
+}
+
+// connected records a connection from clientIP relayed to endpoint
+func (b *balancer) connected(clientIP, endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.active[endpoint]++
+	if b.affinity > 0 {
+		b.clients[clientIP] = stickyEndpoint{endpoint: endpoint, expires: b.now().Add(b.affinity)}
+	}
+}
+
+// disconnected records that a connection relayed to endpoint closed
+func (b *balancer) disconnected(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.active[endpoint]--; b.active[endpoint] <= 0 {
+		delete(b.active, endpoint)
+	}
+}
@@ -0,0 +1,130 @@
+package proxy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// picks returns the first candidate of n new connections from distinct clients
+func picks(b *balancer, n int) []string {
+	var got []string
+	for i := 0; i < n; i++ {
+		got = append(got, b.candidates("")[0])
+	}
+	return got
+}
+
+func TestBalancerAlgorithms(t *testing.T) {
+	endpoints := []string{"a:80", "b:80", "c:80"}
+
+	tests := []struct {
+		name    string
+		rule    Rule
+		active  map[string]int
+		want    []string
+		wantAll []string // the candidates of the next connection, fallbacks included
+	}{
+		{
+			name:    "round-robin by default",
+			rule:    Rule{Endpoints: endpoints},
+			want:    []string{"a:80", "b:80", "c:80", "a:80"},
+			wantAll: []string{"b:80", "c:80", "a:80"},
+		},
+		{
+			name:    "random",
+			rule:    Rule{Endpoints: endpoints, Algorithm: types.LoadBalancingRandom},
+			want:    []string{"c:80", "c:80"},
+			wantAll: []string{"c:80", "a:80", "b:80"},
+		},
+		{
+			name:   "least connections",
+			rule:   Rule{Endpoints: endpoints, Algorithm: types.LoadBalancingLeastConnections},
+			active: map[string]int{"a:80": 2, "c:80": 1},
+			want:   []string{"b:80", "b:80"},
+		},
+		{
+			name: "weighted",
+			rule: Rule{
+				Endpoints: endpoints, Algorithm: types.LoadBalancingWeighted,
+				Weights: map[string]int{"a:80": 3, "c:80": 0},
+			},
+			want: []string{"a:80", "b:80", "a:80", "c:80", "a:80", "a:80", "b:80", "a:80", "c:80", "a:80"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				b := newBalancer(tt.rule)
+				b.rand = func(n int) int { return n - 1 }
+				for endpoint, count := range tt.active {
+					for i := 0; i < count; i++ {
+						b.connected("", endpoint)
+					}
+				}
+
+				if got := picks(b, len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("picks = %v, want %v", got, tt.want)
+				}
+				if tt.wantAll != nil {
+					if got := b.candidates(""); !reflect.DeepEqual(got, tt.wantAll) {
+						t.Errorf("candidates() = %v, want %v", got, tt.wantAll)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestBalancerLeastConnectionsTracksClosedConnections(t *testing.T) {
+	b := newBalancer(Rule{Endpoints: []string{"a:80", "b:80"}, Algorithm: types.LoadBalancingLeastConnections})
+
+	b.connected("10.0.0.1", "a:80")
+	b.connected("10.0.0.2", "a:80")
+	if got := b.candidates("")[0]; got != "b:80" {
+		t.Errorf("with a busy: picked %s, want b:80", got)
+	}
+
+	b.disconnected("a:80")
+	b.disconnected("a:80")
+	b.connected("10.0.0.3", "b:80")
+	if got := b.candidates("")[0]; got != "a:80" {
+		t.Errorf("after a's connections closed: picked %s, want a:80", got)
+	}
+}
+
+func TestBalancerSessionAffinity(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule := Rule{Endpoints: []string{"a:80", "b:80", "c:80"}, Affinity: time.Minute}
+	b := newBalancer(rule)
+	b.now = func() time.Time { return now }
+
+	first := b.candidates("10.0.0.1")[0]
+	b.connected("10.0.0.1", first)
+	for i := 0; i < 3; i++ {
+		if got := b.candidates("10.0.0.1")[0]; got != first {
+			t.Fatalf("connection %d from the same client went to %s, want %s", i, got, first)
+		}
+		if got := b.candidates("10.0.0.2")[0]; got == first && i == 0 {
+			t.Errorf("another client was also sent to %s", got)
+		}
+	}
+
+	// The client is forgotten once the timeout passes without new connections
+	now = now.Add(2 * time.Minute)
+	b.update(rule)
+	if _, ok := b.clients["10.0.0.1"]; ok {
+		t.Error("expired client is still remembered")
+	}
+
+	// and when its endpoint goes away
+	b.connected("10.0.0.1", "b:80")
+	rule.Endpoints = []string{"a:80", "c:80"}
+	b.update(rule)
+	if got := b.candidates("10.0.0.1"); len(got) != 2 || got[0] == "b:80" {
+		t.Errorf("candidates() = %v after the client's endpoint was removed", got)
+	}
+}
@@ -0,0 +1,252 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// DefaultNFTablesTable is the nftables table the proxier owns
+const DefaultNFTablesTable = "podling-proxy"
+
+// masqueradeMark marks service connections that are masqueraded after DNAT, so that replies
+// from endpoints on the same network as the client return through the node
+const masqueradeMark = "0x4000"
+
+// NFTables forwards service traffic in the kernel by DNATing it to an endpoint picked in turn,
+// at random, or at random in proportion to the endpoints' weights. The kernel does not count
+// connections per endpoint, so least-connections services are balanced at random. ClientIP
+// affinity remembers each client in a per-endpoint set with a timeout.
+// The whole table is replaced atomically on every change. Endpoints must be routable from the
+// clients' networks; connections to services without endpoints are rejected.
+type NFTables struct {
+	table string
+	run   func(ctx context.Context, stdin []byte, args ...string) ([]byte, error)
+
+	mu      sync.Mutex
+	applied string
+}
+
+var _ Proxier = (*NFTables)(nil)
+
+// NewNFTables creates an nftables proxier using the nft binary
+func NewNFTables() (*NFTables, error) {
+	if _, err := exec.LookPath("nft"); err != nil {
+		return nil, fmt.Errorf("failed to find nft: %w", err)
+	}
+	return &NFTables{table: DefaultNFTablesTable, run: runNFT}, nil
+}
+
+// runNFT executes nft with stdin attached and returns its standard output
+func runNFT(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "nft", args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("nft %s: %s", strings.Join(args, " "), msg)
+		}
+		return nil, fmt.Errorf("nft %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Sync replaces the proxier's table with one generated from rules
+func (n *NFTables) Sync(rules []Rule) error {
+	ruleset := renderNFTables(n.table, rules)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ruleset == n.applied {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := n.run(ctx, []byte(ruleset), "-f", "-"); err != nil {
+		return err
+	}
+	n.applied = ruleset
+	return nil
+}
+
+// Close deletes the proxier's table
+func (n *NFTables) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	n.applied = ""
+	_, err := n.run(ctx, nil, "delete", "table", "ip", n.table)
+	return err
+}
+
+// renderNFTables generates an nft script that atomically replaces table with the rules
+func renderNFTables(table string, rules []Rule) string {
+	var b strings.Builder
+
+	// Adding the table first makes the delete succeed when it does not exist yet
+	fmt.Fprintf(&b, "add table ip %s\n", table)
+	fmt.Fprintf(&b, "delete table ip %s\n", table)
+	fmt.Fprintf(&b, "table ip %s {\n", table)
+
+	// Services with session affinity get a chain that sends known clients to their endpoint's
+	// chain, which refreshes the client in the endpoint's set and DNATs to the endpoint
+	targets := make([]string, len(rules))
+	for i, rule := range rules {
+		if len(rule.Endpoints) == 0 {
+			continue
+		}
+		if rule.Affinity <= 0 {
+			targets[i] = nftDNAT(rule)
+			continue
+		}
+
+		chain := fmt.Sprintf("service-%d", i)
+		seconds := int((rule.Affinity + time.Second - 1) / time.Second)
+		for j, endpoint := range rule.Endpoints {
+			name := fmt.Sprintf("%s-endpoint-%d", chain, j)
+			fmt.Fprintf(&b, "\tset %s {\n", name)
+			fmt.Fprintf(&b, "\t\ttype ipv4_addr; flags dynamic,timeout; timeout %ds;\n", seconds)
+			b.WriteString("\t}\n")
+			fmt.Fprintf(&b, "\tchain %s {\n", name)
+			fmt.Fprintf(&b, "\t\tupdate @%s { ip saddr }\n", name)
+			fmt.Fprintf(&b, "\t\tdnat to %s\n", endpoint)
+			b.WriteString("\t}\n")
+		}
+
+		fmt.Fprintf(&b, "\tchain %s {\n", chain)
+		verdicts := make([]string, len(rule.Endpoints))
+		for j := range rule.Endpoints {
+			name := fmt.Sprintf("%s-endpoint-%d", chain, j)
+			fmt.Fprintf(&b, "\t\tip saddr @%s goto %s\n", name, name)
+			verdicts[j] = "goto " + name
+		}
+		selector, keys := nftSelector(rule)
+		elements := make([]string, len(keys))
+		for j := range keys {
+			elements[j] = fmt.Sprintf("%s : %s", keys[j], verdicts[j])
+		}
+		fmt.Fprintf(&b, "\t\t%s vmap { %s }\n", selector, strings.Join(elements, ", "))
+		b.WriteString("\t}\n")
+		targets[i] = "goto " + chain
+	}
+
+	b.WriteString("\tchain services {\n")
+	for i, rule := range rules {
+		if len(rule.Endpoints) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\t\t%s meta mark set meta mark or %s %s\n", nftMatch(rule), masqueradeMark, targets[i])
+	}
+	b.WriteString("\t}\n")
+
+	b.WriteString("\tchain no-endpoints {\n")
+	for _, rule := range rules {
+		if len(rule.Endpoints) > 0 {
+			continue
+		}
+		reject := "reject"
+		if rule.Protocol == "TCP" {
+			reject = "reject with tcp reset"
+		}
+		fmt.Fprintf(&b, "\t\t%s %s\n", nftMatch(rule), reject)
+	}
+	b.WriteString("\t}\n")
+
+	writeChain := func(name, kind, hook, priority, body string) {
+		fmt.Fprintf(&b, "\tchain %s {\n", name)
+		fmt.Fprintf(&b, "\t\ttype %s hook %s priority %s; policy accept;\n", kind, hook, priority)
+		fmt.Fprintf(&b, "\t\t%s\n", body)
+		b.WriteString("\t}\n")
+	}
+	writeChain("prerouting", "nat", "prerouting", "dstnat", "jump services")
+	writeChain("output", "nat", "output", "dstnat", "jump services")
+	writeChain("postrouting", "nat", "postrouting", "srcnat", "meta mark & "+masqueradeMark+" != 0 masquerade")
+	writeChain("filter-input", "filter", "input", "filter - 10", "jump no-endpoints")
+	writeChain("filter-forward", "filter", "forward", "filter - 10", "jump no-endpoints")
+	writeChain("filter-output", "filter", "output", "filter - 10", "jump no-endpoints")
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nftMatch matches packets to the rule's service IP and port, or to the node port on any
+// local address
+func nftMatch(rule Rule) string {
+	if rule.NodePort {
+		return fmt.Sprintf("fib daddr type local %s dport %d", strings.ToLower(rule.Protocol), rule.Port)
+	}
+	return fmt.Sprintf("ip daddr %s %s dport %d", rule.ClusterIP, strings.ToLower(rule.Protocol), rule.Port)
+}
+
+// nftSelector returns the expression that picks an endpoint for the rule's algorithm and the
+// map key of each endpoint. Weighted endpoints get a range of keys as wide as their weight.
+func nftSelector(rule Rule) (string, []string) {
+	mode := "random"
+	if rule.Algorithm == "" || rule.Algorithm == types.LoadBalancingRoundRobin {
+		mode = "inc"
+	}
+
+	keys := make([]string, len(rule.Endpoints))
+	total := 0
+	for i, endpoint := range rule.Endpoints {
+		weight := 1
+		if rule.Algorithm == types.LoadBalancingWeighted && rule.Weights[endpoint] > 0 {
+			weight = rule.Weights[endpoint]
+		}
+		if weight == 1 {
+			keys[i] = strconv.Itoa(total)
+		} else {
+			keys[i] = fmt.Sprintf("%d-%d", total, total+weight-1)
+		}
+		total += weight
+	}
+	return fmt.Sprintf("numgen %s mod %d", mode, total), keys
+}
+
+// nftDNAT rewrites the destination to one of the rule's endpoints
+func nftDNAT(rule Rule) string {
+	endpoints := rule.Endpoints
+	hosts := make([]string, len(endpoints))
+	ports := make([]string, len(endpoints))
+	samePort := true
+	for i, endpoint := range endpoints {
+		hosts[i], ports[i], _ = net.SplitHostPort(endpoint)
+		samePort = samePort && ports[i] == ports[0]
+	}
+
+	if len(endpoints) == 1 {
+		return fmt.Sprintf("dnat to %s", endpoints[0])
+	}
+
+	selector, keys := nftSelector(rule)
+	elements := make([]string, len(endpoints))
+	for i := range endpoints {
+		if samePort {
+			elements[i] = fmt.Sprintf("%s : %s", keys[i], hosts[i])
+		} else {
+			elements[i] = fmt.Sprintf("%s : %s . %s", keys[i], hosts[i], ports[i])
+		}
+	}
+	if samePort {
+		return fmt.Sprintf("dnat to %s map { %s } : %s", selector, strings.Join(elements, ", "), ports[0])
+	}
+	return fmt.Sprintf("dnat ip addr . port to %s map { %s }", selector, strings.Join(elements, ", "))
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestRenderNFTables(t *testing.T) {
+	rules := []Rule{
+		{
+			ClusterIP: "10.96.0.10", Port: 80, Protocol: "TCP", Endpoints: []string{"172.18.0.2:8080", "172.18.0.3:8080"},
+			Algorithm: types.LoadBalancingRandom,
+		},
+		{ClusterIP: "10.96.0.11", Port: 443, Protocol: "TCP", Endpoints: []string{"172.18.0.4:8443"}},
+		{ClusterIP: "10.96.0.12", Port: 81, Protocol: "TCP", Endpoints: []string{"172.18.0.5:81", "172.18.0.6:82"}},
+		{ClusterIP: "10.96.0.53", Port: 53, Protocol: "UDP"},
+		{ClusterIP: "10.96.0.13", Port: 80, Protocol: "TCP"},
+		{Port: 30080, NodePort: true, Protocol: "TCP", Endpoints: []string{"172.18.0.4:8443"}},
+		{Port: 30053, NodePort: true, Protocol: "UDP"},
+		{
+			ClusterIP: "10.96.0.14", Port: 80, Protocol: "TCP", Endpoints: []string{"172.18.0.7:80", "172.18.0.8:80"},
+			Algorithm: types.LoadBalancingWeighted, Weights: map[string]int{"172.18.0.7:80": 3},
+		},
+		{
+			ClusterIP: "10.96.0.15", Port: 80, Protocol: "TCP", Endpoints: []string{"172.18.0.9:80", "172.18.0.10:80"},
+			Affinity: 90 * time.Second,
+		},
+	}
+	ruleset := renderNFTables("podling-proxy", rules)
+
+	want := []string{
+		"add table ip podling-proxy\ndelete table ip podling-proxy\ntable ip podling-proxy {",
+		"ip daddr 10.96.0.10 tcp dport 80 meta mark set meta mark or 0x4000 " +
+			"dnat to numgen random mod 2 map { 0 : 172.18.0.2, 1 : 172.18.0.3 } : 8080",
+		"ip daddr 10.96.0.11 tcp dport 443 meta mark set meta mark or 0x4000 dnat to 172.18.0.4:8443",
+		"dnat ip addr . port to numgen inc mod 2 map { 0 : 172.18.0.5 . 81, 1 : 172.18.0.6 . 82 }",
+		"ip daddr 10.96.0.53 udp dport 53 reject\n",
+		"ip daddr 10.96.0.13 tcp dport 80 reject with tcp reset",
+		"type nat hook prerouting priority dstnat; policy accept;",
+		"meta mark & 0x4000 != 0 masquerade",
+		"type filter hook forward priority filter - 10; policy accept;",
+		"fib daddr type local tcp dport 30080 meta mark set meta mark or 0x4000 dnat to 172.18.0.4:8443",
+		"fib daddr type local udp dport 30053 reject\n",
+		"type filter hook input priority filter - 10; policy accept;",
+		"dnat to numgen random mod 4 map { 0-2 : 172.18.0.7, 3 : 172.18.0.8 } : 80",
+		"set service-8-endpoint-0 {\n\t\ttype ipv4_addr; flags dynamic,timeout; timeout 90s;\n\t}",
+		"chain service-8-endpoint-1 {\n\t\tupdate @service-8-endpoint-1 { ip saddr }\n\t\tdnat to 172.18.0.10:80\n",
+		"ip saddr @service-8-endpoint-0 goto service-8-endpoint-0\n",
+		"numgen inc mod 2 vmap { 0 : goto service-8-endpoint-0, 1 : goto service-8-endpoint-1 }",
+		"ip daddr 10.96.0.15 tcp dport 80 meta mark set meta mark or 0x4000 goto service-8\n",
+	}
+	for _, fragment := range want {
+		if !strings.Contains(ruleset, fragment) {
+			t.Errorf("ruleset is missing %q:\n%s", fragment, ruleset)
+		}
+	}
+}
+
+func TestNFTablesSync(t *testing.T) {
+	var calls [][]string
+	n := &NFTables{
+		table: DefaultNFTablesTable,
+		run: func(_ context.Context, stdin []byte, args ...string) ([]byte, error) {
+			calls = append(calls, append([]string{string(stdin)}, args...))
+			return nil, nil
+		},
+	}
+
+	rules := []Rule{{ClusterIP: "10.96.0.10", Port: 80, Protocol: "TCP", Endpoints: []string{"172.18.0.2:80"}}}
+	for i := 0; i < 2; i++ {
+		if err := n.Sync(rules); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+	if len(calls) != 1 || calls[0][1] != "-f" {
+		t.Fatalf("calls = %v, want one nft -f - for an unchanged ruleset", calls)
+	}
+
+	if err := n.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := strings.Join(calls[1][1:], " "); got != "delete table ip podling-proxy" {
+		t.Errorf("close ran nft %s", got)
+	}
+}
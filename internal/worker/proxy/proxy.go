@@ -0,0 +1,157 @@
+// Package proxy makes service ClusterIPs routable on a worker node. It watches services and
+// endpoints on the master and forwards connections to a service port to its ready endpoints,
+// either through a userspace TCP/UDP relay or through nftables DNAT rules.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Mode selects how service traffic is forwarded
+type Mode string
+
+const (
+	// ModeUserspace relays connections through listeners on the service IPs
+	ModeUserspace Mode = "userspace"
+
+	// ModeNFTables rewrites service traffic to endpoints with nftables DNAT rules
+	ModeNFTables Mode = "nftables"
+)
+
+// Config controls how the proxy watches services and forwards traffic
+type Config struct {
+	// Mode selects the forwarding implementation
+	Mode Mode
+
+	// SyncInterval is how often services and endpoints are fetched from the master
+	SyncInterval time.Duration
+
+	// Interface is the dummy network interface service IPs are assigned to in userspace mode
+	Interface string
+
+	// DialTimeout bounds each connection attempt to an endpoint in userspace mode
+	DialTimeout time.Duration
+
+	// UDPIdleTimeout is how long a UDP client mapping is kept without traffic in userspace mode
+	UDPIdleTimeout time.Duration
+}
+
+// DefaultConfig returns the default proxy settings
+func DefaultConfig() Config {
+	return Config{
+		Mode:           ModeUserspace,
+		SyncInterval:   5 * time.Second,
+		Interface:      "podling-svc",
+		DialTimeout:    2 * time.Second,
+		UDPIdleTimeout: 30 * time.Second,
+	}
+}
+
+// Validate checks that the settings are consistent
+func (c Config) Validate() error {
+	if c.Mode != ModeUserspace && c.Mode != ModeNFTables {
+		return fmt.Errorf("unknown proxy mode %q (expected %s or %s)", c.Mode, ModeUserspace, ModeNFTables)
+	}
+	if c.SyncInterval <= 0 {
+		return fmt.Errorf("sync interval must be positive, got %v", c.SyncInterval)
+	}
+	if c.Mode == ModeUserspace {
+		if c.Interface == "" {
+			return errors.New("interface is required in userspace mode")
+		}
+		if c.DialTimeout <= 0 {
+			return fmt.Errorf("dial timeout must be positive, got %v", c.DialTimeout)
+		}
+		if c.UDPIdleTimeout <= 0 {
+			return fmt.Errorf("UDP idle timeout must be positive, got %v", c.UDPIdleTimeout)
+		}
+	}
+	return nil
+}
+
+// Proxier forwards traffic according to a set of rules
+type Proxier interface {
+	// Sync replaces the forwarded services with rules
+	Sync(rules []Rule) error
+
+	// Close stops forwarding and removes anything installed on the node
+	Close() error
+}
+
+// Proxy keeps a proxier in sync with the services and endpoints on the master
+type Proxy struct {
+	source   Source
+	proxier  Proxier
+	interval time.Duration
+
+	mu    sync.Mutex
+	rules []Rule
+}
+
+// New creates a proxy that fetches from source every interval and applies the rules with proxier
+func New(source Source, proxier Proxier, interval time.Duration) *Proxy {
+	return &Proxy{
+		source:   source,
+		proxier:  proxier,
+		interval: interval,
+	}
+}
+
+// Sync fetches services and endpoints and applies them. When the master cannot be reached the
+// current rules are kept, so existing services stay reachable.
+func (p *Proxy) Sync(ctx context.Context) error {
+	services, err := p.source.ListServices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list services: %w", err)
+	}
+	endpoints, err := p.source.ListEndpoints(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	rules := BuildRules(services, endpoints)
+	if err := p.proxier.Sync(rules); err != nil {
+		return fmt.Errorf("failed to apply service rules: %w", err)
+	}
+
+	p.mu.Lock()
+	p.rules = rules
+	p.mu.Unlock()
+	return nil
+}
+
+// Rules returns the rules applied by the last successful sync
+func (p *Proxy) Rules() []Rule {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Rule(nil), p.rules...)
+}
+
+// Run syncs immediately and then every interval until stop is closed, then closes the proxier
+func (p *Proxy) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+		if err := p.Sync(ctx); err != nil {
+			log.Printf("service proxy sync failed: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-stop:
+			if err := p.proxier.Close(); err != nil {
+				log.Printf("failed to clean up service proxy: %v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// fakeSource serves fixed services and endpoints, or an error
+type fakeSource struct {
+	services  []types.Service
+	endpoints []types.Endpoints
+	err       error
+}
+
+func (s *fakeSource) ListServices(context.Context) ([]types.Service, error) {
+	return s.services, s.err
+}
+
+func (s *fakeSource) ListEndpoints(context.Context) ([]types.Endpoints, error) {
+	return s.endpoints, s.err
+}
+
+// recordingProxier remembers the rules it was synced with, or fails with err
+type recordingProxier struct {
+	mu     sync.Mutex
+	syncs  [][]Rule
+	closed bool
+	err    error
+}
+
+func (p *recordingProxier) Sync(rules []Rule) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.syncs = append(p.syncs, rules)
+	return nil
+}
+
+func (p *recordingProxier) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{name: "nftables without userspace settings", modify: func(c *Config) {
+			c.Mode, c.Interface, c.DialTimeout = ModeNFTables, "", 0
+		}},
+		{name: "unknown mode", modify: func(c *Config) { c.Mode = "ipvs" }, wantErr: true},
+		{name: "zero sync interval", modify: func(c *Config) { c.SyncInterval = 0 }, wantErr: true},
+		{name: "userspace without interface", modify: func(c *Config) { c.Interface = "" }, wantErr: true},
+		{name: "zero dial timeout", modify: func(c *Config) { c.DialTimeout = 0 }, wantErr: true},
+		{name: "zero UDP idle timeout", modify: func(c *Config) { c.UDPIdleTimeout = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				config := DefaultConfig()
+				tt.modify(&config)
+				if err := config.Validate(); (err != nil) != tt.wantErr {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestProxySync(t *testing.T) {
+	source := &fakeSource{
+		services: []types.Service{
+			{ServiceID: "svc-1", Name: "web", ClusterIP: "10.96.0.10", Ports: []types.ServicePort{{Port: 80}}},
+		},
+	}
+	proxier := &recordingProxier{}
+	p := New(source, proxier, time.Minute)
+
+	if err := p.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if rules := p.Rules(); len(rules) != 1 || rules[0].ClusterIP != "10.96.0.10" {
+		t.Fatalf("Rules() = %+v, want one rule for 10.96.0.10", rules)
+	}
+
+	source.err = errors.New("master unavailable")
+	if err := p.Sync(context.Background()); err == nil {
+		t.Fatal("expected an error when the master is unavailable")
+	}
+	if len(proxier.syncs) != 1 || len(p.Rules()) != 1 {
+		t.Errorf("rules changed after a failed fetch: %d syncs, rules %+v", len(proxier.syncs), p.Rules())
+	}
+}
+
+func TestProxySyncKeepsRulesWhenApplyFails(t *testing.T) {
+	source := &fakeSource{
+		services: []types.Service{
+			{ServiceID: "svc-1", Name: "web", ClusterIP: "10.96.0.10", Ports: []types.ServicePort{{Port: 80}}},
+		},
+	}
+	proxier := &recordingProxier{}
+	p := New(source, proxier, time.Minute)
+
+	if err := p.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	source.services = append(
+		source.services,
+		types.Service{ServiceID: "svc-2", Name: "api", ClusterIP: "10.96.0.11", Ports: []types.ServicePort{{Port: 80}}},
+	)
+	proxier.err = errors.New("nft: operation not permitted")
+	if err := p.Sync(context.Background()); err == nil {
+		t.Fatal("expected an error when the rules cannot be applied")
+	}
+	if rules := p.Rules(); len(rules) != 1 || rules[0].ClusterIP != "10.96.0.10" {
+		t.Errorf("Rules() = %+v, want the last applied rule for 10.96.0.10", rules)
+	}
+}
+
+func TestProxyRunClosesProxier(t *testing.T) {
+	proxier := &recordingProxier{}
+	p := New(&fakeSource{}, proxier, 10*time.Millisecond)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Run(stop)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after stop")
+	}
+
+	proxier.mu.Lock()
+	defer proxier.mu.Unlock()
+	if len(proxier.syncs) < 2 || !proxier.closed {
+		t.Errorf("syncs = %d, closed = %v; want periodic syncs and a close", len(proxier.syncs), proxier.closed)
+	}
+}
+
+func TestMasterSource(t *testing.T) {
+	master := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/services":
+					_ = json.NewEncoder(w).Encode([]types.Service{{ServiceID: "svc-1"}})
+				case "/api/v1/endpoints":
+					_ = json.NewEncoder(w).Encode([]types.Endpoints{{ServiceID: "svc-1"}, {ServiceID: "svc-2"}})
+				default:
+					http.NotFound(w, r)
+				}
+			},
+		),
+	)
+	defer master.Close()
+
+	source := NewMasterSource(master.URL + "/")
+	services, err := source.ListServices(context.Background())
+	if err != nil || len(services) != 1 {
+		t.Fatalf("ListServices() = %+v, %v", services, err)
+	}
+	endpoints, err := source.ListEndpoints(context.Background())
+	if err != nil || len(endpoints) != 2 {
+		t.Fatalf("ListEndpoints() = %+v, %v", endpoints, err)
+	}
+
+	master.Close()
+	if _, err := source.ListServices(context.Background()); err == nil {
+		t.Error("expected an error when the master is unreachable")
+	}
+}
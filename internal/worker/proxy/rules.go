@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Rule forwards one service port to the ready endpoints behind it
+type Rule struct {
+	// ServiceID is the service the rule belongs to
+	ServiceID string
+
+	// Service is the namespaced service name, for logging
+	Service string
+
+	// ClusterIP is the service's virtual IP or one of its load balancer ingress IPs; it is
+	// empty for node port rules
+	ClusterIP string
+
+	// Port is the service port on the ClusterIP, or the node port
+	Port int
+
+	// NodePort rules accept traffic to Port on every address of the node
+	NodePort bool
+
+	// Protocol is TCP or UDP
+	Protocol string
+
+	// Endpoints are the host:port addresses of the ready endpoints, sorted
+	Endpoints []string
+
+	// Algorithm picks the endpoint for a new connection; empty means round-robin
+	Algorithm types.LoadBalancingAlgorithm
+
+	// Weights are the endpoints' weights by address for weighted balancing; endpoints
+	// without one weigh 1
+	Weights map[string]int
+
+	// Affinity is how long a client IP keeps being sent to the same endpoint after its last
+	// connection; zero disables session affinity
+	Affinity time.Duration
+}
+
+// Key identifies the frontend the rule listens on
+func (r Rule) Key() string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(r.Protocol), net.JoinHostPort(r.ClusterIP, strconv.Itoa(r.Port)))
+}
+
+// BuildRules turns services and their endpoints into one rule per service port, plus one per
+// node port and per load balancer ingress IP. Ports of services without a valid ClusterIP only
+// get their other rules; services without ready endpoints get rules with no endpoints, so their
+// connections are refused rather than left hanging.
+func BuildRules(services []types.Service, endpoints []types.Endpoints) []Rule {
+	byService := make(map[string]types.Endpoints, len(endpoints))
+	for _, ep := range endpoints {
+		byService[ep.ServiceID] = ep
+	}
+
+	var rules []Rule
+	for _, service := range services {
+		hasClusterIP := net.ParseIP(service.ClusterIP).To4() != nil
+
+		namespace := service.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+
+		for i, port := range service.Ports {
+			protocol := strings.ToUpper(port.Protocol)
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			if protocol != "TCP" && protocol != "UDP" {
+				continue
+			}
+
+			addrs, weights := endpointAddresses(byService[service.ServiceID], i, port)
+			rule := Rule{
+				ServiceID: service.ServiceID,
+				Service:   namespace + "/" + service.Name,
+				Protocol:  protocol,
+				Endpoints: addrs,
+				Algorithm: service.LoadBalancing,
+				Affinity:  service.SessionAffinityTimeout(),
+			}
+			if service.LoadBalancing == types.LoadBalancingWeighted {
+				rule.Weights = weights
+			}
+			if hasClusterIP && port.Port > 0 {
+				clusterRule := rule
+				clusterRule.ClusterIP = service.ClusterIP
+				clusterRule.Port = port.Port
+				rules = append(rules, clusterRule)
+			}
+			if service.Type == types.ServiceTypeLoadBalancer && port.Port > 0 {
+				for _, ip := range service.IngressIPs() {
+					if net.ParseIP(ip).To4() == nil {
+						continue
+					}
+					ingressRule := rule
+					ingressRule.ClusterIP = ip
+					ingressRule.Port = port.Port
+					rules = append(rules, ingressRule)
+				}
+			}
+			if service.UsesNodePorts() && port.NodePort > 0 && port.NodePort <= 65535 {
+				nodeRule := rule
+				nodeRule.Port = port.NodePort
+				nodeRule.NodePort = true
+				rules = append(rules, nodeRule)
+			}
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Key() < rules[j].Key() })
+	return rules
+}
+
+// endpointAddresses returns the ready host:port addresses serving the index-th service port,
+// and the weights of the ones that have a weight
+func endpointAddresses(endpoints types.Endpoints, index int, port types.ServicePort) ([]string, map[string]int) {
+	var addrs []string
+	weights := make(map[string]int)
+	for _, subset := range endpoints.Subsets {
+		target, ok := subset.TargetPort(index, port)
+		if !ok {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			hostPort := net.JoinHostPort(addr.IP, strconv.Itoa(target))
+			addrs = append(addrs, hostPort)
+			if addr.Weight > 0 {
+				weights[hostPort] = addr.Weight
+			}
+		}
+	}
+	sort.Strings(addrs)
+	return addrs, weights
+}
@@ -0,0 +1,184 @@
+package proxy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+func TestBuildRules(t *testing.T) {
+	web := types.Service{
+		ServiceID: "svc-web",
+		Name:      "web",
+		ClusterIP: "10.96.0.10",
+		Ports: []types.ServicePort{
+			{Name: "http", Port: 80, TargetPort: 8080, Protocol: "TCP"},
+			{Name: "metrics", Port: 9090, TargetPort: 9090},
+		},
+	}
+	webEndpoints := types.Endpoints{
+		ServiceID: "svc-web",
+		Subsets: []types.EndpointSubset{
+			{
+				Addresses: []types.EndpointAddress{{IP: "172.18.0.3"}, {IP: "172.18.0.2"}},
+				NotReadyAddresses: []types.EndpointAddress{
+					{IP: "172.18.0.4"},
+				},
+				Ports: []types.EndpointPort{{Name: "metrics", Port: 9091}, {Name: "http", Port: 8080}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		services  []types.Service
+		endpoints []types.Endpoints
+		want      []Rule
+	}{
+		{
+			name:      "named ports with ready endpoints",
+			services:  []types.Service{web},
+			endpoints: []types.Endpoints{webEndpoints},
+			want: []Rule{
+				{
+					ServiceID: "svc-web", Service: "default/web", ClusterIP: "10.96.0.10", Port: 80, Protocol: "TCP",
+					Endpoints: []string{"172.18.0.2:8080", "172.18.0.3:8080"},
+				},
+				{
+					ServiceID: "svc-web", Service: "default/web", ClusterIP: "10.96.0.10", Port: 9090, Protocol: "TCP",
+					Endpoints: []string{"172.18.0.2:9091", "172.18.0.3:9091"},
+				},
+			},
+		},
+		{
+			name: "unnamed UDP port without endpoints",
+			services: []types.Service{
+				{
+					ServiceID: "svc-dns", Name: "dns", Namespace: "kube", ClusterIP: "10.96.0.53",
+					Ports: []types.ServicePort{{Port: 53, Protocol: "udp"}},
+				},
+			},
+			want: []Rule{
+				{ServiceID: "svc-dns", Service: "kube/dns", ClusterIP: "10.96.0.53", Port: 53, Protocol: "UDP"},
+			},
+		},
+		{
+			name: "unnamed port falls back to target port",
+			services: []types.Service{
+				{
+					ServiceID: "svc-api", Name: "api", ClusterIP: "10.96.0.11",
+					Ports: []types.ServicePort{{Port: 80, TargetPort: 3000}},
+				},
+			},
+			endpoints: []types.Endpoints{
+				{
+					ServiceID: "svc-api",
+					Subsets:   []types.EndpointSubset{{Addresses: []types.EndpointAddress{{IP: "172.19.0.2"}}}},
+				},
+			},
+			want: []Rule{
+				{
+					ServiceID: "svc-api", Service: "default/api", ClusterIP: "10.96.0.11", Port: 80, Protocol: "TCP",
+					Endpoints: []string{"172.19.0.2:3000"},
+				},
+			},
+		},
+		{
+			name: "node ports get a rule on every node address",
+			services: []types.Service{
+				{
+					ServiceID: "svc-api", Name: "api", Type: types.ServiceTypeNodePort, ClusterIP: "10.96.0.11",
+					Ports: []types.ServicePort{{Port: 80, TargetPort: 3000, NodePort: 30080}},
+				},
+				{
+					ServiceID: "svc-stale", Name: "stale", ClusterIP: "10.96.0.13",
+					Ports: []types.ServicePort{{Port: 81, NodePort: 30081}},
+				},
+			},
+			want: []Rule{
+				{ServiceID: "svc-api", Service: "default/api", ClusterIP: "10.96.0.11", Port: 80, Protocol: "TCP"},
+				{ServiceID: "svc-stale", Service: "default/stale", ClusterIP: "10.96.0.13", Port: 81, Protocol: "TCP"},
+				{ServiceID: "svc-api", Service: "default/api", Port: 30080, NodePort: true, Protocol: "TCP"},
+			},
+		},
+		{
+			name: "balancing algorithm, endpoint weights and session affinity",
+			services: []types.Service{
+				{
+					ServiceID: "svc-api", Name: "api", ClusterIP: "10.96.0.15", Ports: []types.ServicePort{{Port: 80}},
+					LoadBalancing: types.LoadBalancingWeighted, SessionAffinity: types.SessionAffinityClientIP,
+					SessionAffinityTimeoutSeconds: 60,
+				},
+			},
+			endpoints: []types.Endpoints{
+				{
+					ServiceID: "svc-api",
+					Subsets: []types.EndpointSubset{
+						{Addresses: []types.EndpointAddress{{IP: "172.18.0.5", Weight: 3}, {IP: "172.18.0.6"}}},
+					},
+				},
+			},
+			want: []Rule{
+				{
+					ServiceID: "svc-api", Service: "default/api", ClusterIP: "10.96.0.15", Port: 80, Protocol: "TCP",
+					Endpoints: []string{"172.18.0.5:80", "172.18.0.6:80"}, Algorithm: types.LoadBalancingWeighted,
+					Weights: map[string]int{"172.18.0.5:80": 3}, Affinity: time.Minute,
+				},
+			},
+		},
+		{
+			name: "load balancers get a rule per ingress IP",
+			services: []types.Service{
+				{
+					ServiceID: "svc-lb", Name: "lb", Type: types.ServiceTypeLoadBalancer, ClusterIP: "10.96.0.14",
+					Ports: []types.ServicePort{{Port: 443, NodePort: 30443}},
+					Status: types.ServiceStatus{
+						LoadBalancer: types.LoadBalancerStatus{
+							Ingress: []types.LoadBalancerIngress{{IP: "192.168.1.240"}, {Hostname: "lb.example.com"}},
+						},
+					},
+				},
+			},
+			want: []Rule{
+				{ServiceID: "svc-lb", Service: "default/lb", ClusterIP: "10.96.0.14", Port: 443, Protocol: "TCP"},
+				{ServiceID: "svc-lb", Service: "default/lb", ClusterIP: "192.168.1.240", Port: 443, Protocol: "TCP"},
+				{ServiceID: "svc-lb", Service: "default/lb", Port: 30443, NodePort: true, Protocol: "TCP"},
+			},
+		},
+		{
+			name: "services without a cluster IP or with unsupported ports are skipped",
+			services: []types.Service{
+				{ServiceID: "svc-none", Name: "none", Ports: []types.ServicePort{{Port: 80}}},
+				{
+					ServiceID: "svc-sctp", Name: "sctp", ClusterIP: "10.96.0.12",
+					Ports: []types.ServicePort{{Port: 80, Protocol: "SCTP"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := BuildRules(tt.services, tt.endpoints)
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("BuildRules() = %+v, want %+v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestRuleKey(t *testing.T) {
+	rule := Rule{ClusterIP: "10.96.0.10", Port: 53, Protocol: "UDP"}
+	if got := rule.Key(); got != "udp/10.96.0.10:53" {
+		t.Errorf("Key() = %q, want udp/10.96.0.10:53", got)
+	}
+
+	nodePort := Rule{Port: 30080, NodePort: true, Protocol: "TCP"}
+	if got := nodePort.Key(); got != "tcp/:30080" {
+		t.Errorf("Key() = %q, want tcp/:30080", got)
+	}
+}
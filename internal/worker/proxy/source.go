@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/types"
+)
+
+// Source provides the services and endpoints the proxy forwards
+type Source interface {
+	ListServices(ctx context.Context) ([]types.Service, error)
+	ListEndpoints(ctx context.Context) ([]types.Endpoints, error)
+}
+
+// MasterSource reads services and endpoints from the master API
+type MasterSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewMasterSource creates a source backed by the master at masterURL
+func NewMasterSource(masterURL string) *MasterSource {
+	return &MasterSource{
+		url:    strings.TrimSuffix(masterURL, "/") + "/api/v1",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ListServices returns the services in all namespaces
+func (s *MasterSource) ListServices(ctx context.Context) ([]types.Service, error) {
+	var services []types.Service
+	if err := s.get(ctx, "/services", &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// ListEndpoints returns the endpoints of all services
+func (s *MasterSource) ListEndpoints(ctx context.Context) ([]types.Endpoints, error) {
+	var endpoints []types.Endpoints
+	if err := s.get(ctx, "/endpoints", &endpoints); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// get decodes the JSON response of a master API path into v
+func (s *MasterSource) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach master: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("master returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
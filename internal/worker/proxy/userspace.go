@@ -0,0 +1,317 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// udpBufferSize fits the largest UDP datagram
+const udpBufferSize = 65535
+
+// Userspace forwards service traffic through TCP listeners and UDP sockets bound to the service
+// IPs and node ports, relaying every connection to an endpoint chosen by the service's balancing
+// algorithm and session affinity. Endpoints that refuse a TCP connection are skipped, so a stale
+// endpoint only costs a retry.
+type Userspace struct {
+	addresses      Addresses
+	dialTimeout    time.Duration
+	udpIdleTimeout time.Duration
+	filter         Filter
+
+	mu     sync.Mutex
+	relays map[string]*relay
+}
+
+var _ Proxier = (*Userspace)(nil)
+
+// Filter decides which endpoints a client may be relayed to. Relayed connections come from the
+// node, so filters on the node's routed traffic, such as network policies, do not see them.
+type Filter interface {
+	// Allows reports whether a connection from clientIP to port on endpointIP is allowed
+	Allows(clientIP, endpointIP, protocol string, port int) bool
+}
+
+// relay is a service port the userspace proxier listens on
+type relay struct {
+	service  string
+	protocol string
+	closer   io.Closer
+	balancer *balancer
+}
+
+// NewUserspace creates a userspace proxier that assigns service IPs with addresses
+func NewUserspace(addresses Addresses, dialTimeout, udpIdleTimeout time.Duration) *Userspace {
+	return &Userspace{
+		addresses:      addresses,
+		dialTimeout:    dialTimeout,
+		udpIdleTimeout: udpIdleTimeout,
+		relays:         make(map[string]*relay),
+	}
+}
+
+// SetFilter restricts the endpoints each client is relayed to. It must be called before the
+// first Sync.
+func (u *Userspace) SetFilter(filter Filter) {
+	u.filter = filter
+}
+
+// Sync opens listeners for new rules, closes the ones for removed rules and updates endpoints.
+// Connections already relayed to a removed service are left to finish.
+func (u *Userspace) Sync(rules []Rule) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	wanted := make(map[string]Rule, len(rules))
+	ipSet := make(map[string]bool)
+	for _, rule := range rules {
+		wanted[rule.Key()] = rule
+		if !rule.NodePort {
+			ipSet[rule.ClusterIP] = true
+		}
+	}
+
+	for key, r := range u.relays {
+		if _, ok := wanted[key]; !ok {
+			_ = r.closer.Close()
+			delete(u.relays, key)
+		}
+	}
+
+	var errs []error
+	ips := make([]string, 0, len(ipSet))
+	for ip := range ipSet {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	if err := u.addresses.Sync(ips); err != nil {
+		errs = append(errs, fmt.Errorf("failed to assign service IPs: %w", err))
+	}
+
+	for key, rule := range wanted {
+		if r, ok := u.relays[key]; ok {
+			r.balancer.update(rule)
+			continue
+		}
+		r, err := u.listen(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to listen for %s on %s: %w", rule.Service, key, err))
+			continue
+		}
+		u.relays[key] = r
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops all listeners and removes the service IPs from the node
+func (u *Userspace) Close() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, r := range u.relays {
+		_ = r.closer.Close()
+		delete(u.relays, key)
+	}
+	return u.addresses.Sync(nil)
+}
+
+// listen binds the rule's service port and starts relaying it. Node port rules have no
+// ClusterIP, so they listen on every address of the node.
+func (u *Userspace) listen(rule Rule) (*relay, error) {
+	addr := net.JoinHostPort(rule.ClusterIP, strconv.Itoa(rule.Port))
+	r := &relay{service: rule.Service, protocol: rule.Protocol, balancer: newBalancer(rule)}
+
+	if rule.Protocol == "UDP" {
+		conn, err := net.ListenPacket("udp4", addr)
+		if err != nil {
+			return nil, err
+		}
+		r.closer = conn
+		go u.serveUDP(r, conn)
+		return r, nil
+	}
+
+	ln, err := net.Listen("tcp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	r.closer = ln
+	go u.serveTCP(r, ln)
+	return r, nil
+}
+
+// dial connects a client to the first endpoint that accepts the connection and returns the
+// endpoint's address. The caller reports the end of the connection to the relay's balancer.
+func (u *Userspace) dial(r *relay, network string, client net.Addr) (net.Conn, string, error) {
+	clientIP := clientIP(client)
+	endpoints := r.balancer.candidates(clientIP)
+	if len(endpoints) == 0 {
+		return nil, "", fmt.Errorf("service %s has no ready endpoints", r.service)
+	}
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		if !u.allows(r, clientIP, endpoint) {
+			errs = append(errs, fmt.Errorf("connection from %s to %s is not allowed", clientIP, endpoint))
+			continue
+		}
+		conn, err := net.DialTimeout(network, endpoint, u.dialTimeout)
+		if err == nil {
+			r.balancer.connected(clientIP, endpoint)
+			return conn, endpoint, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, "", fmt.Errorf("no endpoint of service %s is reachable: %w", r.service, errors.Join(errs...))
+}
+
+// allows reports whether the filter lets the client connect to the endpoint
+func (u *Userspace) allows(r *relay, clientIP, endpoint string) bool {
+	if u.filter == nil {
+		return true
+	}
+	host, portValue, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return false
+	}
+	port, err := strconv.Atoi(portValue)
+	if err != nil {
+		return false
+	}
+	return u.filter.Allows(clientIP, host, r.protocol, port)
+}
+
+// clientIP returns the IP address of a client, which session affinity is keyed by
+func clientIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// serveTCP accepts connections on a service port until the listener is closed
+func (u *Userspace) serveTCP(r *relay, ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Printf("service proxy: accept for %s failed: %v", r.service, err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go u.relayTCP(r, conn)
+	}
+}
+
+// relayTCP copies a client connection to an endpoint and back until both sides are done
+func (u *Userspace) relayTCP(r *relay, client net.Conn) {
+	defer func() { _ = client.Close() }()
+
+	backend, endpoint, err := u.dial(r, "tcp", client.RemoteAddr())
+	if err != nil {
+		log.Printf("service proxy: %v", err)
+		return
+	}
+	defer func() {
+		_ = backend.Close()
+		r.balancer.disconnected(endpoint)
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		copyAndCloseWrite(backend, client)
+	}()
+	go func() {
+		defer wg.Done()
+		copyAndCloseWrite(client, backend)
+	}()
+	wg.Wait()
+}
+
+// copyAndCloseWrite copies src to dst and then half-closes dst, so the peer sees the end of the stream
+func copyAndCloseWrite(dst, src net.Conn) {
+	_, _ = io.Copy(dst, src)
+	if tcp, ok := dst.(*net.TCPConn); ok {
+		_ = tcp.CloseWrite()
+		return
+	}
+	_ = dst.Close()
+}
+
+// serveUDP relays datagrams on a service port. Each client address is mapped to a connected
+// socket to one endpoint, which carries the replies back until it is idle for udpIdleTimeout.
+func (u *Userspace) serveUDP(r *relay, conn net.PacketConn) {
+	var mu sync.Mutex
+	sessions := make(map[string]net.Conn)
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, backend := range sessions {
+			_ = backend.Close()
+		}
+	}()
+
+	buf := make([]byte, udpBufferSize)
+	for {
+		n, client, err := conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		key := client.String()
+		mu.Lock()
+		backend, ok := sessions[key]
+		if !ok {
+			var endpoint string
+			if backend, endpoint, err = u.dial(r, "udp", client); err != nil {
+				mu.Unlock()
+				log.Printf("service proxy: %v", err)
+				continue
+			}
+			sessions[key] = backend
+			go func() {
+				u.replyUDP(conn, client, backend)
+				mu.Lock()
+				if sessions[key] == backend {
+					delete(sessions, key)
+				}
+				mu.Unlock()
+				_ = backend.Close()
+				r.balancer.disconnected(endpoint)
+			}()
+		}
+		mu.Unlock()
+
+		_ = backend.SetReadDeadline(time.Now().Add(u.udpIdleTimeout))
+		_, _ = backend.Write(buf[:n])
+	}
+}
+
+// replyUDP sends an endpoint's datagrams back to the client until the mapping is idle or closed
+func (u *Userspace) replyUDP(conn net.PacketConn, client net.Addr, backend net.Conn) {
+	buf := make([]byte, udpBufferSize)
+	for {
+		_ = backend.SetReadDeadline(time.Now().Add(u.udpIdleTimeout))
+		n, err := backend.Read(buf)
+		if err != nil {
+			return
+		}
+		if _, err := conn.WriteTo(buf[:n], client); err != nil {
+			return
+		}
+	}
+}
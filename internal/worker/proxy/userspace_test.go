@@ -0,0 +1,284 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingAddresses remembers the service IPs it was asked to assign
+type recordingAddresses struct {
+	mu  sync.Mutex
+	ips []string
+}
+
+func (a *recordingAddresses) Sync(ips []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ips = append([]string(nil), ips...)
+	return nil
+}
+
+// startNamedServer starts a TCP server that answers every connection with its name
+func startNamedServer(t *testing.T, name string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprintln(conn, name)
+			_ = conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// freePort returns a loopback port that is not in use
+func freePort(t *testing.T, network string) int {
+	t.Helper()
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+		return conn.LocalAddr().(*net.UDPAddr).Port
+	}
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// readName connects to addr and returns the first line it receives
+func readName(t *testing.T, addr string) (string, error) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp4", addr, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	return strings.TrimSpace(line), err
+}
+
+func TestUserspaceTCP(t *testing.T) {
+	a := startNamedServer(t, "a")
+	b := startNamedServer(t, "b")
+	dead := fmt.Sprintf("127.0.0.1:%d", freePort(t, "tcp"))
+
+	addresses := &recordingAddresses{}
+	u := NewUserspace(addresses, time.Second, time.Second)
+	defer func() { _ = u.Close() }()
+
+	port := freePort(t, "tcp")
+	rule := Rule{Service: "default/web", ClusterIP: "127.0.0.1", Port: port, Protocol: "TCP"}
+	frontend := fmt.Sprintf("127.0.0.1:%d", port)
+
+	rule.Endpoints = []string{a, b, dead}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !reflect.DeepEqual(addresses.ips, []string{"127.0.0.1"}) {
+		t.Errorf("assigned IPs = %v, want [127.0.0.1]", addresses.ips)
+	}
+
+	seen := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		name, err := readName(t, frontend)
+		if err != nil {
+			t.Fatalf("connection %d through the proxy failed: %v", i, err)
+		}
+		seen[name]++
+	}
+	if seen["a"] == 0 || seen["b"] == 0 || seen["a"]+seen["b"] != 6 {
+		t.Errorf("connections per endpoint = %v, want both endpoints used", seen)
+	}
+
+	rule.Endpoints = []string{b}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if name, err := readName(t, frontend); err != nil || name != "b" {
+			t.Fatalf("after the update got %q, %v, want b", name, err)
+		}
+	}
+
+	rule.Endpoints = nil
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if name, err := readName(t, frontend); err == nil {
+		t.Errorf("service without endpoints answered %q", name)
+	}
+
+	if err := u.Sync(nil); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if conn, err := net.DialTimeout("tcp4", frontend, time.Second); err == nil {
+		_ = conn.Close()
+		t.Error("removed service is still listening")
+	}
+	if len(addresses.ips) != 0 {
+		t.Errorf("assigned IPs = %v after removing all services", addresses.ips)
+	}
+}
+
+// filterFunc adapts a function to a Filter
+type filterFunc func(clientIP, endpointIP, protocol string, port int) bool
+
+func (f filterFunc) Allows(clientIP, endpointIP, protocol string, port int) bool {
+	return f(clientIP, endpointIP, protocol, port)
+}
+
+func TestUserspaceFilter(t *testing.T) {
+	a := startNamedServer(t, "a")
+	b := startNamedServer(t, "b")
+	_, deniedPort, _ := net.SplitHostPort(a)
+
+	u := NewUserspace(&recordingAddresses{}, time.Second, time.Second)
+	u.SetFilter(
+		filterFunc(
+			func(clientIP, endpointIP, protocol string, port int) bool {
+				return clientIP != "127.0.0.1" || protocol != "TCP" || strconv.Itoa(port) != deniedPort
+			},
+		),
+	)
+	defer func() { _ = u.Close() }()
+
+	port := freePort(t, "tcp")
+	rule := Rule{Service: "default/web", ClusterIP: "127.0.0.1", Port: port, Protocol: "TCP", Endpoints: []string{a, b}}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	frontend := fmt.Sprintf("127.0.0.1:%d", port)
+	for i := 0; i < 4; i++ {
+		if name, err := readName(t, frontend); err != nil || name != "b" {
+			t.Fatalf("connection %d got %q, %v, want only the allowed endpoint b", i, name, err)
+		}
+	}
+
+	rule.Endpoints = []string{a}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if name, err := readName(t, frontend); err == nil {
+		t.Errorf("connection to a denied endpoint answered %q", name)
+	}
+}
+
+func TestUserspaceSessionAffinity(t *testing.T) {
+	a := startNamedServer(t, "a")
+	b := startNamedServer(t, "b")
+
+	u := NewUserspace(&recordingAddresses{}, time.Second, time.Second)
+	defer func() { _ = u.Close() }()
+
+	port := freePort(t, "tcp")
+	rule := Rule{
+		Service: "default/web", ClusterIP: "127.0.0.1", Port: port, Protocol: "TCP",
+		Endpoints: []string{a, b}, Affinity: time.Minute,
+	}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	frontend := fmt.Sprintf("127.0.0.1:%d", port)
+	first, err := readName(t, frontend)
+	if err != nil {
+		t.Fatalf("connection through the proxy failed: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if name, err := readName(t, frontend); err != nil || name != first {
+			t.Fatalf("connection %d got %q, %v, want the client's endpoint %q", i, name, err, first)
+		}
+	}
+}
+
+func TestUserspaceNodePort(t *testing.T) {
+	a := startNamedServer(t, "a")
+
+	addresses := &recordingAddresses{}
+	u := NewUserspace(addresses, time.Second, time.Second)
+	defer func() { _ = u.Close() }()
+
+	port := freePort(t, "tcp")
+	rule := Rule{Service: "default/web", Port: port, NodePort: true, Protocol: "TCP", Endpoints: []string{a}}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(addresses.ips) != 0 {
+		t.Errorf("assigned IPs = %v, want none for a node port", addresses.ips)
+	}
+	if name, err := readName(t, fmt.Sprintf("127.0.0.1:%d", port)); err != nil || name != "a" {
+		t.Errorf("node port answered %q, %v, want a", name, err)
+	}
+}
+
+func TestUserspaceUDP(t *testing.T) {
+	backend, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = backend.Close() }()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := backend.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = backend.WriteTo([]byte("echo "+string(buf[:n])), addr)
+		}
+	}()
+
+	u := NewUserspace(&recordingAddresses{}, time.Second, time.Second)
+	defer func() { _ = u.Close() }()
+
+	port := freePort(t, "udp")
+	rule := Rule{
+		Service: "default/dns", ClusterIP: "127.0.0.1", Port: port, Protocol: "UDP",
+		Endpoints: []string{backend.LocalAddr().String()},
+	}
+	if err := u.Sync([]Rule{rule}); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	client, err := net.Dial("udp4", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = client.Close() }()
+	_ = client.SetDeadline(time.Now().Add(2 * time.Second))
+
+	for _, msg := range []string{"one", "two"} {
+		if _, err := client.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1024)
+		n, err := client.Read(buf)
+		if err != nil || string(buf[:n]) != "echo "+msg {
+			t.Fatalf("reply = %q, %v, want %q", buf[:n], err, "echo "+msg)
+		}
+	}
+}
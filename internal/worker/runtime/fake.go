@@ -0,0 +1,695 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FakeBehavior controls how containers created from an image behave in the fake runtime
+type FakeBehavior struct {
+	// PullError is returned when the image is pulled
+	PullError error
+
+	// ExitAfter makes containers exit on their own after running for this long;
+	// zero keeps them running until stopped
+	ExitAfter time.Duration
+
+	// ExitCode is reported when a container exits on its own
+	ExitCode int64
+
+	// Logs is returned as the container's log output and written to its stdout when it starts
+	Logs string
+
+	// CPUMillicores is the CPU a running container simulates using
+	CPUMillicores int64
+
+	// MemoryUsage is the memory working set reported for the container in bytes
+	MemoryUsage int64
+}
+
+// fakeContainer is a container tracked by the fake runtime
+type fakeContainer struct {
+	id        string
+	created   time.Time
+	started   time.Time
+	finished  time.Time
+	opts      ContainerOptions
+	state     string
+	exitCode  int64
+	ip        string
+	done      chan struct{}
+	doneOnce  sync.Once
+	exitTimer *time.Timer
+
+	// logs is the container's output; logsUpdated is closed and replaced when a line is appended
+	logs        []fakeLogLine
+	logsUpdated chan struct{}
+
+	// files is the container's simulated filesystem keyed by absolute path
+	files map[string]fakeFile
+}
+
+// fakeLogLine is a line of simulated container output
+type fakeLogLine struct {
+	time   time.Time
+	stderr bool
+	text   string
+}
+
+// appendLog adds a line to the container's output and wakes up followers
+func (c *fakeContainer) appendLog(line fakeLogLine) {
+	c.logs = append(c.logs, line)
+	close(c.logsUpdated)
+	c.logsUpdated = make(chan struct{})
+}
+
+// finish moves the container to the exited state and wakes up waiters
+func (c *fakeContainer) finish(exitCode int64) {
+	c.doneOnce.Do(
+		func() {
+			c.state = StateExited
+			c.exitCode = exitCode
+			c.finished = time.Now()
+			close(c.done)
+		},
+	)
+}
+
+// Fake is an in-process runtime that simulates containers without a container engine.
+// It is used for tests and for running a worker on machines without Docker.
+type Fake struct {
+	mu         sync.Mutex
+	images     map[string]ImageInfo
+	containers map[string]*fakeContainer
+	networks   map[string]NetworkInfo
+	behaviors  map[string]FakeBehavior
+	execFunc   func(containerID string, cmd []string) (int, string, error)
+	streamFunc func(containerID string, opts ExecOptions) (int, error)
+	pingError  error
+	nextID     atomic.Int64
+	nextIP     atomic.Int64
+}
+
+var _ Runtime = (*Fake)(nil)
+
+// NewFake creates an empty fake runtime
+func NewFake() *Fake {
+	return &Fake{
+		images:     make(map[string]ImageInfo),
+		containers: make(map[string]*fakeContainer),
+		networks:   make(map[string]NetworkInfo),
+		behaviors:  make(map[string]FakeBehavior),
+	}
+}
+
+// SetBehavior configures how containers created from the image behave
+func (f *Fake) SetBehavior(imageName string, behavior FakeBehavior) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.behaviors[imageName] = behavior
+}
+
+// SetExecFunc replaces the handler for ExecInContainer; by default every command succeeds
+func (f *Fake) SetExecFunc(fn func(containerID string, cmd []string) (int, string, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execFunc = fn
+}
+
+// SetExecStreamFunc sets the handler for ExecStream
+func (f *Fake) SetExecStreamFunc(fn func(containerID string, opts ExecOptions) (int, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streamFunc = fn
+}
+
+// SetContainerIP overrides the simulated IP address of a container
+func (f *Fake) SetContainerIP(containerID, ip string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("container %s not found", containerID)
+	}
+	c.ip = ip
+	return nil
+}
+
+// AppendLog writes a line to the container's stdout, or to its stderr if stderr is set
+func (f *Fake) AppendLog(containerID, line string, stderr bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("container %s not found", containerID)
+	}
+	c.appendLog(fakeLogLine{time: time.Now(), stderr: stderr, text: line})
+	return nil
+}
+
+// SetPingError makes Ping fail with err, simulating an unreachable engine; nil restores it
+func (f *Fake) SetPingError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingError = err
+}
+
+// Containers returns the IDs of all containers that have not been removed
+func (f *Fake) Containers() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := make([]string, 0, len(f.containers))
+	for id := range f.containers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ContainerOptions returns the options a container was created with
+func (f *Fake) ContainerOptions(containerID string) (ContainerOptions, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return ContainerOptions{}, false
+	}
+	return c.opts, true
+}
+
+// Name returns the runtime name
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Ping returns the error set with SetPingError
+func (f *Fake) Ping(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pingError
+}
+
+// Close stops all running containers
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, c := range f.containers {
+		if c.exitTimer != nil {
+			c.exitTimer.Stop()
+		}
+		c.finish(137)
+	}
+	return nil
+}
+
+// PullImage records the image as present
+func (f *Fake) PullImage(ctx context.Context, imageName string) error {
+	return f.PullImageWithAuth(ctx, imageName, nil)
+}
+
+// PullImageWithAuth records the image as present, or returns the configured pull error
+func (f *Fake) PullImageWithAuth(_ context.Context, imageName string, _ *RegistryAuth) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.behaviors[imageName].PullError; err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+
+	if _, ok := f.images[imageName]; !ok {
+		f.images[imageName] = ImageInfo{
+			ID:       fmt.Sprintf("sha256:fake%d", f.nextID.Add(1)),
+			RepoTags: []string{imageName},
+			Created:  time.Now(),
+		}
+	}
+	return nil
+}
+
+// ImageExists reports whether the image has been pulled
+func (f *Fake) ImageExists(_ context.Context, imageName string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.images[imageName]
+	return ok, nil
+}
+
+// ListImages returns the pulled images
+func (f *Fake) ListImages(_ context.Context) ([]ImageInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	images := make([]ImageInfo, 0, len(f.images))
+	for name, img := range f.images {
+		img.Containers = 0
+		for _, c := range f.containers {
+			if c.opts.Image == name {
+				img.Containers++
+			}
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// RemoveImage forgets a pulled image
+func (f *Fake) RemoveImage(_ context.Context, imageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for name, img := range f.images {
+		if img.ID == imageID || name == imageID {
+			delete(f.images, name)
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to remove image %s: not found", imageID)
+}
+
+// RootDir returns a placeholder directory; the fake runtime stores nothing on disk
+func (f *Fake) RootDir(_ context.Context) (string, error) {
+	return "/", nil
+}
+
+// SupportsStorageQuota always reports false
+func (f *Fake) SupportsStorageQuota(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// CreateContainer creates a container from an image and environment
+func (f *Fake) CreateContainer(ctx context.Context, imageName string, env []string) (string, error) {
+	return f.CreateContainerWithOptions(ctx, ContainerOptions{Image: imageName, Env: env})
+}
+
+// CreateContainerWithOptions creates a container; the image must have been pulled
+func (f *Fake) CreateContainerWithOptions(_ context.Context, opts ContainerOptions) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.images[opts.Image]; !ok {
+		return "", fmt.Errorf("failed to create container: no such image: %s", opts.Image)
+	}
+	if opts.NetworkID != "" {
+		if _, ok := f.networks[opts.NetworkID]; !ok {
+			return "", fmt.Errorf("failed to create container: network %s not found", opts.NetworkID)
+		}
+	}
+
+	id := fmt.Sprintf("fake-%012d", f.nextID.Add(1))
+	f.containers[id] = &fakeContainer{
+		id:      id,
+		created: time.Now(),
+		opts:    opts,
+		state:   StateCreated,
+		ip:      f.allocateIP(),
+		done:    make(chan struct{}),
+
+		logsUpdated: make(chan struct{}),
+	}
+	return id, nil
+}
+
+// StartContainer marks the container running and schedules its exit if configured
+func (f *Fake) StartContainer(_ context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("failed to start container %s: not found", containerID)
+	}
+	if c.state != StateCreated {
+		return fmt.Errorf("failed to start container %s: container is %s", containerID, c.state)
+	}
+
+	c.state = StateRunning
+	c.started = time.Now()
+	behavior := f.behaviors[c.opts.Image]
+	if behavior.Logs != "" {
+		for _, text := range strings.Split(strings.TrimSuffix(behavior.Logs, "\n"), "\n") {
+			c.appendLog(fakeLogLine{time: c.started, text: text})
+		}
+	}
+	if behavior.ExitAfter > 0 {
+		c.exitTimer = time.AfterFunc(
+			behavior.ExitAfter, func() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				c.finish(behavior.ExitCode)
+			},
+		)
+	}
+	return nil
+}
+
+// StopContainer stops the container as if it received SIGTERM
+func (f *Fake) StopContainer(_ context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("failed to stop container %s: not found", containerID)
+	}
+	if c.exitTimer != nil {
+		c.exitTimer.Stop()
+	}
+	c.finish(143)
+	return nil
+}
+
+// RemoveContainer removes the container, stopping it first
+func (f *Fake) RemoveContainer(_ context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("failed to remove container %s: not found", containerID)
+	}
+	if c.exitTimer != nil {
+		c.exitTimer.Stop()
+	}
+	c.finish(137)
+	delete(f.containers, containerID)
+	return nil
+}
+
+// GetContainerStatus returns the container's state
+func (f *Fake) GetContainerStatus(_ context.Context, containerID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container %s: not found", containerID)
+	}
+	return c.state, nil
+}
+
+// WaitContainer blocks until the container exits or the context is cancelled
+func (f *Fake) WaitContainer(ctx context.Context, containerID string) (int64, error) {
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	f.mu.Unlock()
+
+	if !ok {
+		return -1, fmt.Errorf("error waiting for container %s: not found", containerID)
+	}
+
+	select {
+	case <-c.done:
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return c.exitCode, nil
+	case <-ctx.Done():
+		return -1, fmt.Errorf("error waiting for container %s: %w", containerID, ctx.Err())
+	}
+}
+
+// GetContainerLogs returns the configured log output, limited to the last tail lines
+func (f *Fake) GetContainerLogs(_ context.Context, containerID string, tail int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return "", fmt.Errorf("failed to get logs for container %s: not found", containerID)
+	}
+
+	logs := f.behaviors[c.opts.Image].Logs
+	if tail <= 0 || logs == "" {
+		return logs, nil
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(logs, "\n"), "\n")
+	if len(lines) > tail {
+		lines = lines[len(lines)-tail:]
+	}
+	return strings.Join(lines, "") + "\n", nil
+}
+
+// StreamContainerLogs writes the lines the container has logged and, with Follow, the lines
+// appended until it exits
+func (f *Fake) StreamContainerLogs(ctx context.Context, containerID string, opts LogOptions) error {
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to get logs for container %s: not found", containerID)
+	}
+	lines := append([]fakeLogLine(nil), c.logs...)
+	next := len(c.logs)
+	updated := c.logsUpdated
+	f.mu.Unlock()
+
+	if opts.Tail > 0 && len(lines) > opts.Tail {
+		lines = lines[len(lines)-opts.Tail:]
+	}
+	finished := false
+	for {
+		for _, line := range lines {
+			if line.time.Before(opts.Since) {
+				continue
+			}
+			w := opts.Stdout
+			if line.stderr {
+				w = opts.Stderr
+			}
+			if w == nil {
+				continue
+			}
+			text := line.text + "\n"
+			if opts.Timestamps {
+				text = line.time.Format(time.RFC3339Nano) + " " + text
+			}
+			if _, err := io.WriteString(w, text); err != nil {
+				return fmt.Errorf("failed to write logs: %w", err)
+			}
+		}
+		if !opts.Follow || finished {
+			return nil
+		}
+
+		select {
+		case <-updated:
+		case <-c.done:
+			finished = true
+		case <-ctx.Done():
+			return nil
+		}
+
+		f.mu.Lock()
+		lines = append([]fakeLogLine(nil), c.logs[next:]...)
+		next = len(c.logs)
+		updated = c.logsUpdated
+		f.mu.Unlock()
+	}
+}
+
+// ExecInContainer runs the configured exec handler; commands succeed by default
+func (f *Fake) ExecInContainer(_ context.Context, containerID string, cmd []string) (int, string, error) {
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	execFunc := f.execFunc
+	f.mu.Unlock()
+
+	if !ok {
+		return -1, "", fmt.Errorf("failed to create exec: container %s not found", containerID)
+	}
+	if c.state != StateRunning {
+		return -1, "", fmt.Errorf("failed to create exec: container %s is not running", containerID)
+	}
+	if execFunc != nil {
+		return execFunc(containerID, cmd)
+	}
+	return 0, "", nil
+}
+
+// ExecStream runs the exec stream handler. Without one, the exec handler's output is written to
+// Stdout, and without either, Stdin is echoed back to Stdout.
+func (f *Fake) ExecStream(_ context.Context, containerID string, opts ExecOptions) (int, error) {
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	execFunc, streamFunc := f.execFunc, f.streamFunc
+	f.mu.Unlock()
+
+	if !ok {
+		return -1, fmt.Errorf("failed to create exec: container %s not found", containerID)
+	}
+	if c.state != StateRunning {
+		return -1, fmt.Errorf("failed to create exec: container %s is not running", containerID)
+	}
+
+	switch {
+	case streamFunc != nil:
+		return streamFunc(containerID, opts)
+	case execFunc != nil:
+		code, output, err := execFunc(containerID, opts.Cmd)
+		if err != nil {
+			return code, err
+		}
+		_, _ = io.WriteString(opts.Stdout, output)
+		return code, nil
+	case opts.Stdin != nil:
+		if _, err := io.Copy(opts.Stdout, opts.Stdin); err != nil {
+			return -1, fmt.Errorf("failed to stream exec output: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// ListContainers returns the containers carrying every given label
+func (f *Fake) ListContainers(_ context.Context, labels map[string]string) ([]ContainerInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var containers []ContainerInfo
+	for _, c := range f.containers {
+		containerLabels := map[string]string{LabelManaged: "true"}
+		for k, v := range c.opts.Labels {
+			containerLabels[k] = v
+		}
+
+		matches := true
+		for k, v := range labels {
+			if containerLabels[k] != v {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			containers = append(
+				containers, ContainerInfo{
+					ID:      c.id,
+					Image:   c.opts.Image,
+					State:   c.state,
+					Labels:  containerLabels,
+					Created: c.created,
+				},
+			)
+		}
+	}
+	return containers, nil
+}
+
+// CreatePodNetwork creates a simulated pod network
+func (f *Fake) CreatePodNetwork(_ context.Context, podID string, opts PodNetworkOptions) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := fmt.Sprintf("fake-net-%d", f.nextID.Add(1))
+	f.networks[id] = NetworkInfo{ID: id, PodID: podID, NodeID: opts.NodeID, Subnet: opts.Subnet}
+	return id, nil
+}
+
+// RemovePodNetwork removes a simulated pod network
+func (f *Fake) RemovePodNetwork(_ context.Context, networkID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.networks[networkID]; !ok {
+		return fmt.Errorf("failed to remove network %s: not found", networkID)
+	}
+	delete(f.networks, networkID)
+	return nil
+}
+
+// ListPodNetworks returns the simulated pod networks
+func (f *Fake) ListPodNetworks(_ context.Context) ([]NetworkInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	networks := make([]NetworkInfo, 0, len(f.networks))
+	for _, network := range f.networks {
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// GetContainerIP returns the container's simulated IP address
+func (f *Fake) GetContainerIP(_ context.Context, containerID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container %s: not found", containerID)
+	}
+	return c.ip, nil
+}
+
+// GetNetworkIP returns the container's simulated IP address on the network
+func (f *Fake) GetNetworkIP(ctx context.Context, containerID, networkID string) (string, error) {
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	f.mu.Unlock()
+
+	if !ok {
+		return "", fmt.Errorf("failed to inspect container %s: not found", containerID)
+	}
+	if c.opts.NetworkID != networkID {
+		return "", fmt.Errorf("container %s is not connected to network %s", containerID, networkID)
+	}
+	return f.GetContainerIP(ctx, containerID)
+}
+
+// ContainerMemoryUsage reports the memory usage configured for the container's image
+func (f *Fake) ContainerMemoryUsage(_ context.Context, containerID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return 0, fmt.Errorf("failed to get stats for container %s: not found", containerID)
+	}
+	return f.behaviors[c.opts.Image].MemoryUsage, nil
+}
+
+// ContainerStats reports CPU time accumulated at the configured rate while the container ran
+func (f *Fake) ContainerStats(_ context.Context, containerID string) (ContainerStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return ContainerStats{}, fmt.Errorf("failed to get stats for container %s: not found", containerID)
+	}
+
+	now := time.Now()
+	stats := ContainerStats{Timestamp: now, MemoryLimit: c.opts.MemoryLimit}
+	if c.started.IsZero() {
+		return stats, nil
+	}
+
+	end := now
+	if !c.finished.IsZero() {
+		end = c.finished
+	}
+	behavior := f.behaviors[c.opts.Image]
+	stats.CPUUsageNanos = uint64(end.Sub(c.started).Nanoseconds() * behavior.CPUMillicores / 1000)
+	if c.state == StateRunning {
+		stats.MemoryWorkingSet = behavior.MemoryUsage
+		stats.PIDs = 1
+	}
+	return stats, nil
+}
+
+// ContainerDiskUsage always reports zero usage
+func (f *Fake) ContainerDiskUsage(_ context.Context, _ string) (int64, error) {
+	return 0, nil
+}
+
+// allocateIP returns the next address in the simulated 10.244.0.0/16 range
+func (f *Fake) allocateIP() string {
+	n := f.nextIP.Add(1)
+	return fmt.Sprintf("10.244.%d.%d", (n/254)%256, n%254+1)
+}
@@ -0,0 +1,168 @@
+package runtime
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fakeFile is a file or directory in a fake container's filesystem
+type fakeFile struct {
+	dir     bool
+	mode    int64
+	data    []byte
+	modTime time.Time
+}
+
+// WriteFile creates or replaces a file in the container's simulated filesystem,
+// creating its parent directories
+func (f *Fake) WriteFile(containerID, filePath string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("container %s not found", containerID)
+	}
+	c.writeFile(path.Clean("/"+filePath), fakeFile{mode: 0o644, data: append([]byte(nil), data...)})
+	return nil
+}
+
+// ReadFile returns the contents of a file in the container's simulated filesystem
+func (f *Fake) ReadFile(containerID, filePath string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("container %s not found", containerID)
+	}
+	file, ok := c.files[path.Clean("/"+filePath)]
+	if !ok || file.dir {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrPathNotFound)
+	}
+	return append([]byte(nil), file.data...), nil
+}
+
+// CopyFromContainer writes a tar archive of a file or directory in the simulated filesystem
+func (f *Fake) CopyFromContainer(_ context.Context, containerID, srcPath string, w io.Writer) error {
+	srcPath = path.Clean("/" + srcPath)
+
+	f.mu.Lock()
+	c, ok := f.containers[containerID]
+	if !ok {
+		f.mu.Unlock()
+		return fmt.Errorf("failed to copy from container %s: not found", containerID)
+	}
+	if _, exists := c.files[srcPath]; !exists && srcPath != "/" {
+		f.mu.Unlock()
+		return fmt.Errorf("%s: %w", srcPath, ErrPathNotFound)
+	}
+
+	parent := path.Dir(srcPath)
+	var paths []string
+	for p := range c.files {
+		if p == srcPath || srcPath == "/" || strings.HasPrefix(p, srcPath+"/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	files := make([]fakeFile, len(paths))
+	for i, p := range paths {
+		files[i] = c.files[p]
+	}
+	f.mu.Unlock()
+
+	tw := tar.NewWriter(w)
+	for i, p := range paths {
+		name := strings.TrimPrefix(strings.TrimPrefix(p, parent), "/")
+		header := &tar.Header{Name: name, Mode: files[i].mode, ModTime: files[i].modTime}
+		if files[i].dir {
+			header.Typeflag = tar.TypeDir
+			header.Name += "/"
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(len(files[i].data))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(files[i].data); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	return tw.Close()
+}
+
+// CopyToContainer extracts the directories and regular files of a tar archive into the simulated filesystem
+func (f *Fake) CopyToContainer(_ context.Context, containerID, dstDir string, content io.Reader) error {
+	dstDir = path.Clean("/" + dstDir)
+
+	type entry struct {
+		path string
+		file fakeFile
+	}
+	var entries []entry
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		file := fakeFile{mode: header.Mode, modTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			file.dir = true
+		case tar.TypeReg:
+			if file.data, err = io.ReadAll(tr); err != nil {
+				return fmt.Errorf("failed to read archive: %w", err)
+			}
+		default:
+			continue
+		}
+		entries = append(entries, entry{path: path.Join(dstDir, path.Clean("/"+header.Name)), file: file})
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.containers[containerID]
+	if !ok {
+		return fmt.Errorf("failed to copy to container %s: not found", containerID)
+	}
+	if dir, exists := c.files[dstDir]; dstDir != "/" && (!exists || !dir.dir) {
+		return fmt.Errorf("%s: %w", dstDir, ErrPathNotFound)
+	}
+	for _, e := range entries {
+		c.writeFile(e.path, e.file)
+	}
+	return nil
+}
+
+// writeFile stores a file and creates any missing parent directories
+func (c *fakeContainer) writeFile(filePath string, file fakeFile) {
+	if c.files == nil {
+		c.files = make(map[string]fakeFile)
+	}
+	if file.modTime.IsZero() {
+		file.modTime = time.Now()
+	}
+	for dir := path.Dir(filePath); dir != "/"; dir = path.Dir(dir) {
+		if _, ok := c.files[dir]; !ok {
+			c.files[dir] = fakeFile{dir: true, mode: 0o755, modTime: file.modTime}
+		}
+	}
+	if filePath != "/" {
+		c.files[filePath] = file
+	}
+}
@@ -0,0 +1,210 @@
+package runtime
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeContainerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	if _, err := fake.CreateContainer(ctx, "nginx:1.25", nil); err == nil {
+		t.Fatal("expected create to fail before the image is pulled")
+	}
+
+	if err := fake.PullImage(ctx, "nginx:1.25"); err != nil {
+		t.Fatalf("PullImage() error = %v", err)
+	}
+	if exists, _ := fake.ImageExists(ctx, "nginx:1.25"); !exists {
+		t.Fatal("expected image to exist after pull")
+	}
+
+	networkID, err := fake.CreatePodNetwork(ctx, "pod-1", PodNetworkOptions{})
+	if err != nil {
+		t.Fatalf("CreatePodNetwork() error = %v", err)
+	}
+
+	id, err := fake.CreateContainerWithOptions(ctx, ContainerOptions{Image: "nginx:1.25", NetworkID: networkID})
+	if err != nil {
+		t.Fatalf("CreateContainerWithOptions() error = %v", err)
+	}
+	if state, _ := fake.GetContainerStatus(ctx, id); state != StateCreated {
+		t.Errorf("state = %s, want %s", state, StateCreated)
+	}
+
+	if err := fake.StartContainer(ctx, id); err != nil {
+		t.Fatalf("StartContainer() error = %v", err)
+	}
+	if ip, err := fake.GetNetworkIP(ctx, id, networkID); err != nil || ip == "" {
+		t.Errorf("GetNetworkIP() = %q, %v", ip, err)
+	}
+	if code, _, err := fake.ExecInContainer(ctx, id, []string{"true"}); err != nil || code != 0 {
+		t.Errorf("ExecInContainer() = %d, %v", code, err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := fake.WaitContainer(waitCtx, id); err == nil {
+		t.Fatal("expected wait on a running container to time out")
+	}
+
+	if err := fake.StopContainer(ctx, id); err != nil {
+		t.Fatalf("StopContainer() error = %v", err)
+	}
+	code, err := fake.WaitContainer(ctx, id)
+	if err != nil || code != 143 {
+		t.Errorf("WaitContainer() = %d, %v, want 143", code, err)
+	}
+
+	if err := fake.RemoveContainer(ctx, id); err != nil {
+		t.Fatalf("RemoveContainer() error = %v", err)
+	}
+	if err := fake.RemovePodNetwork(ctx, networkID); err != nil {
+		t.Fatalf("RemovePodNetwork() error = %v", err)
+	}
+	if len(fake.Containers()) != 0 {
+		t.Errorf("expected no containers, got %v", fake.Containers())
+	}
+}
+
+func TestFakeBehavior(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	pullErr := errors.New("manifest unknown")
+	fake.SetBehavior("missing:1.0", FakeBehavior{PullError: pullErr})
+	if err := fake.PullImage(ctx, "missing:1.0"); !errors.Is(err, pullErr) {
+		t.Errorf("PullImage() error = %v, want %v", err, pullErr)
+	}
+
+	fake.SetBehavior("job:1.0", FakeBehavior{ExitAfter: 5 * time.Millisecond, ExitCode: 2, Logs: "one\ntwo\nthree\n"})
+	_ = fake.PullImage(ctx, "job:1.0")
+	id, _ := fake.CreateContainer(ctx, "job:1.0", nil)
+	_ = fake.StartContainer(ctx, id)
+
+	code, err := fake.WaitContainer(ctx, id)
+	if err != nil || code != 2 {
+		t.Errorf("WaitContainer() = %d, %v, want 2", code, err)
+	}
+
+	logs, _ := fake.GetContainerLogs(ctx, id, 2)
+	if logs != "two\nthree\n" {
+		t.Errorf("GetContainerLogs() = %q, want last two lines", logs)
+	}
+}
+
+func TestFakeStreamContainerLogs(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+	fake.SetBehavior("app:1.0", FakeBehavior{Logs: "one\ntwo\nthree\n"})
+	if err := fake.PullImage(ctx, "app:1.0"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := fake.CreateContainer(ctx, "app:1.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.StartContainer(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.AppendLog(id, "oops", true); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr strings.Builder
+	if err := fake.StreamContainerLogs(
+		ctx, id, LogOptions{Tail: 2, Stdout: &stdout, Stderr: &stderr},
+	); err != nil {
+		t.Fatalf("StreamContainerLogs() error = %v", err)
+	}
+	if stdout.String() != "three\n" || stderr.String() != "oops\n" {
+		t.Errorf("stdout = %q, stderr = %q, want the last two lines split by stream", stdout.String(), stderr.String())
+	}
+
+	// Following returns the lines appended until the container exits
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- fake.StreamContainerLogs(ctx, id, LogOptions{Follow: true, Tail: 2, Timestamps: true, Stdout: writer})
+		_ = writer.Close()
+	}()
+
+	lines := bufio.NewScanner(reader)
+	if !lines.Scan() || !strings.HasSuffix(lines.Text(), " three") {
+		t.Fatalf("first line = %q, want a timestamped three", lines.Text())
+	}
+	if _, err := time.Parse(time.RFC3339Nano, strings.Fields(lines.Text())[0]); err != nil {
+		t.Errorf("timestamp: %v", err)
+	}
+	if err := fake.AppendLog(id, "four", false); err != nil {
+		t.Fatal(err)
+	}
+	if !lines.Scan() || !strings.HasSuffix(lines.Text(), " four") {
+		t.Fatalf("second line = %q, want four", lines.Text())
+	}
+	if err := fake.StopContainer(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("StreamContainerLogs() error = %v", err)
+	}
+}
+
+func TestFakeCopyFiles(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+	if err := fake.PullImage(ctx, "app:1.0"); err != nil {
+		t.Fatal(err)
+	}
+	id, err := fake.CreateContainer(ctx, "app:1.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.WriteFile(id, "/var/log/app/app.log", []byte("started\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := fake.CopyFromContainer(ctx, id, "/var/log/app", &archive); err != nil {
+		t.Fatalf("CopyFromContainer() error = %v", err)
+	}
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(archive.Bytes()))
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	if strings.Join(names, ",") != "app/,app/app.log" {
+		t.Errorf("archive entries = %v, want app/ and app/app.log", names)
+	}
+
+	if err := fake.CopyFromContainer(ctx, id, "/missing", io.Discard); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("CopyFromContainer() of a missing path error = %v, want ErrPathNotFound", err)
+	}
+
+	// Copying the archive back into another directory recreates the tree there
+	err = fake.CopyToContainer(ctx, id, "/tmp", bytes.NewReader(archive.Bytes()))
+	if !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("CopyToContainer() into a missing directory error = %v, want ErrPathNotFound", err)
+	}
+	if err := fake.CopyToContainer(ctx, id, "/", bytes.NewReader(archive.Bytes())); err != nil {
+		t.Fatalf("CopyToContainer() error = %v", err)
+	}
+	if data, err := fake.ReadFile(id, "/app/app.log"); err != nil || string(data) != "started\n" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,270 @@
+// Package runtime defines the container runtime interface used by the worker agent,
+// so the agent is not tied to a particular container engine.
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Runtime is a container engine the worker runs tasks and pods on
+type Runtime interface {
+	ImageService
+	ContainerService
+	NetworkService
+	StatsService
+
+	// Name identifies the runtime implementation (e.g., "docker", "cri", "fake")
+	Name() string
+
+	// Ping checks that the container engine is reachable
+	Ping(ctx context.Context) error
+
+	// Close releases the runtime's connection
+	Close() error
+}
+
+// ImageService manages the node's local image store
+type ImageService interface {
+	PullImage(ctx context.Context, imageName string) error
+	PullImageWithAuth(ctx context.Context, imageName string, auth *RegistryAuth) error
+	ImageExists(ctx context.Context, imageName string) (bool, error)
+	ListImages(ctx context.Context) ([]ImageInfo, error)
+	RemoveImage(ctx context.Context, imageID string) error
+
+	// RootDir returns the directory holding the runtime's images and writable layers
+	RootDir(ctx context.Context) (string, error)
+
+	// SupportsStorageQuota reports whether writable layer size can be limited
+	SupportsStorageQuota(ctx context.Context) (bool, error)
+}
+
+// ContainerService manages container lifecycles
+type ContainerService interface {
+	CreateContainer(ctx context.Context, imageName string, env []string) (string, error)
+	CreateContainerWithOptions(ctx context.Context, opts ContainerOptions) (string, error)
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string) error
+	RemoveContainer(ctx context.Context, containerID string) error
+	GetContainerStatus(ctx context.Context, containerID string) (string, error)
+
+	// WaitContainer blocks until the container stops and returns its exit code
+	WaitContainer(ctx context.Context, containerID string) (int64, error)
+
+	GetContainerLogs(ctx context.Context, containerID string, tail int) (string, error)
+
+	// StreamContainerLogs writes the container's output to opts.Stdout and opts.Stderr.
+	// With Follow it keeps writing until the container stops or ctx is cancelled.
+	StreamContainerLogs(ctx context.Context, containerID string, opts LogOptions) error
+
+	// ExecInContainer runs a command in a running container and returns its exit code and output
+	ExecInContainer(ctx context.Context, containerID string, cmd []string) (int, string, error)
+
+	// ExecStream runs a command in a running container with its standard streams attached
+	// and returns its exit code once the command finishes
+	ExecStream(ctx context.Context, containerID string, opts ExecOptions) (int, error)
+
+	// ListContainers returns all containers, running or not, that carry every given label
+	ListContainers(ctx context.Context, labels map[string]string) ([]ContainerInfo, error)
+
+	// CopyFromContainer writes a tar archive of the file or directory at srcPath to w.
+	// Archive entries are named relative to the parent directory of srcPath.
+	// It returns ErrPathNotFound if srcPath does not exist.
+	CopyFromContainer(ctx context.Context, containerID, srcPath string, w io.Writer) error
+
+	// CopyToContainer extracts a tar archive read from content into the directory dstDir.
+	// It returns ErrPathNotFound if dstDir does not exist.
+	CopyToContainer(ctx context.Context, containerID, dstDir string, content io.Reader) error
+}
+
+// ErrPathNotFound is returned when a path copied from or to a container does not exist
+var ErrPathNotFound = errors.New("no such file or directory in container")
+
+// NetworkService manages the networks pods share between their containers
+type NetworkService interface {
+	// CreatePodNetwork creates the network shared by a pod's containers and returns its ID.
+	// Runtimes that configure name resolution per pod rather than per container apply opts.DNS here.
+	CreatePodNetwork(ctx context.Context, podID string, opts PodNetworkOptions) (string, error)
+	RemovePodNetwork(ctx context.Context, networkID string) error
+	GetContainerIP(ctx context.Context, containerID string) (string, error)
+	GetNetworkIP(ctx context.Context, containerID, networkID string) (string, error)
+
+	// ListPodNetworks returns the pod networks created by CreatePodNetwork
+	ListPodNetworks(ctx context.Context) ([]NetworkInfo, error)
+}
+
+// StatsService reports container resource usage
+type StatsService interface {
+	// ContainerMemoryUsage returns the container's memory working set in bytes
+	ContainerMemoryUsage(ctx context.Context, containerID string) (int64, error)
+
+	// ContainerDiskUsage returns the size of the container's writable layer in bytes
+	ContainerDiskUsage(ctx context.Context, containerID string) (int64, error)
+
+	// ContainerStats returns a sample of the container's resource counters
+	ContainerStats(ctx context.Context, containerID string) (ContainerStats, error)
+}
+
+// ExecOptions describes an interactive command run by ExecStream
+type ExecOptions struct {
+	Cmd []string
+	// TTY allocates a terminal; its output is written to Stdout only
+	TTY bool
+	// Stdin is attached to the command when set; the command sees EOF when it is exhausted
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Resize delivers terminal size changes when TTY is set
+	Resize <-chan TerminalSize
+}
+
+// LogOptions selects the output written by StreamContainerLogs
+type LogOptions struct {
+	Follow bool
+	// Since skips lines written before this time; zero writes all lines
+	Since time.Time
+	// Tail limits the output to the last lines; zero or less writes all lines
+	Tail int
+	// Timestamps prefixes each line with its RFC3339Nano time and a space
+	Timestamps bool
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// TerminalSize is the size of an exec terminal in characters
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// ContainerStats is a point-in-time sample of a container's resource counters.
+// CPU, network and block IO counters are cumulative since the container started;
+// runtimes that do not expose a counter leave it at zero.
+type ContainerStats struct {
+	Timestamp        time.Time
+	CPUUsageNanos    uint64
+	MemoryWorkingSet int64
+	MemoryLimit      int64
+	NetworkRxBytes   uint64
+	NetworkTxBytes   uint64
+	BlockReadBytes   uint64
+	BlockWriteBytes  uint64
+	PIDs             int64
+}
+
+// Container states reported by GetContainerStatus
+const (
+	StateCreated = "created"
+	StateRunning = "running"
+	StateExited  = "exited"
+)
+
+// Labels set on containers and networks so the worker can rediscover them after a restart
+const (
+	LabelManaged       = "podling.io/managed"
+	LabelNodeID        = "podling.io/node-id"
+	LabelPodID         = "podling.io/pod-id"
+	LabelContainerName = "podling.io/container-name"
+	LabelTaskID        = "podling.io/task-id"
+	LabelType          = "podling.io/type"
+)
+
+// TypePodNetwork is the LabelType value of pod networks
+const TypePodNetwork = "pod-network"
+
+// ContainerInfo describes a container found by ListContainers
+type ContainerInfo struct {
+	ID      string
+	Image   string
+	State   string
+	Labels  map[string]string
+	Created time.Time
+}
+
+// NetworkInfo describes a pod network found by ListPodNetworks
+type NetworkInfo struct {
+	ID    string
+	PodID string
+	// NodeID is the node the network was created for; empty for networks created without one
+	NodeID string
+	// Subnet is the CIDR the network was created with, if the runtime knows it
+	Subnet string
+}
+
+// PodNetworkOptions describes a pod network to create
+type PodNetworkOptions struct {
+	// NodeID labels the network with the node that owns it, so workers sharing a runtime tell theirs apart
+	NodeID string
+	// DNS is the resolv.conf of the pod's containers; nil uses the runtime default
+	DNS *DNSConfig
+	// Subnet is the CIDR the pod's addresses are assigned from; empty lets the runtime pick one
+	Subnet string
+	// MTU of the pod's network interfaces; zero uses the runtime default
+	MTU int
+}
+
+// RegistryAuth holds credentials for pulling from a private registry
+type RegistryAuth struct {
+	ServerAddress string
+	Username      string
+	Password      string
+}
+
+// ImageInfo describes an image in the local image store
+type ImageInfo struct {
+	ID         string
+	RepoTags   []string
+	Size       int64
+	Created    time.Time
+	Containers int64
+}
+
+// PortMapping represents a mapping between container and host ports
+type PortMapping struct {
+	ContainerPort int
+	HostPort      int
+	Protocol      string
+}
+
+// SecurityOptions holds the privilege settings applied to a container
+type SecurityOptions struct {
+	// User is "uid" or "uid:gid"; empty uses the image default
+	User            string
+	ReadOnlyRootfs  bool
+	Privileged      bool
+	NoNewPrivileges bool
+	CapAdd          []string
+	CapDrop         []string
+	// SeccompProfile is "unconfined" or the JSON content of a profile; empty uses the default
+	SeccompProfile string
+	// AppArmorProfile is "unconfined" or a loaded profile name; empty uses the default
+	AppArmorProfile string
+}
+
+// ContainerOptions describes a container to create
+type ContainerOptions struct {
+	Image       string
+	Env         []string
+	NetworkID   string
+	CPUQuota    float64
+	CPUShares   int64
+	MemoryLimit int64
+	PidsLimit   int64
+	// StorageLimit caps the writable layer size in bytes; requires a runtime with quota support
+	StorageLimit int64
+	Ports        []PortMapping
+	Security     SecurityOptions
+	// Labels are added to the container in addition to LabelManaged
+	Labels map[string]string
+	// DNS replaces the container's resolv.conf settings; nil uses the runtime default
+	DNS *DNSConfig
+}
+
+// DNSConfig is the resolv.conf of a container
+type DNSConfig struct {
+	Servers  []string
+	Searches []string
+	Options  []string
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/danpasecinic/podling/internal/types"
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// Source reports the raw resource counters of a container
+type Source interface {
+	ContainerStats(ctx context.Context, containerID string) (runtime.ContainerStats, error)
+}
+
+// Collector samples container stats and turns cumulative CPU time into a usage rate
+// by comparing each sample with the previous one of the same container
+type Collector struct {
+	source Source
+
+	mu      sync.Mutex
+	samples map[string]sample
+}
+
+type sample struct {
+	raw   runtime.ContainerStats
+	stats types.ContainerStats
+}
+
+// NewCollector creates a collector reading from the given source
+func NewCollector(source Source) *Collector {
+	return &Collector{source: source, samples: make(map[string]sample)}
+}
+
+// Collect samples the given containers and forgets containers no longer in the list.
+// Containers that fail to report keep their previous sample; the first error is returned.
+func (c *Collector) Collect(ctx context.Context, containerIDs []string) error {
+	var firstErr error
+	keep := make(map[string]bool, len(containerIDs))
+
+	for _, id := range containerIDs {
+		keep[id] = true
+		if _, err := c.Sample(ctx, id); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	c.mu.Lock()
+	for id := range c.samples {
+		if !keep[id] {
+			delete(c.samples, id)
+		}
+	}
+	c.mu.Unlock()
+
+	return firstErr
+}
+
+// Sample reads the current stats of a container and records them as its latest sample
+func (c *Collector) Sample(ctx context.Context, containerID string) (types.ContainerStats, error) {
+	raw, err := c.source.ContainerStats(ctx, containerID)
+	if err != nil {
+		return types.ContainerStats{}, fmt.Errorf("failed to collect stats for container %s: %w", containerID, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := sample{raw: raw, stats: convert(containerID, raw)}
+	if previous, ok := c.samples[containerID]; ok {
+		current.stats.CPU = cpuRate(previous.raw, raw)
+	}
+	c.samples[containerID] = current
+	return current.stats, nil
+}
+
+// Stats returns the latest stats of a container.
+// CPU usage is only known once the container was sampled twice.
+func (c *Collector) Stats(containerID string) (types.ContainerStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.samples[containerID]
+	return s.stats, ok
+}
+
+// convert maps raw runtime counters to container stats without a CPU rate
+func convert(containerID string, raw runtime.ContainerStats) types.ContainerStats {
+	return types.ContainerStats{
+		ContainerID:     containerID,
+		Memory:          raw.MemoryWorkingSet,
+		MemoryLimit:     raw.MemoryLimit,
+		NetworkRxBytes:  raw.NetworkRxBytes,
+		NetworkTxBytes:  raw.NetworkTxBytes,
+		BlockReadBytes:  raw.BlockReadBytes,
+		BlockWriteBytes: raw.BlockWriteBytes,
+		PIDs:            raw.PIDs,
+		Timestamp:       raw.Timestamp,
+	}
+}
+
+// cpuRate returns the CPU used between two samples in millicores
+func cpuRate(previous, current runtime.ContainerStats) int64 {
+	elapsed := current.Timestamp.Sub(previous.Timestamp)
+	if elapsed <= 0 || current.CPUUsageNanos < previous.CPUUsageNanos {
+		return 0
+	}
+	return int64((current.CPUUsageNanos - previous.CPUUsageNanos) * 1000 / uint64(elapsed.Nanoseconds()))
+}
@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danpasecinic/podling/internal/worker/runtime"
+)
+
+// stubSource returns queued stats per container
+type stubSource struct {
+	stats map[string]runtime.ContainerStats
+	err   error
+}
+
+func (s *stubSource) ContainerStats(_ context.Context, containerID string) (runtime.ContainerStats, error) {
+	if s.err != nil {
+		return runtime.ContainerStats{}, s.err
+	}
+	return s.stats[containerID], nil
+}
+
+func TestCollector(t *testing.T) {
+	ctx := context.Background()
+	start := time.Now()
+	source := &stubSource{
+		stats: map[string]runtime.ContainerStats{
+			"c1": {Timestamp: start, CPUUsageNanos: 1_000_000_000, MemoryWorkingSet: 1024, PIDs: 3},
+		},
+	}
+	collector := NewCollector(source)
+
+	if err := collector.Collect(ctx, []string{"c1"}); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	first, ok := collector.Stats("c1")
+	if !ok {
+		t.Fatal("expected stats after the first sample")
+	}
+	if first.CPU != 0 || first.Memory != 1024 || first.PIDs != 3 {
+		t.Errorf("first sample = %+v, want no CPU rate, 1024 bytes and 3 pids", first)
+	}
+
+	// Half a core over two seconds
+	source.stats["c1"] = runtime.ContainerStats{
+		Timestamp: start.Add(2 * time.Second), CPUUsageNanos: 2_000_000_000, MemoryWorkingSet: 2048,
+	}
+	if err := collector.Collect(ctx, []string{"c1"}); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	second, _ := collector.Stats("c1")
+	if second.CPU != 500 || second.Memory != 2048 {
+		t.Errorf("second sample = %+v, want 500m and 2048 bytes", second)
+	}
+
+	// A failing runtime keeps the last known sample
+	source.err = errors.New("runtime unavailable")
+	if err := collector.Collect(ctx, []string{"c1"}); err == nil {
+		t.Error("expected Collect() to report the runtime error")
+	}
+	if kept, ok := collector.Stats("c1"); !ok || kept.CPU != 500 {
+		t.Errorf("stats after failure = %+v, %v, want the previous sample", kept, ok)
+	}
+
+	// Containers that are no longer running are forgotten
+	source.err = nil
+	if err := collector.Collect(ctx, nil); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if _, ok := collector.Stats("c1"); ok {
+		t.Error("expected stats of a removed container to be dropped")
+	}
+}
+
+func TestCPURate(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		previous runtime.ContainerStats
+		current  runtime.ContainerStats
+		want     int64
+	}{
+		{
+			name:     "two cores",
+			previous: runtime.ContainerStats{Timestamp: now, CPUUsageNanos: 0},
+			current:  runtime.ContainerStats{Timestamp: now.Add(time.Second), CPUUsageNanos: 2_000_000_000},
+			want:     2000,
+		},
+		{
+			name:     "counter reset after restart",
+			previous: runtime.ContainerStats{Timestamp: now, CPUUsageNanos: 5_000_000_000},
+			current:  runtime.ContainerStats{Timestamp: now.Add(time.Second), CPUUsageNanos: 100},
+			want:     0,
+		},
+		{
+			name:     "no elapsed time",
+			previous: runtime.ContainerStats{Timestamp: now, CPUUsageNanos: 0},
+			current:  runtime.ContainerStats{Timestamp: now, CPUUsageNanos: 100},
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := cpuRate(tt.previous, tt.current); got != tt.want {
+					t.Errorf("cpuRate() = %d, want %d", got, tt.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,95 @@
+// Package stats samples resource usage on the worker node.
+package stats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CPUSampler computes node CPU usage from the kernel's cumulative CPU time counters
+type CPUSampler struct {
+	path string
+	cpus int
+
+	mu        sync.Mutex
+	lastTotal uint64
+	lastIdle  uint64
+	primed    bool
+}
+
+// NewCPUSampler creates a sampler reading /proc/stat
+func NewCPUSampler() *CPUSampler {
+	return &CPUSampler{path: "/proc/stat", cpus: goruntime.NumCPU()}
+}
+
+// Sample returns the CPU used since the previous sample in millicores.
+// The first sample only records a baseline and reports false.
+func (s *CPUSampler) Sample() (int64, bool, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read cpu stats: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	total, idle, err := parseProcStat(f)
+	if err != nil {
+		return 0, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lastTotal, lastIdle, primed := s.lastTotal, s.lastIdle, s.primed
+	s.lastTotal, s.lastIdle, s.primed = total, idle, true
+
+	if !primed || total <= lastTotal {
+		return 0, false, nil
+	}
+	return millicores(total-lastTotal, idle-lastIdle, s.cpus), true, nil
+}
+
+// millicores converts the busy share of elapsed CPU time into millicores across all CPUs
+func millicores(totalDelta, idleDelta uint64, cpus int) int64 {
+	if totalDelta == 0 || idleDelta > totalDelta {
+		return 0
+	}
+	busy := totalDelta - idleDelta
+	return int64(busy * uint64(cpus) * 1000 / totalDelta)
+}
+
+// parseProcStat returns the total and idle jiffies of the aggregate "cpu" line of /proc/stat
+func parseProcStat(r io.Reader) (total, idle uint64, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+
+		// user nice system idle iowait irq softirq steal; guest time is already counted in user
+		for i, field := range fields[1:] {
+			if i >= 8 {
+				break
+			}
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("failed to parse cpu stats: %w", err)
+			}
+			total += value
+			if i == 3 || i == 4 {
+				idle += value
+			}
+		}
+		return total, idle, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse cpu stats: %w", err)
+	}
+	return 0, 0, fmt.Errorf("aggregate cpu line missing from cpu stats")
+}
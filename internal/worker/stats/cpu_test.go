@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseProcStat(t *testing.T) {
+	input := `cpu  100 10 50 800 40 0 0 0 0 0
+cpu0 50 5 25 400 20 0 0 0 0 0
+intr 12345
+`
+	total, idle, err := parseProcStat(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseProcStat() error = %v", err)
+	}
+	if total != 1000 {
+		t.Errorf("total = %d, want 1000", total)
+	}
+	if idle != 840 {
+		t.Errorf("idle = %d, want 840", idle)
+	}
+
+	if _, _, err := parseProcStat(strings.NewReader("intr 12345\n")); err == nil {
+		t.Error("expected error when the cpu line is missing")
+	}
+}
+
+func TestCPUSampler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sampler := &CPUSampler{path: path, cpus: 4}
+
+	write("cpu  100 0 100 800 0 0 0 0\n")
+	if _, ok, err := sampler.Sample(); err != nil || ok {
+		t.Fatalf("first Sample() = ok %v, err %v; want baseline only", ok, err)
+	}
+
+	// 1000 jiffies elapsed, 250 of them busy: a quarter of 4 CPUs
+	write("cpu  300 0 150 1550 0 0 0 0\n")
+	usage, ok, err := sampler.Sample()
+	if err != nil || !ok {
+		t.Fatalf("Sample() = ok %v, err %v", ok, err)
+	}
+	if usage != 1000 {
+		t.Errorf("Sample() = %dm, want 1000m", usage)
+	}
+}